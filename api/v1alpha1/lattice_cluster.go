@@ -34,8 +34,42 @@ type LatticeClusterSpec struct {
 
 	// CredentialRef is the name of a Secret in the controller namespace that
 	// holds the authentication token for the remote management API.
-	// The Secret must have key "token".
+	// The Secret must have key "token", a platform admin session token. It
+	// may also hold "ca.crt", the only CA trusted for the remote endpoint,
+	// and "tls.crt"/"tls.key", a client certificate for a proxy in front of
+	// the endpoint that requires one.
 	CredentialRef string `json:"credentialRef"`
+
+	// TLSServerName overrides the server name verified against the remote
+	// certificate, e.g. when ManagementEndpoint is an IP address.
+	// +optional
+	TLSServerName string `json:"tlsServerName,omitempty"`
+
+	// ProbeInterval is how often the remote cluster is health-checked.
+	// Defaults to 1m.
+	// +optional
+	ProbeInterval *metav1.Duration `json:"probeInterval,omitempty"`
+
+	// DisableCredentialRotation keeps the token in CredentialRef as is. By
+	// default the controller exchanges it for a fresh one once half of its
+	// lifetime has passed and revokes the old token.
+	// +optional
+	DisableCredentialRotation bool `json:"disableCredentialRotation,omitempty"`
+}
+
+// RemoteGateway is the gateway of a remote network that a
+// LatticeClusterPeering connects to, as last seen by the health probe.
+type RemoteGateway struct {
+	// Namespace is the workspace namespace in the remote cluster.
+	Namespace string `json:"namespace"`
+	// Network is the LatticeNetwork name in Namespace.
+	Network string `json:"network"`
+	// PublicKey is the WireGuard public key of the remote gateway.
+	PublicKey string `json:"publicKey,omitempty"`
+	// GatewayIP is the overlay address of the remote gateway.
+	GatewayIP string `json:"gatewayIP,omitempty"`
+	// CIDR is the ActiveCIDR of the remote network.
+	CIDR string `json:"cidr,omitempty"`
 }
 
 // LatticeClusterStatus reports the observed connection state of a remote cluster.
@@ -46,6 +80,17 @@ type LatticeClusterStatus struct {
 	// LastProbeTime is when the controller last successfully contacted the remote cluster.
 	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
 
+	// CredentialRotatedAt is when the token in CredentialRef was last rotated.
+	CredentialRotatedAt *metav1.Time `json:"credentialRotatedAt,omitempty"`
+
+	// CredentialExpiresAt is when the current token expires, if it carries
+	// an expiry.
+	CredentialExpiresAt *metav1.Time `json:"credentialExpiresAt,omitempty"`
+
+	// Gateways lists the remote gateways of the peerings that reference this
+	// cluster. A change re-reconciles the affected peerings.
+	Gateways []RemoteGateway `json:"gateways,omitempty"`
+
 	// Conditions contains fine-grained status conditions.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
@@ -55,6 +100,7 @@ type LatticeClusterStatus struct {
 // +kubebuilder:resource:scope=Cluster,shortName=wfcluster
 // +kubebuilder:printcolumn:name="PHASE",type="string",JSONPath=".status.phase"
 // +kubebuilder:printcolumn:name="ENDPOINT",type="string",JSONPath=".spec.managementEndpoint"
+// +kubebuilder:printcolumn:name="LAST-PROBE",type="date",JSONPath=".status.lastProbeTime"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 
 // LatticeCluster registers a remote Lattice deployment so that
@@ -93,6 +139,19 @@ type LatticeClusterPeeringSpec struct {
 	RemoteNamespace string `json:"remoteNamespace"`
 	// RemoteNetwork is the LatticeNetwork name in RemoteNamespace.
 	RemoteNetwork string `json:"remoteNetwork"`
+
+	// LocalVirtualCIDR is the range the local network is presented as to the
+	// remote cluster. When set, the local gateway translates between the
+	// ActiveCIDR of LocalNetwork and this range with a 1:1 prefix mapping.
+	// The peering in the remote cluster must use it as its RemoteVirtualCIDR.
+	// +optional
+	LocalVirtualCIDR string `json:"localVirtualCIDR,omitempty"`
+
+	// RemoteVirtualCIDR is the range the remote network is presented as to
+	// local peers. It must match the LocalVirtualCIDR of the peering in the
+	// remote cluster, whose gateway performs the translation.
+	// +optional
+	RemoteVirtualCIDR string `json:"remoteVirtualCIDR,omitempty"`
 }
 
 // ClusterPeeringPhase is the lifecycle phase of a LatticeClusterPeering.
@@ -115,6 +174,19 @@ type LatticeClusterPeeringStatus struct {
 	// RemoteCIDR is the ActiveCIDR of the remote network, populated once Ready.
 	RemoteCIDR string `json:"remoteCIDR,omitempty"`
 
+	// MappedLocalCIDR is the range the remote cluster uses to reach the local
+	// network: LocalVirtualCIDR when set, LocalCIDR otherwise.
+	MappedLocalCIDR string `json:"mappedLocalCIDR,omitempty"`
+
+	// MappedRemoteCIDR is the range local peers use to reach the remote
+	// network: RemoteVirtualCIDR when set, RemoteCIDR otherwise.
+	MappedRemoteCIDR string `json:"mappedRemoteCIDR,omitempty"`
+
+	// LocalGateway is the gateway peer of the local network currently
+	// carrying the peering. The management API reports it to the remote
+	// cluster as the gateway of the network.
+	LocalGateway string `json:"localGateway,omitempty"`
+
 	// Conditions contains fine-grained status conditions.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
@@ -125,6 +197,7 @@ type LatticeClusterPeeringStatus struct {
 // +kubebuilder:printcolumn:name="PHASE",type="string",JSONPath=".status.phase"
 // +kubebuilder:printcolumn:name="LOCAL-CIDR",type="string",JSONPath=".status.localCIDR"
 // +kubebuilder:printcolumn:name="REMOTE-CIDR",type="string",JSONPath=".status.remoteCIDR"
+// +kubebuilder:printcolumn:name="MAPPED-REMOTE",type="string",JSONPath=".status.mappedRemoteCIDR",priority=1
 // +kubebuilder:printcolumn:name="REMOTE-CLUSTER",type="string",JSONPath=".spec.remoteCluster"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 
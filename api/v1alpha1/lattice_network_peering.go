@@ -16,6 +16,27 @@ const (
 	PeeringModeMesh PeeringMode = "mesh"
 )
 
+// GatewayMode controls how a network with several gateway peers uses them
+// in gateway mode.
+// +kubebuilder:validation:Enum=active-standby;active-active
+type GatewayMode string
+
+const (
+	// GatewayModeActiveStandby routes all traffic through one healthy
+	// gateway; the others take over when it stops sending heartbeats.
+	GatewayModeActiveStandby GatewayMode = "active-standby"
+
+	// GatewayModeActiveActive spreads the peers of a network across all
+	// healthy gateways by hashing their names, so each peer keeps using
+	// the same gateway while the group is unchanged.
+	GatewayModeActiveActive GatewayMode = "active-active"
+)
+
+// AnnotationGatewayHeartbeat is set on gateway peers to the RFC 3339 time
+// of their last heartbeat. Gateways are elected among the peers whose
+// heartbeat is recent.
+const AnnotationGatewayHeartbeat = "alattice.io/gateway-heartbeat"
+
 // LatticeNetworkPeeringSpec declares a peering relationship between two networks
 // that may reside in different namespaces (workspaces).
 type LatticeNetworkPeeringSpec struct {
@@ -33,6 +54,23 @@ type LatticeNetworkPeeringSpec struct {
 	// Defaults to "gateway".
 	// +kubebuilder:default=gateway
 	PeeringMode PeeringMode `json:"peeringMode,omitempty"`
+
+	// GatewayMode controls how the gateway peers of each network are used
+	// when there are several. Defaults to "active-standby".
+	// +kubebuilder:default=active-standby
+	// +optional
+	GatewayMode GatewayMode `json:"gatewayMode,omitempty"`
+
+	// VirtualCIDRA is the range NetworkA is presented as to NetworkB. When set,
+	// GatewayA translates between the ActiveCIDR of NetworkA and this range
+	// with a 1:1 prefix mapping, so it must have the same prefix length.
+	// Required when the two networks overlap. Gateway mode only.
+	// +optional
+	VirtualCIDRA string `json:"virtualCIDRA,omitempty"`
+
+	// VirtualCIDRB is the range NetworkB is presented as to NetworkA.
+	// +optional
+	VirtualCIDRB string `json:"virtualCIDRB,omitempty"`
 }
 
 // LatticeNetworkPeeringStatus reports the observed state of the peering.
@@ -46,6 +84,31 @@ type LatticeNetworkPeeringStatus struct {
 	// CIDRB is the ActiveCIDR of NetworkB, populated once the peering is Ready.
 	CIDRB string `json:"cidrB,omitempty"`
 
+	// MappedCIDRA is the range NetworkB peers use to reach NetworkA: the
+	// VirtualCIDRA when translation is configured, CIDRA otherwise.
+	MappedCIDRA string `json:"mappedCIDRA,omitempty"`
+
+	// MappedCIDRB is the range NetworkA peers use to reach NetworkB.
+	MappedCIDRB string `json:"mappedCIDRB,omitempty"`
+
+	// Mode is the peering mode currently in effect.
+	Mode PeeringMode `json:"mode,omitempty"`
+
+	// PeersA is the number of NetworkA peers exposed to NetworkB: every peer
+	// in mesh mode, the active gateways in gateway mode.
+	PeersA int32 `json:"peersA,omitempty"`
+
+	// PeersB is the number of NetworkB peers exposed to NetworkA.
+	PeersB int32 `json:"peersB,omitempty"`
+
+	// GatewaysA lists the gateway peers of NetworkA currently carrying
+	// traffic, in gateway mode.
+	GatewaysA []string `json:"gatewaysA,omitempty"`
+
+	// GatewaysB lists the gateway peers of NetworkB currently carrying
+	// traffic, in gateway mode.
+	GatewaysB []string `json:"gatewaysB,omitempty"`
+
 	// Conditions contains fine-grained status conditions.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
@@ -56,7 +119,11 @@ type LatticeNetworkPeeringStatus struct {
 // +kubebuilder:printcolumn:name="PHASE",type="string",JSONPath=".status.phase"
 // +kubebuilder:printcolumn:name="CIDR-A",type="string",JSONPath=".status.cidrA"
 // +kubebuilder:printcolumn:name="CIDR-B",type="string",JSONPath=".status.cidrB"
+// +kubebuilder:printcolumn:name="MAPPED-A",type="string",JSONPath=".status.mappedCIDRA",priority=1
+// +kubebuilder:printcolumn:name="MAPPED-B",type="string",JSONPath=".status.mappedCIDRB",priority=1
 // +kubebuilder:printcolumn:name="MODE",type="string",JSONPath=".spec.peeringMode"
+// +kubebuilder:printcolumn:name="PEERS-A",type="integer",JSONPath=".status.peersA"
+// +kubebuilder:printcolumn:name="PEERS-B",type="integer",JSONPath=".status.peersB"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 
 // LatticeNetworkPeering connects two LatticeNetworks across different workspaces
@@ -15,12 +15,27 @@
 package v1alpha1
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// NetworkLabelKey returns the label that marks a LatticePeer as a member of
+// the named LatticeNetwork. Names too long for a label key are truncated and
+// suffixed with a hash of the full name.
+func NetworkLabelKey(networkName string) string {
+	name := "network-" + networkName
+	if len(name) > 63 {
+		h := sha256.Sum256([]byte(networkName))
+		name = name[:63-9] + "-" + hex.EncodeToString(h[:4])
+	}
+	return "alattice.io/" + name
+}
+
 // LatticeNetworkSpec defines the desired state of LatticeNetwork.
 type LatticeNetworkSpec struct {
 	// name of network
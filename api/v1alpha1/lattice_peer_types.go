@@ -57,6 +57,43 @@ type LatticePeerSpec struct {
 	// WrrpQuicUrl is the QUIC address of the WRRP relay server.
 	// When set, nodes prefer QUIC over TCP for relay traffic.
 	WrrpQuicUrl string `json:"wrrpQuicUrl,omitempty"`
+
+	// Bandwidth caps the peer's throughput through the network.
+	Bandwidth *BandwidthLimit `json:"bandwidth,omitempty"`
+
+	// QoSClass is the class of the peer's traffic that no policy rule
+	// classifies. Defaults to standard.
+	QoSClass QoSClass `json:"qosClass,omitempty"`
+}
+
+// QoSClass is a traffic priority class. Each class maps to a DSCP value and
+// to a priority on the node's WireGuard interface.
+// +kubebuilder:validation:Enum=interactive;standard;bulk
+type QoSClass string
+
+const (
+	// QoSClassInteractive is served first and marked DSCP EF (46).
+	QoSClassInteractive QoSClass = "interactive"
+	// QoSClassStandard is marked DSCP CS0 (0).
+	QoSClassStandard QoSClass = "standard"
+	// QoSClassBulk is served last and marked DSCP CS1 (8).
+	QoSClassBulk QoSClass = "bulk"
+)
+
+// BandwidthLimit caps the throughput of a peer. Zero means unlimited.
+type BandwidthLimit struct {
+	// EgressMbps caps the traffic the peer sends into the network.
+	// +kubebuilder:validation:Minimum=0
+	EgressMbps int32 `json:"egressMbps,omitempty"`
+
+	// IngressMbps caps the traffic the peer receives from the network.
+	// +kubebuilder:validation:Minimum=0
+	IngressMbps int32 `json:"ingressMbps,omitempty"`
+
+	// BurstKB is the number of kilobytes that may be sent at once above the
+	// rate. Defaults to 100 ms of traffic.
+	// +kubebuilder:validation:Minimum=0
+	BurstKB int32 `json:"burstKB,omitempty"`
 }
 
 // LatticePeerStatus defines the observed state of LatticePeer.
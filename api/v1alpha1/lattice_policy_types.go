@@ -48,11 +48,33 @@ type LatticePolicySpec struct {
 type IngressRule struct {
 	From  []PeerSelection     `json:"from,omitempty"` // from what peers connect to the lattice which selected by this policy
 	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+	// RateLimit caps the traffic the rule matches, for each selected peer.
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+	// QoSClass classifies the traffic the rule matches.
+	QoSClass QoSClass `json:"qosClass,omitempty"`
 }
 
 type EgressRule struct {
 	To    []PeerSelection     `json:"to,omitempty"` // to what peers connect to the lattice which selected by this policy
 	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+	// RateLimit caps the traffic the rule matches, for each selected peer.
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+	// QoSClass classifies the traffic the rule matches.
+	QoSClass QoSClass `json:"qosClass,omitempty"`
+}
+
+// RateLimit caps the traffic of a policy rule. Egress rules shape the
+// traffic a selected peer sends; ingress rules drop what a selected peer
+// receives above the rate. Rate limits and classes of DENY policies are
+// ignored.
+type RateLimit struct {
+	// +kubebuilder:validation:Minimum=1
+	Mbps int32 `json:"mbps"`
+
+	// BurstKB is the number of kilobytes that may pass at once above the
+	// rate. Defaults to 100 ms of traffic.
+	// +kubebuilder:validation:Minimum=0
+	BurstKB int32 `json:"burstKB,omitempty"`
 }
 
 type PeerSelection struct {
@@ -67,6 +89,8 @@ type IPBlock struct {
 type NetworkPolicyPort struct {
 	Port     int32  `json:"port,omitempty"`
 	Protocol string `json:"protocol,omitempty"`
+	// EndPort, when set, extends Port to the inclusive range Port..EndPort.
+	EndPort int32 `json:"endPort,omitempty"`
 }
 
 // NetworkPolicyStatus defines the observed state of LatticePolicy.
@@ -44,6 +44,12 @@ type LatticeRelayServerSpec struct {
 	// whose LatticePeers should be configured to use this relay.
 	// An empty list means all namespaces.
 	Namespaces []string `json:"namespaces,omitempty"`
+
+	// MaxPeers caps the number of peers that may use this relay as their home
+	// relay. Once reached, the relay is no longer offered to additional peers.
+	// Zero means unlimited.
+	// +optional
+	MaxPeers int `json:"maxPeers,omitempty"`
 }
 
 // LatticeRelayServerStatus defines the observed state of a LatticeRelayServer.
@@ -63,6 +69,21 @@ type LatticeRelayServerStatus struct {
 	// LastProbeTime is when the relay was last connectivity-tested.
 	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
 
+	// Sessions is the number of live LRP sessions the relay reported in the
+	// last probe. Unset when the relay does not report it.
+	// +optional
+	Sessions *int `json:"sessions,omitempty"`
+
+	// Version is the relay build version reported in the last probe.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// ConsecutiveFailures counts probes in a row in which no endpoint
+	// answered. The relay is marked Offline, and no longer offered to peers,
+	// once it reaches RelayOfflineThreshold.
+	// +optional
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+
 	// Conditions holds standard Kubernetes condition records.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
@@ -86,6 +107,10 @@ const (
 	RelayHealthUnknown  RelayHealth = "Unknown"
 )
 
+// RelayOfflineThreshold is the number of consecutive failed probes after
+// which a relay is marked Offline.
+const RelayOfflineThreshold = 3
+
 // Relay condition types.
 const (
 	RelayConditionReady  = "Ready"
@@ -99,12 +124,17 @@ const RelayFinalizer = "relay.alattice.io/finalizer"
 // given relay, keyed by the relay's metadata.Name.
 const RelayPeerLabel = "relay.alattice.io/name"
 
+// RelayRegionLabel names the region of a relay. Agents prefer relays whose
+// region matches the same label on their LatticePeer.
+const RelayRegionLabel = "topology.kubernetes.io/region"
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster,shortName=wfrelay
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="DISPLAY",type="string",JSONPath=".spec.displayName"
 // +kubebuilder:printcolumn:name="HEALTH",type="string",JSONPath=".status.health"
 // +kubebuilder:printcolumn:name="PEERS",type="integer",JSONPath=".status.connectedPeers"
+// +kubebuilder:printcolumn:name="LATENCY",type="integer",JSONPath=".status.latencyMs",priority=1
 // +kubebuilder:printcolumn:name="TCP",type="string",JSONPath=".spec.tcpUrl"
 // +kubebuilder:printcolumn:name="ENABLED",type="boolean",JSONPath=".spec.enabled"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
@@ -186,6 +216,10 @@ func (in *LatticeRelayServerStatus) DeepCopyInto(out *LatticeRelayServerStatus)
 		x := *in.LatencyMs
 		out.LatencyMs = &x
 	}
+	if in.Sessions != nil {
+		x := *in.Sessions
+		out.Sessions = &x
+	}
 	if in.LastProbeTime != nil {
 		in, out := &in.LastProbeTime, &out.LastProbeTime
 		*out = (*in).DeepCopy()
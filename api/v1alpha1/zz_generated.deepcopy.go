@@ -23,6 +23,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthLimit) DeepCopyInto(out *BandwidthLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthLimit.
+func (in *BandwidthLimit) DeepCopy() *BandwidthLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConnectionSummary) DeepCopyInto(out *ConnectionSummary) {
 	*out = *in
@@ -73,6 +88,11 @@ func (in *EgressRule) DeepCopyInto(out *EgressRule) {
 		*out = make([]NetworkPolicyPort, len(*in))
 		copy(*out, *in)
 	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimit)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressRule.
@@ -131,6 +151,11 @@ func (in *IngressRule) DeepCopyInto(out *IngressRule) {
 		*out = make([]NetworkPolicyPort, len(*in))
 		copy(*out, *in)
 	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimit)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressRule.
@@ -148,7 +173,7 @@ func (in *LatticeCluster) DeepCopyInto(out *LatticeCluster) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -301,6 +326,11 @@ func (in *LatticeClusterPeeringStatus) DeepCopy() *LatticeClusterPeeringStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LatticeClusterSpec) DeepCopyInto(out *LatticeClusterSpec) {
 	*out = *in
+	if in.ProbeInterval != nil {
+		in, out := &in.ProbeInterval, &out.ProbeInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LatticeClusterSpec.
@@ -320,6 +350,19 @@ func (in *LatticeClusterStatus) DeepCopyInto(out *LatticeClusterStatus) {
 		in, out := &in.LastProbeTime, &out.LastProbeTime
 		*out = (*in).DeepCopy()
 	}
+	if in.CredentialRotatedAt != nil {
+		in, out := &in.CredentialRotatedAt, &out.CredentialRotatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CredentialExpiresAt != nil {
+		in, out := &in.CredentialExpiresAt, &out.CredentialExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Gateways != nil {
+		in, out := &in.Gateways, &out.Gateways
+		*out = make([]RemoteGateway, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -840,6 +883,16 @@ func (in *LatticeNetworkPeeringSpec) DeepCopy() *LatticeNetworkPeeringSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LatticeNetworkPeeringStatus) DeepCopyInto(out *LatticeNetworkPeeringStatus) {
 	*out = *in
+	if in.GatewaysA != nil {
+		in, out := &in.GatewaysA, &out.GatewaysA
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GatewaysB != nil {
+		in, out := &in.GatewaysB, &out.GatewaysB
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -991,6 +1044,11 @@ func (in *LatticePeerSpec) DeepCopyInto(out *LatticePeerSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		*out = new(BandwidthLimit)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LatticePeerSpec.
@@ -1261,3 +1319,33 @@ func (in *PeerSelection) DeepCopy() *PeerSelection {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimit) DeepCopyInto(out *RateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimit.
+func (in *RateLimit) DeepCopy() *RateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteGateway) DeepCopyInto(out *RemoteGateway) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteGateway.
+func (in *RemoteGateway) DeepCopy() *RemoteGateway {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteGateway)
+	in.DeepCopyInto(out)
+	return out
+}
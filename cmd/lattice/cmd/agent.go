@@ -0,0 +1,169 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/localapi"
+
+	"github.com/spf13/cobra"
+)
+
+// agentCmd groups operations on the locally running agent. All of them go
+// through the agent's control socket; none contact the management server.
+func agentCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "agent <sub-command>",
+		Short: "Control the locally running agent",
+		Long: `Talk to the running agent over its local control socket
+(/var/run/lattice/<interface>.sock).`,
+		Args: cobra.MinimumNArgs(1),
+	}
+	c.AddCommand(
+		agentReconnectCmd(),
+		agentReloadCmd(),
+		agentLogLevelCmd(),
+		agentConfigCmd(),
+		agentEventsCmd(),
+	)
+	return c
+}
+
+func localClient() (*localapi.Client, error) {
+	path, err := localapi.FindSocket(config.Conf.InterfaceName)
+	if err != nil {
+		return nil, err
+	}
+	return localapi.NewClient(path), nil
+}
+
+func requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), 10*time.Second)
+}
+
+func agentReconnectCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "reconnect <peer-app-id>",
+		Short:   "Force the agent to re-establish the connection to a peer",
+		Example: `  lattice agent reconnect node-b`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			client, err := localClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := requestContext()
+			defer cancel()
+			if err = client.Reconnect(ctx, args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("reconnecting to %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func agentReloadCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reload",
+		Short: "Re-fetch and apply the network map from the management server",
+		RunE: func(c *cobra.Command, args []string) error {
+			client, err := localClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := requestContext()
+			defer cancel()
+			if err = client.Reload(ctx); err != nil {
+				return err
+			}
+			fmt.Println("configuration reloaded")
+			return nil
+		},
+	}
+}
+
+func agentLogLevelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "log-level [debug|info|warn|error]",
+		Short: "Show or change the agent log level without restarting it",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			client, err := localClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := requestContext()
+			defer cancel()
+			if len(args) == 1 {
+				return client.SetLogLevel(ctx, args[0])
+			}
+			level, err := client.LogLevel(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Println(level)
+			return nil
+		},
+	}
+}
+
+func agentConfigCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "config",
+		Short: "Print the network map last applied by the agent (secrets removed)",
+		RunE: func(c *cobra.Command, args []string) error {
+			client, err := localClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := requestContext()
+			defer cancel()
+			msg, err := client.Config(ctx)
+			if err != nil {
+				return err
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(msg)
+		},
+	}
+}
+
+func agentEventsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "events",
+		Short: "Stream agent events (path changes, config applied, log level) as JSON lines",
+		RunE: func(c *cobra.Command, args []string) error {
+			client, err := localClient()
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			enc := json.NewEncoder(os.Stdout)
+			return client.Events(ctx, func(ev localapi.Event) error {
+				return enc.Encode(ev)
+			})
+		},
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alatticeio/lattice/internal/agent/client"
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/server/dto"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+func newAdminClient() (*cmd.Client, error) {
+	return cmd.NewClient(config.Conf.SignalingURL, config.Conf.APIToken)
+}
+
+// exportCmd: lattice export -n <namespace> [-o file]
+func exportCmd() *cobra.Command {
+	var namespace, output string
+	c := &cobra.Command{
+		Use:   "export",
+		Short: "Write a workspace's configuration as a YAML bundle",
+		Long: `Export the networks, policies, peer labels, relays and alert rules of a
+workspace as one YAML bundle. Commit it to git, review changes in pull
+requests, and reconcile the workspace with 'lattice apply'.`,
+		Example: `  lattice export -n <namespace> -o workspace.yaml`,
+		RunE: func(c *cobra.Command, args []string) error {
+			if namespace == "" {
+				return fmt.Errorf("namespace is required (-n <namespace>)\n  run 'lattice workspace list' to see available namespaces")
+			}
+			client, err := newAdminClient()
+			if err != nil {
+				return err
+			}
+			bundle, err := client.ExportBundle(namespace)
+			if err != nil {
+				return err
+			}
+			out, err := yaml.Marshal(bundle)
+			if err != nil {
+				return err
+			}
+			if output == "" || output == "-" {
+				_, err = os.Stdout.Write(out)
+				return err
+			}
+			return os.WriteFile(output, out, 0o644)
+		},
+	}
+	c.Flags().StringVarP(&namespace, "namespace", "n", "", "workspace namespace (required)")
+	c.Flags().StringVarP(&output, "output", "o", "", "file to write (default: stdout)")
+	return c
+}
+
+// diffCmd: lattice diff -f <file> [-n <namespace>] [--prune]
+func diffCmd() *cobra.Command {
+	var flags bundleFlags
+	var exitCode bool
+	c := &cobra.Command{
+		Use:   "diff",
+		Short: "Show what 'lattice apply' would change",
+		Example: `  lattice diff -f workspace.yaml
+  lattice diff -f workspace.yaml --prune --exit-code`,
+		RunE: func(c *cobra.Command, args []string) error {
+			namespace, bundle, err := flags.load()
+			if err != nil {
+				return err
+			}
+			client, err := newAdminClient()
+			if err != nil {
+				return err
+			}
+			n, err := client.DiffBundle(namespace, bundle, flags.prune)
+			if err != nil {
+				return err
+			}
+			if exitCode && n > 0 {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+	flags.register(c)
+	c.Flags().BoolVar(&exitCode, "exit-code", false, "exit with status 1 when there are changes")
+	return c
+}
+
+// applyCmd: lattice apply -f <file> [-n <namespace>] [--prune]
+func applyCmd() *cobra.Command {
+	var flags bundleFlags
+	c := &cobra.Command{
+		Use:   "apply",
+		Short: "Reconcile a workspace with a YAML bundle",
+		Long: `Create and update the objects of a bundle written by 'lattice export'.
+Applying the same bundle twice changes nothing.
+
+With --prune, policies and alert rules missing from the bundle are deleted,
+as are relays that serve only this workspace. Networks and peers are never
+deleted, and peers that have not enrolled yet are skipped.`,
+		Example: `  lattice apply -f workspace.yaml
+  lattice apply -f workspace.yaml -n <other-namespace> --prune`,
+		RunE: func(c *cobra.Command, args []string) error {
+			namespace, bundle, err := flags.load()
+			if err != nil {
+				return err
+			}
+			client, err := newAdminClient()
+			if err != nil {
+				return err
+			}
+			return client.ApplyBundle(namespace, bundle, flags.prune)
+		},
+	}
+	flags.register(c)
+	return c
+}
+
+// bundleFlags are the flags shared by diff and apply.
+type bundleFlags struct {
+	file, namespace string
+	prune           bool
+}
+
+func (f *bundleFlags) register(c *cobra.Command) {
+	c.Flags().StringVarP(&f.file, "filename", "f", "", "bundle file, or - for stdin (required)")
+	c.Flags().StringVarP(&f.namespace, "namespace", "n", "", "workspace namespace (default: the bundle's namespace)")
+	c.Flags().BoolVar(&f.prune, "prune", false, "delete objects missing from the bundle")
+}
+
+// load reads the bundle and resolves the target namespace.
+func (f *bundleFlags) load() (string, *dto.Bundle, error) {
+	if f.file == "" {
+		return "", nil, fmt.Errorf("a bundle file is required (-f <file>)")
+	}
+	var data []byte
+	var err error
+	if f.file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(f.file)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	var bundle dto.Bundle
+	if err = yaml.UnmarshalStrict(data, &bundle); err != nil {
+		return "", nil, fmt.Errorf("%s: %w", f.file, err)
+	}
+	if bundle.Kind != dto.BundleKind {
+		return "", nil, fmt.Errorf("%s: kind must be %s", f.file, dto.BundleKind)
+	}
+	namespace := f.namespace
+	if namespace == "" {
+		namespace = bundle.Namespace
+	}
+	if namespace == "" {
+		return "", nil, fmt.Errorf("namespace is required (-n <namespace>): the bundle names none")
+	}
+	return namespace, &bundle, nil
+}
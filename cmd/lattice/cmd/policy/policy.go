@@ -17,6 +17,8 @@ package policy
 
 import (
 	"fmt"
+	"os"
+
 	"github.com/alatticeio/lattice/internal/agent/client"
 	"github.com/alatticeio/lattice/internal/agent/config"
 
@@ -38,12 +40,13 @@ until an ALLOW policy is explicitly created for their workspace.`,
 		policyAllowAllCmd(),
 		policyRemoveCmd(),
 		policyListCmd(),
+		policyLintCmd(),
 	)
 	return c
 }
 
 func newClient() (*cmd.Client, error) {
-	return cmd.NewClient(config.Conf.SignalingURL)
+	return cmd.NewClient(config.Conf.SignalingURL, config.Conf.APIToken)
 }
 
 // policyAddCmd: lattice policy add <name> -n <namespace> [flags]
@@ -150,3 +153,40 @@ func policyListCmd() *cobra.Command {
 	c.Flags().StringVarP(&namespace, "namespace", "n", "", "workspace namespace (required)")
 	return c
 }
+
+// policyLintCmd: lattice policy lint -n <namespace>
+func policyLintCmd() *cobra.Command {
+	var namespace string
+	var exitCode bool
+	c := &cobra.Command{
+		Use:   "lint",
+		Short: "Check the policies of a workspace for mistakes",
+		Long: `Check the policies of a workspace for shadowed rules, ALLOW/DENY
+conflicts, selectors that match no peers, ipBlocks outside the network
+CIDRs, references to missing networks and peers that nothing can reach.`,
+		Example: `  lattice policy lint -n wf-550e8400-e29b-41d4-a716-446655440000
+
+  # fail a CI job when there are findings
+  lattice policy lint -n <namespace> --exit-code`,
+		RunE: func(c *cobra.Command, args []string) error {
+			if namespace == "" {
+				return fmt.Errorf("namespace is required (-n <namespace>)")
+			}
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			n, err := client.LintPolicies(namespace)
+			if err != nil {
+				return err
+			}
+			if exitCode && n > 0 {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+	c.Flags().StringVarP(&namespace, "namespace", "n", "", "workspace namespace (required)")
+	c.Flags().BoolVar(&exitCode, "exit-code", false, "exit with status 1 when there are findings")
+	return c
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"github.com/alatticeio/lattice/cmd/lattice/cmd/peer"
 	"github.com/alatticeio/lattice/cmd/lattice/cmd/policy"
+	"github.com/alatticeio/lattice/cmd/lattice/cmd/serviceaccount"
 	"github.com/alatticeio/lattice/cmd/lattice/cmd/token"
 	"github.com/alatticeio/lattice/cmd/lattice/cmd/workspace"
 	"github.com/alatticeio/lattice/internal/agent/config"
@@ -75,14 +76,21 @@ func init() {
 	fs.StringP("config-dir", "", "", "config directory (default: ~/.lattice)")
 	fs.StringP("server-url", "", "", "management server URL")
 	fs.StringP("signaling-url", "", "", "signaling server URL")
+	fs.StringP("api-token", "", "", "API token for admin commands (or LATTICE_API_TOKEN)")
 	fs.BoolP("version", "", false, "print version information")
 	fs.BoolP("save", "", false, "persist flags to config file")
 
 	rootCmd.AddCommand(initCmd())
 	rootCmd.AddCommand(upCmd())
+	rootCmd.AddCommand(downCmd())
 	rootCmd.AddCommand(statusCmd())
+	rootCmd.AddCommand(agentCmd())
 	rootCmd.AddCommand(token.NewTokenCommand())
 	rootCmd.AddCommand(workspace.NewWorkspaceCommand())
 	rootCmd.AddCommand(policy.NewPolicyCommand())
 	rootCmd.AddCommand(peer.NewPeerCommand())
+	rootCmd.AddCommand(serviceaccount.NewServiceAccountCommand())
+	rootCmd.AddCommand(exportCmd())
+	rootCmd.AddCommand(diffCmd())
+	rootCmd.AddCommand(applyCmd())
 }
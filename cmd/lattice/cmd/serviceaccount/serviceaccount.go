@@ -0,0 +1,220 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package serviceaccount provides CLI commands for workspace service accounts.
+package serviceaccount
+
+import (
+	"fmt"
+
+	"github.com/alatticeio/lattice/internal/agent/client"
+	"github.com/alatticeio/lattice/internal/agent/config"
+
+	"github.com/spf13/cobra"
+)
+
+// NewServiceAccountCommand returns the top-level "service-account" command.
+func NewServiceAccountCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:     "service-account <sub-command>",
+		Short:   "Manage workspace service accounts and their API tokens",
+		Aliases: []string{"sa"},
+		Long: `Service accounts are non-human workspace members for automation such as
+Terraform or CI. They authenticate with API tokens instead of a login.
+
+Managing service accounts requires an API token of a workspace admin,
+set with --api-token or LATTICE_API_TOKEN. Create a personal one in the
+Dashboard under API tokens.`,
+		Args: cobra.MinimumNArgs(1),
+	}
+	c.AddCommand(
+		saCreateCmd(),
+		saListCmd(),
+		saDeleteCmd(),
+		saTokenCmd(),
+	)
+	return c
+}
+
+func newClient() (*cmd.Client, error) {
+	return cmd.NewClient(config.Conf.SignalingURL, config.Conf.APIToken)
+}
+
+func requireNamespace(namespace string) error {
+	if namespace == "" {
+		return fmt.Errorf("namespace is required (-n <namespace>)\n  run 'lattice workspace list' to see available namespaces")
+	}
+	return nil
+}
+
+// saCreateCmd: lattice service-account create <name> -n <namespace> --role <role>
+func saCreateCmd() *cobra.Command {
+	var namespace, role, description string
+	c := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a service account",
+		Long: `Create a service account with a workspace role: admin, editor, member or viewer.
+Pick the lowest role the automation needs.`,
+		Example: `  lattice service-account create terraform -n <namespace> --role editor
+  lattice service-account create dashboards -n <namespace> --role viewer --desc "read-only exporter"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := requireNamespace(namespace); err != nil {
+				return err
+			}
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			return client.CreateServiceAccount(namespace, args[0], role, description)
+		},
+	}
+	c.Flags().StringVarP(&namespace, "namespace", "n", "", "workspace namespace (required)")
+	c.Flags().StringVar(&role, "role", "viewer", "workspace role: admin, editor, member or viewer")
+	c.Flags().StringVar(&description, "desc", "", "human-readable description")
+	return c
+}
+
+// saListCmd: lattice service-account list -n <namespace>
+func saListCmd() *cobra.Command {
+	var namespace string
+	c := &cobra.Command{
+		Use:     "list",
+		Short:   "List the service accounts of a workspace",
+		Aliases: []string{"ls"},
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := requireNamespace(namespace); err != nil {
+				return err
+			}
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			return client.ListServiceAccounts(namespace)
+		},
+	}
+	c.Flags().StringVarP(&namespace, "namespace", "n", "", "workspace namespace (required)")
+	return c
+}
+
+// saDeleteCmd: lattice service-account delete <name> -n <namespace>
+func saDeleteCmd() *cobra.Command {
+	var namespace string
+	c := &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "Delete a service account and revoke its tokens",
+		Aliases: []string{"rm", "remove"},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := requireNamespace(namespace); err != nil {
+				return err
+			}
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			return client.DeleteServiceAccount(namespace, args[0])
+		},
+	}
+	c.Flags().StringVarP(&namespace, "namespace", "n", "", "workspace namespace (required)")
+	return c
+}
+
+// saTokenCmd: lattice service-account token <create|list|revoke>
+func saTokenCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "token <sub-command>",
+		Short: "Manage the API tokens of a service account",
+		Args:  cobra.MinimumNArgs(1),
+	}
+	c.AddCommand(saTokenCreateCmd(), saTokenListCmd(), saTokenRevokeCmd())
+	return c
+}
+
+func saTokenCreateCmd() *cobra.Command {
+	var (
+		namespace, role string
+		expiresInDays   int
+	)
+	c := &cobra.Command{
+		Use:   "create <service-account> <token-name>",
+		Short: "Create an API token for a service account",
+		Long: `Create an API token for a service account. The token is printed once.
+Use it as "Authorization: Bearer <token>" against the REST API, or as
+--api-token / LATTICE_API_TOKEN with the lattice CLI.`,
+		Example: `  lattice service-account token create terraform ci -n <namespace> --expires-in-days 30
+
+  # read-only token for an editor account
+  lattice service-account token create terraform plan -n <namespace> --role viewer`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := requireNamespace(namespace); err != nil {
+				return err
+			}
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			return client.CreateServiceAccountToken(namespace, args[0], args[1], role, expiresInDays)
+		},
+	}
+	c.Flags().StringVarP(&namespace, "namespace", "n", "", "workspace namespace (required)")
+	c.Flags().StringVar(&role, "role", "", "cap the token below the account's role (default: the account's role)")
+	c.Flags().IntVar(&expiresInDays, "expires-in-days", 0, "token lifetime in days; 0 uses the server default, -1 never expires")
+	return c
+}
+
+func saTokenListCmd() *cobra.Command {
+	var namespace string
+	c := &cobra.Command{
+		Use:     "list <service-account>",
+		Short:   "List the tokens of a service account",
+		Aliases: []string{"ls"},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := requireNamespace(namespace); err != nil {
+				return err
+			}
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			return client.ListServiceAccountTokens(namespace, args[0])
+		},
+	}
+	c.Flags().StringVarP(&namespace, "namespace", "n", "", "workspace namespace (required)")
+	return c
+}
+
+func saTokenRevokeCmd() *cobra.Command {
+	var namespace string
+	c := &cobra.Command{
+		Use:     "revoke <service-account> <token-id>",
+		Short:   "Revoke a service account token",
+		Example: `  lattice service-account token revoke terraform 0d6c3f0e-... -n <namespace>`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := requireNamespace(namespace); err != nil {
+				return err
+			}
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			return client.RevokeServiceAccountToken(namespace, args[0], args[1])
+		},
+	}
+	c.Flags().StringVarP(&namespace, "namespace", "n", "", "workspace namespace (required)")
+	return c
+}
@@ -22,7 +22,8 @@ import (
 )
 
 func statusCmd() *cobra.Command {
-	return &cobra.Command{
+	var peers bool
+	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show the current node status and connected peers",
 		Long: `Display the WireGuard interface information and the status of all peers.
@@ -45,9 +46,18 @@ Example output:
     Endpoint  : 203.0.113.1:51820
     Handshake : 12 seconds ago
     Traffic   : ↑ 1.2 MB  ↓ 3.4 MB
-    Status    : connected`,
+    Status    : connected
+
+With --peers, the running agent is asked how each peer is reached: direct
+(with the selected ICE candidate pair, e.g. host -> srflx) or relayed, the
+measured RTT, and the recent history of upgrades, downgrades and failures.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if peers {
+				return agent.PeerStatus(config.Conf)
+			}
 			return agent.Status(config.Conf)
 		},
 	}
+	cmd.Flags().BoolVar(&peers, "peers", false, "show per-peer connection path (direct/relay), RTT and history")
+	return cmd
 }
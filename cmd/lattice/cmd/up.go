@@ -95,6 +95,9 @@ First time? Run "lattice init" to set up your config interactively.`,
 			if ep, _ := cmd.Flags().GetString("vm-endpoint"); ep != "" {
 				config.Conf.Telemetry.VMEndpoint = ep
 			}
+			if tr, _ := cmd.Flags().GetString("telemetry-transport"); tr != "" {
+				config.Conf.Telemetry.Transport = tr
+			}
 			if em, _ := cmd.Flags().GetBool("enable-metric"); em {
 				config.Conf.EnableMetric = em
 			}
@@ -106,12 +109,14 @@ First time? Run "lattice init" to set up your config interactively.`,
 	fs := cmd.Flags()
 	fs.StringP("token", "", "", "enrollment token to authenticate and join a workspace")
 	fs.StringP("level", "", "", "log level: debug, info, warn, error")
-	fs.StringP("relay-url", "", "", "TCP relay server URL (required when --enable-wrrp)")
-	fs.StringP("relay-quic-url", "", "", "QUIC relay server address (e.g. server:6267)")
+	fs.StringP("relay-url", "", "", "TCP relay server URL; pins the agent to this relay (default: pick from relays offered by the server)")
+	fs.StringP("relay-quic-url", "", "", "QUIC relay server address (e.g. server:6267); pins the agent to this relay")
 	fs.BoolP("enable-wrrp", "", false, "use WRRP relay for NAT traversal")
 	fs.StringP("vm-endpoint", "", "", "use to push tele")
 	fs.BoolP("enable-metric", "", false, "expose Prometheus metrics endpoint")
+	fs.StringP("telemetry-transport", "", "", "telemetry transport: http (push to --vm-endpoint) or nats (send through the management server)")
 	fs.BoolP("enable-sys-log", "", false, "enable verbose WireGuard and ICE debug logging")
 	fs.IntP("wg-port", "", 51820, "UDP port for WireGuard and ICE (default 51820)")
+	fs.IntP("flow-log-interval", "", 60, "seconds between flow log reports to the manager; 0 disables them")
 	return cmd
 }
@@ -69,6 +69,7 @@ func newControllerCmd() *cobra.Command {
 	fs.StringP("metrics-cert-key", ",", "tls.key", "The name of the metrics server key file.")
 	fs.BoolP("enable-http2", "", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	fs.StringP("relay-secret", "", "", "secret shared with the relays to sign the relay tickets of peers")
 
 	return cmd
 }
@@ -15,6 +15,8 @@
 package cmd
 
 import (
+	"context"
+
 	"github.com/alatticeio/lattice/internal/agent/config"
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"github.com/alatticeio/lattice/internal/relay"
@@ -34,6 +36,14 @@ func newWrrpCmd() *cobra.Command {
 			// PersistentPreRunE overrides parent's, so we call LoadConf here.
 			_ = cfgManager.Viper().BindPFlag("listen", cmd.Flags().Lookup("addr"))
 			_ = cfgManager.Viper().BindPFlag("relay-quic-url", cmd.Flags().Lookup("quic-addr"))
+			_ = cfgManager.Viper().BindPFlag("relay-mesh-addr", cmd.Flags().Lookup("mesh-addr"))
+			_ = cfgManager.Viper().BindPFlag("relay-mesh-advertise", cmd.Flags().Lookup("mesh-advertise"))
+			_ = cfgManager.Viper().BindPFlag("relay-session-bandwidth", cmd.Flags().Lookup("session-bandwidth"))
+			_ = cfgManager.Viper().BindPFlag("relay-session-frame-rate", cmd.Flags().Lookup("session-frame-rate"))
+			_ = cfgManager.Viper().BindPFlag("relay-namespace-bandwidth", cmd.Flags().Lookup("namespace-bandwidth"))
+			_ = cfgManager.Viper().BindPFlag("relay-namespace-frame-rate", cmd.Flags().Lookup("namespace-frame-rate"))
+			_ = cfgManager.Viper().BindPFlag("relay-admin-addr", cmd.Flags().Lookup("admin-addr"))
+			_ = cfgManager.Viper().BindPFlag("relay-admin-token", cmd.Flags().Lookup("admin-token"))
 			return cfgManager.LoadConf(cmd)
 		},
 
@@ -46,6 +56,16 @@ func newWrrpCmd() *cobra.Command {
 	fs.BoolP("enable-tls", "", false, "using tls")
 	fs.StringP("level", "", "silent", "log level (debug, info, warn, error)")
 	fs.StringP("quic-addr", "", "", "QUIC relay listen address (e.g. :6267)")
+	fs.StringP("mesh-addr", "", "", "relay mesh listen address (e.g. :6268)")
+	fs.StringP("mesh-advertise", "", "", "relay mesh address announced to other relays")
+	fs.StringP("relay-id", "", "", "relay mesh instance ID (default hostname)")
+	fs.Int64P("session-bandwidth", "", 0, "per-session relay bandwidth limit in bytes/s; 0 is unlimited")
+	fs.IntP("session-frame-rate", "", 0, "per-session relay frame rate limit in frames/s; 0 is unlimited")
+	fs.Int64P("namespace-bandwidth", "", 0, "per-namespace relay bandwidth limit in bytes/s; 0 is unlimited")
+	fs.IntP("namespace-frame-rate", "", 0, "per-namespace relay frame rate limit in frames/s; 0 is unlimited")
+	fs.StringP("admin-addr", "", "", "session admin API listen address (e.g. 127.0.0.1:6269); empty disables")
+	fs.StringP("admin-token", "", "", "bearer token required by the session admin API")
+	fs.StringP("relay-secret", "", "", "secret shared with the manager to verify relay tickets; empty accepts peers without one")
 	return cmd
 }
 
@@ -68,5 +88,36 @@ func runWrrp(flags *config.Config) error {
 		}
 	}
 
+	if flags.RelayMeshAddr != "" {
+		mesh, err := relay.NewMesh(relay.MeshConfig{
+			RelayID:       flags.RelayID,
+			ListenAddr:    flags.RelayMeshAddr,
+			AdvertiseAddr: flags.RelayMeshAdvertise,
+			NatsURL:       flags.SignalingURL,
+			Secret:        flags.RelaySecret,
+		}, server.Manager())
+		if err != nil {
+			return err
+		}
+		go mesh.Run(context.Background())
+	}
+
+	// The relay and mesh counters live on the default registry.
+	if flags.MetricsAddr != "" {
+		go func() {
+			if err := relay.ServeMetrics(flags.MetricsAddr); err != nil {
+				log.GetLogger("wrrp").Error("metrics server error", err)
+			}
+		}()
+	}
+
+	if flags.RelayAdminAddr != "" {
+		go func() {
+			if err := relay.ServeAdmin(flags.RelayAdminAddr, server.Manager(), flags.RelayAdminToken); err != nil {
+				log.GetLogger("wrrp").Error("admin server error", err)
+			}
+		}()
+	}
+
 	return server.Start()
 }
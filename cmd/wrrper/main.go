@@ -19,6 +19,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/alatticeio/lattice/internal/agent/config"
 	"github.com/alatticeio/lattice/internal/agent/log"
@@ -40,6 +41,14 @@ func main() {
 			// Map renamed server flags to their viper keys before config loading.
 			_ = cfgManager.Viper().BindPFlag("listen", cmd.Flags().Lookup("addr"))
 			_ = cfgManager.Viper().BindPFlag("relay-quic-url", cmd.Flags().Lookup("quic-addr"))
+			_ = cfgManager.Viper().BindPFlag("relay-mesh-addr", cmd.Flags().Lookup("mesh-addr"))
+			_ = cfgManager.Viper().BindPFlag("relay-mesh-advertise", cmd.Flags().Lookup("mesh-advertise"))
+			_ = cfgManager.Viper().BindPFlag("relay-session-bandwidth", cmd.Flags().Lookup("session-bandwidth"))
+			_ = cfgManager.Viper().BindPFlag("relay-session-frame-rate", cmd.Flags().Lookup("session-frame-rate"))
+			_ = cfgManager.Viper().BindPFlag("relay-namespace-bandwidth", cmd.Flags().Lookup("namespace-bandwidth"))
+			_ = cfgManager.Viper().BindPFlag("relay-namespace-frame-rate", cmd.Flags().Lookup("namespace-frame-rate"))
+			_ = cfgManager.Viper().BindPFlag("relay-admin-addr", cmd.Flags().Lookup("admin-addr"))
+			_ = cfgManager.Viper().BindPFlag("relay-admin-token", cmd.Flags().Lookup("admin-token"))
 			return cfgManager.LoadConf(cmd)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -53,6 +62,18 @@ func main() {
 	fs.BoolP("enable-tls", "", false, "enable TLS on TCP listener")
 	fs.StringP("quic-addr", "", "", "QUIC relay listen address (e.g. :6267); empty disables QUIC")
 	fs.StringP("level", "", "info", "log level: debug, info, warn, error, silent")
+	fs.StringP("mesh-addr", "", "", "relay mesh listen address (e.g. :6268); empty disables the mesh")
+	fs.StringP("mesh-advertise", "", "", "relay mesh address announced to other relays (default: mesh-addr)")
+	fs.StringP("relay-id", "", "", "relay mesh instance ID (default: hostname)")
+	fs.StringP("signaling-url", "", "", "NATS URL shared by the relay mesh")
+	fs.StringP("metrics-addr", "", ":8443", "Prometheus metrics listen address; empty disables")
+	fs.Int64P("session-bandwidth", "", 0, "per-session relay bandwidth limit in bytes/s; 0 is unlimited")
+	fs.IntP("session-frame-rate", "", 0, "per-session relay frame rate limit in frames/s; 0 is unlimited")
+	fs.Int64P("namespace-bandwidth", "", 0, "per-namespace relay bandwidth limit in bytes/s; 0 is unlimited")
+	fs.IntP("namespace-frame-rate", "", 0, "per-namespace relay frame rate limit in frames/s; 0 is unlimited")
+	fs.StringP("admin-addr", "", "", "session admin API listen address (e.g. 127.0.0.1:6269); empty disables")
+	fs.StringP("admin-token", "", "", "bearer token required by the session admin API")
+	fs.StringP("relay-secret", "", "", "secret shared with the manager to verify relay tickets; empty accepts peers without one")
 
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
@@ -79,5 +100,35 @@ func run(flags *config.Config) error {
 		}
 	}
 
+	if flags.RelayMeshAddr != "" {
+		mesh, err := relay.NewMesh(relay.MeshConfig{
+			RelayID:       flags.RelayID,
+			ListenAddr:    flags.RelayMeshAddr,
+			AdvertiseAddr: flags.RelayMeshAdvertise,
+			NatsURL:       flags.SignalingURL,
+			Secret:        flags.RelaySecret,
+		}, server.Manager())
+		if err != nil {
+			return err
+		}
+		go mesh.Run(context.Background())
+	}
+
+	if flags.MetricsAddr != "" {
+		go func() {
+			if err := relay.ServeMetrics(flags.MetricsAddr); err != nil {
+				log.GetLogger("wrrper").Error("metrics server stopped", err)
+			}
+		}()
+	}
+
+	if flags.RelayAdminAddr != "" {
+		go func() {
+			if err := relay.ServeAdmin(flags.RelayAdminAddr, server.Manager(), flags.RelayAdminToken); err != nil {
+				log.GetLogger("wrrper").Error("admin server stopped", err)
+			}
+		}()
+	}
+
 	return server.Start()
 }
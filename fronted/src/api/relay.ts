@@ -10,6 +10,8 @@ export interface RelayServer {
   enabled: boolean
   status?: 'healthy' | 'degraded' | 'offline' | 'unknown'
   latencyMs?: number
+  sessions?: number       // live LRP sessions reported by the relay
+  version?: string        // relay build version
   connectedPeers?: number
   workspaces?: string[]   // workspace slugs that use this relay
   peerLabels?: string[]   // labels applied to peers using this relay, e.g. ["relay=asia-hk"]
@@ -26,11 +26,22 @@ import (
 
 // call sends a NATS request to "lattice.signals.service.<method>" and returns
 // the raw JSON response body, or an error if the server returned one.
+// The configured API token is added to the payload as "api_token".
 func (c *Client) call(method string, payload any) ([]byte, error) {
 	bs, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
 	}
+	if c.apiToken != "" {
+		fields := map[string]any{}
+		if err = json.Unmarshal(bs, &fields); err != nil {
+			return nil, err
+		}
+		fields["api_token"] = c.apiToken
+		if bs, err = json.Marshal(fields); err != nil {
+			return nil, err
+		}
+	}
 	return c.client.Request(context.Background(), "lattice.signals.service", method, bs)
 }
 
@@ -111,6 +122,9 @@ func (c *Client) AddPolicy(namespace, name, action, description string) error {
 	fmt.Printf("policy %q applied\n", p.Name)
 	fmt.Printf("  action:  %s\n", p.Action)
 	fmt.Printf("  types:   %s\n", strings.Join(p.PolicyTypes, ", "))
+	for _, w := range p.Warnings {
+		fmt.Printf("  warning: %s: %s\n", w.Rule, w.Description)
+	}
 	return nil
 }
 
@@ -166,6 +180,29 @@ func (c *Client) ListPolicies(namespace string) error {
 	return w.Flush()
 }
 
+// LintPolicies prints the lint findings of the policies in the given
+// namespace and returns how many there are.
+func (c *Client) LintPolicies(namespace string) (int, error) {
+	data, err := c.call("policy.lint", map[string]string{"namespace": namespace})
+	if err != nil {
+		return 0, err
+	}
+	var findings []vo.AuditFinding
+	if err = json.Unmarshal(data, &findings); err != nil {
+		return 0, err
+	}
+	if len(findings) == 0 {
+		fmt.Printf("no issues found in namespace %q\n", namespace)
+		return 0, nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "SEVERITY\tRULE\tRESOURCE\tDESCRIPTION") //nolint:errcheck
+	for _, f := range findings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Severity, f.Rule, f.Resource, f.Description) //nolint:errcheck
+	}
+	return len(findings), w.Flush()
+}
+
 // ── peer ──────────────────────────────────────────────────────────────────────
 
 type peerRow struct {
@@ -268,3 +305,132 @@ func (c *Client) RemoveToken(namespace, token string) error {
 	fmt.Printf("token %q revoked\n", token)
 	return nil
 }
+
+// ── service account ───────────────────────────────────────────────────────────
+
+// CreateServiceAccount creates a workspace service account.
+func (c *Client) CreateServiceAccount(namespace, name, role, description string) error {
+	data, err := c.call("serviceaccount.create", map[string]string{
+		"namespace":   namespace,
+		"name":        name,
+		"role":        role,
+		"description": description,
+	})
+	if err != nil {
+		return err
+	}
+	var sa vo.ServiceAccountVo
+	if err = json.Unmarshal(data, &sa); err != nil {
+		return err
+	}
+	fmt.Printf("service account %q created\n", sa.Name)
+	fmt.Printf("  role: %s\n", sa.Role)
+	fmt.Printf("\nCreate a token with 'lattice service-account token create %s <token-name> -n %s'.\n", sa.Name, namespace)
+	return nil
+}
+
+// ListServiceAccounts prints the service accounts of a workspace.
+func (c *Client) ListServiceAccounts(namespace string) error {
+	data, err := c.call("serviceaccount.list", map[string]string{"namespace": namespace})
+	if err != nil {
+		return err
+	}
+	var list []vo.ServiceAccountVo
+	if err = json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Printf("no service accounts in namespace %q\n", namespace)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tROLE\tTOKENS\tCREATED-BY\tDESCRIPTION") //nolint:errcheck
+	for _, sa := range list {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", //nolint:errcheck
+			sa.Name, sa.Role, sa.Tokens, sa.CreatedBy, sa.Description)
+	}
+	return w.Flush()
+}
+
+// DeleteServiceAccount deletes a service account and revokes its tokens.
+func (c *Client) DeleteServiceAccount(namespace, name string) error {
+	_, err := c.call("serviceaccount.delete", map[string]string{"namespace": namespace, "name": name})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("service account %q deleted, its tokens are revoked\n", name)
+	return nil
+}
+
+// CreateServiceAccountToken creates a token for a service account and prints
+// the secret, which cannot be shown again.
+func (c *Client) CreateServiceAccountToken(namespace, name, tokenName, role string, expiresInDays int) error {
+	data, err := c.call("serviceaccount.token.create", map[string]any{
+		"namespace":       namespace,
+		"name":            name,
+		"token_name":      tokenName,
+		"role":            role,
+		"expires_in_days": expiresInDays,
+	})
+	if err != nil {
+		return err
+	}
+	var t vo.APITokenCreatedVo
+	if err = json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	expiry := "never"
+	if t.ExpiresAt != nil {
+		expiry = t.ExpiresAt.Format("2006-01-02 15:04")
+	}
+	fmt.Printf("token %q created for service account %q\n", t.Name, name)
+	fmt.Printf("  id:      %s\n", t.ID)
+	fmt.Printf("  role:    %s\n", t.Role)
+	fmt.Printf("  expires: %s\n", expiry)
+	fmt.Printf("\n%s\n\nStore it now; it cannot be shown again.\n", t.Token)
+	return nil
+}
+
+// ListServiceAccountTokens prints the tokens of a service account.
+func (c *Client) ListServiceAccountTokens(namespace, name string) error {
+	data, err := c.call("serviceaccount.token.list", map[string]string{"namespace": namespace, "name": name})
+	if err != nil {
+		return err
+	}
+	var list []vo.APITokenVo
+	if err = json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Printf("no tokens for service account %q\n", name)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tROLE\tSTATUS\tEXPIRES\tLAST-USED") //nolint:errcheck
+	for _, t := range list {
+		expiry, lastUsed := "never", "never"
+		if t.ExpiresAt != nil {
+			expiry = t.ExpiresAt.Format("2006-01-02 15:04")
+		}
+		if t.LastUsedAt != nil {
+			lastUsed = t.LastUsedAt.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", //nolint:errcheck
+			t.ID, t.Name, t.Prefix, t.Role, t.Status, expiry, lastUsed)
+	}
+	return w.Flush()
+}
+
+// RevokeServiceAccountToken revokes a service account token by ID.
+func (c *Client) RevokeServiceAccountToken(namespace, name, tokenID string) error {
+	_, err := c.call("serviceaccount.token.revoke", map[string]string{
+		"namespace": namespace,
+		"name":      name,
+		"token_id":  tokenID,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("token %q revoked\n", tokenID)
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/vo"
+)
+
+// ExportBundle returns the declarative bundle of a workspace.
+func (c *Client) ExportBundle(namespace string) (*dto.Bundle, error) {
+	data, err := c.call("bundle.export", map[string]string{"namespace": namespace})
+	if err != nil {
+		return nil, err
+	}
+	var bundle dto.Bundle
+	if err = json.Unmarshal(data, &bundle); err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// DiffBundle prints the changes applying bundle would make and returns how
+// many there are. Skipped objects are listed but not counted.
+func (c *Client) DiffBundle(namespace string, bundle *dto.Bundle, prune bool) (int, error) {
+	changes, err := c.bundleChanges("bundle.diff", namespace, bundle, prune)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, ch := range changes {
+		printBundleChange(ch)
+		if ch.Diff != "" {
+			fmt.Println(ch.Diff)
+		}
+		if ch.Action != vo.BundleActionSkip {
+			n++
+		}
+	}
+	if n == 0 {
+		fmt.Printf("namespace %q is up to date\n", namespace)
+	}
+	return n, nil
+}
+
+// ApplyBundle reconciles a workspace with bundle and prints what changed.
+func (c *Client) ApplyBundle(namespace string, bundle *dto.Bundle, prune bool) error {
+	changes, err := c.bundleChanges("bundle.apply", namespace, bundle, prune)
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]int)
+	for _, ch := range changes {
+		printBundleChange(ch)
+		counts[ch.Action]++
+	}
+	fmt.Printf("%d created, %d updated, %d deleted, %d skipped\n",
+		counts[vo.BundleActionCreate], counts[vo.BundleActionUpdate],
+		counts[vo.BundleActionDelete], counts[vo.BundleActionSkip])
+	return nil
+}
+
+func (c *Client) bundleChanges(method, namespace string, bundle *dto.Bundle, prune bool) ([]vo.BundleChange, error) {
+	data, err := c.call(method, map[string]any{
+		"namespace": namespace,
+		"bundle":    bundle,
+		"prune":     prune,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var changes []vo.BundleChange
+	if err = json.Unmarshal(data, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+var bundleActionMarks = map[string]string{
+	vo.BundleActionCreate: "+",
+	vo.BundleActionUpdate: "~",
+	vo.BundleActionDelete: "-",
+	vo.BundleActionSkip:   "!",
+}
+
+func printBundleChange(ch vo.BundleChange) {
+	line := fmt.Sprintf("%s %s %s (%s)", bundleActionMarks[ch.Action], ch.Kind, ch.Name, ch.Action)
+	if ch.Reason != "" {
+		line += ": " + ch.Reason
+	}
+	fmt.Println(line)
+}
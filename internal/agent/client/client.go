@@ -13,14 +13,16 @@ import (
 
 type Client struct {
 	client infra.SignalService
+	// apiToken is sent with every admin-plane request when set.
+	apiToken string
 }
 
-func NewClient(signalUrl string) (*Client, error) {
+func NewClient(signalUrl, apiToken string) (*Client, error) {
 	natsClient, err := nats.NewNatsService(context.Background(), "client", "client", signalUrl)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{client: natsClient}, nil
+	return &Client{client: natsClient, apiToken: apiToken}, nil
 }
 
 func (c *Client) Info(ctx context.Context) error {
@@ -55,13 +57,7 @@ func (c *Client) CreateToken(namespace, name, expiry string) error {
 		Expiry:    expiry,
 	}
 
-	bs, err := json.Marshal(tokenDto)
-	if err != nil {
-		return err
-	}
-
-	data, err := c.client.Request(context.Background(), "lattice.signals.service", "createToken", bs)
-
+	data, err := c.call("createToken", tokenDto)
 	if err != nil {
 		return err
 	}
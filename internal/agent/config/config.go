@@ -244,6 +244,7 @@ func (cm *ConfigManager) load(cmd *cobra.Command) error {
 //   - Management API  → Listen      (默认 :8080)
 //   - Relay (TCP)     → RelayURL    (默认 :6266)
 //   - Relay (QUIC)    → RelayQuicURL (默认空)
+//   - Relay mesh      → RelayMeshAddr (默认空)
 //   - TURN server     → Port        (默认 3478)
 //   - Metrics/Probe   → MetricsAddr (默认 :8443)
 type Config struct {
@@ -255,6 +256,7 @@ type Config struct {
 	Auth          string `mapstructure:"auth"`
 	AppId         string `mapstructure:"app-id"`
 	Token         string `mapstructure:"token"`
+	APIToken      string `mapstructure:"api-token"`      // CLI 调用管理面携带的 API Token，LATTICE_API_TOKEN
 	InterfaceName string `mapstructure:"interface-name"` // WireGuard 接口名
 
 	// ── 网络 / 地址 ───────────────────────────────────────────────
@@ -276,6 +278,29 @@ type Config struct {
 	Port          int    `mapstructure:"port"`    // TURN 业务端口，默认 3478
 	WgPort        int    `mapstructure:"wg-port"` // WireGuard/ICE UDP 监听端口，默认 51820
 
+	// relay mesh：多个 relay 实例通过 SignalingURL 指向的 NATS 共享会话目录，
+	// 并把目标不在本实例的帧转发给持有该会话的 relay。
+	RelayID            string `mapstructure:"relay-id"`             // mesh 中的实例 ID，默认主机名
+	RelayMeshAddr      string `mapstructure:"relay-mesh-addr"`      // mesh 监听地址，空=不加入 mesh
+	RelayMeshAdvertise string `mapstructure:"relay-mesh-advertise"` // 其他 relay 连接本实例的地址，默认取监听地址
+
+	// relay 限流：超出限额的帧直接丢弃，0=不限制。命名空间限额由同一工作空间的所有会话共享。
+	RelaySessionBandwidth   int64 `mapstructure:"relay-session-bandwidth"`    // 单会话转发带宽（字节/秒）
+	RelaySessionFrameRate   int   `mapstructure:"relay-session-frame-rate"`   // 单会话转发帧率（帧/秒）
+	RelayNamespaceBandwidth int64 `mapstructure:"relay-namespace-bandwidth"`  // 单命名空间转发带宽（字节/秒）
+	RelayNamespaceFrameRate int   `mapstructure:"relay-namespace-frame-rate"` // 单命名空间转发帧率（帧/秒）
+
+	// relay 票据：manager 用该密钥为每个 peer 签发票据，relay 据此确认 peer 所属命名空间。
+	// manager 与所有 relay 必须配置相同的值；空=relay 不校验票据，会话不计入命名空间限额。
+	RelaySecret string `mapstructure:"relay-secret"`
+
+	// relay 管理接口：列出在线会话并支持踢出，空=禁用
+	RelayAdminAddr  string `mapstructure:"relay-admin-addr"`
+	RelayAdminToken string `mapstructure:"relay-admin-token"` // Bearer Token，空=不校验，仅适合监听本地地址
+
+	// 流日志：eBPF 执行器按周期汇总每个连接的放行/丢弃记录并通过 NATS 上报
+	FlowLogInterval int `mapstructure:"flow-log-interval"` // 上报周期（秒），0=不上报，默认 60
+
 	// ── 功能开关 ──────────────────────────────────────────────────
 	EnableWrrp   bool `mapstructure:"enable-wrrp"`
 	EnableTLS    bool `mapstructure:"enable-tls"`
@@ -305,21 +330,40 @@ type Config struct {
 	JWT       JWTConfig       `mapstructure:"jwt"`
 	Dex       DexConfig       `mapstructure:"dex"`
 	AI        AIConfig        `mapstructure:"ai"`
+	Audit     AuditConfig     `mapstructure:"audit"`
+	APITokens APITokenConfig  `mapstructure:"api-tokens"`
+}
+
+// APITokenConfig 个人访问令牌与服务账号令牌配置。
+type APITokenConfig struct {
+	// DefaultTTLDays 创建时未指定有效期的令牌默认有效天数，0 表示永不过期。
+	DefaultTTLDays int `mapstructure:"default-ttl-days"`
+
+	// MaxTTLDays 允许的最长有效天数，0 表示不限制；
+	// 大于 0 时也禁止创建永不过期的令牌。
+	MaxTTLDays int `mapstructure:"max-ttl-days"`
+
+	// RequireForNATS 为 true 时 NATS 管理面的写操作必须携带有效 API Token，
+	// 默认 false 以兼容旧版 CLI。
+	RequireForNATS bool `mapstructure:"require-for-nats"`
 }
 
 // AIConfig 聚合 AI 功能相关配置。
-// AI 功能为弱依赖：Enabled=false 或 APIKey 为空时所有 /api/v1/ai/* 接口返回 503。
+// AI 功能为弱依赖：Enabled=false 或 APIKey 为空时 /api/v1/ai/chat 返回 503，
+// /api/v1/ai/audit 只返回策略检查的结果。
 type AIConfig struct {
 	// Enabled 是否启用 AI 功能，默认 false。
 	// 对应环境变量: LATTICE_AI_ENABLED
 	Enabled bool `mapstructure:"enabled"`
 
-	// Provider 指定 LLM 服务商：anthropic（默认）、deepseek、openai，
+	// Provider 指定 LLM 服务商：anthropic（默认）、deepseek、openai、
+	// local（本地 llama.cpp / Ollama 等 OpenAI 兼容服务，无需 API Key）、
+	// fixture（回放 fixture 文件中录制的对话，用于测试与离线演示），
 	// 或配合 base-url 使用任意 OpenAI 兼容服务。
 	// 对应环境变量: LATTICE_AI_PROVIDER
 	Provider string `mapstructure:"provider"`
 
-	// APIKey 服务商 API Key。
+	// APIKey 服务商 API Key，local 与 fixture 可留空。
 	// 对应环境变量: LATTICE_AI_API_KEY
 	APIKey string `mapstructure:"api-key"`
 
@@ -331,12 +375,80 @@ type AIConfig struct {
 	// 对应环境变量: LATTICE_AI_BASE_URL
 	BaseURL string `mapstructure:"base-url"`
 
+	// ToolCalling 仅对 local 生效，指定工具调用方式：
+	//   auto（默认）：启动后首次请求时探测模型是否支持原生工具调用
+	//   native：使用 OpenAI tools 字段
+	//   prompt：在系统提示词中描述工具，由模型以 JSON 文本返回调用
+	ToolCalling string `mapstructure:"tool-calling"`
+
+	// Fixture provider 为 fixture 时回放的对话文件（YAML 或 JSON）。
+	Fixture string `mapstructure:"fixture"`
+
+	// RecordFixture 非空时将与真实 LLM 的对话录制到该文件，供 fixture provider 回放。
+	RecordFixture string `mapstructure:"record-fixture"`
+
 	// MaxToolCalls 单轮对话最大工具调用次数，默认 5。
 	MaxToolCalls int `mapstructure:"max-tool-calls"`
 
 	// AuditSchedule 安全审计定时任务 cron 表达式，默认 "0 2 * * *"（每日凌晨 2 点）。
-	// 留空时禁用定时审计。
+	// 定时对所有工作空间运行策略检查（不依赖 LLM，AI 未启用时同样生效），
+	// 并在日志中记录发现的问题；安全审计报告始终实时检查。留空时禁用定时审计。
 	AuditSchedule string `mapstructure:"audit-schedule"`
+
+	// DailyTokenQuota 每个工作空间每日（UTC）可消耗的 LLM token 数（输入 + 输出），
+	// 0 表示不限制。为软限制：每次请求 LLM 前检查，token 数在请求完成后才计入，
+	// 同时放行的并发请求可能合计超出。
+	DailyTokenQuota int64 `mapstructure:"daily-token-quota"`
+
+	// DailyToolCallQuota 每个工作空间每日（UTC）可执行的工具调用次数，0 表示不限制。
+	// 检查与计数在同一条原子更新中完成，并发对话也不会超出。
+	DailyToolCallQuota int64 `mapstructure:"daily-tool-call-quota"`
+
+	// WorkspaceQuotas 按工作空间命名空间覆盖上述两项配额。
+	WorkspaceQuotas map[string]AIQuota `mapstructure:"workspace-quotas"`
+}
+
+// AIQuota 单个工作空间的每日 AI 配额，0 表示不限制。
+type AIQuota struct {
+	Tokens    int64 `mapstructure:"tokens"`
+	ToolCalls int64 `mapstructure:"tool-calls"`
+}
+
+// AuditConfig 审计日志的保留、归档与实时外发配置。
+type AuditConfig struct {
+	// RetentionDays 审计日志保留天数，0 表示永久保留（默认）。
+	// 对应环境变量: LATTICE_AUDIT_RETENTION_DAYS
+	RetentionDays int `mapstructure:"retention-days"`
+
+	// ArchiveDir 过期日志删除前以 JSONL 写入该目录（每次清理一个文件），
+	// 留空时直接删除。
+	ArchiveDir string `mapstructure:"archive-dir"`
+
+	// Sinks 每条审计日志写入数据库后实时推送到的外部目标。
+	Sinks []AuditSinkConfig `mapstructure:"sinks"`
+
+	// IgnoreFieldManagers 这些 field manager 对 Lattice CRD 的变更视为系统自身的调谐，
+	// 不记入审计日志（默认为 Lattice 各控制器）。
+	IgnoreFieldManagers []string `mapstructure:"ignore-field-managers"`
+
+	// AdmissionToken 审计准入 Webhook（/api/v1/admission/audit）的 Bearer 令牌，
+	// 由 kube-apiserver 通过准入配置中的 kubeconfig 在 Authorization 头中发送，
+	// 不出现在 URL 与访问日志中。留空时不注册该 Webhook，
+	// 直接对 K8s API 的变更只能按 managedFields 中的 field manager 归属。
+	AdmissionToken string `mapstructure:"admission-token"`
+}
+
+// AuditSinkConfig 描述一个审计日志外发目标。
+type AuditSinkConfig struct {
+	// Type 目标类型：syslog（RFC 5424）、webhook 或 file（JSONL）。
+	Type string `mapstructure:"type"`
+
+	// Address 目标地址：syslog 为 udp://host:514 或 tcp://host:601，
+	// webhook 为 URL，file 为文件路径。
+	Address string `mapstructure:"address"`
+
+	// Headers webhook 请求附带的 HTTP 头，例如 Authorization。
+	Headers map[string]string `mapstructure:"headers"`
 }
 
 // AppConfig 聚合应用层服务端配置（不含 CLI 覆盖字段）。
@@ -368,6 +480,9 @@ type NatsConfig struct {
 type MonitorConfig struct {
 	Address     string `mapstructure:"address"`
 	TemplateDir string `mapstructure:"templateDir"` // YAML template path, default "config/metrics/templates.yaml"
+	// RemoteWrite is where telemetry that agents send over NATS is forwarded,
+	// default Address + "/api/v1/write".
+	RemoteWrite string `mapstructure:"remoteWrite"`
 }
 
 // TelemetryConfig configures the lightweight VM telemetry push module in the agent.
@@ -377,8 +492,24 @@ type TelemetryConfig struct {
 	VMEndpoint string `mapstructure:"vmEndpoint"`
 	// IntervalSeconds is the push interval in seconds. Defaults to 30.
 	IntervalSeconds int `mapstructure:"intervalSeconds"`
+	// Transport selects how batches leave the agent: "http" pushes to
+	// VMEndpoint, "nats" sends them over the signaling connection to the
+	// manager, which forwards them to monitor.remoteWrite.
+	Transport string `mapstructure:"transport"`
+	// WALDir holds batches that could not be delivered until they are
+	// replayed. Defaults to <config dir>/telemetry-wal.
+	WALDir string `mapstructure:"walDir"`
+	// WALMaxBytes bounds the WAL; the oldest batches are dropped first.
+	// 0 disables the WAL.
+	WALMaxBytes int64 `mapstructure:"walMaxBytes"`
 }
 
+// Telemetry transports.
+const (
+	TelemetryTransportHTTP = "http"
+	TelemetryTransportNATS = "nats"
+)
+
 type JWTConfig struct {
 	Secret      string `mapstructure:"secret"`
 	ExpireHours int    `mapstructure:"expire_hours"`
@@ -388,6 +519,33 @@ type DexConfig struct {
 	Issur       string   `mapstructure:"issur"`
 	ProviderUrl string   `mapstructure:"providerUrl"`
 	AdminEmails []string `mapstructure:"adminEmails"` // Dex 登录用户中自动授予 platform_admin 的邮箱白名单
+
+	// GroupsClaim ID Token 中携带 IdP 组的声明名，默认 groups。
+	GroupsClaim string `mapstructure:"groupsClaim"`
+	// AdminGroups 成员自动授予 platform_admin 的 IdP 组；离开组后收回（仅限由组授予的）。
+	AdminGroups []string `mapstructure:"adminGroups"`
+	// GroupMappings IdP 组到工作空间角色的映射规则，同一工作空间命中多条时取最高角色。
+	GroupMappings []GroupMapping `mapstructure:"groupMappings"`
+	// GroupSync 周期性按当前规则重新计算组成员关系。
+	GroupSync GroupSyncConfig `mapstructure:"groupSync"`
+}
+
+// GroupMapping 将一个 IdP 组映射为某个工作空间的成员角色。
+type GroupMapping struct {
+	// Group 组名，支持 path.Match 通配符，如 "eng-*"。
+	Group string `mapstructure:"group"`
+	// Workspace 工作空间的 namespace。
+	Workspace string `mapstructure:"workspace"`
+	// Role 工作空间角色：admin / editor / member / viewer。
+	Role string `mapstructure:"role"`
+}
+
+// GroupSyncConfig 组成员关系的周期同步配置。
+type GroupSyncConfig struct {
+	// IntervalSeconds 重新计算的周期（秒），0 表示只在登录时同步。默认 600。
+	IntervalSeconds int `mapstructure:"intervalSeconds"`
+	// MaxAgeHours 用户超过该时长未登录时，视为已不在任何组中，收回组授予的权限。0 表示不过期。
+	MaxAgeHours int `mapstructure:"maxAgeHours"`
 }
 
 // NetworkOptions 用于网络操作的选项参数。
@@ -549,6 +707,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("stun-url", "stun.alattice.io:3478")
 	v.SetDefault("relay-url", ":6266")
 	v.SetDefault("relay-quic-url", "")
+	v.SetDefault("relay-mesh-addr", "")
+	v.SetDefault("relay-admin-addr", "")
+	v.SetDefault("relay-secret", "")
+	v.SetDefault("flow-log-interval", 60)
 	v.SetDefault("port", 3478)
 	v.SetDefault("wg-port", 51820)
 
@@ -558,7 +720,15 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("database.dsn", "")
 
 	v.SetDefault("dex.providerUrl", "") // 空 = 禁用 Dex OIDC
+	v.SetDefault("dex.groupsClaim", "groups")
+	v.SetDefault("dex.groupSync.intervalSeconds", 600)
+	v.SetDefault("dex.groupSync.maxAgeHours", 0)
 	v.SetDefault("monitor.address", "")
+	v.SetDefault("monitor.remoteWrite", "")
+
+	v.SetDefault("telemetry.transport", "http")
+	v.SetDefault("telemetry.walDir", "")
+	v.SetDefault("telemetry.walMaxBytes", 64<<20)
 
 	v.SetDefault("metrics-addr", ":8443")
 	v.SetDefault("health-probe-bind-address", ":8081")
@@ -566,8 +736,23 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("ai.enabled", false)
 	v.SetDefault("ai.provider", "anthropic")
 	v.SetDefault("ai.model", "")
+	v.SetDefault("ai.tool-calling", "auto")
+	v.SetDefault("ai.fixture", "")
+	v.SetDefault("ai.record-fixture", "")
 	v.SetDefault("ai.max-tool-calls", 5)
 	v.SetDefault("ai.audit-schedule", "0 2 * * *")
+	v.SetDefault("ai.daily-token-quota", 0)
+	v.SetDefault("ai.daily-tool-call-quota", 0)
+
+	v.SetDefault("audit.retention-days", 0)
+	v.SetDefault("audit.archive-dir", "")
+	v.SetDefault("audit.ignore-field-managers", []string{"lattice-controller-manager", "relay-reconciler", "manager"})
+	v.SetDefault("audit.admission-token", "")
+
+	v.SetDefault("api-token", "")
+	v.SetDefault("api-tokens.default-ttl-days", 90)
+	v.SetDefault("api-tokens.max-ttl-days", 0)
+	v.SetDefault("api-tokens.require-for-nats", false)
 
 	v.SetDefault("app.name", "Lattice")
 	v.SetDefault("app.initAdmins", []map[string]string{
@@ -0,0 +1,173 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/localapi"
+	"github.com/alatticeio/lattice/internal/agent/log"
+	"github.com/alatticeio/lattice/internal/agent/wireguard"
+	"github.com/alatticeio/lattice/pkg/version"
+)
+
+// Node implements the local control API backend.
+var _ localapi.Backend = (*Node)(nil)
+
+// Status reports the interface identity and the WireGuard counters of every
+// peer, joined with the transport path chosen for it.
+func (c *Node) Status() (*localapi.Status, error) {
+	dump, err := c.iface.IpcGet()
+	if err != nil {
+		return nil, err
+	}
+	stats, err := wireguard.ParseUAPI(dump)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]infra.PeerPath)
+	for _, p := range c.PeerPaths() {
+		paths[p.PublicKey] = p
+	}
+
+	st := &localapi.Status{
+		Version:    version.Get().Version,
+		Interface:  c.Name,
+		ListenPort: stats.ListenPort,
+		LogLevel:   log.CurrentLevel(),
+		Peers:      make([]localapi.PeerStatus, 0, len(stats.Peers)),
+	}
+	if c.relays != nil {
+		st.Relays = c.relays.Preferences()
+	}
+	if cur := c.current; cur != nil {
+		st.AppID = cur.AppID
+		st.PublicKey = cur.PublicKey
+		st.NetworkID = cur.NetworkId
+		if cur.Address != nil {
+			st.Address = *cur.Address
+		}
+	}
+	for _, p := range stats.Peers {
+		ps := localapi.PeerStatus{
+			PublicKey:     p.PublicKey,
+			Endpoint:      p.Endpoint,
+			AllowedIPs:    p.AllowedIPs,
+			LastHandshake: p.LastHandshake,
+			RxBytes:       p.RxBytes,
+			TxBytes:       p.TxBytes,
+		}
+		if path, ok := paths[p.PublicKey]; ok {
+			ps.Path = &path
+		}
+		st.Peers = append(st.Peers, ps)
+	}
+	return st, nil
+}
+
+// handshakes returns the last WireGuard handshake time of every configured
+// peer, keyed by public key. Nil when the device state cannot be read.
+func (c *Node) handshakes() map[string]time.Time {
+	dump, err := c.iface.IpcGet()
+	if err != nil {
+		return nil
+	}
+	stats, err := wireguard.ParseUAPI(dump)
+	if err != nil {
+		return nil
+	}
+	out := make(map[string]time.Time, len(stats.Peers))
+	for _, p := range stats.Peers {
+		out[p.PublicKey] = p.LastHandshake
+	}
+	return out
+}
+
+// Reconnect restarts discovery for a single peer.
+func (c *Node) Reconnect(appId string) error {
+	if c.probeFactory == nil {
+		return errors.New("node is not started")
+	}
+	return c.probeFactory.Reconnect(appId)
+}
+
+// Reload re-fetches the network map from the control plane and applies it.
+func (c *Node) Reload(ctx context.Context) error {
+	if c.GetNetworkMap == nil {
+		return errors.New("network map source not configured")
+	}
+	msg, err := c.GetNetworkMap()
+	if err != nil {
+		return err
+	}
+	return c.messageHandler.ApplyFullConfig(ctx, msg)
+}
+
+// AppliedConfig returns the last applied network map with key material and
+// enrollment tokens stripped, so it is safe to print or attach to a bug report.
+func (c *Node) AppliedConfig() *infra.Message {
+	if c.messageHandler == nil {
+		return nil
+	}
+	msg := c.messageHandler.LastApplied()
+	if msg == nil {
+		return nil
+	}
+	out := *msg
+	out.Current = redactPeer(msg.Current)
+	out.ComputedPeers = redactPeers(msg.ComputedPeers)
+	if msg.Network != nil {
+		network := *msg.Network
+		network.Peers = redactPeers(msg.Network.Peers)
+		out.Network = &network
+	}
+	return &out
+}
+
+func (c *Node) Events() *localapi.EventBus {
+	return c.events
+}
+
+func (c *Node) Shutdown() {
+	if c.OnShutdown != nil {
+		c.OnShutdown()
+	}
+}
+
+func redactPeers(peers []*infra.Peer) []*infra.Peer {
+	if peers == nil {
+		return nil
+	}
+	out := make([]*infra.Peer, 0, len(peers))
+	for _, p := range peers {
+		out = append(out, redactPeer(p))
+	}
+	return out
+}
+
+func redactPeer(p *infra.Peer) *infra.Peer {
+	if p == nil {
+		return nil
+	}
+	cp := *p
+	cp.PrivateKey = ""
+	cp.PresharedKey = ""
+	cp.Token = ""
+	return &cp
+}
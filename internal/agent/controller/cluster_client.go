@@ -0,0 +1,236 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+
+	"github.com/golang-jwt/jwt/v5"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// LabelClusterCredential marks the Secret holding the credentials of a
+	// LatticeCluster; the value is the CredentialRef.
+	LabelClusterCredential = "alattice.io/cluster-credential"
+
+	// Keys of the credential Secret.
+	credentialTokenKey = "token"
+	credentialCAKey    = "ca.crt"
+	credentialCertKey  = "tls.crt"
+	credentialKeyKey   = "tls.key"
+)
+
+// clusterCredential is the content of a LatticeCluster credential Secret.
+type clusterCredential struct {
+	secret *corev1.Secret
+	token  string
+}
+
+// loadClusterCredential reads the credential Secret referenced by name.
+// Credentials are stored in the controller namespace and discovered by the
+// LabelClusterCredential label.
+func loadClusterCredential(ctx context.Context, c client.Client, name string) (*clusterCredential, error) {
+	var secretList corev1.SecretList
+	if err := c.List(ctx, &secretList, client.MatchingLabels{
+		LabelClusterCredential: name,
+	}); err != nil {
+		return nil, err
+	}
+	if len(secretList.Items) == 0 {
+		return nil, fmt.Errorf("secret %q not found (label %s=%s)", name, LabelClusterCredential, name)
+	}
+	secret := &secretList.Items[0]
+	token, ok := secret.Data[credentialTokenKey]
+	if !ok {
+		return nil, fmt.Errorf("secret %q missing key '%s'", name, credentialTokenKey)
+	}
+	return &clusterCredential{secret: secret, token: string(token)}, nil
+}
+
+// tlsConfig returns the TLS settings for the remote endpoint, or nil when
+// the Secret holds no TLS material. A ca.crt replaces the system roots, so
+// only certificates issued by the pinned CA are accepted.
+func (cc *clusterCredential) tlsConfig(serverName string) (*tls.Config, error) {
+	caPEM := cc.secret.Data[credentialCAKey]
+	certPEM := cc.secret.Data[credentialCertKey]
+	keyPEM := cc.secret.Data[credentialKeyKey]
+	if len(caPEM) == 0 && len(certPEM) == 0 && serverName == "" {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: serverName}
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("secret %q: no certificate in %s", cc.secret.Name, credentialCAKey)
+		}
+		cfg.RootCAs = pool
+	}
+	if len(certPEM) > 0 || len(keyPEM) > 0 {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("secret %q: client certificate: %w", cc.secret.Name, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// tokenLifetime returns the issue and expiry time of a JWT token. The
+// signature is not verified: only the remote cluster can do that. ok is
+// false for opaque tokens and tokens without both claims.
+func tokenLifetime(token string) (issued, expires time.Time, ok bool) {
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	if claims.IssuedAt == nil || claims.ExpiresAt == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return claims.IssuedAt.Time, claims.ExpiresAt.Time, true
+}
+
+// remoteCluster calls the management API of a remote Lattice cluster.
+type remoteCluster struct {
+	endpoint string
+	token    string
+	hc       *http.Client
+}
+
+// newRemoteCluster returns a client for cluster using cred. hc, when set,
+// is used as is; tests inject it.
+func newRemoteCluster(cluster *v1alpha1.LatticeCluster, cred *clusterCredential, hc *http.Client) (*remoteCluster, error) {
+	if hc == nil {
+		tlsCfg, err := cred.tlsConfig(cluster.Spec.TLSServerName)
+		if err != nil {
+			return nil, err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsCfg
+		hc = &http.Client{Timeout: 15 * time.Second, Transport: transport}
+	}
+	return &remoteCluster{
+		endpoint: strings.TrimSuffix(cluster.Spec.ManagementEndpoint, "/"),
+		token:    cred.token,
+		hc:       hc,
+	}, nil
+}
+
+// remoteResponse is the envelope of management API responses.
+type remoteResponse struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// call sends a request authenticated with token and decodes the data of
+// the response into out, if out is not nil.
+func (rc *remoteCluster) call(ctx context.Context, method, path, token string, header http.Header, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, rc.endpoint+path, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := rc.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, truncate(string(body), 512))
+	}
+	var envelope remoteResponse
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("decode %s response: %w", path, err)
+	}
+	if envelope.Code != http.StatusOK {
+		return fmt.Errorf("%s %s failed (%d): %s", method, path, envelope.Code, envelope.Msg)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+// health checks that the remote cluster is reachable and accepts the token.
+func (rc *remoteCluster) health(ctx context.Context) error {
+	return rc.call(ctx, http.MethodGet, "/api/v1/cluster/health", rc.token, nil, nil)
+}
+
+// gatewayInfo fetches the gateway of the network ns/network.
+func (rc *remoteCluster) gatewayInfo(ctx context.Context, ns, network string) (*GatewayInfo, error) {
+	query := url.Values{"namespace": {ns}, "network": {network}}
+	header := http.Header{"X-Workspace-Id": {ns}}
+	var info GatewayInfo
+	if err := rc.call(ctx, http.MethodGet, "/api/v1/peering/gateway-info?"+query.Encode(), rc.token, header, &info); err != nil {
+		return nil, err
+	}
+	if info.PublicKey == "" || info.GatewayIP == "" || info.CIDR == "" {
+		return nil, fmt.Errorf("incomplete gateway-info: %+v", info)
+	}
+	return &info, nil
+}
+
+// rotateToken exchanges the current token for a fresh one. The current
+// token stays valid until revokeToken is called with it.
+func (rc *remoteCluster) rotateToken(ctx context.Context) (string, error) {
+	var out struct {
+		Token string `json:"token"`
+	}
+	if err := rc.call(ctx, http.MethodPost, "/api/v1/cluster/credentials/rotate", rc.token, nil, &out); err != nil {
+		return "", err
+	}
+	if out.Token == "" {
+		return "", fmt.Errorf("rotate returned no token")
+	}
+	return out.Token, nil
+}
+
+// revokeToken revokes token on the remote cluster.
+func (rc *remoteCluster) revokeToken(ctx context.Context, token string) error {
+	return rc.call(ctx, http.MethodPost, "/api/v1/auth/logout", token, nil, nil)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,262 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const defaultClusterProbeInterval = time.Minute
+
+// ClusterReconciler health-checks the remote clusters registered as
+// LatticeCluster resources.
+//
+// Every probe interval it:
+//  1. Calls GET /api/v1/cluster/health on the remote management endpoint,
+//     with the pinned CA and client certificate when the credential Secret
+//     holds TLS material.
+//  2. Rotates the token in the credential Secret once half of its lifetime
+//     has passed, and revokes the old one.
+//  3. Fetches the gateways of the LatticeClusterPeerings that reference the
+//     cluster into Status.Gateways. ClusterPeeringReconciler watches that
+//     field, so a remote gateway that changes key or address is re-peered
+//     without waiting for the peering's own resync.
+type ClusterReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	httpClient *http.Client
+}
+
+// +kubebuilder:rbac:groups=alattice.io,resources=latticeclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=alattice.io,resources=latticeclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=alattice.io,resources=latticeclusterpeerings,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update;patch
+
+func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var cluster v1alpha1.LatticeCluster
+	if err := r.Get(ctx, req.NamespacedName, &cluster); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	interval := defaultClusterProbeInterval
+	if cluster.Spec.ProbeInterval != nil && cluster.Spec.ProbeInterval.Duration > 0 {
+		interval = cluster.Spec.ProbeInterval.Duration
+	}
+
+	cred, err := loadClusterCredential(ctx, r.Client, cluster.Spec.CredentialRef)
+	if err != nil {
+		return r.setDisconnected(ctx, &cluster, "CredentialError", err.Error(), interval)
+	}
+	remote, err := newRemoteCluster(&cluster, cred, r.httpClient)
+	if err != nil {
+		return r.setDisconnected(ctx, &cluster, "CredentialError", err.Error(), interval)
+	}
+	if err := remote.health(ctx); err != nil {
+		log.Info("remote cluster probe failed", "cluster", cluster.Name, "error", err.Error())
+		return r.setDisconnected(ctx, &cluster, "ProbeFailed", err.Error(), interval)
+	}
+
+	copy := cluster.DeepCopy()
+	now := metav1.Now()
+	if !cluster.Spec.DisableCredentialRotation {
+		rotated, err := r.rotateCredential(ctx, remote, cred)
+		switch {
+		case err != nil:
+			log.Error(err, "credential rotation failed", "cluster", cluster.Name)
+			copy.Status.Conditions = setCondition(copy.Status.Conditions, metav1.Condition{
+				Type:               "CredentialRotated",
+				Status:             metav1.ConditionFalse,
+				Reason:             "RotationFailed",
+				Message:            err.Error(),
+				LastTransitionTime: now,
+			})
+		case rotated:
+			copy.Status.CredentialRotatedAt = &now
+			copy.Status.Conditions = setCondition(copy.Status.Conditions, metav1.Condition{
+				Type:               "CredentialRotated",
+				Status:             metav1.ConditionTrue,
+				Reason:             "Rotated",
+				Message:            "token exchanged for a fresh one",
+				LastTransitionTime: now,
+			})
+		}
+	}
+	copy.Status.CredentialExpiresAt = nil
+	if _, expires, ok := tokenLifetime(remote.token); ok {
+		copy.Status.CredentialExpiresAt = &metav1.Time{Time: expires}
+	}
+
+	gateways, err := r.probeGateways(ctx, remote, &cluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	copy.Status.Gateways = gateways
+	copy.Status.Phase = v1alpha1.ClusterPhaseConnected
+	copy.Status.LastProbeTime = &now
+	copy.Status.Conditions = setCondition(copy.Status.Conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             metav1.ConditionTrue,
+		Reason:             "ProbeSucceeded",
+		Message:            fmt.Sprintf("remote cluster %s reachable", cluster.Spec.ManagementEndpoint),
+		LastTransitionTime: now,
+	})
+	if err := r.Status().Patch(ctx, copy, client.MergeFrom(&cluster)); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// rotateCredential exchanges the token once half of its lifetime has
+// passed. The new token is stored before the old one is revoked, so a
+// failure at any step leaves a valid token in the Secret. Only platform
+// admin session tokens can be rotated. Tokens without an issue and expiry
+// time are never rotated.
+func (r *ClusterReconciler) rotateCredential(ctx context.Context, remote *remoteCluster, cred *clusterCredential) (bool, error) {
+	issued, expires, ok := tokenLifetime(cred.token)
+	if !ok || time.Now().Before(issued.Add(expires.Sub(issued)/2)) {
+		return false, nil
+	}
+
+	token, err := remote.rotateToken(ctx)
+	if err != nil {
+		return false, err
+	}
+	secret := cred.secret.DeepCopy()
+	secret.Data[credentialTokenKey] = []byte(token)
+	if err := r.Patch(ctx, secret, client.MergeFrom(cred.secret)); err != nil {
+		return false, fmt.Errorf("store rotated token: %w", err)
+	}
+	if err := remote.revokeToken(ctx, cred.token); err != nil {
+		// The old token still expires on its own.
+		logf.FromContext(ctx).Error(err, "failed to revoke rotated token", "secret", cred.secret.Name)
+	}
+	remote.token = token
+	cred.token = token
+	cred.secret = secret
+	return true, nil
+}
+
+// probeGateways fetches the gateway of every remote network peered with
+// cluster. A gateway that cannot be fetched keeps its last known entry; the
+// peering reports the error itself.
+func (r *ClusterReconciler) probeGateways(ctx context.Context, remote *remoteCluster, cluster *v1alpha1.LatticeCluster) ([]v1alpha1.RemoteGateway, error) {
+	var peerings v1alpha1.LatticeClusterPeeringList
+	if err := r.List(ctx, &peerings); err != nil {
+		return nil, err
+	}
+	known := make(map[string]v1alpha1.RemoteGateway, len(cluster.Status.Gateways))
+	for _, gw := range cluster.Status.Gateways {
+		known[gw.Namespace+"/"+gw.Network] = gw
+	}
+
+	var gateways []v1alpha1.RemoteGateway
+	seen := make(map[string]bool)
+	for _, cp := range peerings.Items {
+		key := cp.Spec.RemoteNamespace + "/" + cp.Spec.RemoteNetwork
+		if cp.Spec.RemoteCluster != cluster.Name || seen[key] {
+			continue
+		}
+		seen[key] = true
+		info, err := remote.gatewayInfo(ctx, cp.Spec.RemoteNamespace, cp.Spec.RemoteNetwork)
+		if err != nil {
+			logf.FromContext(ctx).Info("failed to fetch remote gateway", "cluster", cluster.Name, "network", key, "error", err.Error())
+			if gw, ok := known[key]; ok {
+				gateways = append(gateways, gw)
+			}
+			continue
+		}
+		gateways = append(gateways, v1alpha1.RemoteGateway{
+			Namespace: cp.Spec.RemoteNamespace,
+			Network:   cp.Spec.RemoteNetwork,
+			PublicKey: info.PublicKey,
+			GatewayIP: info.GatewayIP,
+			CIDR:      info.CIDR,
+		})
+	}
+	return gateways, nil
+}
+
+func (r *ClusterReconciler) setDisconnected(ctx context.Context, cluster *v1alpha1.LatticeCluster, reason, msg string, interval time.Duration) (ctrl.Result, error) {
+	copy := cluster.DeepCopy()
+	copy.Status.Phase = v1alpha1.ClusterPhaseDisconnected
+	copy.Status.Conditions = setCondition(copy.Status.Conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            msg,
+		LastTransitionTime: metav1.Now(),
+	})
+	if equality.Semantic.DeepEqual(copy.Status, cluster.Status) {
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+	return ctrl.Result{RequeueAfter: interval}, r.Status().Patch(ctx, copy, client.MergeFrom(cluster))
+}
+
+// mapSecretToClusters enqueues the clusters whose credentials are held in
+// the given Secret, so a replaced token or certificate is probed at once.
+func (r *ClusterReconciler) mapSecretToClusters(ctx context.Context, obj client.Object) []reconcile.Request {
+	ref, ok := obj.GetLabels()[LabelClusterCredential]
+	if !ok {
+		return nil
+	}
+	var list v1alpha1.LatticeClusterList
+	if err := r.List(ctx, &list); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list clusters")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cluster := range list.Items {
+		if cluster.Spec.CredentialRef == ref {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cluster)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager registers the ClusterReconciler with the manager.
+func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.LatticeCluster{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.mapSecretToClusters),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				_, ok := obj.GetLabels()[LabelClusterCredential]
+				return ok
+			}))).
+		Named("cluster").
+		Complete(r)
+}
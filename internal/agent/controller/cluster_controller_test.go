@@ -0,0 +1,267 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+
+	"github.com/golang-jwt/jwt/v5"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// fakeRemoteCluster is the management API of a remote cluster, served over
+// mTLS.
+type fakeRemoteCluster struct {
+	mu        sync.Mutex
+	gateway   GatewayInfo
+	tokens    map[string]bool // issued tokens that are not revoked
+	rotations int
+}
+
+func (f *fakeRemoteCluster) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reply := func(code int, data any) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": code, "data": data})
+	}
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !f.tokens[token] {
+		reply(http.StatusUnauthorized, nil)
+		return
+	}
+	switch req.URL.Path {
+	case "/api/v1/cluster/health":
+		reply(http.StatusOK, nil)
+	case "/api/v1/cluster/credentials/rotate":
+		f.rotations++
+		fresh := testClusterToken(time.Now(), 12*time.Hour)
+		f.tokens[fresh] = true
+		reply(http.StatusOK, map[string]string{"token": fresh})
+	case "/api/v1/auth/logout":
+		delete(f.tokens, token)
+		reply(http.StatusOK, nil)
+	case "/api/v1/peering/gateway-info":
+		if req.Header.Get("X-Workspace-Id") != req.URL.Query().Get("namespace") {
+			reply(http.StatusForbidden, nil)
+			return
+		}
+		reply(http.StatusOK, f.gateway)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func testClusterToken(issued time.Time, lifetime time.Duration) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ID:        issued.String(),
+		IssuedAt:  jwt.NewNumericDate(issued),
+		ExpiresAt: jwt.NewNumericDate(issued.Add(lifetime)),
+	}).SignedString([]byte("remote-secret"))
+	if err != nil {
+		panic(err)
+	}
+	return token
+}
+
+// testClientCertificate returns a self-signed client certificate and key.
+func testClientCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestCluster_ProbesRotatesAndTracksGateways(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	// An old token: more than half of its lifetime has passed.
+	oldToken := testClusterToken(time.Now().Add(-10*time.Hour), 12*time.Hour)
+	remote := &fakeRemoteCluster{
+		gateway: GatewayInfo{PublicKey: "key-1", GatewayIP: "10.2.0.1", CIDR: "10.2.0.0/24"},
+		tokens:  map[string]bool{oldToken: true},
+	}
+	certPEM, keyPEM := testClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(certPEM)
+	srv := httptest.NewUnstartedServer(remote)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	cluster := &v1alpha1.LatticeCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod-eu"},
+		Spec:       v1alpha1.LatticeClusterSpec{ManagementEndpoint: srv.URL, CredentialRef: "prod-eu"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "lattice-system", Name: "prod-eu", Labels: map[string]string{LabelClusterCredential: "prod-eu"}},
+		Data: map[string][]byte{
+			credentialTokenKey: []byte(oldToken),
+			credentialCAKey:    caPEM,
+			credentialCertKey:  certPEM,
+			credentialKeyKey:   keyPEM,
+		},
+	}
+	peering := &v1alpha1.LatticeClusterPeering{
+		ObjectMeta: metav1.ObjectMeta{Name: "eu"},
+		Spec: v1alpha1.LatticeClusterPeeringSpec{
+			LocalNamespace: "team-a", LocalNetwork: "net-a",
+			RemoteCluster: "prod-eu", RemoteNamespace: "team-b", RemoteNetwork: "net-b",
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.LatticeCluster{}).
+		WithObjects(cluster, secret, peering).
+		Build()
+	r := &ClusterReconciler{Client: c, Scheme: scheme}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "prod-eu"}}
+
+	probe := func() *v1alpha1.LatticeCluster {
+		t.Helper()
+		res, err := r.Reconcile(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.RequeueAfter != defaultClusterProbeInterval {
+			t.Fatalf("RequeueAfter = %v, want %v", res.RequeueAfter, defaultClusterProbeInterval)
+		}
+		var got v1alpha1.LatticeCluster
+		if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+			t.Fatal(err)
+		}
+		return &got
+	}
+	storedToken := func() string {
+		var s corev1.Secret
+		if err := c.Get(ctx, client.ObjectKeyFromObject(secret), &s); err != nil {
+			t.Fatal(err)
+		}
+		return string(s.Data[credentialTokenKey])
+	}
+
+	// The first probe connects with the client certificate, rotates the old token and records
+	// the remote gateway.
+	first := probe()
+	if first.Status.Phase != v1alpha1.ClusterPhaseConnected || first.Status.LastProbeTime == nil {
+		t.Fatalf("status = %+v, want connected", first.Status)
+	}
+	if first.Status.CredentialRotatedAt == nil || first.Status.CredentialExpiresAt == nil {
+		t.Fatalf("credential status = %+v, want rotated", first.Status)
+	}
+	newToken := storedToken()
+	if newToken == oldToken || !remote.tokens[newToken] || remote.tokens[oldToken] {
+		t.Fatal("token not rotated: want the new token stored and the old one revoked")
+	}
+	want := []v1alpha1.RemoteGateway{{Namespace: "team-b", Network: "net-b", PublicKey: "key-1", GatewayIP: "10.2.0.1", CIDR: "10.2.0.0/24"}}
+	if len(first.Status.Gateways) != 1 || first.Status.Gateways[0] != want[0] {
+		t.Fatalf("gateways = %+v, want %+v", first.Status.Gateways, want)
+	}
+
+	// The fresh token is kept; a re-keyed gateway re-reconciles the peering.
+	remote.mu.Lock()
+	remote.gateway.PublicKey = "key-2"
+	remote.mu.Unlock()
+	second := probe()
+	if remote.rotations != 1 || storedToken() != newToken {
+		t.Fatalf("rotations = %d, want the fresh token kept", remote.rotations)
+	}
+	if second.Status.Gateways[0].PublicKey != "key-2" {
+		t.Fatalf("gateway key = %q, want key-2", second.Status.Gateways[0].PublicKey)
+	}
+	if !remoteClusterChangedPredicate().Update(event.UpdateEvent{ObjectOld: first, ObjectNew: second}) {
+		t.Fatal("gateway key change not passed to the cluster peering controller")
+	}
+	if remoteClusterChangedPredicate().Update(event.UpdateEvent{ObjectOld: second, ObjectNew: second}) {
+		t.Fatal("unchanged cluster passed to the cluster peering controller")
+	}
+	pr := &ClusterPeeringReconciler{Client: c, Scheme: scheme}
+	if got := pr.mapClusterToPeerings(ctx, second); len(got) != 1 || got[0].Name != "eu" {
+		t.Fatalf("mapped peerings = %v, want [eu]", got)
+	}
+
+	// Without the pinned CA the remote certificate is not trusted.
+	var s corev1.Secret
+	if err := c.Get(ctx, client.ObjectKeyFromObject(secret), &s); err != nil {
+		t.Fatal(err)
+	}
+	delete(s.Data, credentialCAKey)
+	if err := c.Update(ctx, &s); err != nil {
+		t.Fatal(err)
+	}
+	third := probe()
+	if third.Status.Phase != v1alpha1.ClusterPhaseDisconnected {
+		t.Fatalf("phase = %s, want Disconnected", third.Status.Phase)
+	}
+	if len(third.Status.Conditions) == 0 {
+		t.Fatal("no Ready condition")
+	}
+	for _, cond := range third.Status.Conditions {
+		if cond.Type == "Ready" && (cond.Status != metav1.ConditionFalse || cond.Reason != "ProbeFailed") {
+			t.Fatalf("Ready condition = %+v, want ProbeFailed", cond)
+		}
+	}
+}
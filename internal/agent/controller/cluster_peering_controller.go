@@ -16,15 +16,13 @@ package controller
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
 
 	"github.com/alatticeio/lattice/api/v1alpha1"
 
-	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,8 +31,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const clusterPeeringFinalizer = "alattice.io/cluster-peering-finalizer"
@@ -47,6 +48,9 @@ type GatewayInfo struct {
 	CIDR      string `json:"cidr"`
 	AppID     string `json:"appId"`
 	PeerID    string `json:"peerId"`
+	// Peers maps the names of the peers in the remote network to their
+	// addresses. It is published to local peers through DNS.
+	Peers map[string]string `json:"peers,omitempty"`
 }
 
 // ClusterPeeringReconciler reconciles LatticeClusterPeering resources.
@@ -107,15 +111,18 @@ func (r *ClusterPeeringReconciler) reconcileNormal(ctx context.Context, cp *v1al
 		return r.setClusterPeeringError(ctx, cp, fmt.Sprintf("LatticeCluster %q not found: %v", cp.Spec.RemoteCluster, err))
 	}
 
-	// 2. Load the bearer token from the referenced Secret.
-	token, err := r.loadCredential(ctx, cluster.Spec.CredentialRef)
+	// 2. Load the credentials from the referenced Secret.
+	cred, err := loadClusterCredential(ctx, r.Client, cluster.Spec.CredentialRef)
+	if err != nil {
+		return r.setClusterPeeringError(ctx, cp, fmt.Sprintf("credential load failed: %v", err))
+	}
+	remote, err := newRemoteCluster(&cluster, cred, r.httpClient)
 	if err != nil {
 		return r.setClusterPeeringError(ctx, cp, fmt.Sprintf("credential load failed: %v", err))
 	}
 
 	// 3. Fetch remote gateway info.
-	info, err := r.fetchGatewayInfo(ctx, cluster.Spec.ManagementEndpoint, token,
-		cp.Spec.RemoteNamespace, cp.Spec.RemoteNetwork)
+	info, err := remote.gatewayInfo(ctx, cp.Spec.RemoteNamespace, cp.Spec.RemoteNetwork)
 	if err != nil {
 		log.Error(err, "failed to fetch remote gateway info")
 		return r.setClusterPeeringError(ctx, cp, fmt.Sprintf("remote gateway info: %v", err))
@@ -127,37 +134,84 @@ func (r *ClusterPeeringReconciler) reconcileNormal(ctx context.Context, cp *v1al
 		return r.setClusterPeeringError(ctx, cp, fmt.Sprintf("local network not ready: %v", err))
 	}
 
-	// 5. Create/update a shadow peer in the local namespace representing the remote gateway.
-	if err := r.ensureRemoteGatewayShadow(ctx, cp, info, localNetwork.Name); err != nil {
+	// 5. Work out the range each side is reached at. Overlapping networks
+	//    are peered through their virtual CIDRs.
+	localCIDR := localNetwork.Status.ActiveCIDR
+	mappedLocal, mappedRemote, err := peeringRanges(localCIDR, cp.Spec.LocalVirtualCIDR, info.CIDR, cp.Spec.RemoteVirtualCIDR)
+	if err != nil {
+		return r.setClusterPeeringError(ctx, cp, err.Error())
+	}
+
+	// 6. Create/update a shadow peer in the local namespace representing the remote gateway.
+	if err := r.ensureRemoteGatewayShadow(ctx, cp, info, localNetwork.Name, mappedRemote); err != nil {
 		return ctrl.Result{}, fmt.Errorf("ensure remote gateway shadow: %w", err)
 	}
 
-	// 6. Find the local gateway and annotate it with the remote CIDR.
-	localGW, err := r.findGatewayForCluster(ctx, cp.Spec.LocalNamespace, cp.Spec.LocalNetwork)
-	if err != nil || localGW == nil {
-		return r.setClusterPeeringError(ctx, cp, fmt.Sprintf("no local gateway: %v", err))
+	// 7. Elect the local gateway and annotate it with the remote range, the
+	//    translation it applies and the names of the remote peers. Cross-
+	//    cluster peerings use a single active gateway: the remote cluster
+	//    learns it through gateway-info.
+	gateways, err := listGateways(ctx, r.Client, cp.Spec.LocalNamespace, cp.Spec.LocalNetwork)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
-	annotationKey := AnnotationPeeringRoutePrefix + cp.Name
-	if err := r.ensureAnnotationForCluster(ctx, localGW, annotationKey, info.CIDR); err != nil {
+	var current []string
+	if cp.Status.LocalGateway != "" {
+		current = []string{cp.Status.LocalGateway}
+	}
+	elected := electGateways(gateways, current, v1alpha1.GatewayModeActiveStandby, time.Now())
+	if len(elected) == 0 {
+		return r.setClusterPeeringError(ctx, cp, fmt.Sprintf("no healthy gateway peer in %s/%s: label %s=true required", cp.Spec.LocalNamespace, cp.Spec.LocalNetwork, LabelGateway))
+	}
+	localGW := elected[0]
+	annotations := map[string]string{AnnotationPeeringRoutePrefix + cp.Name: mappedRemote}
+	if cp.Spec.LocalVirtualCIDR != "" {
+		netmap, err := peeringNetMap(localCIDR, cp.Spec.LocalVirtualCIDR, mappedRemote)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		annotations[AnnotationPeeringNetMapPrefix+cp.Name] = netmap
+	} else {
+		annotations[AnnotationPeeringNetMapPrefix+cp.Name] = ""
+	}
+	records, err := peeringDNSRecords(info.Peers, cp.Spec.RemoteNamespace, info.CIDR, cp.Spec.RemoteVirtualCIDR)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
+	annotations[AnnotationPeeringDNSPrefix+cp.Name] = records
+	if err := r.ensureAnnotationsForCluster(ctx, localGW, annotations); err != nil {
+		return ctrl.Result{}, err
+	}
+	for i := range gateways {
+		if gateways[i].Name != localGW.Name {
+			if err := r.ensureAnnotationsForCluster(ctx, &gateways[i], clusterPeeringAnnotations(cp.Name)); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
 
-	// 7. Ensure policies so all local peers route through the gateway, and the
+	// 8. Ensure policies so all local peers route through the gateway, and the
 	//    gateway can reach the remote shadow.
 	if err := r.ensurePoliciesForCluster(ctx, cp, localNetwork.Name); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// 8. Update status.
-	return r.setClusterPeeringReady(ctx, cp, localNetwork.Status.ActiveCIDR, info.CIDR)
+	// 9. Update status. With standby gateways, re-run the election
+	//    periodically so a gateway that stops sending heartbeats is replaced.
+	res, err := r.setClusterPeeringReady(ctx, cp, localGW.Name, localCIDR, info.CIDR, mappedLocal, mappedRemote)
+	if err == nil && len(gateways) > 1 {
+		res.RequeueAfter = gatewayElectionInterval
+	}
+	return res, err
 }
 
 func (r *ClusterPeeringReconciler) reconcileDelete(ctx context.Context, cp *v1alpha1.LatticeClusterPeering) (ctrl.Result, error) {
 	shadowName := fmt.Sprintf("cluster-shadow-%s", cp.Name)
-	annotationKey := AnnotationPeeringRoutePrefix + cp.Name
 
-	if gw, err := r.findGatewayForCluster(ctx, cp.Spec.LocalNamespace, cp.Spec.LocalNetwork); err == nil && gw != nil {
-		_ = r.removeAnnotationForCluster(ctx, gw, annotationKey)
+	if gateways, err := listGateways(ctx, r.Client, cp.Spec.LocalNamespace, cp.Spec.LocalNetwork); err == nil {
+		for i := range gateways {
+			_ = r.ensureAnnotationsForCluster(ctx, &gateways[i], clusterPeeringAnnotations(cp.Name))
+		}
 	}
 
 	_ = r.deleteClusterResourceIfExists(ctx, &v1alpha1.LatticePeer{}, cp.Spec.LocalNamespace, shadowName)
@@ -168,69 +222,13 @@ func (r *ClusterPeeringReconciler) reconcileDelete(ctx context.Context, cp *v1al
 	return ctrl.Result{}, r.Update(ctx, cp)
 }
 
-// loadCredential reads the bearer token from the Secret referenced by credentialRef.
-// The Secret must be in the controller's own namespace (kube-system or the
-// namespace the controller is deployed in). The token is stored under key "token".
-func (r *ClusterPeeringReconciler) loadCredential(ctx context.Context, secretName string) (string, error) {
-	// Credentials are stored in the controller namespace; use label to discover it.
-	var secretList corev1.SecretList
-	if err := r.List(ctx, &secretList, client.MatchingLabels{
-		"alattice.io/cluster-credential": secretName,
-	}); err != nil {
-		return "", err
-	}
-	// Fallback: try by name in all namespaces.
-	if len(secretList.Items) == 0 {
-		return "", fmt.Errorf("secret %q not found (label alattice.io/cluster-credential=%s)", secretName, secretName)
-	}
-	token, ok := secretList.Items[0].Data["token"]
-	if !ok {
-		return "", fmt.Errorf("secret %q missing key 'token'", secretName)
-	}
-	return string(token), nil
-}
-
-// fetchGatewayInfo calls the remote management API to obtain the gateway info
-// for the specified namespace/network.
-func (r *ClusterPeeringReconciler) fetchGatewayInfo(ctx context.Context, endpoint, token, ns, network string) (*GatewayInfo, error) {
-	hc := r.httpClient
-	if hc == nil {
-		hc = &http.Client{Timeout: 15 * time.Second}
-	}
-
-	url := fmt.Sprintf("%s/api/v1/peering/gateway-info?namespace=%s&network=%s", endpoint, ns, network)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	resp, err := hc.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
-		return nil, fmt.Errorf("remote gateway-info returned %d: %s", resp.StatusCode, string(body))
-	}
-
-	var info GatewayInfo
-	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
-		return nil, fmt.Errorf("decode gateway-info response: %w", err)
-	}
-	if info.PublicKey == "" || info.GatewayIP == "" || info.CIDR == "" {
-		return nil, fmt.Errorf("incomplete gateway-info: %+v", info)
-	}
-	return &info, nil
-}
-
 // ensureRemoteGatewayShadow creates or updates the shadow peer in the local
-// namespace that represents the remote cluster's gateway.
-func (r *ClusterPeeringReconciler) ensureRemoteGatewayShadow(ctx context.Context, cp *v1alpha1.LatticeClusterPeering, info *GatewayInfo, networkName string) error {
+// namespace that represents the remote cluster's gateway. The remote network
+// is reached at mappedRemote; when it is translated, so is the gateway IP.
+func (r *ClusterPeeringReconciler) ensureRemoteGatewayShadow(ctx context.Context, cp *v1alpha1.LatticeClusterPeering, info *GatewayInfo, networkName, mappedRemote string) error {
 	name := fmt.Sprintf("cluster-shadow-%s", cp.Name)
 	networkLabel := fmt.Sprintf("alattice.io/network-%s", networkName)
+	gatewayIP := mappedAddress(info.GatewayIP, info.CIDR, cp.Spec.RemoteVirtualCIDR)
 
 	desired := &v1alpha1.LatticePeer{
 		ObjectMeta: metav1.ObjectMeta{
@@ -241,7 +239,7 @@ func (r *ClusterPeeringReconciler) ensureRemoteGatewayShadow(ctx context.Context
 				networkLabel: "true",
 			},
 			Annotations: map[string]string{
-				AnnotationShadowAllowedIPs: info.CIDR,
+				AnnotationShadowAllowedIPs: mappedRemote,
 			},
 		},
 		Spec: v1alpha1.LatticePeerSpec{
@@ -259,7 +257,7 @@ func (r *ClusterPeeringReconciler) ensureRemoteGatewayShadow(ctx context.Context
 		}
 		// Set the gateway IP in Status so it appears in WireGuard config.
 		created := desired.DeepCopy()
-		created.Status.AllocatedAddress = &info.GatewayIP
+		created.Status.AllocatedAddress = &gatewayIP
 		created.Status.Phase = v1alpha1.NodePhaseReady
 		return r.Status().Update(ctx, created)
 	}
@@ -276,9 +274,9 @@ func (r *ClusterPeeringReconciler) ensureRemoteGatewayShadow(ctx context.Context
 		return patchErr
 	}
 
-	if existing.Status.AllocatedAddress == nil || *existing.Status.AllocatedAddress != info.GatewayIP {
+	if existing.Status.AllocatedAddress == nil || *existing.Status.AllocatedAddress != gatewayIP {
 		statusCopy := peerCopy.DeepCopy()
-		statusCopy.Status.AllocatedAddress = &info.GatewayIP
+		statusCopy.Status.AllocatedAddress = &gatewayIP
 		statusCopy.Status.Phase = v1alpha1.NodePhaseReady
 		return r.Status().Update(ctx, statusCopy)
 	}
@@ -351,38 +349,33 @@ func (r *ClusterPeeringReconciler) getReadyNetworkForCluster(ctx context.Context
 	return &network, nil
 }
 
-func (r *ClusterPeeringReconciler) findGatewayForCluster(ctx context.Context, ns, networkName string) (*v1alpha1.LatticePeer, error) {
-	var list v1alpha1.LatticePeerList
-	if err := r.List(ctx, &list, client.InNamespace(ns), client.MatchingLabels{
-		LabelGateway: "true",
-		fmt.Sprintf("alattice.io/network-%s", networkName): "true",
-	}); err != nil {
-		return nil, err
+// clusterPeeringAnnotations returns the annotations that remove a cluster
+// peering from a gateway.
+func clusterPeeringAnnotations(name string) map[string]string {
+	return map[string]string{
+		AnnotationPeeringRoutePrefix + name:  "",
+		AnnotationPeeringNetMapPrefix + name: "",
+		AnnotationPeeringDNSPrefix + name:    "",
 	}
-	if len(list.Items) == 0 {
-		return nil, nil
-	}
-	return &list.Items[0], nil
 }
 
-func (r *ClusterPeeringReconciler) ensureAnnotationForCluster(ctx context.Context, peer *v1alpha1.LatticePeer, key, value string) error {
-	if peer.GetAnnotations()[key] == value {
-		return nil
-	}
+// ensureAnnotationsForCluster sets the given annotations on a peer in a
+// single patch; an empty value removes the annotation.
+func (r *ClusterPeeringReconciler) ensureAnnotationsForCluster(ctx context.Context, peer *v1alpha1.LatticePeer, annotations map[string]string) error {
 	peerCopy := peer.DeepCopy()
 	if peerCopy.Annotations == nil {
 		peerCopy.Annotations = make(map[string]string)
 	}
-	peerCopy.Annotations[key] = value
-	return r.Patch(ctx, peerCopy, client.MergeFrom(peer))
-}
-
-func (r *ClusterPeeringReconciler) removeAnnotationForCluster(ctx context.Context, peer *v1alpha1.LatticePeer, key string) error {
-	if _, ok := peer.GetAnnotations()[key]; !ok {
+	for k, v := range annotations {
+		if v == "" {
+			delete(peerCopy.Annotations, k)
+		} else {
+			peerCopy.Annotations[k] = v
+		}
+	}
+	if equality.Semantic.DeepEqual(peerCopy.Annotations, peer.Annotations) {
 		return nil
 	}
-	peerCopy := peer.DeepCopy()
-	delete(peerCopy.Annotations, key)
 	return r.Patch(ctx, peerCopy, client.MergeFrom(peer))
 }
 
@@ -396,16 +389,19 @@ func (r *ClusterPeeringReconciler) deleteClusterResourceIfExists(ctx context.Con
 	return err
 }
 
-func (r *ClusterPeeringReconciler) setClusterPeeringReady(ctx context.Context, cp *v1alpha1.LatticeClusterPeering, localCIDR, remoteCIDR string) (ctrl.Result, error) {
+func (r *ClusterPeeringReconciler) setClusterPeeringReady(ctx context.Context, cp *v1alpha1.LatticeClusterPeering, localGateway, localCIDR, remoteCIDR, mappedLocal, mappedRemote string) (ctrl.Result, error) {
 	copy := cp.DeepCopy()
 	copy.Status.Phase = v1alpha1.ClusterPeeringPhaseReady
+	copy.Status.LocalGateway = localGateway
 	copy.Status.LocalCIDR = localCIDR
 	copy.Status.RemoteCIDR = remoteCIDR
+	copy.Status.MappedLocalCIDR = mappedLocal
+	copy.Status.MappedRemoteCIDR = mappedRemote
 	copy.Status.Conditions = setCondition(copy.Status.Conditions, metav1.Condition{
 		Type:               "Ready",
 		Status:             metav1.ConditionTrue,
 		Reason:             "ClusterPeeringEstablished",
-		Message:            fmt.Sprintf("cross-cluster peering %s ↔ %s established", localCIDR, remoteCIDR),
+		Message:            fmt.Sprintf("cross-cluster peering %s ↔ %s established", mappedLocal, mappedRemote),
 		LastTransitionTime: metav1.Now(),
 	})
 	return ctrl.Result{RequeueAfter: 5 * time.Minute}, r.Status().Patch(ctx, copy, client.MergeFrom(cp))
@@ -425,10 +421,50 @@ func (r *ClusterPeeringReconciler) setClusterPeeringError(ctx context.Context, c
 	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 }
 
+// mapClusterToPeerings enqueues the cluster peerings to the given cluster.
+func (r *ClusterPeeringReconciler) mapClusterToPeerings(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list v1alpha1.LatticeClusterPeeringList
+	if err := r.List(ctx, &list); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list cluster peerings")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cp := range list.Items {
+		if cp.Spec.RemoteCluster == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cp)})
+		}
+	}
+	return requests
+}
+
+// remoteClusterChangedPredicate passes LatticeCluster updates that change
+// the probed gateways or the connection phase.
+func remoteClusterChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCluster, ok1 := e.ObjectOld.(*v1alpha1.LatticeCluster)
+			newCluster, ok2 := e.ObjectNew.(*v1alpha1.LatticeCluster)
+			if !ok1 || !ok2 {
+				return false
+			}
+			return oldCluster.Status.Phase != newCluster.Status.Phase ||
+				!equality.Semantic.DeepEqual(oldCluster.Status.Gateways, newCluster.Status.Gateways)
+		},
+	}
+}
+
 // SetupWithManager registers the ClusterPeeringReconciler with the manager.
 func (r *ClusterPeeringReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.LatticeClusterPeering{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Re-reconcile when the probe of a remote cluster sees its gateways
+		// change key or address, or the cluster comes back.
+		Watches(&v1alpha1.LatticeCluster{},
+			handler.EnqueueRequestsFromMapFunc(r.mapClusterToPeerings),
+			builder.WithPredicates(remoteClusterChangedPredicate())).
 		Named("cluster-peering").
 		Complete(r)
 }
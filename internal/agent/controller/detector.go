@@ -19,6 +19,7 @@ import (
 	"fmt"
 	v1alpha1 "github.com/alatticeio/lattice/api/v1alpha1"
 	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/relay"
 	"sort"
 	"sync"
 	"time"
@@ -31,6 +32,7 @@ import (
 
 type Generator struct {
 	client          client.Client
+	relaySecret     string
 	versionMu       sync.Mutex
 	versionCounter  int64
 	peerResolver    PeerResolver
@@ -44,11 +46,15 @@ type PeerStateSnapshot struct {
 	Policies []*v1alpha1.LatticePolicy
 	Peers    []*v1alpha1.LatticePeer
 	Labels   map[string]string
+	Relays   []v1alpha1.LatticeRelayServer
 }
 
-func NewGenerator(client client.Client) *Generator {
+// NewGenerator creates a Generator. relaySecret signs the relay tickets of
+// the generated configs; empty issues none.
+func NewGenerator(client client.Client, relaySecret string) *Generator {
 	return &Generator{
 		client:          client,
+		relaySecret:     relaySecret,
 		peerResolver:    NewPeerResolver(),
 		policyEvaluator: NewPolicyEvaluator(),
 	}
@@ -308,6 +314,10 @@ func (d *Generator) generate(ctx context.Context, current *v1alpha1.LatticePeer,
 	}
 
 	msg.Current.Labels = snapshot.Labels
+	if len(snapshot.Relays) > 0 {
+		msg.Relays = relaysForPeer(snapshot.Relays, current)
+		msg.RelayTicket = relay.PeerTicket(d.relaySecret, current)
+	}
 
 	// 填充网络信息
 	if snapshot.Network != nil {
@@ -320,7 +330,7 @@ func (d *Generator) generate(ctx context.Context, current *v1alpha1.LatticePeer,
 				continue
 			}
 
-			msg.Network.Peers = append(msg.Network.Peers, transferToPeer(p))
+			msg.Network.Peers = append(msg.Network.Peers, transferToPeerFor(p, current.Name))
 		}
 		sort.Slice(msg.Network.Peers, func(i, j int) bool {
 			return msg.Network.Peers[i].Name < msg.Network.Peers[j].Name
@@ -343,6 +353,7 @@ func (d *Generator) generate(ctx context.Context, current *v1alpha1.LatticePeer,
 	if err != nil {
 		return nil, err
 	}
+	msg.ComputedQoS = buildQoS(current, msg.Network, msg.Policies)
 
 	return msg, nil
 }
@@ -388,11 +399,16 @@ func (d *Generator) buildPolicy(ctx context.Context, src *v1alpha1.LatticePolicy
 		}
 		cidrs := extractCIDRs(ingress.From)
 
+		rate, burst := rateLimit(ingress.RateLimit)
+
 		if len(ingress.Ports) == 0 {
 			ingresses = append(ingresses, &infra.Rule{
 				PeerNames: names,
 				CIDRs:     cidrs,
 				Action:    action,
+				RateMbps:  rate,
+				BurstKB:   burst,
+				QoSClass:  string(ingress.QoSClass),
 			})
 		} else {
 			for _, port := range ingress.Ports {
@@ -401,7 +417,11 @@ func (d *Generator) buildPolicy(ctx context.Context, src *v1alpha1.LatticePolicy
 					CIDRs:     cidrs,
 					Protocol:  port.Protocol,
 					Port:      int(port.Port),
+					EndPort:   int(port.EndPort),
 					Action:    action,
+					RateMbps:  rate,
+					BurstKB:   burst,
+					QoSClass:  string(ingress.QoSClass),
 				})
 			}
 		}
@@ -415,11 +435,16 @@ func (d *Generator) buildPolicy(ctx context.Context, src *v1alpha1.LatticePolicy
 		}
 		cidrs := extractCIDRs(egress.To)
 
+		rate, burst := rateLimit(egress.RateLimit)
+
 		if len(egress.Ports) == 0 {
 			egresses = append(egresses, &infra.Rule{
 				PeerNames: names,
 				CIDRs:     cidrs,
 				Action:    action,
+				RateMbps:  rate,
+				BurstKB:   burst,
+				QoSClass:  string(egress.QoSClass),
 			})
 		} else {
 			for _, port := range egress.Ports {
@@ -428,7 +453,11 @@ func (d *Generator) buildPolicy(ctx context.Context, src *v1alpha1.LatticePolicy
 					CIDRs:     cidrs,
 					Protocol:  port.Protocol,
 					Port:      int(port.Port),
+					EndPort:   int(port.EndPort),
 					Action:    action,
+					RateMbps:  rate,
+					BurstKB:   burst,
+					QoSClass:  string(egress.QoSClass),
 				})
 			}
 		}
@@ -0,0 +1,176 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"hash/fnv"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationPeeringGatewaysPrefix is the prefix for the per-peering
+	// gateway group annotation on active-active gateways. The suffix is that
+	// of the peering's route annotation; the value lists the active gateways
+	// of the network, comma-separated and sorted. A peer only routes the
+	// peering through the gateway it hashes to.
+	AnnotationPeeringGatewaysPrefix = "alattice.io/peering-gws-"
+
+	// gatewayHeartbeatTimeout is how old the heartbeat of a gateway may be
+	// for it to be elected. Agents send a heartbeat every 30s.
+	gatewayHeartbeatTimeout = 90 * time.Second
+
+	// gatewayElectionInterval is how often gateway-mode peerings re-run the
+	// election, so a gateway that stops sending heartbeats is replaced.
+	gatewayElectionInterval = 30 * time.Second
+)
+
+// peeringGatewaysAnnotationKey returns the gateway group annotation key
+// matching the route annotation of the given peering.
+func peeringGatewaysAnnotationKey(peeringName string) string {
+	return AnnotationPeeringGatewaysPrefix + strings.TrimPrefix(peeringRouteAnnotationKey(peeringName), AnnotationPeeringRoutePrefix)
+}
+
+// gatewayHealth ranks gateways for the election.
+type gatewayHealth int
+
+const (
+	gatewayStale   gatewayHealth = iota // heartbeat too old
+	gatewayUnknown                      // never reported a heartbeat
+	gatewayHealthy                      // recent heartbeat
+)
+
+func gatewayHealthOf(peer *v1alpha1.LatticePeer, now time.Time) gatewayHealth {
+	v, ok := peer.Annotations[v1alpha1.AnnotationGatewayHeartbeat]
+	if !ok {
+		return gatewayUnknown
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil || now.Sub(t) > gatewayHeartbeatTimeout {
+		return gatewayStale
+	}
+	return gatewayHealthy
+}
+
+// electGateways returns the gateways that carry the traffic of a network,
+// sorted by name. Gateways with a recent heartbeat are preferred; gateways
+// that never reported one are used only when none has, so deployments
+// without heartbeats keep working, and stale gateways never are.
+//
+// In active-standby mode a single gateway is returned. The current one is
+// kept while it stays in the preferred group, so a recovering gateway does
+// not take the traffic back.
+func electGateways(peers []v1alpha1.LatticePeer, current []string, mode v1alpha1.GatewayMode, now time.Time) []*v1alpha1.LatticePeer {
+	var pool []*v1alpha1.LatticePeer
+	best := gatewayStale
+	for i := range peers {
+		p := &peers[i]
+		if !p.DeletionTimestamp.IsZero() || p.Status.AllocatedAddress == nil || p.Spec.PublicKey == "" {
+			continue
+		}
+		h := gatewayHealthOf(p, now)
+		switch {
+		case h == gatewayStale || h < best:
+			continue
+		case h > best:
+			best = h
+			pool = pool[:0]
+		}
+		pool = append(pool, p)
+	}
+	sort.Slice(pool, func(i, j int) bool { return pool[i].Name < pool[j].Name })
+
+	if len(pool) == 0 || mode == v1alpha1.GatewayModeActiveActive {
+		return pool
+	}
+	for _, p := range pool {
+		if slices.Contains(current, p.Name) {
+			return []*v1alpha1.LatticePeer{p}
+		}
+	}
+	return pool[:1]
+}
+
+// listGateways returns the peers labeled as gateways of a network.
+func listGateways(ctx context.Context, c client.Client, ns, networkName string) ([]v1alpha1.LatticePeer, error) {
+	var list v1alpha1.LatticePeerList
+	if err := c.List(ctx, &list, client.InNamespace(ns), client.MatchingLabels{
+		LabelGateway:                 "true",
+		networkLabelKey(networkName): "true",
+	}); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// gatewayNames returns the names of gateways.
+func gatewayNames(gateways []*v1alpha1.LatticePeer) []string {
+	names := make([]string, len(gateways))
+	for i, gw := range gateways {
+		names[i] = gw.Name
+	}
+	return names
+}
+
+// gatewayForPeer returns the member of an active-active gateway group that
+// carries the traffic of peer. Gateways carry their own traffic; other
+// peers are spread by a hash of their name.
+func gatewayForPeer(peer string, members []string) string {
+	if len(members) == 0 {
+		return ""
+	}
+	if slices.Contains(members, peer) {
+		return peer
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(peer))
+	return members[h.Sum32()%uint32(len(members))]
+}
+
+// routesVia reports whether viewer routes the peering whose route
+// annotation key is routeKey through gateway. It is false only for
+// active-active groups where viewer hashes to another member.
+func routesVia(gateway *v1alpha1.LatticePeer, routeKey, viewer string) bool {
+	if viewer == "" {
+		return true
+	}
+	group := gateway.Annotations[AnnotationPeeringGatewaysPrefix+strings.TrimPrefix(routeKey, AnnotationPeeringRoutePrefix)]
+	if group == "" {
+		return true
+	}
+	members := strings.Split(group, ",")
+	if slices.Contains(members, viewer) {
+		// Gateways reach the remote network through their own tunnel.
+		return false
+	}
+	return gatewayForPeer(viewer, members) == gateway.Name
+}
+
+// hostRoute returns the single-address prefix of addr.
+func hostRoute(addr string) string {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return addr + "/32"
+	}
+	return netip.PrefixFrom(ip, ip.BitLen()).String()
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestElectGateways(t *testing.T) {
+	now := time.Now()
+	gateway := func(name string, heartbeat time.Duration) v1alpha1.LatticePeer {
+		addr := "10.1.0.1"
+		p := v1alpha1.LatticePeer{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{}},
+			Spec:       v1alpha1.LatticePeerSpec{PublicKey: "key-" + name},
+			Status:     v1alpha1.LatticePeerStatus{AllocatedAddress: &addr},
+		}
+		if heartbeat >= 0 {
+			p.Annotations[v1alpha1.AnnotationGatewayHeartbeat] = now.Add(-heartbeat).Format(time.RFC3339)
+		}
+		return p
+	}
+	const never = -1
+
+	tests := []struct {
+		name    string
+		peers   []v1alpha1.LatticePeer
+		current []string
+		mode    v1alpha1.GatewayMode
+		want    []string
+	}{
+		{
+			name:  "healthy preferred over unknown and stale",
+			peers: []v1alpha1.LatticePeer{gateway("a", never), gateway("b", 5*time.Minute), gateway("c", 10*time.Second)},
+			mode:  v1alpha1.GatewayModeActiveStandby,
+			want:  []string{"c"},
+		},
+		{
+			name:  "unknown used when no heartbeats",
+			peers: []v1alpha1.LatticePeer{gateway("b", never), gateway("a", never)},
+			mode:  v1alpha1.GatewayModeActiveStandby,
+			want:  []string{"a"},
+		},
+		{
+			name:  "stale never elected",
+			peers: []v1alpha1.LatticePeer{gateway("a", 5*time.Minute)},
+			mode:  v1alpha1.GatewayModeActiveStandby,
+		},
+		{
+			name:    "current kept while healthy",
+			peers:   []v1alpha1.LatticePeer{gateway("a", time.Second), gateway("b", time.Second)},
+			current: []string{"b"},
+			mode:    v1alpha1.GatewayModeActiveStandby,
+			want:    []string{"b"},
+		},
+		{
+			name:    "current replaced when stale",
+			peers:   []v1alpha1.LatticePeer{gateway("a", time.Second), gateway("b", 5*time.Minute)},
+			current: []string{"b"},
+			mode:    v1alpha1.GatewayModeActiveStandby,
+			want:    []string{"a"},
+		},
+		{
+			name:  "active-active uses all healthy",
+			peers: []v1alpha1.LatticePeer{gateway("c", time.Second), gateway("a", time.Second), gateway("b", 5*time.Minute)},
+			mode:  v1alpha1.GatewayModeActiveActive,
+			want:  []string{"a", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gatewayNames(electGateways(tt.peers, tt.current, tt.mode, now))
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("electGateways = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoutesVia(t *testing.T) {
+	members := []string{"gw-1", "gw-2"}
+	gateways := map[string]*v1alpha1.LatticePeer{}
+	for _, name := range members {
+		gateways[name] = &v1alpha1.LatticePeer{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{
+			peeringRouteAnnotationKey("ab"):    "10.2.0.0/24",
+			peeringGatewaysAnnotationKey("ab"): "gw-1,gw-2",
+		}}}
+	}
+
+	// Every peer routes through exactly one member, the same each time.
+	for _, viewer := range []string{"p1", "p2", "p3", "p4", "p5"} {
+		var via []string
+		for _, name := range members {
+			if routesVia(gateways[name], peeringRouteAnnotationKey("ab"), viewer) {
+				via = append(via, name)
+			}
+		}
+		if len(via) != 1 || via[0] != gatewayForPeer(viewer, members) {
+			t.Errorf("%s routes via %v, want [%s]", viewer, via, gatewayForPeer(viewer, members))
+		}
+	}
+
+	// Members use their own tunnel, and configs without a viewer keep all routes.
+	if routesVia(gateways["gw-2"], peeringRouteAnnotationKey("ab"), "gw-1") {
+		t.Error("gw-1 routes via gw-2")
+	}
+	if !routesVia(gateways["gw-2"], peeringRouteAnnotationKey("ab"), "") {
+		t.Error("route dropped without a viewer")
+	}
+}
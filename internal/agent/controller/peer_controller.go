@@ -52,8 +52,10 @@ type PeerReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
-	IPAM          *ipam.IPAM
-	generator     *Generator
+	IPAM      *ipam.IPAM
+	generator *Generator
+	// RelaySecret signs the relay tickets handed to peers.
+	RelaySecret   string
 	SnapshotCache map[types.NamespacedName]*PeerStateSnapshot
 
 	Recorder record.EventRecorder
@@ -64,6 +66,7 @@ type PeerReconciler struct {
 // +kubebuilder:rbac:groups=alattice.io,resources=latticepeers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=alattice.io,resources=latticepeers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=alattice.io,resources=latticepeers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=alattice.io,resources=latticerelayservers,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -718,7 +721,7 @@ func (r *PeerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	if r.generator == nil {
-		r.generator = NewGenerator(mgr.GetClient())
+		r.generator = NewGenerator(mgr.GetClient(), r.RelaySecret)
 	}
 
 	// Predicate that fires only on Update events.
@@ -765,8 +768,43 @@ func (r *PeerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		GenericFunc: func(e event.GenericEvent) bool { return false },
 	}
 
+	// Watch LatticeRelayServer for changes that alter which relays are offered:
+	// spec edits, region relabels, and health or capacity transitions.
+	relayOfferPredicate := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldRelay, ok1 := e.ObjectOld.(*v1alpha1.LatticeRelayServer)
+			newRelay, ok2 := e.ObjectNew.(*v1alpha1.LatticeRelayServer)
+			if !ok1 || !ok2 {
+				return false
+			}
+			return oldRelay.Generation != newRelay.Generation ||
+				oldRelay.Labels[v1alpha1.RelayRegionLabel] != newRelay.Labels[v1alpha1.RelayRegionLabel] ||
+				(oldRelay.Status.Health == v1alpha1.RelayHealthOffline) != (newRelay.Status.Health == v1alpha1.RelayHealthOffline) ||
+				relayAtCapacity(oldRelay) != relayAtCapacity(newRelay)
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}
+
+	// Peering controllers publish routes, address translations and DNS names
+	// as annotations on peers, which do not bump generation. Every peer that
+	// sees the annotated peer needs its config regenerated.
+	peeringAnnotationPredicate := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !reflect.DeepEqual(peeringAnnotations(e.ObjectOld), peeringAnnotations(e.ObjectNew))
+		},
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.LatticePeer{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&v1alpha1.LatticePeer{},
+			handler.EnqueueRequestsFromMapFunc(r.mapPeerForNodes),
+			builder.WithPredicates(peeringAnnotationPredicate)).
+		Watches(&v1alpha1.LatticeRelayServer{},
+			handler.EnqueueRequestsFromMapFunc(r.mapRelayForNodes),
+			builder.WithPredicates(relayOfferPredicate)).
 		Watches(&v1alpha1.LatticeNetwork{},
 			handler.EnqueueRequestsFromMapFunc(r.mapNetworkForNodes),
 			builder.WithPredicates(networkReadyPredicate)).
@@ -856,6 +894,66 @@ func (r *PeerReconciler) mapEndpointForNodes(ctx context.Context, obj client.Obj
 	return requests
 }
 
+// mapPeerForNodes returns reconcile requests for every peer in the namespace
+// of a peer whose peering annotations changed.
+func (r *PeerReconciler) mapPeerForNodes(ctx context.Context, obj client.Object) []reconcile.Request {
+	var peerList v1alpha1.LatticePeerList
+	if err := r.List(ctx, &peerList, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(peerList.Items))
+	for _, item := range peerList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: item.Namespace,
+				Name:      item.Name,
+			},
+		})
+	}
+	return requests
+}
+
+// peeringAnnotations returns the annotations of obj that peering controllers
+// manage and transferToPeer turns into config.
+func peeringAnnotations(obj client.Object) map[string]string {
+	out := make(map[string]string)
+	for k, v := range obj.GetAnnotations() {
+		if k == AnnotationShadowAllowedIPs ||
+			strings.HasPrefix(k, AnnotationPeeringRoutePrefix) ||
+			strings.HasPrefix(k, AnnotationPeeringNetMapPrefix) ||
+			strings.HasPrefix(k, AnnotationPeeringDNSPrefix) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// mapRelayForNodes returns reconcile requests for every peer in the namespaces
+// the relay serves, so their relay list is regenerated.
+func (r *PeerReconciler) mapRelayForNodes(ctx context.Context, obj client.Object) []reconcile.Request {
+	relay := obj.(*v1alpha1.LatticeRelayServer)
+
+	var peerList v1alpha1.LatticePeerList
+	if err := r.List(ctx, &peerList); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, item := range peerList.Items {
+		if !relayServesNamespace(relay, item.Namespace) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: item.Namespace,
+				Name:      item.Name,
+			},
+		})
+	}
+	return requests
+}
+
 // mapPolicyForNodes returns reconcile requests for peers affected by a policy change.
 // Peers are scoped to the policy's network to avoid triggering peers from other
 // networks in the same namespace (an empty PeerSelector would otherwise match all).
@@ -935,6 +1033,11 @@ func (r *PeerReconciler) getPeerStateSnapshot(ctx context.Context, current *v1al
 		}
 	}
 
+	var relayList v1alpha1.LatticeRelayServerList
+	if err = r.List(ctx, &relayList); err == nil {
+		snapshot.Relays = relayList.Items
+	}
+
 	policyList, err := r.filterPoliciesForNode(ctx, snapshot.Peer)
 	if err != nil {
 		return snapshot
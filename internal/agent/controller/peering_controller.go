@@ -17,10 +17,14 @@ package controller
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/alatticeio/lattice/api/v1alpha1"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -40,7 +44,9 @@ import (
 //
 // For each peering it:
 //  1. Annotates the gateway peer in each namespace with a per-peering route so
-//     local peers include the remote CIDR in the gateway's AllowedIPs.
+//     local peers include the remote CIDR in the gateway's AllowedIPs, and,
+//     for overlapping networks, the 1:1 translation into a virtual CIDR the
+//     gateway applies.
 //  2. Creates a "shadow peer" in each namespace representing the remote gateway,
 //     ensuring WireGuard can establish the inter-gateway tunnel.
 //  3. Creates LatticePolicy objects that admit the gateway and shadow peers
@@ -88,9 +94,7 @@ func (r *NetworkPeeringReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 }
 
 func (r *NetworkPeeringReconciler) reconcileNormal(ctx context.Context, peering *v1alpha1.LatticeNetworkPeering) (ctrl.Result, error) {
-	log := logf.FromContext(ctx)
-
-	// 1. Fetch both networks and verify they are ready.
+	// Fetch both networks and verify they are ready.
 	networkA, err := r.getReadyNetwork(ctx, peering.Spec.NamespaceA, peering.Spec.NetworkA)
 	if err != nil {
 		return r.setError(ctx, peering, fmt.Sprintf("network A not ready: %v", err))
@@ -100,44 +104,94 @@ func (r *NetworkPeeringReconciler) reconcileNormal(ctx context.Context, peering
 		return r.setError(ctx, peering, fmt.Sprintf("network B not ready: %v", err))
 	}
 
+	if peering.Spec.PeeringMode == v1alpha1.PeeringModeMesh {
+		return r.reconcileMesh(ctx, peering, networkA, networkB)
+	}
+	return r.reconcileGateway(ctx, peering, networkA, networkB)
+}
+
+// reconcileGateway routes the peering through one gateway peer per side.
+// Mesh resources left from a previous mode are removed only after the
+// gateway path is in place, so switching modes does not interrupt traffic.
+func (r *NetworkPeeringReconciler) reconcileGateway(ctx context.Context, peering *v1alpha1.LatticeNetworkPeering, networkA, networkB *v1alpha1.LatticeNetwork) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
 	cidrA := networkA.Status.ActiveCIDR
 	cidrB := networkB.Status.ActiveCIDR
 
-	// 2. Find designated gateway peers.
-	gatewayA, err := r.findGateway(ctx, peering.Spec.NamespaceA, peering.Spec.NetworkA)
-	if err != nil || gatewayA == nil {
-		msg := fmt.Sprintf("no gateway peer in %s/%s: label %s=true required", peering.Spec.NamespaceA, peering.Spec.NetworkA, LabelGateway)
+	// 1. Elect the gateway peers carrying the traffic of each side.
+	mode := peering.Spec.GatewayMode
+	if mode == "" {
+		mode = v1alpha1.GatewayModeActiveStandby
+	}
+	allA, err := listGateways(ctx, r.Client, peering.Spec.NamespaceA, peering.Spec.NetworkA)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	allB, err := listGateways(ctx, r.Client, peering.Spec.NamespaceB, peering.Spec.NetworkB)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	now := time.Now()
+	gatewaysA := electGateways(allA, peering.Status.GatewaysA, mode, now)
+	if len(gatewaysA) == 0 {
+		msg := fmt.Sprintf("no healthy gateway peer in %s/%s: label %s=true required", peering.Spec.NamespaceA, peering.Spec.NetworkA, LabelGateway)
 		log.Info(msg)
 		return r.setError(ctx, peering, msg)
 	}
-	gatewayB, err := r.findGateway(ctx, peering.Spec.NamespaceB, peering.Spec.NetworkB)
-	if err != nil || gatewayB == nil {
-		msg := fmt.Sprintf("no gateway peer in %s/%s: label %s=true required", peering.Spec.NamespaceB, peering.Spec.NetworkB, LabelGateway)
+	gatewaysB := electGateways(allB, peering.Status.GatewaysB, mode, now)
+	if len(gatewaysB) == 0 {
+		msg := fmt.Sprintf("no healthy gateway peer in %s/%s: label %s=true required", peering.Spec.NamespaceB, peering.Spec.NetworkB, LabelGateway)
 		log.Info(msg)
 		return r.setError(ctx, peering, msg)
 	}
 
-	// 3. Annotate gateway peers with per-peering routes.
-	//    Other local peers will route the remote CIDR through this gateway.
-	annotationKey := peeringRouteAnnotationKey(peering.Name)
-	if err := r.ensureAnnotation(ctx, gatewayA, annotationKey, cidrB); err != nil {
-		return ctrl.Result{}, err
+	// 2. Work out the range each side is reached at. Overlapping networks
+	//    are peered through their virtual CIDRs.
+	mappedA, mappedB, err := peeringRanges(cidrA, peering.Spec.VirtualCIDRA, cidrB, peering.Spec.VirtualCIDRB)
+	if err != nil {
+		return r.setError(ctx, peering, err.Error())
 	}
-	if err := r.ensureAnnotation(ctx, gatewayB, annotationKey, cidrA); err != nil {
-		return ctrl.Result{}, err
+
+	// 3. Annotate the active gateway peers with per-peering routes,
+	//    translations and the names of the remote peers.
+	//    Other local peers will route the remote range through them.
+	for _, gw := range gatewaysA {
+		if err := r.ensureGatewayAnnotations(ctx, peering, gw, gatewayGroup(gatewaysA), cidrA, peering.Spec.VirtualCIDRA, mappedB,
+			peering.Spec.NamespaceB, networkB.Name, cidrB, peering.Spec.VirtualCIDRB); err != nil {
+			return ctrl.Result{}, fmt.Errorf("gateway A annotations: %w", err)
+		}
+	}
+	for _, gw := range gatewaysB {
+		if err := r.ensureGatewayAnnotations(ctx, peering, gw, gatewayGroup(gatewaysB), cidrB, peering.Spec.VirtualCIDRB, mappedA,
+			peering.Spec.NamespaceA, networkA.Name, cidrA, peering.Spec.VirtualCIDRA); err != nil {
+			return ctrl.Result{}, fmt.Errorf("gateway B annotations: %w", err)
+		}
+	}
+
+	// 4. Create/update a shadow peer of each active gateway of A in
+	//    NamespaceB. The gateways of B connect to these shadows to establish
+	//    the inter-gateway tunnels.
+	if err := r.ensureShadowPeers(ctx, peering, gatewaysA, peering.Spec.NamespaceA, networkA.Name,
+		peering.Spec.NamespaceB, networkB.Name, cidrA, peering.Spec.VirtualCIDRA); err != nil {
+		return ctrl.Result{}, fmt.Errorf("shadow peers for gateways A in namespace B: %w", err)
+	}
+	//    Create/update shadow peers of the gateways of B in NamespaceA.
+	if err := r.ensureShadowPeers(ctx, peering, gatewaysB, peering.Spec.NamespaceB, networkB.Name,
+		peering.Spec.NamespaceA, networkA.Name, cidrB, peering.Spec.VirtualCIDRB); err != nil {
+		return ctrl.Result{}, fmt.Errorf("shadow peers for gateways B in namespace A: %w", err)
 	}
 
-	// 4. Create/update shadow peer of GatewayA in NamespaceB.
-	//    GatewayB will connect to this shadow to establish the inter-gateway tunnel.
-	if err := r.ensureShadowPeer(ctx, peering, gatewayA, peering.Spec.NamespaceB, networkB.Name, cidrA); err != nil {
-		return ctrl.Result{}, fmt.Errorf("shadow peer for gateway A in namespace B: %w", err)
+	// Only now that the new path is in place, withdraw the gateways that
+	// lost the election.
+	if err := r.retireGateways(ctx, peering, allA, gatewaysA); err != nil {
+		return ctrl.Result{}, fmt.Errorf("retire gateways A: %w", err)
 	}
-	// 5. Create/update shadow peer of GatewayB in NamespaceA.
-	if err := r.ensureShadowPeer(ctx, peering, gatewayB, peering.Spec.NamespaceA, networkA.Name, cidrB); err != nil {
-		return ctrl.Result{}, fmt.Errorf("shadow peer for gateway B in namespace A: %w", err)
+	if err := r.retireGateways(ctx, peering, allB, gatewaysB); err != nil {
+		return ctrl.Result{}, fmt.Errorf("retire gateways B: %w", err)
 	}
 
-	// 6. Ensure policies so ComputedPeers includes the gateway and shadow peers.
+	// 5. Ensure policies so ComputedPeers includes the gateway and shadow peers.
 	if err := r.ensurePolicies(ctx, peering, networkA.Name, peering.Spec.NamespaceA); err != nil {
 		return ctrl.Result{}, fmt.Errorf("policies namespace A: %w", err)
 	}
@@ -145,8 +199,71 @@ func (r *NetworkPeeringReconciler) reconcileNormal(ctx context.Context, peering
 		return ctrl.Result{}, fmt.Errorf("policies namespace B: %w", err)
 	}
 
-	// 7. Update status.
-	return r.setReady(ctx, peering, cidrA, cidrB)
+	// 6. Drop mesh resources of a previous mode.
+	if err := r.cleanupMesh(ctx, peering); err != nil {
+		return ctrl.Result{}, fmt.Errorf("remove mesh resources: %w", err)
+	}
+
+	// 7. Update status. With standby gateways, re-run the election
+	//    periodically so a gateway that stops sending heartbeats is replaced.
+	copy := peering.DeepCopy()
+	copy.Status.GatewaysA = gatewayNames(gatewaysA)
+	copy.Status.GatewaysB = gatewayNames(gatewaysB)
+	res, err := r.setReady(ctx, peering, copy, v1alpha1.PeeringModeGateway, cidrA, cidrB, mappedA, mappedB,
+		int32(len(gatewaysA)), int32(len(gatewaysB)))
+	if err == nil && (len(allA) > 1 || len(allB) > 1) {
+		res.RequeueAfter = gatewayElectionInterval
+	}
+	return res, err
+}
+
+// reconcileMesh mirrors every peer of each network into the other one as a
+// shadow peer, so all peers on both sides build direct tunnels to each
+// other. Gateway resources left from a previous mode are removed only after
+// the mesh is in place.
+func (r *NetworkPeeringReconciler) reconcileMesh(ctx context.Context, peering *v1alpha1.LatticeNetworkPeering, networkA, networkB *v1alpha1.LatticeNetwork) (ctrl.Result, error) {
+	cidrA := networkA.Status.ActiveCIDR
+	cidrB := networkB.Status.ActiveCIDR
+
+	// Shadow peers carry their real address, so both sides must be
+	// addressable without translation.
+	if peering.Spec.VirtualCIDRA != "" || peering.Spec.VirtualCIDRB != "" {
+		return r.setError(ctx, peering, "virtual CIDRs require gateway mode")
+	}
+	overlap, err := cidrsOverlap(cidrA, cidrB)
+	if err != nil {
+		return r.setError(ctx, peering, err.Error())
+	}
+	if overlap {
+		return r.setError(ctx, peering, fmt.Sprintf("mesh peering requires non-overlapping networks: %s overlaps %s", cidrA, cidrB))
+	}
+
+	// 1. Mirror the peers of each network into the other namespace.
+	peersA, err := r.syncMeshShadows(ctx, peering, peering.Spec.NamespaceA, networkA.Name, peering.Spec.NamespaceB, networkB.Name)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("mirror network A peers into namespace B: %w", err)
+	}
+	peersB, err := r.syncMeshShadows(ctx, peering, peering.Spec.NamespaceB, networkB.Name, peering.Spec.NamespaceA, networkA.Name)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("mirror network B peers into namespace A: %w", err)
+	}
+
+	// 2. Admit the mirrored peers into ComputedPeers on both sides.
+	if err := r.applyPolicy(ctx, meshPolicy(peering, networkA.Name, peering.Spec.NamespaceA)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("mesh policy namespace A: %w", err)
+	}
+	if err := r.applyPolicy(ctx, meshPolicy(peering, networkB.Name, peering.Spec.NamespaceB)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("mesh policy namespace B: %w", err)
+	}
+
+	// 3. Drop gateway resources of a previous mode.
+	r.cleanupGateway(ctx, peering)
+
+	// 4. Update status.
+	copy := peering.DeepCopy()
+	copy.Status.GatewaysA = nil
+	copy.Status.GatewaysB = nil
+	return r.setReady(ctx, peering, copy, v1alpha1.PeeringModeMesh, cidrA, cidrB, cidrA, cidrB, peersA, peersB)
 }
 
 // reconcileDelete removes all resources created by this reconciler and drops
@@ -155,31 +272,74 @@ func (r *NetworkPeeringReconciler) reconcileDelete(ctx context.Context, peering
 	log := logf.FromContext(ctx)
 	log.Info("Deleting LatticeNetworkPeering", "name", peering.Name)
 
-	annotationKey := peeringRouteAnnotationKey(peering.Name)
+	r.cleanupGateway(ctx, peering)
+	if err := r.cleanupMesh(ctx, peering); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	controllerutil.RemoveFinalizer(peering, PeeringFinalizer)
+	return ctrl.Result{}, r.Update(ctx, peering)
+}
+
+// cleanupGateway removes the gateway annotations, shadow peers and policies
+// of gateway mode. Failures are logged; the resources are not in the way of
+// mesh mode.
+func (r *NetworkPeeringReconciler) cleanupGateway(ctx context.Context, peering *v1alpha1.LatticeNetworkPeering) {
+	log := logf.FromContext(ctx)
+
 	shadowName := shadowPeerName(peering.Name)
 
-	// Remove peering-route annotations from gateway peers.
+	// Remove the per-peering annotations from gateway peers.
 	for _, ns := range []string{peering.Spec.NamespaceA, peering.Spec.NamespaceB} {
 		networkName := peering.Spec.NetworkA
 		if ns == peering.Spec.NamespaceB {
 			networkName = peering.Spec.NetworkB
 		}
-		if gw, err := r.findGateway(ctx, ns, networkName); err == nil && gw != nil {
-			if err := r.removeAnnotation(ctx, gw, annotationKey); err != nil {
-				log.Error(err, "failed to remove peering route annotation", "peer", gw.Name)
-			}
+		gateways, err := listGateways(ctx, r.Client, ns, networkName)
+		if err != nil {
+			log.Error(err, "failed to list gateway peers", "namespace", ns)
+			continue
+		}
+		if err := r.retireGateways(ctx, peering, gateways, nil); err != nil {
+			log.Error(err, "failed to remove peering annotations", "namespace", ns)
 		}
 	}
 
 	// Delete shadow peers and policies in both namespaces.
 	for _, ns := range []string{peering.Spec.NamespaceA, peering.Spec.NamespaceB} {
 		_ = r.deleteIfExists(ctx, &v1alpha1.LatticePeer{}, ns, shadowName)
+		var shadows v1alpha1.LatticePeerList
+		if err := r.List(ctx, &shadows, client.InNamespace(ns), client.MatchingLabels{
+			LabelGatewayPeering: safeLabelValue(peering.Name),
+		}); err == nil {
+			for i := range shadows.Items {
+				_ = r.Delete(ctx, &shadows.Items[i])
+			}
+		}
 		_ = r.deleteIfExists(ctx, &v1alpha1.LatticePolicy{}, ns, gwAccessPolicyName(peering.Name))
 		_ = r.deleteIfExists(ctx, &v1alpha1.LatticePolicy{}, ns, shadowPolicyName(peering.Name))
 	}
+}
 
-	controllerutil.RemoveFinalizer(peering, PeeringFinalizer)
-	return ctrl.Result{}, r.Update(ctx, peering)
+// cleanupMesh removes the mirrored peers and the policies of mesh mode.
+func (r *NetworkPeeringReconciler) cleanupMesh(ctx context.Context, peering *v1alpha1.LatticeNetworkPeering) error {
+	for _, ns := range []string{peering.Spec.NamespaceA, peering.Spec.NamespaceB} {
+		var shadows v1alpha1.LatticePeerList
+		if err := r.List(ctx, &shadows, client.InNamespace(ns), client.MatchingLabels{
+			LabelMeshPeering: safeLabelValue(peering.Name),
+		}); err != nil {
+			return err
+		}
+		for i := range shadows.Items {
+			if err := r.Delete(ctx, &shadows.Items[i]); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+		}
+		if err := r.deleteIfExists(ctx, &v1alpha1.LatticePolicy{}, ns, meshPolicyName(peering.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // getReadyNetwork fetches a LatticeNetwork and returns an error if it is not
@@ -196,86 +356,213 @@ func (r *NetworkPeeringReconciler) getReadyNetwork(ctx context.Context, ns, name
 	return &network, nil
 }
 
-// findGateway returns the first LatticePeer labeled alattice.io/gateway=true
-// in the given namespace that belongs to the given network.
-func (r *NetworkPeeringReconciler) findGateway(ctx context.Context, ns, networkName string) (*v1alpha1.LatticePeer, error) {
-	var peerList v1alpha1.LatticePeerList
-	if err := r.List(ctx, &peerList, client.InNamespace(ns), client.MatchingLabels{
-		LabelGateway:                 "true",
-		networkLabelKey(networkName): "true",
-	}); err != nil {
-		return nil, err
-	}
-	if len(peerList.Items) == 0 {
-		return nil, nil
+// retireGateways removes the per-peering annotations from the gateways
+// in all that are not active.
+func (r *NetworkPeeringReconciler) retireGateways(ctx context.Context, peering *v1alpha1.LatticeNetworkPeering, all []v1alpha1.LatticePeer, active []*v1alpha1.LatticePeer) error {
+	names := gatewayNames(active)
+	for i := range all {
+		gw := &all[i]
+		if slices.Contains(names, gw.Name) {
+			continue
+		}
+		if err := r.removeAnnotations(ctx, gw,
+			peeringRouteAnnotationKey(peering.Name),
+			peeringNetMapAnnotationKey(peering.Name),
+			peeringDNSAnnotationKey(peering.Name),
+			peeringGatewaysAnnotationKey(peering.Name)); err != nil {
+			return err
+		}
 	}
-	return &peerList.Items[0], nil
+	return nil
 }
 
-// ensureAnnotation adds or updates a single annotation on a LatticePeer.
-func (r *NetworkPeeringReconciler) ensureAnnotation(ctx context.Context, peer *v1alpha1.LatticePeer, key, value string) error {
-	current := peer.GetAnnotations()
-	if current[key] == value {
-		return nil
+// ensureGatewayAnnotations sets the per-peering annotations of a gateway
+// peer whose network (active, presented as virtual) is peered with a remote
+// network reached at mappedRemote: the route other local peers use, the
+// translation the gateway applies, and the DNS names of the remote peers.
+// group lists the members of an active-active gateway group; it is empty
+// for a single gateway.
+func (r *NetworkPeeringReconciler) ensureGatewayAnnotations(
+	ctx context.Context,
+	peering *v1alpha1.LatticeNetworkPeering,
+	gateway *v1alpha1.LatticePeer,
+	group string,
+	active, virtual, mappedRemote string,
+	remoteNS, remoteNetwork, remoteCIDR, remoteVirtual string,
+) error {
+	set := map[string]string{peeringRouteAnnotationKey(peering.Name): mappedRemote}
+	var remove []string
+
+	if virtual != "" {
+		netmap, err := peeringNetMap(active, virtual, mappedRemote)
+		if err != nil {
+			return err
+		}
+		set[peeringNetMapAnnotationKey(peering.Name)] = netmap
+	} else {
+		remove = append(remove, peeringNetMapAnnotationKey(peering.Name))
 	}
-	peerCopy := peer.DeepCopy()
+	if group != "" {
+		set[peeringGatewaysAnnotationKey(peering.Name)] = group
+	} else {
+		remove = append(remove, peeringGatewaysAnnotationKey(peering.Name))
+	}
+
+	addrs, err := r.peerAddresses(ctx, remoteNS, remoteNetwork)
+	if err != nil {
+		return err
+	}
+	records, err := peeringDNSRecords(addrs, remoteNS, remoteCIDR, remoteVirtual)
+	if err != nil {
+		return err
+	}
+	set[peeringDNSAnnotationKey(peering.Name)] = records
+
+	peerCopy := gateway.DeepCopy()
 	if peerCopy.Annotations == nil {
 		peerCopy.Annotations = make(map[string]string)
 	}
-	peerCopy.Annotations[key] = value
-	return r.Patch(ctx, peerCopy, client.MergeFrom(peer))
+	for k, v := range set {
+		peerCopy.Annotations[k] = v
+	}
+	for _, k := range remove {
+		delete(peerCopy.Annotations, k)
+	}
+	if equality.Semantic.DeepEqual(peerCopy.Annotations, gateway.Annotations) {
+		return nil
+	}
+	return r.Patch(ctx, peerCopy, client.MergeFrom(gateway))
 }
 
-// removeAnnotation deletes a single annotation from a LatticePeer.
-func (r *NetworkPeeringReconciler) removeAnnotation(ctx context.Context, peer *v1alpha1.LatticePeer, key string) error {
-	if _, ok := peer.GetAnnotations()[key]; !ok {
+// removeAnnotations deletes the given annotations from a LatticePeer.
+func (r *NetworkPeeringReconciler) removeAnnotations(ctx context.Context, peer *v1alpha1.LatticePeer, keys ...string) error {
+	peerCopy := peer.DeepCopy()
+	for _, key := range keys {
+		delete(peerCopy.Annotations, key)
+	}
+	if len(peerCopy.Annotations) == len(peer.Annotations) {
 		return nil
 	}
-	peerCopy := peer.DeepCopy()
-	delete(peerCopy.Annotations, key)
 	return r.Patch(ctx, peerCopy, client.MergeFrom(peer))
 }
 
-// ensureShadowPeer creates or updates the shadow LatticePeer that represents
-// srcGateway in the given target namespace.
-func (r *NetworkPeeringReconciler) ensureShadowPeer(
+// peerAddresses returns the addresses of the peers of a network, keyed by
+// peer name. Shadow peers are skipped so peerings do not chain.
+func (r *NetworkPeeringReconciler) peerAddresses(ctx context.Context, ns, networkName string) (map[string]string, error) {
+	var peers v1alpha1.LatticePeerList
+	if err := r.List(ctx, &peers, client.InNamespace(ns), client.MatchingLabels{
+		networkLabelKey(networkName): "true",
+	}); err != nil {
+		return nil, err
+	}
+	addrs := make(map[string]string, len(peers.Items))
+	for i := range peers.Items {
+		p := &peers.Items[i]
+		if p.Labels[LabelShadow] == "true" || p.Status.AllocatedAddress == nil {
+			continue
+		}
+		addrs[p.Name] = cleanIP(p.Status.AllocatedAddress)
+	}
+	return addrs, nil
+}
+
+// ensureShadowPeers creates or updates the shadow LatticePeers that
+// represent the active gateways of the source network in the target
+// namespace, and deletes those of gateways no longer active. When the
+// source network is presented as srcVirtual, the shadows carry translated
+// ranges and addresses, so they never collide with a local peer.
+//
+// A single gateway carries the whole source range. In an active-active
+// group each shadow carries the addresses of the source peers hashed to its
+// gateway, so return traffic goes back through the gateway it came from.
+func (r *NetworkPeeringReconciler) ensureShadowPeers(
 	ctx context.Context,
 	peering *v1alpha1.LatticeNetworkPeering,
-	srcGateway *v1alpha1.LatticePeer,
-	targetNS, targetNetwork, srcCIDR string,
+	gateways []*v1alpha1.LatticePeer,
+	srcNS, srcNetwork, targetNS, targetNetwork, srcCIDR, srcVirtual string,
 ) error {
-	name := shadowPeerName(peering.Name)
-	networkLabel := networkLabelKey(targetNetwork)
+	allowed := map[string][]string{}
+	if len(gateways) > 1 {
+		addrs, err := r.peerAddresses(ctx, srcNS, srcNetwork)
+		if err != nil {
+			return err
+		}
+		members := gatewayNames(gateways)
+		for name, addr := range addrs {
+			gw := gatewayForPeer(name, members)
+			allowed[gw] = append(allowed[gw], hostRoute(mappedAddress(addr, srcCIDR, srcVirtual)))
+		}
+	}
 
-	desired := &v1alpha1.LatticePeer{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: targetNS,
-			Labels: map[string]string{
-				LabelShadow:  "true",
-				networkLabel: "true",
+	want := make(map[string]struct{}, len(gateways))
+	for i, gw := range gateways {
+		name := gatewayShadowName(peering.Name, i)
+		want[name] = struct{}{}
+		allowedIPs := mappedCIDR(srcCIDR, srcVirtual)
+		if len(gateways) > 1 {
+			sort.Strings(allowed[gw.Name])
+			allowedIPs = strings.Join(allowed[gw.Name], ",")
+		}
+		desired := &v1alpha1.LatticePeer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: targetNS,
+				Labels: map[string]string{
+					LabelShadow:                    "true",
+					LabelGatewayPeering:            safeLabelValue(peering.Name),
+					networkLabelKey(targetNetwork): "true",
+				},
+				Annotations: map[string]string{
+					AnnotationShadowAllowedIPs: allowedIPs,
+				},
 			},
-			Annotations: map[string]string{
-				AnnotationShadowAllowedIPs: srcCIDR,
+			Spec: v1alpha1.LatticePeerSpec{
+				AppId:     gw.Spec.AppId,
+				PublicKey: gw.Spec.PublicKey,
+				PeerId:    gw.Spec.PeerId,
 			},
-		},
-		Spec: v1alpha1.LatticePeerSpec{
-			AppId:     srcGateway.Spec.AppId,
-			PublicKey: srcGateway.Spec.PublicKey,
-			PeerId:    srcGateway.Spec.PeerId,
-		},
+		}
+
+		address := gw.Status.AllocatedAddress
+		if address != nil && srcVirtual != "" {
+			mapped := mappedAddress(cleanIP(address), srcCIDR, srcVirtual)
+			address = &mapped
+		}
+		if err := r.applyShadowPeer(ctx, desired, address); err != nil {
+			return err
+		}
+	}
+
+	var shadows v1alpha1.LatticePeerList
+	if err := r.List(ctx, &shadows, client.InNamespace(targetNS), client.MatchingLabels{
+		LabelGatewayPeering: safeLabelValue(peering.Name),
+	}); err != nil {
+		return err
+	}
+	for i := range shadows.Items {
+		if _, ok := want[shadows.Items[i].Name]; ok {
+			continue
+		}
+		if err := r.Delete(ctx, &shadows.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+// applyShadowPeer creates desired or brings an existing shadow peer in line
+// with it, and copies address into its status so the peer appears in
+// WireGuard configs.
+func (r *NetworkPeeringReconciler) applyShadowPeer(ctx context.Context, desired *v1alpha1.LatticePeer, address *string) error {
 	var existing v1alpha1.LatticePeer
-	err := r.Get(ctx, types.NamespacedName{Namespace: targetNS, Name: name}, &existing)
+	err := r.Get(ctx, client.ObjectKeyFromObject(desired), &existing)
 	if k8serrors.IsNotFound(err) {
 		if createErr := r.Create(ctx, desired); createErr != nil {
 			return createErr
 		}
-		// Set Status.AllocatedAddress so the peer appears in WireGuard configs.
-		if srcGateway.Status.AllocatedAddress != nil {
+		if address != nil {
 			created := desired.DeepCopy()
-			created.Status.AllocatedAddress = srcGateway.Status.AllocatedAddress
+			created.Status.AllocatedAddress = address
 			created.Status.Phase = v1alpha1.NodePhaseReady
 			return r.Status().Update(ctx, created)
 		}
@@ -292,22 +579,112 @@ func (r *NetworkPeeringReconciler) ensureShadowPeer(
 	peerCopy.Spec.PublicKey = desired.Spec.PublicKey
 	peerCopy.Spec.AppId = desired.Spec.AppId
 	peerCopy.Spec.PeerId = desired.Spec.PeerId
-	if err := r.Patch(ctx, peerCopy, client.MergeFrom(&existing)); err != nil {
-		return err
+	if !equality.Semantic.DeepEqual(peerCopy, &existing) {
+		if err := r.Patch(ctx, peerCopy, client.MergeFrom(&existing)); err != nil {
+			return err
+		}
 	}
 
 	// Sync AllocatedAddress in status.
-	if srcGateway.Status.AllocatedAddress != nil &&
-		(existing.Status.AllocatedAddress == nil ||
-			*existing.Status.AllocatedAddress != *srcGateway.Status.AllocatedAddress) {
+	if address != nil &&
+		(existing.Status.AllocatedAddress == nil || *existing.Status.AllocatedAddress != *address) {
 		statusCopy := peerCopy.DeepCopy()
-		statusCopy.Status.AllocatedAddress = srcGateway.Status.AllocatedAddress
+		statusCopy.Status.AllocatedAddress = address
 		statusCopy.Status.Phase = v1alpha1.NodePhaseReady
 		return r.Status().Update(ctx, statusCopy)
 	}
 	return nil
 }
 
+// syncMeshShadows mirrors the peers of srcNetwork into dstNS as shadow peers
+// and deletes mirrors of peers that are gone. Shadow peers are not mirrored,
+// so peerings do not chain. It returns the number of mirrored peers.
+func (r *NetworkPeeringReconciler) syncMeshShadows(
+	ctx context.Context,
+	peering *v1alpha1.LatticeNetworkPeering,
+	srcNS, srcNetwork, dstNS, dstNetwork string,
+) (int32, error) {
+	var peers v1alpha1.LatticePeerList
+	if err := r.List(ctx, &peers, client.InNamespace(srcNS), client.MatchingLabels{
+		networkLabelKey(srcNetwork): "true",
+	}); err != nil {
+		return 0, err
+	}
+
+	peeringLabel := safeLabelValue(peering.Name)
+	want := make(map[string]struct{})
+	for i := range peers.Items {
+		src := &peers.Items[i]
+		if src.Labels[LabelShadow] == "true" || !src.DeletionTimestamp.IsZero() ||
+			src.Status.AllocatedAddress == nil || src.Spec.PublicKey == "" {
+			continue
+		}
+		name := meshShadowName(peering.Name, src.Name)
+		want[name] = struct{}{}
+		desired := &v1alpha1.LatticePeer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: dstNS,
+				Labels: map[string]string{
+					LabelShadow:                 "true",
+					LabelMeshPeering:            peeringLabel,
+					networkLabelKey(dstNetwork): "true",
+				},
+			},
+			Spec: v1alpha1.LatticePeerSpec{
+				AppId:     src.Spec.AppId,
+				PublicKey: src.Spec.PublicKey,
+				PeerId:    src.Spec.PeerId,
+			},
+		}
+		if err := r.applyShadowPeer(ctx, desired, src.Status.AllocatedAddress); err != nil {
+			return 0, fmt.Errorf("shadow of %s/%s: %w", srcNS, src.Name, err)
+		}
+	}
+
+	var shadows v1alpha1.LatticePeerList
+	if err := r.List(ctx, &shadows, client.InNamespace(dstNS), client.MatchingLabels{
+		LabelMeshPeering:            peeringLabel,
+		networkLabelKey(dstNetwork): "true",
+	}); err != nil {
+		return 0, err
+	}
+	for i := range shadows.Items {
+		if _, ok := want[shadows.Items[i].Name]; ok {
+			continue
+		}
+		if err := r.Delete(ctx, &shadows.Items[i]); client.IgnoreNotFound(err) != nil {
+			return 0, err
+		}
+	}
+	return int32(len(want)), nil
+}
+
+// meshPolicy admits the peers mirrored by peering into ComputedPeers of
+// every peer of the network, in both directions.
+func meshPolicy(peering *v1alpha1.LatticeNetworkPeering, networkName, ns string) *v1alpha1.LatticePolicy {
+	mirrored := []v1alpha1.PeerSelection{
+		{
+			PeerSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{LabelMeshPeering: safeLabelValue(peering.Name)},
+			},
+		},
+	}
+	return &v1alpha1.LatticePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      meshPolicyName(peering.Name),
+			Namespace: ns,
+			Labels:    map[string]string{"alattice.io/peering": safeLabelValue(peering.Name)},
+		},
+		Spec: v1alpha1.LatticePolicySpec{
+			Network:      networkName,
+			PeerSelector: metav1.LabelSelector{}, // match all peers
+			Egress:       []v1alpha1.EgressRule{{To: mirrored}},
+			Ingress:      []v1alpha1.IngressRule{{From: mirrored}},
+		},
+	}
+}
+
 // ensurePolicies creates or updates the two policies needed in a namespace:
 //  1. gwAccessPolicy  — allows all peers to egress to the gateway (so all local
 //     peers get the gateway in ComputedPeers with expanded AllowedIPs).
@@ -365,8 +742,13 @@ func (r *NetworkPeeringReconciler) ensurePolicies(ctx context.Context, peering *
 				{
 					To: []v1alpha1.PeerSelection{
 						{
+							// Gateway shadows only; peers mirrored by mesh
+							// peerings are reached directly.
 							PeerSelector: &metav1.LabelSelector{
 								MatchLabels: map[string]string{LabelShadow: "true"},
+								MatchExpressions: []metav1.LabelSelectorRequirement{
+									{Key: LabelMeshPeering, Operator: metav1.LabelSelectorOpDoesNotExist},
+								},
 							},
 						},
 					},
@@ -409,16 +791,22 @@ func (r *NetworkPeeringReconciler) deleteIfExists(ctx context.Context, obj clien
 	return err
 }
 
-func (r *NetworkPeeringReconciler) setReady(ctx context.Context, peering *v1alpha1.LatticeNetworkPeering, cidrA, cidrB string) (ctrl.Result, error) {
-	copy := peering.DeepCopy()
+// setReady marks the peering Ready on copy, a copy of peering that may
+// already carry mode-specific status, and patches the status.
+func (r *NetworkPeeringReconciler) setReady(ctx context.Context, peering, copy *v1alpha1.LatticeNetworkPeering, mode v1alpha1.PeeringMode, cidrA, cidrB, mappedA, mappedB string, peersA, peersB int32) (ctrl.Result, error) {
 	copy.Status.Phase = v1alpha1.NetworkPhaseReady
 	copy.Status.CIDRA = cidrA
 	copy.Status.CIDRB = cidrB
+	copy.Status.MappedCIDRA = mappedA
+	copy.Status.MappedCIDRB = mappedB
+	copy.Status.Mode = mode
+	copy.Status.PeersA = peersA
+	copy.Status.PeersB = peersB
 	copy.Status.Conditions = setCondition(copy.Status.Conditions, metav1.Condition{
 		Type:               "Ready",
 		Status:             metav1.ConditionTrue,
 		Reason:             "PeeringEstablished",
-		Message:            fmt.Sprintf("peering between %s and %s established", cidrA, cidrB),
+		Message:            fmt.Sprintf("%s peering between %s and %s established", mode, mappedA, mappedB),
 		LastTransitionTime: metav1.Now(),
 	})
 	return ctrl.Result{}, r.Status().Patch(ctx, copy, client.MergeFrom(peering))
@@ -459,6 +847,25 @@ func shadowPeerName(peeringName string) string {
 	return fmt.Sprintf("peering-shadow-%s", peeringName)
 }
 
+// gatewayShadowName returns the name of the shadow of the i-th active
+// gateway. The first keeps the name of the single-gateway shadow, so
+// growing or shrinking a group does not replace it.
+func gatewayShadowName(peeringName string, i int) string {
+	if i == 0 {
+		return shadowPeerName(peeringName)
+	}
+	return fmt.Sprintf("%s-%d", shadowPeerName(peeringName), i)
+}
+
+// gatewayGroup returns the gateway group annotation value of an
+// active-active group, or "" for a single gateway.
+func gatewayGroup(gateways []*v1alpha1.LatticePeer) string {
+	if len(gateways) < 2 {
+		return ""
+	}
+	return strings.Join(gatewayNames(gateways), ",")
+}
+
 func gwAccessPolicyName(peeringName string) string {
 	return fmt.Sprintf("lattice-peering-%s-gw-access", peeringName)
 }
@@ -467,6 +874,16 @@ func shadowPolicyName(peeringName string) string {
 	return fmt.Sprintf("lattice-peering-%s-shadow", peeringName)
 }
 
+func meshPolicyName(peeringName string) string {
+	return fmt.Sprintf("lattice-peering-%s-mesh", peeringName)
+}
+
+// meshShadowName returns the name of the mirror of peerName created by a
+// mesh peering; it is at most 63 characters.
+func meshShadowName(peeringName, peerName string) string {
+	return safeKeyName("peering-mesh-", peeringName+"-"+peerName)
+}
+
 // SetupWithManager registers the controller with the manager.
 func (r *NetworkPeeringReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Only re-enqueue peerings when a network's Phase or ActiveCIDR actually
@@ -487,12 +904,27 @@ func (r *NetworkPeeringReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		},
 	}
 
+	// Peer addresses are assigned in status and gateways are designated by
+	// label, neither of which GenerationChangedPredicate sees.
+	peerAddressPredicate := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPeer, ok1 := e.ObjectOld.(*v1alpha1.LatticePeer)
+			newPeer, ok2 := e.ObjectNew.(*v1alpha1.LatticePeer)
+			if !ok1 || !ok2 {
+				return false
+			}
+			return !equality.Semantic.DeepEqual(oldPeer.Status.AllocatedAddress, newPeer.Status.AllocatedAddress) ||
+				oldPeer.Labels[LabelGateway] != newPeer.Labels[LabelGateway]
+		},
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.LatticeNetworkPeering{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
-		// Re-enqueue peerings when a gateway peer in either network comes online.
+		// Re-enqueue peerings when a gateway peer in either network comes
+		// online, or a peer gets its address (mesh mode mirrors every peer).
 		Watches(&v1alpha1.LatticePeer{},
 			handler.EnqueueRequestsFromMapFunc(r.mapPeerToPeerings),
-			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, peerAddressPredicate))).
 		// Re-enqueue only when a network's Phase or ActiveCIDR changes.
 		Watches(&v1alpha1.LatticeNetwork{},
 			handler.EnqueueRequestsFromMapFunc(r.mapNetworkToPeerings),
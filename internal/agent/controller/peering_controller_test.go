@@ -0,0 +1,478 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/infra"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNetworkPeering_MeshMirrorsPeersAndSwitchesModes(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	network := func(ns, name, cidr string) *v1alpha1.LatticeNetwork {
+		return &v1alpha1.LatticeNetwork{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Status:     v1alpha1.LatticeNetworkStatus{Phase: v1alpha1.NetworkPhaseReady, ActiveCIDR: cidr},
+		}
+	}
+	peer := func(ns, network, name, addr string, labels map[string]string) *v1alpha1.LatticePeer {
+		p := &v1alpha1.LatticePeer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: map[string]string{networkLabelKey(network): "true"}},
+			Spec:       v1alpha1.LatticePeerSpec{AppId: name, PublicKey: "key-" + name},
+			Status:     v1alpha1.LatticePeerStatus{AllocatedAddress: &addr},
+		}
+		for k, v := range labels {
+			p.Labels[k] = v
+		}
+		return p
+	}
+	peering := &v1alpha1.LatticeNetworkPeering{
+		ObjectMeta: metav1.ObjectMeta{Name: "ab"},
+		Spec: v1alpha1.LatticeNetworkPeeringSpec{
+			NamespaceA: "team-a", NetworkA: "net-a",
+			NamespaceB: "team-b", NetworkB: "net-b",
+			PeeringMode: v1alpha1.PeeringModeMesh,
+		},
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.LatticePeer{}, &v1alpha1.LatticeNetworkPeering{}).
+		WithObjects(
+			network("team-a", "net-a", "10.1.0.0/24"),
+			network("team-b", "net-b", "10.2.0.0/24"),
+			peer("team-a", "net-a", "a1", "10.1.0.2", map[string]string{LabelGateway: "true"}),
+			peer("team-a", "net-a", "other-shadow", "10.9.0.1", map[string]string{LabelShadow: "true"}),
+			peer("team-b", "net-b", "b1", "10.2.0.2", map[string]string{LabelGateway: "true"}),
+			peer("team-b", "net-b", "b2", "10.2.0.3", nil),
+			peering,
+		).
+		Build()
+	r := &NetworkPeeringReconciler{Client: c, Scheme: scheme}
+
+	reconcile := func() *v1alpha1.LatticeNetworkPeering {
+		t.Helper()
+		var current v1alpha1.LatticeNetworkPeering
+		if err := c.Get(ctx, types.NamespacedName{Name: "ab"}, &current); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.reconcileNormal(ctx, &current); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Get(ctx, types.NamespacedName{Name: "ab"}, &current); err != nil {
+			t.Fatal(err)
+		}
+		return &current
+	}
+	mirrors := func(ns string) map[string]string {
+		t.Helper()
+		var list v1alpha1.LatticePeerList
+		if err := c.List(ctx, &list, client.InNamespace(ns), client.MatchingLabels{LabelMeshPeering: "ab"}); err != nil {
+			t.Fatal(err)
+		}
+		out := make(map[string]string)
+		for _, p := range list.Items {
+			out[p.Spec.AppId] = *p.Status.AllocatedAddress
+		}
+		return out
+	}
+	exists := func(obj client.Object, ns, name string) bool {
+		t.Helper()
+		err := c.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, obj)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+
+	got := reconcile()
+	if got.Status.Phase != v1alpha1.NetworkPhaseReady || got.Status.Mode != v1alpha1.PeeringModeMesh ||
+		got.Status.PeersA != 1 || got.Status.PeersB != 2 {
+		t.Fatalf("mesh status = %+v, want ready with 1 and 2 peers", got.Status)
+	}
+	if m := mirrors("team-b"); len(m) != 1 || m["a1"] != "10.1.0.2" {
+		t.Errorf("mirrors in team-b = %v, want a1 only", m)
+	}
+	if m := mirrors("team-a"); len(m) != 2 || m["b1"] != "10.2.0.2" || m["b2"] != "10.2.0.3" {
+		t.Errorf("mirrors in team-a = %v, want b1 and b2", m)
+	}
+	for _, ns := range []string{"team-a", "team-b"} {
+		if !exists(&v1alpha1.LatticePolicy{}, ns, meshPolicyName("ab")) {
+			t.Errorf("mesh policy missing in %s", ns)
+		}
+	}
+
+	// A removed peer loses its mirror.
+	if err := c.Delete(ctx, &v1alpha1.LatticePeer{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "b2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got = reconcile(); got.Status.PeersB != 1 {
+		t.Errorf("PeersB = %d after removing b2, want 1", got.Status.PeersB)
+	}
+	if m := mirrors("team-a"); len(m) != 1 {
+		t.Errorf("mirrors in team-a = %v, want b1 only", m)
+	}
+
+	// Switching to gateway mode builds the gateway path and then drops the mesh.
+	got.Spec.PeeringMode = v1alpha1.PeeringModeGateway
+	if err := c.Update(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	if got = reconcile(); got.Status.Mode != v1alpha1.PeeringModeGateway || got.Status.PeersA != 1 {
+		t.Errorf("gateway status = %+v", got.Status)
+	}
+	if len(mirrors("team-a")) != 0 || len(mirrors("team-b")) != 0 {
+		t.Error("mesh mirrors left after switching to gateway mode")
+	}
+	for _, ns := range []string{"team-a", "team-b"} {
+		if !exists(&v1alpha1.LatticePeer{}, ns, shadowPeerName("ab")) {
+			t.Errorf("gateway shadow missing in %s", ns)
+		}
+		if exists(&v1alpha1.LatticePolicy{}, ns, meshPolicyName("ab")) {
+			t.Errorf("mesh policy left in %s", ns)
+		}
+	}
+}
+
+func TestNetworkPeering_MeshRejectsOverlappingNetworks(t *testing.T) {
+	overlap, err := cidrsOverlap("10.1.0.0/16", "10.1.2.0/24")
+	if err != nil || !overlap {
+		t.Errorf("cidrsOverlap(10.1.0.0/16, 10.1.2.0/24) = %v, %v; want true", overlap, err)
+	}
+	if overlap, _ = cidrsOverlap("10.1.0.0/24", "10.2.0.0/24"); overlap {
+		t.Error("disjoint networks reported as overlapping")
+	}
+	if _, err = cidrsOverlap("10.1.0.0", "10.2.0.0/24"); err == nil {
+		t.Error("expected an error for an invalid CIDR")
+	}
+}
+
+func TestNetworkPeering_GatewayTranslatesOverlappingNetworks(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	network := func(ns, name string) *v1alpha1.LatticeNetwork {
+		return &v1alpha1.LatticeNetwork{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Status:     v1alpha1.LatticeNetworkStatus{Phase: v1alpha1.NetworkPhaseReady, ActiveCIDR: "10.10.1.0/24"},
+		}
+	}
+	peer := func(ns, network, name, addr string, gateway bool) *v1alpha1.LatticePeer {
+		p := &v1alpha1.LatticePeer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: map[string]string{networkLabelKey(network): "true"}},
+			Spec:       v1alpha1.LatticePeerSpec{AppId: name, PublicKey: "key-" + name},
+			Status:     v1alpha1.LatticePeerStatus{AllocatedAddress: &addr},
+		}
+		if gateway {
+			p.Labels[LabelGateway] = "true"
+		}
+		return p
+	}
+	peering := &v1alpha1.LatticeNetworkPeering{
+		ObjectMeta: metav1.ObjectMeta{Name: "ab"},
+		Spec: v1alpha1.LatticeNetworkPeeringSpec{
+			NamespaceA: "team-a", NetworkA: "net-a",
+			NamespaceB: "team-b", NetworkB: "net-b",
+		},
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.LatticePeer{}, &v1alpha1.LatticeNetworkPeering{}).
+		WithObjects(
+			network("team-a", "net-a"),
+			network("team-b", "net-b"),
+			peer("team-a", "net-a", "a1", "10.10.1.2", true),
+			peer("team-b", "net-b", "b1", "10.10.1.2", true),
+			peer("team-b", "net-b", "b2", "10.10.1.7", false),
+			peering,
+		).
+		Build()
+	r := &NetworkPeeringReconciler{Client: c, Scheme: scheme}
+
+	reconcile := func(mutate func(p *v1alpha1.LatticeNetworkPeering)) *v1alpha1.LatticeNetworkPeering {
+		t.Helper()
+		var current v1alpha1.LatticeNetworkPeering
+		if err := c.Get(ctx, types.NamespacedName{Name: "ab"}, &current); err != nil {
+			t.Fatal(err)
+		}
+		if mutate != nil {
+			mutate(&current)
+			if err := c.Update(ctx, &current); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := r.reconcileNormal(ctx, &current); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Get(ctx, types.NamespacedName{Name: "ab"}, &current); err != nil {
+			t.Fatal(err)
+		}
+		return &current
+	}
+	get := func(ns, name string) *v1alpha1.LatticePeer {
+		t.Helper()
+		var p v1alpha1.LatticePeer
+		if err := c.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, &p); err != nil {
+			t.Fatal(err)
+		}
+		return &p
+	}
+
+	// Identical address plans cannot be peered without translation.
+	if got := reconcile(nil); got.Status.Phase != v1alpha1.NetworkPhaseFailed {
+		t.Fatalf("phase = %s, want Failed for overlapping networks", got.Status.Phase)
+	}
+
+	got := reconcile(func(p *v1alpha1.LatticeNetworkPeering) {
+		p.Spec.VirtualCIDRA = "100.64.1.0/24"
+		p.Spec.VirtualCIDRB = "100.64.2.0/24"
+	})
+	if got.Status.Phase != v1alpha1.NetworkPhaseReady ||
+		got.Status.MappedCIDRA != "100.64.1.0/24" || got.Status.MappedCIDRB != "100.64.2.0/24" {
+		t.Fatalf("status = %+v, want ready with mapped ranges", got.Status)
+	}
+
+	// The shadow of gateway B in team-a is reached at its translated address
+	// and routes the virtual range of net-b, so it never collides with a1.
+	shadow := transferToPeer(get("team-a", shadowPeerName("ab")))
+	if *shadow.Address != "100.64.2.2" || shadow.AllowedIPs != "100.64.2.2/32,100.64.2.0/24" {
+		t.Errorf("shadow of gateway B = %s %q, want 100.64.2.2 routing 100.64.2.0/24", *shadow.Address, shadow.AllowedIPs)
+	}
+
+	gwA := transferToPeer(get("team-a", "a1"))
+	if gwA.AllowedIPs != "10.10.1.2/32,100.64.2.0/24" {
+		t.Errorf("gateway A AllowedIPs = %q, want the virtual range of net-b", gwA.AllowedIPs)
+	}
+	wantMap := []infra.NetMap{{Local: "10.10.1.0/24", Virtual: "100.64.1.0/24", Remote: "100.64.2.0/24"}}
+	if !reflect.DeepEqual(gwA.NetMaps, wantMap) {
+		t.Errorf("gateway A netmaps = %+v, want %+v", gwA.NetMaps, wantMap)
+	}
+	wantDNS := map[string]string{"b1.team-b.lattice": "100.64.2.2", "b2.team-b.lattice": "100.64.2.7"}
+	if !reflect.DeepEqual(gwA.DNSRecords, wantDNS) {
+		t.Errorf("gateway A DNS records = %v, want %v", gwA.DNSRecords, wantDNS)
+	}
+
+	// Dropping the translation of one side removes its netmap.
+	reconcile(func(p *v1alpha1.LatticeNetworkPeering) {
+		p.Spec.VirtualCIDRA = ""
+		p.Spec.VirtualCIDRB = "100.64.2.0/24"
+	})
+	if got := reconcile(nil); got.Status.Phase != v1alpha1.NetworkPhaseFailed {
+		t.Fatalf("phase = %s, want Failed when net-a is reached at an overlapping range", got.Status.Phase)
+	}
+	if maps := transferToPeer(get("team-a", "a1")).NetMaps; len(maps) != 1 {
+		t.Errorf("gateway A netmaps = %+v, want the last applied translation kept while failing", maps)
+	}
+}
+
+func TestNetworkPeering_GatewayGroupFailover(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	network := func(ns, name, cidr string) *v1alpha1.LatticeNetwork {
+		return &v1alpha1.LatticeNetwork{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Status:     v1alpha1.LatticeNetworkStatus{Phase: v1alpha1.NetworkPhaseReady, ActiveCIDR: cidr},
+		}
+	}
+	heartbeat := time.Now().UTC().Format(time.RFC3339)
+	peer := func(ns, network, name, addr string, gateway bool) *v1alpha1.LatticePeer {
+		p := &v1alpha1.LatticePeer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: map[string]string{networkLabelKey(network): "true"}},
+			Spec:       v1alpha1.LatticePeerSpec{AppId: name, PublicKey: "key-" + name},
+			Status:     v1alpha1.LatticePeerStatus{AllocatedAddress: &addr},
+		}
+		if gateway {
+			p.Labels[LabelGateway] = "true"
+			p.Annotations = map[string]string{v1alpha1.AnnotationGatewayHeartbeat: heartbeat}
+		}
+		return p
+	}
+	peering := &v1alpha1.LatticeNetworkPeering{
+		ObjectMeta: metav1.ObjectMeta{Name: "ab"},
+		Spec: v1alpha1.LatticeNetworkPeeringSpec{
+			NamespaceA: "team-a", NetworkA: "net-a",
+			NamespaceB: "team-b", NetworkB: "net-b",
+			GatewayMode: v1alpha1.GatewayModeActiveActive,
+		},
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.LatticePeer{}, &v1alpha1.LatticeNetworkPeering{}).
+		WithObjects(
+			network("team-a", "net-a", "10.1.0.0/24"),
+			network("team-b", "net-b", "10.2.0.0/24"),
+			peer("team-a", "net-a", "a1", "10.1.0.2", true),
+			peer("team-a", "net-a", "a2", "10.1.0.3", true),
+			peer("team-a", "net-a", "a3", "10.1.0.4", false),
+			peer("team-b", "net-b", "b1", "10.2.0.2", true),
+			peering,
+		).
+		Build()
+	r := &NetworkPeeringReconciler{Client: c, Scheme: scheme}
+
+	reconcile := func() (*v1alpha1.LatticeNetworkPeering, ctrl.Result) {
+		t.Helper()
+		var current v1alpha1.LatticeNetworkPeering
+		if err := c.Get(ctx, types.NamespacedName{Name: "ab"}, &current); err != nil {
+			t.Fatal(err)
+		}
+		res, err := r.reconcileNormal(ctx, &current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Get(ctx, types.NamespacedName{Name: "ab"}, &current); err != nil {
+			t.Fatal(err)
+		}
+		return &current, res
+	}
+	get := func(ns, name string) *v1alpha1.LatticePeer {
+		t.Helper()
+		var p v1alpha1.LatticePeer
+		if err := c.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, &p); err != nil {
+			t.Fatal(err)
+		}
+		return &p
+	}
+
+	// Both gateways of net-a carry traffic; a3 routes through one of them
+	// and the shadow of that gateway in team-b carries a3's address.
+	got, res := reconcile()
+	if !reflect.DeepEqual(got.Status.GatewaysA, []string{"a1", "a2"}) || got.Status.PeersA != 2 {
+		t.Fatalf("status = %+v, want gateways a1 and a2 active", got.Status)
+	}
+	if res.RequeueAfter != gatewayElectionInterval {
+		t.Errorf("RequeueAfter = %v, want periodic re-election", res.RequeueAfter)
+	}
+	via := gatewayForPeer("a3", []string{"a1", "a2"})
+	for _, name := range []string{"a1", "a2"} {
+		routes := strings.Contains(transferToPeerFor(get("team-a", name), "a3").AllowedIPs, "10.2.0.0/24")
+		if routes != (name == via) {
+			t.Errorf("a3 routes net-b via %s = %v, want only via %s", name, routes, via)
+		}
+	}
+	for i, name := range []string{"a1", "a2"} {
+		shadow := get("team-b", gatewayShadowName("ab", i))
+		allowed := shadow.Annotations[AnnotationShadowAllowedIPs]
+		if shadow.Spec.PublicKey != "key-"+name || strings.Contains(allowed, "10.1.0.4/32") != (name == via) {
+			t.Errorf("shadow %d = %s %q, want %s carrying a3 only if it is a3's gateway", i, shadow.Spec.PublicKey, allowed, name)
+		}
+	}
+
+	// a1 stops sending heartbeats: a2 takes over the whole range.
+	a1 := get("team-a", "a1")
+	a1.Annotations[v1alpha1.AnnotationGatewayHeartbeat] = time.Now().Add(-5 * time.Minute).UTC().Format(time.RFC3339)
+	if err := c.Update(ctx, a1); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = reconcile()
+	if !reflect.DeepEqual(got.Status.GatewaysA, []string{"a2"}) {
+		t.Fatalf("GatewaysA = %v, want [a2]", got.Status.GatewaysA)
+	}
+	if _, ok := get("team-a", "a1").Annotations[peeringRouteAnnotationKey("ab")]; ok {
+		t.Error("failed gateway a1 still routes the peering")
+	}
+	a2 := get("team-a", "a2")
+	if _, ok := a2.Annotations[peeringGatewaysAnnotationKey("ab")]; ok {
+		t.Error("single gateway a2 still carries a group annotation")
+	}
+	shadow := get("team-b", gatewayShadowName("ab", 0))
+	if shadow.Spec.PublicKey != "key-a2" || shadow.Annotations[AnnotationShadowAllowedIPs] != "10.1.0.0/24" {
+		t.Errorf("shadow = %s %q, want a2 routing 10.1.0.0/24", shadow.Spec.PublicKey, shadow.Annotations[AnnotationShadowAllowedIPs])
+	}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "team-b", Name: gatewayShadowName("ab", 1)}, &v1alpha1.LatticePeer{}); !k8serrors.IsNotFound(err) {
+		t.Errorf("shadow of the failed gateway not removed: %v", err)
+	}
+
+	// In active-standby mode a recovered a1 does not take the traffic back.
+	a1 = get("team-a", "a1")
+	a1.Annotations[v1alpha1.AnnotationGatewayHeartbeat] = time.Now().UTC().Format(time.RFC3339)
+	if err := c.Update(ctx, a1); err != nil {
+		t.Fatal(err)
+	}
+	var current v1alpha1.LatticeNetworkPeering
+	if err := c.Get(ctx, types.NamespacedName{Name: "ab"}, &current); err != nil {
+		t.Fatal(err)
+	}
+	current.Spec.GatewayMode = v1alpha1.GatewayModeActiveStandby
+	if err := c.Update(ctx, &current); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = reconcile(); !reflect.DeepEqual(got.Status.GatewaysA, []string{"a2"}) {
+		t.Errorf("GatewaysA = %v, want a2 kept", got.Status.GatewaysA)
+	}
+}
+
+func TestPeeringRanges(t *testing.T) {
+	tests := []struct {
+		name                                       string
+		local, localVirtual, remote, remoteVirtual string
+		wantLocal, wantRemote                      string
+		wantErr                                    bool
+	}{
+		{name: "disjoint", local: "10.1.0.0/24", remote: "10.2.0.0/24", wantLocal: "10.1.0.0/24", wantRemote: "10.2.0.0/24"},
+		{name: "overlap", local: "10.1.0.0/24", remote: "10.1.0.0/24", wantErr: true},
+		{name: "translated", local: "10.1.0.0/24", localVirtual: "100.64.1.0/24", remote: "10.1.0.0/24", remoteVirtual: "100.64.2.0/24",
+			wantLocal: "100.64.1.0/24", wantRemote: "100.64.2.0/24"},
+		{name: "one side translated", local: "10.1.0.0/24", localVirtual: "100.64.1.0/24", remote: "10.1.0.0/24", wantErr: true},
+		{name: "prefix length differs", local: "10.1.0.0/24", localVirtual: "100.64.0.0/16", remote: "10.2.0.0/24", wantErr: true},
+		{name: "virtual overlaps remote", local: "10.1.0.0/24", localVirtual: "10.2.0.0/24", remote: "10.2.0.0/24", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLocal, gotRemote, err := peeringRanges(tt.local, tt.localVirtual, tt.remote, tt.remoteVirtual)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotLocal != tt.wantLocal || gotRemote != tt.wantRemote {
+				t.Errorf("ranges = %s, %s; want %s, %s", gotLocal, gotRemote, tt.wantLocal, tt.wantRemote)
+			}
+		})
+	}
+
+	if got := mappedAddress("10.1.0.77", "10.1.0.0/24", "100.64.3.0/24"); got != "100.64.3.77" {
+		t.Errorf("mappedAddress = %s, want 100.64.3.77", got)
+	}
+	if got := mappedAddress("10.1.0.77", "10.1.0.0/20", "100.64.16.0/20"); got != "100.64.16.77" {
+		t.Errorf("mappedAddress across a partial byte = %s, want 100.64.16.77", got)
+	}
+}
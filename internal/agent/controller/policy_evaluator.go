@@ -33,9 +33,16 @@ type PolicyEvaluator interface {
 type ruleDecisionKey struct {
 	peer     string // IP or CIDR
 	port     int
+	endPort  int
 	protocol string
 }
 
+// ruleDecision is the action for a flow and the policy that set it.
+type ruleDecision struct {
+	action string
+	policy string
+}
+
 type policyEvaluator struct{}
 
 // NewPolicyEvaluator returns a PolicyEvaluator with ALLOW-priority conflict resolution.
@@ -64,17 +71,17 @@ func (e *policyEvaluator) Evaluate(ctx context.Context, currentPeer *infra.Peer,
 		Egress:   make([]infra.TrafficRule, 0),
 	}
 
-	// decisions maps (peer, port, protocol) → "ALLOW" | "DENY"
-	// ALLOW is sticky: once set it cannot be overwritten.
-	ingressDecisions := make(map[ruleDecisionKey]string)
-	egressDecisions := make(map[ruleDecisionKey]string)
+	// decisions maps (peer, port, protocol) → "ALLOW" | "DENY" and the
+	// deciding policy. ALLOW is sticky: once set it cannot be overwritten.
+	ingressDecisions := make(map[ruleDecisionKey]ruleDecision)
+	egressDecisions := make(map[ruleDecisionKey]ruleDecision)
 
-	applyDecision := func(decisions map[ruleDecisionKey]string, rule *infra.Rule) {
-		peers := e.resolveRulePeers(rule, peerIPByName, currentPeer.Name)
+	applyDecision := func(decisions map[ruleDecisionKey]ruleDecision, policy string, rule *infra.Rule) {
+		peers := resolveRulePeers(rule, peerIPByName, currentPeer.Name)
 		for _, peer := range peers {
-			k := ruleDecisionKey{peer: peer, port: rule.Port, protocol: rule.Protocol}
-			if decisions[k] != "ALLOW" {
-				decisions[k] = rule.Action
+			k := ruleDecisionKey{peer: peer, port: rule.Port, endPort: rule.EndPort, protocol: rule.Protocol}
+			if decisions[k].action != "ALLOW" {
+				decisions[k] = ruleDecision{action: rule.Action, policy: policy}
 			}
 		}
 	}
@@ -87,37 +94,41 @@ func (e *policyEvaluator) Evaluate(ctx context.Context, currentPeer *infra.Peer,
 			if rule == nil {
 				continue
 			}
-			applyDecision(ingressDecisions, rule)
+			applyDecision(ingressDecisions, policy.PolicyName, rule)
 		}
 		for _, rule := range policy.Egress {
 			if rule == nil {
 				continue
 			}
-			applyDecision(egressDecisions, rule)
+			applyDecision(egressDecisions, policy.PolicyName, rule)
 		}
 	}
 
 	// Emit TrafficRules from decisions.
-	for k, action := range ingressDecisions {
-		iptAction := toIPTAction(action)
+	for k, d := range ingressDecisions {
+		iptAction := toIPTAction(d.action)
 		tr := infra.TrafficRule{
 			ChainName: "LATTICE-INGRESS",
 			Peers:     []string{k.peer},
 			Port:      k.port,
+			EndPort:   k.endPort,
 			Protocol:  k.protocol,
 			Action:    iptAction,
+			Policy:    d.policy,
 		}
 		result.Ingress = append(result.Ingress, tr)
 	}
 
-	for k, action := range egressDecisions {
-		iptAction := toIPTAction(action)
+	for k, d := range egressDecisions {
+		iptAction := toIPTAction(d.action)
 		tr := infra.TrafficRule{
 			ChainName: "LATTICE-EGRESS",
 			Peers:     []string{k.peer},
 			Port:      k.port,
+			EndPort:   k.endPort,
 			Protocol:  k.protocol,
 			Action:    iptAction,
+			Policy:    d.policy,
 		}
 		result.Egress = append(result.Egress, tr)
 	}
@@ -126,10 +137,12 @@ func (e *policyEvaluator) Evaluate(ctx context.Context, currentPeer *infra.Peer,
 	result.Ingress = append(result.Ingress, infra.TrafficRule{
 		ChainName: "LATTICE-INGRESS",
 		Action:    "DROP",
+		Policy:    infra.DefaultDenyPolicy,
 	})
 	result.Egress = append(result.Egress, infra.TrafficRule{
 		ChainName: "LATTICE-EGRESS",
 		Action:    "DROP",
+		Policy:    infra.DefaultDenyPolicy,
 	})
 
 	log.Info("PolicyEvaluator done",
@@ -140,7 +153,7 @@ func (e *policyEvaluator) Evaluate(ctx context.Context, currentPeer *infra.Peer,
 }
 
 // resolveRulePeers returns the IP/CIDR list for a rule, skipping currentPeerName.
-func (e *policyEvaluator) resolveRulePeers(rule *infra.Rule, peerIPByName map[string]string, currentPeerName string) []string {
+func resolveRulePeers(rule *infra.Rule, peerIPByName map[string]string, currentPeerName string) []string {
 	var peers []string
 	for _, name := range rule.PeerNames {
 		if name == currentPeerName {
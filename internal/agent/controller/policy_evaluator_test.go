@@ -121,6 +121,8 @@ var _ = Describe("PolicyEvaluator", func() {
 			acceptRules := filterByAction(result.Ingress, "ACCEPT")
 			Expect(acceptRules).To(HaveLen(1))
 			Expect(acceptRules[0].Peers).To(ContainElement("10.0.0.2"))
+			Expect(acceptRules[0].Policy).To(Equal("allow-policy"))
+			Expect(result.Ingress[len(result.Ingress)-1].Policy).To(Equal(infra.DefaultDenyPolicy))
 		})
 	})
 
@@ -0,0 +1,92 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strings"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+// buildQoS collects the traffic shaping of current: its own bandwidth limit
+// and class, and the rate limits and classes of the rules of its ALLOW
+// policies. It returns nil when none is configured.
+func buildQoS(current *v1alpha1.LatticePeer, network *infra.Network, policies []*infra.Policy) *infra.QoS {
+	q := &infra.QoS{}
+	if current.Spec.QoSClass != v1alpha1.QoSClassStandard {
+		q.Class = string(current.Spec.QoSClass)
+	}
+	if bw := current.Spec.Bandwidth; bw != nil {
+		q.EgressMbps = int(bw.EgressMbps)
+		q.IngressMbps = int(bw.IngressMbps)
+		q.BurstKB = int(bw.BurstKB)
+	}
+
+	peerIPByName := make(map[string]string)
+	if network != nil {
+		for _, p := range network.Peers {
+			if p.Address != nil {
+				peerIPByName[p.Name] = cleanIP(p.Address)
+			}
+		}
+	}
+	for _, policy := range policies {
+		if policy == nil || !strings.EqualFold(policy.Action, "ALLOW") {
+			continue
+		}
+		q.Ingress = appendQoSRules(q.Ingress, policy.PolicyName, policy.Ingress, peerIPByName, current.Name)
+		q.Egress = appendQoSRules(q.Egress, policy.PolicyName, policy.Egress, peerIPByName, current.Name)
+	}
+
+	if q.Empty() {
+		return nil
+	}
+	return q
+}
+
+// appendQoSRules appends the rules that carry a rate limit or a class.
+// Rules that resolve to no peer are skipped: an empty peer list would match
+// all traffic.
+func appendQoSRules(dst []infra.QoSRule, policy string, rules []*infra.Rule, peerIPByName map[string]string, currentPeerName string) []infra.QoSRule {
+	for _, r := range rules {
+		if r == nil || (r.RateMbps == 0 && r.QoSClass == "") {
+			continue
+		}
+		peers := resolveRulePeers(r, peerIPByName, currentPeerName)
+		if len(peers) == 0 {
+			continue
+		}
+		dst = append(dst, infra.QoSRule{
+			Peers:    peers,
+			Protocol: r.Protocol,
+			Port:     r.Port,
+			EndPort:  r.EndPort,
+			RateMbps: r.RateMbps,
+			BurstKB:  r.BurstKB,
+			Class:    r.QoSClass,
+			Policy:   policy,
+		})
+	}
+	return dst
+}
+
+// rateLimit returns the rate and burst of rl, zero when unset.
+func rateLimit(rl *v1alpha1.RateLimit) (mbps, burstKB int) {
+	if rl == nil {
+		return 0, 0
+	}
+	return int(rl.Mbps), int(rl.BurstKB)
+}
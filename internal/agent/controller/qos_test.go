@@ -0,0 +1,90 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+var _ = Describe("buildQoS", func() {
+	var (
+		current *v1alpha1.LatticePeer
+		network *infra.Network
+	)
+
+	BeforeEach(func() {
+		current = &v1alpha1.LatticePeer{ObjectMeta: metav1.ObjectMeta{Name: "backup-1"}}
+		network = &infra.Network{
+			Peers: []*infra.Peer{
+				{Name: "backup-1", Address: strPtr("10.0.0.1")},
+				{Name: "nas-1", Address: strPtr("10.0.0.2")},
+			},
+		}
+	})
+
+	It("returns nil when nothing is configured", func() {
+		current.Spec.QoSClass = v1alpha1.QoSClassStandard
+		policies := []*infra.Policy{{
+			PolicyName: "allow-nas",
+			Action:     "ALLOW",
+			Egress:     []*infra.Rule{{PeerNames: []string{"nas-1"}}},
+		}}
+		Expect(buildQoS(current, network, policies)).To(BeNil())
+	})
+
+	It("carries the peer limit and the shaped rules of ALLOW policies", func() {
+		current.Spec.Bandwidth = &v1alpha1.BandwidthLimit{EgressMbps: 100, IngressMbps: 50}
+		current.Spec.QoSClass = v1alpha1.QoSClassBulk
+		policies := []*infra.Policy{
+			{
+				PolicyName: "backup",
+				Action:     "ALLOW",
+				Egress: []*infra.Rule{
+					{PeerNames: []string{"nas-1"}, Protocol: "tcp", Port: 873, RateMbps: 20, QoSClass: infra.QoSBulk},
+					{PeerNames: []string{"nas-1"}, Protocol: "tcp", Port: 22},
+				},
+				Ingress: []*infra.Rule{
+					// Resolves to no peer: skipped rather than matching everything.
+					{PeerNames: []string{"backup-1"}, RateMbps: 5},
+				},
+			},
+			{
+				PolicyName: "deny-nas",
+				Action:     "DENY",
+				Egress:     []*infra.Rule{{PeerNames: []string{"nas-1"}, RateMbps: 1}},
+			},
+		}
+
+		q := buildQoS(current, network, policies)
+		Expect(q).NotTo(BeNil())
+		Expect(q.EgressMbps).To(Equal(100))
+		Expect(q.IngressMbps).To(Equal(50))
+		Expect(q.Class).To(Equal(infra.QoSBulk))
+		Expect(q.Ingress).To(BeEmpty())
+		Expect(q.Egress).To(Equal([]infra.QoSRule{{
+			Peers:    []string{"10.0.0.2"},
+			Protocol: "tcp",
+			Port:     873,
+			RateMbps: 20,
+			Class:    infra.QoSBulk,
+			Policy:   "backup",
+		}}))
+	})
+})
@@ -16,10 +16,17 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	lrp "github.com/alatticeio/lattice/internal/relay"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -37,15 +44,30 @@ import (
 )
 
 // RelayReconciler reconciles LatticeRelayServer objects.
-// On every change it propagates the relay's TcpUrl / QuicUrl into the Spec of
-// every LatticePeer that belongs to one of the relay's target namespaces
-// (or all namespaces when Namespaces is empty).  It also maintains a
-// per-peer label so cleanup on deletion is fast.
+// Relays reach agents through the generated peer config (see relaysForPeer);
+// each agent measures latency to the offered relays and picks its home relay,
+// which the management server records in the per-peer RelayPeerLabel. This
+// reconciler counts those labels into Status.ConnectedPeers, which in turn
+// drives the MaxPeers capacity check, and clears the labels on deletion.
+//
+// Enabled relays are also probed every relayProbeInterval with an LRP
+// handshake on each endpoint. The result sets Health, LatencyMs, Sessions
+// and Version; after RelayOfflineThreshold failed probes in a row the relay
+// is Offline and relaysForPeer stops offering it.
 type RelayReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Probe measures one relay endpoint; network is "tcp" or "quic".
+	// Defaults to an LRP handshake. Tests replace it.
+	Probe func(ctx context.Context, network, addr string) (*lrp.ProbeResult, error)
 }
 
+const (
+	relayProbeInterval = 30 * time.Second
+	relayProbeTimeout  = 5 * time.Second
+)
+
 // +kubebuilder:rbac:groups=alattice.io,resources=latticerelayservers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=alattice.io,resources=latticerelayservers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=alattice.io,resources=latticerelayservers/finalizers,verbs=update
@@ -94,33 +116,126 @@ func (r *RelayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	}
 
 	// ── update status only when something actually changed ────────────────────
-	desiredPhase := v1alpha1.RelayPhaseActive
+	patch := relay.DeepCopy()
+	patch.Status.ConnectedPeers = connected
+	patch.Status.Phase = v1alpha1.RelayPhaseActive
 	if !relay.Spec.Enabled {
-		desiredPhase = v1alpha1.RelayPhaseDisabled
+		patch.Status.Phase = v1alpha1.RelayPhaseDisabled
 	}
-	desiredHealth := relay.Status.Health
-	if desiredHealth == "" {
-		desiredHealth = v1alpha1.RelayHealthUnknown
+	if patch.Status.Health == "" {
+		patch.Status.Health = v1alpha1.RelayHealthUnknown
+	}
+	if relay.Spec.Enabled {
+		r.probe(ctx, patch)
 	}
 
-	if relay.Status.ConnectedPeers != connected ||
-		relay.Status.Phase != desiredPhase ||
-		relay.Status.Health != desiredHealth {
-
-		patch := relay.DeepCopy()
-		patch.Status.ConnectedPeers = connected
-		patch.Status.Phase = desiredPhase
-		patch.Status.Health = desiredHealth
-		now := metav1.Now()
-		patch.Status.LastProbeTime = &now
-
+	if !equality.Semantic.DeepEqual(relay.Status, patch.Status) {
 		if err = r.Status().Patch(ctx, patch, client.MergeFrom(&relay)); err != nil {
 			log.Error(err, "failed to patch relay status")
 		}
 	}
 
-	// Re-sync periodically to keep connectedPeers count accurate.
-	return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	if !relay.Spec.Enabled {
+		// Re-sync periodically to keep connectedPeers count accurate.
+		return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	}
+	return ctrl.Result{RequeueAfter: relayProbeInterval}, nil
+}
+
+// probe runs an LRP handshake against each endpoint of relay and records
+// the outcome in relay.Status. A relay is Healthy when every endpoint
+// answers and Degraded when only some do. When none answer it stays
+// Degraded until RelayOfflineThreshold probes in a row have failed, so a
+// single lost probe does not move every peer off the relay.
+func (r *RelayReconciler) probe(ctx context.Context, relay *v1alpha1.LatticeRelayServer) {
+	probe := r.Probe
+	if probe == nil {
+		probe = probeRelayEndpoint
+	}
+
+	type endpoint struct{ network, addr string }
+	var endpoints []endpoint
+	if relay.Spec.TcpUrl != "" {
+		endpoints = append(endpoints, endpoint{"tcp", relay.Spec.TcpUrl})
+	}
+	if relay.Spec.QuicUrl != "" {
+		endpoints = append(endpoints, endpoint{"quic", relay.Spec.QuicUrl})
+	}
+	if len(endpoints) == 0 {
+		return
+	}
+
+	var (
+		best     *lrp.ProbeResult
+		failures []string
+	)
+	for _, ep := range endpoints {
+		pctx, cancel := context.WithTimeout(ctx, relayProbeTimeout)
+		res, err := probe(pctx, ep.network, ep.addr)
+		cancel()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s %s: %v", ep.network, ep.addr, err))
+			continue
+		}
+		if best == nil || res.RTT < best.RTT {
+			best = res
+		}
+	}
+
+	st := &relay.Status
+	now := metav1.Now()
+	st.LastProbeTime = &now
+	cond := metav1.Condition{
+		Type:               v1alpha1.RelayConditionReady,
+		ObservedGeneration: relay.Generation,
+		LastTransitionTime: now,
+	}
+
+	if best == nil {
+		st.ConsecutiveFailures++
+		st.LatencyMs = nil
+		st.Health = v1alpha1.RelayHealthDegraded
+		if st.ConsecutiveFailures >= v1alpha1.RelayOfflineThreshold {
+			st.Health = v1alpha1.RelayHealthOffline
+		}
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "ProbeFailed"
+		cond.Message = strings.Join(failures, "; ")
+		st.Conditions = setCondition(st.Conditions, cond)
+		return
+	}
+
+	st.ConsecutiveFailures = 0
+	latency := best.RTT.Milliseconds()
+	st.LatencyMs = &latency
+	if best.Sessions >= 0 {
+		sessions := best.Sessions
+		st.Sessions = &sessions
+	} else {
+		st.Sessions = nil
+	}
+	st.Version = best.Version
+
+	if len(failures) > 0 {
+		st.Health = v1alpha1.RelayHealthDegraded
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "PartiallyReachable"
+		cond.Message = strings.Join(failures, "; ")
+	} else {
+		st.Health = v1alpha1.RelayHealthHealthy
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "ProbeSucceeded"
+		cond.Message = fmt.Sprintf("relay reachable in %dms", latency)
+	}
+	st.Conditions = setCondition(st.Conditions, cond)
+}
+
+// probeRelayEndpoint is the default RelayReconciler.Probe.
+func probeRelayEndpoint(ctx context.Context, network, addr string) (*lrp.ProbeResult, error) {
+	if network == "quic" {
+		return lrp.ProbeQUIC(ctx, addr)
+	}
+	return lrp.ProbeTCP(ctx, addr)
 }
 
 // countConnectedPeers counts the LatticePeers that are associated with this
@@ -179,6 +294,60 @@ func (r *RelayReconciler) clearPeers(ctx context.Context, relayName string) erro
 	return nil
 }
 
+// relayServesNamespace reports whether relay may be offered to peers in ns.
+func relayServesNamespace(relay *v1alpha1.LatticeRelayServer, ns string) bool {
+	return len(relay.Spec.Namespaces) == 0 || slices.Contains(relay.Spec.Namespaces, ns)
+}
+
+// relayAtCapacity reports whether relay has reached Spec.MaxPeers.
+func relayAtCapacity(relay *v1alpha1.LatticeRelayServer) bool {
+	return relay.Spec.MaxPeers > 0 && relay.Status.ConnectedPeers >= relay.Spec.MaxPeers
+}
+
+// relayLoad returns the share of relay's MaxPeers in use, in percent rounded
+// down to a multiple of 10, or 0 when the relay has no limit.
+func relayLoad(relay *v1alpha1.LatticeRelayServer) int {
+	if relay.Spec.MaxPeers <= 0 {
+		return 0
+	}
+	load := min(100, relay.Status.ConnectedPeers*100/relay.Spec.MaxPeers)
+	return load / 10 * 10
+}
+
+// relaysForPeer returns the relays offered to peer, sorted by name so the
+// generated config hash is stable. A relay is offered when it is enabled,
+// serves the peer's namespace, is not known to be offline and still has
+// capacity. A relay at capacity stays on offer to peers already homed on it.
+// Agents measure latency themselves and pick among the returned relays.
+func relaysForPeer(relays []v1alpha1.LatticeRelayServer, peer *v1alpha1.LatticePeer) []*infra.Relay {
+	current := peer.GetLabels()[v1alpha1.RelayPeerLabel]
+	out := make([]*infra.Relay, 0, len(relays))
+	for i := range relays {
+		relay := &relays[i]
+		if !relay.Spec.Enabled || !relay.DeletionTimestamp.IsZero() || relay.Spec.TcpUrl == "" {
+			continue
+		}
+		if !relayServesNamespace(relay, peer.Namespace) {
+			continue
+		}
+		if relay.Status.Health == v1alpha1.RelayHealthOffline {
+			continue
+		}
+		if relayAtCapacity(relay) && current != relay.Name {
+			continue
+		}
+		out = append(out, &infra.Relay{
+			Name:    relay.Name,
+			TcpUrl:  relay.Spec.TcpUrl,
+			QuicUrl: relay.Spec.QuicUrl,
+			Region:  relay.GetLabels()[v1alpha1.RelayRegionLabel],
+			Load:    relayLoad(relay),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
 // SetupWithManager registers the reconciler with the controller-runtime manager.
 func (r *RelayReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Only react to LatticeRelayServer spec changes (GenerationChangedPredicate),
@@ -0,0 +1,176 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	lrp "github.com/alatticeio/lattice/internal/relay"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestRelaysForPeer(t *testing.T) {
+	relay := func(name string, mut func(r *v1alpha1.LatticeRelayServer)) v1alpha1.LatticeRelayServer {
+		r := v1alpha1.LatticeRelayServer{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1alpha1.LatticeRelayServerSpec{
+				TcpUrl:  name + ":6266",
+				Enabled: true,
+			},
+		}
+		if mut != nil {
+			mut(&r)
+		}
+		return r
+	}
+
+	relays := []v1alpha1.LatticeRelayServer{
+		relay("zeta", func(r *v1alpha1.LatticeRelayServer) {
+			r.Labels = map[string]string{v1alpha1.RelayRegionLabel: "eu-west"}
+		}),
+		relay("alpha", nil),
+		relay("disabled", func(r *v1alpha1.LatticeRelayServer) { r.Spec.Enabled = false }),
+		relay("offline", func(r *v1alpha1.LatticeRelayServer) { r.Status.Health = v1alpha1.RelayHealthOffline }),
+		relay("other-ns", func(r *v1alpha1.LatticeRelayServer) { r.Spec.Namespaces = []string{"team-b"} }),
+		relay("full", func(r *v1alpha1.LatticeRelayServer) {
+			r.Spec.MaxPeers = 10
+			r.Status.ConnectedPeers = 10
+		}),
+		relay("busy", func(r *v1alpha1.LatticeRelayServer) {
+			r.Spec.Namespaces = []string{"team-c"}
+			r.Spec.MaxPeers = 200
+			r.Status.ConnectedPeers = 139
+		}),
+	}
+
+	peer := &v1alpha1.LatticePeer{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Namespace: "team-a"}}
+	got := relaysForPeer(relays, peer)
+	if len(got) != 2 || got[0].Name != "alpha" || got[1].Name != "zeta" {
+		t.Fatalf("relaysForPeer() = %+v, want [alpha zeta]", got)
+	}
+	if got[1].Region != "eu-west" || got[1].TcpUrl != "zeta:6266" {
+		t.Errorf("zeta relay = %+v, want region eu-west and tcp url", got[1])
+	}
+
+	if got[0].Load != 0 {
+		t.Errorf("alpha load = %d, want 0 without a peer limit", got[0].Load)
+	}
+	busy := relaysForPeer(relays, &v1alpha1.LatticePeer{ObjectMeta: metav1.ObjectMeta{Name: "node-c", Namespace: "team-c"}})
+	if len(busy) != 3 || busy[0].Name != "alpha" || busy[1].Name != "busy" || busy[1].Load != 60 {
+		t.Errorf("relaysForPeer() for team-c = %+v, want busy at load 60", busy)
+	}
+
+	// A peer already homed on a full relay keeps it on offer.
+	peer.Labels = map[string]string{v1alpha1.RelayPeerLabel: "full"}
+	got = relaysForPeer(relays, peer)
+	if len(got) != 3 || got[1].Name != "full" {
+		t.Errorf("relaysForPeer() for homed peer = %+v, want [alpha full zeta]", got)
+	}
+}
+
+func TestRelayReconciler_Probe(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	relay := &v1alpha1.LatticeRelayServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "eu-1", Finalizers: []string{v1alpha1.RelayFinalizer}},
+		Spec: v1alpha1.LatticeRelayServerSpec{
+			TcpUrl:  "relay:6266",
+			QuicUrl: "relay:6267",
+			Enabled: true,
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.LatticeRelayServer{}).
+		WithObjects(relay).
+		Build()
+
+	down := map[string]bool{}
+	r := &RelayReconciler{Client: c, Scheme: scheme,
+		Probe: func(ctx context.Context, network, addr string) (*lrp.ProbeResult, error) {
+			if down[network] {
+				return nil, errors.New("connection refused")
+			}
+			rtt := 20 * time.Millisecond
+			if network == "quic" {
+				rtt = 12 * time.Millisecond
+			}
+			return &lrp.ProbeResult{RTT: rtt, Version: "v1.2.3", Sessions: 4}, nil
+		},
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "eu-1"}}
+	probe := func() v1alpha1.LatticeRelayServerStatus {
+		t.Helper()
+		res, err := r.Reconcile(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.RequeueAfter != relayProbeInterval {
+			t.Fatalf("RequeueAfter = %v, want %v", res.RequeueAfter, relayProbeInterval)
+		}
+		var got v1alpha1.LatticeRelayServer
+		if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+			t.Fatal(err)
+		}
+		return got.Status
+	}
+
+	st := probe()
+	if st.Health != v1alpha1.RelayHealthHealthy || st.LatencyMs == nil || *st.LatencyMs != 12 {
+		t.Fatalf("status = %+v, want healthy at 12ms", st)
+	}
+	if st.Sessions == nil || *st.Sessions != 4 || st.Version != "v1.2.3" || st.LastProbeTime == nil {
+		t.Fatalf("status = %+v, want sessions and version recorded", st)
+	}
+
+	// One endpoint down: still offered, but degraded.
+	down["quic"] = true
+	if st = probe(); st.Health != v1alpha1.RelayHealthDegraded || *st.LatencyMs != 20 {
+		t.Fatalf("status = %+v, want degraded at 20ms", st)
+	}
+
+	// Both down: degraded until the threshold, then offline.
+	down["tcp"] = true
+	for i := 1; i < v1alpha1.RelayOfflineThreshold; i++ {
+		if st = probe(); st.Health != v1alpha1.RelayHealthDegraded || st.ConsecutiveFailures != i {
+			t.Fatalf("probe %d: status = %+v, want degraded", i, st)
+		}
+	}
+	st = probe()
+	if st.Health != v1alpha1.RelayHealthOffline || st.LatencyMs != nil {
+		t.Fatalf("status = %+v, want offline", st)
+	}
+	if len(st.Conditions) != 1 || st.Conditions[0].Reason != "ProbeFailed" || st.Conditions[0].Message == "" {
+		t.Fatalf("conditions = %+v, want ProbeFailed with message", st.Conditions)
+	}
+
+	// A single good probe brings it back.
+	down["tcp"], down["quic"] = false, false
+	if st = probe(); st.Health != v1alpha1.RelayHealthHealthy || st.ConsecutiveFailures != 0 {
+		t.Fatalf("status = %+v, want healthy", st)
+	}
+}
@@ -179,6 +179,7 @@ func Start(flags *config.Config) error {
 		Scheme:        mgr.GetScheme(),
 		SnapshotCache: make(map[types.NamespacedName]*PeerStateSnapshot),
 		IPAM:          ipam.NewIPAM(mgr.GetClient()),
+		RelaySecret:   flags.RelaySecret,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "LatticePeer")
 		return err
@@ -219,6 +220,13 @@ func Start(flags *config.Config) error {
 		setupLog.Error(err, "unable to create controller", "controller", "LatticeClusterPeering")
 		return err
 	}
+	if err := (&ClusterReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LatticeCluster")
+		return err
+	}
 	//+kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -17,9 +17,12 @@ package controller
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	latticev1alpha1 "github.com/alatticeio/lattice/api/v1alpha1"
 	"github.com/alatticeio/lattice/internal/agent/infra"
+	"net/netip"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -32,6 +35,16 @@ const (
 	// NetworkPeeringReconciler. PeerReconciler skips shadow peers.
 	LabelShadow = "alattice.io/shadow"
 
+	// LabelMeshPeering is set on the shadow peers a mesh-mode
+	// LatticeNetworkPeering creates for every remote peer; the value is the
+	// peering name.
+	LabelMeshPeering = "alattice.io/mesh-peering"
+
+	// LabelGatewayPeering is set on the shadow peers a gateway-mode
+	// LatticeNetworkPeering creates for the active gateways of the remote
+	// network; the value is the peering name.
+	LabelGatewayPeering = "alattice.io/gateway-peering"
+
 	// AnnotationShadowAllowedIPs is set on shadow peers and contains the CIDR
 	// of the remote network that should be routed through this peer.
 	// Example: "10.0.1.0/24"
@@ -44,6 +57,22 @@ const (
 	// with AllowedIPs expanded to include all annotated CIDRs.
 	AnnotationPeeringRoutePrefix = "alattice.io/peering-route-"
 
+	// AnnotationPeeringNetMapPrefix is the prefix for per-peering address
+	// translation annotations on gateway peers. The value is a JSON-encoded
+	// infra.NetMap that the gateway applies as a 1:1 prefix mapping.
+	// Example: "alattice.io/peering-netmap-ws-a-to-ws-b" =
+	// {"local":"10.10.1.0/24","virtual":"100.64.1.0/24","remote":"100.64.2.0/24"}
+	AnnotationPeeringNetMapPrefix = "alattice.io/peering-netmap-"
+
+	// AnnotationPeeringDNSPrefix is the prefix for per-peering DNS annotations
+	// on gateway peers. The value is a JSON object mapping the names of the
+	// remote peers to the (possibly translated) addresses local peers use.
+	AnnotationPeeringDNSPrefix = "alattice.io/peering-dns-"
+
+	// PeeringDNSDomain is the domain under which peers reachable through a
+	// peering are published: <peer>.<namespace>.<PeeringDNSDomain>.
+	PeeringDNSDomain = "lattice"
+
 	// PeeringFinalizer is the finalizer added to LatticeNetworkPeering resources.
 	PeeringFinalizer = "alattice.io/peering-finalizer"
 )
@@ -145,7 +174,7 @@ func safeKeyName(prefix, name string) string {
 // networkLabelKey returns the label key used to tag a peer as belonging to a
 // LatticeNetwork. The name segment is guaranteed to be ≤63 characters.
 func networkLabelKey(networkName string) string {
-	return "alattice.io/" + safeKeyName("network-", networkName)
+	return latticev1alpha1.NetworkLabelKey(networkName)
 }
 
 // peeringRouteAnnotationKey returns the annotation key used to store a
@@ -154,6 +183,18 @@ func peeringRouteAnnotationKey(peeringName string) string {
 	return "alattice.io/" + safeKeyName("peering-route-", peeringName)
 }
 
+// peeringNetMapAnnotationKey returns the annotation key used to store a
+// per-peering address translation on gateway peers.
+func peeringNetMapAnnotationKey(peeringName string) string {
+	return "alattice.io/" + safeKeyName("peering-netmap-", peeringName)
+}
+
+// peeringDNSAnnotationKey returns the annotation key used to publish the
+// remote peers of a peering on gateway peers.
+func peeringDNSAnnotationKey(peeringName string) string {
+	return "alattice.io/" + safeKeyName("peering-dns-", peeringName)
+}
+
 // safeLabelValue returns a label value of at most 63 characters.
 // If name fits, it is returned as-is. Otherwise a truncated-name + short hash.
 func safeLabelValue(name string) string {
@@ -166,6 +207,154 @@ func safeLabelValue(name string) string {
 	return name[:maxLen-9] + "-" + hash
 }
 
+// cidrsOverlap reports whether two CIDRs share any address.
+func cidrsOverlap(a, b string) (bool, error) {
+	pa, err := netip.ParsePrefix(a)
+	if err != nil {
+		return false, fmt.Errorf("invalid CIDR %q: %w", a, err)
+	}
+	pb, err := netip.ParsePrefix(b)
+	if err != nil {
+		return false, fmt.Errorf("invalid CIDR %q: %w", b, err)
+	}
+	return pa.Overlaps(pb), nil
+}
+
+// validateVirtualCIDR checks that virtual can stand in for active under a
+// 1:1 prefix mapping: same address family and prefix length.
+func validateVirtualCIDR(active, virtual string) error {
+	pa, err := netip.ParsePrefix(active)
+	if err != nil {
+		return fmt.Errorf("invalid CIDR %q: %w", active, err)
+	}
+	pv, err := netip.ParsePrefix(virtual)
+	if err != nil {
+		return fmt.Errorf("invalid virtual CIDR %q: %w", virtual, err)
+	}
+	if pa.Addr().Is4() != pv.Addr().Is4() || pa.Bits() != pv.Bits() {
+		return fmt.Errorf("virtual CIDR %s must have the same family and prefix length as %s", virtual, active)
+	}
+	return nil
+}
+
+// peeringRanges validates the virtual CIDRs of a peering and returns the
+// ranges each side is reached at from the other. Local peers route the
+// remote range through their gateway, so it must not overlap the local
+// network, and the two reachable ranges must be disjoint.
+func peeringRanges(localCIDR, localVirtual, remoteCIDR, remoteVirtual string) (mappedLocal, mappedRemote string, err error) {
+	if localVirtual != "" {
+		if err := validateVirtualCIDR(localCIDR, localVirtual); err != nil {
+			return "", "", err
+		}
+	}
+	if remoteVirtual != "" {
+		if err := validateVirtualCIDR(remoteCIDR, remoteVirtual); err != nil {
+			return "", "", err
+		}
+	}
+	mappedLocal = mappedCIDR(localCIDR, localVirtual)
+	mappedRemote = mappedCIDR(remoteCIDR, remoteVirtual)
+
+	for _, pair := range [][2]string{{mappedLocal, mappedRemote}, {localCIDR, mappedRemote}, {mappedLocal, remoteCIDR}} {
+		overlap, err := cidrsOverlap(pair[0], pair[1])
+		if err != nil {
+			return "", "", err
+		}
+		if overlap {
+			if localVirtual == "" && remoteVirtual == "" {
+				return "", "", fmt.Errorf("networks %s and %s overlap: configure virtual CIDRs to translate them", localCIDR, remoteCIDR)
+			}
+			return "", "", fmt.Errorf("%s overlaps %s: virtual CIDRs must be disjoint from both networks", pair[0], pair[1])
+		}
+	}
+	return mappedLocal, mappedRemote, nil
+}
+
+// peeringNetMap returns the JSON-encoded translation a gateway applies for
+// its network, reached at virtual, when talking to remote.
+func peeringNetMap(local, virtual, remote string) (string, error) {
+	b, err := json.Marshal(infra.NetMap{Local: local, Virtual: virtual, Remote: remote})
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// mappedAddress returns the address a peer at addr is reached at from the
+// other side of a peering whose local range active is presented as virtual.
+// Addresses outside active, or with no virtual range, are returned as-is.
+func mappedAddress(addr, active, virtual string) string {
+	if virtual == "" {
+		return addr
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return addr
+	}
+	from, err := netip.ParsePrefix(active)
+	if err != nil || !from.Contains(ip) {
+		return addr
+	}
+	to, err := netip.ParsePrefix(virtual)
+	if err != nil {
+		return addr
+	}
+	return mapAddress(ip, from, to).String()
+}
+
+// mappedCIDR returns the range a network is reached at from the other side
+// of a peering: its virtual CIDR when one is configured, its own otherwise.
+func mappedCIDR(active, virtual string) string {
+	if virtual != "" {
+		return virtual
+	}
+	return active
+}
+
+// mapAddress translates addr from the from prefix into the to prefix,
+// keeping the host bits. Both prefixes must have the same length.
+func mapAddress(addr netip.Addr, from, to netip.Prefix) netip.Addr {
+	src := addr.AsSlice()
+	dst := to.Masked().Addr().AsSlice()
+	bits := from.Bits()
+	for i := range dst {
+		switch {
+		case bits >= 8:
+			bits -= 8
+			continue
+		case bits > 0:
+			mask := byte(0xff) >> bits
+			dst[i] = dst[i]&^mask | src[i]&mask
+			bits = 0
+		default:
+			dst[i] = src[i]
+		}
+	}
+	out, _ := netip.AddrFromSlice(dst)
+	return out
+}
+
+// peeringDNSName returns the name under which a peer reachable through a
+// peering is published.
+func peeringDNSName(peerName, namespace string) string {
+	return fmt.Sprintf("%s.%s.%s", peerName, namespace, PeeringDNSDomain)
+}
+
+// peeringDNSRecords builds a peering DNS annotation value from the
+// addresses of the remote peers, keyed by peer name, translating them from
+// active into virtual when a virtual CIDR is set.
+func peeringDNSRecords(addrs map[string]string, namespace, active, virtual string) (string, error) {
+	records := make(map[string]string, len(addrs))
+	for name, addr := range addrs {
+		records[peeringDNSName(name, namespace)] = mappedAddress(addr, active, virtual)
+	}
+	b, err := json.Marshal(records)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
 // cleanIP strips the CIDR suffix from an IP string (e.g. "10.0.0.1/32" → "10.0.0.1").
 // Returns empty string if ip is nil.
 func cleanIP(ip *string) string {
@@ -179,6 +368,13 @@ func cleanIP(ip *string) string {
 }
 
 func transferToPeer(peer *latticev1alpha1.LatticePeer) *infra.Peer {
+	return transferToPeerFor(peer, "")
+}
+
+// transferToPeerFor converts peer as it appears in the config of the peer
+// named viewer: routes of active-active gateway groups are only kept on the
+// gateway viewer hashes to. An empty viewer keeps all routes.
+func transferToPeerFor(peer *latticev1alpha1.LatticePeer, viewer string) *infra.Peer {
 	var peerID uint64
 	if peer.Spec.PeerId != "" {
 		peerID, _ = strconv.ParseUint(peer.Spec.PeerId, 10, 64)
@@ -186,6 +382,7 @@ func transferToPeer(peer *latticev1alpha1.LatticePeer) *infra.Peer {
 	p := &infra.Peer{
 		PeerID:        peerID,
 		Name:          peer.Name,
+		Namespace:     peer.Namespace,
 		AppID:         peer.Spec.AppId,
 		Platform:      peer.Spec.Platform,
 		InterfaceName: peer.Spec.InterfaceName,
@@ -213,7 +410,7 @@ func transferToPeer(peer *latticev1alpha1.LatticePeer) *infra.Peer {
 	// through the gateway's tunnel, enabling cross-workspace forwarding.
 	var extraRoutes []string
 	for k, v := range peer.GetAnnotations() {
-		if strings.HasPrefix(k, AnnotationPeeringRoutePrefix) && v != "" {
+		if strings.HasPrefix(k, AnnotationPeeringRoutePrefix) && v != "" && routesVia(peer, k, viewer) {
 			extraRoutes = append(extraRoutes, v)
 		}
 	}
@@ -226,5 +423,36 @@ func transferToPeer(peer *latticev1alpha1.LatticePeer) *infra.Peer {
 		}
 	}
 
+	// Gateway peers of translated peerings carry the mappings to apply;
+	// sort them so the generated config hashes stably.
+	for k, v := range peer.GetAnnotations() {
+		if !strings.HasPrefix(k, AnnotationPeeringNetMapPrefix) {
+			continue
+		}
+		var nm infra.NetMap
+		if err := json.Unmarshal([]byte(v), &nm); err == nil {
+			p.NetMaps = append(p.NetMaps, nm)
+		}
+	}
+	sort.Slice(p.NetMaps, func(i, j int) bool {
+		return p.NetMaps[i].Virtual < p.NetMaps[j].Virtual
+	})
+
+	for k, v := range peer.GetAnnotations() {
+		if !strings.HasPrefix(k, AnnotationPeeringDNSPrefix) {
+			continue
+		}
+		var records map[string]string
+		if err := json.Unmarshal([]byte(v), &records); err != nil {
+			continue
+		}
+		if p.DNSRecords == nil {
+			p.DNSRecords = make(map[string]string, len(records))
+		}
+		for name, addr := range records {
+			p.DNSRecords[name] = addr
+		}
+	}
+
 	return p
 }
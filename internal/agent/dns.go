@@ -0,0 +1,36 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import "github.com/alatticeio/lattice/internal/agent/infra"
+
+// dnsRecords collects the names published in msg by peering gateways: the
+// remote peers behind them, at the address this node reaches them on.
+func dnsRecords(msg *infra.Message) map[string]string {
+	records := make(map[string]string)
+	add := func(p *infra.Peer) {
+		if p == nil {
+			return
+		}
+		for name, addr := range p.DNSRecords {
+			records[name] = addr
+		}
+	}
+	add(msg.Current)
+	for _, p := range msg.ComputedPeers {
+		add(p)
+	}
+	return records
+}
@@ -12,7 +12,21 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package ebpf contains the eBPF programs and loader for Lattice policy enforcement.
+// Package ebpf enforces Lattice firewall rules with TC programs attached to
+// the WireGuard interface.
 //
-//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target bpfel -cc clang -no-strip -type ip_key -type port_key tc_ingress ./tc_ingress.bpf.c -- -I. -O2
+// Two programs are attached through TCX: ingress matches the source address
+// of packets arriving from the tunnel, egress the destination address of
+// packets leaving into it. Both read the IPv4 or IPv6 header relative to the
+// network header, so they work on L3 (TUN) devices that carry no Ethernet
+// header. The programs are assembled in Go (see program.go) so the agent
+// needs no BPF toolchain at build time; the tests run them on crafted
+// packets through BPF_PROG_TEST_RUN.
+//
+// Rules are compiled into a pair of LPM tries per direction, keyed by the
+// peer address in IPv4-mapped IPv6 form. Each trie entry holds the ordered
+// list of rules that apply to that prefix. Provision writes the inactive
+// pair and then flips a single index, so a rule change never exposes a
+// half-written policy. Accepted flows are remembered in an LRU map so that
+// replies pass without a matching rule in the opposite direction.
 package ebpf
@@ -0,0 +1,391 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package ebpf
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/log"
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/rlimit"
+)
+
+var errNotLoaded = errors.New("ebpf: policy programs are not loaded")
+
+// Manager enforces FirewallRules with TC programs on one interface.
+type Manager struct {
+	iface  string
+	logger *log.Logger
+
+	mu     sync.Mutex
+	coll   *ebpf.Collection
+	links  []link.Link
+	active int
+	// stats holds the rules of each policy slot, indexed by rule ID.
+	stats [2][]RuleStat
+}
+
+// NewManager creates a manager for iface. Call Load before Provision.
+func NewManager(iface string, logger *log.Logger) *Manager {
+	return &Manager{iface: iface, logger: logger}
+}
+
+func (m *Manager) Name() string { return "ebpf" }
+
+// SetupNAT is a no-op: NAT is not handled by the TC programs.
+func (m *Manager) SetupNAT(_ string) error { return nil }
+
+// HaveTCX reports whether the kernel can attach TC programs through TCX
+// (Linux 6.6+), which Load needs. It attaches a program that defers to the
+// next one to the loopback interface and detaches it again.
+func HaveTCX() error {
+	if err := rlimit.RemoveMemlock(); err != nil {
+		return fmt.Errorf("ebpf: remove memlock: %w", err)
+	}
+	lo, err := net.InterfaceByName("lo")
+	if err != nil {
+		return fmt.Errorf("ebpf: loopback interface: %w", err)
+	}
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Type:    ebpf.SchedCLS,
+		License: "Apache-2.0",
+		Instructions: asm.Instructions{
+			asm.Mov.Imm(asm.R0, tcxNext),
+			asm.Return(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("ebpf: load probe: %w", err)
+	}
+	defer prog.Close() //nolint:errcheck
+
+	l, err := link.AttachTCX(link.TCXOptions{
+		Interface: lo.Index,
+		Program:   prog,
+		Attach:    ebpf.AttachTCXIngress,
+	})
+	if err != nil {
+		return fmt.Errorf("ebpf: attach TCX: %w", err)
+	}
+	return l.Close()
+}
+
+// Load creates the maps and attaches both programs to the interface with
+// TCX (Linux 6.6+). Until the first Provision all traffic passes.
+func (m *Manager) Load() error {
+	if err := rlimit.RemoveMemlock(); err != nil {
+		return fmt.Errorf("ebpf: remove memlock: %w", err)
+	}
+	iface, err := net.InterfaceByName(m.iface)
+	if err != nil {
+		return fmt.Errorf("ebpf: interface %s: %w", m.iface, err)
+	}
+
+	coll, err := loadCollection()
+	if err != nil {
+		return err
+	}
+
+	var links []link.Link
+	for _, dir := range []Direction{Ingress, Egress} {
+		attach := ebpf.AttachTCXIngress
+		if dir == Egress {
+			attach = ebpf.AttachTCXEgress
+		}
+		l, err := link.AttachTCX(link.TCXOptions{
+			Interface: iface.Index,
+			Program:   coll.Programs[progPrefix+dir.String()],
+			Attach:    attach,
+		})
+		if err != nil {
+			for _, l := range links {
+				l.Close() //nolint:errcheck
+			}
+			coll.Close()
+			return fmt.Errorf("ebpf: attach %s to %s: %w", dir, m.iface, err)
+		}
+		links = append(links, l)
+	}
+
+	m.mu.Lock()
+	m.coll, m.links, m.active, m.stats = coll, links, 0, [2][]RuleStat{}
+	m.mu.Unlock()
+	m.logger.Info("eBPF policy programs attached", "iface", m.iface)
+	return nil
+}
+
+// loadCollection creates the maps and programs with slot 0 active and an
+// accept default in both directions.
+func loadCollection() (*ebpf.Collection, error) {
+	coll, err := ebpf.NewCollection(newCollectionSpec())
+	if err != nil {
+		return nil, fmt.Errorf("ebpf: load programs: %w", err)
+	}
+	cfg := policyConfig{}
+	cfg.Slots[0].Default[Ingress] = defaultRule{Action: ActionAccept, ID: maxRules}
+	cfg.Slots[0].Default[Egress] = defaultRule{Action: ActionAccept, ID: maxRules + 1}
+	if err = coll.Maps[mapConfig].Put(uint32(0), cfg); err != nil {
+		coll.Close()
+		return nil, fmt.Errorf("ebpf: init config: %w", err)
+	}
+	return coll, nil
+}
+
+// Provision writes rule into the inactive policy slot and then switches the
+// programs over to it.
+func (m *Manager) Provision(rule *infra.FirewallRule) error {
+	cp, err := compile(rule)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.coll == nil {
+		return errNotLoaded
+	}
+
+	next := 1 - m.active
+	base := uint32(next * slotCounters)
+	for _, dir := range []Direction{Ingress, Egress} {
+		trie := m.coll.Maps[trieName(dir, next)]
+		if err = clearMap(trie); err != nil {
+			return fmt.Errorf("ebpf: clear %s rules: %w", dir, err)
+		}
+		for key, set := range cp.sets[dir] {
+			for i := uint32(0); i < set.Count; i++ {
+				set.Rules[i].ID += base
+			}
+			if err = trie.Put(key, set); err != nil {
+				return fmt.Errorf("ebpf: write %s rules: %w", dir, err)
+			}
+		}
+	}
+
+	hits := m.coll.Maps[mapHits]
+	zero := make([]ruleCounter, ebpf.MustPossibleCPU())
+	ids := []uint32{base + maxRules, base + maxRules + 1}
+	for i := range cp.stats {
+		ids = append(ids, base+uint32(i))
+	}
+	for _, id := range ids {
+		if err = hits.Put(id, zero); err != nil {
+			return fmt.Errorf("ebpf: reset counters: %w", err)
+		}
+	}
+
+	// Publish the slot's defaults first and flip the index in a second
+	// write, so the programs never see the new index with old defaults.
+	config := m.coll.Maps[mapConfig]
+	var cfg policyConfig
+	if err = config.Lookup(uint32(0), &cfg); err != nil {
+		return fmt.Errorf("ebpf: read config: %w", err)
+	}
+	for _, dir := range []Direction{Ingress, Egress} {
+		cfg.Slots[next].Default[dir] = defaultRule{
+			Action: uint32(cp.defaultAction[dir]),
+			ID:     base + maxRules + uint32(dir),
+		}
+	}
+	if err = config.Put(uint32(0), cfg); err != nil {
+		return fmt.Errorf("ebpf: write config: %w", err)
+	}
+	cfg.Active = uint32(next)
+	if err = config.Put(uint32(0), cfg); err != nil {
+		return fmt.Errorf("ebpf: switch policy slot: %w", err)
+	}
+
+	m.active, m.stats[next] = next, cp.stats
+	m.logger.Debug("eBPF policy provisioned", "policy", rule.PolicyName, "rules", len(cp.stats), "slot", next)
+	return nil
+}
+
+// RuleStats returns the hit counters of the rules of the last Provision.
+func (m *Manager) RuleStats() ([]RuleStat, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.coll == nil {
+		return nil, errNotLoaded
+	}
+
+	base := uint32(m.active * slotCounters)
+	hits := m.coll.Maps[mapHits]
+	out := make([]RuleStat, len(m.stats[m.active]))
+	var perCPU []ruleCounter
+	for i, st := range m.stats[m.active] {
+		id := base + uint32(i)
+		if len(st.Peers) == 0 {
+			id = base + maxRules + uint32(st.Direction)
+		}
+		if err := hits.Lookup(id, &perCPU); err != nil {
+			return nil, fmt.Errorf("ebpf: read counter %d: %w", id, err)
+		}
+		for _, c := range perCPU {
+			st.Packets += c.Packets
+			st.Bytes += c.Bytes
+		}
+		out[i] = st
+	}
+	return out, nil
+}
+
+// FlowRecords drains the flow log: it returns the traffic seen since the
+// previous call, one record per flow, direction and verdict. RemotePeer is
+// left for the caller to fill in.
+func (m *Manager) FlowRecords() ([]infra.FlowRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.coll == nil {
+		return nil, errNotLoaded
+	}
+
+	flowLog := m.coll.Maps[mapFlowLog]
+	var (
+		key  flowLogKey
+		keys []flowLogKey
+		val  flowLogValue
+	)
+	iter := flowLog.Iterate()
+	for iter.Next(&key, &val) {
+		keys = append(keys, key)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("ebpf: read flow log: %w", err)
+	}
+
+	out := make([]infra.FlowRecord, 0, len(keys))
+	for _, k := range keys {
+		if err := lookupAndDelete(flowLog, k, &val); err != nil {
+			if errors.Is(err, ebpf.ErrKeyNotExist) {
+				continue
+			}
+			return out, fmt.Errorf("ebpf: drain flow log: %w", err)
+		}
+		out = append(out, m.flowRecord(k, val))
+	}
+	return out, nil
+}
+
+// lookupAndDelete falls back to a lookup and a delete on kernels without
+// BPF_MAP_LOOKUP_AND_DELETE_ELEM for hash maps (before 5.14); packets
+// counted in between are lost.
+func lookupAndDelete(mp *ebpf.Map, key flowLogKey, val *flowLogValue) error {
+	err := mp.LookupAndDelete(key, val)
+	if !errors.Is(err, ebpf.ErrNotSupported) {
+		return err
+	}
+	if err = mp.Lookup(key, val); err != nil {
+		return err
+	}
+	return mp.Delete(key)
+}
+
+// flowRecord converts a flow log entry. Established entries carry the rule
+// that accepted the connection, which belongs to the opposite direction.
+func (m *Manager) flowRecord(k flowLogKey, v flowLogValue) infra.FlowRecord {
+	dir := Direction(k.Dir)
+	ruleDir := dir
+	if k.Established != 0 {
+		ruleDir = 1 - dir
+	}
+	verdict := infra.VerdictAccept
+	if k.Action == ActionDrop {
+		verdict = infra.VerdictDrop
+	}
+	rec := infra.FlowRecord{
+		Direction:   dir.String(),
+		Verdict:     verdict,
+		Protocol:    protoName(k.Proto),
+		LocalIP:     netip.AddrFrom16(k.Local).Unmap().String(),
+		RemoteIP:    netip.AddrFrom16(k.Remote).Unmap().String(),
+		Policy:      m.rulePolicy(v.RuleID, ruleDir),
+		Established: k.Established != 0,
+		Packets:     v.Packets,
+		Bytes:       v.Bytes,
+	}
+	if k.Proto == protoTCP || k.Proto == protoUDP || k.Proto == protoSCTP {
+		rec.LocalPort = int(ntohs(k.LocalPort))
+		rec.RemotePort = int(ntohs(k.RemotePort))
+	}
+	return rec
+}
+
+// rulePolicy returns the policy of the rule behind counter id. The
+// accept-all defaults in place before the first Provision have none.
+func (m *Manager) rulePolicy(id uint32, dir Direction) string {
+	slot, idx := int(id/slotCounters), int(id%slotCounters)
+	if slot > 1 {
+		return ""
+	}
+	stats := m.stats[slot]
+	if idx >= maxRules {
+		for _, st := range stats {
+			if st.Direction == dir && len(st.Peers) == 0 {
+				return st.Policy
+			}
+		}
+		return ""
+	}
+	if idx < len(stats) {
+		return stats[idx].Policy
+	}
+	return ""
+}
+
+// Cleanup detaches the programs and releases the maps.
+func (m *Manager) Cleanup() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	for _, l := range m.links {
+		errs = append(errs, l.Close())
+	}
+	if m.coll != nil {
+		m.coll.Close()
+	}
+	m.coll, m.links, m.stats = nil, nil, [2][]RuleStat{}
+	return errors.Join(errs...)
+}
+
+// clearMap deletes every entry of a rule trie.
+func clearMap(mp *ebpf.Map) error {
+	var (
+		key  lpmKey
+		keys []lpmKey
+		set  ruleSet
+	)
+	iter := mp.Iterate()
+	for iter.Next(&key, &set) {
+		keys = append(keys, key)
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := mp.Delete(k); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package ebpf
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/log"
+	"github.com/cilium/ebpf/rlimit"
+)
+
+// newTestManager loads the programs without attaching them; packets are fed
+// through BPF_PROG_TEST_RUN. The test is skipped without BPF privileges.
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	if err := rlimit.RemoveMemlock(); err != nil {
+		t.Skipf("cannot raise memlock: %v", err)
+	}
+	coll, err := loadCollection()
+	if err != nil {
+		t.Skipf("cannot load BPF programs: %v", err)
+	}
+	m := NewManager("test0", log.GetLogger("ebpf-test"))
+	m.coll = coll
+	t.Cleanup(func() { _ = m.Cleanup() })
+	return m
+}
+
+// packet builds an Ethernet-framed IP packet with an optional L4 port pair;
+// BPF_PROG_TEST_RUN expects an L2 frame for TC programs.
+func packet(src, dst string, proto uint8, sport, dport uint16) []byte {
+	s, d := netip.MustParseAddr(src), netip.MustParseAddr(dst)
+	frame := make([]byte, 14)
+	var ip []byte
+	if s.Is4() {
+		binary.BigEndian.PutUint16(frame[12:], ethPIP)
+		ip = make([]byte, 20)
+		ip[0] = 0x45
+		ip[9] = proto
+		copy(ip[12:16], s.AsSlice())
+		copy(ip[16:20], d.AsSlice())
+	} else {
+		binary.BigEndian.PutUint16(frame[12:], ethPIPv6)
+		ip = make([]byte, 40)
+		ip[0] = 0x60
+		ip[6] = proto
+		copy(ip[8:24], s.AsSlice())
+		copy(ip[24:40], d.AsSlice())
+	}
+	l4 := make([]byte, 20)
+	binary.BigEndian.PutUint16(l4[0:], sport)
+	binary.BigEndian.PutUint16(l4[2:], dport)
+	return append(append(frame, ip...), l4...)
+}
+
+func run(t *testing.T, m *Manager, dir Direction, pkt []byte) uint32 {
+	t.Helper()
+	ret, _, err := m.coll.Programs[progPrefix+dir.String()].Test(pkt)
+	if err != nil {
+		t.Skipf("BPF_PROG_TEST_RUN unavailable: %v", err)
+	}
+	return ret
+}
+
+func TestManager_EnforcesRules(t *testing.T) {
+	m := newTestManager(t)
+
+	if got := run(t, m, Ingress, packet("10.0.0.2", "10.0.0.1", protoTCP, 1234, 22)); got != tcActOK {
+		t.Fatalf("before Provision: verdict %d, want pass", got)
+	}
+
+	err := m.Provision(&infra.FirewallRule{
+		PolicyName: "web",
+		Ingress: []infra.TrafficRule{
+			{Peers: []string{"10.0.0.2"}, Protocol: "tcp", Port: 80, EndPort: 90, Action: "ACCEPT"},
+			{Peers: []string{"fd00::/64"}, Action: "ACCEPT"},
+			{Action: "DROP"},
+		},
+		Egress: []infra.TrafficRule{
+			{Peers: []string{"10.0.0.9"}, Protocol: "tcp", Port: 443, Action: "ACCEPT"},
+			{Action: "DROP"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		dir  Direction
+		pkt  []byte
+		want uint32
+	}{
+		{"port in range", Ingress, packet("10.0.0.2", "10.0.0.1", protoTCP, 1234, 85), tcActOK},
+		{"port above range", Ingress, packet("10.0.0.2", "10.0.0.1", protoTCP, 1234, 91), tcActShot},
+		{"wrong protocol", Ingress, packet("10.0.0.2", "10.0.0.1", protoUDP, 1234, 85), tcActShot},
+		{"unknown peer", Ingress, packet("10.0.0.3", "10.0.0.1", protoTCP, 1234, 85), tcActShot},
+		{"ipv6 prefix", Ingress, packet("fd00::5", "fd00::1", protoUDP, 1, 53), tcActOK},
+		{"ipv6 outside prefix", Ingress, packet("fd01::5", "fd00::1", protoUDP, 1, 53), tcActShot},
+		{"egress allowed", Egress, packet("10.0.0.1", "10.0.0.9", protoTCP, 40000, 443), tcActOK},
+		{"reply of egress flow", Ingress, packet("10.0.0.9", "10.0.0.1", protoTCP, 443, 40000), tcActOK},
+		{"unrelated ingress", Ingress, packet("10.0.0.9", "10.0.0.1", protoTCP, 443, 40001), tcActShot},
+		{"egress denied", Egress, packet("10.0.0.1", "10.0.0.8", protoTCP, 40000, 443), tcActShot},
+	}
+	for _, tt := range tests {
+		if got := run(t, m, tt.dir, tt.pkt); got != tt.want {
+			t.Errorf("%s: verdict %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	stats, err := m.RuleStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 5 {
+		t.Fatalf("got %d stats, want 5", len(stats))
+	}
+	if stats[0].Packets != 1 || stats[0].Bytes == 0 {
+		t.Errorf("port-range rule: %d packets, %d bytes; want 1 packet", stats[0].Packets, stats[0].Bytes)
+	}
+	if stats[2].Packets != 5 {
+		t.Errorf("ingress default: %d packets, want 5", stats[2].Packets)
+	}
+
+	records, err := m.FlowRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	find := func(dir string, localPort int, remote string, remotePort int) *infra.FlowRecord {
+		for i := range records {
+			r := &records[i]
+			if r.Direction == dir && r.LocalPort == localPort && r.RemoteIP == remote && r.RemotePort == remotePort {
+				return r
+			}
+		}
+		t.Fatalf("no %s flow record for :%d-%s:%d in %+v", dir, localPort, remote, remotePort, records)
+		return nil
+	}
+	if r := find("egress", 40000, "10.0.0.9", 443); r.Verdict != infra.VerdictAccept || r.Established || r.Policy != "web" {
+		t.Errorf("egress allowed: %+v", r)
+	}
+	if r := find("ingress", 40000, "10.0.0.9", 443); r.Verdict != infra.VerdictAccept || !r.Established || r.Policy != "web" || r.Protocol != "tcp" {
+		t.Errorf("reply of egress flow: %+v", r)
+	}
+	if r := find("ingress", 85, "10.0.0.3", 1234); r.Verdict != infra.VerdictDrop || r.Packets != 1 || r.Bytes == 0 {
+		t.Errorf("unknown peer: %+v", r)
+	}
+	if records, err = m.FlowRecords(); err != nil || len(records) != 0 {
+		t.Errorf("flow log not drained: %d records, err %v", len(records), err)
+	}
+
+	// The second Provision lands in the other slot and replaces the rules.
+	if err = m.Provision(&infra.FirewallRule{
+		Ingress: []infra.TrafficRule{{Peers: []string{"10.0.0.3"}, Action: "ACCEPT"}, {Action: "DROP"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := run(t, m, Ingress, packet("10.0.0.3", "10.0.0.1", protoTCP, 1234, 85)); got != tcActOK {
+		t.Errorf("new rule: verdict %d, want pass", got)
+	}
+	if got := run(t, m, Ingress, packet("10.0.0.2", "10.0.0.1", protoTCP, 1234, 86)); got != tcActShot {
+		t.Errorf("removed rule: verdict %d, want drop", got)
+	}
+}
+
+func TestManager_ParsesHeaders(t *testing.T) {
+	m := newTestManager(t)
+	if err := m.Provision(&infra.FirewallRule{
+		Ingress: []infra.TrafficRule{
+			{Peers: []string{"10.0.0.2"}, Protocol: "tcp", Port: 22, Action: "ACCEPT"},
+			{Peers: []string{"10.0.0.4"}, Action: "ACCEPT"},
+			{Action: "DROP"},
+		},
+		Egress: []infra.TrafficRule{
+			{Peers: []string{"fd00::9"}, Protocol: "udp", Port: 53, Action: "ACCEPT"},
+			{Action: "DROP"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	arp := packet("10.0.0.3", "10.0.0.1", 0, 0, 0)
+	binary.BigEndian.PutUint16(arp[12:], 0x0806)
+	// A non-first fragment carries no ports.
+	fragment := func(src string) []byte {
+		pkt := packet(src, "10.0.0.1", protoTCP, 1234, 22)
+		binary.BigEndian.PutUint16(pkt[14+6:], 185)
+		return pkt
+	}
+	// Without an L4 header the ports cannot be read either.
+	noPorts := func(src string) []byte {
+		return packet(src, "10.0.0.1", protoTCP, 1234, 22)[:14+20]
+	}
+
+	tests := []struct {
+		name string
+		dir  Direction
+		pkt  []byte
+		want uint32
+	}{
+		{"not ip", Ingress, arp, tcActOK},
+		{"first packet with port", Ingress, packet("10.0.0.2", "10.0.0.1", protoTCP, 1234, 22), tcActOK},
+		{"fragment against port rule", Ingress, fragment("10.0.0.2"), tcActShot},
+		{"fragment against portless rule", Ingress, fragment("10.0.0.4"), tcActOK},
+		{"no l4 header against port rule", Ingress, noPorts("10.0.0.2"), tcActShot},
+		{"no l4 header against portless rule", Ingress, noPorts("10.0.0.4"), tcActOK},
+		{"ipv6 egress", Egress, packet("fd00::1", "fd00::9", protoUDP, 5000, 53), tcActOK},
+		{"ipv6 egress other port", Egress, packet("fd00::1", "fd00::9", protoUDP, 5000, 54), tcActShot},
+		{"ipv6 egress matches destination", Egress, packet("fd00::9", "fd00::1", protoUDP, 5000, 53), tcActShot},
+	}
+	for _, tt := range tests {
+		if got := run(t, m, tt.dir, tt.pkt); got != tt.want {
+			t.Errorf("%s: verdict %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHaveTCX(t *testing.T) {
+	if err := HaveTCX(); err != nil {
+		t.Skipf("TCX unavailable: %v", err)
+	}
+	// The probe detaches again, so it can run any number of times.
+	if err := HaveTCX(); err != nil {
+		t.Fatalf("second probe: %v", err)
+	}
+}
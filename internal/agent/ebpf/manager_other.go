@@ -12,19 +12,20 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-//go:build !pro
+//go:build !linux
 
 package ebpf
 
 import (
 	"errors"
+
 	"github.com/alatticeio/lattice/internal/agent/infra"
 	"github.com/alatticeio/lattice/internal/agent/log"
 )
 
-var errEBPFNotAvailable = errors.New("eBPF policy enforcement is a Lattice Pro feature — upgrade at https://alattice.io/pro")
+var errEBPFNotAvailable = errors.New("eBPF policy enforcement requires Linux")
 
-// Manager is a no-op stub in community builds.
+// Manager is a no-op stub on platforms without eBPF.
 type Manager struct {
 	logger *log.Logger
 }
@@ -34,8 +35,15 @@ func NewManager(iface string, logger *log.Logger) *Manager {
 	return &Manager{logger: logger}
 }
 
+// HaveTCX always fails on platforms without eBPF.
+func HaveTCX() error { return errEBPFNotAvailable }
+
 func (m *Manager) Load() error                           { return errEBPFNotAvailable }
 func (m *Manager) Provision(_ *infra.FirewallRule) error { return errEBPFNotAvailable }
-func (m *Manager) Cleanup() error                        { return nil }
-func (m *Manager) Name() string                          { return "ebpf" }
-func (m *Manager) SetupNAT(_ string) error               { return nil }
+func (m *Manager) RuleStats() ([]RuleStat, error)        { return nil, errEBPFNotAvailable }
+func (m *Manager) FlowRecords() ([]infra.FlowRecord, error) {
+	return nil, errEBPFNotAvailable
+}
+func (m *Manager) Cleanup() error          { return nil }
+func (m *Manager) Name() string            { return "ebpf" }
+func (m *Manager) SetupNAT(_ string) error { return nil }
@@ -0,0 +1,538 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package ebpf
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+)
+
+// Map and program names of the policy collection.
+const (
+	mapConfig  = "lattice_policy_config"
+	mapFlows   = "lattice_policy_flows"
+	mapHits    = "lattice_policy_hits"
+	mapFlowLog = "lattice_policy_flowlog"
+	progPrefix = "lattice_tc_"
+)
+
+// trieName returns the rule trie of dir in policy slot.
+func trieName(dir Direction, slot int) string {
+	return fmt.Sprintf("lattice_%s_%d", dir, slot)
+}
+
+// policyConfig is the single value of mapConfig. Active selects the slot the
+// programs read; each slot carries the default action and its counter ID per
+// direction.
+type policyConfig struct {
+	Active uint32
+	Slots  [2]slotConfig
+}
+
+type slotConfig struct {
+	Default [2]defaultRule
+}
+
+type defaultRule struct {
+	Action uint32
+	ID     uint32
+}
+
+// Byte offsets inside policyConfig.
+const (
+	configSlotsOff = 4
+	configSlotSize = 16
+	configDirSize  = 8
+)
+
+// flowKey identifies an accepted flow from the local point of view, so that
+// the egress and ingress halves of a connection share one entry.
+type flowKey struct {
+	Local      [16]byte
+	Remote     [16]byte
+	LocalPort  uint16 // network byte order
+	RemotePort uint16 // network byte order
+	Proto      uint8
+	_          [3]byte
+}
+
+// flowLogKey is the key of mapFlowLog: a flowKey that also tells the
+// direction, the verdict and whether the packet passed on the flow fast path.
+type flowLogKey struct {
+	Local       [16]byte
+	Remote      [16]byte
+	LocalPort   uint16 // network byte order
+	RemotePort  uint16 // network byte order
+	Proto       uint8
+	Dir         uint8
+	Action      uint8
+	Established uint8
+}
+
+// flowLogValue counts the packets of a flowLogKey. RuleID is the counter
+// index of the deciding rule, or for established flows of the rule that
+// accepted the connection.
+type flowLogValue struct {
+	Packets uint64
+	Bytes   uint64
+	RuleID  uint32
+	_       uint32
+}
+
+// ruleCounter is the per-CPU value of mapHits.
+type ruleCounter struct {
+	Packets uint64
+	Bytes   uint64
+}
+
+const (
+	tcActOK   = 0
+	tcActShot = 2
+	// tcxNext hands the packet to the next program of a TCX chain.
+	tcxNext = -1
+
+	ethPIP   = 0x0800
+	ethPIPv6 = 0x86DD
+
+	bpfHdrStartNet = 1
+
+	skbLenOff      = 0
+	skbProtocolOff = 16
+)
+
+// Stack layout of the programs, as offsets from R10.
+const (
+	fpScratch = -48  // IP header, up to 40 bytes
+	fpFlow    = -88  // flowKey, 40 bytes
+	fpLPM     = -112 // lpmKey, 20 bytes
+	fpPorts   = -120 // L4 source and destination port
+	fpKey     = -124 // u32 key for array lookups
+	fpActive  = -128 // active policy slot
+	fpFlowVal = -136 // u64 value for mapFlows
+	fpLog     = -176 // flowLogKey, 40 bytes
+	fpLogVal  = -200 // flowLogValue, 24 bytes
+)
+
+const (
+	flowLocalOff      = 0
+	flowRemoteOff     = 16
+	flowLocalPortOff  = 32
+	flowRemotePortOff = 34
+	flowProtoOff      = 36
+	flowKeySize       = 40
+
+	flowLogDirOff         = 37
+	flowLogActionOff      = 38
+	flowLogEstablishedOff = 39
+	flowLogRuleIDOff      = 16
+)
+
+// newCollectionSpec describes the maps and both TC programs.
+func newCollectionSpec() *ebpf.CollectionSpec {
+	spec := &ebpf.CollectionSpec{
+		Maps: map[string]*ebpf.MapSpec{
+			mapConfig: {
+				Name:       mapConfig,
+				Type:       ebpf.Array,
+				KeySize:    4,
+				ValueSize:  uint32(binary.Size(policyConfig{})),
+				MaxEntries: 1,
+			},
+			mapFlows: {
+				Name:       mapFlows,
+				Type:       ebpf.LRUHash,
+				KeySize:    flowKeySize,
+				ValueSize:  8,
+				MaxEntries: maxFlows,
+			},
+			mapFlowLog: {
+				Name:       mapFlowLog,
+				Type:       ebpf.LRUHash,
+				KeySize:    uint32(binary.Size(flowLogKey{})),
+				ValueSize:  uint32(binary.Size(flowLogValue{})),
+				MaxEntries: maxFlowLog,
+			},
+			mapHits: {
+				Name:       mapHits,
+				Type:       ebpf.PerCPUArray,
+				KeySize:    4,
+				ValueSize:  uint32(binary.Size(ruleCounter{})),
+				MaxEntries: numCounters,
+			},
+		},
+		Programs: map[string]*ebpf.ProgramSpec{},
+	}
+
+	for _, dir := range []Direction{Ingress, Egress} {
+		for slot := 0; slot < 2; slot++ {
+			name := trieName(dir, slot)
+			spec.Maps[name] = &ebpf.MapSpec{
+				Name:       name,
+				Type:       ebpf.LPMTrie,
+				KeySize:    uint32(binary.Size(lpmKey{})),
+				ValueSize:  uint32(binary.Size(ruleSet{})),
+				MaxEntries: maxPolicyPrefixes,
+				Flags:      1, // BPF_F_NO_PREALLOC, required for LPM tries
+			}
+		}
+		name := progPrefix + dir.String()
+		spec.Programs[name] = &ebpf.ProgramSpec{
+			Name:         name,
+			Type:         ebpf.SchedCLS,
+			License:      "Apache-2.0",
+			Instructions: buildProgram(dir),
+		}
+	}
+	return spec
+}
+
+// htons returns v in network byte order as the program sees it in a
+// native-endian load.
+func htons(v uint16) int32 {
+	return int32(binary.NativeEndian.Uint16(binary.BigEndian.AppendUint16(nil, v)))
+}
+
+// ntohs converts a port loaded natively from network byte order.
+func ntohs(v uint16) uint16 {
+	return binary.BigEndian.Uint16(binary.NativeEndian.AppendUint16(nil, v))
+}
+
+// buildProgram assembles the TC program for dir.
+//
+// Register use: R6 holds the skb, R7 the matched rule set and then the
+// verdict, R8 the destination port in host order and R9 the L4 protocol.
+//
+// Non-first IPv4 fragments and IPv6 packets with extension headers carry no
+// ports where the program looks for them; they only match rules without a
+// port.
+func buildProgram(dir Direction) asm.Instructions {
+	// The remote end is the source of ingress and the destination of egress
+	// traffic; the destination port is the service port in both cases.
+	v4Local, v4Remote := int16(16), int16(12)
+	v6Local, v6Remote := int16(24), int16(8)
+	if dir == Egress {
+		v4Local, v4Remote = v4Remote, v4Local
+		v6Local, v6Remote = v6Remote, v6Local
+	}
+
+	var label int
+	newLabel := func(name string) string {
+		label++
+		return fmt.Sprintf("%s_%d", name, label)
+	}
+
+	// countHit increments the counter whose index is stored at fpKey.
+	countHit := func() asm.Instructions {
+		skip := newLabel("hit_done")
+		return asm.Instructions{
+			asm.LoadMapPtr(asm.R1, 0).WithReference(mapHits),
+			asm.Mov.Reg(asm.R2, asm.RFP),
+			asm.Add.Imm(asm.R2, fpKey),
+			asm.FnMapLookupElem.Call(),
+			asm.JEq.Imm(asm.R0, 0, skip),
+			asm.LoadMem(asm.R1, asm.R0, 0, asm.DWord),
+			asm.Add.Imm(asm.R1, 1),
+			asm.StoreMem(asm.R0, 0, asm.R1, asm.DWord),
+			asm.LoadMem(asm.R1, asm.R6, skbLenOff, asm.Word),
+			asm.LoadMem(asm.R2, asm.R0, 8, asm.DWord),
+			asm.Add.Reg(asm.R2, asm.R1),
+			asm.StoreMem(asm.R0, 8, asm.R2, asm.DWord),
+			asm.Mov.Imm(asm.R0, 0).WithSymbol(skip),
+		}
+	}
+
+	// copyFlow copies the flow key into the flow log key; the caller then
+	// sets the verdict, the established flag and the rule ID before logFlow
+	// adds the packet to the entry.
+	copyFlow := func() asm.Instructions {
+		var insns asm.Instructions
+		for off := int16(0); off < flowKeySize; off += 8 {
+			insns = append(insns,
+				asm.LoadMem(asm.R1, asm.RFP, fpFlow+off, asm.DWord),
+				asm.StoreMem(asm.RFP, fpLog+off, asm.R1, asm.DWord),
+			)
+		}
+		return append(insns, asm.StoreImm(asm.RFP, fpLog+flowLogDirOff, int64(dir), asm.Byte))
+	}
+	logFlow := func() asm.Instructions {
+		create := newLabel("log_create")
+		done := newLabel("log_done")
+		return asm.Instructions{
+			asm.LoadMapPtr(asm.R1, 0).WithReference(mapFlowLog),
+			asm.Mov.Reg(asm.R2, asm.RFP),
+			asm.Add.Imm(asm.R2, fpLog),
+			asm.FnMapLookupElem.Call(),
+			asm.JEq.Imm(asm.R0, 0, create),
+			asm.Mov.Imm(asm.R1, 1),
+			asm.StoreXAdd(asm.R0, asm.R1, asm.DWord),
+			asm.LoadMem(asm.R1, asm.R6, skbLenOff, asm.Word),
+			asm.Add.Imm(asm.R0, 8),
+			asm.StoreXAdd(asm.R0, asm.R1, asm.DWord),
+			asm.Ja.Label(done),
+
+			asm.Mov.Imm(asm.R1, 1).WithSymbol(create),
+			asm.StoreMem(asm.RFP, fpLogVal, asm.R1, asm.DWord),
+			asm.LoadMem(asm.R1, asm.R6, skbLenOff, asm.Word),
+			asm.StoreMem(asm.RFP, fpLogVal+8, asm.R1, asm.DWord),
+			asm.StoreImm(asm.RFP, fpLogVal+flowLogRuleIDOff+4, 0, asm.Word),
+			asm.LoadMapPtr(asm.R1, 0).WithReference(mapFlowLog),
+			asm.Mov.Reg(asm.R2, asm.RFP),
+			asm.Add.Imm(asm.R2, fpLog),
+			asm.Mov.Reg(asm.R3, asm.RFP),
+			asm.Add.Imm(asm.R3, fpLogVal),
+			asm.Mov.Imm(asm.R4, 1), // BPF_NOEXIST
+			asm.FnMapUpdateElem.Call(),
+			asm.Mov.Imm(asm.R0, 0).WithSymbol(done),
+		}
+	}
+
+	// loadHeader copies size bytes at offset (register) of the network
+	// header into dst on the stack; R0 is zero on success.
+	loadHeader := func(offset asm.Register, dst int16, size int32) asm.Instructions {
+		return asm.Instructions{
+			asm.Mov.Reg(asm.R1, asm.R6),
+			asm.Mov.Reg(asm.R2, offset),
+			asm.Mov.Reg(asm.R3, asm.RFP),
+			asm.Add.Imm(asm.R3, int32(dst)),
+			asm.Mov.Imm(asm.R4, size),
+			asm.Mov.Imm(asm.R5, bpfHdrStartNet),
+			asm.FnSkbLoadBytesRelative.Call(),
+		}
+	}
+
+	insns := asm.Instructions{
+		asm.Mov.Reg(asm.R6, asm.R1),
+		asm.Mov.Imm(asm.R1, 0),
+	}
+	for off := int16(fpFlow); off < fpFlow+flowKeySize; off += 8 {
+		insns = append(insns, asm.StoreMem(asm.RFP, off, asm.R1, asm.DWord))
+	}
+	insns = append(insns,
+		asm.StoreMem(asm.RFP, fpLPM, asm.R1, asm.DWord),
+		asm.StoreMem(asm.RFP, fpLPM+8, asm.R1, asm.DWord),
+		asm.StoreMem(asm.RFP, fpLPM+16, asm.R1, asm.Word),
+		asm.Mov.Imm(asm.R8, 0),
+
+		asm.LoadMem(asm.R2, asm.R6, skbProtocolOff, asm.Word),
+		asm.JEq.Imm(asm.R2, htons(ethPIP), "ipv4"),
+		asm.JEq.Imm(asm.R2, htons(ethPIPv6), "ipv6"),
+		// Not IP: nothing to enforce.
+		asm.Mov.Imm(asm.R0, tcActOK),
+		asm.Return(),
+	)
+
+	// IPv4: addresses are stored IPv4-mapped.
+	insns = append(insns, asm.Mov.Imm(asm.R7, 0).WithSymbol("ipv4"))
+	insns = append(insns, loadHeader(asm.R7, fpScratch, 20)...)
+	insns = append(insns,
+		asm.JNE.Imm(asm.R0, 0, "drop"),
+		asm.LoadMem(asm.R9, asm.RFP, fpScratch+9, asm.Byte),
+		asm.LoadMem(asm.R7, asm.RFP, fpScratch, asm.Byte),
+		asm.And.Imm(asm.R7, 0x0f),
+		asm.LSh.Imm(asm.R7, 2),
+		asm.Mov.Imm(asm.R1, 0xffff),
+		asm.StoreMem(asm.RFP, fpFlow+flowLocalOff+10, asm.R1, asm.Half),
+		asm.StoreMem(asm.RFP, fpFlow+flowRemoteOff+10, asm.R1, asm.Half),
+		asm.LoadMem(asm.R1, asm.RFP, fpScratch+v4Local, asm.Word),
+		asm.StoreMem(asm.RFP, fpFlow+flowLocalOff+12, asm.R1, asm.Word),
+		asm.LoadMem(asm.R1, asm.RFP, fpScratch+v4Remote, asm.Word),
+		asm.StoreMem(asm.RFP, fpFlow+flowRemoteOff+12, asm.R1, asm.Word),
+		// Fragment offset != 0: no L4 header in this packet.
+		asm.LoadMem(asm.R1, asm.RFP, fpScratch+6, asm.Half),
+		asm.And.Imm(asm.R1, htons(0x1fff)),
+		asm.JNE.Imm(asm.R1, 0, "flow"),
+		asm.Ja.Label("l4"),
+	)
+
+	// IPv6: fixed 40-byte header.
+	insns = append(insns, asm.Mov.Imm(asm.R7, 0).WithSymbol("ipv6"))
+	insns = append(insns, loadHeader(asm.R7, fpScratch, 40)...)
+	insns = append(insns,
+		asm.JNE.Imm(asm.R0, 0, "drop"),
+		asm.LoadMem(asm.R9, asm.RFP, fpScratch+6, asm.Byte),
+		asm.Mov.Imm(asm.R7, 40),
+	)
+	for i := int16(0); i < 16; i += 8 {
+		insns = append(insns,
+			asm.LoadMem(asm.R1, asm.RFP, fpScratch+v6Local+i, asm.DWord),
+			asm.StoreMem(asm.RFP, fpFlow+flowLocalOff+i, asm.R1, asm.DWord),
+			asm.LoadMem(asm.R1, asm.RFP, fpScratch+v6Remote+i, asm.DWord),
+			asm.StoreMem(asm.RFP, fpFlow+flowRemoteOff+i, asm.R1, asm.DWord),
+		)
+	}
+
+	// L4 ports for TCP, UDP and SCTP; R7 holds the L4 offset.
+	localPort, remotePort := asm.R2, asm.R1
+	if dir == Egress {
+		localPort, remotePort = asm.R1, asm.R2
+	}
+	insns = append(insns,
+		asm.JEq.Imm(asm.R9, protoTCP, "ports").WithSymbol("l4"),
+		asm.JEq.Imm(asm.R9, protoUDP, "ports"),
+		asm.JEq.Imm(asm.R9, protoSCTP, "ports"),
+		asm.Ja.Label("flow"),
+	)
+	loadPorts := loadHeader(asm.R7, fpPorts, 4)
+	loadPorts[0] = loadPorts[0].WithSymbol("ports")
+	insns = append(insns, loadPorts...)
+	insns = append(insns,
+		asm.JNE.Imm(asm.R0, 0, "flow"),
+		asm.LoadMem(asm.R1, asm.RFP, fpPorts, asm.Half),
+		asm.LoadMem(asm.R2, asm.RFP, fpPorts+2, asm.Half),
+		asm.StoreMem(asm.RFP, fpFlow+flowLocalPortOff, localPort, asm.Half),
+		asm.StoreMem(asm.RFP, fpFlow+flowRemotePortOff, remotePort, asm.Half),
+		asm.Mov.Reg(asm.R8, asm.R2),
+		asm.HostTo(asm.BE, asm.R8, asm.Half),
+
+		// Replies of accepted flows pass without rule evaluation.
+		asm.StoreMem(asm.RFP, fpFlow+flowProtoOff, asm.R9, asm.Byte).WithSymbol("flow"),
+		asm.LoadMapPtr(asm.R1, 0).WithReference(mapFlows),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, fpFlow),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "rules"),
+		asm.LoadMem(asm.R7, asm.R0, 0, asm.DWord),
+		asm.StoreImm(asm.RFP, fpKey, int64(flowCounterIdx+int(dir)), asm.Word),
+	)
+	insns = append(insns, countHit()...)
+	insns = append(insns, copyFlow()...)
+	insns = append(insns,
+		asm.StoreImm(asm.RFP, fpLog+flowLogActionOff, ActionAccept, asm.Byte),
+		asm.StoreImm(asm.RFP, fpLog+flowLogEstablishedOff, 1, asm.Byte),
+		asm.StoreMem(asm.RFP, fpLogVal+flowLogRuleIDOff, asm.R7, asm.Word),
+	)
+	insns = append(insns, logFlow()...)
+	insns = append(insns,
+		asm.Mov.Imm(asm.R0, tcActOK),
+		asm.Return(),
+	)
+
+	// Look up the rule set of the remote address in the active slot.
+	insns = append(insns,
+		asm.StoreImm(asm.RFP, fpKey, 0, asm.Word).WithSymbol("rules"),
+		asm.LoadMapPtr(asm.R1, 0).WithReference(mapConfig),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, fpKey),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "drop"),
+		asm.LoadMem(asm.R1, asm.R0, 0, asm.Word),
+		asm.StoreMem(asm.RFP, fpActive, asm.R1, asm.Word),
+		asm.StoreImm(asm.RFP, fpLPM, 128, asm.Word),
+	)
+	for i := int16(0); i < 16; i += 4 {
+		insns = append(insns,
+			asm.LoadMem(asm.R1, asm.RFP, fpFlow+flowRemoteOff+i, asm.Word),
+			asm.StoreMem(asm.RFP, fpLPM+4+i, asm.R1, asm.Word),
+		)
+	}
+	insns = append(insns,
+		asm.LoadMem(asm.R1, asm.RFP, fpActive, asm.Word),
+		asm.JNE.Imm(asm.R1, 0, "slot1"),
+		asm.LoadMapPtr(asm.R1, 0).WithReference(trieName(dir, 0)),
+		asm.Ja.Label("lookup"),
+		asm.LoadMapPtr(asm.R1, 0).WithReference(trieName(dir, 1)).WithSymbol("slot1"),
+		asm.Mov.Reg(asm.R2, asm.RFP).WithSymbol("lookup"),
+		asm.Add.Imm(asm.R2, fpLPM),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "default"),
+		asm.Mov.Reg(asm.R7, asm.R0),
+		asm.LoadMem(asm.R5, asm.R7, 0, asm.Word),
+	)
+
+	// First matching rule wins; the loop is unrolled.
+	for i := 0; i < maxRulesPerPrefix; i++ {
+		base := int16(ruleSetRulesOff + i*ruleEntrySize)
+		next := fmt.Sprintf("rule_%d", i+1)
+		if i == maxRulesPerPrefix-1 {
+			next = "default"
+		}
+		portCheck := fmt.Sprintf("rule_%d_ports", i)
+		match := fmt.Sprintf("rule_%d_match", i)
+
+		insns = append(insns,
+			asm.JLE.Imm(asm.R5, int32(i), "default").WithSymbol(fmt.Sprintf("rule_%d", i)),
+			asm.LoadMem(asm.R1, asm.R7, base+ruleProtoOff, asm.Byte),
+			asm.JEq.Imm(asm.R1, 0, portCheck),
+			asm.JNE.Reg(asm.R1, asm.R9, next),
+			asm.LoadMem(asm.R2, asm.R7, base+rulePortHiOff, asm.Half).WithSymbol(portCheck),
+			asm.JEq.Imm(asm.R2, 0, match),
+			asm.JGT.Reg(asm.R8, asm.R2, next),
+			asm.LoadMem(asm.R1, asm.R7, base+rulePortLoOff, asm.Half),
+			asm.JLT.Reg(asm.R8, asm.R1, next),
+			asm.LoadMem(asm.R1, asm.R7, base+ruleIDOff, asm.Word).WithSymbol(match),
+			asm.StoreMem(asm.RFP, fpKey, asm.R1, asm.Word),
+			asm.LoadMem(asm.R7, asm.R7, base+ruleActionOff, asm.Byte),
+			asm.Ja.Label("verdict"),
+		)
+	}
+
+	// No rule matched: the default action of the active slot.
+	defOff := func(slot int) int16 {
+		return int16(configSlotsOff + slot*configSlotSize + int(dir)*configDirSize)
+	}
+	insns = append(insns,
+		asm.StoreImm(asm.RFP, fpKey, 0, asm.Word).WithSymbol("default"),
+		asm.LoadMapPtr(asm.R1, 0).WithReference(mapConfig),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, fpKey),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "drop"),
+		asm.LoadMem(asm.R1, asm.RFP, fpActive, asm.Word),
+		asm.JNE.Imm(asm.R1, 0, "default_slot1"),
+		asm.LoadMem(asm.R7, asm.R0, defOff(0), asm.Word),
+		asm.LoadMem(asm.R1, asm.R0, defOff(0)+4, asm.Word),
+		asm.Ja.Label("default_store"),
+		asm.LoadMem(asm.R7, asm.R0, defOff(1), asm.Word).WithSymbol("default_slot1"),
+		asm.LoadMem(asm.R1, asm.R0, defOff(1)+4, asm.Word),
+		asm.StoreMem(asm.RFP, fpKey, asm.R1, asm.Word).WithSymbol("default_store"),
+	)
+
+	// Count and log the hit and apply the verdict in R7; accepted flows are
+	// remembered with their rule ID for their replies.
+	hit := countHit()
+	hit[0] = hit[0].WithSymbol("verdict")
+	insns = append(insns, hit...)
+	insns = append(insns, copyFlow()...)
+	insns = append(insns,
+		asm.StoreMem(asm.RFP, fpLog+flowLogActionOff, asm.R7, asm.Byte),
+		asm.StoreImm(asm.RFP, fpLog+flowLogEstablishedOff, 0, asm.Byte),
+		asm.LoadMem(asm.R1, asm.RFP, fpKey, asm.Word),
+		asm.StoreMem(asm.RFP, fpLogVal+flowLogRuleIDOff, asm.R1, asm.Word),
+	)
+	insns = append(insns, logFlow()...)
+	insns = append(insns,
+		asm.JEq.Imm(asm.R7, ActionDrop, "drop"),
+		asm.LoadMem(asm.R1, asm.RFP, fpKey, asm.Word),
+		asm.StoreMem(asm.RFP, fpFlowVal, asm.R1, asm.DWord),
+		asm.LoadMapPtr(asm.R1, 0).WithReference(mapFlows),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, fpFlow),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, fpFlowVal),
+		asm.Mov.Imm(asm.R4, 0), // BPF_ANY
+		asm.FnMapUpdateElem.Call(),
+		asm.Mov.Imm(asm.R0, tcActOK),
+		asm.Return(),
+
+		asm.Mov.Imm(asm.R0, tcActShot).WithSymbol("drop"),
+		asm.Return(),
+	)
+	return insns
+}
@@ -0,0 +1,312 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package ebpf
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+// Limits of the policy maps.
+const (
+	// maxPolicyPrefixes bounds the distinct peer prefixes per direction.
+	maxPolicyPrefixes = 4096
+	// maxRules bounds the TrafficRules (ingress and egress together) of one
+	// FirewallRule.
+	maxRules = 4096
+	// maxRulesPerPrefix bounds the rules that can apply to a single peer
+	// prefix; the BPF program walks them unrolled.
+	maxRulesPerPrefix = 32
+	// maxFlows is the size of the LRU map of accepted flows.
+	maxFlows = 65536
+	// maxFlowLog is the size of the LRU map of flow log entries awaiting
+	// collection.
+	maxFlowLog = 65536
+)
+
+// Counter layout: every policy slot owns slotCounters entries of the hit
+// map, the rule IDs first and then the two default actions. The flow-table
+// hits of both directions follow the two slots.
+const (
+	slotCounters   = maxRules + 2
+	flowCounterIdx = 2 * slotCounters
+	numCounters    = flowCounterIdx + 2
+)
+
+const (
+	protoICMP   = 1
+	protoTCP    = 6
+	protoUDP    = 17
+	protoICMPv6 = 58
+	protoSCTP   = 132
+)
+
+// lpmKey is the LPM trie key: a prefix length and an IPv6 address, with IPv4
+// carried in IPv4-mapped form (::ffff:a.b.c.d, prefix length + 96).
+type lpmKey struct {
+	PrefixLen uint32
+	Addr      [16]byte
+}
+
+// ruleEntry is one rule of a prefix's rule list. PortHi == 0 matches any
+// port; Proto == 0 matches any protocol.
+type ruleEntry struct {
+	ID     uint32
+	PortLo uint16
+	PortHi uint16
+	Proto  uint8
+	Action uint8
+	_      [2]byte
+}
+
+// ruleSet is the trie value. Rules are ordered most specific first and the
+// first match decides.
+type ruleSet struct {
+	Count uint32
+	_     uint32
+	Rules [maxRulesPerPrefix]ruleEntry
+}
+
+// Byte offsets inside ruleSet and ruleEntry, used by the program builder.
+const (
+	ruleSetRulesOff = 8
+	ruleEntrySize   = 12
+	ruleIDOff       = 0
+	rulePortLoOff   = 4
+	rulePortHiOff   = 6
+	ruleProtoOff    = 8
+	ruleActionOff   = 9
+)
+
+// compiledPolicy is a FirewallRule in map form, with rule IDs relative to
+// the slot they are written to.
+type compiledPolicy struct {
+	sets          [2]map[lpmKey]*ruleSet
+	defaultAction [2]uint8
+	stats         []RuleStat
+}
+
+type prefixRule struct {
+	prefix netip.Prefix
+	entry  ruleEntry
+}
+
+// compile turns rule into per-direction trie contents.
+func compile(rule *infra.FirewallRule) (*compiledPolicy, error) {
+	if n := len(rule.Ingress) + len(rule.Egress); n > maxRules {
+		return nil, fmt.Errorf("ebpf: %d rules exceed the limit of %d", n, maxRules)
+	}
+
+	cp := &compiledPolicy{}
+	for _, dir := range []Direction{Ingress, Egress} {
+		trs := rule.Ingress
+		if dir == Egress {
+			trs = rule.Egress
+		}
+
+		var rules []prefixRule
+		cp.defaultAction[dir] = ActionDrop
+		for _, tr := range trs {
+			entry, err := toRuleEntry(tr)
+			if err != nil {
+				return nil, err
+			}
+			entry.ID = uint32(len(cp.stats))
+			policy := tr.Policy
+			if policy == "" {
+				policy = rule.PolicyName
+			}
+			cp.stats = append(cp.stats, RuleStat{
+				Direction: dir,
+				Policy:    policy,
+				Peers:     tr.Peers,
+				Protocol:  tr.Protocol,
+				Port:      tr.Port,
+				EndPort:   tr.EndPort,
+				Action:    tr.Action,
+			})
+
+			// A rule without peers is the tail of the chain.
+			if len(tr.Peers) == 0 {
+				cp.defaultAction[dir] = entry.Action
+				continue
+			}
+			for _, peer := range tr.Peers {
+				prefix, err := parsePeer(peer)
+				if err != nil {
+					return nil, err
+				}
+				rules = append(rules, prefixRule{prefix: prefix, entry: entry})
+			}
+		}
+
+		sets, err := buildRuleSets(rules)
+		if err != nil {
+			return nil, err
+		}
+		cp.sets[dir] = sets
+	}
+	return cp, nil
+}
+
+// buildRuleSets gives every distinct prefix the rules of all prefixes that
+// contain it, since the trie only returns the longest match.
+func buildRuleSets(rules []prefixRule) (map[lpmKey]*ruleSet, error) {
+	prefixes := make(map[netip.Prefix]struct{})
+	for _, r := range rules {
+		prefixes[r.prefix] = struct{}{}
+	}
+	if len(prefixes) > maxPolicyPrefixes {
+		return nil, fmt.Errorf("ebpf: %d peer prefixes exceed the limit of %d", len(prefixes), maxPolicyPrefixes)
+	}
+
+	sets := make(map[lpmKey]*ruleSet, len(prefixes))
+	for p := range prefixes {
+		var matching []prefixRule
+		for _, r := range rules {
+			if r.prefix.Bits() <= p.Bits() && r.prefix.Contains(p.Addr()) {
+				matching = append(matching, r)
+			}
+		}
+		if len(matching) > maxRulesPerPrefix {
+			return nil, fmt.Errorf("ebpf: %d rules apply to %s, limit is %d", len(matching), p, maxRulesPerPrefix)
+		}
+		sortRules(matching)
+
+		set := &ruleSet{Count: uint32(len(matching))}
+		for i, r := range matching {
+			set.Rules[i] = r.entry
+		}
+		sets[lpmKey{PrefixLen: uint32(p.Bits()), Addr: p.Addr().As16()}] = set
+	}
+	return sets, nil
+}
+
+// sortRules orders rules most specific first: longer peer prefix, then a
+// narrower port range, then a specific protocol. Accept wins a tie.
+func sortRules(rules []prefixRule) {
+	span := func(e ruleEntry) int {
+		if e.PortHi == 0 {
+			return 1 << 16
+		}
+		return int(e.PortHi) - int(e.PortLo)
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.prefix.Bits() != b.prefix.Bits() {
+			return a.prefix.Bits() > b.prefix.Bits()
+		}
+		if sa, sb := span(a.entry), span(b.entry); sa != sb {
+			return sa < sb
+		}
+		if (a.entry.Proto == 0) != (b.entry.Proto == 0) {
+			return a.entry.Proto != 0
+		}
+		if a.entry.Action != b.entry.Action {
+			return a.entry.Action == ActionAccept
+		}
+		return a.entry.ID < b.entry.ID
+	})
+}
+
+// protoName is the inverse of the protocol mapping of toRuleEntry; other
+// protocols are reported by number.
+func protoName(proto uint8) string {
+	switch proto {
+	case protoTCP:
+		return "tcp"
+	case protoUDP:
+		return "udp"
+	case protoSCTP:
+		return "sctp"
+	case protoICMP:
+		return "icmp"
+	case protoICMPv6:
+		return "icmpv6"
+	}
+	return strconv.Itoa(int(proto))
+}
+
+// toRuleEntry converts the protocol, ports and action of tr. As with the
+// iptables enforcer, ports only apply together with a protocol.
+func toRuleEntry(tr infra.TrafficRule) (ruleEntry, error) {
+	var e ruleEntry
+	switch strings.ToLower(tr.Protocol) {
+	case "":
+	case "tcp":
+		e.Proto = protoTCP
+	case "udp":
+		e.Proto = protoUDP
+	case "sctp":
+		e.Proto = protoSCTP
+	case "icmp":
+		e.Proto = protoICMP
+	case "icmpv6", "ipv6-icmp":
+		e.Proto = protoICMPv6
+	default:
+		return e, fmt.Errorf("ebpf: unsupported protocol %q", tr.Protocol)
+	}
+
+	if e.Proto != 0 && tr.Port != 0 {
+		hi := tr.EndPort
+		if hi < tr.Port {
+			hi = tr.Port
+		}
+		if tr.Port < 0 || hi > 65535 {
+			return e, fmt.Errorf("ebpf: invalid port range %d-%d", tr.Port, hi)
+		}
+		e.PortLo, e.PortHi = uint16(tr.Port), uint16(hi)
+	}
+
+	switch strings.ToUpper(tr.Action) {
+	case "", "ACCEPT", "ALLOW":
+		e.Action = ActionAccept
+	default:
+		e.Action = ActionDrop
+	}
+	return e, nil
+}
+
+// parsePeer parses an IP or CIDR into its IPv4-mapped IPv6 prefix.
+func parsePeer(peer string) (netip.Prefix, error) {
+	peer = strings.TrimSpace(peer)
+	var prefix netip.Prefix
+	if strings.Contains(peer, "/") {
+		p, err := netip.ParsePrefix(peer)
+		if err != nil {
+			return prefix, fmt.Errorf("ebpf: invalid peer %q: %w", peer, err)
+		}
+		prefix = p
+	} else {
+		a, err := netip.ParseAddr(peer)
+		if err != nil {
+			return prefix, fmt.Errorf("ebpf: invalid peer %q: %w", peer, err)
+		}
+		prefix = netip.PrefixFrom(a, a.BitLen())
+	}
+
+	bits := prefix.Bits()
+	if prefix.Addr().Is4() {
+		bits += 96
+	}
+	return netip.PrefixFrom(netip.AddrFrom16(prefix.Addr().As16()), bits).Masked(), nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package ebpf
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+func TestParsePeer_MapsIPv4(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.1":      "::ffff:10.0.0.1/128",
+		"10.1.2.3/16":   "::ffff:10.1.0.0/112",
+		"fd00::1":       "fd00::1/128",
+		"fd00:1::7/48 ": "fd00:1::/48",
+	}
+	for in, want := range tests {
+		got, err := parsePeer(in)
+		if err != nil {
+			t.Fatalf("parsePeer(%q): %v", in, err)
+		}
+		if got != netip.MustParsePrefix(want) {
+			t.Errorf("parsePeer(%q) = %s, want %s", in, got, want)
+		}
+	}
+	if _, err := parsePeer("not-an-ip"); err == nil {
+		t.Error("expected error for invalid peer")
+	}
+}
+
+func TestCompile_InheritsAndOrdersRules(t *testing.T) {
+	cp, err := compile(&infra.FirewallRule{
+		Ingress: []infra.TrafficRule{
+			{Peers: []string{"10.0.0.0/8"}, Action: "DROP"},
+			{Peers: []string{"10.0.0.5"}, Protocol: "tcp", Port: 8000, EndPort: 8100, Action: "ACCEPT"},
+			{Peers: []string{"10.0.0.5"}, Protocol: "tcp", Port: 22, Action: "ACCEPT"},
+			{Action: "DROP"},
+		},
+		Egress: []infra.TrafficRule{{Action: "ACCEPT"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.defaultAction[Ingress] != ActionDrop || cp.defaultAction[Egress] != ActionAccept {
+		t.Fatalf("default actions = %v", cp.defaultAction)
+	}
+
+	host := cp.sets[Ingress][lpmKey{PrefixLen: 128, Addr: netip.MustParseAddr("::ffff:10.0.0.5").As16()}]
+	if host == nil || host.Count != 3 {
+		t.Fatalf("host rule set = %+v, want 3 rules including the inherited /8", host)
+	}
+	// Single port, then the range, then the inherited any-port drop.
+	if r := host.Rules[0]; r.PortLo != 22 || r.PortHi != 22 || r.Proto != protoTCP {
+		t.Errorf("rule 0 = %+v, want tcp/22", r)
+	}
+	if r := host.Rules[1]; r.PortLo != 8000 || r.PortHi != 8100 {
+		t.Errorf("rule 1 = %+v, want tcp/8000-8100", r)
+	}
+	if r := host.Rules[2]; r.PortHi != 0 || r.Action != ActionDrop || r.ID != 0 {
+		t.Errorf("rule 2 = %+v, want inherited drop", r)
+	}
+
+	net8 := cp.sets[Ingress][lpmKey{PrefixLen: 104, Addr: netip.MustParseAddr("::ffff:10.0.0.0").As16()}]
+	if net8 == nil || net8.Count != 1 {
+		t.Fatalf("/8 rule set = %+v, want only its own rule", net8)
+	}
+	if len(cp.stats) != 5 {
+		t.Errorf("got %d rule stats, want 5", len(cp.stats))
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	if _, err := compile(&infra.FirewallRule{Ingress: []infra.TrafficRule{{Peers: []string{"10.0.0.1"}, Protocol: "gre"}}}); err == nil {
+		t.Error("expected error for unsupported protocol")
+	}
+
+	var many []infra.TrafficRule
+	for i := 0; i <= maxRulesPerPrefix; i++ {
+		many = append(many, infra.TrafficRule{Peers: []string{"10.0.0.1"}, Protocol: "tcp", Port: 1000 + i})
+	}
+	if _, err := compile(&infra.FirewallRule{Ingress: many}); err == nil {
+		t.Error("expected error when a prefix exceeds the per-prefix rule limit")
+	}
+}
@@ -27,3 +27,32 @@ type PolicyEntry struct {
 	Port     int    // 0 for all ports
 	Action   uint8  // ActionAccept or ActionDrop
 }
+
+// Direction is the traffic direction a rule applies to.
+type Direction uint8
+
+const (
+	Ingress Direction = iota
+	Egress
+)
+
+func (d Direction) String() string {
+	if d == Egress {
+		return "egress"
+	}
+	return "ingress"
+}
+
+// RuleStat is the hit counter of one provisioned TrafficRule. Rules without
+// peers report the default action of their direction.
+type RuleStat struct {
+	Direction Direction
+	Policy    string
+	Peers     []string
+	Protocol  string
+	Port      int
+	EndPort   int
+	Action    string
+	Packets   uint64
+	Bytes     uint64
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/log"
+)
+
+const flowLogTimeout = 10 * time.Second
+
+// flowLogBatch bounds the records of one NATS request so a busy interval
+// stays below the default 1 MiB payload limit.
+const flowLogBatch = 2000
+
+// StartFlowLog sends the flows recorded by the policy enforcer to the
+// management server every interval. It returns at once when the enforcer
+// records no flows (iptables) or interval is not positive.
+// It runs until ctx is cancelled and is safe to run in a goroutine.
+func (c *Node) StartFlowLog(ctx context.Context, interval time.Duration) {
+	if c.flowLog == nil || interval <= 0 {
+		return
+	}
+	logger := log.GetLogger("flowlog")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case end := <-ticker.C:
+			c.sendFlowLog(ctx, logger, start, end)
+			start = end
+		}
+	}
+}
+
+func (c *Node) sendFlowLog(ctx context.Context, logger *log.Logger, start, end time.Time) {
+	records, err := c.flowLog.FlowRecords()
+	if err != nil {
+		logger.Warn("read flow log failed", "err", err)
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	names := c.peerNamesByIP()
+	for i := range records {
+		records[i].RemotePeer = names[records[i].RemoteIP]
+	}
+
+	for len(records) > 0 {
+		n := min(len(records), flowLogBatch)
+		report := infra.FlowLogReport{
+			AppID:     config.Conf.AppId,
+			Namespace: c.current.NetworkId,
+			Start:     start,
+			End:       end,
+			Records:   records[:n],
+		}
+		records = records[n:]
+
+		data, err := json.Marshal(report)
+		if err != nil {
+			logger.Error("marshal flow log failed", err)
+			return
+		}
+		reqCtx, cancel := context.WithTimeout(ctx, flowLogTimeout)
+		_, err = c.ctrClient.RequestNats(reqCtx, "lattice.signals.peer", "flowlog", data)
+		cancel()
+		if err != nil {
+			logger.Warn("flow log send failed", "err", err, "records", n)
+		}
+	}
+}
+
+// peerNamesByIP maps the overlay address of every peer in the applied
+// network map to its name.
+func (c *Node) peerNamesByIP() map[string]string {
+	names := make(map[string]string)
+	if c.messageHandler == nil {
+		return names
+	}
+	msg := c.messageHandler.LastApplied()
+	if msg == nil || msg.Network == nil {
+		return names
+	}
+	for _, p := range msg.Network.Peers {
+		if p == nil || p.Address == nil {
+			continue
+		}
+		addr, _, _ := strings.Cut(*p.Address, "/")
+		if ip, err := netip.ParseAddr(addr); err == nil {
+			names[ip.Unmap().String()] = p.Name
+		}
+	}
+	return names
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/infra"
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"time"
 )
@@ -27,6 +28,13 @@ const heartbeatTimeout = 5 * time.Second
 
 type heartbeatPayload struct {
 	AppID string `json:"appId"`
+	// Peers carries the current connection path to each remote peer so the
+	// management server can tell direct from relayed sessions.
+	Peers []infra.PeerPath `json:"peers,omitempty"`
+	// Namespace and Relay report the home relay chosen by the agent so the
+	// server can label the LatticePeer and keep relay load counts current.
+	Namespace string `json:"namespace,omitempty"`
+	Relay     string `json:"relay,omitempty"`
 }
 
 // StartHeartbeat sends a periodic heartbeat to the management server via NATS
@@ -36,13 +44,17 @@ func (c *Node) StartHeartbeat(ctx context.Context) {
 	logger := log.GetLogger("heartbeat")
 	appId := config.Conf.AppId
 
-	data, err := json.Marshal(heartbeatPayload{AppID: appId})
-	if err != nil {
-		logger.Error("marshal heartbeat payload failed", err)
-		return
-	}
-
 	send := func() {
+		payload := heartbeatPayload{AppID: appId, Peers: c.PeerPaths()}
+		if c.relays != nil {
+			payload.Namespace = c.current.NetworkId
+			payload.Relay = c.relays.Home()
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			logger.Error("marshal heartbeat payload failed", err)
+			return
+		}
 		hbCtx, cancel := context.WithTimeout(ctx, heartbeatTimeout)
 		defer cancel()
 		if _, err := c.ctrClient.RequestNats(hbCtx, "lattice.signals.peer", "heartbeat", data); err != nil {
@@ -0,0 +1,57 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infra
+
+import "time"
+
+// Flow verdicts.
+const (
+	VerdictAccept = "accept"
+	VerdictDrop   = "drop"
+)
+
+// FlowRecord is the traffic of one connection in one direction that
+// received the same verdict during a reporting interval. Addresses and ports
+// are seen from the reporting peer.
+type FlowRecord struct {
+	Direction  string `json:"direction"` // ingress | egress
+	Verdict    string `json:"verdict"`   // accept | drop
+	Protocol   string `json:"protocol"`
+	LocalIP    string `json:"localIP"`
+	LocalPort  int    `json:"localPort,omitempty"`
+	RemoteIP   string `json:"remoteIP"`
+	RemotePort int    `json:"remotePort,omitempty"`
+	// RemotePeer is the name of the peer that owns RemoteIP; empty for
+	// addresses outside the network.
+	RemotePeer string `json:"remotePeer,omitempty"`
+	// Policy names the LatticePolicy whose rule decided the flow, or
+	// "default-deny" when no rule matched.
+	Policy string `json:"policy,omitempty"`
+	// Established is set for replies of an accepted connection, which pass
+	// without rule evaluation.
+	Established bool   `json:"established,omitempty"`
+	Packets     uint64 `json:"packets"`
+	Bytes       uint64 `json:"bytes"`
+}
+
+// FlowLogReport is sent by an agent to the management server at the end of
+// every reporting interval.
+type FlowLogReport struct {
+	AppID     string       `json:"appId"`
+	Namespace string       `json:"namespace"`
+	Start     time.Time    `json:"start"`
+	End       time.Time    `json:"end"`
+	Records   []FlowRecord `json:"records"`
+}
@@ -37,6 +37,14 @@ type Message struct {
 	ComputedPeers []*Peer           `json:"computedpeers,omitempty"` //当前要连接的节点, 由controller计算完成返回给lattice
 	ComputedRules *FirewallRule     `json:"computedrules,omitempty"`
 	Labels        map[string]string `json:"labels,omitempty"`
+	// ComputedQoS is the traffic shaping the node applies on its interface;
+	// nil when neither the peer nor its policies configure any.
+	ComputedQoS *QoS `json:"computedqos,omitempty"`
+	// Relays lists the WRRP relays the peer may use, sorted by name.
+	Relays []*Relay `json:"relays,omitempty"`
+	// RelayTicket is presented to those relays on registration. It is
+	// signed by the manager and names the peer's workspace.
+	RelayTicket string `json:"relayTicket,omitempty"`
 }
 
 func (m *Message) Equal(b *Message) bool {
@@ -60,6 +68,18 @@ func (m *Message) Equal(b *Message) bool {
 		return false
 	}
 
+	if !reflect.DeepEqual(m.ComputedQoS, b.ComputedQoS) {
+		return false
+	}
+
+	if !reflect.DeepEqual(m.Relays, b.Relays) {
+		return false
+	}
+
+	if m.RelayTicket != b.RelayTicket {
+		return false
+	}
+
 	if !reflect.DeepEqual(m.Current.Name, b.Current.Name) {
 		return false
 	}
@@ -123,6 +143,7 @@ type Peer struct {
 	Platform            string            `json:"platform,omitempty"`
 	Description         string            `json:"description,omitempty"`
 	NetworkId           string            `json:"NetworkId,omitempty"` // belong to which group
+	Namespace           string            `json:"namespace,omitempty"` // workspace namespace
 	CreatedBy           string            `json:"createdBy,omitempty"` // ownerID
 	UserId              uint64            `json:"userId,omitempty"`
 	Hostname            string            `json:"hostname,omitempty"`
@@ -144,6 +165,25 @@ type Peer struct {
 	Token               string            `json:"token,omitempty"`
 	WrrpUrl             string            `json:"wrrpUrl,omitempty"`
 	Labels              map[string]string `json:"labels,omitempty"`
+	// Relays carries the sender's connected relays, best first, in WRRP
+	// SYN/ACK peer info so both sides can pin the pair to a shared relay.
+	Relays []string `json:"relays,omitempty"`
+	// NetMaps lists the 1:1 translations a peering gateway applies between
+	// its local network and the virtual range the remote side sees.
+	NetMaps []NetMap `json:"netMaps,omitempty"`
+	// DNSRecords maps names of peers reachable through this peer to the
+	// address local peers use for them, e.g. the translated address of a
+	// peer behind a remote peering gateway.
+	DNSRecords map[string]string `json:"dnsRecords,omitempty"`
+}
+
+// NetMap is a stateless 1:1 prefix translation applied at a peering
+// gateway. Local and Virtual have the same prefix length; host bits are
+// preserved. Only traffic exchanged with Remote is translated.
+type NetMap struct {
+	Local   string `json:"local"`
+	Virtual string `json:"virtual"`
+	Remote  string `json:"remote"`
 }
 
 // Network is the network information, contains all peers/policies in the network
@@ -175,7 +215,11 @@ type Rule struct {
 	CIDRs     []string `json:"cidrs,omitempty"` // from IPBlock
 	Protocol  string   `json:"protocol"`
 	Port      int      `json:"port"`
-	Action    string   `json:"action,omitempty"` // "ALLOW" or "DENY"
+	EndPort   int      `json:"endPort,omitempty"` // inclusive upper bound of a port range
+	Action    string   `json:"action,omitempty"`  // "ALLOW" or "DENY"
+	RateMbps  int      `json:"rateMbps,omitempty"`
+	BurstKB   int      `json:"burstKB,omitempty"`
+	QoSClass  string   `json:"qosClass,omitempty"`
 }
 
 type TrafficRule struct {
@@ -183,9 +227,14 @@ type TrafficRule struct {
 	Peers     []string `json:"peers,omitempty"` // ip list
 	Protocol  string   `json:"protocol,omitempty"`
 	Port      int      `json:"port,omitempty"`
-	Action    string   `json:"action,omitempty"` // Accept or drop
+	EndPort   int      `json:"endPort,omitempty"` // inclusive upper bound of a port range
+	Action    string   `json:"action,omitempty"`  // Accept or drop
+	Policy    string   `json:"policy,omitempty"`  // name of the policy that decided the rule
 }
 
+// DefaultDenyPolicy is the Policy of the default-deny tail rules.
+const DefaultDenyPolicy = "default-deny"
+
 func NewMessage() *Message {
 	return &Message{}
 }
@@ -0,0 +1,73 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infra
+
+import "time"
+
+// PathKind is the user-facing classification of a peer connection path.
+type PathKind string
+
+const (
+	// PathNone means no transport is currently established.
+	PathNone PathKind = ""
+	// PathDirect means WireGuard traffic flows peer-to-peer (ICE).
+	PathDirect PathKind = "direct"
+	// PathRelay means WireGuard traffic is forwarded through a WRRP relay.
+	PathRelay PathKind = "relay"
+)
+
+// PathKindOf maps a transport type onto its user-facing path kind.
+func PathKindOf(t TransportType) PathKind {
+	if t == WRRP {
+		return PathRelay
+	}
+	return PathDirect
+}
+
+// PathEvent records a single change of the connection path to a peer.
+type PathEvent struct {
+	At     time.Time `json:"at"`
+	From   PathKind  `json:"from"`
+	To     PathKind  `json:"to"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// PeerPath is a point-in-time snapshot of how the local node reaches one
+// remote peer. It is served by the local agent API, printed by
+// `lattice status --peers` and attached to the heartbeat.
+type PeerPath struct {
+	AppID     string   `json:"appId"`
+	PublicKey string   `json:"publicKey"`
+	State     string   `json:"state"`
+	Path      PathKind `json:"path"`
+	Endpoint  string   `json:"endpoint,omitempty"`
+
+	// LocalCandidate and RemoteCandidate are the ICE candidate types of the
+	// selected pair (host, srflx, prflx or relay). Empty for relayed paths.
+	LocalCandidate  string `json:"localCandidate,omitempty"`
+	RemoteCandidate string `json:"remoteCandidate,omitempty"`
+
+	// RTTMillis is the round-trip time measured on the selected candidate
+	// pair during connectivity checks. Zero when unknown.
+	RTTMillis float64 `json:"rttMs,omitempty"`
+
+	// LastChange is when the path last changed (connect, upgrade, downgrade
+	// or failure). LastError holds the most recent discovery failure.
+	LastChange time.Time `json:"lastChange,omitempty"`
+	LastError  string    `json:"lastError,omitempty"`
+
+	// History keeps the most recent path changes, oldest first.
+	History []PathEvent `json:"history,omitempty"`
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infra
+
+// QoS classes; see v1alpha1.QoSClass.
+const (
+	QoSInteractive = "interactive"
+	QoSStandard    = "standard"
+	QoSBulk        = "bulk"
+)
+
+// QoS is the traffic shaping a node applies on its WireGuard interface.
+type QoS struct {
+	// EgressMbps and IngressMbps cap all traffic of the node; 0 means
+	// unlimited.
+	EgressMbps  int `json:"egressMbps,omitempty"`
+	IngressMbps int `json:"ingressMbps,omitempty"`
+	BurstKB     int `json:"burstKB,omitempty"`
+	// Class is the class of traffic no rule classifies; empty means
+	// standard.
+	Class   string    `json:"class,omitempty"`
+	Ingress []QoSRule `json:"ingress,omitempty"`
+	Egress  []QoSRule `json:"egress,omitempty"`
+}
+
+// QoSRule rate limits or classifies the traffic exchanged with Peers.
+type QoSRule struct {
+	Peers    []string `json:"peers"` // IPs or CIDRs
+	Protocol string   `json:"protocol,omitempty"`
+	Port     int      `json:"port,omitempty"`
+	EndPort  int      `json:"endPort,omitempty"`
+	RateMbps int      `json:"rateMbps,omitempty"`
+	BurstKB  int      `json:"burstKB,omitempty"`
+	Class    string   `json:"class,omitempty"`
+	Policy   string   `json:"policy,omitempty"`
+}
+
+// Empty reports whether q shapes nothing.
+func (q *QoS) Empty() bool {
+	return q == nil || (q.EgressMbps == 0 && q.IngressMbps == 0 && q.Class == "" &&
+		len(q.Ingress) == 0 && len(q.Egress) == 0)
+}
+
+// DSCP returns the DSCP value of a QoS class.
+func DSCP(class string) int {
+	switch class {
+	case QoSInteractive:
+		return 46 // EF
+	case QoSBulk:
+		return 8 // CS1
+	default:
+		return 0 // CS0
+	}
+}
+
+// BytesPerSecond converts a rate in Mbps to bytes per second.
+func BytesPerSecond(mbps int) int64 {
+	return int64(mbps) * 1_000_000 / 8
+}
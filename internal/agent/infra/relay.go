@@ -0,0 +1,48 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infra
+
+import "net"
+
+// Relay is a WRRP relay server offered to a peer by the control plane.
+// The list in Message.Relays only contains relays that are enabled, allowed
+// for the peer's namespace, not offline and not at capacity.
+type Relay struct {
+	Name    string `json:"name"`
+	TcpUrl  string `json:"tcpUrl"`
+	QuicUrl string `json:"quicUrl,omitempty"`
+	Region  string `json:"region,omitempty"`
+	// Load is the share of the relay's peer capacity in use, in percent
+	// rounded down to a multiple of 10. It is 0 for relays without a peer
+	// limit. The coarse steps keep the network map from changing with
+	// every peer that joins or leaves.
+	Load int `json:"load,omitempty"`
+}
+
+// RelaySelector is implemented by Wrrp clients that keep sessions to several
+// relays. Both sides of a peer pair exchange their Preferences in the WRRP
+// SYN/ACK and Pin the pair to the same relay so frames meet on one server.
+type RelaySelector interface {
+	// Preferences returns the names of the connected relays, best first.
+	Preferences() []string
+	// Pin selects the relay used to reach remoteId from the remote's
+	// preferences and returns its name ("" when no shared relay exists and
+	// the home relay is used).
+	Pin(remoteId uint64, remotePrefs []string, initiator bool) string
+	// RemoteAddrFor returns the address of the relay used to reach remoteId.
+	RemoteAddrFor(remoteId uint64) net.Addr
+	// Home returns the name of the preferred relay, or "" when none is connected.
+	Home() string
+}
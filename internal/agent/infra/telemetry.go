@@ -0,0 +1,26 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infra
+
+// TelemetryReport carries one Prometheus Remote Write batch (protobuf +
+// Snappy) from an agent to the management server, which forwards it to
+// VictoriaMetrics. Token is the agent's enrollment token; the server takes
+// the workspace from it and rejects a report for another namespace.
+type TelemetryReport struct {
+	Token     string `json:"token"`
+	AppID     string `json:"appId"`
+	Namespace string `json:"namespace"`
+	Payload   []byte `json:"payload"`
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+// Client talks to a running agent over its control socket.
+type Client struct {
+	http *http.Client
+	// stream is used for long-lived requests such as the event stream and
+	// therefore carries no overall timeout.
+	stream *http.Client
+}
+
+// NewClient returns a Client bound to the Unix socket at path.
+func NewClient(path string) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}
+	return &Client{
+		http:   &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		stream: &http.Client{Transport: transport},
+	}
+}
+
+// Status returns the interface and per-peer status of the agent.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var out Status
+	if err := c.do(ctx, http.MethodGet, "/status", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Peers returns the connection path to every remote peer known to the agent.
+func (c *Client) Peers(ctx context.Context) ([]infra.PeerPath, error) {
+	var out []infra.PeerPath
+	if err := c.do(ctx, http.MethodGet, "/peers", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Reconnect forces the agent to re-run discovery for the given peer.
+func (c *Client) Reconnect(ctx context.Context, appId string) error {
+	return c.do(ctx, http.MethodPost, "/peers/"+url.PathEscape(appId)+"/reconnect", nil, nil)
+}
+
+// Reload asks the agent to re-fetch and apply its network map.
+func (c *Client) Reload(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/reload", nil, nil)
+}
+
+// Config returns the network map last applied by the agent.
+func (c *Client) Config(ctx context.Context) (*infra.Message, error) {
+	var out infra.Message
+	if err := c.do(ctx, http.MethodGet, "/config", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LogLevel returns the agent's current log level.
+func (c *Client) LogLevel(ctx context.Context) (string, error) {
+	var out LogLevelRequest
+	if err := c.do(ctx, http.MethodGet, "/log-level", nil, &out); err != nil {
+		return "", err
+	}
+	return out.Level, nil
+}
+
+// SetLogLevel changes the agent's log level without a restart.
+func (c *Client) SetLogLevel(ctx context.Context, level string) error {
+	return c.do(ctx, http.MethodPut, "/log-level", LogLevelRequest{Level: level}, nil)
+}
+
+// Shutdown asks the agent to exit gracefully.
+func (c *Client) Shutdown(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/shutdown", nil, nil)
+}
+
+// Events streams agent events to fn until ctx is cancelled, the agent closes
+// the stream or fn returns an error.
+func (c *Client) Events(ctx context.Context, fn func(Event) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://lattice/"+Version+"/events", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.stream.Do(req)
+	if err != nil {
+		return fmt.Errorf("local api: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if err = checkResponse(resp, http.MethodGet, "/events"); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var ev Event
+		if err = dec.Decode(&ev); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err = fn(ev); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, route string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	// The host part is ignored by the Unix dialer but must be a valid URL.
+	req, err := http.NewRequestWithContext(ctx, method, "http://lattice/"+Version+route, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("local api: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if err = checkResponse(resp, method, route); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func checkResponse(resp *http.Response, method, route string) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	var body errorBody
+	if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Error != "" {
+		return fmt.Errorf("local api: %s", body.Error)
+	}
+	return fmt.Errorf("local api: %s %s: %s", method, route, resp.Status)
+}
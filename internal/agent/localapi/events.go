@@ -0,0 +1,67 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localapi
+
+import (
+	"sync"
+	"time"
+)
+
+// subscriberBuffer bounds how far a slow subscriber may lag before events
+// are dropped for it. Publishers never block.
+const subscriberBuffer = 64
+
+// EventBus fans out agent events to local API subscribers.
+type EventBus struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+// NewEventBus creates an EventBus with no subscribers.
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[chan Event]struct{})}
+}
+
+// Publish delivers an event to every subscriber without blocking.
+func (b *EventBus) Publish(typ string, data any) {
+	ev := Event{Type: typ, Time: time.Now(), Data: data}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// Subscribe registers a new subscriber. The returned cancel func must be
+// called to release it; the channel is closed afterwards.
+func (b *EventBus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
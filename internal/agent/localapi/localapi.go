@@ -0,0 +1,59 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package localapi serves the running agent's local control API over a Unix
+// socket and provides the matching client used by the lattice CLI.
+//
+// The API is plain JSON over HTTP; every route is prefixed with the API
+// version so clients and agents of different releases fail loudly instead of
+// misinterpreting each other.
+package localapi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Version is the current local API version, used as the URL prefix.
+const Version = "v1"
+
+// socketDir holds one control socket per running agent interface.
+var socketDir = "/var/run/lattice"
+
+// SocketPath returns the control socket path for the given interface.
+func SocketPath(iface string) string {
+	return filepath.Join(socketDir, iface+".sock")
+}
+
+// FindSocket resolves the control socket for iface. When iface is empty the
+// first socket found is returned, matching how `lattice status` picks the
+// first WireGuard interface when none is configured.
+func FindSocket(iface string) (string, error) {
+	if iface != "" {
+		path := SocketPath(iface)
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("lattice is not running on %s: %w", iface, err)
+		}
+		return path, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(socketDir, "*.sock"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("lattice is not running (no control socket in %s)", socketDir)
+	}
+	return matches[0], nil
+}
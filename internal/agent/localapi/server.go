@@ -0,0 +1,227 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/log"
+)
+
+// Backend is the subset of the running node exposed through the local API.
+type Backend interface {
+	// Status returns interface information and per-peer WireGuard counters.
+	Status() (*Status, error)
+	// PeerPaths returns the connection path to every known remote peer.
+	PeerPaths() []infra.PeerPath
+	// Reconnect tears down and re-runs discovery for one peer.
+	Reconnect(appId string) error
+	// Reload re-fetches the network map from the control plane and applies it.
+	Reload(ctx context.Context) error
+	// AppliedConfig returns the last applied network map with secrets removed.
+	AppliedConfig() *infra.Message
+	// Events returns the bus on which agent events are published.
+	Events() *EventBus
+	// Shutdown asks the agent to exit gracefully.
+	Shutdown()
+}
+
+// Server serves the local API on a Unix socket.
+type Server struct {
+	path    string
+	backend Backend
+	logger  *log.Logger
+}
+
+// NewServer creates a Server listening on path once Serve is called.
+func NewServer(path string, backend Backend) *Server {
+	return &Server{
+		path:    path,
+		backend: backend,
+		logger:  log.GetLogger("local-api"),
+	}
+}
+
+// Serve listens on the Unix socket and blocks until ctx is cancelled.
+// A stale socket left behind by a crashed agent is removed first.
+func (s *Server) Serve(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	_ = os.Remove(s.path)
+
+	ln, err := net.Listen("unix", s.path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(s.path) //nolint:errcheck
+
+	// Only root (and the owning group) may drive the agent.
+	if err = os.Chmod(s.path, 0660); err != nil {
+		s.logger.Warn("chmod control socket failed", "path", s.path, "err", err)
+	}
+
+	srv := &http.Server{
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+		// Request contexts derive from ctx so streaming handlers return
+		// when the agent shuts down.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	s.logger.Debug("local api listening", "path", s.path)
+	if err = srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	prefix := "/" + Version
+	mux.HandleFunc("GET "+prefix+"/status", s.handleStatus)
+	mux.HandleFunc("GET "+prefix+"/peers", s.handlePeers)
+	mux.HandleFunc("POST "+prefix+"/peers/{appId}/reconnect", s.handleReconnect)
+	mux.HandleFunc("POST "+prefix+"/reload", s.handleReload)
+	mux.HandleFunc("GET "+prefix+"/config", s.handleConfig)
+	mux.HandleFunc("GET "+prefix+"/log-level", s.handleGetLogLevel)
+	mux.HandleFunc("PUT "+prefix+"/log-level", s.handleSetLogLevel)
+	mux.HandleFunc("GET "+prefix+"/events", s.handleEvents)
+	mux.HandleFunc("POST "+prefix+"/shutdown", s.handleShutdown)
+	return mux
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	st, err := s.backend.Status()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (s *Server) handlePeers(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.backend.PeerPaths())
+}
+
+func (s *Server) handleReconnect(w http.ResponseWriter, r *http.Request) {
+	if err := s.backend.Reconnect(r.PathValue("appId")); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if err := s.backend.Reload(r.Context()); err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, _ *http.Request) {
+	msg := s.backend.AppliedConfig()
+	if msg == nil {
+		writeError(w, http.StatusNotFound, errors.New("no network map applied yet"))
+		return
+	}
+	writeJSON(w, http.StatusOK, msg)
+}
+
+func (s *Server) handleGetLogLevel(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, LogLevelRequest{Level: log.CurrentLevel()})
+}
+
+func (s *Server) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req LogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !log.ValidLevel(req.Level) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown log level %q", req.Level))
+		return
+	}
+	log.SetLevel(req.Level)
+	s.logger.Info("log level changed via local api", "level", req.Level)
+	s.backend.Events().Publish(EventLogLevel, LogLevelRequest{Level: log.CurrentLevel()})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEvents streams events as newline-delimited JSON until the client
+// disconnects.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming unsupported"))
+		return
+	}
+	events, cancel := s.backend.Events().Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-events:
+			if err := enc.Encode(ev); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) handleShutdown(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusAccepted)
+	s.logger.Info("shutdown requested via local api")
+	go s.backend.Shutdown()
+}
+
+// errorBody is the JSON body returned for every non-2xx response.
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorBody{Error: err.Error()})
+}
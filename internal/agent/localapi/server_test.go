@@ -0,0 +1,171 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localapi
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+type fakeBackend struct {
+	events     *EventBus
+	reconnects []string
+	shutdown   atomic.Bool
+}
+
+func (f *fakeBackend) Status() (*Status, error) {
+	return &Status{AppID: "node-a", Interface: "wf0"}, nil
+}
+
+func (f *fakeBackend) PeerPaths() []infra.PeerPath {
+	return []infra.PeerPath{{AppID: "node-b", Path: infra.PathRelay}}
+}
+
+func (f *fakeBackend) Reconnect(appId string) error {
+	if appId != "node-b" {
+		return errors.New("no connection to peer")
+	}
+	f.reconnects = append(f.reconnects, appId)
+	return nil
+}
+
+func (f *fakeBackend) Reload(context.Context) error { return nil }
+
+func (f *fakeBackend) AppliedConfig() *infra.Message {
+	return &infra.Message{ConfigVersion: "7"}
+}
+
+func (f *fakeBackend) Events() *EventBus { return f.events }
+
+func (f *fakeBackend) Shutdown() { f.shutdown.Store(true) }
+
+func startServer(t *testing.T) (*Client, *fakeBackend) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "wf0.sock")
+	backend := &fakeBackend{events: NewEventBus()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = NewServer(path, backend).Serve(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	client := NewClient(path)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := client.Status(context.Background()); err == nil {
+			return client, backend
+		} else if time.Now().After(deadline) {
+			t.Fatalf("local api did not come up: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLocalAPI_StatusPeersAndConfig(t *testing.T) {
+	client, _ := startServer(t)
+	ctx := context.Background()
+
+	st, err := client.Status(ctx)
+	if err != nil || st.AppID != "node-a" {
+		t.Fatalf("unexpected status %+v, err=%v", st, err)
+	}
+	peers, err := client.Peers(ctx)
+	if err != nil || len(peers) != 1 || peers[0].Path != infra.PathRelay {
+		t.Fatalf("unexpected peers %+v, err=%v", peers, err)
+	}
+	msg, err := client.Config(ctx)
+	if err != nil || msg.ConfigVersion != "7" {
+		t.Fatalf("unexpected config %+v, err=%v", msg, err)
+	}
+}
+
+func TestLocalAPI_ReconnectUnknownPeer(t *testing.T) {
+	client, backend := startServer(t)
+	ctx := context.Background()
+
+	if err := client.Reconnect(ctx, "node-b"); err != nil {
+		t.Fatalf("reconnect node-b: %v", err)
+	}
+	if err := client.Reconnect(ctx, "node-x"); err == nil {
+		t.Fatal("expected error for unknown peer")
+	}
+	if len(backend.reconnects) != 1 {
+		t.Errorf("expected one reconnect, got %v", backend.reconnects)
+	}
+}
+
+func TestLocalAPI_SetLogLevelRejectsUnknown(t *testing.T) {
+	client, _ := startServer(t)
+	if err := client.SetLogLevel(context.Background(), "verbose"); err == nil {
+		t.Fatal("expected error for unknown log level")
+	}
+}
+
+func TestLocalAPI_EventsStream(t *testing.T) {
+	client, backend := startServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	got := make(chan Event, 1)
+	go func() {
+		_ = client.Events(ctx, func(ev Event) error {
+			got <- ev
+			return errors.New("stop")
+		})
+	}()
+
+	// Publish until the subscriber is attached and receives one event.
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case ev := <-got:
+			if ev.Type != EventConfigApplied {
+				t.Fatalf("unexpected event %+v", ev)
+			}
+			return
+		case <-ticker.C:
+			backend.events.Publish(EventConfigApplied, ConfigAppliedEvent{ConfigVersion: "8"})
+		case <-ctx.Done():
+			t.Fatal("no event received")
+		}
+	}
+}
+
+func TestLocalAPI_Shutdown(t *testing.T) {
+	client, backend := startServer(t)
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !backend.shutdown.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("backend Shutdown was not called")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
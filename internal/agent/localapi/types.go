@@ -0,0 +1,80 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localapi
+
+import (
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+// Status is the response of GET /v1/status.
+type Status struct {
+	Version    string       `json:"version"`
+	AppID      string       `json:"appId"`
+	Interface  string       `json:"interface"`
+	Address    string       `json:"address,omitempty"`
+	PublicKey  string       `json:"publicKey"`
+	NetworkID  string       `json:"networkId,omitempty"`
+	ListenPort int          `json:"listenPort"`
+	LogLevel   string       `json:"logLevel"`
+	Relays     []string     `json:"relays,omitempty"` // connected relays, home relay first
+	Peers      []PeerStatus `json:"peers"`
+}
+
+// PeerStatus combines the WireGuard counters of one peer with the
+// connection path chosen by the transport layer.
+type PeerStatus struct {
+	PublicKey     string          `json:"publicKey"`
+	Endpoint      string          `json:"endpoint,omitempty"`
+	AllowedIPs    []string        `json:"allowedIps,omitempty"`
+	LastHandshake time.Time       `json:"lastHandshake,omitempty"`
+	RxBytes       int64           `json:"rxBytes"`
+	TxBytes       int64           `json:"txBytes"`
+	Path          *infra.PeerPath `json:"path,omitempty"`
+}
+
+// LogLevelRequest is the body of PUT /v1/log-level.
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// Event types published on GET /v1/events.
+const (
+	EventPeerPath      = "peer.path"
+	EventConfigApplied = "config.applied"
+	EventLogLevel      = "log.level"
+)
+
+// Event is a single record on the event stream. Data depends on Type:
+// PeerPathEvent for peer.path, ConfigAppliedEvent for config.applied and
+// LogLevelRequest for log.level.
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data,omitempty"`
+}
+
+// PeerPathEvent reports a path change for one remote peer.
+type PeerPathEvent struct {
+	AppID string `json:"appId"`
+	infra.PathEvent
+}
+
+// ConfigAppliedEvent reports that a network map was applied.
+type ConfigAppliedEvent struct {
+	ConfigVersion string `json:"configVersion"`
+	Peers         int    `json:"peers"`
+}
@@ -109,6 +109,29 @@ func GetLogger(module string) *Logger {
 	return &Logger{logger}
 }
 
+// CurrentLevel returns the active log level name.
+func CurrentLevel() string {
+	switch level.Level() {
+	case slog.LevelDebug:
+		return "debug"
+	case slog.LevelWarn:
+		return "warning"
+	case slog.LevelError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// ValidLevel reports whether l is a level name understood by SetLevel.
+func ValidLevel(l string) bool {
+	switch strings.ToLower(l) {
+	case "debug", "info", "warn", "warning", "error":
+		return true
+	}
+	return false
+}
+
 func GetLogLevel(level string) slog.Level {
 	level = strings.ToLower(level)
 	switch level {
@@ -118,7 +141,7 @@ func GetLogLevel(level string) slog.Level {
 		return slog.LevelError
 	case "info":
 		return slog.LevelInfo
-	case "warning":
+	case "warn", "warning":
 		return slog.LevelWarn
 	default:
 		return slog.LevelInfo
@@ -17,6 +17,8 @@ package agent
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
+
 	"github.com/alatticeio/lattice/internal/agent/infra"
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"github.com/alatticeio/lattice/internal/agent/provision"
@@ -25,6 +27,8 @@ import (
 type Handler interface {
 	HandleEvent(ctx context.Context, msg *infra.Message) error
 	ApplyFullConfig(ctx context.Context, msg *infra.Message) error
+	// LastApplied returns the most recent message applied successfully.
+	LastApplied() *infra.Message
 }
 
 // event handler for lattice to handle event from management
@@ -32,16 +36,26 @@ type MessageHandler struct {
 	deviceManager infra.NodeInterface
 	logger        *log.Logger
 	provisioner   provision.Provisioner
+
+	applied   atomic.Pointer[infra.Message]
+	onApplied func(msg *infra.Message)
 }
 
-func NewMessageHandler(e infra.NodeInterface, logger *log.Logger, provisioner provision.Provisioner) *MessageHandler {
+// NewMessageHandler creates a MessageHandler. onApplied is optional and is
+// called after every successful ApplyFullConfig.
+func NewMessageHandler(e infra.NodeInterface, logger *log.Logger, provisioner provision.Provisioner, onApplied func(msg *infra.Message)) *MessageHandler {
 	return &MessageHandler{
 		deviceManager: e,
 		logger:        logger,
 		provisioner:   provisioner,
+		onApplied:     onApplied,
 	}
 }
 
+func (h *MessageHandler) LastApplied() *infra.Message {
+	return h.applied.Load()
+}
+
 type HandlerFunc func(ctx context.Context, msg *infra.Message) error
 
 func (h *MessageHandler) HandleEvent(ctx context.Context, msg *infra.Message) error {
@@ -156,6 +170,17 @@ func (h *MessageHandler) ApplyFullConfig(ctx context.Context, msg *infra.Message
 		return err
 	}
 
+	if err = h.applyNetMaps(msg); err != nil {
+		h.logger.Error("failed to apply peering address translation", err)
+		return err
+	}
+
+	h.applyQoS(msg)
+
+	h.applied.Store(msg)
+	if h.onApplied != nil {
+		h.onApplied(msg)
+	}
 	h.logger.Debug("full config reconciled", "version", msg.ConfigVersion)
 	return nil
 }
@@ -170,6 +195,23 @@ func (h *MessageHandler) applyRemotePeers(ctx context.Context, msg *infra.Messag
 	return nil
 }
 
+// applyNetMaps installs the address translations of the peerings this node
+// is the gateway for; other nodes carry none, which clears stale ones.
+func (h *MessageHandler) applyNetMaps(msg *infra.Message) error {
+	if msg.Current == nil {
+		return nil
+	}
+	return h.provisioner.ApplyNetMaps(h.deviceManager.GetDeviceName(), msg.Current.NetMaps)
+}
+
+// applyQoS installs the node's bandwidth limits and QoS classes. Shaping is
+// best effort: a host without tc keeps working, only unshaped.
+func (h *MessageHandler) applyQoS(msg *infra.Message) {
+	if err := h.provisioner.ApplyQoS(h.deviceManager.GetDeviceName(), msg.ComputedQoS); err != nil {
+		h.logger.Warn("failed to apply bandwidth limits", "err", err)
+	}
+}
+
 func (h *MessageHandler) applyFirewallRules(ctx context.Context, msg *infra.Message) error {
 	if msg.ComputedRules == nil {
 		return nil
@@ -20,9 +20,11 @@ package agent
 import (
 	"context"
 	"fmt"
+	"github.com/alatticeio/lattice/api/v1alpha1"
 	"github.com/alatticeio/lattice/internal"
 	"github.com/alatticeio/lattice/internal/agent/config"
 	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/localapi"
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"github.com/alatticeio/lattice/internal/agent/provision"
 	"github.com/alatticeio/lattice/internal/agent/wireguard"
@@ -53,6 +55,8 @@ type Node struct {
 	bind        *infra.DefaultBind
 	provisioner provision.Provisioner
 	natsService infra.SignalService
+	// flowLog is set when the policy enforcer records flows (eBPF).
+	flowLog provision.FlowLogSource
 
 	// GetNetworkMap is set externally after NewAgent returns and before Start
 	// is called. It fetches the current network topology from the control plane.
@@ -68,12 +72,26 @@ type Node struct {
 
 	current    *infra.Peer
 	wrrpClient infra.Wrrp
+	// relays is set when the relay is not pinned by flags; it picks among
+	// the relays offered in the network map.
+	relays *relay.Selector
 
 	token          string
 	callback       func(message *infra.Message) error // nolint
 	messageHandler Handler
 
 	DeviceManager *wireguard.DeviceManager
+
+	// events carries agent events to local API subscribers.
+	events *localapi.EventBus
+
+	// OnShutdown is set externally after NewNode returns. It is invoked when
+	// a graceful shutdown is requested through the local API.
+	OnShutdown func()
+
+	// OnConfigApplied is set externally after NewNode returns. It is invoked
+	// after every network map the node applies.
+	OnConfigApplied func(msg *infra.Message)
 }
 
 // NodeConfig holds the startup parameters for NewNode.
@@ -127,6 +145,7 @@ func NewNode(ctx context.Context, cfg *NodeConfig) (*Node, error) {
 	node.manager.peerManager = infra.NewPeerManager()
 	node.logger = cfg.Logger
 	node.manager.turnManager = new(internal.TurnManager)
+	node.events = localapi.NewEventBus()
 
 	// TUN device: the OS virtual NIC that serves as WireGuard's L3 ingress/egress.
 	node.Name, iface, err = infra.CreateTUN(infra.DefaultMTU, cfg.Logger)
@@ -239,6 +258,10 @@ func NewNode(ctx context.Context, cfg *NodeConfig) (*Node, error) {
 		GetWrrp: func() infra.Wrrp {
 			return wrrp
 		},
+		OnPathChange: func(appId string, ev infra.PathEvent) {
+			node.events.Publish(localapi.EventPeerPath, localapi.PeerPathEvent{AppID: appId, PathEvent: ev})
+		},
+		GetHandshakes: node.handshakes,
 	})
 
 	// Subscribe to this node's NATS signaling subject. All incoming ICE and
@@ -248,21 +271,21 @@ func NewNode(ctx context.Context, cfg *NodeConfig) (*Node, error) {
 	}
 
 	// WRRP is an optional relay channel used as a fallback when ICE traversal
-	// fails (e.g. symmetric NAT on both sides).
+	// fails (e.g. symmetric NAT on both sides). A relay given by flag is used
+	// as-is; otherwise the Selector chooses among the relays the control plane
+	// offers in the network map and fails over between them.
 	if cfg.Flags.EnableWrrp {
-		if cfg.Flags.RelayQuicURL != "" {
+		// probeFactory.Handle is passed directly: probeFactory already exists
+		// at this point so no closure is needed on this side of the circular dep.
+		switch {
+		case cfg.Flags.RelayQuicURL != "":
 			wrrp, err = relay.NewQUICClient(ctx, localIdentity.ID(), cfg.Flags.RelayQuicURL, node.probeFactory.Handle)
-		} else {
-			wrrpUrl := cfg.Flags.RelayURL
-			if wrrpUrl == "" {
-				wrrpUrl = node.current.WrrpUrl
-			}
-
-			if wrrpUrl != "" {
-				// probeFactory.Handle is passed directly: probeFactory already exists
-				// at this point so no closure is needed on this side of the circular dep.
-				wrrp, err = relay.NewTCPClient(ctx, localIdentity.ID(), wrrpUrl, node.probeFactory.Handle)
-			}
+		case pinnedRelayURL(cfg.Flags.RelayURL) != "":
+			wrrp, err = relay.NewTCPClient(ctx, localIdentity.ID(), cfg.Flags.RelayURL, node.probeFactory.Handle)
+		default:
+			node.relays = relay.NewSelector(ctx, localIdentity.ID(), node.probeFactory.Handle)
+			go node.relays.Run()
+			wrrp = node.relays
 		}
 		if err != nil {
 			return nil, err
@@ -305,6 +328,7 @@ func NewNode(ctx context.Context, cfg *NodeConfig) (*Node, error) {
 	default:
 		policyEnforcer = provision.NewIptablesEnforcer(cfg.Logger, node.Name)
 	}
+	node.flowLog, _ = policyEnforcer.(provision.FlowLogSource)
 	node.provisioner = provision.NewProvisioner(
 		provision.NewRouteProvisioner(cfg.Logger),
 		policyEnforcer,
@@ -315,7 +339,20 @@ func NewNode(ctx context.Context, cfg *NodeConfig) (*Node, error) {
 
 	// MessageHandler processes topology change events pushed by the control plane
 	// (peers added/removed, configuration updates) and applies them via Provisioner.
-	node.messageHandler = NewMessageHandler(node, log.GetLogger("event-handler"), node.provisioner)
+	node.messageHandler = NewMessageHandler(node, log.GetLogger("event-handler"), node.provisioner, func(msg *infra.Message) {
+		if node.relays != nil {
+			node.relays.SetTicket(msg.RelayTicket)
+			node.relays.SetRegion(msg.Current.Labels[v1alpha1.RelayRegionLabel])
+			node.relays.Update(msg.Relays)
+		}
+		node.events.Publish(localapi.EventConfigApplied, localapi.ConfigAppliedEvent{
+			ConfigVersion: msg.ConfigVersion,
+			Peers:         len(msg.ComputedPeers),
+		})
+		if node.OnConfigApplied != nil {
+			node.OnConfigApplied(msg)
+		}
+	})
 
 	node.DeviceManager = wireguard.NewDeviceManager(log.GetLogger("device-manager"), node.iface, make(chan struct{}))
 	node.token = cfg.Token
@@ -375,6 +412,10 @@ func (c *Node) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Keep relayed peers re-probing for a direct path and move stalled
+	// direct peers back to the relay.
+	go c.probeFactory.RunPathMonitor(ctx)
+
 	return c.messageHandler.ApplyFullConfig(ctx, remoteCfg)
 }
 
@@ -469,3 +510,23 @@ func (c *Node) GetDeviceName() string {
 func (c *Node) GetPeerManager() *infra.PeerManager {
 	return c.manager.peerManager
 }
+
+// PeerPaths reports how each remote peer is currently reached: direct or
+// relayed, the selected ICE candidate pair, RTT and recent path changes.
+func (c *Node) PeerPaths() []infra.PeerPath {
+	if c.probeFactory == nil {
+		return nil
+	}
+	return c.probeFactory.Paths()
+}
+
+// pinnedRelayURL returns u when it names a relay host. The shared config
+// default ":6266" is the relay server's listen address and has no host, so
+// it does not pin the agent to a relay.
+func pinnedRelayURL(u string) string {
+	host, _, err := net.SplitHostPort(u)
+	if err != nil || host == "" {
+		return ""
+	}
+	return u
+}
@@ -19,14 +19,23 @@ import (
 	"github.com/alatticeio/lattice/internal/agent/log"
 )
 
-// NewEBPFEnforcer creates an eBPF-based PolicyEnforcer.
-// In community builds this falls back to iptables since the eBPF manager
-// is a stub that always returns errors.
+// NewEBPFEnforcer creates an eBPF-based PolicyEnforcer. It falls back to
+// iptables when the programs cannot be loaded or attached.
 func NewEBPFEnforcer(iface string, logger *log.Logger) PolicyEnforcer {
 	mgr := ebpf.NewManager(iface, logger)
 	if err := mgr.Load(); err != nil {
 		logger.Warn("eBPF load failed, falling back to iptables", "err", err)
 		return NewIptablesEnforcer(logger, iface)
 	}
-	return mgr
+	return &ebpfEnforcer{Manager: mgr, nat: NewIptablesEnforcer(logger, iface)}
+}
+
+// ebpfEnforcer filters with eBPF and leaves container NAT to iptables.
+type ebpfEnforcer struct {
+	*ebpf.Manager
+	nat PolicyEnforcer
+}
+
+func (e *ebpfEnforcer) SetupNAT(interfaceName string) error {
+	return e.nat.SetupNAT(interfaceName)
 }
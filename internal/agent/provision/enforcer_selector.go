@@ -39,8 +39,8 @@ func (m EnforcerMode) String() string {
 }
 
 // SelectEnforcerMode decides which PolicyEnforcer backend to use.
-// In community builds this always returns ModeIPTables.
-// In pro builds it checks kernel BPF capability and license validity.
+// On Linux it picks eBPF when the kernel can attach TC programs through TCX;
+// everywhere else it returns ModeIPTables.
 func SelectEnforcerMode(logger *log.Logger) EnforcerMode {
 	mode := selectEBPFAvailable()
 	if mode == ModeEBPF {
@@ -12,22 +12,18 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-//go:build pro
+//go:build linux
 
 package provision
 
 import (
-	"github.com/cilium/ebpf"
-	"github.com/cilium/ebpf/rlimit"
+	"github.com/alatticeio/lattice/internal/agent/ebpf"
 )
 
 func selectEBPFAvailable() EnforcerMode {
-	// Remove memlock rlimit (required for eBPF).
-	if err := rlimit.RemoveMemlock(); err != nil {
-		return ModeIPTables
-	}
-	// Probe basic eBPF support.
-	if ok, _ := ebpf.HaveProgramType(ebpf.SchedCLS); !ok {
+	// The enforcer attaches through TCX; without it NewEBPFEnforcer would
+	// only fall back to iptables.
+	if err := ebpf.HaveTCX(); err != nil {
 		return ModeIPTables
 	}
 	return ModeEBPF
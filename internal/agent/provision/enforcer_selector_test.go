@@ -12,21 +12,24 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-//go:build !pro
-
 package provision
 
 import (
 	"testing"
 
+	"github.com/alatticeio/lattice/internal/agent/ebpf"
 	"github.com/alatticeio/lattice/internal/agent/log"
 )
 
-func TestSelectEnforcerMode_Community(t *testing.T) {
+func TestSelectEnforcerMode(t *testing.T) {
 	logger := log.GetLogger("test")
 	mode := SelectEnforcerMode(logger)
-	if mode != ModeIPTables {
-		t.Errorf("expected ModeIPTables in community build, got %v", mode)
+	want := ModeIPTables
+	if ebpf.HaveTCX() == nil {
+		want = ModeEBPF
+	}
+	if mode != want {
+		t.Errorf("expected %v, got %v", want, mode)
 	}
 }
 
@@ -36,6 +39,7 @@ func TestEnforcerMode_String(t *testing.T) {
 		want string
 	}{
 		{ModeIPTables, "iptables"},
+		{ModeEBPF, "ebpf"},
 		{ModeUnset, "unknown"},
 	}
 	for _, tt := range tests {
@@ -0,0 +1,107 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provision
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+// Peering translation on Linux: every NetMap becomes two tc flower filters
+// on the WireGuard interface's clsact qdisc. On ingress, traffic from the
+// remote range to the virtual range gets the local network's prefix as its
+// destination; on egress, traffic from the local network to the remote
+// range gets the virtual prefix as its source. pedit overwrites only the
+// prefix bits of the address and csum fixes the checksums after it.
+//
+// Each packet is translated on its own, without conntrack: flows that
+// existed before the filters were rebuilt are translated like new ones,
+// and replies need no state from the packet they answer.
+const (
+	// netmapPref is the priority of the translation filters, so they can
+	// be flushed without touching other filters of the interface.
+	netmapPref = 100
+
+	// Offsets of the source and destination addresses in the IP headers.
+	ip4SrcOffset = 12
+	ip4DstOffset = 16
+	ip6SrcOffset = 8
+	ip6DstOffset = 24
+)
+
+// netmapResetCommands remove the translation filters of interface name and
+// leave its clsact qdisc in place.
+func netmapResetCommands(name string) []string {
+	return []string{
+		fmt.Sprintf("tc qdisc add dev %s clsact 2>/dev/null || true", name),
+		fmt.Sprintf("tc filter del dev %s ingress pref %d 2>/dev/null || true", name, netmapPref),
+		fmt.Sprintf("tc filter del dev %s egress pref %d 2>/dev/null || true", name, netmapPref),
+	}
+}
+
+// netmapCommands install the filters of m on interface name after
+// netmapResetCommands.
+func netmapCommands(name string, m infra.NetMap) ([]string, error) {
+	local, err := netip.ParsePrefix(m.Local)
+	if err != nil {
+		return nil, err
+	}
+	virtual, err := netip.ParsePrefix(m.Virtual)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := netip.ParsePrefix(m.Remote)
+	if err != nil {
+		return nil, err
+	}
+	is4 := local.Addr().Is4()
+	if virtual.Addr().Is4() != is4 || remote.Addr().Is4() != is4 {
+		return nil, fmt.Errorf("netmap %s -> %s: mixed address families", m.Local, m.Virtual)
+	}
+	if local.Bits() != virtual.Bits() || local.Bits() == 0 {
+		return nil, fmt.Errorf("netmap %s -> %s: prefix lengths differ", m.Local, m.Virtual)
+	}
+
+	proto, csum, srcOffset, dstOffset := "ip", "ip4h and tcp and udp", ip4SrcOffset, ip4DstOffset
+	if !is4 {
+		// IPv6 has no header checksum, but ICMPv6 covers the addresses.
+		proto, csum, srcOffset, dstOffset = "ipv6", "tcp and udp and icmp", ip6SrcOffset, ip6DstOffset
+	}
+	filter := func(dir string, src, dst netip.Prefix, offset int, to netip.Prefix) string {
+		return fmt.Sprintf("tc filter add dev %s %s pref %d protocol %s flower src_ip %s dst_ip %s action pedit %s pipe action csum %s",
+			name, dir, netmapPref, proto, src.Masked(), dst.Masked(), prefixKeys(offset, to), csum)
+	}
+	return []string{
+		filter("ingress", remote, virtual, dstOffset, local),
+		filter("egress", local, remote, srcOffset, virtual),
+	}, nil
+}
+
+// prefixKeys returns the pedit keys that overwrite the prefix bits of the
+// address at offset of the IP header with those of p, 32 bits at a time.
+func prefixKeys(offset int, p netip.Prefix) string {
+	addr := p.Masked().Addr().AsSlice()
+	var keys []string
+	for i := 0; i < len(addr) && p.Bits() > i*8; i += 4 {
+		bits := min(p.Bits()-i*8, 32)
+		keys = append(keys, fmt.Sprintf("munge offset %d u32 set 0x%08x retain 0x%08x",
+			offset+i, binary.BigEndian.Uint32(addr[i:]), ^uint32(0)<<(32-bits)))
+	}
+	return strings.Join(keys, " ")
+}
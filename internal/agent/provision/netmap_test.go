@@ -0,0 +1,74 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provision
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+func TestNetMapCommands(t *testing.T) {
+	got, err := netmapCommands("wg0", infra.NetMap{Local: "10.10.1.0/24", Virtual: "100.64.2.0/24", Remote: "100.64.1.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"tc filter add dev wg0 ingress pref 100 protocol ip flower src_ip 100.64.1.0/24 dst_ip 100.64.2.0/24 " +
+			"action pedit munge offset 16 u32 set 0x0a0a0100 retain 0xffffff00 pipe action csum ip4h and tcp and udp",
+		"tc filter add dev wg0 egress pref 100 protocol ip flower src_ip 10.10.1.0/24 dst_ip 100.64.1.0/24 " +
+			"action pedit munge offset 12 u32 set 0x64400200 retain 0xffffff00 pipe action csum ip4h and tcp and udp",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, cmd := range got {
+		if strings.Contains(cmd, "conntrack") || strings.Contains(cmd, "NETMAP") {
+			t.Errorf("translation must be stateless: %s", cmd)
+		}
+	}
+}
+
+func TestNetMapCommands_IPv6(t *testing.T) {
+	got, err := netmapCommands("wg0", infra.NetMap{Local: "fd00:1::/56", Virtual: "fd64:2::/56", Remote: "fd64:1::/56"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := strings.Join(got, "\n")
+	for _, want := range []string{
+		"protocol ipv6 flower src_ip fd64:1::/56 dst_ip fd64:2::/56 action pedit " +
+			"munge offset 24 u32 set 0xfd000001 retain 0xffffffff munge offset 28 u32 set 0x00000000 retain 0xffffff00 pipe",
+		"protocol ipv6 flower src_ip fd00:1::/56 dst_ip fd64:1::/56 action pedit " +
+			"munge offset 8 u32 set 0xfd640002 retain 0xffffffff munge offset 12 u32 set 0x00000000 retain 0xffffff00 pipe",
+		"action csum tcp and udp and icmp",
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("missing %q in\n%s", want, all)
+		}
+	}
+}
+
+func TestNetMapCommands_Invalid(t *testing.T) {
+	for _, m := range []infra.NetMap{
+		{Local: "10.10.1.0/24", Virtual: "100.64.2.0/23", Remote: "100.64.1.0/24"},
+		{Local: "10.10.1.0/24", Virtual: "fd64:2::/24", Remote: "100.64.1.0/24"},
+		{Local: "10.10.1.0", Virtual: "100.64.2.0/24", Remote: "100.64.1.0/24"},
+	} {
+		if _, err := netmapCommands("wg0", m); err == nil {
+			t.Errorf("netmap %+v accepted", m)
+		}
+	}
+}
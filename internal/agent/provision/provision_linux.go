@@ -21,6 +21,7 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"reflect"
 	"strings"
 	"sync"
 )
@@ -60,6 +61,77 @@ func (r *routeProvisioner) ApplyRoute(action, address, name string) error {
 	return nil
 }
 
+// ApplyNetMaps installs the 1:1 prefix translations of a peering gateway
+// with stateless tc filters; see netmapCommands. The filters are rebuilt on
+// every call, and routes to remote ranges no longer mapped are removed.
+func (r *routeProvisioner) ApplyNetMaps(name string, maps []infra.NetMap) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(maps) == 0 && len(r.netmaps) == 0 {
+		return nil
+	}
+
+	for _, cmd := range netmapResetCommands(name) {
+		if err := infra.ExecCommand("/bin/sh", "-c", cmd); err != nil {
+			return fmt.Errorf("prepare netmap filters: %w", err)
+		}
+	}
+
+	want := make(map[string]struct{}, len(maps))
+	for _, m := range maps {
+		cmds, err := netmapCommands(name, m)
+		if err != nil {
+			return fmt.Errorf("apply netmap %s -> %s: %w", m.Local, m.Virtual, err)
+		}
+		cmds = append(cmds, fmt.Sprintf("ip route replace %s dev %s", m.Remote, name))
+		for _, cmd := range cmds {
+			if err = infra.ExecCommand("/bin/sh", "-c", cmd); err != nil {
+				return fmt.Errorf("apply netmap %s -> %s: %w", m.Local, m.Virtual, err)
+			}
+		}
+		want[m.Remote] = struct{}{}
+		r.logger.Debug("apply netmap", "local", m.Local, "virtual", m.Virtual, "remote", m.Remote, "dev", name)
+	}
+
+	for _, m := range r.netmaps {
+		if _, ok := want[m.Remote]; !ok {
+			_ = infra.ExecCommand("/bin/sh", "-c", fmt.Sprintf("ip route del %s dev %s 2>/dev/null || true", m.Remote, name))
+		}
+	}
+	r.netmaps = maps
+	return nil
+}
+
+// ApplyQoS installs the traffic shaping of qos on interface name with tc
+// and iptables mangle rules; see qosCommands. The previous shaping is
+// removed first, so the call is skipped when qos has not changed.
+func (r *routeProvisioner) ApplyQoS(name string, qos *infra.QoS) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if reflect.DeepEqual(qos, r.qos) || (qos.Empty() && r.qos.Empty()) {
+		return nil
+	}
+	cmds := qosResetCommands(name)
+	if !qos.Empty() {
+		cmds = append(cmds, qosCommands(name, qos)...)
+	}
+	for _, cmd := range cmds {
+		if err := infra.ExecCommand("/bin/sh", "-c", cmd); err != nil {
+			// Leave no half-built tree behind; the next call starts over.
+			for _, reset := range qosResetCommands(name) {
+				_ = infra.ExecCommand("/bin/sh", "-c", reset)
+			}
+			r.qos = nil
+			return fmt.Errorf("apply qos: %s: %w", cmd, err)
+		}
+	}
+	r.qos = qos
+	r.logger.Debug("apply qos", "dev", name, "egressMbps", qos.EgressMbps, "ingressMbps", qos.IngressMbps)
+	return nil
+}
+
 func (r *routeProvisioner) ApplyIP(action, address, name string) error {
 	switch action {
 	case "add":
@@ -178,7 +250,11 @@ func (p *ruleProvisioner) addRule(chain, dir, ip string, tr infra.TrafficRule) e
 	}
 	var args []string
 	if tr.Protocol != "" && tr.Port != 0 {
-		args = []string{"-A", chain, dir, ip, "-p", strings.ToLower(tr.Protocol), "--dport", fmt.Sprintf("%d", tr.Port), "-j", target}
+		dport := fmt.Sprintf("%d", tr.Port)
+		if tr.EndPort > tr.Port {
+			dport = fmt.Sprintf("%d:%d", tr.Port, tr.EndPort)
+		}
+		args = []string{"-A", chain, dir, ip, "-p", strings.ToLower(tr.Protocol), "--dport", dport, "-j", target}
 	} else {
 		args = []string{"-A", chain, dir, ip, "-j", target}
 	}
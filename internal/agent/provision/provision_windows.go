@@ -32,6 +32,23 @@ func (r *routeProvisioner) ApplyRoute(action, address, interfaceName string) err
 	return nil
 }
 
+// ApplyNetMaps is not supported on Windows: peering gateways that translate
+// addresses must run on Linux.
+func (r *routeProvisioner) ApplyNetMaps(name string, maps []infra.NetMap) error {
+	if len(maps) > 0 {
+		r.logger.Warn("peering address translation requires a linux gateway, ignoring", "maps", len(maps))
+	}
+	return nil
+}
+
+// ApplyQoS is not supported on Windows: traffic shaping requires Linux.
+func (r *routeProvisioner) ApplyQoS(name string, qos *infra.QoS) error {
+	if !qos.Empty() {
+		r.logger.Warn("traffic shaping requires linux, ignoring", "dev", name)
+	}
+	return nil
+}
+
 func (r *routeProvisioner) ApplyIP(action, address, name string) error {
 	switch action {
 	case "add":
@@ -54,6 +54,12 @@ type Provisioner interface {
 type RouteProvisioner interface {
 	ApplyRoute(action, address, name string) error
 	ApplyIP(action, address, name string) error
+	// ApplyNetMaps replaces the 1:1 address translations a peering gateway
+	// applies on interface name. An empty maps removes them all.
+	ApplyNetMaps(name string, maps []infra.NetMap) error
+	// ApplyQoS replaces the traffic shaping on interface name. A nil qos
+	// removes it.
+	ApplyQoS(name string, qos *infra.QoS) error
 }
 
 type PolicyEnforcer interface {
@@ -70,6 +76,12 @@ type PolicyEnforcer interface {
 	SetupNAT(interfaceName string) error
 }
 
+// FlowLogSource is implemented by enforcers that record the verdict of
+// every flow. FlowRecords returns the flows seen since the previous call.
+type FlowLogSource interface {
+	FlowRecords() ([]infra.FlowRecord, error)
+}
+
 const (
 	PersistentKeepalive int = 25
 )
@@ -181,6 +193,14 @@ type routeProvisioner struct {
 	// to add it, and the second one fails with xtables lock error (exit status 1).
 	mu     sync.Mutex //nolint:unused
 	logger *log.Logger
+
+	// netmaps holds the translations last applied by ApplyNetMaps, so routes
+	// of dropped peerings can be removed.
+	netmaps []infra.NetMap //nolint:unused
+
+	// qos is the shaping last applied by ApplyQoS, so unchanged configs are
+	// not reinstalled.
+	qos *infra.QoS //nolint:unused
 }
 
 func NewRouteProvisioner(logger *log.Logger) RouteProvisioner {
@@ -0,0 +1,187 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provision
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+// Traffic shaping on Linux: egress traffic of the WireGuard interface goes
+// through an HTB tree with one fq_codel class per QoS class, plus one capped
+// class per rate-limited egress rule. Packets are sorted into the classes,
+// and marked with their class's DSCP value, by an iptables mangle chain.
+// Ingress traffic cannot be queued, so ingress limits drop what arrives
+// above the rate with hashlimit.
+//
+//	1:      htb root, default = class of the peer
+//	└─ 1:1  rate = egress limit
+//	   ├─ 1:10  interactive  prio 0
+//	   ├─ 1:20  standard     prio 1
+//	   ├─ 1:30  bulk         prio 2
+//	   └─ 1:100+i  egress rule i, capped at its rate
+const (
+	qosOutChain = "LATTICE-QOS-OUT"
+	qosInChain  = "LATTICE-QOS-IN"
+
+	// qosLinkMbps is the HTB rate of an interface without an egress limit.
+	qosLinkMbps = 10000
+	// qosRuleClass is the HTB minor id of the first rate-limited rule.
+	qosRuleClass = 0x100
+)
+
+// qosClasses lists the HTB class, priority and share of the link each QoS
+// class is guaranteed.
+var qosClasses = []struct {
+	name    string
+	minor   int
+	prio    int
+	percent int
+}{
+	{infra.QoSInteractive, 0x10, 0, 30},
+	{infra.QoSStandard, 0x20, 1, 50},
+	{infra.QoSBulk, 0x30, 2, 20},
+}
+
+// qosClass returns the HTB minor id and priority of a QoS class; unknown
+// and empty classes are standard.
+func qosClass(name string) (minor, prio int) {
+	for _, c := range qosClasses {
+		if c.name == name {
+			return c.minor, c.prio
+		}
+	}
+	return 0x20, 1
+}
+
+// qosBurstKB defaults the burst of a rate to 100 ms of traffic.
+func qosBurstKB(mbps, burstKB int) int {
+	if burstKB > 0 {
+		return burstKB
+	}
+	return max(mbps*1000/8/10, 16)
+}
+
+// qosResetCommands remove the shaping of interface name and leave empty,
+// hooked QoS chains behind.
+func qosResetCommands(name string) []string {
+	return []string{
+		fmt.Sprintf("tc qdisc del dev %s root 2>/dev/null || true", name),
+		fmt.Sprintf("iptables -w 5 -t mangle -N %[1]s 2>/dev/null; iptables -w 5 -t mangle -F %[1]s", qosOutChain),
+		fmt.Sprintf("iptables -w 5 -t mangle -N %[1]s 2>/dev/null; iptables -w 5 -t mangle -F %[1]s", qosInChain),
+		fmt.Sprintf("iptables -w 5 -t mangle -C POSTROUTING -o %[1]s -j %[2]s 2>/dev/null || iptables -w 5 -t mangle -A POSTROUTING -o %[1]s -j %[2]s", name, qosOutChain),
+		fmt.Sprintf("iptables -w 5 -t mangle -C PREROUTING -i %[1]s -j %[2]s 2>/dev/null || iptables -w 5 -t mangle -A PREROUTING -i %[1]s -j %[2]s", name, qosInChain),
+	}
+}
+
+// qosCommands install q on interface name after qosResetCommands.
+func qosCommands(name string, q *infra.QoS) []string {
+	var cmds []string
+	tc := func(format string, a ...any) { cmds = append(cmds, "tc "+fmt.Sprintf(format, a...)) }
+	ipt := func(chain, args string) {
+		cmds = append(cmds, fmt.Sprintf("iptables -w 5 -t mangle -A %s %s", chain, args))
+	}
+
+	// Egress: HTB tree.
+	link, linkBurst := qosLinkMbps, ""
+	if q.EgressMbps > 0 {
+		link = q.EgressMbps
+		linkBurst = fmt.Sprintf(" burst %dkb", qosBurstKB(q.EgressMbps, q.BurstKB))
+	}
+	defMinor, _ := qosClass(q.Class)
+	tc("qdisc add dev %s root handle 1: htb default %x", name, defMinor)
+	tc("class add dev %s parent 1: classid 1:1 htb rate %dmbit ceil %dmbit%s", name, link, link, linkBurst)
+	for _, c := range qosClasses {
+		tc("class add dev %s parent 1:1 classid 1:%x htb rate %dmbit ceil %dmbit prio %d",
+			name, c.minor, max(link*c.percent/100, 1), link, c.prio)
+		tc("qdisc add dev %s parent 1:%x fq_codel", name, c.minor)
+	}
+	for i, r := range q.Egress {
+		if r.RateMbps <= 0 {
+			continue
+		}
+		_, prio := qosClass(r.Class)
+		rate := min(r.RateMbps, link)
+		tc("class add dev %s parent 1:1 classid 1:%x htb rate %dmbit ceil %dmbit burst %dkb prio %d",
+			name, qosRuleClass+i, rate, rate, qosBurstKB(rate, r.BurstKB), prio)
+		tc("qdisc add dev %s parent 1:%x fq_codel", name, qosRuleClass+i)
+	}
+
+	// Egress: classification. Unmarked packets take the peer's class,
+	// marked ones are queued by their DSCP value, and rules override both.
+	if q.Class != "" && infra.DSCP(q.Class) != 0 {
+		ipt(qosOutChain, fmt.Sprintf("-m dscp --dscp 0 -j DSCP --set-dscp %d", infra.DSCP(q.Class)))
+	}
+	for _, c := range qosClasses {
+		if c.name != infra.QoSStandard {
+			ipt(qosOutChain, fmt.Sprintf("-m dscp --dscp %d -j CLASSIFY --set-class 1:%x", infra.DSCP(c.name), c.minor))
+		}
+	}
+	for i, r := range q.Egress {
+		for _, peer := range r.Peers {
+			match := qosMatch("-d", peer, r)
+			if r.Class != "" {
+				ipt(qosOutChain, fmt.Sprintf("%s -j DSCP --set-dscp %d", match, infra.DSCP(r.Class)))
+			}
+			switch {
+			case r.RateMbps > 0:
+				ipt(qosOutChain, fmt.Sprintf("%s -j CLASSIFY --set-class 1:%x", match, qosRuleClass+i))
+			case r.Class != "":
+				minor, _ := qosClass(r.Class)
+				ipt(qosOutChain, fmt.Sprintf("%s -j CLASSIFY --set-class 1:%x", match, minor))
+			}
+		}
+	}
+
+	// Ingress: marking and policing. Rules with the same hashlimit name
+	// share one bucket, so a rule is limited as a whole.
+	for i, r := range q.Ingress {
+		for _, peer := range r.Peers {
+			match := qosMatch("-s", peer, r)
+			if r.Class != "" {
+				ipt(qosInChain, fmt.Sprintf("%s -j DSCP --set-dscp %d", match, infra.DSCP(r.Class)))
+			}
+			if r.RateMbps > 0 {
+				ipt(qosInChain, fmt.Sprintf("%s %s -j DROP", match,
+					qosPolice(fmt.Sprintf("lq-in-%d", i), r.RateMbps, r.BurstKB)))
+			}
+		}
+	}
+	if q.IngressMbps > 0 {
+		ipt(qosInChain, qosPolice("lq-in", q.IngressMbps, q.BurstKB)+" -j DROP")
+	}
+	return cmds
+}
+
+// qosMatch matches traffic exchanged with peer; dir is "-s" or "-d".
+func qosMatch(dir, peer string, r infra.QoSRule) string {
+	args := []string{dir, peer}
+	if r.Protocol != "" && r.Port != 0 {
+		dport := fmt.Sprintf("%d", r.Port)
+		if r.EndPort > r.Port {
+			dport = fmt.Sprintf("%d:%d", r.Port, r.EndPort)
+		}
+		args = append(args, "-p", strings.ToLower(r.Protocol), "--dport", dport)
+	}
+	return strings.Join(args, " ")
+}
+
+// qosPolice matches the traffic above mbps.
+func qosPolice(name string, mbps, burstKB int) string {
+	return fmt.Sprintf("-m hashlimit --hashlimit-name %s --hashlimit-above %dkb/s --hashlimit-burst %dkb",
+		name, max(infra.BytesPerSecond(mbps)/1024, 1), qosBurstKB(mbps, burstKB))
+}
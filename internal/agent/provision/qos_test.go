@@ -0,0 +1,68 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provision
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+func TestQoSCommands(t *testing.T) {
+	q := &infra.QoS{
+		EgressMbps:  100,
+		IngressMbps: 40,
+		Class:       infra.QoSBulk,
+		Egress: []infra.QoSRule{
+			{Peers: []string{"10.0.0.2"}, Class: infra.QoSInteractive, Protocol: "udp", Port: 5060, EndPort: 5061},
+			{Peers: []string{"10.0.0.3", "10.0.0.4"}, RateMbps: 20},
+		},
+		Ingress: []infra.QoSRule{
+			{Peers: []string{"10.0.0.5"}, Protocol: "TCP", Port: 873, RateMbps: 8, BurstKB: 256},
+		},
+	}
+	got := strings.Join(qosCommands("wg0", q), "\n")
+
+	for _, want := range []string{
+		"tc qdisc add dev wg0 root handle 1: htb default 30",
+		"tc class add dev wg0 parent 1: classid 1:1 htb rate 100mbit ceil 100mbit burst 1250kb",
+		"tc class add dev wg0 parent 1:1 classid 1:10 htb rate 30mbit ceil 100mbit prio 0",
+		"tc class add dev wg0 parent 1:1 classid 1:101 htb rate 20mbit ceil 20mbit burst 250kb prio 1",
+		"-A LATTICE-QOS-OUT -m dscp --dscp 0 -j DSCP --set-dscp 8",
+		"-A LATTICE-QOS-OUT -d 10.0.0.2 -p udp --dport 5060:5061 -j DSCP --set-dscp 46",
+		"-A LATTICE-QOS-OUT -d 10.0.0.2 -p udp --dport 5060:5061 -j CLASSIFY --set-class 1:10",
+		"-A LATTICE-QOS-OUT -d 10.0.0.4 -j CLASSIFY --set-class 1:101",
+		"-A LATTICE-QOS-IN -s 10.0.0.5 -p tcp --dport 873 -m hashlimit --hashlimit-name lq-in-0 --hashlimit-above 976kb/s --hashlimit-burst 256kb -j DROP",
+		"-A LATTICE-QOS-IN -m hashlimit --hashlimit-name lq-in --hashlimit-above 4882kb/s --hashlimit-burst 500kb -j DROP",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "classid 1:100 ") {
+		t.Error("rule without a rate must not get a capped class")
+	}
+}
+
+func TestQoSCommands_Unlimited(t *testing.T) {
+	got := strings.Join(qosCommands("wg0", &infra.QoS{Class: infra.QoSInteractive}), "\n")
+	if !strings.Contains(got, "htb default 10") || !strings.Contains(got, "classid 1:1 htb rate 10000mbit ceil 10000mbit\n") {
+		t.Errorf("unexpected tree:\n%s", got)
+	}
+	if strings.Contains(got, "hashlimit") {
+		t.Errorf("no ingress limit expected:\n%s", got)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"github.com/alatticeio/lattice/internal/agent/config"
 	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/localapi"
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"github.com/alatticeio/lattice/internal/agent/wireguard"
 	"github.com/alatticeio/lattice/internal/dns"
@@ -67,11 +68,16 @@ func Start(ctx context.Context, flags *config.Config) error {
 		defer os.Remove(pidPath)
 	}
 
+	// shutdown lets the local API stop the agent the same way SIGTERM does.
+	ctx, shutdown := context.WithCancel(ctx)
+	defer shutdown()
+
 	g, gCtx := errgroup.WithContext(ctx)
 
+	var nativeDNS *dns.LinkDNS
 	if flags.EnableDNS {
+		nativeDNS = dns.NewNativeDNS(&dns.DNSConfig{})
 		go func() {
-			nativeDNS := dns.NewNativeDNS(&dns.DNSConfig{})
 			if err := nativeDNS.Start(); err != nil {
 				logger.Error("DNS start failed", err)
 			}
@@ -83,6 +89,12 @@ func Start(ctx context.Context, flags *config.Config) error {
 		return err
 	}
 
+	c.OnShutdown = shutdown
+	if nativeDNS != nil {
+		c.OnConfigApplied = func(msg *infra.Message) {
+			nativeDNS.SetRecords(dnsRecords(msg))
+		}
+	}
 	c.GetNetworkMap = func() (*infra.Message, error) {
 		msg, err := c.ctrClient.GetNetMap(flags.Token)
 		if err != nil {
@@ -98,14 +110,26 @@ func Start(ctx context.Context, flags *config.Config) error {
 
 	// Start heartbeat so the management server can track online status.
 	go c.StartHeartbeat(gCtx)
+	go c.StartFlowLog(gCtx, time.Duration(flags.FlowLogInterval)*time.Second)
 
 	logger.Debug("Interface name", "name", c.Name)
 
-	if flags.EnableMetric && flags.Telemetry.VMEndpoint != "" {
+	viaNATS := flags.Telemetry.Transport == config.TelemetryTransportNATS
+	if flags.EnableMetric && (flags.Telemetry.VMEndpoint != "" || viaNATS) {
 		tc := telemetry.Config{
 			VMEndpoint: flags.Telemetry.VMEndpoint,
 			Interval:   time.Duration(flags.Telemetry.IntervalSeconds) * time.Second,
 		}
+		if viaNATS {
+			tc.Sink = telemetry.SinkFunc(c.sendTelemetry)
+		}
+		if flags.Telemetry.WALMaxBytes > 0 {
+			tc.WALMaxBytes = flags.Telemetry.WALMaxBytes
+			tc.WALDir = flags.Telemetry.WALDir
+			if tc.WALDir == "" {
+				tc.WALDir = filepath.Join(filepath.Dir(config.GetConfigFilePath()), "telemetry-wal")
+			}
+		}
 		collector, err := telemetry.New(tc, c.GetPeerManager(), logger)
 		if err != nil {
 			logger.Warn("telemetry init failed, skipping", "err", err)
@@ -124,6 +148,15 @@ func Start(ctx context.Context, flags *config.Config) error {
 		}
 	}
 
+	// Local control API: used by `lattice status`/`lattice down` and local tooling.
+	apiServer := localapi.NewServer(localapi.SocketPath(c.Name), c)
+	g.Go(func() error {
+		if err := apiServer.Serve(gCtx); err != nil {
+			logger.Warn("local api stopped", "err", err)
+		}
+		return nil
+	})
+
 	fileUAPI, err := ipc.UAPIOpen(c.Name)
 	if err != nil {
 		return fmt.Errorf("failed to open UAPI socket: %w", err)
@@ -234,8 +267,22 @@ func startDaemon(flags *config.Config, logger *log.Logger) error {
 	return nil // unreachable
 }
 
-// Stop sends SIGTERM to the running lattice daemon via its PID file.
+// Stop asks the running agent to shut down through its local control API,
+// falling back to sending SIGTERM via its PID file for older agents.
 func Stop(flags *config.Config) error {
+	if path, err := localapi.FindSocket(flags.InterfaceName); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err = localapi.NewClient(path).Shutdown(ctx); err == nil {
+			fmt.Printf("lattice is shutting down (%s)\n", path)
+			return nil
+		}
+	}
+	return stopViaPIDFile(flags)
+}
+
+// stopViaPIDFile sends SIGTERM to the running lattice daemon via its PID file.
+func stopViaPIDFile(flags *config.Config) error {
 	interfaceName := flags.InterfaceName
 	if interfaceName == "" {
 		ctr, err := wgctrl.New()
@@ -276,8 +323,14 @@ func Stop(flags *config.Config) error {
 	return nil
 }
 
+// Status prints the node status reported by the running agent. When no
+// agent control socket is found it falls back to querying WireGuard directly.
 func Status(flags *config.Config) error {
-	return wireguard.PrintStatus(flags.InterfaceName)
+	path, err := localapi.FindSocket(flags.InterfaceName)
+	if err != nil {
+		return wireguard.PrintStatus(flags.InterfaceName)
+	}
+	return printAgentStatus(path)
 }
 
 func pidFilePath(iface string) string {
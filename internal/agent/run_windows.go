@@ -141,9 +141,10 @@ func Start(ctx context.Context, flags *config.Config) error {
 	}
 
 	// enable DNS
+	var nativeDNS *dns.LinkDNS
 	if flags.EnableDNS {
+		nativeDNS = dns.NewNativeDNS(&dns.DNSConfig{})
 		go func() {
-			nativeDNS := dns.NewNativeDNS(&dns.DNSConfig{})
 			nativeDNS.Start()
 			fmt.Println("Dns started")
 		}()
@@ -154,6 +155,12 @@ func Start(ctx context.Context, flags *config.Config) error {
 		return err
 	}
 
+	if nativeDNS != nil {
+		c.OnConfigApplied = func(msg *infra.Message) {
+			nativeDNS.SetRecords(dnsRecords(msg))
+		}
+	}
+
 	c.GetNetworkMap = func() (*infra.Message, error) {
 		// get network map from list
 		msg, err := c.ctrClient.GetNetMap(flags.Token)
@@ -0,0 +1,162 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/localapi"
+	"github.com/alatticeio/lattice/internal/agent/wireguard"
+)
+
+// PeerStatus prints the connection path to every remote peer, as reported
+// by the running agent over its local control socket.
+func PeerStatus(flags *config.Config) error {
+	path, err := localapi.FindSocket(flags.InterfaceName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	paths, err := localapi.NewClient(path).Peers(ctx)
+	if err != nil {
+		return err
+	}
+
+	direct, relayed := 0, 0
+	for _, p := range paths {
+		switch p.Path {
+		case infra.PathDirect:
+			direct++
+		case infra.PathRelay:
+			relayed++
+		}
+	}
+	fmt.Printf("Peers: %d total, %d direct, %d relayed\n", len(paths), direct, relayed)
+
+	for _, p := range paths {
+		printPeerPath(p)
+	}
+	return nil
+}
+
+// printAgentStatus prints the interface and peer status reported over the
+// control socket at path, in the same layout as wireguard.PrintStatus plus
+// the transport path of each peer.
+func printAgentStatus(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	st, err := localapi.NewClient(path).Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Interface : %s\n", st.Interface)
+	if st.Address != "" {
+		fmt.Printf("Address   : %s\n", st.Address)
+	}
+	fmt.Printf("Public Key: %s\n", st.PublicKey)
+	fmt.Printf("Port      : %d\n", st.ListenPort)
+	if len(st.Relays) > 0 {
+		fmt.Printf("Relays    : %s\n", strings.Join(st.Relays, ", "))
+	}
+
+	connected := 0
+	for _, p := range st.Peers {
+		if !p.LastHandshake.IsZero() && time.Since(p.LastHandshake) < wireguard.HandshakeActiveThreshold {
+			connected++
+		}
+	}
+	fmt.Printf("\nPeers: %d total, %d connected\n", len(st.Peers), connected)
+
+	for _, p := range st.Peers {
+		status := "disconnected"
+		handshake := "never"
+		if !p.LastHandshake.IsZero() {
+			elapsed := time.Since(p.LastHandshake)
+			handshake = wireguard.FormatDuration(elapsed) + " ago"
+			if elapsed < wireguard.HandshakeActiveThreshold {
+				status = "connected"
+			}
+		}
+		addrs := strings.Join(p.AllowedIPs, ", ")
+		if addrs == "" {
+			addrs = "(none)"
+		}
+		endpoint := p.Endpoint
+		if endpoint == "" {
+			endpoint = "(none)"
+		}
+
+		fmt.Printf("\n  Peer      : %s\n", p.PublicKey)
+		if p.Path != nil {
+			fmt.Printf("  Name      : %s\n", p.Path.AppID)
+		}
+		fmt.Printf("  Address   : %s\n", addrs)
+		fmt.Printf("  Endpoint  : %s\n", endpoint)
+		if p.Path != nil {
+			fmt.Printf("  Path      : %s\n", pathString(*p.Path))
+		}
+		fmt.Printf("  Handshake : %s\n", handshake)
+		fmt.Printf("  Traffic   : ↑ %s  ↓ %s\n", wireguard.FormatBytes(p.TxBytes), wireguard.FormatBytes(p.RxBytes))
+		fmt.Printf("  Status    : %s\n", status)
+	}
+	return nil
+}
+
+func pathString(p infra.PeerPath) string {
+	path := string(p.Path)
+	if path == "" {
+		path = "none"
+	}
+	if p.LocalCandidate != "" {
+		path = fmt.Sprintf("%s (%s -> %s)", path, p.LocalCandidate, p.RemoteCandidate)
+	}
+	return path
+}
+
+func printPeerPath(p infra.PeerPath) {
+	fmt.Printf("\n  Peer      : %s\n", p.AppID)
+	fmt.Printf("  State     : %s\n", p.State)
+	fmt.Printf("  Path      : %s\n", pathString(p))
+	if p.Endpoint != "" {
+		fmt.Printf("  Endpoint  : %s\n", p.Endpoint)
+	}
+	if p.RTTMillis > 0 {
+		fmt.Printf("  RTT       : %.1f ms\n", p.RTTMillis)
+	}
+	if !p.LastChange.IsZero() {
+		fmt.Printf("  Changed   : %s ago\n", time.Since(p.LastChange).Round(time.Second))
+	}
+	if p.LastError != "" {
+		fmt.Printf("  Last error: %s\n", p.LastError)
+	}
+	for _, ev := range p.History {
+		from, to := string(ev.From), string(ev.To)
+		if from == "" {
+			from = "none"
+		}
+		if to == "" {
+			to = "none"
+		}
+		fmt.Printf("    %s  %s -> %s  %s\n", ev.At.Format(time.TimeOnly), from, to, ev.Reason)
+	}
+}
@@ -5,6 +5,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/alatticeio/lattice/internal/server/dto"
 	"github.com/alatticeio/lattice/internal/server/models"
@@ -22,6 +23,7 @@ type Store interface {
 	UserIdentities() UserIdentityRepository
 	WorkspaceInvitations() WorkspaceInvitationRepository
 	AuditLogs() AuditLogRepository
+	FlowLogs() FlowLogRepository
 	WorkflowRequests() WorkflowRepository
 	Policies() PolicyRepository
 
@@ -30,6 +32,9 @@ type Store interface {
 
 	Alerts() AlertRepository
 	CustomMetrics() CustomMetricRepository
+	ServiceAccounts() ServiceAccountRepository
+	APITokens() APITokenRepository
+	AIConversations() AIConversationRepository
 
 	Close() error
 }
@@ -73,6 +78,8 @@ type WorkspaceMemberRepository interface {
 	ListMembers(ctx context.Context, workspaceID string) ([]*models.WorkspaceMember, error)
 	ListByUser(ctx context.Context, userID string, page, pageSize int) ([]*models.WorkspaceMember, int64, error)
 	UpdateRole(ctx context.Context, workspaceID, userID string, role dto.WorkspaceRole) error
+	// ListBySource 返回用户指定来源的全部成员关系（含 suspended）。
+	ListBySource(ctx context.Context, userID, source string) ([]*models.WorkspaceMember, error)
 }
 
 // ProfileRepository 定义用户扩展资料数据操作。
@@ -85,7 +92,9 @@ type ProfileRepository interface {
 type UserIdentityRepository interface {
 	GetByProviderAndExternalID(ctx context.Context, provider, externalID string) (*models.UserIdentity, error)
 	ListByUser(ctx context.Context, userID string) ([]*models.UserIdentity, error)
+	ListByProvider(ctx context.Context, provider string) ([]*models.UserIdentity, error)
 	Create(ctx context.Context, identity *models.UserIdentity) error
+	Update(ctx context.Context, identity *models.UserIdentity) error
 }
 
 // WorkspaceInvitationRepository manages workspace invitations.
@@ -106,6 +115,7 @@ type AuditLogFilter struct {
 	Action      string
 	Resource    string
 	Status      string
+	Source      string // api | kubernetes | nats | ai
 	Keyword     string // searches UserName and ResourceName
 	From        string // RFC3339 or date string
 	To          string
@@ -116,8 +126,49 @@ type AuditLogFilter struct {
 // AuditLogRepository manages append-only audit log records.
 type AuditLogRepository interface {
 	Create(ctx context.Context, log *models.AuditLog) error
+	// BatchCreate writes all of logs or, on error, none of them.
 	BatchCreate(ctx context.Context, logs []*models.AuditLog) error
 	List(ctx context.Context, filter AuditLogFilter) ([]*models.AuditLog, int64, error)
+	// Last returns the hash-chained record with the highest Seq, or nil
+	// when there is none.
+	Last(ctx context.Context) (*models.AuditLog, error)
+	// Range returns up to limit hash-chained records with Seq > afterSeq,
+	// in Seq order.
+	Range(ctx context.Context, afterSeq int64, limit int) ([]*models.AuditLog, error)
+	// Each calls fn for every record matching filter, oldest first. Paging
+	// fields are ignored; an error from fn stops the iteration.
+	Each(ctx context.Context, filter AuditLogFilter, fn func(*models.AuditLog) error) error
+	// DeleteUntil removes records created at or before t and returns how
+	// many were removed. The last hash-chained record removed becomes the
+	// anchor.
+	DeleteUntil(ctx context.Context, t time.Time) (int64, error)
+	// Anchor returns the last hash-chained record DeleteUntil removed, or
+	// nil when none was.
+	Anchor(ctx context.Context) (*models.AuditAnchor, error)
+}
+
+// FlowLogFilter defines query parameters for flow log listing.
+type FlowLogFilter struct {
+	WorkspaceID string
+	Peer        string // matches the reporting or the remote peer
+	Verdict     string
+	Policy      string
+	Direction   string
+	IP          string // matches the local or the remote address
+	Port        int    // matches the local or the remote port
+	From        string // RFC3339
+	To          string
+	Page        int
+	PageSize    int
+}
+
+// FlowLogRepository manages append-only flow log records.
+type FlowLogRepository interface {
+	BatchCreate(ctx context.Context, logs []*models.FlowLog) error
+	List(ctx context.Context, filter FlowLogFilter) ([]*models.FlowLog, int64, error)
+	// DeleteBefore removes records whose interval ended before t and
+	// returns how many were removed.
+	DeleteBefore(ctx context.Context, t time.Time) (int64, error)
 }
 
 // WorkflowFilter defines query parameters for workflow request listing.
@@ -190,4 +241,77 @@ type CustomMetricRepository interface {
 	Update(ctx context.Context, m *models.CustomMetric) error
 	Delete(ctx context.Context, id string) error
 	ListByWorkspace(ctx context.Context, wsID string) ([]*models.CustomMetric, error)
+	// List 返回所有工作空间的自定义指标，用于启动时注册到模板库。
+	List(ctx context.Context) ([]*models.CustomMetric, error)
+}
+
+// ServiceAccountRepository manages workspace service accounts.
+type ServiceAccountRepository interface {
+	GetByID(ctx context.Context, id string) (*models.ServiceAccount, error)
+	Create(ctx context.Context, sa *models.ServiceAccount) error
+	Delete(ctx context.Context, id string) error
+	ListByWorkspace(ctx context.Context, wsID string) ([]*models.ServiceAccount, error)
+}
+
+// APITokenRepository manages personal access tokens and service account tokens.
+type APITokenRepository interface {
+	GetByID(ctx context.Context, id string) (*models.APIToken, error)
+	GetByHash(ctx context.Context, hash string) (*models.APIToken, error)
+	Create(ctx context.Context, t *models.APIToken) error
+	ListByUser(ctx context.Context, userID string) ([]*models.APIToken, error)
+	ListByServiceAccount(ctx context.Context, saID string) ([]*models.APIToken, error)
+	// Revoke marks the token revoked; revoking twice keeps the first time.
+	Revoke(ctx context.Context, id string, at time.Time) error
+	// RevokeByServiceAccount revokes every live token of a service account.
+	RevokeByServiceAccount(ctx context.Context, saID string, at time.Time) error
+	TouchLastUsed(ctx context.Context, id string, at time.Time, ip string) error
+}
+
+// AIConversationFilter defines query parameters for AI conversation listing.
+type AIConversationFilter struct {
+	WorkspaceID string
+	UserID      string
+	Keyword     string // searches Title and UserName
+	From        string // RFC3339, on CreatedAt
+	To          string
+	Page        int
+	PageSize    int
+}
+
+// AIUsageFilter defines query parameters for AI usage listing. Days are
+// 2006-01-02 and inclusive.
+type AIUsageFilter struct {
+	WorkspaceID string
+	From        string
+	To          string
+}
+
+// AIConversationRepository manages AI assistant conversations and the daily
+// LLM usage of each workspace.
+type AIConversationRepository interface {
+	Create(ctx context.Context, c *models.AIConversation) error
+	GetByID(ctx context.Context, id string) (*models.AIConversation, error)
+	// List returns conversations matching filter, most recently active first.
+	List(ctx context.Context, filter AIConversationFilter) ([]*models.AIConversation, int64, error)
+	// Each calls fn for every conversation matching filter, oldest first.
+	// Paging fields are ignored; an error from fn stops the iteration.
+	Each(ctx context.Context, filter AIConversationFilter, fn func(*models.AIConversation) error) error
+	// AppendMessages stores msgs in order and adds their tokens and tool
+	// calls to the conversation's counters.
+	AppendMessages(ctx context.Context, conversationID string, msgs []*models.AIMessage) error
+	// Messages returns the messages of a conversation in order.
+	Messages(ctx context.Context, conversationID string) ([]*models.AIMessage, error)
+
+	// AddUsage adds the counters of u to the usage of u.WorkspaceID on u.Day.
+	AddUsage(ctx context.Context, u *models.AIUsage) error
+	// ReserveUsage adds the counters of u like AddUsage, but only while the
+	// usage stays within the limits: input plus output tokens below
+	// maxTokens, and tool calls no more than maxToolCalls once u is added.
+	// A limit of 0 is no limit. Check and addition are one statement, so
+	// concurrent callers cannot both take the last unit. It reports whether
+	// u was added.
+	ReserveUsage(ctx context.Context, u *models.AIUsage, maxTokens, maxToolCalls int64) (bool, error)
+	// GetUsage returns the usage of a workspace on day, zero when there is none.
+	GetUsage(ctx context.Context, workspaceID, day string) (*models.AIUsage, error)
+	ListUsage(ctx context.Context, filter AIUsageFilter) ([]*models.AIUsage, error)
 }
@@ -0,0 +1,49 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+const telemetryTimeout = 10 * time.Second
+
+// sendTelemetry is the telemetry Sink used with the "nats" transport: it
+// sends a Remote Write batch to the management server over the signaling
+// connection.
+func (c *Node) sendTelemetry(ctx context.Context, payload []byte) error {
+	if c.current == nil {
+		return errors.New("telemetry: network map not received yet")
+	}
+	data, err := json.Marshal(infra.TelemetryReport{
+		Token:     c.token,
+		AppID:     config.Conf.AppId,
+		Namespace: c.current.NetworkId,
+		Payload:   payload,
+	})
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, telemetryTimeout)
+	defer cancel()
+	_, err = c.ctrClient.RequestNats(reqCtx, "lattice.signals.peer", "telemetry", data)
+	return err
+}
@@ -24,7 +24,9 @@ import (
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 )
 
-const handshakeActiveThreshold = 3 * time.Minute
+// HandshakeActiveThreshold is how recent the last handshake must be for a
+// peer to be reported as connected.
+const HandshakeActiveThreshold = 3 * time.Minute
 
 // PrintStatus prints the current WireGuard interface and peer status to stdout.
 func PrintStatus(interfaceName string) error {
@@ -70,7 +72,7 @@ func PrintStatus(interfaceName string) error {
 
 		connected := 0
 		for _, p := range dev.Peers {
-			if !p.LastHandshakeTime.IsZero() && time.Since(p.LastHandshakeTime) < handshakeActiveThreshold {
+			if !p.LastHandshakeTime.IsZero() && time.Since(p.LastHandshakeTime) < HandshakeActiveThreshold {
 				connected++
 			}
 		}
@@ -88,8 +90,8 @@ func printPeer(p wgtypes.Peer) {
 	handshakeStr := "never"
 	if !p.LastHandshakeTime.IsZero() {
 		elapsed := time.Since(p.LastHandshakeTime)
-		handshakeStr = formatDuration(elapsed) + " ago"
-		if elapsed < handshakeActiveThreshold {
+		handshakeStr = FormatDuration(elapsed) + " ago"
+		if elapsed < HandshakeActiveThreshold {
 			status = "connected"
 		}
 	}
@@ -112,11 +114,12 @@ func printPeer(p wgtypes.Peer) {
 	fmt.Printf("  Address   : %s\n", ipStr)
 	fmt.Printf("  Endpoint  : %s\n", endpointStr)
 	fmt.Printf("  Handshake : %s\n", handshakeStr)
-	fmt.Printf("  Traffic   : ↑ %s  ↓ %s\n", formatBytes(p.TransmitBytes), formatBytes(p.ReceiveBytes))
+	fmt.Printf("  Traffic   : ↑ %s  ↓ %s\n", FormatBytes(p.TransmitBytes), FormatBytes(p.ReceiveBytes))
 	fmt.Printf("  Status    : %s\n", status)
 }
 
-func formatDuration(d time.Duration) string {
+// FormatDuration renders d as a coarse human-readable age.
+func FormatDuration(d time.Duration) string {
 	d = d.Round(time.Second)
 	if d < time.Minute {
 		return fmt.Sprintf("%d seconds", int(d.Seconds()))
@@ -127,7 +130,8 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%d hours", int(d.Hours()))
 }
 
-func formatBytes(b int64) string {
+// FormatBytes renders b with a binary unit suffix.
+func FormatBytes(b int64) string {
 	const unit = 1024
 	if b < unit {
 		return fmt.Sprintf("%d B", b)
@@ -0,0 +1,111 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wireguard
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// PeerStats holds the per-peer counters reported by a WireGuard device.
+type PeerStats struct {
+	PublicKey     string
+	Endpoint      string
+	AllowedIPs    []string
+	LastHandshake time.Time
+	RxBytes       int64
+	TxBytes       int64
+}
+
+// DeviceStats is the parsed output of a UAPI "get=1" operation.
+type DeviceStats struct {
+	ListenPort int
+	Peers      []PeerStats
+}
+
+// ParseUAPI parses the key=value dump produced by Device.IpcGet.
+// Keys are returned in hex by the UAPI; public keys are converted to the
+// usual base64 form so they can be matched against infra.Peer.PublicKey.
+func ParseUAPI(dump string) (*DeviceStats, error) {
+	stats := &DeviceStats{}
+	var (
+		cur     *PeerStats
+		hsSec   int64
+		hsNsec  int64
+		flushHs = func() {
+			if cur != nil && hsSec != 0 {
+				cur.LastHandshake = time.Unix(hsSec, hsNsec)
+			}
+			hsSec, hsNsec = 0, 0
+		}
+	)
+
+	sc := bufio.NewScanner(strings.NewReader(dump))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed uapi line %q", line)
+		}
+
+		if key == "public_key" {
+			flushHs()
+			raw, err := hex.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid public_key: %w", err)
+			}
+			k, err := wgtypes.NewKey(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid public_key: %w", err)
+			}
+			stats.Peers = append(stats.Peers, PeerStats{PublicKey: k.String()})
+			cur = &stats.Peers[len(stats.Peers)-1]
+			continue
+		}
+
+		if cur == nil {
+			if key == "listen_port" {
+				stats.ListenPort, _ = strconv.Atoi(value)
+			}
+			continue
+		}
+
+		switch key {
+		case "endpoint":
+			cur.Endpoint = value
+		case "allowed_ip":
+			cur.AllowedIPs = append(cur.AllowedIPs, value)
+		case "rx_bytes":
+			cur.RxBytes, _ = strconv.ParseInt(value, 10, 64)
+		case "tx_bytes":
+			cur.TxBytes, _ = strconv.ParseInt(value, 10, 64)
+		case "last_handshake_time_sec":
+			hsSec, _ = strconv.ParseInt(value, 10, 64)
+		case "last_handshake_time_nsec":
+			hsNsec, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	flushHs()
+	return stats, sc.Err()
+}
@@ -0,0 +1,221 @@
+package gormstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// aiConversationEachBatch is the page size Each reads conversations in.
+const aiConversationEachBatch = 200
+
+type aiConversationRepo struct {
+	db *gorm.DB
+}
+
+func newAIConversationRepo(db *gorm.DB) *aiConversationRepo {
+	return &aiConversationRepo{db: db}
+}
+
+func (r *aiConversationRepo) Create(ctx context.Context, c *models.AIConversation) error {
+	return r.db.WithContext(ctx).Create(c).Error
+}
+
+func (r *aiConversationRepo) GetByID(ctx context.Context, id string) (*models.AIConversation, error) {
+	var c models.AIConversation
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func applyAIConversationFilter(q *gorm.DB, f store.AIConversationFilter) *gorm.DB {
+	if f.WorkspaceID != "" {
+		q = q.Where("workspace_id = ?", f.WorkspaceID)
+	}
+	if f.UserID != "" {
+		q = q.Where("user_id = ?", f.UserID)
+	}
+	if f.Keyword != "" {
+		like := "%" + f.Keyword + "%"
+		q = q.Where("title LIKE ? OR user_name LIKE ?", like, like)
+	}
+	if f.From != "" {
+		if t, err := time.Parse(time.RFC3339, f.From); err == nil {
+			q = q.Where("created_at >= ?", t)
+		}
+	}
+	if f.To != "" {
+		if t, err := time.Parse(time.RFC3339, f.To); err == nil {
+			q = q.Where("created_at <= ?", t)
+		}
+	}
+	return q
+}
+
+func (r *aiConversationRepo) List(ctx context.Context, f store.AIConversationFilter) ([]*models.AIConversation, int64, error) {
+	q := applyAIConversationFilter(r.db.WithContext(ctx).Model(&models.AIConversation{}), f)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := f.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := f.PageSize
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	var convs []*models.AIConversation
+	err := q.Order("updated_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&convs).Error
+	return convs, total, err
+}
+
+func (r *aiConversationRepo) Each(ctx context.Context, f store.AIConversationFilter, fn func(*models.AIConversation) error) error {
+	q := applyAIConversationFilter(r.db.WithContext(ctx).Model(&models.AIConversation{}), f).
+		Order("created_at ASC, id ASC")
+
+	for offset := 0; ; offset += aiConversationEachBatch {
+		var convs []*models.AIConversation
+		if err := q.Session(&gorm.Session{}).Offset(offset).Limit(aiConversationEachBatch).Find(&convs).Error; err != nil {
+			return err
+		}
+		for _, c := range convs {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+		if len(convs) < aiConversationEachBatch {
+			return nil
+		}
+	}
+}
+
+func (r *aiConversationRepo) AppendMessages(ctx context.Context, conversationID string, msgs []*models.AIMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	var input, output int64
+	var toolCalls int
+	for _, m := range msgs {
+		m.ConversationID = conversationID
+		input += int64(m.InputTokens)
+		output += int64(m.OutputTokens)
+		toolCalls += m.ToolCallCount
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(msgs).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&models.AIConversation{}).
+			Where("id = ?", conversationID).
+			UpdateColumns(map[string]any{
+				"message_count": gorm.Expr("message_count + ?", len(msgs)),
+				"input_tokens":  gorm.Expr("input_tokens + ?", input),
+				"output_tokens": gorm.Expr("output_tokens + ?", output),
+				"tool_calls":    gorm.Expr("tool_calls + ?", toolCalls),
+				"updated_at":    time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *aiConversationRepo) Messages(ctx context.Context, conversationID string) ([]*models.AIMessage, error) {
+	var msgs []*models.AIMessage
+	err := r.db.WithContext(ctx).
+		Where("conversation_id = ?", conversationID).
+		Order("id ASC").
+		Find(&msgs).Error
+	return msgs, err
+}
+
+func (r *aiConversationRepo) AddUsage(ctx context.Context, u *models.AIUsage) error {
+	u.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "workspace_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"requests":      gorm.Expr("requests + ?", u.Requests),
+			"input_tokens":  gorm.Expr("input_tokens + ?", u.InputTokens),
+			"output_tokens": gorm.Expr("output_tokens + ?", u.OutputTokens),
+			"tool_calls":    gorm.Expr("tool_calls + ?", u.ToolCalls),
+			"updated_at":    u.UpdatedAt,
+		}),
+	}).Create(u).Error
+}
+
+func (r *aiConversationRepo) ReserveUsage(ctx context.Context, u *models.AIUsage, maxTokens, maxToolCalls int64) (bool, error) {
+	u.UpdatedAt = time.Now()
+	reserved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		row := &models.AIUsage{WorkspaceID: u.WorkspaceID, Day: u.Day, UpdatedAt: u.UpdatedAt}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(row).Error; err != nil {
+			return err
+		}
+		q := tx.Model(&models.AIUsage{}).Where("workspace_id = ? AND day = ?", u.WorkspaceID, u.Day)
+		if maxTokens > 0 {
+			q = q.Where("input_tokens + output_tokens < ?", maxTokens)
+		}
+		if maxToolCalls > 0 {
+			q = q.Where("tool_calls + ? <= ?", u.ToolCalls, maxToolCalls)
+		}
+		res := q.Updates(map[string]any{
+			"requests":      gorm.Expr("requests + ?", u.Requests),
+			"input_tokens":  gorm.Expr("input_tokens + ?", u.InputTokens),
+			"output_tokens": gorm.Expr("output_tokens + ?", u.OutputTokens),
+			"tool_calls":    gorm.Expr("tool_calls + ?", u.ToolCalls),
+			"updated_at":    u.UpdatedAt,
+		})
+		reserved = res.RowsAffected == 1
+		return res.Error
+	})
+	return reserved, err
+}
+
+func (r *aiConversationRepo) GetUsage(ctx context.Context, workspaceID, day string) (*models.AIUsage, error) {
+	var u models.AIUsage
+	err := r.db.WithContext(ctx).Where("workspace_id = ? AND day = ?", workspaceID, day).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.AIUsage{WorkspaceID: workspaceID, Day: day}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *aiConversationRepo) ListUsage(ctx context.Context, f store.AIUsageFilter) ([]*models.AIUsage, error) {
+	q := r.db.WithContext(ctx).Model(&models.AIUsage{})
+	if f.WorkspaceID != "" {
+		q = q.Where("workspace_id = ?", f.WorkspaceID)
+	}
+	if f.From != "" {
+		q = q.Where("day >= ?", f.From)
+	}
+	if f.To != "" {
+		q = q.Where("day <= ?", f.To)
+	}
+	var usage []*models.AIUsage
+	err := q.Order("day ASC, workspace_id ASC").Find(&usage).Error
+	return usage, err
+}
+
+var _ store.AIConversationRepository = (*aiConversationRepo)(nil)
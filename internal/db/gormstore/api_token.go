@@ -0,0 +1,92 @@
+package gormstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/models"
+	"github.com/alatticeio/lattice/internal/server/repository"
+
+	"gorm.io/gorm"
+)
+
+type serviceAccountRepo struct {
+	*repository.BaseRepository[models.ServiceAccount]
+}
+
+func newServiceAccountRepo(db *gorm.DB) *serviceAccountRepo {
+	return &serviceAccountRepo{BaseRepository: repository.NewBaseRepository[models.ServiceAccount](db)}
+}
+
+func (r *serviceAccountRepo) GetByID(ctx context.Context, id string) (*models.ServiceAccount, error) {
+	return r.BaseRepository.GetByID(ctx, id)
+}
+
+func (r *serviceAccountRepo) Delete(ctx context.Context, id string) error {
+	return r.BaseRepository.Delete(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ?", id)
+	})
+}
+
+func (r *serviceAccountRepo) ListByWorkspace(ctx context.Context, wsID string) ([]*models.ServiceAccount, error) {
+	return r.Find(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("workspace_id = ?", wsID).Order("created_at ASC")
+	})
+}
+
+type apiTokenRepo struct {
+	*repository.BaseRepository[models.APIToken]
+}
+
+func newAPITokenRepo(db *gorm.DB) *apiTokenRepo {
+	return &apiTokenRepo{BaseRepository: repository.NewBaseRepository[models.APIToken](db)}
+}
+
+func (r *apiTokenRepo) GetByID(ctx context.Context, id string) (*models.APIToken, error) {
+	return r.BaseRepository.GetByID(ctx, id)
+}
+
+func (r *apiTokenRepo) GetByHash(ctx context.Context, hash string) (*models.APIToken, error) {
+	return r.First(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("hash = ?", hash)
+	})
+}
+
+func (r *apiTokenRepo) ListByUser(ctx context.Context, userID string) ([]*models.APIToken, error) {
+	return r.Find(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ?", userID).Order("created_at DESC")
+	})
+}
+
+func (r *apiTokenRepo) ListByServiceAccount(ctx context.Context, saID string) ([]*models.APIToken, error) {
+	return r.Find(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("service_account_id = ?", saID).Order("created_at DESC")
+	})
+}
+
+func (r *apiTokenRepo) Revoke(ctx context.Context, id string, at time.Time) error {
+	return r.DB().WithContext(ctx).
+		Model(&models.APIToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *apiTokenRepo) RevokeByServiceAccount(ctx context.Context, saID string, at time.Time) error {
+	return r.DB().WithContext(ctx).
+		Model(&models.APIToken{}).
+		Where("service_account_id = ? AND revoked_at IS NULL", saID).
+		Update("revoked_at", at).Error
+}
+
+func (r *apiTokenRepo) TouchLastUsed(ctx context.Context, id string, at time.Time, ip string) error {
+	return r.DB().WithContext(ctx).
+		Model(&models.APIToken{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]any{"last_used_at": at, "last_used_ip": ip}).Error
+}
+
+var (
+	_ store.ServiceAccountRepository = (*serviceAccountRepo)(nil)
+	_ store.APITokenRepository       = (*apiTokenRepo)(nil)
+)
@@ -26,12 +26,115 @@ func (r *auditLogRepo) BatchCreate(ctx context.Context, logs []*models.AuditLog)
 	if len(logs) == 0 {
 		return nil
 	}
-	return r.db.WithContext(ctx).CreateInBatches(logs, 100).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(logs, 100).Error
+	})
 }
 
 func (r *auditLogRepo) List(ctx context.Context, f store.AuditLogFilter) ([]*models.AuditLog, int64, error) {
-	q := r.db.WithContext(ctx).Model(&models.AuditLog{})
+	q := applyAuditFilter(r.db.WithContext(ctx).Model(&models.AuditLog{}), f)
 
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := f.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := f.PageSize
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	var logs []*models.AuditLog
+	err := q.Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&logs).Error
+	return logs, total, err
+}
+
+func (r *auditLogRepo) Last(ctx context.Context) (*models.AuditLog, error) {
+	var logs []*models.AuditLog
+	err := r.db.WithContext(ctx).
+		Where("seq > 0").
+		Order("seq DESC").
+		Limit(1).
+		Find(&logs).Error
+	if err != nil || len(logs) == 0 {
+		return nil, err
+	}
+	return logs[0], nil
+}
+
+func (r *auditLogRepo) Range(ctx context.Context, afterSeq int64, limit int) ([]*models.AuditLog, error) {
+	var logs []*models.AuditLog
+	err := r.db.WithContext(ctx).
+		Where("seq > ?", afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+// auditEachBatch is the page size Each reads with, so exports of any size
+// never hold more than one page in memory.
+const auditEachBatch = 500
+
+func (r *auditLogRepo) Each(ctx context.Context, f store.AuditLogFilter, fn func(*models.AuditLog) error) error {
+	q := applyAuditFilter(r.db.WithContext(ctx).Model(&models.AuditLog{}), f).
+		Order("created_at ASC, seq ASC")
+
+	for offset := 0; ; offset += auditEachBatch {
+		var logs []*models.AuditLog
+		if err := q.Session(&gorm.Session{}).Offset(offset).Limit(auditEachBatch).Find(&logs).Error; err != nil {
+			return err
+		}
+		for _, l := range logs {
+			if err := fn(l); err != nil {
+				return err
+			}
+		}
+		if len(logs) < auditEachBatch {
+			return nil
+		}
+	}
+}
+
+// auditAnchorID is the primary key of the single AuditAnchor row.
+const auditAnchorID = 1
+
+func (r *auditLogRepo) DeleteUntil(ctx context.Context, t time.Time) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last []*models.AuditLog
+		if err := tx.Where("created_at <= ? AND seq > 0", t).Order("seq DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+		res := tx.Where("created_at <= ?", t).Delete(&models.AuditLog{})
+		if res.Error != nil {
+			return res.Error
+		}
+		n = res.RowsAffected
+		if len(last) == 0 {
+			return nil
+		}
+		return tx.Save(&models.AuditAnchor{ID: auditAnchorID, Seq: last[0].Seq, Hash: last[0].Hash}).Error
+	})
+	return n, err
+}
+
+func (r *auditLogRepo) Anchor(ctx context.Context) (*models.AuditAnchor, error) {
+	var anchors []*models.AuditAnchor
+	if err := r.db.WithContext(ctx).Where("id = ?", auditAnchorID).Limit(1).Find(&anchors).Error; err != nil || len(anchors) == 0 {
+		return nil, err
+	}
+	return anchors[0], nil
+}
+
+func applyAuditFilter(q *gorm.DB, f store.AuditLogFilter) *gorm.DB {
 	if f.WorkspaceID != "" {
 		q = q.Where("workspace_id = ?", f.WorkspaceID)
 	}
@@ -44,6 +147,9 @@ func (r *auditLogRepo) List(ctx context.Context, f store.AuditLogFilter) ([]*mod
 	if f.Status != "" {
 		q = q.Where("status = ?", f.Status)
 	}
+	if f.Source != "" {
+		q = q.Where("source = ?", f.Source)
+	}
 	if f.Keyword != "" {
 		like := "%" + f.Keyword + "%"
 		q = q.Where("user_name LIKE ? OR resource_name LIKE ?", like, like)
@@ -58,25 +164,5 @@ func (r *auditLogRepo) List(ctx context.Context, f store.AuditLogFilter) ([]*mod
 			q = q.Where("created_at <= ?", t)
 		}
 	}
-
-	var total int64
-	if err := q.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	page := f.Page
-	if page < 1 {
-		page = 1
-	}
-	pageSize := f.PageSize
-	if pageSize < 1 {
-		pageSize = 20
-	}
-
-	var logs []*models.AuditLog
-	err := q.Order("created_at DESC").
-		Offset((page - 1) * pageSize).
-		Limit(pageSize).
-		Find(&logs).Error
-	return logs, total, err
+	return q
 }
@@ -25,6 +25,10 @@ func (r *customMetricRepo) ListByWorkspace(ctx context.Context, wsID string) ([]
 	})
 }
 
+func (r *customMetricRepo) List(ctx context.Context) ([]*models.CustomMetric, error) {
+	return r.BaseRepository.Find(ctx)
+}
+
 func (r *customMetricRepo) GetByID(ctx context.Context, id string) (*models.CustomMetric, error) {
 	return r.BaseRepository.GetByID(ctx, id)
 }
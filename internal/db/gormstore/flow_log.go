@@ -0,0 +1,88 @@
+package gormstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/models"
+
+	"gorm.io/gorm"
+)
+
+type flowLogRepo struct {
+	db *gorm.DB
+}
+
+func newFlowLogRepo(db *gorm.DB) *flowLogRepo {
+	return &flowLogRepo{db: db}
+}
+
+func (r *flowLogRepo) BatchCreate(ctx context.Context, logs []*models.FlowLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(logs, 100).Error
+}
+
+func (r *flowLogRepo) List(ctx context.Context, f store.FlowLogFilter) ([]*models.FlowLog, int64, error) {
+	q := r.db.WithContext(ctx).Model(&models.FlowLog{})
+
+	if f.WorkspaceID != "" {
+		q = q.Where("workspace_id = ?", f.WorkspaceID)
+	}
+	if f.Peer != "" {
+		q = q.Where("peer = ? OR remote_peer = ?", f.Peer, f.Peer)
+	}
+	if f.Verdict != "" {
+		q = q.Where("verdict = ?", f.Verdict)
+	}
+	if f.Policy != "" {
+		q = q.Where("policy = ?", f.Policy)
+	}
+	if f.Direction != "" {
+		q = q.Where("direction = ?", f.Direction)
+	}
+	if f.IP != "" {
+		q = q.Where("local_ip = ? OR remote_ip = ?", f.IP, f.IP)
+	}
+	if f.Port != 0 {
+		q = q.Where("local_port = ? OR remote_port = ?", f.Port, f.Port)
+	}
+	if f.From != "" {
+		if t, err := time.Parse(time.RFC3339, f.From); err == nil {
+			q = q.Where("interval_end >= ?", t)
+		}
+	}
+	if f.To != "" {
+		if t, err := time.Parse(time.RFC3339, f.To); err == nil {
+			q = q.Where("interval_start <= ?", t)
+		}
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := f.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := f.PageSize
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	var logs []*models.FlowLog
+	err := q.Order("interval_end DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&logs).Error
+	return logs, total, err
+}
+
+func (r *flowLogRepo) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("interval_end < ?", t).Delete(&models.FlowLog{})
+	return res.RowsAffected, res.Error
+}
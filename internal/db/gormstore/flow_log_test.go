@@ -0,0 +1,70 @@
+// Copyright 2026 alatticeio
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormstore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/models"
+)
+
+func TestFlowLogs_ListFiltersAndPrunes(t *testing.T) {
+	st := setupTestStore(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	old := now.Add(-48 * time.Hour)
+	logs := []*models.FlowLog{
+		{ID: "f1", WorkspaceID: "ws1", Peer: "web", RemotePeer: "db", RemoteIP: "10.0.0.3", RemotePort: 5432, Verdict: "accept", Policy: "allow-db", IntervalEnd: now},
+		{ID: "f2", WorkspaceID: "ws1", Peer: "db", RemotePeer: "web", RemoteIP: "10.0.0.2", LocalPort: 5432, Verdict: "accept", Policy: "allow-db", IntervalEnd: now},
+		{ID: "f3", WorkspaceID: "ws1", Peer: "web", RemoteIP: "10.0.0.9", RemotePort: 22, Verdict: "drop", Policy: "default-deny", IntervalEnd: old},
+		{ID: "f4", WorkspaceID: "ws2", Peer: "web", RemotePeer: "db", Verdict: "drop", IntervalEnd: now},
+	}
+	if err := st.FlowLogs().BatchCreate(ctx, logs); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter store.FlowLogFilter
+		want   int64
+	}{
+		{"workspace", store.FlowLogFilter{WorkspaceID: "ws1"}, 3},
+		{"either peer", store.FlowLogFilter{WorkspaceID: "ws1", Peer: "db"}, 2},
+		{"verdict", store.FlowLogFilter{WorkspaceID: "ws1", Verdict: "drop"}, 1},
+		{"either port", store.FlowLogFilter{WorkspaceID: "ws1", Port: 5432}, 2},
+		{"since", store.FlowLogFilter{WorkspaceID: "ws1", From: now.Add(-time.Hour).Format(time.RFC3339)}, 2},
+	}
+	for _, tt := range tests {
+		_, total, err := st.FlowLogs().List(ctx, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != tt.want {
+			t.Errorf("%s: got %d records, want %d", tt.name, total, tt.want)
+		}
+	}
+
+	n, err := st.FlowLogs().DeleteBefore(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("pruned %d records, want 1", n)
+	}
+}
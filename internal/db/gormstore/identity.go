@@ -30,6 +30,16 @@ func (r *userIdentityRepo) ListByUser(ctx context.Context, userID string) ([]*mo
 	})
 }
 
+func (r *userIdentityRepo) ListByProvider(ctx context.Context, provider string) ([]*models.UserIdentity, error) {
+	return r.Find(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("provider = ?", provider)
+	})
+}
+
 func (r *userIdentityRepo) Create(ctx context.Context, identity *models.UserIdentity) error {
 	return r.BaseRepository.Create(ctx, identity)
 }
+
+func (r *userIdentityRepo) Update(ctx context.Context, identity *models.UserIdentity) error {
+	return r.BaseRepository.Update(ctx, identity)
+}
@@ -10,6 +10,13 @@ import (
 // GORM AutoMigrate 仅做增量变更（新增列/索引），不会删除列，对存量数据安全。
 // Token 和 Peer 数据已迁移至 K8s etcd，不再在此处管理。
 func migrate(db *gorm.DB) error {
+	// 审计日志 Seq 由普通索引改为唯一索引：启用哈希链之前的记录不参与唯一约束，
+	// 其 Seq 须为 NULL 而非 0，否则创建唯一索引会因重复的 0 失败。
+	if db.Migrator().HasColumn(&models.AuditLog{}, "seq") {
+		if err := db.Model(&models.AuditLog{}).Where("seq = 0").Update("seq", nil).Error; err != nil {
+			return err
+		}
+	}
 	return db.AutoMigrate(
 		&models.User{},
 		&models.UserProfile{},
@@ -18,6 +25,8 @@ func migrate(db *gorm.DB) error {
 		&models.WorkspaceMember{},
 		&models.WorkspaceInvitation{},
 		&models.AuditLog{},
+		&models.AuditAnchor{},
+		&models.FlowLog{},
 		&models.WorkflowRequest{},
 		&models.Policy{},
 		&models.AlertRule{},
@@ -25,5 +34,10 @@ func migrate(db *gorm.DB) error {
 		&models.AlertChannel{},
 		&models.AlertSilence{},
 		&models.CustomMetric{},
+		&models.ServiceAccount{},
+		&models.APIToken{},
+		&models.AIConversation{},
+		&models.AIMessage{},
+		&models.AIUsage{},
 	)
 }
@@ -23,10 +23,14 @@ type GormStore struct {
 	userIdentities       store.UserIdentityRepository
 	workspaceInvitations store.WorkspaceInvitationRepository
 	auditLogs            store.AuditLogRepository
+	flowLogs             store.FlowLogRepository
 	workflowRequests     store.WorkflowRepository
 	policies             store.PolicyRepository
 	alerts               store.AlertRepository
 	customMetrics        store.CustomMetricRepository
+	serviceAccounts      store.ServiceAccountRepository
+	apiTokens            store.APITokenRepository
+	aiConversations      store.AIConversationRepository
 }
 
 // New 创建 gormStore：先执行 AutoMigrate，再初始化各子 Repository。
@@ -46,10 +50,14 @@ func newStore(db *gorm.DB) *GormStore {
 		userIdentities:       newUserIdentityRepo(db),
 		workspaceInvitations: newWorkspaceInvitationRepo(db),
 		auditLogs:            newAuditLogRepo(db),
+		flowLogs:             newFlowLogRepo(db),
 		workflowRequests:     newWorkflowRepo(db),
 		policies:             newPolicyRepo(db),
 		alerts:               newAlertRepo(db),
 		customMetrics:        newCustomMetricRepo(db),
+		serviceAccounts:      newServiceAccountRepo(db),
+		apiTokens:            newAPITokenRepo(db),
+		aiConversations:      newAIConversationRepo(db),
 	}
 }
 
@@ -62,10 +70,18 @@ func (s *GormStore) WorkspaceInvitations() store.WorkspaceInvitationRepository {
 	return s.workspaceInvitations
 }
 func (s *GormStore) AuditLogs() store.AuditLogRepository         { return s.auditLogs }
+func (s *GormStore) FlowLogs() store.FlowLogRepository           { return s.flowLogs }
 func (s *GormStore) WorkflowRequests() store.WorkflowRepository  { return s.workflowRequests }
 func (s *GormStore) Policies() store.PolicyRepository            { return s.policies }
 func (s *GormStore) Alerts() store.AlertRepository               { return s.alerts }
 func (s *GormStore) CustomMetrics() store.CustomMetricRepository { return s.customMetrics }
+func (s *GormStore) ServiceAccounts() store.ServiceAccountRepository {
+	return s.serviceAccounts
+}
+func (s *GormStore) APITokens() store.APITokenRepository { return s.apiTokens }
+func (s *GormStore) AIConversations() store.AIConversationRepository {
+	return s.aiConversations
+}
 
 // Tx 在数据库事务中执行 fn，fn 内通过临时 Store 访问所有 Repository。
 func (s *GormStore) Tx(ctx context.Context, fn func(store.Store) error) error {
@@ -119,3 +119,9 @@ func (r *workspaceMemberRepo) UpdateRole(ctx context.Context, workspaceID, userI
 		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
 		Update("role", role).Error
 }
+
+func (r *workspaceMemberRepo) ListBySource(ctx context.Context, userID, source string) ([]*models.WorkspaceMember, error) {
+	return r.Find(ctx, repository.WithUserID(userID), func(db *gorm.DB) *gorm.DB {
+		return db.Where("source = ? AND status != ?", source, models.MemberStatusRemoved)
+	})
+}
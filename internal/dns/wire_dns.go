@@ -17,13 +17,22 @@ package dns
 import (
 	"fmt"
 	"log"
+	"net/netip"
+	"strings"
+	"sync"
 
 	"github.com/miekg/dns"
 )
 
+// recordTTL is the TTL of answers served from the local records.
+const recordTTL = 60
+
 type LinkDNS struct {
 	listenAddr  string
 	upstreamDNS string
+
+	mu      sync.RWMutex
+	records map[string]netip.Addr
 }
 
 type DNSConfig struct {
@@ -47,6 +56,44 @@ func NewNativeDNS(cfg *DNSConfig) *LinkDNS {
 	}
 }
 
+// SetRecords replaces the names answered locally, e.g. the peers reachable
+// through a network peering. Names are matched case-insensitively; other
+// names are forwarded upstream.
+func (l *LinkDNS) SetRecords(records map[string]string) {
+	parsed := make(map[string]netip.Addr, len(records))
+	for name, addr := range records {
+		ip, err := netip.ParseAddr(addr)
+		if err != nil {
+			continue
+		}
+		parsed[dns.Fqdn(strings.ToLower(name))] = ip
+	}
+	l.mu.Lock()
+	l.records = parsed
+	l.mu.Unlock()
+}
+
+// lookup returns the local answer for q, if any.
+func (l *LinkDNS) lookup(q dns.Question) (dns.RR, bool) {
+	l.mu.RLock()
+	ip, ok := l.records[strings.ToLower(q.Name)]
+	l.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	hdr := dns.RR_Header{Name: q.Name, Class: dns.ClassINET, Ttl: recordTTL}
+	switch {
+	case q.Qtype == dns.TypeA && ip.Is4():
+		hdr.Rrtype = dns.TypeA
+		return &dns.A{Hdr: hdr, A: ip.AsSlice()}, true
+	case q.Qtype == dns.TypeAAAA && ip.Is6():
+		hdr.Rrtype = dns.TypeAAAA
+		return &dns.AAAA{Hdr: hdr, AAAA: ip.AsSlice()}, true
+	}
+	// The name exists but has no record of this type.
+	return nil, true
+}
+
 //func loadConfig(filename string) error {
 //	configLock.Lock()
 //	defer configLock.Unlock()
@@ -65,6 +112,12 @@ func (l *LinkDNS) handleDNSRequest(w dns.ResponseWriter, r *dns.Msg) {
 	m.Authoritative = true
 
 	for _, q := range r.Question {
+		if rr, ok := l.lookup(q); ok {
+			if rr != nil {
+				m.Answer = append(m.Answer, rr)
+			}
+			continue
+		}
 		switch q.Qtype {
 		case dns.TypeA:
 			log.Printf("查询域名: %s", q.Name)
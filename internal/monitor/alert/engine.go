@@ -86,10 +86,16 @@ func (e *AlertEngine) evaluateRule(ctx context.Context, rule *models.AlertRule)
 		return nil
 	}
 
+	// Metrics are labelled with the workspace namespace, not its ID.
+	ws, err := e.store.Workspaces().GetByID(ctx, rule.WorkspaceID)
+	if err != nil {
+		return fmt.Errorf("load workspace: %w", err)
+	}
+
 	req := &adapter.QueryRequest{
 		MetricType: rule.MetricType,
 		Labels:     map[string]string{},
-		Namespace:  rule.WorkspaceID,
+		Namespace:  ws.Namespace,
 		TimeRange:  adapter.TimeRange{End: time.Now(), Lookback: rule.Lookback},
 	}
 
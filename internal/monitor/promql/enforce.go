@@ -0,0 +1,227 @@
+// Package promql rewrites user-supplied PromQL so it can only read the
+// series of one tenant.
+//
+// There is no full PromQL parser here: queries are tokenized, and every
+// vector selector is found and given a tenant matcher. Anything the
+// tokenizer does not understand is rejected rather than passed through, so
+// a misread query fails closed.
+package promql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCrossTenant is returned for queries that match the tenant label
+	// with anything other than the tenant's own value.
+	ErrCrossTenant = errors.New("query reads series of another tenant")
+	// ErrUnsupported is returned for syntax the rewriter does not accept.
+	ErrUnsupported = errors.New("unsupported query syntax")
+)
+
+// labelListKeywords are followed by a parenthesised list of label names,
+// not by an expression.
+var labelListKeywords = map[string]bool{
+	"by": true, "without": true, "on": true, "ignoring": true, "group_left": true, "group_right": true,
+}
+
+// keywords are identifiers that are never metric names.
+var keywords = map[string]bool{
+	"bool": true, "offset": true, "and": true, "or": true, "unless": true, "atan2": true,
+	"inf": true, "nan": true,
+	// MetricsQL
+	"default": true, "if": true, "ifnot": true, "limit": true, "keep_metric_names": true,
+}
+
+// EnforceLabel returns query with label="value" added to every vector
+// selector. Selectors that already name label must use = with exactly value.
+func EnforceLabel(query, label, value string) (string, error) {
+	toks, err := lex(query)
+	if err != nil {
+		return "", err
+	}
+	matcher := label + "=" + strconv.Quote(value)
+
+	type insertion struct {
+		pos  int
+		text string
+	}
+	var inserts []insertion
+	selectors := 0
+
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch t.kind {
+		case tokIdent:
+			word := strings.ToLower(t.text)
+			next := peek(toks, i+1)
+			switch {
+			case word == "with" && next.kind == tokLParen:
+				// MetricsQL WITH templates define their own selectors.
+				return "", fmt.Errorf("%w: WITH templates", ErrUnsupported)
+			case labelListKeywords[word]:
+				if next.kind == tokLParen {
+					end, err := skipLabelList(toks, i+1)
+					if err != nil {
+						return "", err
+					}
+					i = end
+				}
+			case keywords[word]:
+			case next.kind == tokLParen:
+				// function or aggregation call
+			case next.kind == tokIdent && labelListKeywords[strings.ToLower(next.text)]:
+				// aggregation with a leading "by (...)"
+			case next.kind == tokLBrace:
+				end, found, err := checkMatchers(toks, i+1, label, value)
+				if err != nil {
+					return "", err
+				}
+				if !found {
+					inserts = append(inserts, insertion{toks[end].start, matcherText(toks, i+1, end, matcher)})
+				}
+				selectors++
+				i = end
+			default:
+				inserts = append(inserts, insertion{t.end, "{" + matcher + "}"})
+				selectors++
+			}
+		case tokLBrace:
+			end, found, err := checkMatchers(toks, i, label, value)
+			if err != nil {
+				return "", err
+			}
+			if !found {
+				inserts = append(inserts, insertion{toks[end].start, matcherText(toks, i, end, matcher)})
+			}
+			selectors++
+			i = end
+		case tokRBrace:
+			return "", fmt.Errorf("%w: unbalanced }", ErrUnsupported)
+		}
+	}
+	if selectors == 0 {
+		return "", fmt.Errorf("%w: query selects no series", ErrUnsupported)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, in := range inserts {
+		b.WriteString(query[last:in.pos])
+		b.WriteString(in.text)
+		last = in.pos
+	}
+	b.WriteString(query[last:])
+	return b.String(), nil
+}
+
+// checkMatchers validates the matcher list opening at toks[open] and
+// reports whether it already pins label. It returns the index of the
+// closing brace.
+func checkMatchers(toks []token, open int, label, value string) (int, bool, error) {
+	found := false
+	i := open + 1
+	for {
+		t := peek(toks, i)
+		if t.kind == tokRBrace {
+			return i, found, nil
+		}
+
+		var name string
+		switch t.kind {
+		case tokIdent:
+			name = t.text
+		case tokString:
+			s, err := unquote(t.text)
+			if err != nil {
+				return 0, false, err
+			}
+			name = s
+		default:
+			return 0, false, fmt.Errorf("%w: expected label matcher, got %q", ErrUnsupported, t.text)
+		}
+
+		op := peek(toks, i+1)
+		if t.kind == tokString && (op.kind == tokComma || op.kind == tokRBrace) {
+			// {"metric_name"}: a quoted metric name
+			i++
+		} else {
+			val := peek(toks, i+2)
+			if op.kind != tokOp || !isMatchOp(op.text) || val.kind != tokString {
+				return 0, false, fmt.Errorf("%w: bad matcher for %q", ErrUnsupported, name)
+			}
+			if name == label {
+				v, err := unquote(val.text)
+				if err != nil {
+					return 0, false, err
+				}
+				if op.text != "=" || v != value {
+					return 0, false, fmt.Errorf("%w: %s%s%s", ErrCrossTenant, name, op.text, val.text)
+				}
+				found = true
+			}
+			i += 3
+		}
+
+		switch peek(toks, i).kind {
+		case tokComma:
+			i++
+		case tokRBrace:
+		default:
+			return 0, false, fmt.Errorf("%w: expected , or } in matchers", ErrUnsupported)
+		}
+	}
+}
+
+// matcherText is the tenant matcher, inserted before the closing brace. It
+// needs a separating comma unless the list is empty or ends with one.
+func matcherText(toks []token, open, close int, matcher string) string {
+	if close == open+1 || toks[close-1].kind == tokComma {
+		return matcher
+	}
+	return "," + matcher
+}
+
+func skipLabelList(toks []token, open int) (int, error) {
+	for i := open + 1; i < len(toks); i++ {
+		switch toks[i].kind {
+		case tokRParen:
+			return i, nil
+		case tokIdent, tokString, tokComma:
+		default:
+			return 0, fmt.Errorf("%w: bad label list", ErrUnsupported)
+		}
+	}
+	return 0, fmt.Errorf("%w: unterminated label list", ErrUnsupported)
+}
+
+func isMatchOp(op string) bool {
+	switch op {
+	case "=", "!=", "=~", "!~":
+		return true
+	}
+	return false
+}
+
+func peek(toks []token, i int) token {
+	if i < len(toks) {
+		return toks[i]
+	}
+	return token{kind: tokEOF}
+}
+
+// unquote decodes a PromQL string literal: "…", '…' or `…`.
+func unquote(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '\'' {
+		body := strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`)
+		s = `"` + strings.ReplaceAll(body, `"`, `\"`) + `"`
+	}
+	v, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: bad string %s", ErrUnsupported, s)
+	}
+	return v, nil
+}
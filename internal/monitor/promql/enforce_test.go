@@ -0,0 +1,76 @@
+package promql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforceLabel_Rewrites(t *testing.T) {
+	tests := []struct {
+		name, query, want string
+	}{
+		{"bare metric", `up`, `up{network_id="wf-a"}`},
+		{"empty matchers", `up{}`, `up{network_id="wf-a"}`},
+		{"existing matchers", `up{job="x"}`, `up{job="x",network_id="wf-a"}`},
+		{"trailing comma", `up{job="x",}`, `up{job="x",network_id="wf-a"}`},
+		{"own tenant kept", `up{network_id="wf-a"}`, `up{network_id="wf-a"}`},
+		{"nameless selector", `{__name__=~"lattice_.*"}`, `{__name__=~"lattice_.*",network_id="wf-a"}`},
+		{"quoted name", `{"up"}`, `{"up",network_id="wf-a"}`},
+		{"range and function", `rate(x_total[5m])`, `rate(x_total{network_id="wf-a"}[5m])`},
+		{"subquery", `max_over_time(rate(x[1m])[10m:1m])`, `max_over_time(rate(x{network_id="wf-a"}[1m])[10m:1m])`},
+		{
+			"aggregation modifiers",
+			`sum by (peer_id) (rate(a[5m])) / on(peer_id) group_left(name) b`,
+			`sum by (peer_id) (rate(a{network_id="wf-a"}[5m])) / on(peer_id) group_left(name) b{network_id="wf-a"}`,
+		},
+		{"trailing by", `sum(a) without (job)`, `sum(a{network_id="wf-a"}) without (job)`},
+		{"offset and bool", `a offset 5m > bool 1e-3`, `a{network_id="wf-a"} offset 5m > bool 1e-3`},
+		{"set operators", `a and b or c unless d`, `a{network_id="wf-a"} and b{network_id="wf-a"} or c{network_id="wf-a"} unless d{network_id="wf-a"}`},
+		{"string args", `label_replace(a, "dst", "$1", "src", "(.*)")`, `label_replace(a{network_id="wf-a"}, "dst", "$1", "src", "(.*)")`},
+		{"recording rule name", `job:x:rate5m`, `job:x:rate5m{network_id="wf-a"}`},
+		{"comment", "a # {network_id!=\"wf-a\"}\n", "a{network_id=\"wf-a\"} # {network_id!=\"wf-a\"}\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := EnforceLabel(tc.query, "network_id", "wf-a")
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestEnforceLabel_CrossTenant(t *testing.T) {
+	for _, q := range []string{
+		`up{network_id="wf-b"}`,
+		`up{network_id!="wf-a"}`,
+		`up{network_id=~"wf-.*"}`,
+		`up{network_id=~"wf-a"}`,
+		`up{network_id!~"wf-a"}`,
+		`{"network_id"="wf-b"}`,
+		`up{network_id='wf-b'}`,
+		`a{network_id="wf-a"} or b{network_id="wf-b"}`,
+	} {
+		_, err := EnforceLabel(q, "network_id", "wf-a")
+		assert.ErrorIs(t, err, ErrCrossTenant, q)
+	}
+}
+
+func TestEnforceLabel_Unsupported(t *testing.T) {
+	for _, q := range []string{
+		``,
+		`1 + 1`,
+		`up{job="x" or network_id="wf-b"}`,
+		`WITH (x = {network_id="wf-b"}) x`,
+		`up{job="x"`,
+		`up}`,
+		`rate(up[{network_id="wf-b"}])`,
+		`"unterminated`,
+		`up; drop`,
+		`sum by (x{a="b"}) (up)`,
+	} {
+		_, err := EnforceLabel(q, "network_id", "wf-a")
+		assert.ErrorIs(t, err, ErrUnsupported, q)
+	}
+}
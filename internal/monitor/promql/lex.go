@@ -0,0 +1,146 @@
+package promql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokLBrace
+	tokRBrace
+	tokLParen
+	tokRParen
+	tokRange // […], kept whole
+	tokComma
+	tokOp
+)
+
+type token struct {
+	kind       tokKind
+	text       string
+	start, end int
+}
+
+// twoCharOps are checked before single characters.
+var twoCharOps = []string{"==", "!=", "<=", ">=", "=~", "!~"}
+
+const oneCharOps = "+-*/%^<>=@"
+
+func lex(q string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(q) {
+		c := q[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '#':
+			for i < len(q) && q[i] != '\n' {
+				i++
+			}
+			continue
+		case isIdentStart(c):
+			for i < len(q) && isIdentChar(q[i]) {
+				i++
+			}
+			toks = append(toks, token{tokIdent, q[start:i], start, i})
+			continue
+		case isDigit(c) || (c == '.' && i+1 < len(q) && isDigit(q[i+1])):
+			// numbers and durations: 1, 1.5, 1e-3, 0x1f, 5m, 1h30m
+			i++
+			for i < len(q) {
+				if isDigit(q[i]) || isLetter(q[i]) || q[i] == '.' || q[i] == '_' {
+					i++
+				} else if (q[i] == '+' || q[i] == '-') && (q[i-1] == 'e' || q[i-1] == 'E') && !strings.HasPrefix(q[start:], "0x") {
+					i++
+				} else {
+					break
+				}
+			}
+			toks = append(toks, token{tokNumber, q[start:i], start, i})
+			continue
+		case c == '"' || c == '\'' || c == '`':
+			i++
+			for i < len(q) && q[i] != c {
+				if q[i] == '\\' && c != '`' {
+					i++
+				}
+				i++
+			}
+			if i >= len(q) {
+				return nil, fmt.Errorf("%w: unterminated string", ErrUnsupported)
+			}
+			i++
+			toks = append(toks, token{tokString, q[start:i], start, i})
+			continue
+		case c == '[':
+			// Ranges and subqueries hold only durations and steps.
+			i++
+			for i < len(q) && q[i] != ']' {
+				if !isRangeChar(q[i]) {
+					return nil, fmt.Errorf("%w: unexpected %q in range", ErrUnsupported, q[i])
+				}
+				i++
+			}
+			if i >= len(q) {
+				return nil, fmt.Errorf("%w: unterminated range", ErrUnsupported)
+			}
+			i++
+			toks = append(toks, token{tokRange, q[start:i], start, i})
+			continue
+		}
+
+		kind := tokOp
+		switch c {
+		case '{':
+			kind = tokLBrace
+		case '}':
+			kind = tokRBrace
+		case '(':
+			kind = tokLParen
+		case ')':
+			kind = tokRParen
+		case ',':
+			kind = tokComma
+		}
+		if kind != tokOp {
+			i++
+			toks = append(toks, token{kind, q[start:i], start, i})
+			continue
+		}
+		op := ""
+		for _, o := range twoCharOps {
+			if strings.HasPrefix(q[i:], o) {
+				op = o
+				break
+			}
+		}
+		if op == "" && strings.IndexByte(oneCharOps, c) >= 0 {
+			op = string(c)
+		}
+		if op == "" {
+			return nil, fmt.Errorf("%w: unexpected %q", ErrUnsupported, c)
+		}
+		i += len(op)
+		toks = append(toks, token{tokOp, op, start, i})
+	}
+	return toks, nil
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+func isIdentStart(c byte) bool { return isLetter(c) || c == '_' || c == ':' }
+func isIdentChar(c byte) bool  { return isIdentStart(c) || isDigit(c) }
+
+func isRangeChar(c byte) bool {
+	return isLetter(c) || isDigit(c) || strings.IndexByte(" .:_+-", c) >= 0
+}
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	_ "embed"
 	"fmt"
+	"strings"
+	"sync"
 	"text/template"
 
+	"github.com/alatticeio/lattice/internal/monitor/promql"
+
 	"github.com/goccy/go-yaml"
 )
 
@@ -14,6 +18,24 @@ var defaultTemplatesFS []byte
 
 var ErrTemplateNotFound = fmt.Errorf("template not found")
 
+// ErrTenantMismatch is returned when a tenant template is rendered for
+// another namespace.
+var ErrTenantMismatch = fmt.Errorf("metric belongs to another workspace")
+
+const (
+	// CustomPrefix starts the metric type of user-defined metrics.
+	CustomPrefix = "custom:"
+	// TenantLabel carries the workspace namespace on every series.
+	TenantLabel = "network_id"
+)
+
+// CustomMetricType returns the metric type under which the custom metric
+// with the given ID is registered.
+func CustomMetricType(id string) string { return CustomPrefix + id }
+
+// IsCustomMetricType reports whether metricType names a custom metric.
+func IsCustomMetricType(metricType string) bool { return strings.HasPrefix(metricType, CustomPrefix) }
+
 // MetricTemplate represents a single PromQL template.
 type MetricTemplate struct {
 	Name        string   `yaml:"-"`
@@ -23,15 +45,24 @@ type MetricTemplate struct {
 	GroupBy     []string `yaml:"group_by"`
 	Description string   `yaml:"description"`
 	System      bool     `yaml:"-"`
+	// Namespace is set for user-defined templates. They only render for
+	// that namespace, and every selector is forced to it.
+	Namespace string `yaml:"-"`
 }
 
 type templateFile struct {
 	Metrics map[string]MetricTemplate `yaml:"metrics"`
 }
 
+// Resolver looks up a user-defined template by metric type. It returns an
+// error wrapping ErrTemplateNotFound when the metric does not exist.
+type Resolver func(metricType string) (*MetricTemplate, error)
+
 // TemplateRegistry holds all loaded metric templates.
 type TemplateRegistry struct {
+	mu        sync.RWMutex
 	templates map[string]*MetricTemplate
+	resolver  Resolver
 }
 
 // NewRegistry creates a registry and loads default embedded templates.
@@ -57,9 +88,66 @@ func (r *TemplateRegistry) loadDefaults() error {
 	return nil
 }
 
+// Register adds or replaces a user-defined template after validating it.
+// Built-in templates cannot be replaced.
+func (r *TemplateRegistry) Register(tpl *MetricTemplate) error {
+	if err := Validate(tpl); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.templates[tpl.Name]; ok && old.System {
+		return fmt.Errorf("metric %s is built in", tpl.Name)
+	}
+	r.templates[tpl.Name] = tpl
+	return nil
+}
+
+// Unregister removes a user-defined template.
+func (r *TemplateRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tpl, ok := r.templates[name]; ok && !tpl.System {
+		delete(r.templates, name)
+	}
+}
+
+// Validate checks that a user-defined template renders for its own
+// namespace and reads only that namespace's series.
+func Validate(tpl *MetricTemplate) error {
+	if tpl.Namespace == "" {
+		return fmt.Errorf("metric %s has no namespace", tpl.Name)
+	}
+	_, err := render(tpl, map[string]any{"Namespace": tpl.Namespace, "Step": "1m"})
+	return err
+}
+
+// SetResolver makes res the source of custom metric templates. It is called
+// on every lookup of a custom metric type, so a metric created, changed or
+// deleted through another replica is seen at once; templates registered
+// locally are then ignored for those types.
+func (r *TemplateRegistry) SetResolver(res Resolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolver = res
+}
+
 // Get returns a metric template by type.
 func (r *TemplateRegistry) Get(metricType string) (*MetricTemplate, error) {
+	r.mu.RLock()
 	tpl, ok := r.templates[metricType]
+	res := r.resolver
+	r.mu.RUnlock()
+	if res != nil && IsCustomMetricType(metricType) {
+		tpl, err := res(metricType)
+		if err != nil {
+			return nil, err
+		}
+		if err := Validate(tpl); err != nil {
+			return nil, err
+		}
+		return tpl, nil
+	}
 	if !ok {
 		return nil, fmt.Errorf("metric template not found: %s: %w", metricType, ErrTemplateNotFound)
 	}
@@ -72,6 +160,15 @@ func (r *TemplateRegistry) Render(metricType string, params map[string]any) (str
 	if err != nil {
 		return "", err
 	}
+	return render(tpl, params)
+}
+
+// render executes tpl. The output of a user-defined template is rewritten
+// so that every selector matches its namespace, whatever the parameters.
+func render(tpl *MetricTemplate, params map[string]any) (string, error) {
+	if tpl.Namespace != "" && params["Namespace"] != tpl.Namespace {
+		return "", ErrTenantMismatch
+	}
 	t, err := template.New("promql").Parse(tpl.Query)
 	if err != nil {
 		return "", fmt.Errorf("parse template: %w", err)
@@ -80,5 +177,8 @@ func (r *TemplateRegistry) Render(metricType string, params map[string]any) (str
 	if err := t.Execute(&buf, params); err != nil {
 		return "", fmt.Errorf("execute template: %w", err)
 	}
-	return buf.String(), nil
+	if tpl.Namespace == "" {
+		return buf.String(), nil
+	}
+	return promql.EnforceLabel(buf.String(), TenantLabel, tpl.Namespace)
 }
@@ -40,3 +40,44 @@ func TestRender_Throughput(t *testing.T) {
 	assert.Contains(t, promql, "lattice_node_traffic_bytes_total")
 	assert.Contains(t, promql, `direction="tx"`)
 }
+
+func TestRegister_CustomMetricIsTenantScoped(t *testing.T) {
+	r, err := NewRegistry()
+	require.NoError(t, err)
+
+	tpl := &MetricTemplate{
+		Name:       CustomMetricType("m1"),
+		Query:      `sum(rate(lattice_node_traffic_bytes_total{direction="{{.direction}}"}[5m]))`,
+		ResultType: "scalar",
+		Namespace:  "wf-a",
+	}
+	require.NoError(t, r.Register(tpl))
+
+	promql, err := r.Render(tpl.Name, map[string]any{"Namespace": "wf-a", "direction": "tx"})
+	require.NoError(t, err)
+	assert.Equal(t, `sum(rate(lattice_node_traffic_bytes_total{direction="tx",network_id="wf-a"}[5m]))`, promql)
+
+	// Label values cannot smuggle in selectors for other tenants.
+	_, err = r.Render(tpl.Name, map[string]any{"Namespace": "wf-a", "direction": `tx"} or lattice_node_traffic_bytes_total{network_id="wf-b`})
+	require.Error(t, err)
+
+	_, err = r.Render(tpl.Name, map[string]any{"Namespace": "wf-b"})
+	assert.ErrorIs(t, err, ErrTenantMismatch)
+
+	r.Unregister(tpl.Name)
+	_, err = r.Get(tpl.Name)
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestRegister_Rejects(t *testing.T) {
+	r, err := NewRegistry()
+	require.NoError(t, err)
+
+	assert.Error(t, r.Register(&MetricTemplate{Name: "online_count", Query: "up", Namespace: "wf-a"}), "built-in replaced")
+	assert.Error(t, r.Register(&MetricTemplate{Name: "x", Query: "up"}), "no namespace")
+	assert.Error(t, r.Register(&MetricTemplate{Name: "x", Query: `up{network_id="wf-b"}`, Namespace: "wf-a"}), "cross-tenant query")
+
+	r.Unregister("online_count")
+	_, err = r.Get("online_count")
+	assert.NoError(t, err, "built-in unregistered")
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminHandler(t *testing.T) {
+	sm := NewSessionManager()
+	stream := &mockStream{}
+	sm.Register(7, &Session{ID: 7, Stream: stream, Type: "TCP"})
+	h := AdminHandler(sm, "secret")
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do("GET", "/sessions", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bad token: code %d", rec.Code)
+	}
+	rec := do("GET", "/sessions", "secret")
+	var list []SessionInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 1 || list[0].ID != 7 {
+		t.Fatalf("list: %d %s", rec.Code, rec.Body)
+	}
+	if rec = do("DELETE", "/sessions/7", "secret"); rec.Code != http.StatusNoContent || !stream.closed {
+		t.Fatalf("kick: code %d closed %v", rec.Code, stream.closed)
+	}
+	if rec = do("DELETE", "/sessions/8", "secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("kick unknown: code %d", rec.Code)
+	}
+	if rec = do("DELETE", "/sessions/x", "secret"); rec.Code != http.StatusBadRequest {
+		t.Errorf("kick invalid: code %d", rec.Code)
+	}
+}
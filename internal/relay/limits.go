@@ -0,0 +1,95 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// minByteBurst keeps a bandwidth limit from rejecting large frames outright:
+// the byte bucket always holds at least one maximum-size frame.
+const minByteBurst = 64*1024 + HeaderSize
+
+// Limits caps what a relay forwards. Session limits apply to each session
+// on its own; namespace limits are shared by all sessions that registered
+// with the same namespace. Zero means unlimited. Frames over a limit are
+// dropped, as a congested UDP path would.
+type Limits struct {
+	SessionBandwidth   int64 // bytes per second
+	SessionFrameRate   int   // frames per second
+	NamespaceBandwidth int64 // bytes per second
+	NamespaceFrameRate int   // frames per second
+}
+
+// minLimit returns the tighter of two limits, where zero means unlimited.
+func minLimit(a, b int64) int64 {
+	if a <= 0 {
+		return b
+	}
+	if b <= 0 {
+		return a
+	}
+	return min(a, b)
+}
+
+// limiter is a byte and a frame token bucket. A nil bucket is unlimited.
+type limiter struct {
+	bytes  *rate.Limiter
+	frames *rate.Limiter
+}
+
+// newLimiter returns nil when neither limit is set.
+func newLimiter(bandwidth int64, frameRate int) *limiter {
+	if bandwidth <= 0 && frameRate <= 0 {
+		return nil
+	}
+	l := &limiter{}
+	if bandwidth > 0 {
+		l.bytes = rate.NewLimiter(rate.Limit(bandwidth), max(int(bandwidth), minByteBurst))
+	}
+	if frameRate > 0 {
+		l.frames = rate.NewLimiter(rate.Limit(frameRate), frameRate)
+	}
+	return l
+}
+
+// allow takes one frame of n bytes. Tokens are only taken when both
+// buckets have room.
+func (l *limiter) allow(now time.Time, n int) bool {
+	if l == nil {
+		return true
+	}
+	var taken []*rate.Reservation
+	for _, b := range []struct {
+		lim *rate.Limiter
+		n   int
+	}{{l.frames, 1}, {l.bytes, n}} {
+		if b.lim == nil {
+			continue
+		}
+		r := b.lim.ReserveN(now, b.n)
+		if !r.OK() || r.DelayFrom(now) > 0 {
+			r.CancelAt(now)
+			for _, t := range taken {
+				t.CancelAt(now)
+			}
+			return false
+		}
+		taken = append(taken, r)
+	}
+	return true
+}
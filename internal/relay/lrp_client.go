@@ -16,6 +16,7 @@ package relay
 
 import (
 	"context"
+	"encoding/json"
 	"sync/atomic"
 
 	"github.com/alatticeio/lattice/internal/agent/infra"
@@ -51,6 +52,21 @@ type lrpClient struct {
 	onMessage func(ctx context.Context, remoteId infra.PeerID, packet *grpc.SignalPacket) error
 	probeCh   chan *Task
 	seq       atomic.Uint32
+	ticket    string
+}
+
+// ClientOption configures an LRP client.
+type ClientOption func(*lrpClient)
+
+// WithTicket presents the relay ticket the manager issued to the peer. The
+// relay takes the peer's workspace and bandwidth limits from it.
+func WithTicket(ticket string) ClientOption {
+	return func(c *lrpClient) { c.ticket = ticket }
+}
+
+// registerInfo is what the client reports to the relay on registration.
+func (c *lrpClient) registerInfo() RegisterInfo {
+	return RegisterInfo{Ticket: c.ticket}
 }
 
 func (c *lrpClient) nextSeq() uint16 {
@@ -75,15 +91,20 @@ func (c *lrpClient) probeWorker() {
 	}
 }
 
-// register sends a Register frame on the given writer.
-func (c *lrpClient) register(w writer) error {
+// register sends a Register frame on the given writer. withInfo appends
+// RegisterInfo as the payload; see RegisterInfo for when that is safe.
+func (c *lrpClient) register(w writer, withInfo bool) error {
+	var payload []byte
+	if info := c.registerInfo(); withInfo && info != (RegisterInfo{}) {
+		payload, _ = json.Marshal(info)
+	}
 	h := &Header{
 		Seq:        c.nextSeq(),
-		PayloadLen: 0,
+		PayloadLen: uint32(len(payload)),
 		Cmd:        Register,
 		ToID:       uint32(c.localId.ToUint64()),
 	}
-	_, err := w.Write(h.Marshal())
+	_, err := w.Write(append(h.Marshal(), payload...))
 	return err
 }
 
@@ -43,7 +43,7 @@ type QUICClient struct {
 }
 
 // NewQUICClient creates a new QUIC LRP client, connects, and registers.
-func NewQUICClient(ctx context.Context, localID infra.PeerID, url string, onMessage func(ctx context.Context, remoteId infra.PeerID, packet *grpc.SignalPacket) error) (*QUICClient, error) {
+func NewQUICClient(ctx context.Context, localID infra.PeerID, url string, onMessage func(ctx context.Context, remoteId infra.PeerID, packet *grpc.SignalPacket) error, opts ...ClientOption) (*QUICClient, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	c := &QUICClient{
 		lrpClient: &lrpClient{
@@ -57,6 +57,9 @@ func NewQUICClient(ctx context.Context, localID infra.PeerID, url string, onMess
 		},
 		ready: make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(c.lrpClient)
+	}
 
 	go c.probeWorker()
 
@@ -94,7 +97,7 @@ func (c *QUICClient) Connect() error {
 	c.conn = conn
 	c.control = ctrl
 
-	if err = c.register(ctrl); err != nil {
+	if err = c.register(ctrl, true); err != nil {
 		conn.CloseWithError(0, "register failed") //nolint:errcheck
 		return err
 	}
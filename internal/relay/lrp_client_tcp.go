@@ -47,7 +47,7 @@ type TCPClient struct {
 }
 
 // NewTCPClient creates a new TCP LRP client, connects, and registers.
-func NewTCPClient(ctx context.Context, localID infra.PeerID, url string, onMessage func(ctx context.Context, remoteId infra.PeerID, packet *grpc.SignalPacket) error) (*TCPClient, error) {
+func NewTCPClient(ctx context.Context, localID infra.PeerID, url string, onMessage func(ctx context.Context, remoteId infra.PeerID, packet *grpc.SignalPacket) error, opts ...ClientOption) (*TCPClient, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	c := &TCPClient{
 		lrpClient: &lrpClient{
@@ -62,6 +62,9 @@ func NewTCPClient(ctx context.Context, localID infra.PeerID, url string, onMessa
 		sendCh: make(chan []byte, sendChanDepth),
 		ready:  make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(c.lrpClient)
+	}
 
 	go c.probeWorker()
 
@@ -91,6 +94,9 @@ func (c *TCPClient) Connect() error {
 	}
 	req.Header.Set("Upgrade", "lrp")
 	req.Header.Set("Connection", "Upgrade")
+	if c.ticket != "" {
+		req.Header.Set(ticketHeader, c.ticket)
+	}
 
 	if err = req.Write(conn); err != nil {
 		conn.Close()
@@ -112,7 +118,7 @@ func (c *TCPClient) Connect() error {
 	c.writer = bufio.NewWriterSize(conn, writerBufSize)
 	c.mu.Unlock()
 
-	if err = c.register(c); err != nil {
+	if err = c.register(c, false); err != nil {
 		conn.Close()
 		return err
 	}
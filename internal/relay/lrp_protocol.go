@@ -16,7 +16,9 @@ package relay
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"errors"
+	"net/http"
 )
 
 const HeaderSize = 12
@@ -29,6 +31,36 @@ const (
 	Probe     uint8 = 0x04
 )
 
+// ticketHeader carries the peer's relay ticket on the TCP upgrade request.
+// Relays that predate it ignore the header.
+const ticketHeader = "Lattice-Relay-Ticket"
+
+// maxRegisterPayload bounds the Register payload a relay accepts.
+const maxRegisterPayload = 1024
+
+// RegisterInfo is the optional JSON payload of a Register frame. Agents only
+// send it on QUIC, where relays that predate it skip it harmlessly; on TCP
+// it would desynchronise the stream of such relays, so the fields go in
+// ticketHeader instead.
+type RegisterInfo struct {
+	// Ticket is the signed Ticket the manager issued to the peer.
+	Ticket string `json:"ticket,omitempty"`
+}
+
+// registerInfoFromHeader reads the RegisterInfo a TCP client sends as
+// upgrade request headers.
+func registerInfoFromHeader(h http.Header) RegisterInfo {
+	return RegisterInfo{Ticket: h.Get(ticketHeader)}
+}
+
+// parseRegisterInfo decodes a Register payload. Malformed payloads yield an
+// empty RegisterInfo, which carries no ticket.
+func parseRegisterInfo(b []byte) RegisterInfo {
+	var info RegisterInfo
+	_ = json.Unmarshal(b, &info)
+	return info
+}
+
 // Header is the 12-byte LRP frame header (little-endian).
 // Offset 0-1:   Seq        — frame sequence number
 // Offset 2-5:   PayloadLen — payload size in bytes
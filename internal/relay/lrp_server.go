@@ -39,7 +39,15 @@ func NewServer(flags *config.Config) *Server {
 		log:        internallog.GetLogger("bolt"),
 		sessionMgr: NewSessionManager(),
 	}
+	s.sessionMgr.SetLimits(Limits{
+		SessionBandwidth:   flags.RelaySessionBandwidth,
+		SessionFrameRate:   flags.RelaySessionFrameRate,
+		NamespaceBandwidth: flags.RelayNamespaceBandwidth,
+		NamespaceFrameRate: flags.RelayNamespaceFrameRate,
+	})
+	s.sessionMgr.SetSecret(flags.RelaySecret)
 	mux := http.NewServeMux()
+	mux.HandleFunc("/lrp/v1/upgrade", s.boltUpgradeHandler)
 	mux.HandleFunc("/bolt/v1/upgrade", s.boltUpgradeHandler)
 
 	httpServer := &http.Server{
@@ -71,13 +79,15 @@ func (s *Server) Start() error {
 }
 
 func (s *Server) boltUpgradeHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Header.Get("Upgrade") != "bolt" {
-		http.Error(w, "Expected Bolt Upgrade", http.StatusBadRequest)
+	// Agents upgrade to "lrp"; "bolt" is kept for older clients.
+	proto := r.Header.Get("Upgrade")
+	if proto != "lrp" && proto != "bolt" {
+		http.Error(w, "Expected LRP Upgrade", http.StatusBadRequest)
 		return
 	}
 
 	rc := http.NewResponseController(w)
-	w.Header().Set("Upgrade", "bolt")
+	w.Header().Set("Upgrade", proto)
 	w.Header().Set("Connection", "Upgrade")
 	w.WriteHeader(http.StatusSwitchingProtocols)
 
@@ -93,10 +103,10 @@ func (s *Server) boltUpgradeHandler(w http.ResponseWriter, r *http.Request) {
 		_ = tcpConn.SetNoDelay(true)
 	}
 
-	s.handleBoltSession(conn, bufrw)
+	s.handleBoltSession(conn, bufrw, registerInfoFromHeader(r.Header))
 }
 
-func (s *Server) handleBoltSession(conn net.Conn, bufrw *bufio.ReadWriter) {
+func (s *Server) handleBoltSession(conn net.Conn, bufrw *bufio.ReadWriter, info RegisterInfo) {
 	stream := &ReadWriterConn{Conn: conn, ReadWriter: bufrw}
 	defer stream.Close()
 
@@ -114,13 +124,42 @@ func (s *Server) handleBoltSession(conn net.Conn, bufrw *bufio.ReadWriter) {
 		return
 	}
 
+	if header.PayloadLen > 0 {
+		if header.PayloadLen > maxRegisterPayload {
+			s.log.Warn("register payload too large", "len", header.PayloadLen)
+			return
+		}
+		payload := make([]byte, header.PayloadLen)
+		if _, err = io.ReadFull(stream, payload); err != nil {
+			s.log.Error("failed to read Register payload", err)
+			return
+		}
+		if p := parseRegisterInfo(payload); p != (RegisterInfo{}) {
+			info = p
+		}
+	}
+
+	if header.ToID == RelayID {
+		s.serveProbes(conn, stream)
+		return
+	}
+
 	fromId := uint64(header.ToID)
-	s.sessionMgr.Register(fromId, &Session{
-		ID:     fromId,
-		Stream: stream,
-		Type:   "TCP",
-	})
-	defer s.sessionMgr.Unregister(fromId)
+	ticket, err := s.sessionMgr.admit(fromId, info.Ticket)
+	if err != nil {
+		s.log.Warn("session rejected", "from", fromId, "err", err)
+		return
+	}
+	session := &Session{
+		ID:               fromId,
+		Stream:           stream,
+		Type:             "TCP",
+		Namespace:        ticket.Namespace,
+		EgressBandwidth:  ticket.EgressBandwidth,
+		IngressBandwidth: ticket.IngressBandwidth,
+	}
+	s.sessionMgr.Register(fromId, session)
+	defer s.sessionMgr.release(session)
 
 	_ = conn.SetReadDeadline(time.Time{})
 	s.log.Info("session registered", "from", fromId)
@@ -155,9 +194,35 @@ func (s *Server) handleBoltSession(conn net.Conn, bufrw *bufio.ReadWriter) {
 				}
 			}
 
-			if relayErr := s.sessionMgr.Relay(uint64(h.ToID), frame); relayErr != nil {
+			if relayErr := s.sessionMgr.relayFrom(session, uint64(h.ToID), frame); relayErr != nil {
 				s.log.Warn("relay failed", "from", fromId, "to", h.ToID, "err", relayErr)
 			}
 		}
 	}
 }
+
+// serveProbes answers Probe frames on a probe-only session. The session is
+// never registered, so it cannot take over a peer's ID.
+func (s *Server) serveProbes(conn net.Conn, stream *ReadWriterConn) {
+	headBuf := make([]byte, HeaderSize)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+		if _, err := io.ReadFull(stream, headBuf); err != nil {
+			return
+		}
+		h, err := Unmarshal(headBuf)
+		if err != nil || h.PayloadLen > maxProbePayload {
+			return
+		}
+		payload := make([]byte, h.PayloadLen)
+		if _, err = io.ReadFull(stream, payload); err != nil {
+			return
+		}
+		if h.Cmd != Probe {
+			continue
+		}
+		if _, err = stream.Write(probeReplyFrame(s.sessionMgr, h.Seq, payload)); err != nil {
+			return
+		}
+	}
+}
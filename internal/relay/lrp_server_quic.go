@@ -113,18 +113,50 @@ func (s *QUICServer) handleConn(conn *quic.Conn) {
 		return
 	}
 
+	if h.ToID == RelayID {
+		s.serveProbes(conn)
+		return
+	}
+
+	// The Register payload, when present, carries RegisterInfo.
+	var info RegisterInfo
+	if h.PayloadLen > 0 {
+		if h.PayloadLen > maxRegisterPayload {
+			s.log.Warn("register payload too large", "len", h.PayloadLen)
+			return
+		}
+		payload := make([]byte, h.PayloadLen)
+		if _, err = io.ReadFull(ctrl, payload); err != nil {
+			s.log.Error("failed to read Register payload", err)
+			return
+		}
+		info = parseRegisterInfo(payload)
+	}
+
 	fromId := uint64(h.ToID)
-	ctrlStream := &quicControlStream{stream: ctrl, conn: conn}
-	s.sessionMgr.RegisterQUIC(fromId, ctrlStream, conn)
-	defer s.sessionMgr.Unregister(fromId)
+	ticket, err := s.sessionMgr.admit(fromId, info.Ticket)
+	if err != nil {
+		s.log.Warn("QUIC session rejected", "from", fromId, "err", err)
+		return
+	}
+	session := &Session{
+		ID:               fromId,
+		Stream:           &quicControlStream{stream: ctrl, conn: conn},
+		Namespace:        ticket.Namespace,
+		EgressBandwidth:  ticket.EgressBandwidth,
+		IngressBandwidth: ticket.IngressBandwidth,
+	}
+	s.sessionMgr.RegisterQUIC(fromId, session, conn)
+	defer s.sessionMgr.release(session)
 
 	s.log.Info("QUIC session registered", "from", fromId)
 
-	go s.relayDatagrams(conn, fromId)
+	go s.relayDatagrams(conn, session)
 	s.handleControlStream(ctrl, fromId)
 }
 
-func (s *QUICServer) relayDatagrams(conn *quic.Conn, fromId uint64) {
+func (s *QUICServer) relayDatagrams(conn *quic.Conn, session *Session) {
+	fromId := session.ID
 	for {
 		data, err := conn.ReceiveDatagram(context.Background())
 		if err != nil {
@@ -134,21 +166,24 @@ func (s *QUICServer) relayDatagrams(conn *quic.Conn, fromId uint64) {
 
 		if len(data) < HeaderSize {
 			s.log.Warn("datagram too short", "from", fromId, "len", len(data))
+			quicDatagramDrops.WithLabelValues("too_short").Inc()
 			continue
 		}
 
 		h, err := Unmarshal(data[:HeaderSize])
 		if err != nil {
 			s.log.Warn("invalid datagram header", "from", fromId, "err", err)
+			quicDatagramDrops.WithLabelValues("bad_header").Inc()
 			continue
 		}
 
 		if h.Cmd != Forward && h.Cmd != Probe {
 			s.log.Debug("ignoring non-data datagram", "cmd", h.Cmd)
+			quicDatagramDrops.WithLabelValues("unexpected_command").Inc()
 			continue
 		}
 
-		if relayErr := s.sessionMgr.Relay(uint64(h.ToID), data); relayErr != nil {
+		if relayErr := s.sessionMgr.relayFrom(session, uint64(h.ToID), data); relayErr != nil {
 			s.log.Warn("datagram relay failed", "from", fromId, "to", h.ToID, "err", relayErr)
 		} else {
 			s.log.Debug("datagram relayed", "from", fromId, "to", h.ToID)
@@ -156,6 +191,26 @@ func (s *QUICServer) relayDatagrams(conn *quic.Conn, fromId uint64) {
 	}
 }
 
+// serveProbes answers Probe datagrams on a probe-only connection. The
+// connection is never registered, so it cannot take over a peer's ID.
+func (s *QUICServer) serveProbes(conn *quic.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for {
+		data, err := conn.ReceiveDatagram(ctx)
+		if err != nil {
+			return
+		}
+		h, err := Unmarshal(data)
+		if err != nil || h.Cmd != Probe || len(data)-HeaderSize > maxProbePayload {
+			continue
+		}
+		if err = conn.SendDatagram(probeReplyFrame(s.sessionMgr, h.Seq, data[HeaderSize:])); err != nil {
+			return
+		}
+	}
+}
+
 func (s *QUICServer) handleControlStream(ctrl *quic.Stream, fromId uint64) {
 	headBuf := make([]byte, HeaderSize)
 	for {
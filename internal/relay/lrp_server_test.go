@@ -0,0 +1,108 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/grpc"
+
+	"github.com/quic-go/quic-go"
+)
+
+func noopMessage(context.Context, infra.PeerID, *grpc.SignalPacket) error { return nil }
+
+// waitSession polls until id is registered on sm.
+func waitSession(t *testing.T, sm *SessionManager, id uint64) *Session {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if s := sm.Get(id); s != nil {
+			return s
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("session %d not registered", id)
+	return nil
+}
+
+func TestServer_RegisterNamespace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// TCP: the ticket travels in the upgrade request.
+	s := NewServer(&config.Config{RelaySecret: "secret"})
+	ts := httptest.NewServer(http.HandlerFunc(s.boltUpgradeHandler))
+	defer ts.Close()
+	ticket := Ticket{PeerID: 42, Namespace: "team-a"}.Sign("secret")
+	tc, err := NewTCPClient(ctx, infra.FromUint64(42), strings.TrimPrefix(ts.URL, "http://"), noopMessage, WithTicket(ticket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	if got := waitSession(t, s.sessionMgr, 42); got.Namespace != "team-a" || got.Type != "TCP" {
+		t.Errorf("TCP session = %+v, want namespace team-a", got)
+	}
+
+	// A ticket issued to another peer is rejected.
+	bad, err := NewTCPClient(ctx, infra.FromUint64(44), strings.TrimPrefix(ts.URL, "http://"), noopMessage, WithTicket(ticket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bad.Close()
+	time.Sleep(100 * time.Millisecond)
+	if s.sessionMgr.Get(44) != nil {
+		t.Error("session registered with another peer's ticket")
+	}
+
+	// QUIC: the ticket travels in the Register payload.
+	tlsCfg, err := GenerateSelfSignedTLS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := quic.ListenAddr("127.0.0.1:0", tlsCfg, &quic.Config{EnableDatagrams: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	sm := NewSessionManager()
+	sm.SetSecret("secret")
+	qs := NewQUICServer(sm)
+	go func() {
+		for {
+			conn, err := ln.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			go qs.handleConn(conn)
+		}
+	}()
+	ticket = Ticket{PeerID: 43, Namespace: "team-b"}.Sign("secret")
+	qc, err := NewQUICClient(ctx, infra.FromUint64(43), ln.Addr().String(), noopMessage, WithTicket(ticket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer qc.Close()
+	if got := waitSession(t, qs.sessionMgr, 43); got.Namespace != "team-b" || got.Type != "QUIC" {
+		t.Errorf("QUIC session = %+v, want namespace team-b", got)
+	}
+}
@@ -0,0 +1,615 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	internallog "github.com/alatticeio/lattice/internal/agent/log"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+// Relay mesh: relay instances share a session directory over NATS and
+// forward frames to the instance that holds the destination session, so two
+// peers attached to different relays can still reach each other.
+//
+// Directory updates are published on meshSubjectSessions. Every instance
+// announces attach/detach events as they happen and its full session list
+// every meshSyncInterval; entries not refreshed within meshEntryTTL expire.
+// A starting instance publishes on meshSubjectHello so the others send their
+// full list immediately.
+//
+// Announcements carry the time they were sent and an HMAC under the secret
+// shared by the relays; unsigned, forged or stale ones are ignored.
+//
+// Frames travel over one-way TCP links. The sending relay dials the owner's
+// mesh address and the owner answers with the meshHandshake line and a
+// random nonce. The sender replies with the meshHandshake line, its ID and
+// the HMAC of the nonce and its ID, then writes frames, each prefixed with
+// the sending session's ID and namespace so the owner can apply its own
+// limits to them. The receiving relay only delivers to local sessions, so a
+// frame crosses the mesh at most once.
+const (
+	meshSubjectSessions = "lattice.relay.mesh.sessions"
+	meshSubjectHello    = "lattice.relay.mesh.hello"
+
+	meshSyncInterval = 30 * time.Second
+	meshEntryTTL     = 3 * meshSyncInterval
+
+	meshHandshake        = "LRPMESH/2"
+	meshHandshakeTimeout = 10 * time.Second
+	meshDialTimeout      = 3 * time.Second
+	meshWriteTimeout     = 5 * time.Second
+
+	// meshMaxPayload bounds a single forwarded frame; WireGuard packets are
+	// well below it.
+	meshMaxPayload = 64 * 1024
+
+	// meshPrefixSize is the size of the prefix of a forwarded frame: the
+	// sender's session ID (uint32) and the length of its namespace (uint8).
+	meshPrefixSize = 5
+
+	// meshNonceSize is the size of the link challenge.
+	meshNonceSize = 16
+	// meshMaxClockSkew bounds how far the time of an announcement may be
+	// from the receiver's clock.
+	meshMaxClockSkew = meshSyncInterval
+
+	meshOpAttach = "attach"
+	meshOpDetach = "detach"
+	meshOpSync   = "sync"
+)
+
+// errMeshLinkPending is returned while the link to the owning relay is being
+// dialled; the frame is dropped and WireGuard retransmits.
+var errMeshLinkPending = errors.New("lrp: mesh link to owning relay not ready")
+
+// errMeshAuth is returned for a link or announcement that does not carry a
+// valid HMAC under the mesh secret.
+var errMeshAuth = errors.New("lrp: relay mesh authentication failed")
+
+// MeshConfig configures a relay's membership in the relay mesh.
+type MeshConfig struct {
+	// RelayID identifies this instance in the mesh. Defaults to the hostname.
+	RelayID string
+	// ListenAddr is where other relays connect to deliver frames.
+	ListenAddr string
+	// AdvertiseAddr is the address announced to other relays. Defaults to
+	// ListenAddr, with the hostname filled in when the host is unspecified.
+	AdvertiseAddr string
+	// NatsURL is the NATS server shared by all relays of the mesh.
+	NatsURL string
+	// Secret authenticates links and announcements between relays. All
+	// relays of the mesh must share it.
+	Secret string
+}
+
+// meshAnnounce is the directory message published on meshSubjectSessions.
+type meshAnnounce struct {
+	Relay string   `json:"relay"`
+	Addr  string   `json:"addr"`
+	Op    string   `json:"op"`
+	Peers []uint64 `json:"peers"`
+	// Time is when the announcement was sent, in Unix nanoseconds.
+	Time int64 `json:"time"`
+	// MAC is the hex HMAC of the announcement with MAC empty.
+	MAC string `json:"mac,omitempty"`
+}
+
+// sign sets a.MAC under secret.
+func (a *meshAnnounce) sign(secret string) {
+	a.MAC = ""
+	body, _ := json.Marshal(a)
+	a.MAC = hex.EncodeToString(hmacSum(secret, body))
+}
+
+// verify checks a.MAC under secret and that a was sent around now.
+func (a meshAnnounce) verify(secret string, now time.Time) error {
+	mac, err := hex.DecodeString(a.MAC)
+	if err != nil {
+		return errMeshAuth
+	}
+	a.MAC = ""
+	body, _ := json.Marshal(a)
+	if !hmac.Equal(mac, hmacSum(secret, body)) {
+		return errMeshAuth
+	}
+	if skew := now.Sub(time.Unix(0, a.Time)); skew > meshMaxClockSkew || skew < -meshMaxClockSkew {
+		return fmt.Errorf("relay mesh: announcement from %s is %s off", a.Relay, skew)
+	}
+	return nil
+}
+
+// linkMAC is the HMAC a relay presents to open a link: it binds the
+// listener's nonce to the dialling relay's ID.
+func linkMAC(secret, nonce, relay string) string {
+	return hex.EncodeToString(hmacSum(secret, []byte(nonce+" "+relay)))
+}
+
+type meshEntry struct {
+	relay   string
+	addr    string
+	expires time.Time
+}
+
+// meshLink is an outbound frame link to another relay.
+type meshLink struct {
+	relay string
+	conn  net.Conn
+	mu    sync.Mutex
+}
+
+func (l *meshLink) write(frame []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.conn.SetWriteDeadline(time.Now().Add(meshWriteTimeout))
+	_, err := l.conn.Write(frame)
+	return err
+}
+
+// Mesh joins a SessionManager to the relay mesh. It implements Forwarder.
+type Mesh struct {
+	log   *internallog.Logger
+	cfg   MeshConfig
+	local *SessionManager
+	nc    *natsgo.Conn
+	ln    net.Listener
+
+	mu      sync.RWMutex
+	dir     map[uint64]meshEntry
+	links   map[string]*meshLink
+	dialing map[string]bool
+	closed  bool
+}
+
+// NewMesh starts the mesh listener, connects to NATS and installs itself as
+// the forwarder and session observer of local. Call Run to start announcing.
+func NewMesh(cfg MeshConfig, local *SessionManager) (*Mesh, error) {
+	if cfg.NatsURL == "" {
+		return nil, errors.New("relay mesh: NATS URL is required")
+	}
+	if cfg.Secret == "" {
+		return nil, errors.New("relay mesh: secret is required")
+	}
+	if cfg.RelayID == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("relay mesh: relay id: %w", err)
+		}
+		cfg.RelayID = host
+	}
+
+	ln, err := net.Listen("tcp", cfg.ListenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("relay mesh: listen %s: %w", cfg.ListenAddr, err)
+	}
+	if cfg.AdvertiseAddr == "" {
+		cfg.AdvertiseAddr = advertiseAddr(ln.Addr())
+	}
+
+	m := &Mesh{
+		log:     internallog.GetLogger("relay-mesh"),
+		cfg:     cfg,
+		local:   local,
+		ln:      ln,
+		dir:     make(map[uint64]meshEntry),
+		links:   make(map[string]*meshLink),
+		dialing: make(map[string]bool),
+	}
+
+	m.nc, err = natsgo.Connect(cfg.NatsURL,
+		natsgo.Name("lattice-relay-"+cfg.RelayID),
+		natsgo.MaxReconnects(-1),
+		natsgo.ReconnectHandler(func(*natsgo.Conn) { m.announceAll() }),
+	)
+	if err != nil {
+		ln.Close() //nolint:errcheck
+		return nil, fmt.Errorf("relay mesh: connect NATS: %w", err)
+	}
+	if _, err = m.nc.Subscribe(meshSubjectSessions, m.onAnnounce); err == nil {
+		_, err = m.nc.Subscribe(meshSubjectHello, func(*natsgo.Msg) { m.announceAll() })
+	}
+	if err != nil {
+		m.nc.Close()
+		ln.Close() //nolint:errcheck
+		return nil, fmt.Errorf("relay mesh: subscribe: %w", err)
+	}
+
+	local.SetForwarder(m)
+	local.SetObserver(m.onSession)
+	return m, nil
+}
+
+// advertiseAddr turns a listener address into one other relays can dial.
+func advertiseAddr(addr net.Addr) string {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		if h, err := os.Hostname(); err == nil {
+			host = h
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// ID returns the relay ID announced to the mesh.
+func (m *Mesh) ID() string {
+	return m.cfg.RelayID
+}
+
+// Run accepts mesh links and keeps the directory fresh until ctx is done.
+func (m *Mesh) Run(ctx context.Context) {
+	m.log.Info("relay mesh joined", "relay", m.cfg.RelayID, "addr", m.cfg.AdvertiseAddr)
+	go m.acceptLoop()
+
+	_ = m.nc.Publish(meshSubjectHello, nil)
+	m.announceAll()
+
+	ticker := time.NewTicker(meshSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.Close()
+			return
+		case now := <-ticker.C:
+			m.announceAll()
+			m.expire(now)
+			m.local.expireRemotes(now.Add(-meshEntryTTL))
+		}
+	}
+}
+
+// Close leaves the mesh and closes all links.
+func (m *Mesh) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	links := m.links
+	m.links = make(map[string]*meshLink)
+	m.mu.Unlock()
+
+	m.announce(meshOpSync, nil)
+	m.nc.Close()
+	m.ln.Close() //nolint:errcheck
+	for _, l := range links {
+		l.conn.Close() //nolint:errcheck
+	}
+	meshLinks.Set(0)
+}
+
+// Forward sends frame from the local session from to the relay that holds
+// toID.
+func (m *Mesh) Forward(from *Session, toID uint64, frame []byte) error {
+	m.mu.RLock()
+	e, ok := m.dir[toID]
+	m.mu.RUnlock()
+	if !ok || time.Now().After(e.expires) {
+		meshForwardErrors.WithLabelValues("unknown_peer").Inc()
+		return errTargetNotFound
+	}
+
+	l := m.link(e.relay, e.addr)
+	if l == nil {
+		meshForwardErrors.WithLabelValues("link_pending").Inc()
+		return errMeshLinkPending
+	}
+	if len(from.Namespace) > 255 {
+		meshForwardErrors.WithLabelValues("namespace").Inc()
+		return fmt.Errorf("lrp: namespace of session %d too long for the mesh", from.ID)
+	}
+	buf := make([]byte, 0, meshPrefixSize+len(from.Namespace)+len(frame))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(from.ID))
+	buf = append(buf, byte(len(from.Namespace)))
+	buf = append(buf, from.Namespace...)
+	buf = append(buf, frame...)
+	if err := l.write(buf); err != nil {
+		meshForwardErrors.WithLabelValues("write").Inc()
+		m.dropLink(l)
+		return fmt.Errorf("lrp: mesh forward to %s: %w", e.relay, err)
+	}
+	meshForwardedBytes.WithLabelValues("out", e.relay).Add(float64(len(frame)))
+	meshForwardedFrames.WithLabelValues("out", e.relay).Inc()
+	return nil
+}
+
+// link returns the open link to relay, starting a dial in the background
+// when there is none.
+func (m *Mesh) link(relay, addr string) *meshLink {
+	m.mu.RLock()
+	l := m.links[relay]
+	m.mu.RUnlock()
+	if l != nil {
+		return l
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l = m.links[relay]; l != nil || m.closed {
+		return l
+	}
+	if !m.dialing[relay] {
+		m.dialing[relay] = true
+		go m.dialLink(relay, addr)
+	}
+	return nil
+}
+
+func (m *Mesh) dialLink(relay, addr string) {
+	var r *bufio.Reader
+	conn, err := net.DialTimeout("tcp", addr, meshDialTimeout)
+	if err == nil {
+		r = bufio.NewReader(conn)
+		err = m.answerChallenge(conn, r)
+	}
+
+	m.mu.Lock()
+	delete(m.dialing, relay)
+	if err != nil || m.closed {
+		m.mu.Unlock()
+		if conn != nil {
+			conn.Close() //nolint:errcheck
+		}
+		if err != nil {
+			m.log.Warn("relay mesh dial failed", "relay", relay, "addr", addr, "err", err)
+		}
+		return
+	}
+	l := &meshLink{relay: relay, conn: conn}
+	m.links[relay] = l
+	meshLinks.Set(float64(len(m.links)))
+	m.mu.Unlock()
+
+	m.log.Info("relay mesh link established", "relay", relay, "addr", addr)
+
+	// Links are one-way; a read only returns when the remote closes.
+	_, _ = io.Copy(io.Discard, r)
+	m.dropLink(l)
+}
+
+// answerChallenge reads the listener's nonce and proves the mesh secret.
+func (m *Mesh) answerChallenge(conn net.Conn, r *bufio.Reader) error {
+	_ = conn.SetDeadline(time.Now().Add(meshHandshakeTimeout))
+	defer conn.SetDeadline(time.Time{}) //nolint:errcheck
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	proto, nonce, ok := strings.Cut(strings.TrimSpace(line), " ")
+	if !ok || proto != meshHandshake || nonce == "" {
+		return fmt.Errorf("relay mesh: unexpected handshake %q", strings.TrimSpace(line))
+	}
+	_, err = fmt.Fprintf(conn, "%s %s %s\n", meshHandshake, m.cfg.RelayID, linkMAC(m.cfg.Secret, nonce, m.cfg.RelayID))
+	return err
+}
+
+// challenge sends a nonce to a dialling relay and returns the ID it proves.
+func (m *Mesh) challenge(conn net.Conn, r *bufio.Reader) (string, error) {
+	_ = conn.SetDeadline(time.Now().Add(meshHandshakeTimeout))
+	defer conn.SetDeadline(time.Time{}) //nolint:errcheck
+
+	b := make([]byte, meshNonceSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	nonce := hex.EncodeToString(b)
+	if _, err := fmt.Fprintf(conn, "%s %s\n", meshHandshake, nonce); err != nil {
+		return "", err
+	}
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[0] != meshHandshake || fields[1] == "" {
+		return "", errMeshAuth
+	}
+	if !hmac.Equal([]byte(fields[2]), []byte(linkMAC(m.cfg.Secret, nonce, fields[1]))) {
+		return "", errMeshAuth
+	}
+	return fields[1], nil
+}
+
+func (m *Mesh) dropLink(l *meshLink) {
+	m.mu.Lock()
+	if m.links[l.relay] == l {
+		delete(m.links, l.relay)
+		meshLinks.Set(float64(len(m.links)))
+	}
+	m.mu.Unlock()
+	l.conn.Close() //nolint:errcheck
+}
+
+func (m *Mesh) acceptLoop() {
+	for {
+		conn, err := m.ln.Accept()
+		if err != nil {
+			m.mu.RLock()
+			closed := m.closed
+			m.mu.RUnlock()
+			if !closed {
+				m.log.Error("relay mesh accept failed", err)
+			}
+			return
+		}
+		go m.serveLink(conn)
+	}
+}
+
+// serveLink reads frames from another relay and delivers them locally.
+func (m *Mesh) serveLink(conn net.Conn) {
+	defer conn.Close() //nolint:errcheck
+
+	r := bufio.NewReader(conn)
+	relay, err := m.challenge(conn, r)
+	if err != nil {
+		meshForwardErrors.WithLabelValues("auth").Inc()
+		m.log.Warn("relay mesh handshake rejected", "remote", conn.RemoteAddr(), "err", err)
+		return
+	}
+
+	prefix := make([]byte, meshPrefixSize)
+	nsBuf := make([]byte, 255)
+	headBuf := make([]byte, HeaderSize)
+	for {
+		// Each frame is preceded by the sender's session ID and namespace.
+		if _, err = io.ReadFull(r, prefix); err != nil {
+			m.log.Debug("relay mesh link closed", "relay", relay, "err", err)
+			return
+		}
+		from := uint64(binary.BigEndian.Uint32(prefix))
+		namespace := nsBuf[:prefix[4]]
+		if _, err = io.ReadFull(r, namespace); err != nil {
+			return
+		}
+		if _, err = io.ReadFull(r, headBuf); err != nil {
+			return
+		}
+		h, _ := Unmarshal(headBuf)
+		if h.PayloadLen > meshMaxPayload {
+			m.log.Warn("relay mesh frame too large", "relay", relay, "len", h.PayloadLen)
+			return
+		}
+
+		frame := make([]byte, HeaderSize+int(h.PayloadLen))
+		copy(frame, headBuf)
+		if _, err = io.ReadFull(r, frame[HeaderSize:]); err != nil {
+			return
+		}
+		if h.Cmd != Forward && h.Cmd != Probe {
+			continue
+		}
+
+		meshForwardedBytes.WithLabelValues("in", relay).Add(float64(len(frame)))
+		meshForwardedFrames.WithLabelValues("in", relay).Inc()
+		err = m.local.relayRemote(relay, from, string(namespace), uint64(h.ToID), frame)
+		if errors.Is(err, errTargetNotFound) {
+			meshForwardErrors.WithLabelValues("target_gone").Inc()
+		}
+		if err != nil {
+			m.log.Debug("relay mesh delivery failed", "relay", relay, "to", h.ToID, "err", err)
+		}
+	}
+}
+
+// onSession publishes local attach/detach events.
+func (m *Mesh) onSession(id uint64, attached bool) {
+	op := meshOpDetach
+	if attached {
+		op = meshOpAttach
+	}
+	m.announce(op, []uint64{id})
+}
+
+func (m *Mesh) announceAll() {
+	m.announce(meshOpSync, m.local.LocalPeers())
+}
+
+func (m *Mesh) announce(op string, peers []uint64) {
+	a := meshAnnounce{
+		Relay: m.cfg.RelayID,
+		Addr:  m.cfg.AdvertiseAddr,
+		Op:    op,
+		Peers: peers,
+		Time:  time.Now().UnixNano(),
+	}
+	a.sign(m.cfg.Secret)
+	data, err := json.Marshal(a)
+	if err != nil {
+		return
+	}
+	if err = m.nc.Publish(meshSubjectSessions, data); err != nil {
+		m.log.Warn("relay mesh announce failed", "op", op, "err", err)
+	}
+}
+
+func (m *Mesh) onAnnounce(msg *natsgo.Msg) {
+	var a meshAnnounce
+	if err := json.Unmarshal(msg.Data, &a); err != nil {
+		m.log.Warn("invalid relay mesh announcement", "err", err)
+		return
+	}
+	now := time.Now()
+	if err := a.verify(m.cfg.Secret, now); err != nil {
+		m.log.Warn("relay mesh announcement rejected", "relay", a.Relay, "err", err)
+		return
+	}
+	m.apply(a, now)
+}
+
+// apply merges an announcement from another relay into the directory.
+func (m *Mesh) apply(a meshAnnounce, now time.Time) {
+	if a.Relay == "" || a.Relay == m.cfg.RelayID {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch a.Op {
+	case meshOpAttach:
+		for _, id := range a.Peers {
+			m.dir[id] = meshEntry{relay: a.Relay, addr: a.Addr, expires: now.Add(meshEntryTTL)}
+		}
+	case meshOpDetach:
+		// A peer that moved may already be claimed by another relay.
+		for _, id := range a.Peers {
+			if e, ok := m.dir[id]; ok && e.relay == a.Relay {
+				delete(m.dir, id)
+			}
+		}
+	case meshOpSync:
+		// A sync is the complete list of the sender's sessions.
+		for id, e := range m.dir {
+			if e.relay == a.Relay {
+				delete(m.dir, id)
+			}
+		}
+		for _, id := range a.Peers {
+			m.dir[id] = meshEntry{relay: a.Relay, addr: a.Addr, expires: now.Add(meshEntryTTL)}
+		}
+	}
+	meshDirectoryPeers.Set(float64(len(m.dir)))
+}
+
+// expire drops directory entries that were not refreshed in time.
+func (m *Mesh) expire(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, e := range m.dir {
+		if now.After(e.expires) {
+			delete(m.dir, id)
+		}
+	}
+	meshDirectoryPeers.Set(float64(len(m.dir)))
+}
@@ -0,0 +1,252 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	natsserver "github.com/nats-io/nats-server/v2/server"
+)
+
+// frameStream records frames written to a session; safe for concurrent use.
+type frameStream struct {
+	mu     sync.Mutex
+	frames [][]byte
+}
+
+func (f *frameStream) Read(p []byte) (int, error) { return 0, nil }
+func (f *frameStream) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.frames = append(f.frames, append([]byte(nil), p...))
+	return len(p), nil
+}
+func (f *frameStream) Close() error         { return nil }
+func (f *frameStream) RemoteAddr() net.Addr { return nil }
+
+func (f *frameStream) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.frames)
+}
+
+func runTestNATS(t *testing.T) string {
+	t.Helper()
+	ns, err := natsserver.NewServer(&natsserver.Options{Host: "127.0.0.1", Port: -1, NoLog: true, NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go ns.Start()
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(ns.Shutdown)
+	return ns.ClientURL()
+}
+
+const testMeshSecret = "mesh-secret"
+
+func newTestMesh(t *testing.T, id, natsURL string) (*Mesh, *SessionManager) {
+	t.Helper()
+	return newTestMeshWith(t, id, natsURL, NewSessionManager())
+}
+
+func newTestMeshWith(t *testing.T, id, natsURL string, sm *SessionManager) (*Mesh, *SessionManager) {
+	t.Helper()
+	m, err := NewMesh(MeshConfig{RelayID: id, ListenAddr: "127.0.0.1:0", NatsURL: natsURL, Secret: testMeshSecret}, sm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(m.Close)
+	return m, sm
+}
+
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMesh_ForwardsToOwningRelay(t *testing.T) {
+	natsURL := runTestNATS(t)
+	a, smA := newTestMesh(t, "relay-a", natsURL)
+	b, smB := newTestMesh(t, "relay-b", natsURL)
+	go a.Run(t.Context())
+	go b.Run(t.Context())
+
+	dst := &frameStream{}
+	smB.Register(7, &Session{ID: 7, Stream: dst, Type: "TCP"})
+
+	src := &Session{ID: 3, Namespace: "wf-a"}
+	h := &Header{PayloadLen: 5, Cmd: Forward, ToID: 7}
+	frame := append(h.Marshal(), []byte("hello")...)
+
+	// The first frames may be dropped while A dials B.
+	eventually(t, "frame delivered through relay-b", func() bool {
+		_ = smA.Relay(src, 7, frame)
+		return dst.count() > 0
+	})
+	dst.mu.Lock()
+	got := string(dst.frames[0][HeaderSize:])
+	dst.mu.Unlock()
+	if got != "hello" {
+		t.Fatalf("payload = %q, want hello", got)
+	}
+
+	smB.Unregister(7)
+	eventually(t, "detach propagated", func() bool {
+		return smA.Relay(src, 7, frame) == errTargetNotFound
+	})
+}
+
+func TestMesh_ApplyAnnouncements(t *testing.T) {
+	m := &Mesh{cfg: MeshConfig{RelayID: "self", Secret: testMeshSecret}, dir: make(map[uint64]meshEntry)}
+	now := time.Now()
+
+	m.apply(meshAnnounce{Relay: "self", Op: meshOpAttach, Peers: []uint64{1}}, now)
+	if len(m.dir) != 0 {
+		t.Fatal("own announcements must be ignored")
+	}
+
+	m.apply(meshAnnounce{Relay: "a", Addr: "a:1", Op: meshOpSync, Peers: []uint64{1, 2}}, now)
+	m.apply(meshAnnounce{Relay: "b", Addr: "b:1", Op: meshOpAttach, Peers: []uint64{2}}, now)
+	if e := m.dir[2]; e.relay != "b" {
+		t.Fatalf("peer 2 owned by %q, want b after it moved", e.relay)
+	}
+
+	// A late detach from the previous owner must not drop the new entry.
+	m.apply(meshAnnounce{Relay: "a", Op: meshOpDetach, Peers: []uint64{2}}, now)
+	if _, ok := m.dir[2]; !ok {
+		t.Fatal("stale detach removed peer 2")
+	}
+
+	// A sync replaces everything the sender owned.
+	m.apply(meshAnnounce{Relay: "a", Addr: "a:1", Op: meshOpSync, Peers: []uint64{3}}, now)
+	if _, ok := m.dir[1]; ok {
+		t.Fatal("peer 1 should be dropped by a's sync")
+	}
+	if e := m.dir[3]; e.relay != "a" {
+		t.Fatalf("peer 3 owned by %q, want a", e.relay)
+	}
+
+	m.expire(now.Add(meshEntryTTL + time.Second))
+	if len(m.dir) != 0 {
+		t.Fatalf("expected all entries to expire, %d left", len(m.dir))
+	}
+}
+
+func TestMesh_AnnouncementAuth(t *testing.T) {
+	now := time.Now()
+	a := meshAnnounce{Relay: "a", Addr: "a:1", Op: meshOpAttach, Peers: []uint64{1}, Time: now.UnixNano()}
+	a.sign(testMeshSecret)
+	if err := a.verify(testMeshSecret, now); err != nil {
+		t.Fatalf("signed announcement rejected: %v", err)
+	}
+
+	forged := a
+	forged.Peers = []uint64{2}
+	unsigned := a
+	unsigned.MAC = ""
+	otherSecret := a
+	otherSecret.sign("other")
+	stale := a
+	stale.Time = now.Add(-2 * meshMaxClockSkew).UnixNano()
+	stale.sign(testMeshSecret)
+	for name, b := range map[string]meshAnnounce{
+		"forged": forged, "unsigned": unsigned, "other secret": otherSecret, "stale": stale,
+	} {
+		if b.verify(testMeshSecret, now) == nil {
+			t.Errorf("%s announcement accepted", name)
+		}
+	}
+}
+
+func TestMesh_RejectsUnauthenticatedLink(t *testing.T) {
+	natsURL := runTestNATS(t)
+	b, smB := newTestMesh(t, "relay-b", natsURL)
+	go b.Run(t.Context())
+
+	dst := &frameStream{}
+	smB.Register(7, &Session{ID: 7, Stream: dst, Type: "TCP"})
+
+	conn, err := net.Dial("tcp", b.ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	h := &Header{PayloadLen: 5, Cmd: Forward, ToID: 7}
+	frame := append([]byte{0, 0, 0, 3, 0}, append(h.Marshal(), []byte("hello")...)...)
+	_, _ = conn.Write([]byte(meshHandshake + " relay-x 00\n"))
+	_, _ = conn.Write(frame)
+
+	// The listener closes the link once the handshake fails.
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err = io.Copy(io.Discard, conn); errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatal("link not closed after a failed handshake")
+	}
+	if dst.count() != 0 {
+		t.Fatal("frame from an unauthenticated link was delivered")
+	}
+}
+
+func TestMesh_DestinationLimits(t *testing.T) {
+	natsURL := runTestNATS(t)
+	a, smA := newTestMesh(t, "relay-a", natsURL)
+	smB := NewSessionManager()
+	smB.SetLimits(Limits{NamespaceFrameRate: 1})
+	b, _ := newTestMeshWith(t, "relay-b", natsURL, smB)
+	go a.Run(t.Context())
+	go b.Run(t.Context())
+
+	dst := &frameStream{}
+	smB.Register(7, &Session{ID: 7, Stream: dst, Type: "TCP"})
+
+	src := &Session{ID: 3, Namespace: "wf-a"}
+	h := &Header{PayloadLen: 5, Cmd: Forward, ToID: 7}
+	frame := append(h.Marshal(), []byte("hello")...)
+	eventually(t, "first frame delivered through relay-b", func() bool {
+		_ = smA.Relay(src, 7, frame)
+		return dst.count() > 0
+	})
+
+	// relay-b allows one frame per second for wf-a however many frames
+	// relay-a lets through.
+	for range 10 {
+		_ = smA.Relay(src, 7, frame)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := dst.count(); n > 2 {
+		t.Fatalf("delivered %d frames, want relay-b's namespace limit to drop the burst", n)
+	}
+
+	smB.expireRemotes(time.Now().Add(time.Second))
+	smB.mu.RLock()
+	defer smB.mu.RUnlock()
+	if len(smB.remotes) != 0 || len(smB.nsLimiters) != 0 {
+		t.Fatalf("remote sender not expired: %d remotes, %d namespace limiters", len(smB.remotes), len(smB.nsLimiters))
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Relay mesh metrics. They and the server metrics below are registered on
+// the default registry so an embedding process that already serves /metrics
+// exposes them as well.
+var (
+	meshForwardedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lattice",
+		Subsystem: "relay_mesh",
+		Name:      "forwarded_bytes_total",
+		Help:      "LRP frame bytes exchanged with other relay instances.",
+	}, []string{"direction", "relay"})
+
+	meshForwardedFrames = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lattice",
+		Subsystem: "relay_mesh",
+		Name:      "forwarded_frames_total",
+		Help:      "LRP frames exchanged with other relay instances.",
+	}, []string{"direction", "relay"})
+
+	meshForwardErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lattice",
+		Subsystem: "relay_mesh",
+		Name:      "forward_errors_total",
+		Help:      "Frames that could not be forwarded over the relay mesh.",
+	}, []string{"reason"})
+
+	meshDirectoryPeers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "lattice",
+		Subsystem: "relay_mesh",
+		Name:      "directory_peers",
+		Help:      "Peers known to be attached to other relay instances.",
+	})
+
+	meshLinks = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "lattice",
+		Subsystem: "relay_mesh",
+		Name:      "links",
+		Help:      "Open outbound links to other relay instances.",
+	})
+)
+
+// Relay server metrics.
+var (
+	sessionsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "lattice",
+		Subsystem: "relay",
+		Name:      "sessions",
+		Help:      "Peer sessions attached to this relay instance.",
+	}, []string{"transport"})
+
+	sessionBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lattice",
+		Subsystem: "relay",
+		Name:      "session_bytes_total",
+		Help:      "LRP frame bytes received from and delivered to each session.",
+	}, []string{"session", "direction"})
+
+	sessionFrames = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lattice",
+		Subsystem: "relay",
+		Name:      "session_frames_total",
+		Help:      "LRP frames received from and delivered to each session.",
+	}, []string{"session", "direction"})
+
+	relayFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lattice",
+		Subsystem: "relay",
+		Name:      "failures_total",
+		Help:      "Frames that could not be relayed.",
+	}, []string{"reason"})
+
+	quicDatagramDrops = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lattice",
+		Subsystem: "relay",
+		Name:      "quic_datagram_drops_total",
+		Help:      "QUIC datagrams dropped on receive or send.",
+	}, []string{"reason"})
+)
+
+func init() {
+	prometheus.MustRegister(meshForwardedBytes, meshForwardedFrames, meshForwardErrors, meshDirectoryPeers, meshLinks)
+	prometheus.MustRegister(sessionsGauge, sessionBytes, sessionFrames, relayFailures, quicDatagramDrops)
+}
+
+// ServeMetrics exposes the default Prometheus registry on addr at /metrics.
+// It blocks until the listener fails.
+func ServeMetrics(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return http.ListenAndServe(addr, mux)
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/alatticeio/lattice/pkg/version"
+
+	"github.com/quic-go/quic-go"
+)
+
+// RelayID addresses the relay itself. A session that registers as RelayID is
+// a health probe: it is not added to the session manager, and Probe frames
+// sent to RelayID are answered by the relay with a ProbeReply.
+const RelayID uint32 = 0
+
+const (
+	// maxProbePayload bounds the nonce a probe session may send.
+	maxProbePayload = 256
+	// maxProbeReply bounds a frame read while waiting for the reply.
+	maxProbeReply = 64 * 1024
+)
+
+// ProbeReply is the JSON payload of the relay's answer to a Probe frame.
+type ProbeReply struct {
+	Nonce    string `json:"nonce"`
+	Version  string `json:"version"`
+	Sessions int    `json:"sessions"`
+}
+
+// ProbeResult is the outcome of one successful probe.
+type ProbeResult struct {
+	RTT     time.Duration
+	Version string
+	// Sessions is the number of sessions the relay reports, or -1 when the
+	// relay predates ProbeReply and only echoed the frame.
+	Sessions int
+}
+
+// probeReplyFrame builds the relay's answer to a Probe frame addressed to
+// RelayID. req is the probe payload (the nonce).
+func probeReplyFrame(m *SessionManager, seq uint16, req []byte) []byte {
+	payload, _ := json.Marshal(ProbeReply{
+		Nonce:    string(req),
+		Version:  version.Version,
+		Sessions: m.ConnectedPeers(),
+	})
+	h := Header{Seq: seq, PayloadLen: uint32(len(payload)), Cmd: Probe, ToID: RelayID}
+	return append(h.Marshal(), payload...)
+}
+
+// ProbeTCP performs an LRP handshake against the relay's TCP endpoint and
+// times a Probe round trip.
+func ProbeTCP(ctx context.Context, addr string) (*ProbeResult, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	req, err := http.NewRequest("GET", "/lrp/v1/upgrade", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Upgrade", "lrp")
+	req.Header.Set("Connection", "Upgrade")
+	if err = req.Write(conn); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req) //nolint:bodyclose // resp.Body wraps conn
+	if err != nil {
+		return nil, fmt.Errorf("upgrade failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("upgrade failed: %s", resp.Status)
+	}
+
+	reg := Header{Cmd: Register, ToID: RelayID}
+	if _, err = conn.Write(reg.Marshal()); err != nil {
+		return nil, err
+	}
+
+	nonce := newNonce()
+	start := time.Now()
+	if _, err = conn.Write(probeFrame(nonce)); err != nil {
+		return nil, err
+	}
+	headBuf := make([]byte, HeaderSize)
+	for {
+		if _, err = io.ReadFull(reader, headBuf); err != nil {
+			return nil, err
+		}
+		h, err := Unmarshal(headBuf)
+		if err != nil {
+			return nil, err
+		}
+		if h.PayloadLen > maxProbeReply {
+			return nil, fmt.Errorf("lrp: probe reply too large (%d bytes)", h.PayloadLen)
+		}
+		payload := make([]byte, h.PayloadLen)
+		if _, err = io.ReadFull(reader, payload); err != nil {
+			return nil, err
+		}
+		if h.Cmd != Probe {
+			continue
+		}
+		return parseProbeReply(nonce, payload, time.Since(start))
+	}
+}
+
+// ProbeQUIC performs an LRP handshake against the relay's QUIC endpoint and
+// times a Probe round trip over datagrams.
+func ProbeQUIC(ctx context.Context, addr string) (*ProbeResult, error) {
+	tlsCfg := &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec
+		NextProtos:         []string{"lrp"},
+	}
+	conn, err := quic.DialAddr(ctx, addr, tlsCfg, &quic.Config{EnableDatagrams: true})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.CloseWithError(0, "probe done") //nolint:errcheck
+
+	ctrl, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reg := Header{Cmd: Register, ToID: RelayID}
+	if _, err = ctrl.Write(reg.Marshal()); err != nil {
+		return nil, err
+	}
+
+	// The Register frame travels on the stream and the probe as a datagram,
+	// so the relay may see the probe first. Resend until answered.
+	nonce := newNonce()
+	frame := probeFrame(nonce)
+	start := time.Now()
+	replies := make(chan []byte, 1)
+	go func() {
+		for {
+			data, err := conn.ReceiveDatagram(ctx)
+			if err != nil {
+				close(replies)
+				return
+			}
+			if h, err := Unmarshal(data); err == nil && h.Cmd == Probe {
+				replies <- data[HeaderSize:]
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if err := conn.SendDatagram(frame); err != nil {
+			return nil, err
+		}
+		select {
+		case payload, ok := <-replies:
+			if !ok {
+				return nil, ctx.Err()
+			}
+			return parseProbeReply(nonce, payload, time.Since(start))
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func probeFrame(nonce string) []byte {
+	h := Header{PayloadLen: uint32(len(nonce)), Cmd: Probe, ToID: RelayID}
+	return append(h.Marshal(), nonce...)
+}
+
+// parseProbeReply accepts a ProbeReply, or the probe frame itself echoed back
+// by relays that predate ProbeReply.
+func parseProbeReply(nonce string, payload []byte, rtt time.Duration) (*ProbeResult, error) {
+	if bytes.Equal(payload, []byte(nonce)) {
+		return &ProbeResult{RTT: rtt, Sessions: -1}, nil
+	}
+	var reply ProbeReply
+	if err := json.Unmarshal(payload, &reply); err != nil {
+		return nil, fmt.Errorf("lrp: bad probe reply: %w", err)
+	}
+	if reply.Nonce != nonce {
+		return nil, errors.New("lrp: probe reply nonce mismatch")
+	}
+	return &ProbeResult{RTT: rtt, Version: reply.Version, Sessions: reply.Sessions}, nil
+}
+
+func newNonce() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/pkg/version"
+
+	"github.com/quic-go/quic-go"
+)
+
+func TestProbeTCP(t *testing.T) {
+	s := NewServer(&config.Config{})
+	s.sessionMgr.Register(7, &Session{ID: 7, Stream: &mockStream{}, Type: "TCP"})
+	ts := httptest.NewServer(http.HandlerFunc(s.boltUpgradeHandler))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := ProbeTCP(ctx, strings.TrimPrefix(ts.URL, "http://"))
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if res.Version != version.Version {
+		t.Errorf("version = %q, want %q", res.Version, version.Version)
+	}
+	if res.Sessions != 1 {
+		t.Errorf("sessions = %d, want 1", res.Sessions)
+	}
+	if s.sessionMgr.Get(uint64(RelayID)) != nil {
+		t.Error("probe session must not be registered")
+	}
+}
+
+func TestProbeQUIC(t *testing.T) {
+	tlsCfg, err := GenerateSelfSignedTLS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := quic.ListenAddr("127.0.0.1:0", tlsCfg, &quic.Config{EnableDatagrams: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	s := NewQUICServer(NewSessionManager())
+	go func() {
+		for {
+			conn, err := ln.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			go s.handleConn(conn)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := ProbeQUIC(ctx, ln.Addr().String())
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if res.Version != version.Version || res.Sessions != 0 {
+		t.Errorf("got %+v", res)
+	}
+}
+
+func TestParseProbeReply_LegacyEcho(t *testing.T) {
+	res, err := parseProbeReply("abc", []byte("abc"), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Sessions != -1 || res.Version != "" {
+		t.Errorf("got %+v", res)
+	}
+
+	if _, err = parseProbeReply("abc", []byte(`{"nonce":"xyz"}`), 0); err == nil {
+		t.Error("expected nonce mismatch")
+	}
+}
@@ -0,0 +1,576 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/log"
+	"github.com/alatticeio/lattice/internal/grpc"
+
+	wgconn "golang.zx2c4.com/wireguard/conn"
+)
+
+const (
+	// maxRelaySessions is how many of the best relays a Selector keeps
+	// sessions to. Extra sessions give peer pairs a shared relay to meet on
+	// and a warm standby when the home relay fails.
+	maxRelaySessions = 3
+
+	// measureInterval is how often relay latency is re-measured and failed
+	// relays are re-dialed.
+	measureInterval = 60 * time.Second
+	measureTimeout  = 3 * time.Second
+
+	// rttBucket groups latencies so that jitter does not reorder relays.
+	rttBucket = 5 * time.Millisecond
+
+	recvChanDepth = 1024
+	recvIdle      = 30 * time.Second
+)
+
+var (
+	_ infra.Wrrp          = (*Selector)(nil)
+	_ infra.RelaySelector = (*Selector)(nil)
+
+	errNoRelay = errors.New("lrp: no relay connected")
+)
+
+type onMessageFunc func(ctx context.Context, remoteId infra.PeerID, packet *grpc.SignalPacket) error
+
+// relayMember is one relay offered by the control plane.
+type relayMember struct {
+	relay  infra.Relay
+	rtt    time.Duration // last measured TCP connect time; 0 when unreachable
+	client infra.Wrrp    // nil when no session is open
+}
+
+// remotePrefs is what a remote peer advertised in its last SYN/ACK.
+type remotePrefs struct {
+	prefs     []string
+	initiator bool // true when the local side is the initiator of the pair
+}
+
+type received struct {
+	data     []byte
+	remoteId uint64
+}
+
+// Selector implements infra.Wrrp on top of several relays. It measures the
+// latency to every relay offered in Message.Relays, keeps sessions to the
+// best few (same region first, then lowest latency) and routes each peer
+// pair through a relay both sides are connected to. When a relay session
+// drops, pairs move to the next shared relay and the relay is re-dialed on
+// the next measurement round.
+type Selector struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	log       *log.Logger
+	localId   infra.PeerID
+	onMessage onMessageFunc
+
+	// dial and measure are replaceable in tests.
+	dial    func(ctx context.Context, r infra.Relay) (infra.Wrrp, error)
+	measure func(ctx context.Context, r infra.Relay) (time.Duration, error)
+
+	mu      sync.RWMutex
+	region  string
+	ticket  string
+	members map[string]*relayMember
+	prefs   []string // connected relays, best first
+	remotes map[uint64]remotePrefs
+	pins    map[uint64]string // remoteId -> relay name
+
+	recvCh chan received
+	kick   chan struct{}
+}
+
+// NewSelector creates a Selector with no relays. Relays are supplied with
+// Update once the network map arrives; Run drives measurement and failover.
+func NewSelector(ctx context.Context, localID infra.PeerID, onMessage onMessageFunc) *Selector {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Selector{
+		ctx:       ctx,
+		cancel:    cancel,
+		log:       log.GetLogger("relay-selector"),
+		localId:   localID,
+		onMessage: onMessage,
+		members:   make(map[string]*relayMember),
+		remotes:   make(map[uint64]remotePrefs),
+		pins:      make(map[uint64]string),
+		recvCh:    make(chan received, recvChanDepth),
+		kick:      make(chan struct{}, 1),
+	}
+	s.dial = s.dialRelay
+	s.measure = measureRelay
+	return s
+}
+
+// SetRegion sets the local region used to prefer nearby relays.
+func (s *Selector) SetRegion(region string) {
+	s.mu.Lock()
+	changed := s.region != region
+	s.region = region
+	s.mu.Unlock()
+	if changed {
+		s.trigger()
+	}
+}
+
+// SetTicket sets the relay ticket the manager issued to this peer. It is
+// presented to relays on later dials.
+func (s *Selector) SetTicket(ticket string) {
+	s.mu.Lock()
+	s.ticket = ticket
+	s.mu.Unlock()
+}
+
+// Update replaces the set of offered relays. Sessions to relays that are no
+// longer offered are closed; new relays are measured on the next round,
+// which Update triggers immediately.
+func (s *Selector) Update(relays []*infra.Relay) {
+	var closing []infra.Wrrp
+	s.mu.Lock()
+	offered := make(map[string]bool, len(relays))
+	for _, r := range relays {
+		if r == nil || r.Name == "" {
+			continue
+		}
+		offered[r.Name] = true
+		if m, ok := s.members[r.Name]; ok {
+			if (m.relay.TcpUrl != r.TcpUrl || m.relay.QuicUrl != r.QuicUrl) && m.client != nil {
+				// Endpoint changed: reconnect on the next round.
+				closing = append(closing, m.client)
+				m.client = nil
+			}
+			m.relay = *r
+			continue
+		}
+		s.members[r.Name] = &relayMember{relay: *r}
+	}
+	for name, m := range s.members {
+		if offered[name] {
+			continue
+		}
+		if m.client != nil {
+			closing = append(closing, m.client)
+		}
+		delete(s.members, name)
+	}
+	s.reorderLocked()
+	s.mu.Unlock()
+
+	for _, c := range closing {
+		c.Close() //nolint:errcheck
+	}
+	s.trigger()
+}
+
+// Run measures relays and maintains sessions until ctx is done.
+func (s *Selector) Run() {
+	ticker := time.NewTicker(measureInterval)
+	defer ticker.Stop()
+	for {
+		s.refresh()
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.kick:
+		}
+	}
+}
+
+func (s *Selector) trigger() {
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+}
+
+// refresh runs one measurement round: measure every offered relay, open
+// sessions to the best maxRelaySessions reachable relays and close the rest.
+func (s *Selector) refresh() {
+	s.mu.RLock()
+	targets := make([]infra.Relay, 0, len(s.members))
+	for _, m := range s.members {
+		targets = append(targets, m.relay)
+	}
+	s.mu.RUnlock()
+
+	rtts := make(map[string]time.Duration, len(targets))
+	var wg sync.WaitGroup
+	var rttMu sync.Mutex
+	for _, r := range targets {
+		wg.Add(1)
+		go func(r infra.Relay) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(s.ctx, measureTimeout)
+			defer cancel()
+			rtt, err := s.measure(ctx, r)
+			if err != nil {
+				s.log.Debug("relay unreachable", "relay", r.Name, "err", err)
+				rtt = 0
+			}
+			rttMu.Lock()
+			rtts[r.Name] = rtt
+			rttMu.Unlock()
+		}(r)
+	}
+	wg.Wait()
+
+	// Decide which relays should hold a session.
+	s.mu.Lock()
+	for name, rtt := range rtts {
+		if m, ok := s.members[name]; ok {
+			m.rtt = rtt
+		}
+	}
+	ranked := rankRelays(s.members, s.region)
+	want := make(map[string]bool, maxRelaySessions)
+	var toDial []infra.Relay
+	for _, name := range ranked {
+		m := s.members[name]
+		if m.rtt == 0 && m.client == nil {
+			continue
+		}
+		if len(want) == maxRelaySessions {
+			break
+		}
+		want[name] = true
+		if m.client == nil {
+			toDial = append(toDial, m.relay)
+		}
+	}
+	var closing []infra.Wrrp
+	for name, m := range s.members {
+		if !want[name] && m.client != nil {
+			closing = append(closing, m.client)
+			m.client = nil
+		}
+	}
+	s.reorderLocked()
+	s.mu.Unlock()
+
+	for _, c := range closing {
+		c.Close() //nolint:errcheck
+	}
+	for _, r := range toDial {
+		client, err := s.dial(s.ctx, r)
+		if err != nil {
+			s.log.Warn("relay dial failed", "relay", r.Name, "err", err)
+			continue
+		}
+		s.attach(r.Name, client)
+	}
+}
+
+// attach installs a connected client and starts its receive loop.
+func (s *Selector) attach(name string, client infra.Wrrp) {
+	s.mu.Lock()
+	m, ok := s.members[name]
+	if !ok || m.client != nil {
+		s.mu.Unlock()
+		client.Close() //nolint:errcheck
+		return
+	}
+	m.client = client
+	s.reorderLocked()
+	s.mu.Unlock()
+
+	s.log.Info("relay session established", "relay", name)
+	go s.receiveLoop(name, client)
+}
+
+// detach drops a failed session; the relay is re-dialed on the next round.
+func (s *Selector) detach(name string, client infra.Wrrp) {
+	s.mu.Lock()
+	m, ok := s.members[name]
+	if ok && m.client == client {
+		m.client = nil
+		s.reorderLocked()
+	}
+	s.mu.Unlock()
+	client.Close() //nolint:errcheck
+}
+
+// receiveLoop copies frames from one relay session into the shared receive
+// channel until the session fails.
+func (s *Selector) receiveLoop(name string, client infra.Wrrp) {
+	fn := client.ReceiveFunc()
+	bufs := [][]byte{make([]byte, 65535)}
+	sizes := make([]int, 1)
+	eps := make([]wgconn.Endpoint, 1)
+	for {
+		n, err := fn(bufs, sizes, eps)
+		if err != nil {
+			if s.ctx.Err() == nil {
+				s.log.Warn("relay session lost, failing over", "relay", name, "err", err)
+				s.detach(name, client)
+				s.trigger()
+			}
+			return
+		}
+		if n == 0 {
+			continue
+		}
+		ep, ok := eps[0].(*infra.WRRPEndpoint)
+		if !ok {
+			continue
+		}
+		data := make([]byte, sizes[0])
+		copy(data, bufs[0][:sizes[0]])
+		select {
+		case s.recvCh <- received{data: data, remoteId: ep.RemoteId}:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// reorderLocked recomputes the preference list and the per-peer pins after
+// the set of connected relays changed. Caller holds mu.
+func (s *Selector) reorderLocked() {
+	s.prefs = s.prefs[:0]
+	for _, name := range rankRelays(s.members, s.region) {
+		if s.members[name].client != nil {
+			s.prefs = append(s.prefs, name)
+		}
+	}
+	for id, rp := range s.remotes {
+		s.pins[id] = s.pickLocked(rp)
+	}
+}
+
+// pickLocked returns the relay shared with a remote peer. The initiator's
+// preference order wins so both sides reach the same answer. Caller holds mu.
+func (s *Selector) pickLocked(rp remotePrefs) string {
+	if rp.initiator {
+		return sharedRelay(s.prefs, rp.prefs)
+	}
+	return sharedRelay(rp.prefs, s.prefs)
+}
+
+// rankRelays orders relay names by region match, then bucketed latency
+// (unreachable last), then load, then name.
+func rankRelays(members map[string]*relayMember, region string) []string {
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := members[names[i]], members[names[j]]
+		if region != "" {
+			ar, br := a.relay.Region == region, b.relay.Region == region
+			if ar != br {
+				return ar
+			}
+		}
+		ab, bb := rttRank(a.rtt), rttRank(b.rtt)
+		if ab != bb {
+			return ab < bb
+		}
+		if a.relay.Load != b.relay.Load {
+			return a.relay.Load < b.relay.Load
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func rttRank(rtt time.Duration) time.Duration {
+	if rtt <= 0 {
+		return time.Duration(1<<63 - 1)
+	}
+	return rtt / rttBucket
+}
+
+// sharedRelay returns the first relay in first that also appears in second.
+func sharedRelay(first, second []string) string {
+	for _, name := range first {
+		if slices.Contains(second, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// Preferences implements infra.RelaySelector.
+func (s *Selector) Preferences() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.prefs)
+}
+
+// Home implements infra.RelaySelector.
+func (s *Selector) Home() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.prefs) == 0 {
+		return ""
+	}
+	return s.prefs[0]
+}
+
+// Pin implements infra.RelaySelector.
+func (s *Selector) Pin(remoteId uint64, prefs []string, initiator bool) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rp := remotePrefs{prefs: slices.Clone(prefs), initiator: initiator}
+	s.remotes[remoteId] = rp
+	name := s.pickLocked(rp)
+	s.pins[remoteId] = name
+	return name
+}
+
+// clientFor returns the session used to reach remoteId: the pinned relay
+// when it is connected, otherwise the home relay. Caller holds mu.
+func (s *Selector) clientForLocked(remoteId uint64) infra.Wrrp {
+	if name := s.pins[remoteId]; name != "" {
+		if m, ok := s.members[name]; ok && m.client != nil {
+			return m.client
+		}
+	}
+	if len(s.prefs) == 0 {
+		return nil
+	}
+	return s.members[s.prefs[0]].client
+}
+
+// RemoteAddrFor implements infra.RelaySelector.
+func (s *Selector) RemoteAddrFor(remoteId uint64) net.Addr {
+	s.mu.RLock()
+	c := s.clientForLocked(remoteId)
+	s.mu.RUnlock()
+	if c == nil {
+		return nil
+	}
+	return c.RemoteAddr()
+}
+
+// Send implements infra.Wrrp by routing through the relay pinned for targetId.
+func (s *Selector) Send(ctx context.Context, targetId uint64, lrpType uint8, data []byte) error {
+	s.mu.RLock()
+	c := s.clientForLocked(targetId)
+	s.mu.RUnlock()
+	if c == nil {
+		return errNoRelay
+	}
+	return c.Send(ctx, targetId, lrpType, data)
+}
+
+// ReceiveFunc implements infra.Wrrp. Frames from every relay session are
+// delivered through one function so WireGuard sees a single relay bind.
+func (s *Selector) ReceiveFunc() wgconn.ReceiveFunc {
+	return func(packets [][]byte, sizes []int, eps []wgconn.Endpoint) (int, error) {
+		timer := time.NewTimer(recvIdle)
+		defer timer.Stop()
+		select {
+		case <-s.ctx.Done():
+			return 0, net.ErrClosed
+		case <-timer.C:
+			return 0, nil
+		case r := <-s.recvCh:
+			if len(r.data) > len(packets[0]) {
+				s.log.Warn("forward payload exceeds buffer", "need", len(r.data), "have", len(packets[0]))
+				return 0, nil
+			}
+			sizes[0] = copy(packets[0], r.data)
+			eps[0] = &infra.WRRPEndpoint{
+				Addr:          infra.WrrpFakeAddrPort(r.remoteId),
+				RemoteId:      r.remoteId,
+				TransportType: infra.WRRP,
+			}
+			return 1, nil
+		}
+	}
+}
+
+// Connect implements infra.Wrrp by running a measurement round right away.
+func (s *Selector) Connect() error {
+	s.refresh()
+	if s.Home() == "" {
+		return errNoRelay
+	}
+	return nil
+}
+
+// RemoteAddr implements infra.Wrrp and returns the home relay address.
+func (s *Selector) RemoteAddr() net.Addr {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.prefs) == 0 {
+		return nil
+	}
+	return s.members[s.prefs[0]].client.RemoteAddr()
+}
+
+// Close implements infra.Wrrp and closes every relay session.
+func (s *Selector) Close() error {
+	s.cancel()
+	s.mu.Lock()
+	var closing []infra.Wrrp
+	for _, m := range s.members {
+		if m.client != nil {
+			closing = append(closing, m.client)
+			m.client = nil
+		}
+	}
+	s.prefs = nil
+	s.mu.Unlock()
+	for _, c := range closing {
+		c.Close() //nolint:errcheck
+	}
+	return nil
+}
+
+// dialRelay opens a session to r, preferring QUIC when the relay offers it.
+func (s *Selector) dialRelay(ctx context.Context, r infra.Relay) (infra.Wrrp, error) {
+	s.mu.RLock()
+	opts := []ClientOption{WithTicket(s.ticket)}
+	s.mu.RUnlock()
+	if r.QuicUrl != "" {
+		c, err := NewQUICClient(ctx, s.localId, r.QuicUrl, s.onMessage, opts...)
+		if err == nil {
+			return c, nil
+		}
+		s.log.Debug("QUIC dial failed, falling back to TCP", "relay", r.Name, "err", err)
+	}
+	return NewTCPClient(ctx, s.localId, r.TcpUrl, s.onMessage, opts...)
+}
+
+// measureRelay returns the TCP connect time to the relay as a latency estimate.
+func measureRelay(ctx context.Context, r infra.Relay) (time.Duration, error) {
+	var d net.Dialer
+	start := time.Now()
+	conn, err := d.DialContext(ctx, "tcp", r.TcpUrl)
+	if err != nil {
+		return 0, fmt.Errorf("measure %s: %w", r.TcpUrl, err)
+	}
+	rtt := time.Since(start)
+	conn.Close() //nolint:errcheck
+	if rtt <= 0 {
+		rtt = time.Microsecond
+	}
+	return rtt, nil
+}
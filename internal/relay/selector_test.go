@@ -0,0 +1,210 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+
+	wgconn "golang.zx2c4.com/wireguard/conn"
+)
+
+// fakeWrrp records sent frames and blocks in ReceiveFunc until closed.
+type fakeWrrp struct {
+	name   string
+	mu     sync.Mutex
+	sent   []uint64
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newFakeWrrp(name string) *fakeWrrp {
+	return &fakeWrrp{name: name, closed: make(chan struct{})}
+}
+
+func (f *fakeWrrp) ReceiveFunc() wgconn.ReceiveFunc {
+	return func([][]byte, []int, []wgconn.Endpoint) (int, error) {
+		<-f.closed
+		return 0, net.ErrClosed
+	}
+}
+
+func (f *fakeWrrp) Send(_ context.Context, remoteId uint64, _ uint8, _ []byte) error {
+	f.mu.Lock()
+	f.sent = append(f.sent, remoteId)
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeWrrp) Connect() error { return nil }
+
+func (f *fakeWrrp) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 6266}
+}
+
+func (f *fakeWrrp) Close() error {
+	f.once.Do(func() { close(f.closed) })
+	return nil
+}
+
+func (f *fakeWrrp) sentTo() []uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.sent)
+}
+
+func newTestSelector(t *testing.T, rtts map[string]time.Duration) (*Selector, map[string]*fakeWrrp) {
+	t.Helper()
+	s := NewSelector(context.Background(), infra.FromUint64(1), nil)
+	t.Cleanup(func() { s.Close() }) //nolint:errcheck
+
+	var mu sync.Mutex
+	clients := make(map[string]*fakeWrrp)
+	s.measure = func(_ context.Context, r infra.Relay) (time.Duration, error) {
+		if rtt, ok := rtts[r.Name]; ok {
+			return rtt, nil
+		}
+		return 0, errors.New("unreachable")
+	}
+	s.dial = func(_ context.Context, r infra.Relay) (infra.Wrrp, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		c := newFakeWrrp(r.Name)
+		clients[r.Name] = c
+		return c, nil
+	}
+	return s, clients
+}
+
+func TestSelector_PrefersRegionThenLatency(t *testing.T) {
+	s, _ := newTestSelector(t, map[string]time.Duration{
+		"eu-1": 40 * time.Millisecond,
+		"us-1": 10 * time.Millisecond,
+		"us-2": 30 * time.Millisecond,
+		"ap-1": 5 * time.Millisecond,
+	})
+	s.SetRegion("us")
+	s.Update([]*infra.Relay{
+		{Name: "eu-1", Region: "eu"},
+		{Name: "us-1", Region: "us"},
+		{Name: "us-2", Region: "us"},
+		{Name: "ap-1", Region: "ap"},
+		{Name: "down", Region: "us"},
+	})
+	s.refresh()
+
+	want := []string{"us-1", "us-2", "ap-1"}
+	if got := s.Preferences(); !slices.Equal(got, want) {
+		t.Fatalf("Preferences() = %v, want %v", got, want)
+	}
+	if got := s.Home(); got != "us-1" {
+		t.Errorf("Home() = %q, want us-1", got)
+	}
+}
+
+func TestSelector_PrefersLessLoadedAtEqualLatency(t *testing.T) {
+	s, _ := newTestSelector(t, map[string]time.Duration{
+		"a": 11 * time.Millisecond,
+		"b": 12 * time.Millisecond,
+		"c": 30 * time.Millisecond,
+	})
+	s.Update([]*infra.Relay{
+		{Name: "a", Load: 80},
+		{Name: "b", Load: 20},
+		{Name: "c"},
+	})
+	s.refresh()
+
+	want := []string{"b", "a", "c"}
+	if got := s.Preferences(); !slices.Equal(got, want) {
+		t.Fatalf("Preferences() = %v, want %v", got, want)
+	}
+}
+
+func TestSelector_PinAndFailover(t *testing.T) {
+	s, clients := newTestSelector(t, map[string]time.Duration{
+		"a": 10 * time.Millisecond,
+		"b": 20 * time.Millisecond,
+		"c": 30 * time.Millisecond,
+	})
+	s.Update([]*infra.Relay{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+	s.refresh()
+
+	// The remote initiator prefers c, then b; the responder follows it.
+	if got := s.Pin(42, []string{"c", "b"}, false); got != "c" {
+		t.Fatalf("Pin as responder = %q, want c", got)
+	}
+	// As initiator our own order wins among relays the remote also has.
+	if got := s.Pin(43, []string{"c", "b"}, true); got != "b" {
+		t.Fatalf("Pin as initiator = %q, want b", got)
+	}
+
+	if err := s.Send(context.Background(), 42, Forward, nil); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got := clients["c"].sentTo(); !slices.Equal(got, []uint64{42}) {
+		t.Fatalf("relay c sent %v, want [42]", got)
+	}
+
+	// Relay c goes down: the pair moves to the next shared relay.
+	s.detach("c", clients["c"])
+	if err := s.Send(context.Background(), 42, Forward, nil); err != nil {
+		t.Fatalf("Send after failover: %v", err)
+	}
+	if got := clients["b"].sentTo(); !slices.Equal(got, []uint64{42}) {
+		t.Fatalf("relay b sent %v, want [42]", got)
+	}
+
+	// Unknown peers use the home relay.
+	if err := s.Send(context.Background(), 7, Forward, nil); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got := clients["a"].sentTo(); !slices.Equal(got, []uint64{7}) {
+		t.Fatalf("relay a sent %v, want [7]", got)
+	}
+}
+
+func TestSelector_UpdateDropsWithdrawnRelays(t *testing.T) {
+	s, clients := newTestSelector(t, map[string]time.Duration{
+		"a": 10 * time.Millisecond,
+		"b": 20 * time.Millisecond,
+	})
+	s.Update([]*infra.Relay{{Name: "a"}, {Name: "b"}})
+	s.refresh()
+
+	s.Update([]*infra.Relay{{Name: "b"}})
+	select {
+	case <-clients["a"].closed:
+	case <-time.After(time.Second):
+		t.Fatal("session to withdrawn relay was not closed")
+	}
+	if got := s.Preferences(); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("Preferences() = %v, want [b]", got)
+	}
+}
+
+func TestSelector_SendWithoutRelay(t *testing.T) {
+	s, _ := newTestSelector(t, nil)
+	if err := s.Send(context.Background(), 1, Forward, nil); !errors.Is(err, errNoRelay) {
+		t.Errorf("Send() error = %v, want errNoRelay", err)
+	}
+}
@@ -16,46 +16,244 @@ package relay
 
 import (
 	"errors"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	quic "github.com/quic-go/quic-go"
 )
 
+var (
+	// errTargetNotFound is returned by Relay when the destination peer is
+	// neither attached locally nor known to the relay mesh.
+	errTargetNotFound = errors.New("lrp: relay target not found")
+	// errRateLimited is returned by relayFrom when the sender is over its
+	// session or namespace limit.
+	errRateLimited = errors.New("lrp: rate limited")
+	// errSessionNotFound is returned by Kick for an unknown session.
+	errSessionNotFound = errors.New("lrp: session not found")
+)
+
+// Forwarder carries frames for peers attached to another relay instance.
+// from is the local session that sent the frame.
+type Forwarder interface {
+	Forward(from *Session, toID uint64, frame []byte) error
+}
+
+// SessionObserver is notified when a peer attaches to or detaches from this
+// relay instance.
+type SessionObserver func(id uint64, attached bool)
+
 type SessionManager struct {
 	mu        sync.RWMutex
 	sessions  map[uint64]*Session
 	quicConns map[uint64]*quic.Conn
+
+	forwarder Forwarder
+	observer  SessionObserver
+
+	limits     Limits
+	nsLimiters map[string]*nsLimiter
+	// remotes holds the limiters of sessions attached to other relays of
+	// the mesh that send frames to this one.
+	remotes map[remoteKey]*remoteSender
+	// secret verifies the tickets peers present; empty accepts every peer
+	// without a namespace.
+	secret string
+}
+
+// nsLimiter is the limiter shared by the sessions of one namespace. It is
+// dropped with the last of them.
+type nsLimiter struct {
+	*limiter
+	sessions int
+}
+
+type remoteKey struct {
+	relay string
+	id    uint64
+}
+
+// remoteSender is a session of another relay as seen by this one. Its
+// frames count against this relay's session limits and the limiter of its
+// namespace here, like those of a local session.
+type remoteSender struct {
+	namespace string
+	limiter   *limiter
+	nsLimiter *limiter
+	lastSeen  atomic.Int64
 }
 
 func NewSessionManager() *SessionManager {
 	return &SessionManager{
-		sessions:  make(map[uint64]*Session),
-		quicConns: make(map[uint64]*quic.Conn),
+		sessions:   make(map[uint64]*Session),
+		quicConns:  make(map[uint64]*quic.Conn),
+		nsLimiters: make(map[string]*nsLimiter),
+		remotes:    make(map[remoteKey]*remoteSender),
 	}
 }
 
-func (m *SessionManager) Register(id uint64, s *Session) {
+// SetLimits sets the limits applied to sessions registered afterwards. Must
+// be called before the servers start accepting.
+func (m *SessionManager) SetLimits(l Limits) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.sessions[id] = s
+	m.limits = l
+	m.nsLimiters = make(map[string]*nsLimiter)
+	m.remotes = make(map[remoteKey]*remoteSender)
 }
 
-func (m *SessionManager) Unregister(id uint64) {
+// SetSecret sets the secret shared with the manager. Peers must then present
+// a ticket signed with it, and their namespace is taken from the ticket.
+// Must be called before the servers start accepting.
+func (m *SessionManager) SetSecret(secret string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	delete(m.sessions, id)
-	delete(m.quicConns, id)
+	m.secret = secret
 }
 
-func (m *SessionManager) RegisterQUIC(id uint64, ctrl Stream, conn *quic.Conn) {
+// admit checks the ticket presented by the peer registering as id and
+// returns it. Without a secret no ticket is checked and the session has no
+// namespace and no limits of its own.
+func (m *SessionManager) admit(id uint64, ticket string) (Ticket, error) {
+	m.mu.RLock()
+	secret := m.secret
+	m.mu.RUnlock()
+	if secret == "" {
+		return Ticket{}, nil
+	}
+	t, err := ParseTicket(secret, ticket)
+	if err != nil {
+		return Ticket{}, err
+	}
+	if !t.matches(id) {
+		return Ticket{}, errInvalidTicket
+	}
+	return t, nil
+}
+
+// SetForwarder installs the fallback used by Relay when the target is not
+// attached locally. Must be called before the servers start accepting.
+func (m *SessionManager) SetForwarder(f Forwarder) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.sessions[id] = &Session{
-		ID:     id,
-		Stream: ctrl,
-		Type:   "QUIC",
+	m.forwarder = f
+}
+
+// SetObserver installs a callback for session attach/detach events. Must be
+// called before the servers start accepting.
+func (m *SessionManager) SetObserver(fn SessionObserver) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observer = fn
+}
+
+func (m *SessionManager) Register(id uint64, s *Session) {
+	m.mu.Lock()
+	m.attach(id, s)
+	observer := m.observer
+	m.mu.Unlock()
+
+	if observer != nil {
+		observer(id, true)
+	}
+}
+
+func (m *SessionManager) Unregister(id uint64) {
+	m.mu.Lock()
+	m.detach(id)
+	observer := m.observer
+	m.mu.Unlock()
+
+	if observer != nil {
+		observer(id, false)
+	}
+}
+
+// release unregisters s unless the peer has registered a newer session
+// under the same ID in the meantime.
+func (m *SessionManager) release(s *Session) {
+	m.mu.RLock()
+	current := m.sessions[s.ID] == s
+	m.mu.RUnlock()
+	if current {
+		m.Unregister(s.ID)
 	}
+}
+
+func (m *SessionManager) RegisterQUIC(id uint64, s *Session, conn *quic.Conn) {
+	m.mu.Lock()
+	s.Type = "QUIC"
+	m.attach(id, s)
 	m.quicConns[id] = conn
+	observer := m.observer
+	m.mu.Unlock()
+
+	if observer != nil {
+		observer(id, true)
+	}
+}
+
+// attach stores s under id and sets up its limiters and metrics.
+// Called with m.mu held.
+func (m *SessionManager) attach(id uint64, s *Session) {
+	if old := m.sessions[id]; old != nil {
+		sessionsGauge.WithLabelValues(old.Type).Dec()
+		m.releaseNamespace(old.Namespace)
+	}
+	delete(m.quicConns, id)
+
+	s.ID = id
+	if s.ConnectedAt.IsZero() {
+		s.ConnectedAt = time.Now()
+	}
+	s.limiter = newLimiter(minLimit(m.limits.SessionBandwidth, s.EgressBandwidth), m.limits.SessionFrameRate)
+	s.recvLimiter = newLimiter(s.IngressBandwidth, 0)
+	s.nsLimiter = m.acquireNamespace(s.Namespace)
+	s.metrics = newSessionMetrics(id)
+	m.sessions[id] = s
+	sessionsGauge.WithLabelValues(s.Type).Inc()
+}
+
+// detach removes the session under id. Called with m.mu held.
+func (m *SessionManager) detach(id uint64) {
+	if s := m.sessions[id]; s != nil {
+		sessionsGauge.WithLabelValues(s.Type).Dec()
+		deleteSessionMetrics(id)
+		m.releaseNamespace(s.Namespace)
+	}
+	delete(m.sessions, id)
+	delete(m.quicConns, id)
+}
+
+// acquireNamespace returns the limiter of namespace for one more session.
+// Called with m.mu held.
+func (m *SessionManager) acquireNamespace(namespace string) *limiter {
+	if namespace == "" {
+		return nil
+	}
+	l := m.nsLimiters[namespace]
+	if l == nil {
+		l = &nsLimiter{limiter: newLimiter(m.limits.NamespaceBandwidth, m.limits.NamespaceFrameRate)}
+		if l.limiter == nil {
+			return nil
+		}
+		m.nsLimiters[namespace] = l
+	}
+	l.sessions++
+	return l.limiter
+}
+
+// releaseNamespace undoes acquireNamespace. Called with m.mu held.
+func (m *SessionManager) releaseNamespace(namespace string) {
+	l := m.nsLimiters[namespace]
+	if l == nil {
+		return
+	}
+	if l.sessions--; l.sessions <= 0 {
+		delete(m.nsLimiters, namespace)
+	}
 }
 
 func (m *SessionManager) Get(id uint64) *Session {
@@ -64,20 +262,205 @@ func (m *SessionManager) Get(id uint64) *Session {
 	return m.sessions[id]
 }
 
-func (m *SessionManager) Relay(toID uint64, frame []byte) error {
+// relayFrom relays a frame received on session from to toID, after counting
+// it against the sender's limits.
+func (m *SessionManager) relayFrom(from *Session, toID uint64, frame []byte) error {
+	from.countIn(len(frame))
+	now := time.Now()
+	if !from.limiter.allow(now, len(frame)) {
+		from.stats.dropped.Add(1)
+		relayFailures.WithLabelValues("session_rate_limited").Inc()
+		return errRateLimited
+	}
+	if !from.nsLimiter.allow(now, len(frame)) {
+		from.stats.dropped.Add(1)
+		relayFailures.WithLabelValues("namespace_rate_limited").Inc()
+		return errRateLimited
+	}
+	return m.Relay(from, toID, frame)
+}
+
+// Relay delivers frame from the local session from to toID, handing it to
+// the mesh forwarder when the peer is attached to another relay instance.
+func (m *SessionManager) Relay(from *Session, toID uint64, frame []byte) error {
+	err := m.RelayLocal(toID, frame)
+	if err != errTargetNotFound {
+		return err
+	}
+
+	m.mu.RLock()
+	forwarder := m.forwarder
+	m.mu.RUnlock()
+	if forwarder == nil {
+		relayFailures.WithLabelValues("target_not_found").Inc()
+		return err
+	}
+	if err = forwarder.Forward(from, toID, frame); err != nil {
+		if errors.Is(err, errTargetNotFound) {
+			relayFailures.WithLabelValues("target_not_found").Inc()
+		} else {
+			relayFailures.WithLabelValues("mesh_forward_failed").Inc()
+		}
+	}
+	return err
+}
+
+// relayRemote delivers a frame that session from of another relay forwarded
+// over the mesh, after counting it against this relay's limits.
+func (m *SessionManager) relayRemote(relay string, from uint64, namespace string, toID uint64, frame []byte) error {
+	now := time.Now()
+	r := m.remoteSender(relay, from, namespace)
+	r.lastSeen.Store(now.UnixNano())
+	if !r.limiter.allow(now, len(frame)) {
+		relayFailures.WithLabelValues("session_rate_limited").Inc()
+		return errRateLimited
+	}
+	if !r.nsLimiter.allow(now, len(frame)) {
+		relayFailures.WithLabelValues("namespace_rate_limited").Inc()
+		return errRateLimited
+	}
+	return m.RelayLocal(toID, frame)
+}
+
+// remoteSender returns the limiters of session id of relay, creating them
+// on its first frame.
+func (m *SessionManager) remoteSender(relay string, id uint64, namespace string) *remoteSender {
+	key := remoteKey{relay: relay, id: id}
+	m.mu.RLock()
+	r := m.remotes[key]
+	m.mu.RUnlock()
+	if r != nil && r.namespace == namespace {
+		return r
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r = m.remotes[key]; r != nil {
+		if r.namespace == namespace {
+			return r
+		}
+		m.releaseNamespace(r.namespace)
+	}
+	r = &remoteSender{
+		namespace: namespace,
+		limiter:   newLimiter(m.limits.SessionBandwidth, m.limits.SessionFrameRate),
+		nsLimiter: m.acquireNamespace(namespace),
+	}
+	m.remotes[key] = r
+	return r
+}
+
+// expireRemotes drops remote senders that sent nothing since before.
+func (m *SessionManager) expireRemotes(before time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, r := range m.remotes {
+		if r.lastSeen.Load() < before.UnixNano() {
+			m.releaseNamespace(r.namespace)
+			delete(m.remotes, key)
+		}
+	}
+}
+
+// RelayLocal delivers frame to toID only if the peer is attached to this
+// instance. Frames received from the mesh use it so they are never forwarded
+// a second time.
+func (m *SessionManager) RelayLocal(toID uint64, frame []byte) error {
 	m.mu.RLock()
 	qconn := m.quicConns[toID]
 	session := m.sessions[toID]
 	m.mu.RUnlock()
 
-	if qconn != nil {
-		return qconn.SendDatagram(frame)
+	if session == nil {
+		return errTargetNotFound
+	}
+	if !session.recvLimiter.allow(time.Now(), len(frame)) {
+		session.stats.dropped.Add(1)
+		relayFailures.WithLabelValues("ingress_rate_limited").Inc()
+		return errRateLimited
 	}
-	if session != nil {
-		_, err := session.Stream.Write(frame)
+	if qconn != nil {
+		if err := qconn.SendDatagram(frame); err != nil {
+			quicDatagramDrops.WithLabelValues("send_failed").Inc()
+			return err
+		}
+	} else if err := session.write(frame); err != nil {
+		relayFailures.WithLabelValues("write_failed").Inc()
 		return err
 	}
-	return errors.New("lrp: relay target not found")
+	session.countOut(len(frame))
+	return nil
+}
+
+// SessionInfo describes a live session for the admin endpoint.
+type SessionInfo struct {
+	ID        uint64 `json:"id"`
+	Transport string `json:"transport"`
+	Namespace string `json:"namespace,omitempty"`
+	// EgressBandwidth and IngressBandwidth are the peer's limits in bytes
+	// per second, from its ticket.
+	EgressBandwidth  int64     `json:"egressBandwidth,omitempty"`
+	IngressBandwidth int64     `json:"ingressBandwidth,omitempty"`
+	RemoteAddr       string    `json:"remoteAddr,omitempty"`
+	ConnectedAt      time.Time `json:"connectedAt"`
+	BytesIn          uint64    `json:"bytesIn"`
+	BytesOut         uint64    `json:"bytesOut"`
+	FramesIn         uint64    `json:"framesIn"`
+	FramesOut        uint64    `json:"framesOut"`
+	Dropped          uint64    `json:"dropped"`
+}
+
+// Sessions lists the sessions attached to this instance, by ID.
+func (m *SessionManager) Sessions() []SessionInfo {
+	m.mu.RLock()
+	out := make([]SessionInfo, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		info := SessionInfo{
+			ID:               s.ID,
+			Transport:        s.Type,
+			Namespace:        s.Namespace,
+			EgressBandwidth:  s.EgressBandwidth,
+			IngressBandwidth: s.IngressBandwidth,
+			ConnectedAt:      s.ConnectedAt,
+			BytesIn:          s.stats.bytesIn.Load(),
+			BytesOut:         s.stats.bytesOut.Load(),
+			FramesIn:         s.stats.framesIn.Load(),
+			FramesOut:        s.stats.framesOut.Load(),
+			Dropped:          s.stats.dropped.Load(),
+		}
+		if s.Stream != nil {
+			if addr := s.Stream.RemoteAddr(); addr != nil {
+				info.RemoteAddr = addr.String()
+			}
+		}
+		out = append(out, info)
+	}
+	m.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Kick closes the session of id. The server loop serving it then
+// unregisters it; the peer is free to reconnect.
+func (m *SessionManager) Kick(id uint64) error {
+	m.mu.RLock()
+	s := m.sessions[id]
+	m.mu.RUnlock()
+	if s == nil {
+		return errSessionNotFound
+	}
+	return s.Stream.Close()
+}
+
+// LocalPeers returns the IDs of the peers attached to this instance.
+func (m *SessionManager) LocalPeers() []uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]uint64, 0, len(m.sessions))
+	for id := range m.sessions {
+		ids = append(ids, id)
+	}
+	return ids
 }
 
 func (m *SessionManager) ConnectedPeers() int {
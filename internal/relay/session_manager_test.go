@@ -16,12 +16,19 @@ package relay
 
 import (
 	"net"
+	"net/http"
+	"strings"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type mockStream struct {
 	written [][]byte
+	closed  bool
 }
 
 func (m *mockStream) Read(p []byte) (int, error) { return 0, nil }
@@ -29,7 +36,7 @@ func (m *mockStream) Write(p []byte) (int, error) {
 	m.written = append(m.written, p)
 	return len(p), nil
 }
-func (m *mockStream) Close() error         { return nil }
+func (m *mockStream) Close() error         { m.closed = true; return nil }
 func (m *mockStream) RemoteAddr() net.Addr { return nil }
 
 func TestSessionManager_RegisterAndGet(t *testing.T) {
@@ -58,7 +65,7 @@ func TestSessionManager_Unregister(t *testing.T) {
 
 func TestSessionManager_RelayToMissingTarget(t *testing.T) {
 	sm := NewSessionManager()
-	err := sm.Relay(99, []byte("data"))
+	err := sm.Relay(&Session{ID: 1}, 99, []byte("data"))
 	if err == nil {
 		t.Error("expected error when relaying to missing target")
 	}
@@ -69,7 +76,7 @@ func TestSessionManager_RelayTCP(t *testing.T) {
 	stream := &mockStream{}
 	sm.Register(99, &Session{ID: 99, Stream: stream, Type: "TCP"})
 
-	err := sm.Relay(99, []byte("hello"))
+	err := sm.Relay(&Session{ID: 1}, 99, []byte("hello"))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -109,3 +116,175 @@ func TestSessionManager_ConnectedPeers(t *testing.T) {
 		t.Errorf("expected 1 connected peer after unregister, got %d", sm.ConnectedPeers())
 	}
 }
+
+func TestSessionManager_Limits(t *testing.T) {
+	sm := NewSessionManager()
+	sm.SetLimits(Limits{SessionFrameRate: 2, NamespaceFrameRate: 3})
+	dst := &mockStream{}
+	sm.Register(9, &Session{ID: 9, Stream: dst, Type: "TCP"})
+	a := &Session{ID: 1, Stream: &mockStream{}, Type: "TCP", Namespace: "team-a"}
+	b := &Session{ID: 2, Stream: &mockStream{}, Type: "TCP", Namespace: "team-a"}
+	sm.Register(1, a)
+	sm.Register(2, b)
+
+	// Each session gets its own two frames; the namespace allows three.
+	var relayed, limited int
+	for _, s := range []*Session{a, a, a, b, b} {
+		switch err := sm.relayFrom(s, 9, []byte("x")); err {
+		case nil:
+			relayed++
+		case errRateLimited:
+			limited++
+		default:
+			t.Fatal(err)
+		}
+	}
+	if relayed != 3 || limited != 2 || len(dst.written) != 3 {
+		t.Fatalf("relayed %d, limited %d, written %d; want 3, 2, 3", relayed, limited, len(dst.written))
+	}
+	if a.stats.dropped.Load() != 1 || b.stats.dropped.Load() != 1 {
+		t.Errorf("dropped = %d/%d, want 1/1", a.stats.dropped.Load(), b.stats.dropped.Load())
+	}
+}
+
+func TestSessionManager_NamespaceLimiterEvicted(t *testing.T) {
+	sm := NewSessionManager()
+	sm.SetLimits(Limits{NamespaceFrameRate: 3})
+	sm.Register(1, &Session{ID: 1, Stream: &mockStream{}, Type: "TCP", Namespace: "team-a"})
+	sm.Register(2, &Session{ID: 2, Stream: &mockStream{}, Type: "TCP", Namespace: "team-a"})
+	// A reconnect replaces session 1 under another namespace.
+	sm.Register(1, &Session{ID: 1, Stream: &mockStream{}, Type: "TCP", Namespace: "team-b"})
+	if l := sm.nsLimiters["team-a"]; l == nil || l.sessions != 1 {
+		t.Fatalf("team-a limiter = %+v, want one session", l)
+	}
+	sm.Unregister(1)
+	sm.Unregister(2)
+	if len(sm.nsLimiters) != 0 {
+		t.Errorf("limiters left after the last session: %v", sm.nsLimiters)
+	}
+}
+
+func TestSessionManager_Admit(t *testing.T) {
+	sm := NewSessionManager()
+	if got, err := sm.admit(7, "anything"); err != nil || got != (Ticket{}) {
+		t.Errorf("without a secret: %+v, %v", got, err)
+	}
+	sm.SetSecret("secret")
+	ticket := Ticket{PeerID: 1<<32 | 7, Namespace: "team-a"}.Sign("secret")
+	payload, sig, _ := strings.Cut(ticket, ".")
+	forged, _, _ := strings.Cut(Ticket{PeerID: 7, Namespace: "team-b"}.Sign("other"), ".")
+	if got, err := sm.admit(7, ticket); err != nil || got.Namespace != "team-a" {
+		t.Errorf("valid ticket: %+v, %v", got, err)
+	}
+	// The limits come from the LatticePeer, not from the agent.
+	peer := &v1alpha1.LatticePeer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-c"},
+		Spec: v1alpha1.LatticePeerSpec{
+			PeerId:    "7",
+			Bandwidth: &v1alpha1.BandwidthLimit{EgressMbps: 8, IngressMbps: 16},
+		},
+	}
+	want := Ticket{PeerID: 7, Namespace: "team-c", EgressBandwidth: 1_000_000, IngressBandwidth: 2_000_000}
+	if got, err := sm.admit(7, PeerTicket("secret", peer)); err != nil || got != want {
+		t.Errorf("peer ticket: %+v, %v; want %+v", got, err, want)
+	}
+	for name, tc := range map[string]struct {
+		id     uint64
+		ticket string
+	}{
+		"none":         {7, ""},
+		"other peer":   {8, ticket},
+		"other secret": {7, Ticket{PeerID: 7, Namespace: "team-a"}.Sign("other")},
+		"tampered":     {7, forged + "." + sig},
+		"unsigned":     {7, payload},
+	} {
+		if _, err := sm.admit(tc.id, tc.ticket); err == nil {
+			t.Errorf("%s: ticket accepted", name)
+		}
+	}
+}
+
+func TestLimiter_Bandwidth(t *testing.T) {
+	l := newLimiter(1000, 0)
+	now := time.Now()
+	if !l.allow(now, minByteBurst) {
+		t.Fatal("a full burst should pass")
+	}
+	if l.allow(now, 1) {
+		t.Fatal("bucket should be empty")
+	}
+	if !l.allow(now.Add(time.Second), 1000) {
+		t.Error("bucket should refill at the configured rate")
+	}
+	if newLimiter(0, 0) != nil || !(*limiter)(nil).allow(now, 1<<20) {
+		t.Error("zero limits should be unlimited")
+	}
+}
+
+func TestSessionManager_SessionsAndKick(t *testing.T) {
+	sm := NewSessionManager()
+	stream := &mockStream{}
+	s := &Session{ID: 5, Stream: stream, Type: "TCP", Namespace: "team-a"}
+	sm.Register(5, s)
+	if err := sm.RelayLocal(5, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	list := sm.Sessions()
+	if len(list) != 1 || list[0].ID != 5 || list[0].Namespace != "team-a" || list[0].BytesOut != 5 || list[0].FramesOut != 1 {
+		t.Fatalf("Sessions() = %+v", list)
+	}
+	if err := sm.Kick(5); err != nil || !stream.closed {
+		t.Fatalf("Kick: err=%v closed=%v", err, stream.closed)
+	}
+	if err := sm.Kick(6); err != errSessionNotFound {
+		t.Errorf("Kick(unknown) = %v, want errSessionNotFound", err)
+	}
+
+	// A replaced session does not unregister its successor.
+	next := &Session{ID: 5, Stream: &mockStream{}, Type: "TCP"}
+	sm.Register(5, next)
+	sm.release(s)
+	if sm.Get(5) != next {
+		t.Error("release of a stale session removed the new one")
+	}
+	sm.release(next)
+	if sm.Get(5) != nil {
+		t.Error("release should remove the current session")
+	}
+}
+
+func TestSessionManager_PeerBandwidth(t *testing.T) {
+	sm := NewSessionManager()
+	sm.SetLimits(Limits{SessionBandwidth: 1 << 20})
+	dst := &mockStream{}
+	to := &Session{ID: 9, Stream: dst, Type: "TCP", IngressBandwidth: 1000}
+	from := &Session{ID: 1, Stream: &mockStream{}, Type: "TCP", EgressBandwidth: 1000}
+	sm.Register(9, to)
+	sm.Register(1, from)
+
+	// The peer's egress limit is tighter than the relay's.
+	if from.limiter.bytes.Limit() != 1000 {
+		t.Errorf("session limit = %v, want 1000", from.limiter.bytes.Limit())
+	}
+	if err := sm.RelayLocal(9, make([]byte, minByteBurst)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.RelayLocal(9, []byte("x")); err != errRateLimited {
+		t.Fatalf("RelayLocal over ingress limit = %v, want errRateLimited", err)
+	}
+	if len(dst.written) != 1 || to.stats.dropped.Load() != 1 {
+		t.Errorf("written %d, dropped %d; want 1, 1", len(dst.written), to.stats.dropped.Load())
+	}
+	if list := sm.Sessions(); list[1].IngressBandwidth != 1000 {
+		t.Errorf("Sessions() = %+v", list)
+	}
+}
+
+func TestRegisterInfoFromHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set(ticketHeader, "t")
+	if got := registerInfoFromHeader(h); got != (RegisterInfo{Ticket: "t"}) {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -14,7 +14,15 @@
 
 package relay
 
-import "net"
+import (
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 // Stream abstract the exact transport protocol
 type Stream interface {
@@ -28,4 +36,85 @@ type Session struct {
 	ID     uint64
 	Stream Stream
 	Type   string // TCP / QUIC / KCP
+
+	// Namespace is the workspace namespace of the peer's ticket, empty when
+	// the relay checks no tickets. Sessions of one namespace share the
+	// namespace limits.
+	Namespace   string
+	ConnectedAt time.Time
+
+	// EgressBandwidth and IngressBandwidth are the peer's limits in bytes
+	// per second, taken from its ticket; zero means unlimited.
+	// Egress tightens the session limit, ingress caps what is relayed to
+	// the peer.
+	EgressBandwidth  int64
+	IngressBandwidth int64
+
+	// wmu serialises frame writes: several senders and the mesh may relay
+	// to the same session concurrently.
+	wmu sync.Mutex
+
+	// Set by SessionManager.Register.
+	limiter     *limiter
+	nsLimiter   *limiter
+	recvLimiter *limiter
+	stats       sessionStats
+	metrics     sessionMetrics
+}
+
+// sessionStats are the live counters listed by the admin endpoint.
+type sessionStats struct {
+	bytesIn, bytesOut   atomic.Uint64
+	framesIn, framesOut atomic.Uint64
+	dropped             atomic.Uint64
+}
+
+// sessionMetrics caches the per-session Prometheus series.
+type sessionMetrics struct {
+	bytesIn, bytesOut   prometheus.Counter
+	framesIn, framesOut prometheus.Counter
+}
+
+func newSessionMetrics(id uint64) sessionMetrics {
+	label := strconv.FormatUint(id, 10)
+	return sessionMetrics{
+		bytesIn:   sessionBytes.WithLabelValues(label, "in"),
+		bytesOut:  sessionBytes.WithLabelValues(label, "out"),
+		framesIn:  sessionFrames.WithLabelValues(label, "in"),
+		framesOut: sessionFrames.WithLabelValues(label, "out"),
+	}
+}
+
+func deleteSessionMetrics(id uint64) {
+	label := strconv.FormatUint(id, 10)
+	for _, dir := range []string{"in", "out"} {
+		sessionBytes.DeleteLabelValues(label, dir)
+		sessionFrames.DeleteLabelValues(label, dir)
+	}
+}
+
+// write sends one complete frame on the session stream.
+func (s *Session) write(frame []byte) error {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	_, err := s.Stream.Write(frame)
+	return err
+}
+
+func (s *Session) countIn(n int) {
+	s.stats.bytesIn.Add(uint64(n))
+	s.stats.framesIn.Add(1)
+	if s.metrics.bytesIn != nil {
+		s.metrics.bytesIn.Add(float64(n))
+		s.metrics.framesIn.Inc()
+	}
+}
+
+func (s *Session) countOut(n int) {
+	s.stats.bytesOut.Add(uint64(n))
+	s.stats.framesOut.Add(1)
+	if s.metrics.bytesOut != nil {
+		s.metrics.bytesOut.Add(float64(n))
+		s.metrics.framesOut.Inc()
+	}
 }
@@ -0,0 +1,100 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+var errInvalidTicket = errors.New("lrp: invalid relay ticket")
+
+// Ticket is what the manager vouches for about a peer that registers with a
+// relay: its workspace and the bandwidth limits of its LatticePeer. The
+// manager signs it with the secret it shares with the relays and hands it to
+// the agent; the relay trusts the fields of a ticket, never what the peer
+// reports about itself.
+type Ticket struct {
+	PeerID    uint64 `json:"peer"`
+	Namespace string `json:"ns"`
+	// EgressBandwidth and IngressBandwidth are the peer's limits in bytes
+	// per second; zero means unlimited.
+	EgressBandwidth  int64 `json:"egress,omitempty"`
+	IngressBandwidth int64 `json:"ingress,omitempty"`
+}
+
+// Sign encodes t and appends an HMAC-SHA256 of it under secret.
+func (t Ticket) Sign(secret string) string {
+	body, _ := json.Marshal(t)
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(hmacSum(secret, []byte(payload)))
+}
+
+// ParseTicket verifies s under secret and decodes it.
+func ParseTicket(secret, s string) (Ticket, error) {
+	var t Ticket
+	payload, sig, ok := strings.Cut(s, ".")
+	if !ok {
+		return t, errInvalidTicket
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, hmacSum(secret, []byte(payload))) {
+		return t, errInvalidTicket
+	}
+	body, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || json.Unmarshal(body, &t) != nil {
+		return t, errInvalidTicket
+	}
+	return t, nil
+}
+
+// matches reports whether the ticket was issued for the session id. LRP
+// carries the low 32 bits of the peer ID.
+func (t Ticket) matches(id uint64) bool {
+	return uint32(t.PeerID) == uint32(id)
+}
+
+// hmacSum returns the HMAC-SHA256 of data under secret.
+func hmacSum(secret string, data []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// PeerTicket returns the signed ticket of peer, or "" when no secret is
+// configured or the peer has no ID yet.
+func PeerTicket(secret string, peer *v1alpha1.LatticePeer) string {
+	if secret == "" || peer.Spec.PeerId == "" {
+		return ""
+	}
+	id, err := strconv.ParseUint(peer.Spec.PeerId, 10, 64)
+	if err != nil {
+		return ""
+	}
+	t := Ticket{PeerID: id, Namespace: peer.Namespace}
+	if bw := peer.Spec.Bandwidth; bw != nil {
+		t.EgressBandwidth = infra.BytesPerSecond(int(bw.EgressMbps))
+		t.IngressBandwidth = infra.BytesPerSecond(int(bw.IngressMbps))
+	}
+	return t.Sign(secret)
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+//
+// Copyright 2026 The Lattice Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/server/dto"
+)
+
+// APITokenPrefix starts every API token, so they can be told apart from
+// session JWTs and spotted by secret scanners.
+const APITokenPrefix = "lat_"
+
+// IsAPIToken reports whether a bearer credential is an API token rather than
+// a session JWT.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// APIIdentity is who an API token acts as, and what it is limited to.
+type APIIdentity struct {
+	TokenID  string
+	UserID   string // the owning user, or the service account ID
+	Username string
+	Email    string
+	// SystemRole is platform_admin only for a token created with platform
+	// admin rights by a user who still has them.
+	SystemRole string
+	// ServiceAccount is set for service account tokens. They act only in
+	// WorkspaceID, with exactly Role.
+	ServiceAccount bool
+	// WorkspaceID restricts the token to one workspace; empty means any.
+	WorkspaceID string
+	// Role caps the token's workspace role; empty means no cap.
+	Role      dto.WorkspaceRole
+	ExpiresAt time.Time
+}
+
+// APITokenAuthenticator resolves API tokens to identities.
+type APITokenAuthenticator interface {
+	AuthenticateAPIToken(ctx context.Context, token, clientIP string) (*APIIdentity, error)
+}
+
+var (
+	ErrTokenWorkspace = errors.New("token is not valid for this workspace")
+	ErrTokenRole      = errors.New("token role is insufficient")
+	ErrTokenReadOnly  = errors.New("token is read-only")
+)
+
+// Permits checks the token's own limits for acting in wsID with role. The
+// owner's membership is checked separately, except for service accounts,
+// whose role is their membership.
+func (i *APIIdentity) Permits(wsID string, role dto.WorkspaceRole) error {
+	if i.WorkspaceID != "" && i.WorkspaceID != wsID {
+		return ErrTokenWorkspace
+	}
+	if i.Role != "" && dto.GetRoleWeight(i.Role) < dto.GetRoleWeight(role) {
+		return ErrTokenRole
+	}
+	return nil
+}
+
+// PermitsMethod rejects writes for viewer tokens, including on routes that
+// are not workspace-scoped.
+func (i *APIIdentity) PermitsMethod(method string) error {
+	if i.Role != dto.RoleViewer {
+		return nil
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+	return ErrTokenReadOnly
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"context"
+	"io"
+
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/models"
+	"github.com/alatticeio/lattice/internal/server/service"
+	"github.com/alatticeio/lattice/internal/server/vo"
+)
+
+// AIConversationController handles stored AI conversations and usage.
+type AIConversationController interface {
+	List(ctx context.Context, filter store.AIConversationFilter) (*dto.PageResult[vo.AIConversationVo], error)
+	Get(ctx context.Context, id string) (*vo.AIConversationVo, error)
+	Export(ctx context.Context, filter store.AIConversationFilter, w io.Writer) error
+	Usage(ctx context.Context, filter store.AIUsageFilter) ([]*models.AIUsage, error)
+}
+
+type aiConversationController struct {
+	svc service.AIConversationService
+}
+
+func NewAIConversationController(svc service.AIConversationService) AIConversationController {
+	return &aiConversationController{svc: svc}
+}
+
+func (c *aiConversationController) List(ctx context.Context, filter store.AIConversationFilter) (*dto.PageResult[vo.AIConversationVo], error) {
+	return c.svc.List(ctx, filter)
+}
+
+func (c *aiConversationController) Get(ctx context.Context, id string) (*vo.AIConversationVo, error) {
+	return c.svc.Get(ctx, id)
+}
+
+func (c *aiConversationController) Export(ctx context.Context, filter store.AIConversationFilter, w io.Writer) error {
+	return c.svc.Export(ctx, filter, w)
+}
+
+func (c *aiConversationController) Usage(ctx context.Context, filter store.AIUsageFilter) ([]*models.AIUsage, error) {
+	return c.svc.Usage(ctx, filter)
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"io"
 
 	"github.com/alatticeio/lattice/internal/agent/store"
 	"github.com/alatticeio/lattice/internal/server/dto"
@@ -13,6 +14,8 @@ import (
 // AuditController handles audit log queries.
 type AuditController interface {
 	List(ctx context.Context, filter store.AuditLogFilter) (*dto.PageResult[vo.AuditLogVo], error)
+	Verify(ctx context.Context) (*vo.AuditVerifyVo, error)
+	Export(ctx context.Context, filter store.AuditLogFilter, format string, w io.Writer) error
 }
 
 type auditController struct {
@@ -40,6 +43,14 @@ func (c *auditController) List(ctx context.Context, filter store.AuditLogFilter)
 	}, nil
 }
 
+func (c *auditController) Verify(ctx context.Context) (*vo.AuditVerifyVo, error) {
+	return c.svc.Verify(ctx)
+}
+
+func (c *auditController) Export(ctx context.Context, filter store.AuditLogFilter, format string, w io.Writer) error {
+	return c.svc.Export(ctx, filter, format, w)
+}
+
 func toAuditVo(l *models.AuditLog) vo.AuditLogVo {
 	return vo.AuditLogVo{
 		ID:           l.ID,
@@ -49,6 +60,7 @@ func toAuditVo(l *models.AuditLog) vo.AuditLogVo {
 		UserEmail:    l.UserEmail,
 		UserIP:       l.UserIP,
 		WorkspaceID:  l.WorkspaceID,
+		Source:       l.Source,
 		Action:       l.Action,
 		Resource:     l.Resource,
 		ResourceID:   l.ResourceID,
@@ -57,5 +69,7 @@ func toAuditVo(l *models.AuditLog) vo.AuditLogVo {
 		Status:       l.Status,
 		StatusCode:   l.StatusCode,
 		Detail:       l.Detail,
+		Seq:          l.Seq,
+		Hash:         l.Hash,
 	}
 }
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/resource"
+	"github.com/alatticeio/lattice/internal/server/service"
+	"github.com/alatticeio/lattice/internal/server/vo"
+)
+
+// BundleController exports and reconciles declarative workspace bundles.
+type BundleController interface {
+	Export(ctx context.Context, namespace string) (*dto.Bundle, error)
+	Diff(ctx context.Context, namespace string, bundle *dto.Bundle, prune bool) ([]vo.BundleChange, error)
+	Apply(ctx context.Context, namespace string, bundle *dto.Bundle, prune bool) ([]vo.BundleChange, error)
+}
+
+type bundleController struct {
+	svc service.BundleService
+}
+
+func (c *bundleController) Export(ctx context.Context, namespace string) (*dto.Bundle, error) {
+	return c.svc.Export(ctx, namespace)
+}
+
+func (c *bundleController) Diff(ctx context.Context, namespace string, bundle *dto.Bundle, prune bool) ([]vo.BundleChange, error) {
+	return c.svc.Diff(ctx, namespace, bundle, prune)
+}
+
+func (c *bundleController) Apply(ctx context.Context, namespace string, bundle *dto.Bundle, prune bool) ([]vo.BundleChange, error) {
+	return c.svc.Apply(ctx, namespace, bundle, prune)
+}
+
+// NewBundleController constructs a BundleController.
+func NewBundleController(c *resource.Client, st store.Store) BundleController {
+	return &bundleController{
+		svc: service.NewBundleService(c, st),
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/monitor"
 	"github.com/alatticeio/lattice/internal/server/models"
 	"github.com/alatticeio/lattice/internal/server/service"
 )
@@ -11,10 +12,11 @@ import (
 // CustomMetricController defines the custom metric operations.
 type CustomMetricController interface {
 	List(ctx context.Context, wsID string) ([]*models.CustomMetric, error)
-	Get(ctx context.Context, id string) (*models.CustomMetric, error)
+	Get(ctx context.Context, wsID, id string) (*models.CustomMetric, error)
 	Create(ctx context.Context, wsID, createdBy string, req service.CreateCustomMetricRequest) (*models.CustomMetric, error)
-	Update(ctx context.Context, id string, req service.CreateCustomMetricRequest) (*models.CustomMetric, error)
-	Delete(ctx context.Context, id string) error
+	Update(ctx context.Context, wsID, id string, req service.CreateCustomMetricRequest) (*models.CustomMetric, error)
+	Delete(ctx context.Context, wsID, id string) error
+	Query(ctx context.Context, wsID, id string, req service.QueryCustomMetricRequest) (any, error)
 }
 
 type customMetricController struct {
@@ -22,10 +24,10 @@ type customMetricController struct {
 	store store.Store
 }
 
-// NewCustomMetricController creates a new CustomMetricController.
-func NewCustomMetricController(st store.Store) CustomMetricController {
+// NewCustomMetricController creates a new CustomMetricController. mon may be nil.
+func NewCustomMetricController(st store.Store, mon *monitor.Monitor) CustomMetricController {
 	return &customMetricController{
-		svc:   service.NewCustomMetricService(st),
+		svc:   service.NewCustomMetricService(st, mon),
 		store: st,
 	}
 }
@@ -34,18 +36,22 @@ func (c *customMetricController) List(ctx context.Context, wsID string) ([]*mode
 	return c.svc.List(ctx, wsID)
 }
 
-func (c *customMetricController) Get(ctx context.Context, id string) (*models.CustomMetric, error) {
-	return c.svc.Get(ctx, id)
+func (c *customMetricController) Get(ctx context.Context, wsID, id string) (*models.CustomMetric, error) {
+	return c.svc.Get(ctx, wsID, id)
 }
 
 func (c *customMetricController) Create(ctx context.Context, wsID, createdBy string, req service.CreateCustomMetricRequest) (*models.CustomMetric, error) {
 	return c.svc.Create(ctx, wsID, createdBy, req)
 }
 
-func (c *customMetricController) Update(ctx context.Context, id string, req service.CreateCustomMetricRequest) (*models.CustomMetric, error) {
-	return c.svc.Update(ctx, id, req)
+func (c *customMetricController) Update(ctx context.Context, wsID, id string, req service.CreateCustomMetricRequest) (*models.CustomMetric, error) {
+	return c.svc.Update(ctx, wsID, id, req)
 }
 
-func (c *customMetricController) Delete(ctx context.Context, id string) error {
-	return c.svc.Delete(ctx, id)
+func (c *customMetricController) Delete(ctx context.Context, wsID, id string) error {
+	return c.svc.Delete(ctx, wsID, id)
+}
+
+func (c *customMetricController) Query(ctx context.Context, wsID, id string, req service.QueryCustomMetricRequest) (any, error) {
+	return c.svc.Query(ctx, wsID, id, req)
 }
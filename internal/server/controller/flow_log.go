@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/models"
+	"github.com/alatticeio/lattice/internal/server/service"
+	"github.com/alatticeio/lattice/internal/server/vo"
+)
+
+// FlowLogController handles flow log queries.
+type FlowLogController interface {
+	List(ctx context.Context, filter store.FlowLogFilter) (*dto.PageResult[vo.FlowLogVo], error)
+}
+
+type flowLogController struct {
+	svc service.FlowLogService
+}
+
+func NewFlowLogController(svc service.FlowLogService) FlowLogController {
+	return &flowLogController{svc: svc}
+}
+
+func (c *flowLogController) List(ctx context.Context, filter store.FlowLogFilter) (*dto.PageResult[vo.FlowLogVo], error) {
+	logs, total, err := c.svc.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	vos := make([]vo.FlowLogVo, 0, len(logs))
+	for _, l := range logs {
+		vos = append(vos, toFlowLogVo(l))
+	}
+	return &dto.PageResult[vo.FlowLogVo]{
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		Total:    total,
+		List:     vos,
+	}, nil
+}
+
+func toFlowLogVo(l *models.FlowLog) vo.FlowLogVo {
+	return vo.FlowLogVo{
+		ID:            l.ID,
+		WorkspaceID:   l.WorkspaceID,
+		Peer:          l.Peer,
+		IntervalStart: l.IntervalStart.UTC().Format(time.RFC3339),
+		IntervalEnd:   l.IntervalEnd.UTC().Format(time.RFC3339),
+		Direction:     l.Direction,
+		Protocol:      l.Protocol,
+		LocalIP:       l.LocalIP,
+		LocalPort:     l.LocalPort,
+		RemoteIP:      l.RemoteIP,
+		RemotePort:    l.RemotePort,
+		RemotePeer:    l.RemotePeer,
+		Verdict:       l.Verdict,
+		Policy:        l.Policy,
+		Established:   l.Established,
+		Packets:       l.Packets,
+		Bytes:         l.Bytes,
+	}
+}
@@ -41,6 +41,7 @@ type PeerController interface {
 
 	ListPeers(ctx context.Context, pageParam *dto.PageRequest) (*dto.PageResult[vo.PeerVo], error)
 	UpdatePeer(ctx context.Context, peerDto *dto.PeerDto) (*vo.PeerVo, error)
+	UpdateLabels(ctx context.Context, namespace, name string, labels map[string]string) error
 	DisablePeer(ctx context.Context, namespace, name string) error
 	EnablePeer(ctx context.Context, namespace, name string) error
 	DeletePeer(ctx context.Context, namespace, name string) error
@@ -62,6 +63,10 @@ func (p *peerController) UpdatePeer(ctx context.Context, peerDto *dto.PeerDto) (
 	return p.peerService.UpdatePeer(ctx, peerDto)
 }
 
+func (p *peerController) UpdateLabels(ctx context.Context, namespace, name string, labels map[string]string) error {
+	return p.peerService.UpdateLabels(ctx, namespace, name, labels)
+}
+
 func (p *peerController) ListPeers(ctx context.Context, pageParam *dto.PageRequest) (*dto.PageResult[vo.PeerVo], error) {
 	return p.peerService.ListPeers(ctx, pageParam)
 }
@@ -16,6 +16,7 @@ type PolicyController interface {
 	Submit(ctx context.Context, wsID, createdBy, createdByName string, policyDto *dto.PolicyDto) (*models.Policy, error)
 	ApplyDirect(ctx context.Context, wsID, operatorID, operatorName string, policyDto *dto.PolicyDto) (*vo.PolicyVo, error)
 	Apply(ctx context.Context, policyID string) error
+	Lint(ctx context.Context, wsID string, policyDto *dto.PolicyDto) ([]vo.AuditFinding, error)
 	DeletePolicy(ctx context.Context, name string) error
 }
 
@@ -39,6 +40,10 @@ func (p *policyController) Apply(ctx context.Context, policyID string) error {
 	return p.policyService.Apply(ctx, policyID)
 }
 
+func (p *policyController) Lint(ctx context.Context, wsID string, policyDto *dto.PolicyDto) ([]vo.AuditFinding, error) {
+	return p.policyService.Lint(ctx, wsID, policyDto)
+}
+
 func (p *policyController) DeletePolicy(ctx context.Context, name string) error {
 	return p.policyService.DeletePolicy(ctx, name)
 }
@@ -85,6 +85,7 @@ func (c *workspaceMemberController) List(ctx context.Context, workspaceID string
 			Role:     m.Role,
 			Provider: provider,
 			Status:   m.Status,
+			Source:   m.Source,
 			JoinedAt: joinedAt,
 		})
 	}
@@ -28,7 +28,7 @@ var errProRequired = errors.New("Dex OIDC/SSO is a Lattice Pro feature — upgra
 // Dex stub: satisfies call sites in management/server/api.go.
 type Dex struct{}
 
-func NewDex(_ service.UserService) (*Dex, error) {
+func NewDex(_ service.UserService, _ service.GroupSyncService) (*Dex, error) {
 	return nil, errProRequired
 }
 
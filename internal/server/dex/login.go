@@ -29,7 +29,7 @@ var oauth2Config = oauth2.Config{
 	ClientSecret: "lattice-secret-key", // 必须对应 dex-oauth2Config.yaml
 	Endpoint:     endpoint,
 	RedirectURL:  "http://localhost:8080/auth/callback",
-	Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+	Scopes:       []string{oidc.ScopeOpenID, "profile", "email", "groups"},
 }
 
 type Dex struct {
@@ -37,15 +37,17 @@ type Dex struct {
 	oauth2Config *oauth2.Config
 
 	userService service.UserService
+	groupSync   service.GroupSyncService
 }
 
-func NewDex(userService service.UserService) (*Dex, error) {
+func NewDex(userService service.UserService, groupSync service.GroupSyncService) (*Dex, error) {
 	veryfier, err := InitVerifier()
 	if err != nil {
 		return nil, err
 	}
 	return &Dex{
 		userService:  userService,
+		groupSync:    groupSync,
 		oauth2Config: &oauth2Config,
 		verifier:     veryfier,
 	}, nil
@@ -99,12 +101,29 @@ func (d *Dex) Login(c *gin.Context) {
 	//	return
 	//}
 
-	user, err := d.userService.OnboardExternalUser(ctx, "dex", dexClaims.Subject, dexClaims.Email, config.GlobalConfig.Dex.AdminEmails)
+	user, err := d.userService.OnboardExternalUser(ctx, service.GroupSyncProvider, dexClaims.Subject, dexClaims.Email, config.GlobalConfig.Dex.AdminEmails)
 	if err != nil {
 		resp.Error(c, fmt.Sprintf("Failed to get user: %v", err))
 		return
 	}
 
+	// 5.1 按 IdP 组重新计算工作空间成员关系，离开组的用户在此失去权限
+	var rawClaims map[string]any
+	if err = idToken.Claims(&rawClaims); err != nil {
+		resp.Error(c, "Failed to parse claims")
+		return
+	}
+	groups := service.GroupsFromClaims(rawClaims, config.GlobalConfig.Dex.GroupsClaim)
+	if err = d.groupSync.SyncLogin(ctx, service.GroupSyncProvider, dexClaims.Subject, groups); err != nil {
+		resp.Error(c, fmt.Sprintf("Failed to sync groups: %v", err))
+		return
+	}
+	// 组同步可能改变了 platform_admin，签发 JWT 前重新读取
+	if user, err = d.userService.GetMe(ctx, user.ID); err != nil {
+		resp.Error(c, fmt.Sprintf("Failed to get user: %v", err))
+		return
+	}
+
 	// 6. 签发你自己的业务 JWT (给前端后续请求使用)
 	businessToken, _ := utils.GenerateBusinessJWT(user.ID, user.Email, user.Username, string(user.SystemRole))
 
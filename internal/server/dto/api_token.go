@@ -0,0 +1,29 @@
+package dto
+
+// APITokenDto creates a personal access token or a service account token.
+type APITokenDto struct {
+	Name string `json:"name" binding:"required,max=64"`
+
+	// ExpiresInDays is the token lifetime. 0 uses the configured default;
+	// -1 asks for a token that never expires, if the configuration allows it.
+	ExpiresInDays int `json:"expiresInDays,omitempty"`
+
+	// WorkspaceID restricts a personal token to one workspace. Service
+	// account tokens are always restricted to the account's workspace.
+	WorkspaceID string `json:"workspaceId,omitempty"`
+
+	// Role caps the token below its owner's role, e.g. "viewer" for a
+	// read-only token. Empty keeps the owner's role.
+	Role WorkspaceRole `json:"role,omitempty"`
+
+	// PlatformAdmin keeps the creator's platform admin rights. Only platform
+	// admins may set it, and only on personal tokens.
+	PlatformAdmin bool `json:"platformAdmin,omitempty"`
+}
+
+// ServiceAccountDto creates a workspace service account.
+type ServiceAccountDto struct {
+	Name        string        `json:"name" binding:"required,max=64"`
+	Description string        `json:"description,omitempty"`
+	Role        WorkspaceRole `json:"role" binding:"required"`
+}
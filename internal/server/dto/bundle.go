@@ -0,0 +1,69 @@
+package dto
+
+import "github.com/alatticeio/lattice/api/v1alpha1"
+
+const (
+	BundleAPIVersion = "lattice.io/v1"
+	BundleKind       = "WorkspaceBundle"
+)
+
+// Bundle is the declarative configuration of one workspace, as written by
+// `lattice export` and reconciled by `lattice apply`. Objects are keyed by
+// name within their section.
+type Bundle struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Namespace is the workspace the bundle was exported from. apply uses
+	// it when -n is not given.
+	Namespace string `json:"namespace,omitempty"`
+
+	Networks   []BundleNetwork   `json:"networks,omitempty"`
+	Policies   []BundlePolicy    `json:"policies,omitempty"`
+	Peers      []BundlePeer      `json:"peers,omitempty"`
+	Relays     []BundleRelay     `json:"relays,omitempty"`
+	AlertRules []BundleAlertRule `json:"alertRules,omitempty"`
+}
+
+// BundleNetwork is a LatticeNetwork of the workspace.
+type BundleNetwork struct {
+	Name string                      `json:"name"`
+	Spec v1alpha1.LatticeNetworkSpec `json:"spec"`
+}
+
+// BundlePolicy is a LatticePolicy; the spec fields are inlined.
+type BundlePolicy struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	PolicyTypes []string `json:"policyTypes,omitempty"`
+	v1alpha1.LatticePolicySpec
+}
+
+// BundlePeer holds the user-managed settings of a registered peer. Peers are
+// created by enrolling agents, so apply only updates existing ones.
+type BundlePeer struct {
+	Name      string                   `json:"name"`
+	Labels    map[string]string        `json:"labels,omitempty"`
+	Bandwidth *v1alpha1.BandwidthLimit `json:"bandwidth,omitempty"`
+	QoSClass  v1alpha1.QoSClass        `json:"qosClass,omitempty"`
+}
+
+// BundleRelay is a LatticeRelayServer that serves the workspace.
+type BundleRelay struct {
+	Name string                          `json:"name"`
+	Spec v1alpha1.LatticeRelayServerSpec `json:"spec"`
+}
+
+// BundleAlertRule is an alert rule. Channels are referenced by name.
+type BundleAlertRule struct {
+	Name       string   `json:"name"`
+	MetricType string   `json:"metricType"`
+	Operator   string   `json:"operator"`
+	Threshold  float64  `json:"threshold"`
+	Duration   string   `json:"duration"`
+	Lookback   string   `json:"lookback"`
+	GroupBy    []string `json:"groupBy,omitempty"`
+	ForEach    bool     `json:"forEach,omitempty"`
+	Channels   []string `json:"channels,omitempty"`
+	Severity   string   `json:"severity"`
+	Message    string   `json:"message,omitempty"`
+}
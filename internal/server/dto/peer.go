@@ -16,6 +16,8 @@ package dto
 
 import (
 	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
 )
 
 type SearchParams struct {
@@ -52,6 +54,11 @@ type PeerDto struct {
 	Namespace   string            `json:"namespace,omitempty"`
 	Labels      map[string]string `json:"labels,omitempty"`
 	DisplayName string            `json:"displayName,omitempty"`
+	// Bandwidth replaces the node's bandwidth limits when set; an empty
+	// limit removes them.
+	Bandwidth *v1alpha1.BandwidthLimit `json:"bandwidth,omitempty"`
+	// QoSClass replaces the node's QoS class when set; "" removes it.
+	QoSClass *v1alpha1.QoSClass `json:"qosClass,omitempty"`
 }
 
 type TokenDto struct {
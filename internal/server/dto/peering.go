@@ -13,4 +13,8 @@ type PeeringDto struct {
 
 	// PeeringMode controls traffic forwarding: "gateway" (default) or "mesh".
 	PeeringMode string `json:"peeringMode,omitempty"`
+
+	// GatewayMode controls how several gateways of a network are used:
+	// "active-standby" (default) or "active-active".
+	GatewayMode string `json:"gatewayMode,omitempty"`
 }
@@ -0,0 +1,24 @@
+package dto
+
+// Workflow payloads for the requests the AI assistant submits. A payload is
+// stored as JSON on the WorkflowRequest and read back by the executor
+// registered for its resource type and action once the request is approved.
+
+// PeerLabelsPayload is the payload of a "peer"/"update" request. A label
+// with an empty value is removed.
+type PeerLabelsPayload struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Labels    map[string]string `json:"labels"`
+}
+
+// PeerPayload is the payload of a "peer"/"disable" request.
+type PeerPayload struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// TokenPayload is the payload of a "token"/"create" request.
+type TokenPayload struct {
+	WorkspaceID string `json:"workspaceId"`
+}
@@ -83,7 +83,11 @@ type anthropicTool struct {
 type anthropicResponse struct {
 	Content    []anthropicBlock `json:"content"`
 	StopReason string           `json:"stop_reason"`
-	Error      *struct {
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
 		Type    string `json:"type"`
 		Message string `json:"message"`
 	} `json:"error,omitempty"`
@@ -203,7 +207,10 @@ func toAnthropicMessage(m Message) (anthropicMessage, error) {
 }
 
 func fromAnthropicResponse(ar anthropicResponse) *Response {
-	r := &Response{StopReason: StopReasonEndTurn}
+	r := &Response{
+		StopReason: StopReasonEndTurn,
+		Usage:      Usage{InputTokens: ar.Usage.InputTokens, OutputTokens: ar.Usage.OutputTokens},
+	}
 	if ar.StopReason == "tool_use" {
 		r.StopReason = StopReasonToolUse
 	}
@@ -1,6 +1,8 @@
 // Package llm provides a provider-agnostic LLM client interface.
-// Supported providers: Anthropic (native API) and any OpenAI-compatible service
-// (DeepSeek, OpenAI, self-hosted models).
+// Supported providers: Anthropic (native API), any OpenAI-compatible service
+// (DeepSeek, OpenAI, self-hosted models), local llama.cpp / Ollama servers
+// with or without tool-calling support, and a fixture provider that replays
+// recorded conversations for tests and offline demos.
 package llm
 
 import (
@@ -63,6 +65,19 @@ type Response struct {
 	Content    string     `json:"content"`
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 	StopReason string     `json:"stop_reason"`
+	Usage      Usage      `json:"usage"`
+}
+
+// Usage is the token count a provider reports for one completion. Providers
+// that report none leave it zero.
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Total returns the input and output tokens together.
+func (u Usage) Total() int {
+	return u.InputTokens + u.OutputTokens
 }
 
 // HasToolCalls reports whether the response contains tool calls to execute.
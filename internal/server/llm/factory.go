@@ -6,8 +6,33 @@ import (
 )
 
 // NewClient creates an LLMClient from the AI configuration.
-// Returns an error if the provider is unknown or APIKey is empty.
+// Returns an error if the provider is unknown or a hosted provider has no
+// APIKey. With RecordFixture set, the completions are recorded to that file.
 func NewClient(cfg config.AIConfig) (Client, error) {
+	c, err := newProviderClient(cfg)
+	if err != nil || cfg.RecordFixture == "" || cfg.Provider == "fixture" {
+		return c, err
+	}
+	return NewRecorder(c, cfg.RecordFixture)
+}
+
+func newProviderClient(cfg config.AIConfig) (Client, error) {
+	switch cfg.Provider {
+	case "fixture":
+		if cfg.Fixture == "" {
+			return nil, fmt.Errorf("ai.fixture is not configured")
+		}
+		f, err := LoadFixture(cfg.Fixture)
+		if err != nil {
+			return nil, err
+		}
+		return NewFixtureClient(f), nil
+
+	case "local":
+		// Self-hosted llama.cpp / Ollama — no API key needed
+		return NewLocalClient(cfg.BaseURL, cfg.APIKey, cfg.Model, cfg.ToolCalling)
+	}
+
 	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("ai.api-key is not configured")
 	}
@@ -0,0 +1,161 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Fixture is a set of recorded conversations that FixtureClient replays.
+//
+// Each turn answers one user message. Within a turn, the n-th completion
+// requested after the user message gets the n-th response, so a turn holds
+// the tool calls the model made followed by its final answer:
+//
+//	name: demo
+//	turns:
+//	  - match: token
+//	    responses:
+//	      - tool_calls: [{name: create_token, input: {}}]
+//	      - content: 已提交审批请求。
+//	  - responses:
+//	      - content: 这是一个离线演示。
+type Fixture struct {
+	Name  string        `json:"name,omitempty"`
+	Turns []FixtureTurn `json:"turns"`
+}
+
+// FixtureTurn holds the responses to a user message containing Match. Turns
+// are tried in order, and an empty Match answers any message.
+type FixtureTurn struct {
+	Match     string     `json:"match,omitempty"`
+	Responses []Response `json:"responses"`
+}
+
+// LoadFixture reads a fixture from a YAML or JSON file.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture: %w", err)
+	}
+	var f Fixture
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse fixture %s: %w", path, err)
+	}
+	if len(f.Turns) == 0 {
+		return nil, fmt.Errorf("fixture %s has no turns", path)
+	}
+	return &f, nil
+}
+
+// FixtureClient implements Client by replaying a Fixture. It keeps no state:
+// the response is chosen from the request alone, so replies are the same
+// for every run, and a resumed conversation picks up where it left off.
+type FixtureClient struct {
+	fixture *Fixture
+}
+
+func NewFixtureClient(f *Fixture) *FixtureClient {
+	return &FixtureClient{fixture: f}
+}
+
+func (c *FixtureClient) Complete(_ context.Context, req *Request) (*Response, error) {
+	message, step := turnPosition(req.Messages)
+	for ti, turn := range c.fixture.Turns {
+		if !strings.Contains(message, turn.Match) {
+			continue
+		}
+		if step >= len(turn.Responses) {
+			return nil, fmt.Errorf("fixture %q: turn %d has no response %d", c.fixture.Name, ti, step+1)
+		}
+		resp := turn.Responses[step]
+		resp.ToolCalls = append([]ToolCall(nil), resp.ToolCalls...)
+		for i := range resp.ToolCalls {
+			if resp.ToolCalls[i].ID == "" {
+				resp.ToolCalls[i].ID = fmt.Sprintf("call_%d_%d_%d", ti, step, i)
+			}
+			if len(resp.ToolCalls[i].Input) == 0 {
+				resp.ToolCalls[i].Input = []byte("{}")
+			}
+		}
+		if resp.StopReason == "" {
+			resp.StopReason = StopReasonEndTurn
+			if resp.HasToolCalls() {
+				resp.StopReason = StopReasonToolUse
+			}
+		}
+		return &resp, nil
+	}
+	return nil, fmt.Errorf("fixture %q: no turn matches %q", c.fixture.Name, message)
+}
+
+// turnPosition returns the last user message of msgs and how many assistant
+// messages follow it.
+func turnPosition(msgs []Message) (string, int) {
+	step := 0
+	for i := len(msgs) - 1; i >= 0; i-- {
+		switch msgs[i].Role {
+		case RoleUser:
+			return msgs[i].Content, step
+		case RoleAssistant:
+			step++
+		}
+	}
+	return "", step
+}
+
+// Recorder wraps a Client and records its responses as a Fixture, written
+// to path after every completion. Each user message becomes a turn that
+// matches the whole message. Concurrent conversations would interleave, so
+// record one at a time.
+type Recorder struct {
+	Client
+	path string
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// NewRecorder records the completions of inner to path. An existing fixture
+// at path is extended.
+func NewRecorder(inner Client, path string) (*Recorder, error) {
+	r := &Recorder{Client: inner, path: path}
+	if _, err := os.Stat(path); err == nil {
+		f, err := LoadFixture(path)
+		if err != nil {
+			return nil, err
+		}
+		r.fixture = *f
+	}
+	return r, nil
+}
+
+func (r *Recorder) Complete(ctx context.Context, req *Request) (*Response, error) {
+	resp, err := r.Client.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	message, step := turnPosition(req.Messages)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	turns := r.fixture.Turns
+	if step == 0 || len(turns) == 0 || turns[len(turns)-1].Match != message {
+		r.fixture.Turns = append(turns, FixtureTurn{Match: message})
+	}
+	last := &r.fixture.Turns[len(r.fixture.Turns)-1]
+	last.Responses = append(last.Responses, *resp)
+
+	data, err := yaml.Marshal(r.fixture)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(r.path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("write fixture: %w", err)
+	}
+	return resp, nil
+}
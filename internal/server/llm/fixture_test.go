@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testFixture = `
+name: demo
+turns:
+  - match: token
+    responses:
+      - tool_calls:
+          - name: create_token
+            input: {}
+        usage: {input_tokens: 100, output_tokens: 10}
+      - content: 已提交审批请求。
+  - responses:
+      - content: 这是一个离线演示。
+`
+
+func loadTestFixture(t *testing.T) *Fixture {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "demo.yaml")
+	if err := os.WriteFile(path, []byte(testFixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFixtureClient_Replays(t *testing.T) {
+	c := NewFixtureClient(loadTestFixture(t))
+	ctx := context.Background()
+
+	msgs := []Message{{Role: RoleUser, Content: "帮我创建一个 token"}}
+	resp, err := c.Complete(ctx, &Request{Messages: msgs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "create_token" || resp.ToolCalls[0].ID == "" ||
+		string(resp.ToolCalls[0].Input) != "{}" || resp.StopReason != StopReasonToolUse || resp.Usage.InputTokens != 100 {
+		t.Fatalf("first response = %+v", resp)
+	}
+
+	// The next completion of the same turn gets the next response.
+	msgs = append(msgs,
+		Message{Role: RoleAssistant, ToolCalls: resp.ToolCalls},
+		Message{Role: RoleTool, ToolResults: []ToolResult{{ToolCallID: resp.ToolCalls[0].ID, Content: "ok"}}},
+	)
+	resp, err = c.Complete(ctx, &Request{Messages: msgs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "已提交审批请求。" || resp.StopReason != StopReasonEndTurn {
+		t.Fatalf("second response = %+v", resp)
+	}
+
+	// A turn without more responses is an error.
+	msgs = append(msgs, Message{Role: RoleAssistant, Content: resp.Content})
+	if _, err := c.Complete(ctx, &Request{Messages: msgs}); err == nil {
+		t.Error("expected an error past the last response")
+	}
+
+	// Other messages fall through to the catch-all turn.
+	resp, err = c.Complete(ctx, &Request{Messages: append(msgs, Message{Role: RoleUser, Content: "你好"})})
+	if err != nil || resp.Content != "这是一个离线演示。" {
+		t.Errorf("catch-all response = %+v, %v", resp, err)
+	}
+}
+
+func TestRecorder_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recorded.yaml")
+	rec, err := NewRecorder(NewFixtureClient(loadTestFixture(t)), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	msgs := []Message{{Role: RoleUser, Content: "token please"}}
+	first, err := rec.Complete(ctx, &Request{Messages: msgs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs = append(msgs, Message{Role: RoleAssistant, ToolCalls: first.ToolCalls}, Message{Role: RoleTool})
+	if _, err := rec.Complete(ctx, &Request{Messages: msgs}); err != nil {
+		t.Fatal(err)
+	}
+
+	recorded, err := LoadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded.Turns) != 1 || recorded.Turns[0].Match != "token please" || len(recorded.Turns[0].Responses) != 2 {
+		t.Fatalf("recorded = %+v", recorded)
+	}
+
+	// The recording replays the same conversation.
+	resp, err := NewFixtureClient(recorded).Complete(ctx, &Request{Messages: msgs[:1]})
+	if err != nil || resp.ToolCalls[0].ID != first.ToolCalls[0].ID || string(resp.ToolCalls[0].Input) != "{}" {
+		t.Errorf("replayed = %+v, %v", resp, err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "create_token") {
+		t.Errorf("fixture file:\n%s", data)
+	}
+}
@@ -0,0 +1,293 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const defaultLocalBaseURL = "http://localhost:11434/v1"
+
+// Tool-calling modes of LocalClient (ai.tool-calling).
+const (
+	ToolCallingAuto   = "auto"
+	ToolCallingNative = "native"
+	ToolCallingPrompt = "prompt"
+)
+
+// LocalClient implements Client for a self-hosted OpenAI-compatible server
+// such as llama.cpp or Ollama. Many local models, or servers started without
+// a tool-capable chat template, reject the tools field; for those the tools
+// are described in the system prompt instead and the model answers with a
+// JSON tool call, which LocalClient turns back into ToolCalls.
+type LocalClient struct {
+	oai  *OpenAICompatClient
+	mode string
+
+	mu     sync.Mutex
+	native *bool // tool-calling support, once detected
+}
+
+func NewLocalClient(baseURL, apiKey, model, mode string) (*LocalClient, error) {
+	if baseURL == "" {
+		baseURL = defaultLocalBaseURL
+	}
+	switch mode {
+	case "":
+		mode = ToolCallingAuto
+	case ToolCallingAuto, ToolCallingNative, ToolCallingPrompt:
+	default:
+		return nil, fmt.Errorf("unknown ai.tool-calling %q, want auto, native or prompt", mode)
+	}
+	return &LocalClient{
+		oai:  NewOpenAICompatClient(strings.TrimSuffix(baseURL, "/"), apiKey, model),
+		mode: mode,
+	}, nil
+}
+
+func (c *LocalClient) Complete(ctx context.Context, req *Request) (*Response, error) {
+	if !usesTools(req) {
+		return c.oai.Complete(ctx, req)
+	}
+	native, err := c.nativeTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if native {
+		return c.oai.Complete(ctx, req)
+	}
+	resp, err := c.oai.Complete(ctx, promptToolRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return parsePromptToolCalls(resp, req), nil
+}
+
+// usesTools reports whether req offers tools or carries earlier tool calls.
+func usesTools(req *Request) bool {
+	if len(req.Tools) > 0 {
+		return true
+	}
+	for _, m := range req.Messages {
+		if len(m.ToolCalls) > 0 || len(m.ToolResults) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// nativeTools reports whether the server takes the tools field. In auto mode
+// the answer is detected on first use and kept once the server gives a
+// definite one; otherwise it is asked again next time.
+func (c *LocalClient) nativeTools(ctx context.Context) (bool, error) {
+	switch c.mode {
+	case ToolCallingNative:
+		return true, nil
+	case ToolCallingPrompt:
+		return false, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.native != nil {
+		return *c.native, nil
+	}
+	native, err := c.detectTools(ctx)
+	if err != nil {
+		return false, fmt.Errorf("detect tool calling: %w", err)
+	}
+	c.native = &native
+	return native, nil
+}
+
+// detectTools asks the server whether the model supports tool calling: first
+// through the Ollama and llama.cpp metadata endpoints, then by sending a
+// one-token request with a tool. The probe counts as a "no" only when the
+// server rejects the tools field: an error status, other than an auth or
+// rate-limit one, whose message mentions tools (llama.cpp without --jinja
+// answers 500). Any other failure, such as a 401, a 503 or a model still
+// loading, is returned so that the answer is not kept.
+func (c *LocalClient) detectTools(ctx context.Context) (bool, error) {
+	root := strings.TrimSuffix(c.oai.baseURL, "/v1")
+
+	// Ollama: POST /api/show lists the model capabilities.
+	var show struct {
+		Capabilities []string `json:"capabilities"`
+	}
+	if ok := c.getJSON(ctx, http.MethodPost, root+"/api/show", map[string]string{"model": c.oai.model}, &show); ok && show.Capabilities != nil {
+		for _, capability := range show.Capabilities {
+			if capability == "tools" {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	// llama.cpp: GET /props describes the loaded chat template.
+	var props struct {
+		ChatTemplateCaps *struct {
+			SupportsTools bool `json:"supports_tools"`
+		} `json:"chat_template_caps"`
+	}
+	if ok := c.getJSON(ctx, http.MethodGet, root+"/props", nil, &props); ok && props.ChatTemplateCaps != nil {
+		return props.ChatTemplateCaps.SupportsTools, nil
+	}
+
+	_, err := c.oai.do(ctx, &oaiRequest{
+		Model:    c.oai.model,
+		Messages: []oaiMessage{{Role: "user", Content: "ping"}},
+		Tools: []oaiTool{{Type: "function", Function: oaiToolFunction{
+			Name:        "ping",
+			Description: "Reply to a ping.",
+			Parameters:  json.RawMessage(`{"type":"object","properties":{}}`),
+		}}},
+		MaxTokens: 1,
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case isToolRejection(err):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// isToolRejection reports whether err is the server refusing a request
+// because of its tools field.
+func isToolRejection(err error) bool {
+	var se *statusError
+	if !errors.As(err, &se) || se.code < 400 || se.code == http.StatusUnauthorized ||
+		se.code == http.StatusForbidden || se.code == http.StatusTooManyRequests {
+		return false
+	}
+	return strings.Contains(strings.ToLower(se.msg), "tool")
+}
+
+// getJSON decodes the response of a metadata endpoint into out, reporting
+// whether the server answered it successfully.
+func (c *LocalClient) getJSON(ctx context.Context, method, endpoint string, in, out any) bool {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return false
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, &body)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.oai.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.oai.apiKey)
+	}
+	resp, err := c.oai.http.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(out) == nil
+}
+
+// ── Prompt-based tool calling ─────────────────────────────────────────────────
+
+const promptToolInstructions = `
+
+## 工具
+你可以调用以下工具（JSON Schema 描述参数）：
+%s
+需要调用工具时，只回复一个 JSON 对象，不要其他内容：
+{"tool_calls":[{"name":"工具名","input":{参数}}]}
+工具结果会在下一条消息中返回。不需要工具时，直接用文字回答。`
+
+// promptTool is how a tool call is written in prompt-based tool calling.
+type promptTool struct {
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+}
+
+// promptToolRequest rewrites req for a server without tool support: the
+// tools move into the system prompt, earlier tool calls become assistant
+// JSON and their results become user messages.
+func promptToolRequest(req *Request) *Request {
+	var tools strings.Builder
+	for _, t := range req.Tools {
+		fmt.Fprintf(&tools, "- %s: %s\n  参数: %s\n", t.Name, t.Description, string(t.InputSchema))
+	}
+	out := &Request{
+		System:    req.System + fmt.Sprintf(promptToolInstructions, tools.String()),
+		MaxTokens: req.MaxTokens,
+	}
+
+	names := make(map[string]string)
+	for _, m := range req.Messages {
+		switch {
+		case len(m.ToolCalls) > 0:
+			var calls struct {
+				ToolCalls []promptTool `json:"tool_calls"`
+			}
+			for _, tc := range m.ToolCalls {
+				names[tc.ID] = tc.Name
+				calls.ToolCalls = append(calls.ToolCalls, promptTool{Name: tc.Name, Input: tc.Input})
+			}
+			raw, _ := json.Marshal(calls)
+			out.Messages = append(out.Messages, Message{Role: RoleAssistant, Content: string(raw)})
+		case m.Role == RoleTool:
+			var sb strings.Builder
+			sb.WriteString("工具结果：")
+			for _, tr := range m.ToolResults {
+				fmt.Fprintf(&sb, "\n[%s]\n%s", names[tr.ToolCallID], tr.Content)
+			}
+			out.Messages = append(out.Messages, Message{Role: RoleUser, Content: sb.String()})
+		default:
+			out.Messages = append(out.Messages, Message{Role: m.Role, Content: m.Content})
+		}
+	}
+	return out
+}
+
+// parsePromptToolCalls turns a JSON tool call in resp into ToolCalls. Calls
+// to tools req does not offer are left as text.
+func parsePromptToolCalls(resp *Response, req *Request) *Response {
+	content := strings.TrimSpace(resp.Content)
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return resp
+	}
+	var calls struct {
+		ToolCalls []promptTool `json:"tool_calls"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &calls); err != nil || len(calls.ToolCalls) == 0 {
+		return resp
+	}
+
+	offered := make(map[string]bool, len(req.Tools))
+	for _, t := range req.Tools {
+		offered[t.Name] = true
+	}
+	var toolCalls []ToolCall
+	for i, tc := range calls.ToolCalls {
+		if !offered[tc.Name] {
+			return resp
+		}
+		input := tc.Input
+		if len(input) == 0 {
+			input = json.RawMessage("{}")
+		}
+		toolCalls = append(toolCalls, ToolCall{
+			ID:    fmt.Sprintf("call_%d_%d", len(req.Messages), i),
+			Name:  tc.Name,
+			Input: input,
+		})
+	}
+	return &Response{
+		ToolCalls:  toolCalls,
+		StopReason: StopReasonToolUse,
+		Usage:      resp.Usage,
+	}
+}
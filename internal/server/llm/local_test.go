@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// localServer fakes an OpenAI-compatible server. Requests to paths in meta
+// get the given JSON; chat completions are recorded and answered with reply,
+// or rejected when they carry tools and tools is false.
+type localServer struct {
+	meta     map[string]string
+	tools    bool
+	reply    string
+	requests []oaiRequest
+}
+
+func (s *localServer) start(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, ok := s.meta[r.URL.Path]; ok {
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		var req oaiRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.requests = append(s.requests, req)
+		if len(req.Tools) > 0 && !s.tools {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"type":"invalid_request_error","message":"model does not support tools"}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"role": "assistant", "content": s.reply}, "finish_reason": "stop"}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL + "/v1"
+}
+
+var localTools = []Tool{{Name: "list_peers", Description: "列出 Peer", InputSchema: json.RawMessage(`{"type":"object","properties":{}}`)}}
+
+func TestLocalClient_DetectsToolCalling(t *testing.T) {
+	for name, tc := range map[string]struct {
+		srv  localServer
+		want bool
+	}{
+		"ollama tools":     {srv: localServer{meta: map[string]string{"/api/show": `{"capabilities":["completion","tools"]}`}}, want: true},
+		"ollama no tools":  {srv: localServer{meta: map[string]string{"/api/show": `{"capabilities":["completion"]}`}, tools: true}, want: false},
+		"llama.cpp tools":  {srv: localServer{meta: map[string]string{"/props": `{"chat_template_caps":{"supports_tools":true}}`}}, want: true},
+		"llama.cpp plain":  {srv: localServer{meta: map[string]string{"/props": `{"chat_template_caps":{"supports_tools":false}}`}, tools: true}, want: false},
+		"probe accepted":   {srv: localServer{tools: true}, want: true},
+		"probe rejected":   {srv: localServer{tools: false}, want: false},
+		"no capabilities":  {srv: localServer{meta: map[string]string{"/api/show": `{}`}, tools: true}, want: true},
+		"no template caps": {srv: localServer{meta: map[string]string{"/props": `{}`}}, want: false},
+	} {
+		c, err := NewLocalClient(tc.srv.start(t), "", "qwen2.5", ToolCallingAuto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.nativeTools(context.Background())
+		if err != nil || got != tc.want {
+			t.Errorf("%s: native = %v, %v; want %v", name, got, err, tc.want)
+		}
+	}
+}
+
+func TestLocalClient_ProbeErrorIsNotKept(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"type":"unavailable_error","message":"loading model"}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"role": "assistant", "content": "pong"}, "finish_reason": "stop"}},
+		})
+	}))
+	defer srv.Close()
+
+	c, err := NewLocalClient(srv.URL+"/v1", "", "qwen2.5", ToolCallingAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.nativeTools(context.Background()); err == nil {
+		t.Fatal("expected the 503 to be returned")
+	}
+	native, err := c.nativeTools(context.Background())
+	if err != nil || !native {
+		t.Errorf("native = %v, %v after the server recovered", native, err)
+	}
+}
+
+func TestLocalClient_PromptToolCalling(t *testing.T) {
+	srv := &localServer{reply: "```json\n{\"tool_calls\":[{\"name\":\"list_peers\",\"input\":{}}]}\n```"}
+	c, err := NewLocalClient(srv.start(t), "", "phi3", ToolCallingPrompt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	req := &Request{System: "你是助手", Messages: []Message{{Role: RoleUser, Content: "有哪些 Peer？"}}, Tools: localTools}
+	resp, err := c.Complete(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "list_peers" || resp.Content != "" || resp.StopReason != StopReasonToolUse {
+		t.Fatalf("response = %+v", resp)
+	}
+	sent := srv.requests[0]
+	if len(sent.Tools) != 0 || !strings.Contains(sent.Messages[0].Content.(string), "- list_peers: 列出 Peer") {
+		t.Errorf("request = %+v", sent)
+	}
+
+	// Tool calls and results are sent back as plain messages.
+	srv.reply = "共 2 个 Peer。"
+	req.Messages = append(req.Messages,
+		Message{Role: RoleAssistant, ToolCalls: resp.ToolCalls},
+		Message{Role: RoleTool, ToolResults: []ToolResult{{ToolCallID: resp.ToolCalls[0].ID, Content: "web-1, db-1"}}},
+	)
+	resp, err = c.Complete(ctx, req)
+	if err != nil || resp.Content != "共 2 个 Peer。" || resp.HasToolCalls() {
+		t.Fatalf("response = %+v, %v", resp, err)
+	}
+	msgs := srv.requests[1].Messages
+	if len(msgs) != 4 || msgs[2].Role != RoleAssistant || len(msgs[2].ToolCalls) != 0 ||
+		msgs[3].Role != RoleUser || !strings.Contains(msgs[3].Content.(string), "[list_peers]\nweb-1, db-1") {
+		t.Errorf("messages = %+v", msgs)
+	}
+}
+
+func TestParsePromptToolCalls_IgnoresUnknownTools(t *testing.T) {
+	resp := &Response{Content: `{"tool_calls":[{"name":"delete_everything","input":{}}]}`}
+	if got := parsePromptToolCalls(resp, &Request{Tools: localTools}); got != resp {
+		t.Errorf("got %+v", got)
+	}
+}
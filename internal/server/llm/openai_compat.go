@@ -34,9 +34,10 @@ func NewOpenAICompatClient(baseURL, apiKey, model string) *OpenAICompatClient {
 // ── OpenAI wire types ─────────────────────────────────────────────────────────
 
 type oaiRequest struct {
-	Model    string       `json:"model"`
-	Messages []oaiMessage `json:"messages"`
-	Tools    []oaiTool    `json:"tools,omitempty"`
+	Model     string       `json:"model"`
+	Messages  []oaiMessage `json:"messages"`
+	Tools     []oaiTool    `json:"tools,omitempty"`
+	MaxTokens int          `json:"max_tokens,omitempty"`
 }
 
 type oaiMessage struct {
@@ -70,7 +71,11 @@ type oaiToolFunction struct {
 
 type oaiResponse struct {
 	Choices []oaiChoice `json:"choices"`
-	Error   *struct {
+	Usage   struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
 	} `json:"error,omitempty"`
@@ -108,6 +113,11 @@ func (c *OpenAICompatClient) Complete(ctx context.Context, req *Request) (*Respo
 		or.Messages = append(or.Messages, toOAIMessages(m)...)
 	}
 
+	return c.do(ctx, &or)
+}
+
+// do posts a chat completion request and converts the first choice.
+func (c *OpenAICompatClient) do(ctx context.Context, or *oaiRequest) (*Response, error) {
 	body, err := json.Marshal(or)
 	if err != nil {
 		return nil, err
@@ -119,7 +129,9 @@ func (c *OpenAICompatClient) Complete(ctx context.Context, req *Request) (*Respo
 		return nil, err
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
 
 	resp, err := c.http.Do(httpReq)
 	if err != nil {
@@ -134,21 +146,34 @@ func (c *OpenAICompatClient) Complete(ctx context.Context, req *Request) (*Respo
 
 	var or2 oaiResponse
 	if err := json.Unmarshal(data, &or2); err != nil {
+		if resp.StatusCode >= 400 {
+			return nil, &statusError{code: resp.StatusCode, msg: fmt.Sprintf("llm HTTP %d: %s", resp.StatusCode, string(data))}
+		}
 		return nil, fmt.Errorf("parse response: %w", err)
 	}
 	if or2.Error != nil {
-		return nil, fmt.Errorf("llm error %s: %s", or2.Error.Type, or2.Error.Message)
+		return nil, &statusError{code: resp.StatusCode, msg: fmt.Sprintf("llm error %s: %s", or2.Error.Type, or2.Error.Message)}
 	}
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("llm HTTP %d: %s", resp.StatusCode, string(data))
+		return nil, &statusError{code: resp.StatusCode, msg: fmt.Sprintf("llm HTTP %d: %s", resp.StatusCode, string(data))}
 	}
 	if len(or2.Choices) == 0 {
 		return nil, fmt.Errorf("llm returned empty choices")
 	}
 
-	return fromOAIResponse(or2.Choices[0]), nil
+	r := fromOAIResponse(or2.Choices[0])
+	r.Usage = Usage{InputTokens: or2.Usage.PromptTokens, OutputTokens: or2.Usage.CompletionTokens}
+	return r, nil
 }
 
+// statusError is returned when the server answers a request with an error.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string { return e.msg }
+
 // toOAIMessages converts a neutral Message to one or more OpenAI messages.
 // Tool results require one message per result in OpenAI format.
 func toOAIMessages(m Message) []oaiMessage {
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// AIConversation is a chat with the AI assistant, owned by one user in one
+// workspace. The counters cover every turn, so a conversation can be billed
+// and reviewed without reading its messages.
+type AIConversation struct {
+	Model
+
+	WorkspaceID string `gorm:"index;size:36" json:"workspaceId"`
+	UserID      string `gorm:"index;size:36" json:"userId"`
+	UserName    string `gorm:"size:100"      json:"userName"` // denormalized for display
+
+	// 标题取首条用户消息的开头
+	Title string `gorm:"size:200" json:"title"`
+
+	MessageCount int   `json:"messageCount"`
+	InputTokens  int64 `json:"inputTokens"`
+	OutputTokens int64 `json:"outputTokens"`
+	ToolCalls    int   `json:"toolCalls"`
+}
+
+func (AIConversation) TableName() string { return "t_ai_conversation" }
+
+// AIMessage is one message of an AIConversation, kept in the form the LLM
+// client takes so that a resumed conversation continues where it stopped.
+type AIMessage struct {
+	// 自增 ID 即消息顺序
+	ID             uint64    `gorm:"primaryKey;autoIncrement" json:"-"`
+	CreatedAt      time.Time `json:"createdAt"`
+	ConversationID string    `gorm:"index;size:36" json:"conversationId"`
+
+	Role    string `gorm:"size:20"   json:"role"` // user | assistant | tool
+	Content string `gorm:"type:text" json:"content,omitempty"`
+
+	// llm.ToolCall / llm.ToolResult 列表（JSON）
+	ToolCalls   string `gorm:"type:text" json:"toolCalls,omitempty"`
+	ToolResults string `gorm:"type:text" json:"toolResults,omitempty"`
+
+	// ToolCallCount 是 ToolCalls 中的调用数。
+	ToolCallCount int `json:"toolCallCount,omitempty"`
+
+	// 生成本条 assistant 消息的那次请求的用量
+	InputTokens  int `json:"inputTokens,omitempty"`
+	OutputTokens int `json:"outputTokens,omitempty"`
+}
+
+func (AIMessage) TableName() string { return "t_ai_message" }
+
+// AIUsage is the LLM usage of one workspace on one UTC day. Daily quotas are
+// checked against it.
+type AIUsage struct {
+	WorkspaceID string    `gorm:"primaryKey;size:36" json:"workspaceId"`
+	Day         string    `gorm:"primaryKey;size:10" json:"day"` // 2006-01-02
+	UpdatedAt   time.Time `json:"updatedAt"`
+
+	Requests     int64 `json:"requests"` // LLM 请求次数
+	InputTokens  int64 `json:"inputTokens"`
+	OutputTokens int64 `json:"outputTokens"`
+	ToolCalls    int64 `json:"toolCalls"`
+}
+
+func (AIUsage) TableName() string { return "t_ai_usage" }
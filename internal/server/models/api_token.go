@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/alatticeio/lattice/internal/server/dto"
+)
+
+// ServiceAccount is a non-human workspace member for automation (Terraform,
+// CI). It cannot log in; it only authenticates with its API tokens.
+type ServiceAccount struct {
+	Model
+	WorkspaceID string            `gorm:"index;not null" json:"workspaceId"`
+	Name        string            `gorm:"size:64;not null" json:"name"`
+	Description string            `gorm:"size:255" json:"description"`
+	Role        dto.WorkspaceRole `gorm:"type:varchar(20);not null" json:"role"`
+	CreatedBy   string            `gorm:"type:varchar(100)" json:"createdBy"`
+}
+
+func (ServiceAccount) TableName() string { return "t_service_account" }
+
+// APIToken is a long-lived bearer credential. Exactly one of UserID
+// (personal access token) and ServiceAccountID is set. Only the SHA-256 of
+// the secret is stored; Prefix is kept to tell tokens apart in listings.
+type APIToken struct {
+	Model
+	Name             string `gorm:"size:64;not null" json:"name"`
+	Prefix           string `gorm:"size:16" json:"prefix"`
+	Hash             string `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	UserID           string `gorm:"index" json:"userId,omitempty"`
+	ServiceAccountID string `gorm:"index" json:"serviceAccountId,omitempty"`
+
+	// WorkspaceID restricts the token to one workspace; empty means every
+	// workspace the owner can access.
+	WorkspaceID string `gorm:"index" json:"workspaceId,omitempty"`
+	// Role caps what the token may do in a workspace, below the owner's own
+	// role. Empty means no cap.
+	Role dto.WorkspaceRole `gorm:"type:varchar(20)" json:"role,omitempty"`
+	// PlatformAdmin keeps the owner's platform admin rights for this token.
+	PlatformAdmin bool `json:"platformAdmin"`
+
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP string     `gorm:"size:64" json:"lastUsedIp,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedBy  string     `gorm:"type:varchar(100)" json:"createdBy"`
+}
+
+func (APIToken) TableName() string { return "t_api_token" }
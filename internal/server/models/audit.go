@@ -2,6 +2,14 @@ package models
 
 import "time"
 
+// Audit sources, see AuditLog.Source.
+const (
+	AuditSourceAPI        = "api"
+	AuditSourceKubernetes = "kubernetes"
+	AuditSourceNATS       = "nats"
+	AuditSourceAI         = "ai"
+)
+
 // AuditLog records every mutating operation in the system.
 // It is append-only: no UpdatedAt, no soft-delete.
 type AuditLog struct {
@@ -17,8 +25,11 @@ type AuditLog struct {
 	// 作用域
 	WorkspaceID string `gorm:"index;size:36" json:"workspaceId"` // empty = platform-level
 
+	// 来源 — 变更经由哪条路径发生
+	Source string `gorm:"size:20;index" json:"source"` // api | kubernetes | nats | ai，空值为早期的 api 记录
+
 	// 操作描述
-	Action       string `gorm:"size:50;index" json:"action"`   // CREATE UPDATE DELETE LOGIN INVITE REVOKE EXPORT
+	Action       string `gorm:"size:50;index" json:"action"`   // CREATE UPDATE DELETE LOGIN INVITE REVOKE EXPORT TOOL
 	Resource     string `gorm:"size:50;index" json:"resource"` // member workspace policy token relay invitation peer
 	ResourceID   string `gorm:"size:36"       json:"resourceId"`
 	ResourceName string `gorm:"size:200"      json:"resourceName"` // denormalized
@@ -32,6 +43,24 @@ type AuditLog struct {
 
 	// 详情快照（JSON，可选，仅记录关键操作的 before/after）
 	Detail string `gorm:"type:text" json:"detail,omitempty"`
+
+	// 哈希链 — Seq 按写入顺序从 1 递增，Hash = sha256(PrevHash + 本条内容)。
+	// 修改或删除任意一条都会使校验在该处失败。Seq 为 0（库中为 NULL）的是启用哈希链之前的记录。
+	// Seq 唯一：多个副本同时追加时，后写入者因冲突失败并基于新的链头重试，链不会分叉。
+	Seq      int64  `gorm:"uniqueIndex" json:"seq"`
+	PrevHash string `gorm:"size:64" json:"prevHash"`
+	Hash     string `gorm:"size:64" json:"hash"`
 }
 
 func (AuditLog) TableName() string { return "t_audit_log" }
+
+// AuditAnchor 记录保留期清理删除的最后一条哈希链记录。清理后保留的第一条记录的
+// PrevHash 须等于其 Hash，校验据此发现清理之外被删除的最早记录。表中只有一行。
+type AuditAnchor struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	Seq       int64     `json:"seq"`
+	Hash      string    `gorm:"size:64" json:"hash"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (AuditAnchor) TableName() string { return "t_audit_anchor" }
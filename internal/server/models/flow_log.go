@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// FlowLog is one flow record reported by an agent: the traffic of one
+// connection in one direction with one verdict during a reporting interval.
+// Like AuditLog it is append-only; old rows are pruned by FlowLogService.
+type FlowLog struct {
+	ID        string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	CreatedAt time.Time `gorm:"index"                       json:"createdAt"`
+
+	// 作用域
+	WorkspaceID string `gorm:"index;size:36"  json:"workspaceId"`
+	Peer        string `gorm:"index;size:253" json:"peer"` // reporting peer (app ID)
+
+	// 上报周期
+	IntervalStart time.Time `json:"intervalStart"`
+	IntervalEnd   time.Time `gorm:"index" json:"intervalEnd"`
+
+	// 五元组（从上报节点视角）
+	Direction  string `gorm:"size:10"        json:"direction"` // ingress | egress
+	Protocol   string `gorm:"size:10"        json:"protocol"`
+	LocalIP    string `gorm:"size:45"        json:"localIP"`
+	LocalPort  int    `json:"localPort"`
+	RemoteIP   string `gorm:"size:45;index"  json:"remoteIP"`
+	RemotePort int    `json:"remotePort"`
+	RemotePeer string `gorm:"size:253;index" json:"remotePeer"`
+
+	// 判定
+	Verdict     string `gorm:"size:10;index"  json:"verdict"` // accept | drop
+	Policy      string `gorm:"size:253;index" json:"policy"`
+	Established bool   `json:"established"`
+
+	Packets uint64 `json:"packets"`
+	Bytes   uint64 `json:"bytes"`
+}
+
+func (FlowLog) TableName() string { return "t_flow_log" }
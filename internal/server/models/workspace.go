@@ -21,6 +21,11 @@ const (
 	MemberStatusRemoved   = "removed"
 )
 
+// MemberSourceGroup marks a membership granted by an IdP group mapping. Group
+// sync adds, updates and removes only these; memberships with an empty
+// source are managed by hand.
+const MemberSourceGroup = "group"
+
 // WorkspaceMember 关联表：连接 User 和 Workspace (Namespace)  这里其实就是RoleBinding 所有的权限校验在数据库层面，不去找k8s的
 type WorkspaceMember struct {
 	Model
@@ -33,6 +38,9 @@ type WorkspaceMember struct {
 	// 成员状态（用于邀请机制）
 	Status string `gorm:"type:varchar(20);default:'active'" json:"status"` // e.g., "pending", "active"
 
+	// 来源：空 = 手动添加/邀请，"group" = 由 IdP 组映射同步
+	Source string `gorm:"type:varchar(20);default:''" json:"source,omitempty"`
+
 	InvitedBy string     `gorm:"column:invited_by" json:"invitedBy,omitempty"`
 	JoinedAt  *time.Time `gorm:"column:joined_at" json:"joinedAt,omitempty"`
 
@@ -17,6 +17,8 @@ package nats
 import (
 	"sync"
 	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
 )
 
 // offlineThreshold is how long since the last heartbeat before a node is considered offline.
@@ -26,15 +28,47 @@ const offlineThreshold = 90 * time.Second
 // NodePresenceStore is a thread-safe in-memory store that tracks the last
 // heartbeat timestamp for each agent node identified by its AppID.
 type NodePresenceStore struct {
-	mu sync.RWMutex
-	m  map[string]time.Time // appId -> lastHeartbeat
+	mu    sync.RWMutex
+	m     map[string]time.Time        // appId -> lastHeartbeat
+	paths map[string][]infra.PeerPath // appId -> reported peer paths
+	relay map[string]string           // appId -> reported home relay
 }
 
 // NewNodePresenceStore creates an empty NodePresenceStore.
 func NewNodePresenceStore() *NodePresenceStore {
 	return &NodePresenceStore{
-		m: make(map[string]time.Time),
+		m:     make(map[string]time.Time),
+		paths: make(map[string][]infra.PeerPath),
+		relay: make(map[string]string),
+	}
+}
+
+// UpdatePaths replaces the peer connection paths reported by appId.
+func (s *NodePresenceStore) UpdatePaths(appId string, paths []infra.PeerPath) {
+	s.mu.Lock()
+	if len(paths) == 0 {
+		delete(s.paths, appId)
+	} else {
+		s.paths[appId] = paths
 	}
+	s.mu.Unlock()
+}
+
+// GetPaths returns the peer connection paths last reported by appId.
+func (s *NodePresenceStore) GetPaths(appId string) []infra.PeerPath {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.paths[appId]
+}
+
+// UpdateRelay records the home relay reported by appId and reports whether
+// it differs from the previously recorded one.
+func (s *NodePresenceStore) UpdateRelay(appId, relay string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, ok := s.relay[appId]
+	s.relay[appId] = relay
+	return !ok || prev != relay
 }
 
 // Update records a heartbeat for the given appId at the current time.
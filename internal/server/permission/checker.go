@@ -34,6 +34,10 @@ type Checker interface {
 	// Returns the member record for downstream use (nil for platform admins).
 	RequireWorkspaceRole(ctx context.Context, wsID, userID string, role dto.WorkspaceRole) (*models.WorkspaceMember, error)
 
+	// RequireMembership is RequireWorkspaceRole without the platform admin
+	// bypass, for API tokens that do not carry platform admin rights.
+	RequireMembership(ctx context.Context, wsID, userID string, role dto.WorkspaceRole) (*models.WorkspaceMember, error)
+
 	// K8sClient returns an impersonated K8s client scoped to the caller's
 	// workspace role. Requires membership and sufficient role.
 	K8sClient(ctx context.Context, wsID, userID string) (client.Client, error)
@@ -66,6 +70,10 @@ func (c *checker) RequireWorkspaceRole(ctx context.Context, wsID, userID string,
 		return nil, nil
 	}
 
+	return c.RequireMembership(ctx, wsID, userID, role)
+}
+
+func (c *checker) RequireMembership(ctx context.Context, wsID, userID string, role dto.WorkspaceRole) (*models.WorkspaceMember, error) {
 	// Check membership and status.
 	member, err := c.store.WorkspaceMembers().GetMembership(ctx, wsID, userID)
 	if err != nil {
@@ -48,6 +48,7 @@ type Client struct {
 	manager.Manager
 
 	log *log.Logger
+	own *ownWrites
 
 	hashMu         sync.RWMutex
 	lastPushedHash map[string]string
@@ -79,8 +80,10 @@ func NewClient(signal infra.SignalService, mgr manager.Manager) (*Client, error)
 	// 3. Set the initialized log for controller-runtime
 	logf.SetLogger(zapLogger)
 
+	own := newOwnWrites()
 	client := &Client{
-		Client:         mgr.GetClient(),
+		Client:         &recordingClient{Client: mgr.GetClient(), own: own},
+		own:            own,
 		lastPushedHash: make(map[string]string),
 		log:            logger,
 		sender:         signal,
@@ -0,0 +1,118 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ownWriteTTL is how long a write made by this process is remembered while
+// waiting for the informer to deliver its event.
+const ownWriteTTL = 2 * time.Minute
+
+// ownWrites remembers the objects this process wrote, so watchers can tell
+// them apart from changes made with kubectl or GitOps. Creates and updates
+// are keyed by the resourceVersion they produced; deletes by the object.
+type ownWrites struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+func newOwnWrites() *ownWrites {
+	return &ownWrites{entries: make(map[string]time.Time)}
+}
+
+func ownWriteKey(obj client.Object, resourceVersion string) string {
+	gvk, _ := apiutil.GVKForObject(obj, scheme)
+	return gvk.Kind + "/" + obj.GetNamespace() + "/" + obj.GetName() + "@" + resourceVersion
+}
+
+func (w *ownWrites) mark(key string) {
+	now := time.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for k, at := range w.entries {
+		if now.Sub(at) > ownWriteTTL {
+			delete(w.entries, k)
+		}
+	}
+	w.entries[key] = now
+}
+
+func (w *ownWrites) consume(key string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	at, ok := w.entries[key]
+	if !ok {
+		return false
+	}
+	delete(w.entries, key)
+	return time.Since(at) <= ownWriteTTL
+}
+
+// recordingClient marks every successful write in its ownWrites. Status
+// writes go through the embedded client unrecorded.
+type recordingClient struct {
+	client.Client
+	own *ownWrites
+}
+
+func (c *recordingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.own.mark(ownWriteKey(obj, obj.GetResourceVersion()))
+	return nil
+}
+
+func (c *recordingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.Client.Update(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.own.mark(ownWriteKey(obj, obj.GetResourceVersion()))
+	return nil
+}
+
+func (c *recordingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.Client.Patch(ctx, obj, patch, opts...); err != nil {
+		return err
+	}
+	c.own.mark(ownWriteKey(obj, obj.GetResourceVersion()))
+	return nil
+}
+
+func (c *recordingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.Client.Delete(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.own.mark(ownWriteKey(obj, ""))
+	return nil
+}
+
+// IsOwnWrite reports whether obj, as delivered by an informer, is the result
+// of a write made through this client. Each write matches once. Pass
+// deleted=true for delete events.
+func (c *Client) IsOwnWrite(obj client.Object, deleted bool) bool {
+	rv := obj.GetResourceVersion()
+	if deleted {
+		rv = ""
+	}
+	return c.own.consume(ownWriteKey(obj, rv))
+}
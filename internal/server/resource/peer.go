@@ -134,37 +134,36 @@ func (c *Client) UpdateNodeSepc(ctx context.Context, namespace, name string, upd
 	return c.Update(ctx, &node)
 }
 
-// GetNetworkMap get network map when node init
-func (c *Client) GetNetworkMap(ctx context.Context, tokenStr, name string) (*infra.Message, error) {
-	logger := c.log
-	logger.Info("Get node", "tokenStr", tokenStr, "name", name)
-
+// TokenNamespace returns the namespace of the enrollment token an agent
+// presents on the signaling plane.
+func (c *Client) TokenNamespace(ctx context.Context, tokenStr string) (string, error) {
 	if tokenStr == "" {
-		return nil, fmt.Errorf("token is empty")
+		return "", fmt.Errorf("token is empty")
 	}
 	var list v1alpha1.LatticeEnrollmentTokenList
-	err := c.List(ctx, &list, client.MatchingFields{"spec.token": tokenStr})
-	if err != nil {
-		return nil, fmt.Errorf("get token failed: %v", err)
-	}
-
-	if len(list.Items) == 0 {
-		return nil, fmt.Errorf("token not exists")
+	if err := c.List(ctx, &list, client.MatchingFields{"spec.token": tokenStr}); err != nil {
+		return "", fmt.Errorf("get token failed: %v", err)
 	}
-
-	var token *v1alpha1.LatticeEnrollmentToken
 	for _, t := range list.Items {
 		if t.Status.Token == tokenStr {
-			token = &t
+			return t.Namespace, nil
 		}
 	}
+	return "", fmt.Errorf("token not exists")
+}
+
+// GetNetworkMap get network map when node init
+func (c *Client) GetNetworkMap(ctx context.Context, tokenStr, name string) (*infra.Message, error) {
+	logger := c.log
+	logger.Info("Get node", "tokenStr", tokenStr, "name", name)
 
-	if token == nil {
-		return nil, fmt.Errorf("token not exists")
+	namespace, err := c.TokenNamespace(ctx, tokenStr)
+	if err != nil {
+		return nil, err
 	}
 
 	var node v1alpha1.LatticePeer
-	if err = c.Get(ctx, types.NamespacedName{Namespace: token.Namespace, Name: name}, &node); err != nil {
+	if err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &node); err != nil {
 		return nil, err
 	}
 
@@ -190,7 +189,7 @@ func (c *Client) GetNetworkMap(ctx context.Context, tokenStr, name string) (*inf
 		return nil, err
 	}
 
-	logger.Info("Get network map success", "namespace", token.Namespace, "name", name, "message", message)
+	logger.Info("Get network map success", "namespace", namespace, "name", name, "message", message)
 	return message, nil
 }
 
@@ -0,0 +1,36 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTokenNamespace(t *testing.T) {
+	token := &v1alpha1.LatticeEnrollmentToken{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "wf-a", Name: "t"},
+		Spec:       v1alpha1.LatticeEnrollmentTokenSpec{Token: "secret"},
+		Status:     v1alpha1.LatticeEnrollmentTokenStatus{Token: "secret"},
+	}
+	c := &Client{Client: fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(token).
+		WithIndex(&v1alpha1.LatticeEnrollmentToken{}, "spec.token", func(o client.Object) []string {
+			return []string{o.(*v1alpha1.LatticeEnrollmentToken).Spec.Token}
+		}).
+		Build()}
+
+	ns, err := c.TokenNamespace(context.Background(), "secret")
+	if err != nil || ns != "wf-a" {
+		t.Errorf("TokenNamespace = %q, %v", ns, err)
+	}
+	for _, tok := range []string{"", "other"} {
+		if _, err := c.TokenNamespace(context.Background(), tok); err == nil {
+			t.Errorf("token %q accepted", tok)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/alatticeio/lattice/internal/server/dto"
 	"github.com/alatticeio/lattice/internal/server/server/middleware"
 	"github.com/alatticeio/lattice/internal/server/service"
 	"github.com/alatticeio/lattice/pkg/utils/resp"
@@ -16,20 +17,19 @@ import (
 
 func (s *Server) aiRouter() {
 	if s.aiService == nil {
-		// AI not configured: register stub handlers returning 503
+		// AI not configured: chat returns 503, audit reports the policy lint
+		// findings, which need no LLM.
 		ai := s.Group("/api/v1/ai")
-		ai.Use(middleware.AuthMiddleware(nil))
+		ai.Use(middleware.AuthMiddleware(s.revocationList, s.apiTokenService))
 		ai.POST("/chat", func(c *gin.Context) {
-			resp.Error(c, "AI not configured: set ai.enabled=true and ai.api-key in lattice.yaml")
-		})
-		ai.GET("/audit", func(c *gin.Context) {
-			resp.Error(c, "AI not configured: set ai.enabled=true and ai.api-key in lattice.yaml")
+			resp.Error(c, "AI not configured: set ai.enabled=true and ai.api-key (or ai.provider: local) in lattice.yaml")
 		})
+		ai.GET("/audit", s.handleAIAudit())
 		return
 	}
 
 	ai := s.Group("/api/v1/ai")
-	ai.Use(middleware.AuthMiddleware(nil))
+	ai.Use(middleware.AuthMiddleware(s.revocationList, s.apiTokenService))
 	{
 		ai.POST("/chat", s.handleAIChat())
 		ai.GET("/audit", s.handleAIAudit())
@@ -40,11 +40,17 @@ func (s *Server) aiRouter() {
 //
 // Request body:
 //
-//	{ "message": "...", "workspaceId": "ws-xxx", "history": [...] }
+//	{ "message": "...", "workspaceId": "ws-xxx", "conversationId": "...", "history": [...] }
+//
+// conversationId continues a stored conversation; without it a new one is
+// started, seeded with history.
 //
 // Response: text/event-stream
 //
+//	data: {"type":"conversation","conversationId":"..."}
 //	data: {"type":"tool_use","tool":"list_peers","input":{}}
+//	data: {"type":"tool_use","tool":"disable_peer","input":{"peer":"web-1"}}
+//	data: {"type":"preview","tool":"disable_peer","content":"--- current\n+++ proposed\n...","workflowId":"..."}
 //	data: {"type":"token","content":"当前有 3 个 Peer..."}
 //	data: {"type":"done"}
 func (s *Server) handleAIChat() gin.HandlerFunc {
@@ -63,6 +69,17 @@ func (s *Server) handleAIChat() gin.HandlerFunc {
 			return
 		}
 
+		// The assistant reads the workspace and files workflow requests in
+		// the caller's name, so it is offered to its members only.
+		if err := s.authorizeAIWorkspace(c, req.WorkspaceID); err != nil {
+			resp.Forbidden(c, err.Error())
+			return
+		}
+		req.UserID = c.GetString("user_id")
+		req.UserName = c.GetString("username")
+		req.UserEmail = c.GetString("email")
+		req.AllowWrites = true
+
 		c.Header("Content-Type", "text/event-stream")
 		c.Header("Cache-Control", "no-cache")
 		c.Header("Connection", "keep-alive")
@@ -78,6 +95,7 @@ func (s *Server) handleAIChat() gin.HandlerFunc {
 }
 
 // handleAIAudit runs a security audit on the workspace and returns findings.
+// Without an LLM it returns the policy lint findings alone.
 //
 // Query params: workspaceId=ws-xxx
 func (s *Server) handleAIAudit() gin.HandlerFunc {
@@ -87,8 +105,18 @@ func (s *Server) handleAIAudit() gin.HandlerFunc {
 			resp.BadRequest(c, "workspaceId is required")
 			return
 		}
+		if err := s.authorizeAIWorkspace(c, wsID); err != nil {
+			resp.Forbidden(c, err.Error())
+			return
+		}
 
-		report, err := s.aiService.Audit(c.Request.Context(), wsID)
+		var report *service.AuditReport
+		var err error
+		if s.aiService != nil {
+			report, err = s.aiService.Audit(c.Request.Context(), wsID)
+		} else {
+			report, err = s.policyLintService.Report(c.Request.Context(), wsID)
+		}
 		if err != nil {
 			resp.Error(c, err.Error())
 			return
@@ -98,6 +126,15 @@ func (s *Server) handleAIAudit() gin.HandlerFunc {
 	}
 }
 
+// authorizeAIWorkspace checks the caller may use the assistant in wsID with
+// the check WorkspaceAuthMiddleware uses for workspace routes: API tokens are
+// held to their workspace scope, and the caller must be a member of the
+// workspace or a platform admin.
+func (s *Server) authorizeAIWorkspace(c *gin.Context, wsID string) error {
+	_, err := s.middleware.AuthorizeWorkspace(c.Request.Context(), middleware.APIIdentity(c), c.GetString("user_id"), c.GetString("system_role"), wsID, dto.RoleViewer)
+	return err
+}
+
 // ── SSE writer ────────────────────────────────────────────────────────────────
 
 // sseWriter implements service.StreamWriter and writes events in SSE format.
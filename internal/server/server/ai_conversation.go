@@ -0,0 +1,169 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/models"
+	"github.com/alatticeio/lattice/internal/server/server/middleware"
+	"github.com/alatticeio/lattice/pkg/utils/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// aiConversationRouter registers the stored AI conversation and usage
+// routes. They read the database only, so they are served whether or not
+// an LLM is configured.
+func (s *Server) aiConversationRouter() {
+	// The caller's own conversations, for resuming a chat.
+	own := s.Group("/api/v1/ai/conversations")
+	own.Use(middleware.AuthMiddleware(s.revocationList, s.apiTokenService))
+	{
+		own.GET("", s.handleListAIConversations(true))
+		own.GET("/:convId", s.handleGetAIConversation(true))
+	}
+
+	// Transcript review and usage of one workspace (workspace admins).
+	ws := s.Group("/api/v1/workspaces/:id/ai")
+	ws.Use(s.middleware.WorkspaceAuthMiddleware(dto.RoleAdmin))
+	{
+		ws.GET("/conversations", s.handleListAIConversations(false))
+		ws.GET("/conversations/export", s.handleExportAIConversations())
+		ws.GET("/conversations/:convId", s.handleGetAIConversation(false))
+		ws.GET("/usage", s.handleAIUsage())
+	}
+
+	// All workspaces (platform_admin only).
+	platform := s.Group("/api/v1/ai-conversations")
+	platform.Use(middleware.AuthMiddleware(s.revocationList, s.apiTokenService), s.middleware.PlatformAdminOnly())
+	{
+		platform.GET("", s.handleListAIConversations(false))
+		platform.GET("/export", s.handleExportAIConversations())
+		platform.GET("/:convId", s.handleGetAIConversation(false))
+	}
+	usage := s.Group("/api/v1/ai-usage")
+	usage.Use(middleware.AuthMiddleware(s.revocationList, s.apiTokenService), s.middleware.PlatformAdminOnly())
+	usage.GET("", s.handleAIUsage())
+}
+
+// handleListAIConversations lists conversations without their messages. With
+// own set it lists the caller's conversations only.
+func (s *Server) handleListAIConversations(own bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := aiConversationFilterFromQuery(c, own)
+		if err := bindPage(c, &filter.Page, &filter.PageSize); err != nil {
+			resp.BadRequest(c, err.Error())
+			return
+		}
+
+		result, err := s.aiConversationController.List(c.Request.Context(), filter)
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, result)
+	}
+}
+
+// handleGetAIConversation returns a conversation with its messages. A
+// conversation outside the scope of the route is reported as not found.
+func (s *Server) handleGetAIConversation(own bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := aiConversationFilterFromQuery(c, own)
+
+		conv, err := s.aiConversationController.Get(c.Request.Context(), c.Param("convId"))
+		if err == nil && (filter.WorkspaceID != "" && conv.WorkspaceID != filter.WorkspaceID ||
+			filter.UserID != "" && conv.UserID != filter.UserID) {
+			err = fmt.Errorf("conversation not found")
+		}
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, conv)
+	}
+}
+
+// handleExportAIConversations streams the matching conversations with their
+// messages as a JSONL download and records the export in the audit log.
+func (s *Server) handleExportAIConversations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := aiConversationFilterFromQuery(c, false)
+
+		name := fmt.Sprintf("ai-conversations-%s.jsonl", time.Now().UTC().Format("20060102T150405Z"))
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		c.Status(http.StatusOK)
+
+		status := "success"
+		if err := s.aiConversationController.Export(c.Request.Context(), filter, c.Writer); err != nil {
+			// Headers are already sent; the client sees a truncated file.
+			s.logger.Error("AI conversation export failed", err)
+			status = "failed"
+		}
+
+		s.auditService.Log(models.AuditLog{
+			UserID:      c.GetString("user_id"),
+			UserName:    c.GetString("username"),
+			UserEmail:   c.GetString("email"),
+			UserIP:      c.ClientIP(),
+			WorkspaceID: filter.WorkspaceID,
+			Source:      models.AuditSourceAPI,
+			Action:      "EXPORT",
+			Resource:    "ai-conversation",
+			Scope:       fmt.Sprintf("user:%s from:%s to:%s", filter.UserID, filter.From, filter.To),
+			Status:      status,
+			StatusCode:  http.StatusOK,
+		})
+	}
+}
+
+// handleAIUsage returns daily LLM usage rows.
+//
+// Query params: from=2006-01-02, to=2006-01-02 (inclusive), and workspaceId
+// on the platform route.
+func (s *Server) handleAIUsage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wsID := c.Param("id")
+		if wsID == "" {
+			wsID = c.Query("workspaceId")
+		}
+		rows, err := s.aiConversationController.Usage(c.Request.Context(), store.AIUsageFilter{
+			WorkspaceID: wsID,
+			From:        c.Query("from"),
+			To:          c.Query("to"),
+		})
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, rows)
+	}
+}
+
+// aiConversationFilterFromQuery builds a filter from the query string,
+// supporting both workspace-scoped (/workspaces/:id/ai) and global routes.
+// With own set the filter is pinned to the caller.
+func aiConversationFilterFromQuery(c *gin.Context, own bool) store.AIConversationFilter {
+	filter := store.AIConversationFilter{
+		WorkspaceID: c.Param("id"),
+		UserID:      c.Query("userId"),
+		Keyword:     c.Query("keyword"),
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+	}
+	if filter.WorkspaceID == "" {
+		filter.WorkspaceID = c.Query("workspaceId")
+	}
+	if own {
+		filter.UserID = c.GetString("user_id")
+		// A token restricted to one workspace sees that workspace only.
+		if ident := middleware.APIIdentity(c); ident != nil && ident.WorkspaceID != "" {
+			filter.WorkspaceID = ident.WorkspaceID
+		}
+	}
+	return filter
+}
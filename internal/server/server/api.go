@@ -22,7 +22,7 @@ func (s *Server) apiRouter() error {
 
 	// Dex OIDC 为可选依赖：providerUrl 为空时跳过初始化，注册降级 handler。
 	if s.cfg.Dex.ProviderUrl != "" {
-		dexSvc, err := dex.NewDex(service.NewUserService(s.store))
+		dexSvc, err := dex.NewDex(service.NewUserService(s.store), s.groupSyncService)
 		if err != nil {
 			s.logger.Warn("Dex init failed, /auth/callback will return 503", "err", err)
 			s.GET("/auth/callback", func(c *gin.Context) {
@@ -79,6 +79,7 @@ func (s *Server) apiRouter() error {
 	}
 
 	s.userRouter()
+	s.apiTokenRouter()
 
 	s.workspaceRouter()
 
@@ -99,12 +100,15 @@ func (s *Server) apiRouter() error {
 	s.dashboardRouter()
 
 	s.auditRouter()
+	s.flowLogRouter()
 
 	s.workflowRouter()
 
 	s.peeringRouter()
+	s.clusterRouter()
 
 	s.aiRouter()
+	s.aiConversationRouter()
 
 	// SPA 静态资源：必须最后注册，通过 NoRoute 捕获所有未匹配路径
 	s.logger.Info("Registering SPA static files")
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/server/middleware"
+	"github.com/alatticeio/lattice/pkg/utils/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) apiTokenRouter() {
+	// Personal access tokens of the calling user.
+	pat := s.Group("/api/v1/api-tokens")
+	pat.Use(middleware.AuthMiddleware(s.revocationList, s.apiTokenService))
+	{
+		pat.GET("", s.handleListAPITokens())
+		pat.POST("", s.handleCreateAPIToken())
+		pat.DELETE("/:tokenID", s.handleRevokeAPIToken())
+	}
+
+	// Workspace service accounts and their tokens — workspace admins only.
+	sa := s.Group("/api/v1/workspaces/:id/service-accounts")
+	sa.Use(s.middleware.AdminOnly())
+	{
+		sa.GET("", s.handleListServiceAccounts())
+		sa.POST("", s.handleCreateServiceAccount())
+		sa.DELETE("/:saID", s.handleDeleteServiceAccount())
+		sa.GET("/:saID/tokens", s.handleListServiceAccountTokens())
+		sa.POST("/:saID/tokens", s.handleCreateServiceAccountToken())
+		sa.DELETE("/:saID/tokens/:tokenID", s.handleRevokeServiceAccountToken())
+	}
+}
+
+// rejectTokenMinting keeps a token from minting tokens that would outlive its
+// own revocation: no API token may create personal tokens, and service
+// account tokens may not create any. Returns false and writes the error
+// response if the caller is not allowed.
+func rejectTokenMinting(c *gin.Context, personal bool) bool {
+	ident := middleware.APIIdentity(c)
+	if ident != nil && (personal || ident.ServiceAccount) {
+		resp.Forbidden(c, "this api token cannot create tokens; sign in to create one")
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleListAPITokens() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, err := s.apiTokenService.ListPersonal(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, data)
+	}
+}
+
+func (s *Server) handleCreateAPIToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !rejectTokenMinting(c, true) {
+			return
+		}
+		var req dto.APITokenDto
+		if err := c.ShouldBindJSON(&req); err != nil {
+			resp.BadRequest(c, err.Error())
+			return
+		}
+		data, err := s.apiTokenService.CreatePersonal(c.Request.Context(), c.GetString("user_id"), &req)
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		middleware.SetAuditScope(c, "api-token:"+data.Name)
+		resp.OK(c, data)
+	}
+}
+
+func (s *Server) handleRevokeAPIToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := s.apiTokenService.RevokePersonal(c.Request.Context(), c.GetString("user_id"), c.Param("tokenID")); err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, nil)
+	}
+}
+
+func (s *Server) handleListServiceAccounts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, err := s.apiTokenService.ListServiceAccounts(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, data)
+	}
+}
+
+func (s *Server) handleCreateServiceAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ServiceAccountDto
+		if err := c.ShouldBindJSON(&req); err != nil {
+			resp.BadRequest(c, err.Error())
+			return
+		}
+		data, err := s.apiTokenService.CreateServiceAccount(c.Request.Context(), c.Param("id"), c.GetString("username"), &req)
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, data)
+	}
+}
+
+func (s *Server) handleDeleteServiceAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := s.apiTokenService.DeleteServiceAccount(c.Request.Context(), c.Param("id"), c.Param("saID")); err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, nil)
+	}
+}
+
+func (s *Server) handleListServiceAccountTokens() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, err := s.apiTokenService.ListServiceAccountTokens(c.Request.Context(), c.Param("id"), c.Param("saID"))
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, data)
+	}
+}
+
+func (s *Server) handleCreateServiceAccountToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !rejectTokenMinting(c, false) {
+			return
+		}
+		var req dto.APITokenDto
+		if err := c.ShouldBindJSON(&req); err != nil {
+			resp.BadRequest(c, err.Error())
+			return
+		}
+		data, err := s.apiTokenService.CreateServiceAccountToken(c.Request.Context(), c.Param("id"), c.Param("saID"), c.GetString("username"), &req)
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, data)
+	}
+}
+
+func (s *Server) handleRevokeServiceAccountToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := s.apiTokenService.RevokeServiceAccountToken(c.Request.Context(), c.Param("id"), c.Param("saID"), c.Param("tokenID")); err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, nil)
+	}
+}
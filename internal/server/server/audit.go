@@ -1,14 +1,21 @@
 package server
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/alatticeio/lattice/internal/agent/store"
 	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/models"
+	"github.com/alatticeio/lattice/internal/server/service"
 	"github.com/alatticeio/lattice/pkg/utils/resp"
 
 	"github.com/gin-gonic/gin"
+	admissionv1 "k8s.io/api/admission/v1"
 )
 
 func (s *Server) auditRouter() {
@@ -17,6 +24,7 @@ func (s *Server) auditRouter() {
 	ws.Use(s.middleware.WorkspaceAuthMiddleware(dto.RoleViewer))
 	{
 		ws.GET("", s.handleListAuditLogs())
+		ws.GET("/export", s.handleExportAuditLogs())
 	}
 
 	// Platform-level audit logs (platform_admin only).
@@ -24,26 +32,22 @@ func (s *Server) auditRouter() {
 	platform.Use(s.middleware.PlatformAdminOnly())
 	{
 		platform.GET("", s.handleListAuditLogs())
+		platform.GET("/export", s.handleExportAuditLogs())
+		platform.GET("/verify", s.handleVerifyAuditLogs())
+	}
+
+	// Validating admission webhook that attributes kubectl / GitOps changes
+	// to the Kubernetes user. The API server cannot present a JWT; it sends a
+	// shared bearer token from its admission kubeconfig instead, which keeps
+	// the secret out of URLs and access logs.
+	if s.cfg.Audit.AdmissionToken != "" {
+		s.POST("/api/v1/admission/audit", s.handleAuditAdmission())
 	}
 }
 
 func (s *Server) handleListAuditLogs() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Support both workspace-scoped (/workspaces/:id/audit-logs) and global.
-		wsID := c.Param("id")
-		if wsID == "" {
-			wsID = c.Query("workspaceId")
-		}
-
-		filter := store.AuditLogFilter{
-			WorkspaceID: wsID,
-			Action:      c.Query("action"),
-			Resource:    c.Query("resource"),
-			Status:      c.Query("status"),
-			Keyword:     c.Query("keyword"),
-			From:        c.Query("from"),
-			To:          c.Query("to"),
-		}
+		filter := auditFilterFromQuery(c)
 
 		if err := bindPage(c, &filter.Page, &filter.PageSize); err != nil {
 			resp.BadRequest(c, err.Error())
@@ -59,6 +63,108 @@ func (s *Server) handleListAuditLogs() gin.HandlerFunc {
 	}
 }
 
+// handleExportAuditLogs streams the matching entries as a JSONL or CSV
+// download (?format=jsonl|csv) and records the export itself in the audit log.
+func (s *Server) handleExportAuditLogs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := auditFilterFromQuery(c)
+
+		format := c.DefaultQuery("format", service.AuditExportJSONL)
+		var contentType string
+		switch format {
+		case service.AuditExportJSONL:
+			contentType = "application/x-ndjson"
+		case service.AuditExportCSV:
+			contentType = "text/csv"
+		default:
+			resp.BadRequest(c, fmt.Sprintf("unsupported format %q, want jsonl or csv", format))
+			return
+		}
+
+		name := fmt.Sprintf("audit-logs-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		c.Status(http.StatusOK)
+
+		status := "success"
+		if err := s.auditController.Export(c.Request.Context(), filter, format, c.Writer); err != nil {
+			// Headers are already sent; the client sees a truncated file.
+			s.logger.Error("audit export failed", err)
+			status = "failed"
+		}
+
+		s.auditService.Log(models.AuditLog{
+			UserID:      c.GetString("user_id"),
+			UserName:    c.GetString("username"),
+			UserEmail:   c.GetString("email"),
+			UserIP:      c.ClientIP(),
+			WorkspaceID: filter.WorkspaceID,
+			Source:      models.AuditSourceAPI,
+			Action:      "EXPORT",
+			Resource:    "audit-log",
+			Scope:       fmt.Sprintf("format:%s from:%s to:%s", format, filter.From, filter.To),
+			Status:      status,
+			StatusCode:  http.StatusOK,
+		})
+	}
+}
+
+// handleVerifyAuditLogs walks the audit hash chain and reports whether it is
+// intact.
+func (s *Server) handleVerifyAuditLogs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := s.auditController.Verify(c.Request.Context())
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, result)
+	}
+}
+
+// handleAuditAdmission answers an AdmissionReview from the Kubernetes API
+// server. It never denies a request.
+func (s *Server) handleAuditAdmission() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Audit.AdmissionToken)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		var review admissionv1.AdmissionReview
+		if err := c.ShouldBindJSON(&review); err != nil || review.Request == nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if s.crdAuditService != nil {
+			review.Response = s.crdAuditService.Review(review.Request)
+		} else {
+			review.Response = &admissionv1.AdmissionResponse{UID: review.Request.UID, Allowed: true}
+		}
+		review.Request = nil
+		c.JSON(http.StatusOK, review)
+	}
+}
+
+// auditFilterFromQuery builds a filter from the query string, supporting both
+// workspace-scoped (/workspaces/:id/audit-logs) and global routes.
+func auditFilterFromQuery(c *gin.Context) store.AuditLogFilter {
+	wsID := c.Param("id")
+	if wsID == "" {
+		wsID = c.Query("workspaceId")
+	}
+	return store.AuditLogFilter{
+		WorkspaceID: wsID,
+		Action:      c.Query("action"),
+		Resource:    c.Query("resource"),
+		Status:      c.Query("status"),
+		Source:      c.Query("source"),
+		Keyword:     c.Query("keyword"),
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+	}
+}
+
 // bindPage reads ?page= and ?pageSize= query params with sensible defaults.
 func bindPage(c *gin.Context, page, pageSize *int) error {
 	p, ps := 1, 20
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"time"
+
+	"github.com/alatticeio/lattice/internal/server/server/middleware"
+	"github.com/alatticeio/lattice/pkg/utils"
+	"github.com/alatticeio/lattice/pkg/utils/resp"
+	"github.com/gin-gonic/gin"
+)
+
+// clusterRouter serves the endpoints remote Lattice clusters call on this
+// one: the LatticeCluster health probe and credential rotation.
+func (s *Server) clusterRouter() {
+	clusterApi := s.Group("/api/v1/cluster")
+	clusterApi.Use(middleware.AuthMiddleware(s.revocationList, s.apiTokenService))
+	{
+		clusterApi.GET("/health", s.clusterHealth())
+		clusterApi.POST("/credentials/rotate", s.middleware.PlatformAdminOnly(), s.rotateClusterCredential())
+	}
+}
+
+// clusterHealth answers the health probe. Reaching it proves the endpoint
+// is up, the TLS handshake succeeds and the token is still accepted.
+func (s *Server) clusterHealth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resp.OK(c, gin.H{"status": "ok", "time": time.Now().UTC()})
+	}
+}
+
+// rotateClusterCredential issues a fresh session token for the calling
+// platform admin. The old token stays valid so that the caller keeps access
+// until the new one is stored; it then revokes the old one through
+// /auth/logout. API tokens are scoped and cannot be exchanged for an
+// unrestricted session.
+func (s *Server) rotateClusterCredential() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if middleware.APIIdentity(c) != nil {
+			resp.Forbidden(c, "api tokens cannot be rotated into a session token")
+			return
+		}
+		token, err := utils.GenerateBusinessJWT(
+			c.GetString("user_id"),
+			c.GetString("email"),
+			c.GetString("username"),
+			c.GetString("system_role"),
+		)
+		if err != nil {
+			resp.Error(c, "failed to issue token")
+			return
+		}
+		resp.OK(c, gin.H{"token": token})
+	}
+}
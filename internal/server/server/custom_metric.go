@@ -1,6 +1,9 @@
 package server
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/alatticeio/lattice/internal/server/dto"
 	"github.com/alatticeio/lattice/internal/server/service"
 	"github.com/alatticeio/lattice/pkg/utils/resp"
@@ -16,6 +19,7 @@ func (s *Server) customMetricRouter() {
 		r.POST("", s.createCustomMetric())
 		r.PUT("/:id", s.updateCustomMetric())
 		r.DELETE("/:id", s.deleteCustomMetric())
+		r.GET("/:id/query", s.queryCustomMetric())
 	}
 }
 
@@ -65,7 +69,7 @@ func (s *Server) updateCustomMetric() gin.HandlerFunc {
 			resp.Error(c, err.Error())
 			return
 		}
-		data, err := s.customMetricController.Update(c.Request.Context(), id, req)
+		data, err := s.customMetricController.Update(c.Request.Context(), c.GetString("workspace_id"), id, req)
 		if err != nil {
 			resp.Error(c, err.Error())
 			return
@@ -77,10 +81,48 @@ func (s *Server) updateCustomMetric() gin.HandlerFunc {
 func (s *Server) deleteCustomMetric() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
-		if err := s.customMetricController.Delete(c.Request.Context(), id); err != nil {
+		if err := s.customMetricController.Delete(c.Request.Context(), c.GetString("workspace_id"), id); err != nil {
 			resp.Error(c, err.Error())
 			return
 		}
 		resp.OK(c, nil)
 	}
 }
+
+// queryCustomMetric evaluates a custom metric. Without start/end (unix
+// seconds) it runs an instant query; step is a duration such as "1m".
+func (s *Server) queryCustomMetric() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req service.QueryCustomMetricRequest
+		if v := c.Query("start"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				resp.BadRequest(c, "invalid start")
+				return
+			}
+			req.Start = time.Unix(n, 0)
+		}
+		if v := c.Query("end"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				resp.BadRequest(c, "invalid end")
+				return
+			}
+			req.End = time.Unix(n, 0)
+		}
+		if v := c.Query("step"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				resp.BadRequest(c, "invalid step")
+				return
+			}
+			req.Step = d
+		}
+		data, err := s.customMetricController.Query(c.Request.Context(), c.GetString("workspace_id"), c.Param("id"), req)
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, data)
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/pkg/utils/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) flowLogRouter() {
+	// Any workspace member can read the flow logs of their workspace.
+	ws := s.Group("/api/v1/workspaces/:id/flow-logs")
+	ws.Use(s.middleware.WorkspaceAuthMiddleware(dto.RoleViewer))
+	{
+		ws.GET("", s.handleListFlowLogs())
+	}
+}
+
+func (s *Server) handleListFlowLogs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := store.FlowLogFilter{
+			WorkspaceID: c.Param("id"),
+			Peer:        c.Query("peer"),
+			Verdict:     c.Query("verdict"),
+			Policy:      c.Query("policy"),
+			Direction:   c.Query("direction"),
+			IP:          c.Query("ip"),
+			From:        c.Query("from"),
+			To:          c.Query("to"),
+		}
+		if v := c.Query("port"); v != "" {
+			port, err := strconv.Atoi(v)
+			if err != nil {
+				resp.BadRequest(c, "invalid port: "+err.Error())
+				return
+			}
+			filter.Port = port
+		}
+
+		if err := bindPage(c, &filter.Page, &filter.PageSize); err != nil {
+			resp.BadRequest(c, err.Error())
+			return
+		}
+
+		result, err := s.flowLogController.List(c.Request.Context(), filter)
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		resp.OK(c, result)
+	}
+}
+
+// FlowLog stores the flow records an agent reports over NATS.
+func (s *Server) FlowLog(content []byte) ([]byte, error) {
+	var report infra.FlowLogReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.flowLogService.Record(ctx, &report); err != nil {
+		return nil, err
+	}
+	return []byte{}, nil
+}
@@ -24,7 +24,7 @@ func (s *Server) invitationRouter() {
 		// POST /api/v1/invite/:token/register — register new account + accept (public)
 		pub.POST("/:token/register", s.handleRegisterAndAccept())
 		// POST /api/v1/invite/:token/accept   — accept with existing logged-in account
-		pub.POST("/:token/accept", middleware.AuthMiddleware(s.revocationList), s.handleAcceptInvitation())
+		pub.POST("/:token/accept", middleware.AuthMiddleware(s.revocationList, s.apiTokenService), s.handleAcceptInvitation())
 	}
 }
 
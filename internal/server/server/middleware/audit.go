@@ -114,7 +114,7 @@ func scopeFromParams(c *gin.Context) string {
 	if id := c.Param("id"); id != "" {
 		parts = append(parts, "workspace:"+id)
 	}
-	for _, p := range []string{"userID", "invID", "token", "name"} {
+	for _, p := range []string{"userID", "invID", "token", "name", "saID", "tokenID"} {
 		if v := c.Param(p); v != "" {
 			parts = append(parts, p+":"+v)
 		}
@@ -126,8 +126,10 @@ func scopeFromParams(c *gin.Context) string {
 // as an audit log entry using the provided AuditService.
 func AuditMiddleware(svc service.AuditService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Only audit mutating requests.
-		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodOptions {
+		// Only audit mutating requests. Admission reviews come from the
+		// Kubernetes API server and are audited as the change they admit.
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodOptions ||
+			strings.HasPrefix(c.Request.URL.Path, "/api/v1/admission/") {
 			c.Next()
 			return
 		}
@@ -164,6 +166,7 @@ func AuditMiddleware(svc service.AuditService) gin.HandlerFunc {
 			UserEmail:   c.GetString("email"),
 			UserIP:      c.ClientIP(),
 			WorkspaceID: c.GetHeader("X-Workspace-Id"),
+			Source:      models.AuditSourceAPI,
 			Action:      actionFromMethod(c.Request.Method, c.FullPath()),
 			Resource:    resourceFromPath(c.FullPath()),
 			Scope:       scopeStr,
@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/alatticeio/lattice/internal/agent/infra"
 	"github.com/alatticeio/lattice/internal/server/auth"
@@ -12,47 +13,100 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(revocationList *auth.RevocationList) gin.HandlerFunc {
+// AuthMiddleware accepts session JWTs and, when apiTokens is set, API tokens.
+func AuthMiddleware(revocationList *auth.RevocationList, apiTokens auth.APITokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 1. 获取 Authorization Header (格式: Bearer <token>)
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			resp.Unauthorized(c, "未授权，请先登录")
-			c.Abort() // 停止执行后续的处理函数
+		if _, ok := authenticate(c, revocationList, apiTokens); !ok {
 			return
 		}
+		c.Next()
+	}
+}
+
+// authenticate 解析 Authorization 头并把用户信息写入上下文。
+// 使用 API Token 时返回其身份（JWT 时为 nil）；失败时已写入响应并 Abort。
+func authenticate(c *gin.Context, revocationList *auth.RevocationList, apiTokens auth.APITokenAuthenticator) (*auth.APIIdentity, bool) {
+	// 1. 获取 Authorization Header (格式: Bearer <token>)
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		resp.Unauthorized(c, "未授权，请先登录")
+		c.Abort() // 停止执行后续的处理函数
+		return nil, false
+	}
 
-		tokenString := authHeader[7:] // 截取 "Bearer " 之后的部分
+	tokenString := authHeader[7:] // 截取 "Bearer " 之后的部分
 
-		// 2. 解析并验证 Token
-		claims, err := utils.ParseToken(tokenString) // 这里需要实现解析逻辑
+	var (
+		ident                            *auth.APIIdentity
+		userID, username, email, sysRole string
+		jti                              string
+		exp                              time.Time
+	)
+	if auth.IsAPIToken(tokenString) {
+		// 2a. API Token：查库校验（吊销、过期在 AuthenticateAPIToken 中处理）
+		if apiTokens == nil {
+			resp.Unauthorized(c, "无效的 Token")
+			c.Abort()
+			return nil, false
+		}
+		var err error
+		ident, err = apiTokens.AuthenticateAPIToken(c.Request.Context(), tokenString, c.ClientIP())
+		if err != nil {
+			resp.Unauthorized(c, err.Error())
+			c.Abort()
+			return nil, false
+		}
+		if err := ident.PermitsMethod(c.Request.Method); err != nil {
+			resp.Forbidden(c, err.Error())
+			c.Abort()
+			return nil, false
+		}
+		userID, username, email, sysRole = ident.UserID, ident.Username, ident.Email, ident.SystemRole
+		jti, exp = ident.TokenID, ident.ExpiresAt
+		c.Set("api_token", ident)
+	} else {
+		// 2b. 解析并验证 JWT
+		claims, err := utils.ParseToken(tokenString)
 		if err != nil {
 			resp.Unauthorized(c, "无效的 Token")
 			c.Abort()
-			return
+			return nil, false
 		}
 
 		// 3. Check revocation (skip if list is nil — for migration during Task 5)
 		if revocationList != nil && claims.ID != "" && revocationList.IsRevoked(claims.ID) {
 			resp.Unauthorized(c, "token has been revoked")
 			c.Abort()
-			return
+			return nil, false
 		}
+		userID, username, email, sysRole = claims.Subject, claims.Username, claims.Email, claims.SystemRole
+		jti, exp = claims.ID, claims.ExpiresAt.Time
+	}
 
-		// 4. 将用户 ID 写入上下文，后续 Handler 可以通过 c.Get("userID") 拿到
-		c.Set("user_id", claims.Subject)
-		c.Set("username", claims.Username)
-		c.Set("email", claims.Email)
-		c.Set("system_role", claims.SystemRole)
-		c.Set("jti", claims.ID)
-		c.Set("exp", claims.ExpiresAt.Time)
-
-		// 进阶：如果你想让后面的 context.Context 也能拿到这个值
-		// 可以重写 Request 的 Context (可选，但在纯净的架构中很有用)
-		ctx := context.WithValue(c.Request.Context(), infra.UserIDKey, claims.Subject)
-		ctx = context.WithValue(ctx, infra.SystemRoleKey, claims.SystemRole)
-		ctx = context.WithValue(ctx, infra.UsernameKey, claims.Username)
-		c.Request = c.Request.WithContext(ctx)
-		c.Next()
+	// 4. 将用户 ID 写入上下文，后续 Handler 可以通过 c.Get("userID") 拿到
+	c.Set("user_id", userID)
+	c.Set("username", username)
+	c.Set("email", email)
+	c.Set("system_role", sysRole)
+	c.Set("jti", jti)
+	c.Set("exp", exp)
+
+	// 进阶：如果你想让后面的 context.Context 也能拿到这个值
+	// 可以重写 Request 的 Context (可选，但在纯净的架构中很有用)
+	ctx := context.WithValue(c.Request.Context(), infra.UserIDKey, userID)
+	ctx = context.WithValue(ctx, infra.SystemRoleKey, sysRole)
+	ctx = context.WithValue(ctx, infra.UsernameKey, username)
+	c.Request = c.Request.WithContext(ctx)
+	return ident, true
+}
+
+// APIIdentity returns the API token identity of the request, or nil when it
+// was made with a session JWT.
+func APIIdentity(c *gin.Context) *auth.APIIdentity {
+	v, ok := c.Get("api_token")
+	if !ok {
+		return nil
 	}
+	ident, _ := v.(*auth.APIIdentity)
+	return ident
 }
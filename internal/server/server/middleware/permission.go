@@ -16,75 +16,48 @@ package middleware
 
 import (
 	"context"
-	"strings"
+	"errors"
+	"fmt"
 
 	"github.com/alatticeio/lattice/internal/agent/infra"
 	"github.com/alatticeio/lattice/internal/agent/store"
 	"github.com/alatticeio/lattice/internal/server/auth"
 	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/models"
 	"github.com/alatticeio/lattice/internal/server/permission"
-	"github.com/alatticeio/lattice/pkg/utils"
 	"github.com/alatticeio/lattice/pkg/utils/resp"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Middleware unifies workspace permission enforcement.
-// It combines JWT parsing, revocation check, membership/role check,
-// and workspace context injection into a single middleware chain.
+// It combines JWT/API token authentication, revocation check, membership/role
+// check, and workspace context injection into a single middleware chain.
 type Middleware struct {
 	checker        permission.Checker
 	store          store.Store
 	revocationList *auth.RevocationList
+	apiTokens      auth.APITokenAuthenticator
 }
 
-// NewMiddleware creates a Middleware with a revocation list.
-func NewMiddleware(checker permission.Checker, st store.Store, revocationList *auth.RevocationList) *Middleware {
-	return &Middleware{checker: checker, store: st, revocationList: revocationList}
+// NewMiddleware creates a Middleware with a revocation list. apiTokens may be
+// nil, in which case only session JWTs are accepted.
+func NewMiddleware(checker permission.Checker, st store.Store, revocationList *auth.RevocationList, apiTokens auth.APITokenAuthenticator) *Middleware {
+	return &Middleware{checker: checker, store: st, revocationList: revocationList, apiTokens: apiTokens}
 }
 
 // WorkspaceAuthMiddleware enforces workspace access control.
 // wsID is taken from X-Workspace-Id header (primary) or URL param :id (fallback).
-// The middleware parses JWT, checks revocation, verifies membership/role, and injects workspace context.
+// The middleware authenticates the caller, verifies membership/role, and injects workspace context.
+// API tokens are additionally held to their own workspace restriction and role cap.
 func (m *Middleware) WorkspaceAuthMiddleware(requiredRole dto.WorkspaceRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 1. Parse JWT from Authorization header.
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			resp.Unauthorized(c, "未授权，请先登录")
-			c.Abort()
-			return
-		}
-		tokenString := authHeader[7:]
-
-		claims, err := utils.ParseToken(tokenString)
-		if err != nil {
-			resp.Unauthorized(c, "无效的 Token")
-			c.Abort()
+		// 1-3. Authenticate and write user info to Gin and request context.
+		ident, ok := authenticate(c, m.revocationList, m.apiTokens)
+		if !ok {
 			return
 		}
 
-		// 2. Check revocation.
-		if m.revocationList != nil && claims.ID != "" && m.revocationList.IsRevoked(claims.ID) {
-			resp.Unauthorized(c, "token has been revoked")
-			c.Abort()
-			return
-		}
-
-		// 3. Write user info to Gin context (same as AuthMiddleware).
-		c.Set("user_id", claims.Subject)
-		c.Set("username", claims.Username)
-		c.Set("email", claims.Email)
-		c.Set("system_role", claims.SystemRole)
-		c.Set("jti", claims.ID)
-		c.Set("exp", claims.ExpiresAt.Time)
-
-		// Also inject into Request context.
-		ctx := context.WithValue(c.Request.Context(), infra.UserIDKey, claims.Subject)
-		ctx = context.WithValue(ctx, infra.SystemRoleKey, claims.SystemRole)
-		ctx = context.WithValue(ctx, infra.UsernameKey, claims.Username)
-		c.Request = c.Request.WithContext(ctx)
-
 		// 4. Get wsID from header (primary) or URL param (fallback).
 		wsID := c.GetHeader("X-Workspace-Id")
 		if wsID == "" {
@@ -96,30 +69,24 @@ func (m *Middleware) WorkspaceAuthMiddleware(requiredRole dto.WorkspaceRole) gin
 			return
 		}
 
-		userID := claims.Subject
-
-		// 5. Check platform admin (read from context we just set).
-		if claims.SystemRole == string(dto.SystemRolePlatformAdmin) {
-			// Platform admins bypass workspace checks.
-			// Still inject workspace context if wsID is provided.
-			c.Set("workspace_id", wsID)
-			reqCtx := context.WithValue(c.Request.Context(), infra.WorkspaceKey, wsID)
-			c.Request = c.Request.WithContext(reqCtx)
-			c.Next()
-			return
-		}
-
-		// 6. Check membership and role.
-		member, err := m.checker.RequireWorkspaceRole(c.Request.Context(), wsID, userID, requiredRole)
+		// 5-6. Check the token scope, then membership and role.
+		member, err := m.AuthorizeWorkspace(c.Request.Context(), ident, c.GetString("user_id"), c.GetString("system_role"), wsID, requiredRole)
 		if err != nil {
-			resp.Forbidden(c, "权限不足")
+			msg := err.Error()
+			if errors.Is(err, ErrNotMember) {
+				msg = "权限不足"
+			}
+			resp.Forbidden(c, msg)
 			c.Abort()
 			return
 		}
 
-		// 7. Inject workspace context and member info.
+		// 7. Inject workspace context and member info. Platform admins have
+		// no member.
 		c.Set("workspace_id", wsID)
-		c.Set("currentTeamMember", member)
+		if member != nil {
+			c.Set("currentTeamMember", member)
+		}
 		reqCtx := context.WithValue(c.Request.Context(), infra.WorkspaceKey, wsID)
 		c.Request = c.Request.WithContext(reqCtx)
 
@@ -127,6 +94,50 @@ func (m *Middleware) WorkspaceAuthMiddleware(requiredRole dto.WorkspaceRole) gin
 	}
 }
 
+// ErrNotMember is returned by AuthorizeWorkspace when the caller has no
+// membership in the workspace with the required role.
+var ErrNotMember = errors.New("not a member of the workspace with the required role")
+
+// AuthorizeWorkspace checks that a caller may act in wsID with role. ident
+// is the caller's API token, or nil for a session JWT. An API token is held
+// to its own workspace restriction and role cap first.
+//
+// It returns the caller's membership, with the role capped to the token's,
+// or nil for a platform admin, who bypasses workspace checks. A service
+// account is a member of its own workspace only, with the role Permits has
+// already checked. Tokens without platform admin rights act through the
+// owner's membership even when the owner is a platform admin.
+func (m *Middleware) AuthorizeWorkspace(ctx context.Context, ident *auth.APIIdentity, userID, systemRole, wsID string, role dto.WorkspaceRole) (*models.WorkspaceMember, error) {
+	if ident != nil {
+		if err := ident.Permits(wsID, role); err != nil {
+			return nil, err
+		}
+		systemRole = ident.SystemRole
+	}
+	if systemRole == string(dto.SystemRolePlatformAdmin) {
+		return nil, nil
+	}
+	if ident != nil && ident.ServiceAccount {
+		return &models.WorkspaceMember{WorkspaceID: wsID, UserID: userID, Role: ident.Role, Status: models.MemberStatusActive}, nil
+	}
+
+	require := m.checker.RequireWorkspaceRole
+	if ident != nil {
+		require = m.checker.RequireMembership
+	}
+	member, err := require(ctx, wsID, userID, role)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotMember, err)
+	}
+	if ident != nil && ident.Role != "" && dto.GetRoleWeight(ident.Role) < dto.GetRoleWeight(member.Role) {
+		// Handlers see the token's capped role, not the owner's.
+		capped := *member
+		capped.Role = ident.Role
+		member = &capped
+	}
+	return member, nil
+}
+
 // AdminOnly enforces workspace admin role.
 func (m *Middleware) AdminOnly() gin.HandlerFunc {
 	return m.WorkspaceAuthMiddleware(dto.RoleAdmin)
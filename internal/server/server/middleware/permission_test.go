@@ -21,6 +21,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/alatticeio/lattice/internal/agent/config"
 	"github.com/alatticeio/lattice/internal/agent/infra"
 	"github.com/alatticeio/lattice/internal/agent/store"
 	"github.com/alatticeio/lattice/internal/db/gormstore"
@@ -29,6 +30,7 @@ import (
 	"github.com/alatticeio/lattice/internal/server/models"
 	"github.com/alatticeio/lattice/internal/server/permission"
 	mw "github.com/alatticeio/lattice/internal/server/server/middleware"
+	"github.com/alatticeio/lattice/internal/server/service"
 	"github.com/alatticeio/lattice/pkg/utils"
 	"github.com/alatticeio/lattice/pkg/utils/resp"
 	"github.com/gin-gonic/gin"
@@ -50,7 +52,7 @@ func setupPermissionTest(t *testing.T) (*gin.Engine, store.Store, *mw.Middleware
 		t.Fatal(err)
 	}
 	checker := permission.NewForTest(st, nil)
-	middleware := mw.NewMiddleware(checker, st, nil)
+	middleware := mw.NewMiddleware(checker, st, nil, nil)
 
 	engine := gin.New()
 	return engine, st, middleware
@@ -71,7 +73,7 @@ func setupWithRevocation(t *testing.T) (*gin.Engine, store.Store, *mw.Middleware
 	}
 	rl := auth.NewRevocationList()
 	checker := permission.NewForTest(st, nil)
-	middleware := mw.NewMiddleware(checker, st, rl)
+	middleware := mw.NewMiddleware(checker, st, rl, nil)
 
 	engine := gin.New()
 	return engine, st, middleware, rl
@@ -608,7 +610,7 @@ func TestPlatformAdminOnly_AllowsPlatformAdmin(t *testing.T) {
 	token := makeTestToken(t, "u-pa", "pa@test.com", "pa", string(dto.SystemRolePlatformAdmin))
 
 	// Use AuthMiddleware first, then PlatformAdminOnly.
-	engine.GET("/test", mw.AuthMiddleware(rl), mwInst.PlatformAdminOnly(), func(c *gin.Context) {
+	engine.GET("/test", mw.AuthMiddleware(rl, nil), mwInst.PlatformAdminOnly(), func(c *gin.Context) {
 		c.String(200, "ok")
 	})
 
@@ -629,7 +631,7 @@ func TestPlatformAdminOnly_RejectsRegularUser(t *testing.T) {
 
 	token := makeTestToken(t, "u-regular", "reg@test.com", "reg", "")
 
-	engine.GET("/test", mw.AuthMiddleware(rl), mwInst.PlatformAdminOnly(), func(c *gin.Context) {
+	engine.GET("/test", mw.AuthMiddleware(rl, nil), mwInst.PlatformAdminOnly(), func(c *gin.Context) {
 		c.String(200, "ok")
 	})
 
@@ -647,3 +649,73 @@ func TestPlatformAdminOnly_RejectsRegularUser(t *testing.T) {
 		t.Errorf("expected 403, got %d", r.Code)
 	}
 }
+
+func TestWorkspaceAuthMiddleware_APITokenScope(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := gormstore.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	tokens := service.NewAPITokenService(st, config.APITokenConfig{})
+	middleware := mw.NewMiddleware(permission.NewForTest(st, nil), st, nil, tokens)
+
+	// A platform admin who is an editor of ws-a and not a member of ws-b.
+	st.Users().Create(ctx, &models.User{Model: models.Model{ID: "u-pat"}, Username: "pat", SystemRole: dto.SystemRolePlatformAdmin})
+	st.WorkspaceMembers().AddMember(ctx, &models.WorkspaceMember{WorkspaceID: "ws-a", UserID: "u-pat", Role: dto.RoleEditor, Status: models.MemberStatusActive})
+
+	viewer, err := tokens.CreatePersonal(ctx, "u-pat", &dto.APITokenDto{Name: "ro", Role: dto.RoleViewer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scoped, err := tokens.CreatePersonal(ctx, "u-pat", &dto.APITokenDto{Name: "a-only", WorkspaceID: "ws-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := gin.New()
+	handler := func(c *gin.Context) {
+		member, _ := c.Get("currentTeamMember")
+		c.String(200, string(member.(*models.WorkspaceMember).Role))
+	}
+	engine.GET("/api/v1/workspaces/:id/test", middleware.WorkspaceAuthMiddleware(dto.RoleViewer), handler)
+	engine.POST("/api/v1/workspaces/:id/test", middleware.WorkspaceAuthMiddleware(dto.RoleViewer), handler)
+
+	cases := []struct {
+		name, method, ws, token string
+		wantCode                int
+		wantBody                string
+	}{
+		{"viewer token reads with capped role", "GET", "ws-a", viewer.Token, http.StatusOK, "viewer"},
+		{"viewer token cannot write", "POST", "ws-a", viewer.Token, http.StatusForbidden, ""},
+		{"no platform admin bypass without the flag", "GET", "ws-b", viewer.Token, http.StatusForbidden, ""},
+		{"scoped token in its workspace", "POST", "ws-a", scoped.Token, http.StatusOK, "editor"},
+		{"scoped token outside its workspace", "GET", "ws-b", scoped.Token, http.StatusForbidden, ""},
+		{"unknown token", "GET", "ws-a", "lat_unknown", http.StatusUnauthorized, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/api/v1/workspaces/"+tc.ws+"/test", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if tc.wantCode == http.StatusOK {
+				if rec.Code != http.StatusOK || rec.Body.String() != tc.wantBody {
+					t.Errorf("got %d %q, want 200 %q", rec.Code, rec.Body.String(), tc.wantBody)
+				}
+				return
+			}
+			var r resp.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &r); err != nil {
+				t.Fatalf("expected JSON, got %s", rec.Body.String())
+			}
+			if r.Code != tc.wantCode {
+				t.Errorf("got code %d, want %d", r.Code, tc.wantCode)
+			}
+		})
+	}
+}
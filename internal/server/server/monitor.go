@@ -13,7 +13,7 @@ import (
 
 func (s *Server) monitorRouter() {
 	monitorRouter := s.Group("/api/v1/monitor")
-	monitorRouter.Use(middleware.AuthMiddleware(s.revocationList))
+	monitorRouter.Use(middleware.AuthMiddleware(s.revocationList, s.apiTokenService))
 	{
 		monitorRouter.GET("/topology", s.topology())
 		monitorRouter.GET("/ws-snapshot", s.middleware.WorkspaceAuthMiddleware(dto.RoleViewer), s.workspaceSnapshot())
@@ -20,7 +20,10 @@ import (
 	"fmt"
 	"github.com/alatticeio/lattice/api/v1alpha1"
 	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/server/auth"
 	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/models"
+	"github.com/alatticeio/lattice/internal/server/vo"
 	"strings"
 	"time"
 
@@ -93,6 +96,140 @@ func (s *Server) workspaceCtxByNs(parent context.Context, namespace string) (con
 	return context.WithValue(parent, infra.WorkspaceKey, ws.ID), nil
 }
 
+// natsAuditReq picks the fields that identify the target of an admin-plane
+// request. Token values are deliberately not read.
+type natsAuditReq struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	PeerName  string `json:"peer_name"`
+	Slug      string `json:"slug"`
+}
+
+// natsAuthReq carries the caller's API token. The CLI adds it to every
+// admin-plane request when one is configured.
+type natsAuthReq struct {
+	APIToken  string `json:"api_token"`
+	Namespace string `json:"namespace"`
+}
+
+// natsPlatformAdmin marks admin-plane routes that are not scoped to a
+// workspace and need platform admin rights.
+const natsPlatformAdmin dto.WorkspaceRole = ""
+
+// natsHandler is an admin-plane handler that needs the caller's identity.
+// ident is nil for requests without an API token.
+type natsHandler func(ident *auth.APIIdentity, data []byte) ([]byte, error)
+
+// natsAuthorize authenticates the request's API token and checks it may act
+// with role in the request's namespace, or as platform admin for
+// natsPlatformAdmin. Requests without a token are let through unless
+// api-tokens.require-for-nats is set, as older CLIs do not send one.
+func (s *Server) natsAuthorize(data []byte, role dto.WorkspaceRole) (*auth.APIIdentity, error) {
+	var req natsAuthReq
+	_ = json.Unmarshal(data, &req)
+	if req.APIToken == "" {
+		if s.cfg.APITokens.RequireForNATS {
+			return nil, fmt.Errorf("an api token is required: set api-token or LATTICE_API_TOKEN")
+		}
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ident, err := s.apiTokenService.AuthenticateAPIToken(ctx, req.APIToken, "nats")
+	if err != nil {
+		return nil, err
+	}
+	if role == natsPlatformAdmin {
+		if ident.SystemRole != string(dto.SystemRolePlatformAdmin) {
+			return nil, fmt.Errorf("platform admin only")
+		}
+		return ident, nil
+	}
+	if req.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	ws, err := s.store.Workspaces().GetByNamespace(ctx, req.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("workspace not found for namespace %q: %w", req.Namespace, err)
+	}
+	if _, err := s.middleware.AuthorizeWorkspace(ctx, ident, ident.UserID, ident.SystemRole, ws.ID, role); err != nil {
+		return nil, err
+	}
+	return ident, nil
+}
+
+// natsAuthorized checks the caller may use a read-only admin-plane handler.
+func (s *Server) natsAuthorized(role dto.WorkspaceRole, h Handler) Handler {
+	return s.natsAuthorizedAs(role, func(_ *auth.APIIdentity, data []byte) ([]byte, error) {
+		return h(data)
+	})
+}
+
+func (s *Server) natsAuthorizedAs(role dto.WorkspaceRole, h natsHandler) Handler {
+	return func(data []byte) ([]byte, error) {
+		ident, err := s.natsAuthorize(data, role)
+		if err != nil {
+			return nil, err
+		}
+		return h(ident, data)
+	}
+}
+
+// natsAudited checks the caller may use a mutating admin-plane handler and
+// records every call, including rejected ones, in the audit log. Calls made
+// without an API token carry no user.
+func (s *Server) natsAudited(action, resource string, role dto.WorkspaceRole, h Handler) Handler {
+	return s.natsAuditedAs(action, resource, role, func(_ *auth.APIIdentity, data []byte) ([]byte, error) {
+		return h(data)
+	})
+}
+
+func (s *Server) natsAuditedAs(action, resource string, role dto.WorkspaceRole, h natsHandler) Handler {
+	return func(data []byte) ([]byte, error) {
+		ident, err := s.natsAuthorize(data, role)
+		var out []byte
+		if err == nil {
+			out, err = h(ident, data)
+		}
+
+		var req natsAuditReq
+		_ = json.Unmarshal(data, &req)
+		name := req.Name
+		if name == "" {
+			name = req.PeerName
+		}
+		if name == "" {
+			name = req.Slug
+		}
+		entry := models.AuditLog{
+			Source:       models.AuditSourceNATS,
+			Action:       action,
+			Resource:     resource,
+			ResourceName: name,
+			Status:       "success",
+		}
+		if ident != nil {
+			entry.UserID = ident.UserID
+			entry.UserName = ident.Username
+			entry.UserEmail = ident.Email
+		}
+		if req.Namespace != "" {
+			entry.Scope = "namespace:" + req.Namespace
+			if ws, wsErr := s.store.Workspaces().GetByNamespace(context.Background(), req.Namespace); wsErr == nil {
+				entry.WorkspaceID = ws.ID
+			}
+		}
+		if err != nil {
+			entry.Status = "failed"
+			detail, _ := json.Marshal(map[string]string{"error": err.Error()})
+			entry.Detail = string(detail)
+		}
+		s.auditService.Log(entry)
+		return out, err
+	}
+}
+
 func marshal(v any) ([]byte, error) {
 	return json.Marshal(v)
 }
@@ -232,6 +369,25 @@ func (s *Server) NatsListPolicies(data []byte) ([]byte, error) {
 	return marshal(result.List)
 }
 
+// NatsLintPolicies returns the lint findings of the policies in a namespace.
+func (s *Server) NatsLintPolicies(data []byte) ([]byte, error) {
+	var req policyListReq
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if req.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	findings, err := s.policyLintService.Lint(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return marshal(findings)
+}
+
 // ── token handlers ────────────────────────────────────────────────────────────
 
 func (s *Server) NatsListTokens(data []byte) ([]byte, error) {
@@ -425,3 +581,255 @@ func (s *Server) NatsRemoveToken(data []byte) ([]byte, error) {
 	// but Status.Token retains the original case, so normalise here to match.
 	return nil, s.tokenController.Delete(ctx, strings.ToLower(req.Token))
 }
+
+// ── bundle handlers ───────────────────────────────────────────────────────────
+
+type bundleReq struct {
+	Namespace string      `json:"namespace"`
+	Bundle    *dto.Bundle `json:"bundle"`
+	Prune     bool        `json:"prune"`
+}
+
+// bundleApplyTimeout bounds a whole apply, which may touch many objects.
+const bundleApplyTimeout = 2 * time.Minute
+
+func parseBundleReq(data []byte, withBundle bool) (*bundleReq, error) {
+	var req bundleReq
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if req.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	if withBundle && req.Bundle == nil {
+		return nil, fmt.Errorf("bundle is required")
+	}
+	return &req, nil
+}
+
+// NatsExportBundle returns the declarative bundle of a workspace.
+func (s *Server) NatsExportBundle(data []byte) ([]byte, error) {
+	req, err := parseBundleReq(data, false)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	bundle, err := s.bundleController.Export(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return marshal(bundle)
+}
+
+// NatsDiffBundle lists the changes applying a bundle would make.
+func (s *Server) NatsDiffBundle(data []byte) ([]byte, error) {
+	req, err := parseBundleReq(data, true)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	changes, err := s.bundleController.Diff(ctx, req.Namespace, req.Bundle, req.Prune)
+	if err != nil {
+		return nil, err
+	}
+	return marshal(changes)
+}
+
+// NatsApplyBundle reconciles a workspace with a bundle. Relays are shared by
+// workspaces, so a caller that is not platform admin may only apply bundles
+// that leave them unchanged.
+func (s *Server) NatsApplyBundle(ident *auth.APIIdentity, data []byte) ([]byte, error) {
+	req, err := parseBundleReq(data, true)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), bundleApplyTimeout)
+	defer cancel()
+
+	if ident != nil && ident.SystemRole != string(dto.SystemRolePlatformAdmin) {
+		changes, err := s.bundleController.Diff(ctx, req.Namespace, req.Bundle, req.Prune)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range changes {
+			if c.Kind == vo.BundleKindRelay && c.Action != vo.BundleActionSkip {
+				return nil, fmt.Errorf("relay %q: changing relays needs platform admin", c.Name)
+			}
+		}
+	}
+
+	changes, err := s.bundleController.Apply(ctx, req.Namespace, req.Bundle, req.Prune)
+	if err != nil {
+		return nil, err
+	}
+	return marshal(changes)
+}
+
+// ── service account handlers ──────────────────────────────────────────────────
+
+type serviceAccountReq struct {
+	Namespace     string            `json:"namespace"`
+	Name          string            `json:"name"` // service account name
+	Description   string            `json:"description"`
+	Role          dto.WorkspaceRole `json:"role"`
+	TokenName     string            `json:"token_name"`
+	TokenID       string            `json:"token_id"`
+	ExpiresInDays int               `json:"expires_in_days"`
+}
+
+// serviceAccountCtx parses a service account request and resolves its
+// workspace. Managing service accounts over NATS always needs an API token,
+// so the anonymous admin plane cannot mint credentials for the REST API.
+func (s *Server) serviceAccountCtx(ident *auth.APIIdentity, data []byte) (*serviceAccountReq, string, error) {
+	if ident == nil {
+		return nil, "", fmt.Errorf("an api token is required to manage service accounts")
+	}
+	var req serviceAccountReq
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, "", fmt.Errorf("invalid request: %w", err)
+	}
+	if req.Namespace == "" {
+		return nil, "", fmt.Errorf("namespace is required")
+	}
+	ws, err := s.store.Workspaces().GetByNamespace(context.Background(), req.Namespace)
+	if err != nil {
+		return nil, "", fmt.Errorf("workspace not found for namespace %q: %w", req.Namespace, err)
+	}
+	return &req, ws.ID, nil
+}
+
+// serviceAccountID resolves a service account name in wsID.
+func (s *Server) serviceAccountID(ctx context.Context, wsID, name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	accounts, err := s.apiTokenService.ListServiceAccounts(ctx, wsID)
+	if err != nil {
+		return "", err
+	}
+	for _, sa := range accounts {
+		if sa.Name == name {
+			return sa.ID, nil
+		}
+	}
+	return "", fmt.Errorf("service account %q not found", name)
+}
+
+func (s *Server) NatsCreateServiceAccount(ident *auth.APIIdentity, data []byte) ([]byte, error) {
+	req, wsID, err := s.serviceAccountCtx(ident, data)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	vo, err := s.apiTokenService.CreateServiceAccount(ctx, wsID, ident.Username, &dto.ServiceAccountDto{
+		Name:        req.Name,
+		Description: req.Description,
+		Role:        req.Role,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return marshal(vo)
+}
+
+func (s *Server) NatsListServiceAccounts(ident *auth.APIIdentity, data []byte) ([]byte, error) {
+	_, wsID, err := s.serviceAccountCtx(ident, data)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	list, err := s.apiTokenService.ListServiceAccounts(ctx, wsID)
+	if err != nil {
+		return nil, err
+	}
+	return marshal(list)
+}
+
+func (s *Server) NatsDeleteServiceAccount(ident *auth.APIIdentity, data []byte) ([]byte, error) {
+	req, wsID, err := s.serviceAccountCtx(ident, data)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	saID, err := s.serviceAccountID(ctx, wsID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return nil, s.apiTokenService.DeleteServiceAccount(ctx, wsID, saID)
+}
+
+func (s *Server) NatsCreateServiceAccountToken(ident *auth.APIIdentity, data []byte) ([]byte, error) {
+	req, wsID, err := s.serviceAccountCtx(ident, data)
+	if err != nil {
+		return nil, err
+	}
+	if ident.ServiceAccount {
+		return nil, fmt.Errorf("service account tokens cannot create tokens")
+	}
+	if req.TokenName == "" {
+		return nil, fmt.Errorf("token_name is required")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	saID, err := s.serviceAccountID(ctx, wsID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	vo, err := s.apiTokenService.CreateServiceAccountToken(ctx, wsID, saID, ident.Username, &dto.APITokenDto{
+		Name:          req.TokenName,
+		ExpiresInDays: req.ExpiresInDays,
+		Role:          req.Role,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return marshal(vo)
+}
+
+func (s *Server) NatsListServiceAccountTokens(ident *auth.APIIdentity, data []byte) ([]byte, error) {
+	req, wsID, err := s.serviceAccountCtx(ident, data)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	saID, err := s.serviceAccountID(ctx, wsID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.apiTokenService.ListServiceAccountTokens(ctx, wsID, saID)
+	if err != nil {
+		return nil, err
+	}
+	return marshal(list)
+}
+
+func (s *Server) NatsRevokeServiceAccountToken(ident *auth.APIIdentity, data []byte) ([]byte, error) {
+	req, wsID, err := s.serviceAccountCtx(ident, data)
+	if err != nil {
+		return nil, err
+	}
+	if req.TokenID == "" {
+		return nil, fmt.Errorf("token_id is required")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	saID, err := s.serviceAccountID(ctx, wsID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return nil, s.apiTokenService.RevokeServiceAccountToken(ctx, wsID, saID, req.TokenID)
+}
@@ -15,7 +15,10 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/alatticeio/lattice/api/v1alpha1"
 	"github.com/alatticeio/lattice/internal/server/dto"
@@ -75,7 +78,8 @@ func (s *Server) deletePeering(c *gin.Context) {
 }
 
 // gatewayInfo returns the gateway peer's public key, IP, and network CIDR for a
-// given namespace/network. Remote clusters call this to set up cross-cluster tunnels.
+// given namespace/network, along with the addresses of the network's peers.
+// Remote clusters call this to set up cross-cluster tunnels.
 //
 // Query params:
 //   - namespace (required): the K8s namespace of the local network
@@ -117,18 +121,66 @@ func (s *Server) gatewayInfo() gin.HandlerFunc {
 			resp.Error(c, fmt.Sprintf("no gateway peer found in %s/%s", ns, networkName))
 			return
 		}
-		gw := &peerList.Items[0]
+		gw, err := s.activeGateway(ctx, ns, networkName, peerList.Items)
+		if err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
 		if gw.Status.AllocatedAddress == nil {
 			resp.Error(c, "gateway peer has no allocated address yet")
 			return
 		}
 
+		var members v1alpha1.LatticePeerList
+		if err := s.client.List(ctx, &members, client.InNamespace(ns), client.MatchingLabels{
+			fmt.Sprintf("alattice.io/network-%s", networkName): "true",
+		}); err != nil {
+			resp.Error(c, err.Error())
+			return
+		}
+		peers := make(map[string]string, len(members.Items))
+		for _, p := range members.Items {
+			if p.Labels["alattice.io/shadow"] == "true" || p.Status.AllocatedAddress == nil {
+				continue
+			}
+			peers[p.Name] = strings.Split(*p.Status.AllocatedAddress, "/")[0]
+		}
+
 		resp.OK(c, gin.H{
 			"publicKey": gw.Spec.PublicKey,
 			"gatewayIP": *gw.Status.AllocatedAddress,
 			"cidr":      network.Status.ActiveCIDR,
 			"appId":     gw.Spec.AppId,
 			"peerId":    gw.Spec.PeerId,
+			"peers":     peers,
 		})
 	}
 }
+
+// activeGateway picks the gateway reported to remote clusters: the one
+// elected by a cluster peering of the network, else the first gateway with
+// a recent heartbeat, else the first gateway.
+func (s *Server) activeGateway(ctx context.Context, ns, networkName string, gateways []v1alpha1.LatticePeer) (*v1alpha1.LatticePeer, error) {
+	var peerings v1alpha1.LatticeClusterPeeringList
+	if err := s.client.List(ctx, &peerings); err != nil {
+		return nil, err
+	}
+	elected := make(map[string]bool)
+	for _, cp := range peerings.Items {
+		if cp.Spec.LocalNamespace == ns && cp.Spec.LocalNetwork == networkName && cp.Status.LocalGateway != "" {
+			elected[cp.Status.LocalGateway] = true
+		}
+	}
+	for i := range gateways {
+		if elected[gateways[i].Name] {
+			return &gateways[i], nil
+		}
+	}
+	for i := range gateways {
+		t, err := time.Parse(time.RFC3339, gateways[i].Annotations[v1alpha1.AnnotationGatewayHeartbeat])
+		if err == nil && time.Since(t) < 90*time.Second {
+			return &gateways[i], nil
+		}
+	}
+	return &gateways[0], nil
+}
@@ -70,7 +70,13 @@ func (s *Server) createOrUpdatePolicy(c *gin.Context) {
 			resp.Error(c, err.Error())
 			return
 		}
-		c.JSON(202, gin.H{"code": 0, "msg": "policy creation submitted for approval", "data": v})
+		body := gin.H{"code": 0, "msg": "policy creation submitted for approval", "data": v}
+		if warnings, err := s.policyController.Lint(c.Request.Context(), wsID, &req); err != nil {
+			s.logger.Warn("policy lint failed", "policy", req.Name, "err", err)
+		} else if len(warnings) > 0 {
+			body["warnings"] = warnings
+		}
+		c.JSON(202, body)
 		return
 	}
 
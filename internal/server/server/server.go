@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/alatticeio/lattice/api/v1alpha1"
 	"github.com/alatticeio/lattice/internal/agent/config"
 	"github.com/alatticeio/lattice/internal/agent/infra"
 	"github.com/alatticeio/lattice/internal/agent/log"
@@ -26,6 +27,7 @@ import (
 	"github.com/alatticeio/lattice/internal/monitor"
 	"github.com/alatticeio/lattice/internal/server/auth"
 	"github.com/alatticeio/lattice/internal/server/controller"
+	"github.com/alatticeio/lattice/internal/server/dto"
 	"github.com/alatticeio/lattice/internal/server/llm"
 	managementnats "github.com/alatticeio/lattice/internal/server/nats"
 	"github.com/alatticeio/lattice/internal/server/permission"
@@ -34,10 +36,13 @@ import (
 	"github.com/alatticeio/lattice/internal/server/service"
 	"github.com/alatticeio/lattice/pkg/utils"
 	"github.com/alatticeio/lattice/pkg/version"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
@@ -65,21 +70,33 @@ type Server struct {
 	relayController      controller.RelayController
 	invitationController controller.InvitationController
 
-	monitorController      controller.MonitorController
-	alertController        controller.AlertController
-	customMetricController controller.CustomMetricController
-	profileController      controller.ProfileController
-	auditController        controller.AuditController
-	workflowController     controller.WorkflowController
-
-	aiService      service.AIService
-	peeringService service.PeeringService
+	monitorController        controller.MonitorController
+	alertController          controller.AlertController
+	customMetricController   controller.CustomMetricController
+	profileController        controller.ProfileController
+	auditController          controller.AuditController
+	aiConversationController controller.AIConversationController
+	flowLogController        controller.FlowLogController
+	workflowController       controller.WorkflowController
+	bundleController         controller.BundleController
+
+	aiService         service.AIService
+	policyLintService service.PolicyLintService
+	peeringService    service.PeeringService
+	crdAuditService   service.CRDAuditService
+	apiTokenService   service.APITokenService
+	groupSyncService  service.GroupSyncService
 
 	middleware      *middleware.Middleware
+	checker         permission.Checker
 	revocationList  *auth.RevocationList
 	auditService    service.AuditService
+	flowLogService  service.FlowLogService
 	workflowService service.WorkflowService
 
+	// telemetryService is nil when no VictoriaMetrics is configured.
+	telemetryService service.TelemetryService
+
 	store    store.Store
 	presence *managementnats.NodePresenceStore
 	monitor  *monitor.Monitor
@@ -155,23 +172,47 @@ func NewServer(ctx context.Context, serverConfig *ServerConfig) (*Server, error)
 
 	presence := managementnats.NewNodePresenceStore()
 
-	auditSvc := service.NewAuditService(st)
+	auditSvc := service.NewAuditService(st, cfg.Audit)
 	auditSvc.Start(ctx)
 
+	flowLogSvc := service.NewFlowLogService(st)
+	flowLogSvc.Start(ctx)
+
 	workflowSvc := service.NewWorkflowService(st)
 
-	// ── 弱依赖③：AI 服务（APIKey 未配置时降级为 nil）──────────────────────
+	// Kubernetes 侧的 CRD 变更（kubectl / GitOps）同样记入审计日志。
+	var crdAuditSvc service.CRDAuditService
+	if client != nil {
+		crdAuditSvc = service.NewCRDAuditService(client, auditSvc, st, cfg.Audit)
+		if err := crdAuditSvc.Start(ctx); err != nil {
+			logger.Warn("CRD audit init failed, out-of-band CRD changes will not be audited", "err", err)
+			crdAuditSvc = nil
+		}
+	}
+
+	// 策略检查不依赖 LLM：按 ai.audit-schedule 定时检查所有工作空间并记录日志，安全审计报告实时检查。
+	policyLintSvc := service.NewPolicyLintService(client, st)
+	if client != nil {
+		if err := policyLintSvc.Start(ctx, cfg.AI.AuditSchedule); err != nil {
+			logger.Warn("scheduled policy lint disabled", "err", err)
+		}
+	}
+
+	// ── 弱依赖③：AI 服务（未启用或 Provider 初始化失败时降级为 nil）──────────
+	// local 与 fixture 无需 APIKey，其余 Provider 缺少 APIKey 时 NewClient 返回错误。
 	var aiSvc service.AIService
-	if cfg.AI.Enabled && cfg.AI.APIKey != "" {
+	if cfg.AI.Enabled {
 		llmClient, aiErr := llm.NewClient(cfg.AI)
 		if aiErr != nil {
 			logger.Warn("AI init failed, AI features disabled", "err", aiErr)
 		} else {
-			aiSvc = service.NewAIService(llmClient, st, client, presence, cfg.AI.MaxToolCalls)
+			// 写入类工具只提交审批请求，由 register*Executor 注册的执行器在审批通过后落地。
+			aiSvc = service.NewAIService(llmClient, st, client, presence, policyLintSvc,
+				service.NewPolicyService(client, st), workflowSvc, auditSvc, cfg.AI)
 			logger.Info("AI service initialized", "provider", cfg.AI.Provider)
 		}
 	} else {
-		logger.Info("AI service disabled (set ai.enabled=true and ai.api-key to enable)")
+		logger.Info("AI service disabled (set ai.enabled=true to enable)")
 	}
 
 	// ── 弱依赖④：Monitor（可选）────────────────────────────────────
@@ -188,44 +229,71 @@ func NewServer(ctx context.Context, serverConfig *ServerConfig) (*Server, error)
 		mon.StartAlertEngine(context.Background())
 	}
 
+	// agent 经 NATS 上报的遥测数据转发到 VictoriaMetrics（telemetry.transport=nats）。
+	var telemetrySvc service.TelemetryService
+	if remoteWrite := cfg.Monitor.RemoteWrite; remoteWrite != "" || cfg.Monitor.Address != "" {
+		if remoteWrite == "" {
+			remoteWrite = strings.TrimSuffix(cfg.Monitor.Address, "/") + "/api/v1/write"
+		}
+		telemetrySvc = service.NewTelemetryService(remoteWrite)
+	}
+
 	revocationList := auth.NewRevocationList()
 	revocationList.StartCleanup(5 * time.Minute)
 
+	apiTokenSvc := service.NewAPITokenService(st, cfg.APITokens)
+
+	// IdP 组 → 工作空间角色：登录时同步，并按周期应用规则变更与过期。
+	groupSyncSvc := service.NewGroupSyncService(st, cfg.Dex)
+	if cfg.Dex.ProviderUrl != "" {
+		groupSyncSvc.Start(ctx)
+	}
+
 	checker := permission.NewChecker(st, nil)
 
 	s := &Server{
-		Engine:                 gin.Default(),
-		logger:                 logger,
-		listen:                 cfg.Listen,
-		nats:                   signal,
-		manager:                mgr,
-		cacheReady:             cacheReady,
-		client:                 client,
-		cfg:                    cfg,
-		presence:               presence,
-		peerController:         controller.NewPeerController(client, st, presence),
-		networkController:      controller.NewNetworkController(client, st),
-		userController:         controller.NewUserController(st),
-		policyController:       controller.NewPolicyController(client, st),
-		workspaceController:    controller.NewWorkspaceController(client, st),
-		memberController:       controller.NewWorkspaceMemberController(st),
-		tokenController:        controller.NewTokenController(client, st),
-		relayController:        controller.NewRelayController(client, st),
-		invitationController:   controller.NewInvitationController(st, string(utils.GetJWTSecret())),
-		monitorController:      controller.NewMonitorController(cfg.Monitor.Address, st),
-		alertController:        controller.NewAlertController(st),
-		customMetricController: controller.NewCustomMetricController(st),
-		profileController:      controller.NewProfileController(st),
-		auditController:        controller.NewAuditController(auditSvc),
-		workflowController:     controller.NewWorkflowController(workflowSvc),
-		middleware:             middleware.NewMiddleware(checker, st, revocationList),
-		revocationList:         revocationList,
-		auditService:           auditSvc,
-		workflowService:        workflowSvc,
-		store:                  st,
-		aiService:              aiSvc,
-		peeringService:         service.NewPeeringService(client, st),
-		monitor:                mon,
+		Engine:                   gin.Default(),
+		logger:                   logger,
+		listen:                   cfg.Listen,
+		nats:                     signal,
+		manager:                  mgr,
+		cacheReady:               cacheReady,
+		client:                   client,
+		cfg:                      cfg,
+		presence:                 presence,
+		peerController:           controller.NewPeerController(client, st, presence),
+		networkController:        controller.NewNetworkController(client, st),
+		userController:           controller.NewUserController(st),
+		policyController:         controller.NewPolicyController(client, st),
+		workspaceController:      controller.NewWorkspaceController(client, st),
+		memberController:         controller.NewWorkspaceMemberController(st),
+		tokenController:          controller.NewTokenController(client, st),
+		relayController:          controller.NewRelayController(client, st),
+		invitationController:     controller.NewInvitationController(st, string(utils.GetJWTSecret())),
+		monitorController:        controller.NewMonitorController(cfg.Monitor.Address, st),
+		alertController:          controller.NewAlertController(st),
+		customMetricController:   controller.NewCustomMetricController(st, mon),
+		profileController:        controller.NewProfileController(st),
+		auditController:          controller.NewAuditController(auditSvc),
+		aiConversationController: controller.NewAIConversationController(service.NewAIConversationService(st)),
+		flowLogController:        controller.NewFlowLogController(flowLogSvc),
+		workflowController:       controller.NewWorkflowController(workflowSvc),
+		bundleController:         controller.NewBundleController(client, st),
+		middleware:               middleware.NewMiddleware(checker, st, revocationList, apiTokenSvc),
+		checker:                  checker,
+		revocationList:           revocationList,
+		auditService:             auditSvc,
+		flowLogService:           flowLogSvc,
+		workflowService:          workflowSvc,
+		store:                    st,
+		aiService:                aiSvc,
+		policyLintService:        policyLintSvc,
+		peeringService:           service.NewPeeringService(client, st),
+		crdAuditService:          crdAuditSvc,
+		apiTokenService:          apiTokenSvc,
+		groupSyncService:         groupSyncSvc,
+		monitor:                  mon,
+		telemetryService:         telemetrySvc,
 	}
 
 	// initAdmins：DB 已就绪后执行；失败只告警，不阻断启动。
@@ -237,6 +305,8 @@ func NewServer(ctx context.Context, serverConfig *ServerConfig) (*Server, error)
 
 	// Register workflow executors before starting the router.
 	s.registerPolicyExecutor()
+	s.registerPeerExecutors()
+	s.registerTokenExecutor()
 
 	if err = s.apiRouter(); err != nil {
 		return nil, err
@@ -258,21 +328,35 @@ func (s *Server) Start(ctx context.Context) error {
 		"lattice.signals.peer.register":  s.Register,
 		"lattice.signals.peer.GetNetMap": s.GetNetMap,
 		"lattice.signals.peer.heartbeat": s.Heartbeat,
+		"lattice.signals.peer.flowlog":   s.FlowLog,
+		"lattice.signals.peer.telemetry": s.Telemetry,
 
 		// CLI ↔ server (service/admin plane)
 		"lattice.signals.service.info":             s.Info,
-		"lattice.signals.service.createToken":      s.CreateToken,
-		"lattice.signals.service.workspace.add":    s.NatsAddWorkspace,
-		"lattice.signals.service.workspace.remove": s.NatsRemoveWorkspace,
-		"lattice.signals.service.workspace.list":   s.NatsListWorkspaces,
-		"lattice.signals.service.policy.add":       s.NatsAddPolicy,
-		"lattice.signals.service.policy.allow-all": s.NatsAllowAll,
-		"lattice.signals.service.policy.remove":    s.NatsRemovePolicy,
-		"lattice.signals.service.policy.list":      s.NatsListPolicies,
-		"lattice.signals.service.token.list":       s.NatsListTokens,
-		"lattice.signals.service.token.remove":     s.NatsRemoveToken,
-		"lattice.signals.service.peer.list":        s.NatsPeerList,
-		"lattice.signals.service.peer.label":       s.NatsPeerLabel,
+		"lattice.signals.service.createToken":      s.natsAudited("CREATE", "token", dto.RoleEditor, s.CreateToken),
+		"lattice.signals.service.workspace.add":    s.natsAudited("CREATE", "workspace", natsPlatformAdmin, s.NatsAddWorkspace),
+		"lattice.signals.service.workspace.remove": s.natsAudited("DELETE", "workspace", natsPlatformAdmin, s.NatsRemoveWorkspace),
+		"lattice.signals.service.workspace.list":   s.natsAuthorized(natsPlatformAdmin, s.NatsListWorkspaces),
+		"lattice.signals.service.policy.add":       s.natsAudited("CREATE", "policy", dto.RoleEditor, s.NatsAddPolicy),
+		"lattice.signals.service.policy.allow-all": s.natsAudited("CREATE", "policy", dto.RoleEditor, s.NatsAllowAll),
+		"lattice.signals.service.policy.remove":    s.natsAudited("DELETE", "policy", dto.RoleEditor, s.NatsRemovePolicy),
+		"lattice.signals.service.policy.list":      s.natsAuthorized(dto.RoleViewer, s.NatsListPolicies),
+		"lattice.signals.service.policy.lint":      s.natsAuthorized(dto.RoleViewer, s.NatsLintPolicies),
+		"lattice.signals.service.token.list":       s.natsAuthorized(dto.RoleViewer, s.NatsListTokens),
+		"lattice.signals.service.token.remove":     s.natsAudited("DELETE", "token", dto.RoleEditor, s.NatsRemoveToken),
+		"lattice.signals.service.peer.list":        s.natsAuthorized(dto.RoleViewer, s.NatsPeerList),
+		"lattice.signals.service.peer.label":       s.natsAudited("UPDATE", "peer", dto.RoleEditor, s.NatsPeerLabel),
+		"lattice.signals.service.bundle.export":    s.natsAuthorized(dto.RoleViewer, s.NatsExportBundle),
+		"lattice.signals.service.bundle.diff":      s.natsAuthorized(dto.RoleViewer, s.NatsDiffBundle),
+		"lattice.signals.service.bundle.apply":     s.natsAuditedAs("UPDATE", "bundle", dto.RoleEditor, s.NatsApplyBundle),
+
+		// service accounts — always require an API token of a workspace admin
+		"lattice.signals.service.serviceaccount.create":       s.natsAuditedAs("CREATE", "service-account", dto.RoleAdmin, s.NatsCreateServiceAccount),
+		"lattice.signals.service.serviceaccount.list":         s.natsAuthorizedAs(dto.RoleAdmin, s.NatsListServiceAccounts),
+		"lattice.signals.service.serviceaccount.delete":       s.natsAuditedAs("DELETE", "service-account", dto.RoleAdmin, s.NatsDeleteServiceAccount),
+		"lattice.signals.service.serviceaccount.token.create": s.natsAuditedAs("CREATE", "api-token", dto.RoleAdmin, s.NatsCreateServiceAccountToken),
+		"lattice.signals.service.serviceaccount.token.list":   s.natsAuthorizedAs(dto.RoleAdmin, s.NatsListServiceAccountTokens),
+		"lattice.signals.service.serviceaccount.token.revoke": s.natsAuditedAs("DELETE", "api-token", dto.RoleAdmin, s.NatsRevokeServiceAccountToken),
 	}
 
 	for route, handler := range routes {
@@ -345,17 +429,95 @@ func (s *Server) CreateToken(content []byte) ([]byte, error) {
 // the in-memory presence store so ListPeers can report real-time online status.
 func (s *Server) Heartbeat(content []byte) ([]byte, error) {
 	var payload struct {
-		AppID string `json:"appId"`
+		AppID     string           `json:"appId"`
+		Peers     []infra.PeerPath `json:"peers"`
+		Namespace string           `json:"namespace"`
+		Relay     string           `json:"relay"`
 	}
 	if err := json.Unmarshal(content, &payload); err != nil {
 		return nil, err
 	}
 	if payload.AppID != "" {
 		s.presence.Update(payload.AppID)
+		s.presence.UpdatePaths(payload.AppID, payload.Peers)
+		if payload.Namespace != "" && s.presence.UpdateRelay(payload.AppID, payload.Relay) {
+			go s.syncRelayLabel(payload.Namespace, payload.AppID, payload.Relay)
+		}
+		if payload.Namespace != "" {
+			s.syncGatewayHeartbeat(payload.Namespace, payload.AppID)
+		}
 	}
 	return []byte{}, nil
 }
 
+// gatewayHeartbeatRefresh is how old the recorded heartbeat of a gateway may
+// get before a heartbeat replaces it. Gateways are elected on a heartbeat
+// younger than 90s and agents send one every 30s, so a live gateway stays
+// eligible while most heartbeats write nothing.
+const gatewayHeartbeatRefresh = 45 * time.Second
+
+// syncGatewayHeartbeat records the heartbeat of a gateway peer on its
+// LatticePeer, where the peering controllers elect the gateways that carry
+// traffic. The peer is read from the cache, and it is only patched when the
+// recorded heartbeat is older than gatewayHeartbeatRefresh. Peers that are
+// not gateways are left alone.
+func (s *Server) syncGatewayHeartbeat(namespace, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	peer := &v1alpha1.LatticePeer{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, peer); err != nil {
+		return
+	}
+	if peer.Labels["alattice.io/gateway"] != "true" {
+		return
+	}
+	now := time.Now().UTC()
+	if t, err := time.Parse(time.RFC3339, peer.Annotations[v1alpha1.AnnotationGatewayHeartbeat]); err == nil && now.Sub(t) < gatewayHeartbeatRefresh {
+		return
+	}
+	original := peer.DeepCopy()
+	if peer.Annotations == nil {
+		peer.Annotations = make(map[string]string)
+	}
+	peer.Annotations[v1alpha1.AnnotationGatewayHeartbeat] = now.Format(time.RFC3339)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.client.Patch(ctx, peer, client.MergeFrom(original)); err != nil {
+			s.logger.Warn("gateway heartbeat: patch failed", "namespace", namespace, "peer", name, "err", err)
+		}
+	}()
+}
+
+// syncRelayLabel records the home relay an agent reported on its LatticePeer
+// so RelayReconciler can count relay load. An empty relay removes the label.
+func (s *Server) syncRelayLabel(namespace, name, relay string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	peer := &v1alpha1.LatticePeer{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, peer); err != nil {
+		s.logger.Warn("relay label: peer not found", "namespace", namespace, "peer", name, "err", err)
+		return
+	}
+	if peer.Labels[v1alpha1.RelayPeerLabel] == relay {
+		return
+	}
+	original := peer.DeepCopy()
+	if relay == "" {
+		delete(peer.Labels, v1alpha1.RelayPeerLabel)
+	} else {
+		if peer.Labels == nil {
+			peer.Labels = make(map[string]string)
+		}
+		peer.Labels[v1alpha1.RelayPeerLabel] = relay
+	}
+	if err := s.client.Patch(ctx, peer, client.MergeFrom(original)); err != nil {
+		s.logger.Warn("relay label: patch failed", "namespace", namespace, "peer", name, "err", err)
+	}
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 	return nil
 }
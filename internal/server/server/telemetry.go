@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/infra"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Telemetry forwards a Remote Write batch an agent sends over NATS to
+// VictoriaMetrics. The report is bound to the namespace of the enrollment
+// token it carries: a report naming another namespace is rejected, and the
+// peer must exist in that namespace. The workspace labels of the batch are
+// then set from that peer, not from the agent's own labels.
+func (s *Server) Telemetry(content []byte) ([]byte, error) {
+	if s.telemetryService == nil {
+		return nil, errors.New("telemetry forwarding is disabled: monitor.address is not set")
+	}
+	var report infra.TelemetryReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, err
+	}
+	if report.AppID == "" || report.Namespace == "" {
+		return nil, errors.New("telemetry report has no peer identity")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	namespace, err := s.client.TokenNamespace(ctx, report.Token)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry from %s/%s: %w", report.Namespace, report.AppID, err)
+	}
+	if namespace != report.Namespace {
+		return nil, fmt.Errorf("telemetry from %s/%s: token belongs to namespace %s", report.Namespace, report.AppID, namespace)
+	}
+	peer := &v1alpha1.LatticePeer{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: report.Namespace, Name: report.AppID}, peer); err != nil {
+		return nil, fmt.Errorf("telemetry from unknown peer %s/%s: %w", report.Namespace, report.AppID, err)
+	}
+	if err := s.telemetryService.Forward(ctx, peer.Namespace, peer.Name, report.Payload); err != nil {
+		return nil, err
+	}
+	return []byte{}, nil
+}
@@ -16,19 +16,19 @@ func (s *Server) userRouter() {
 	// Auth group — logout endpoint.
 	authGroup := s.Group("/api/v1/auth")
 	{
-		authGroup.POST("/logout", middleware.AuthMiddleware(s.revocationList), s.logout())
+		authGroup.POST("/logout", middleware.AuthMiddleware(s.revocationList, s.apiTokenService), s.logout())
 	}
 
 	userApi := s.Group("/api/v1/users")
 	{
 		userApi.POST("/register", s.RegisterUser)
 		userApi.POST("/login", s.login)
-		userApi.GET("/getme", middleware.AuthMiddleware(s.revocationList), s.getMe())
-		userApi.GET("/list", middleware.AuthMiddleware(s.revocationList), s.listUser())
-		userApi.POST("/add", middleware.AuthMiddleware(s.revocationList), s.handleAddUser())
-		userApi.DELETE("/:name", middleware.AuthMiddleware(s.revocationList), s.handleDeleteUser())
-		userApi.PATCH("/:id/system-role", middleware.AuthMiddleware(s.revocationList), s.handleUpdateSystemRole())
-		userApi.GET("/:id/workspaces", middleware.AuthMiddleware(s.revocationList), s.handleGetUserWorkspaces())
+		userApi.GET("/getme", middleware.AuthMiddleware(s.revocationList, s.apiTokenService), s.getMe())
+		userApi.GET("/list", middleware.AuthMiddleware(s.revocationList, s.apiTokenService), s.listUser())
+		userApi.POST("/add", middleware.AuthMiddleware(s.revocationList, s.apiTokenService), s.handleAddUser())
+		userApi.DELETE("/:name", middleware.AuthMiddleware(s.revocationList, s.apiTokenService), s.handleDeleteUser())
+		userApi.PATCH("/:id/system-role", middleware.AuthMiddleware(s.revocationList, s.apiTokenService), s.handleUpdateSystemRole())
+		userApi.GET("/:id/workspaces", middleware.AuthMiddleware(s.revocationList, s.apiTokenService), s.handleGetUserWorkspaces())
 	}
 }
 
@@ -225,6 +225,10 @@ func (s *Server) handleGetUserWorkspaces() gin.HandlerFunc {
 
 func (s *Server) logout() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if middleware.APIIdentity(c) != nil {
+			resp.BadRequest(c, "api tokens are revoked through /api/v1/api-tokens")
+			return
+		}
 		jti := c.GetString("jti")
 		if jti == "" {
 			resp.Error(c, "invalid token")
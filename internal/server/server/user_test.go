@@ -53,7 +53,7 @@ func TestLogoutEndpoint_RevokesToken(t *testing.T) {
 	// that mimics the logout logic.
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	r.POST("/api/v1/auth/logout", middleware.AuthMiddleware(rl), func(c *gin.Context) {
+	r.POST("/api/v1/auth/logout", middleware.AuthMiddleware(rl, nil), func(c *gin.Context) {
 		jti := c.GetString("jti")
 		if jti == "" {
 			c.JSON(400, gin.H{"error": "invalid token"})
@@ -93,7 +93,7 @@ func TestLogoutEndpoint_RejectsMissingToken(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	r.POST("/api/v1/auth/logout", middleware.AuthMiddleware(rl), func(c *gin.Context) {
+	r.POST("/api/v1/auth/logout", middleware.AuthMiddleware(rl, nil), func(c *gin.Context) {
 		jti := c.GetString("jti")
 		if jti == "" {
 			c.JSON(200, gin.H{"error": "invalid token"})
@@ -132,12 +132,12 @@ func TestLogoutEndpoint_RevokedTokenCannotBeUsed(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	r.POST("/api/v1/auth/logout", middleware.AuthMiddleware(rl), func(c *gin.Context) {
+	r.POST("/api/v1/auth/logout", middleware.AuthMiddleware(rl, nil), func(c *gin.Context) {
 		jti := c.GetString("jti")
 		rl.Revoke(jti, claims.ExpiresAt.Time)
 		c.JSON(200, gin.H{"code": 0})
 	})
-	r.GET("/api/v1/users/getme", middleware.AuthMiddleware(rl), func(c *gin.Context) {
+	r.GET("/api/v1/users/getme", middleware.AuthMiddleware(rl, nil), func(c *gin.Context) {
 		c.JSON(200, gin.H{"code": 0})
 	})
 
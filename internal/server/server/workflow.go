@@ -1,8 +1,10 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 
+	"github.com/alatticeio/lattice/internal/agent/infra"
 	"github.com/alatticeio/lattice/internal/agent/store"
 	"github.com/alatticeio/lattice/internal/server/dto"
 	"github.com/alatticeio/lattice/internal/server/service"
@@ -160,3 +162,35 @@ func (s *Server) handleRejectWorkflowRequest() gin.HandlerFunc {
 		resp.OK(c, nil)
 	}
 }
+
+// registerPeerExecutors registers the executors for approved peer changes.
+// Payloads are dto.PeerLabelsPayload ("update") and dto.PeerPayload ("disable").
+func (s *Server) registerPeerExecutors() {
+	s.workflowService.RegisterExecutor("peer", "update", func(ctx context.Context, payload string) error {
+		var p dto.PeerLabelsPayload
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return err
+		}
+		return s.peerController.UpdateLabels(ctx, p.Namespace, p.Name, p.Labels)
+	})
+	s.workflowService.RegisterExecutor("peer", "disable", func(ctx context.Context, payload string) error {
+		var p dto.PeerPayload
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return err
+		}
+		return s.peerController.DisablePeer(ctx, p.Namespace, p.Name)
+	})
+}
+
+// registerTokenExecutor registers the executor that creates an enrollment
+// token once approved. Payload is dto.TokenPayload.
+func (s *Server) registerTokenExecutor() {
+	s.workflowService.RegisterExecutor("token", "create", func(ctx context.Context, payload string) error {
+		var p dto.TokenPayload
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return err
+		}
+		_, err := s.tokenController.Create(context.WithValue(ctx, infra.WorkspaceKey, p.WorkspaceID))
+		return err
+	})
+}
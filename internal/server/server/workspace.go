@@ -10,7 +10,7 @@ import (
 
 func (s *Server) workspaceRouter() {
 	workspaceGroup := s.Group("/api/v1/workspaces")
-	workspaceGroup.Use(middleware.AuthMiddleware(s.revocationList))
+	workspaceGroup.Use(middleware.AuthMiddleware(s.revocationList, s.apiTokenService))
 	{
 		workspaceGroup.POST("/add", s.handleAddWs())
 		workspaceGroup.GET("/list", s.handleListWs())
@@ -3,15 +3,19 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/config"
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"github.com/alatticeio/lattice/internal/agent/store"
 	"github.com/alatticeio/lattice/internal/server/llm"
+	"github.com/alatticeio/lattice/internal/server/models"
 	managementnats "github.com/alatticeio/lattice/internal/server/nats"
 	"github.com/alatticeio/lattice/internal/server/resource"
+	"github.com/alatticeio/lattice/internal/server/vo"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -21,9 +25,20 @@ import (
 // ── Public types ──────────────────────────────────────────────────────────────
 
 type ChatRequest struct {
-	Message     string        `json:"message"`
-	WorkspaceID string        `json:"workspaceId"`
-	History     []ChatMessage `json:"history"`
+	Message     string `json:"message"`
+	WorkspaceID string `json:"workspaceId"`
+	// ConversationID continues a stored conversation; its messages replace
+	// History. Empty starts a new conversation.
+	ConversationID string        `json:"conversationId,omitempty"`
+	History        []ChatMessage `json:"history"`
+
+	// Caller identity, set by the HTTP layer. Write tools submit workflow
+	// requests in the caller's name and are offered only when AllowWrites is
+	// set.
+	UserID      string `json:"-"`
+	UserName    string `json:"-"`
+	UserEmail   string `json:"-"`
+	AllowWrites bool   `json:"-"`
 }
 
 type ChatMessage struct {
@@ -33,11 +48,15 @@ type ChatMessage struct {
 
 // StreamEvent is the SSE payload sent to the client.
 type StreamEvent struct {
-	Type    string          `json:"type"`              // token | tool_use | preview | error | done
-	Content string          `json:"content,omitempty"` // type=token
-	Tool    string          `json:"tool,omitempty"`    // type=tool_use
-	Input   json.RawMessage `json:"input,omitempty"`   // type=tool_use
-	Error   string          `json:"error,omitempty"`   // type=error
+	Type       string          `json:"type"`                 // conversation | token | tool_use | preview | error | done
+	Content    string          `json:"content,omitempty"`    // type=token; type=preview: unified diff of the change
+	Tool       string          `json:"tool,omitempty"`       // type=tool_use | preview
+	Input      json.RawMessage `json:"input,omitempty"`      // type=tool_use
+	WorkflowID string          `json:"workflowId,omitempty"` // type=preview: the approval request submitted
+	Warnings   []AuditFinding  `json:"warnings,omitempty"`   // type=preview: policy lint findings
+	Error      string          `json:"error,omitempty"`      // type=error
+
+	ConversationID string `json:"conversationId,omitempty"` // type=conversation: sent first, pass it back to resume
 }
 
 // StreamWriter receives events from the AI service and forwards them to the HTTP layer.
@@ -45,14 +64,9 @@ type StreamWriter interface {
 	Write(event StreamEvent) error
 }
 
-// AuditFinding is a single security issue found during an audit scan.
-type AuditFinding struct {
-	Severity    string `json:"severity"` // high | medium | low
-	Rule        string `json:"rule"`
-	Resource    string `json:"resource"`
-	Description string `json:"description"`
-	Suggestion  string `json:"suggestion"`
-}
+// AuditFinding is a single security issue found during an audit scan. It is
+// defined in vo so that policy responses can carry lint findings.
+type AuditFinding = vo.AuditFinding
 
 // AuditReport is the result of a workspace security audit.
 type AuditReport struct {
@@ -75,6 +89,11 @@ type aiService struct {
 	store        store.Store
 	k8s          *resource.Client
 	presence     *managementnats.NodePresenceStore
+	lint         PolicyLintService
+	policies     PolicyService
+	workflow     WorkflowService
+	audit        AuditService
+	cfg          config.AIConfig
 	maxToolCalls int
 }
 
@@ -83,8 +102,13 @@ func NewAIService(
 	st store.Store,
 	k8s *resource.Client,
 	presence *managementnats.NodePresenceStore,
-	maxToolCalls int,
+	lint PolicyLintService,
+	policies PolicyService,
+	workflow WorkflowService,
+	audit AuditService,
+	cfg config.AIConfig,
 ) AIService {
+	maxToolCalls := cfg.MaxToolCalls
 	if maxToolCalls <= 0 {
 		maxToolCalls = 5
 	}
@@ -94,6 +118,11 @@ func NewAIService(
 		store:        st,
 		k8s:          k8s,
 		presence:     presence,
+		lint:         lint,
+		policies:     policies,
+		workflow:     workflow,
+		audit:        audit,
+		cfg:          cfg,
 		maxToolCalls: maxToolCalls,
 	}
 }
@@ -106,6 +135,23 @@ func (s *aiService) Chat(ctx context.Context, req *ChatRequest, out StreamWriter
 		return fmt.Errorf("workspace not found: %w", err)
 	}
 
+	conv, history, err := s.openConversation(ctx, req, ws)
+	if err != nil {
+		_ = out.Write(StreamEvent{Type: "error", Error: err.Error()})
+		return err
+	}
+	_ = out.Write(StreamEvent{Type: "conversation", ConversationID: conv.ID})
+
+	// Everything said in this turn is stored, even when the turn fails, and
+	// even when the client has gone away.
+	tr := &transcript{}
+	if req.ConversationID == "" {
+		for _, h := range history {
+			tr.add(h, llm.Usage{})
+		}
+	}
+	defer s.saveTranscript(context.WithoutCancel(ctx), conv.ID, tr)
+
 	system, err := s.buildSystemPrompt(ctx, ws.ID, ws.Namespace, ws.DisplayName)
 	if err != nil {
 		s.logger.Warn("failed to build system prompt, using minimal version", "err", err)
@@ -113,31 +159,30 @@ func (s *aiService) Chat(ctx context.Context, req *ChatRequest, out StreamWriter
 	}
 
 	// Build message history
-	msgs := make([]llm.Message, 0, len(req.History)+1)
-	for _, h := range req.History {
-		msgs = append(msgs, llm.Message{Role: h.Role, Content: h.Content})
-	}
-	msgs = append(msgs, llm.Message{Role: llm.RoleUser, Content: req.Message})
+	userMsg := llm.Message{Role: llm.RoleUser, Content: req.Message}
+	msgs := append(history, userMsg)
+	tr.add(userMsg, llm.Usage{})
 
 	tools := s.buildTools(ws.Namespace)
+	if req.AllowWrites {
+		tools = append(tools, writeTools...)
+	}
 
 	// Agentic loop
 	for i := 0; i < s.maxToolCalls; i++ {
-		llmReq := &llm.Request{
+		resp, err := s.complete(ctx, ws, &llm.Request{
 			System:    system,
 			Messages:  msgs,
 			Tools:     tools,
 			MaxTokens: 4096,
-		}
-
-		resp, err := s.llm.Complete(ctx, llmReq)
+		}, out)
 		if err != nil {
-			_ = out.Write(StreamEvent{Type: "error", Error: err.Error()})
 			return err
 		}
 
 		if !resp.HasToolCalls() {
 			// Final text response
+			tr.add(llm.Message{Role: llm.RoleAssistant, Content: resp.Content}, resp.Usage)
 			_ = out.Write(StreamEvent{Type: "token", Content: resp.Content})
 			_ = out.Write(StreamEvent{Type: "done"})
 			return nil
@@ -154,10 +199,7 @@ func (s *aiService) Chat(ctx context.Context, req *ChatRequest, out StreamWriter
 		for _, tc := range resp.ToolCalls {
 			_ = out.Write(StreamEvent{Type: "tool_use", Tool: tc.Name, Input: tc.Input})
 
-			result, toolErr := s.executeTool(ctx, ws.Namespace, tc.Name, tc.Input)
-			if toolErr != nil {
-				result = fmt.Sprintf("error: %s", toolErr.Error())
-			}
+			result := s.runTool(ctx, req, ws, tc, out)
 			toolResultMsg.ToolResults = append(toolResultMsg.ToolResults, llm.ToolResult{
 				ToolCallID: tc.ID,
 				Content:    result,
@@ -165,24 +207,81 @@ func (s *aiService) Chat(ctx context.Context, req *ChatRequest, out StreamWriter
 		}
 
 		msgs = append(msgs, assistantMsg, toolResultMsg)
+		tr.add(assistantMsg, resp.Usage)
+		tr.add(toolResultMsg, llm.Usage{})
 	}
 
 	// Exhausted tool call budget — ask LLM for final answer without tools
-	llmReq := &llm.Request{
+	resp, err := s.complete(ctx, ws, &llm.Request{
 		System:    system,
 		Messages:  msgs,
 		MaxTokens: 4096,
-	}
-	resp, err := s.llm.Complete(ctx, llmReq)
+	}, out)
 	if err != nil {
-		_ = out.Write(StreamEvent{Type: "error", Error: err.Error()})
 		return err
 	}
+	tr.add(llm.Message{Role: llm.RoleAssistant, Content: resp.Content}, resp.Usage)
 	_ = out.Write(StreamEvent{Type: "token", Content: resp.Content})
 	_ = out.Write(StreamEvent{Type: "done"})
 	return nil
 }
 
+// complete sends one request to the LLM within the daily token quota of ws
+// and records its usage. Errors are also sent to out.
+func (s *aiService) complete(ctx context.Context, ws *models.Workspace, llmReq *llm.Request, out StreamWriter) (*llm.Response, error) {
+	if err := s.reserve(ctx, ws, false); err != nil {
+		_ = out.Write(StreamEvent{Type: "error", Error: err.Error()})
+		return nil, err
+	}
+	resp, err := s.llm.Complete(ctx, llmReq)
+	if err != nil {
+		aiRequests.WithLabelValues(ws.Namespace, "error").Inc()
+		_ = out.Write(StreamEvent{Type: "error", Error: err.Error()})
+		return nil, err
+	}
+	s.recordCompletion(ctx, ws, resp.Usage)
+	return resp, nil
+}
+
+// openConversation returns the stored conversation req continues and its
+// messages, or starts a new conversation whose history is req.History.
+func (s *aiService) openConversation(ctx context.Context, req *ChatRequest, ws *models.Workspace) (*models.AIConversation, []llm.Message, error) {
+	if req.ConversationID == "" {
+		conv := &models.AIConversation{
+			WorkspaceID: ws.ID,
+			UserID:      req.UserID,
+			UserName:    req.UserName,
+			Title:       conversationTitle(req.Message),
+		}
+		if err := s.store.AIConversations().Create(ctx, conv); err != nil {
+			return nil, nil, fmt.Errorf("create conversation: %w", err)
+		}
+		history := make([]llm.Message, 0, len(req.History)+1)
+		for _, h := range req.History {
+			history = append(history, llm.Message{Role: h.Role, Content: h.Content})
+		}
+		return conv, history, nil
+	}
+
+	// Users resume only their own conversations, in the workspace they
+	// were started in.
+	conv, err := s.store.AIConversations().GetByID(ctx, req.ConversationID)
+	if err != nil || conv.WorkspaceID != ws.ID || conv.UserID != req.UserID {
+		return nil, nil, errors.New("conversation not found")
+	}
+	stored, err := s.store.AIConversations().Messages(ctx, conv.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load conversation: %w", err)
+	}
+	return conv, llmMessages(stored), nil
+}
+
+func (s *aiService) saveTranscript(ctx context.Context, conversationID string, tr *transcript) {
+	if err := s.store.AIConversations().AppendMessages(ctx, conversationID, tr.msgs); err != nil {
+		s.logger.Warn("failed to store AI conversation", "conversation", conversationID, "err", err)
+	}
+}
+
 // ── Audit ─────────────────────────────────────────────────────────────────────
 
 func (s *aiService) Audit(ctx context.Context, workspaceID string) (*AuditReport, error) {
@@ -192,12 +291,30 @@ func (s *aiService) Audit(ctx context.Context, workspaceID string) (*AuditReport
 	}
 
 	findings := s.runAuditRules(ctx, ws.Namespace)
+	if s.lint != nil {
+		lintFindings, err := s.lint.Lint(ctx, ws.Namespace)
+		if err != nil {
+			s.logger.Warn("audit: policy lint failed", "err", err)
+		}
+		findings = append(findings, lintFindings...)
+	}
 
-	// Ask LLM to generate human-readable descriptions (best effort)
-	if len(findings) > 0 {
-		s.enrichFindingsWithLLM(ctx, findings)
+	// Ask LLM to describe the findings the rules left undescribed (best effort)
+	for _, f := range findings {
+		if f.Description == "" {
+			s.enrichFindingsWithLLM(ctx, findings)
+			break
+		}
 	}
 
+	return &AuditReport{
+		Score:    auditScore(findings),
+		Findings: findings,
+	}, nil
+}
+
+// auditScore starts at 100 and deducts points per finding by severity.
+func auditScore(findings []AuditFinding) int {
 	score := 100
 	for _, f := range findings {
 		switch f.Severity {
@@ -212,11 +329,7 @@ func (s *aiService) Audit(ctx context.Context, workspaceID string) (*AuditReport
 	if score < 0 {
 		score = 0
 	}
-
-	return &AuditReport{
-		Score:    score,
-		Findings: findings,
-	}, nil
+	return score
 }
 
 func (s *aiService) enrichFindingsWithLLM(ctx context.Context, findings []AuditFinding) {
@@ -225,9 +338,11 @@ func (s *aiService) enrichFindingsWithLLM(ctx context.Context, findings []AuditF
 		Severity string `json:"severity"`
 		Resource string `json:"resource"`
 	}
-	summaries := make([]findingSummary, len(findings))
-	for i, f := range findings {
-		summaries[i] = findingSummary{Rule: f.Rule, Severity: f.Severity, Resource: f.Resource}
+	var summaries []findingSummary
+	for _, f := range findings {
+		if f.Description == "" {
+			summaries = append(summaries, findingSummary{Rule: f.Rule, Severity: f.Severity, Resource: f.Resource})
+		}
 	}
 	summaryJSON, _ := json.Marshal(summaries)
 
@@ -269,6 +384,9 @@ func (s *aiService) enrichFindingsWithLLM(ctx context.Context, findings []AuditF
 		byRule[e.Rule] = struct{ desc, sug string }{e.Description, e.Suggestion}
 	}
 	for i := range findings {
+		if findings[i].Description != "" {
+			continue
+		}
 		if e, ok := byRule[findings[i].Rule]; ok {
 			findings[i].Description = e.desc
 			findings[i].Suggestion = e.sug
@@ -341,10 +459,14 @@ const baseSystemPrompt = `你是 Lattice 的网络管理助手，帮助用户管
 
 ## 操作规范
 - 查询操作：直接返回结果
-- 创建/修改/删除操作：先展示变更预览，用户确认后才能执行
+- 创建/修改操作：调用 create_policy、label_peer、disable_peer、create_token 等写入工具，工具会生成变更预览并提交审批请求，审批通过后才会执行；回复时给出审批请求 ID，不要声称变更已经生效
+- 删除操作：不支持，请引导用户在控制台完成
 - 不确定的操作：先询问用户意图，再给出方案`
 
 func (s *aiService) buildSystemPrompt(ctx context.Context, wsID, namespace, wsName string) (string, error) {
+	if s.k8s == nil {
+		return "", errors.New("kubernetes client not available")
+	}
 	var peerList v1alpha1.LatticePeerList
 	_ = s.k8s.GetAPIReader().List(ctx, &peerList, client.InNamespace(namespace))
 
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/llm"
+	"github.com/alatticeio/lattice/internal/server/models"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ── Write tools ───────────────────────────────────────────────────────────────
+//
+// Write tools never change the workspace themselves. Each one builds a diff
+// preview of the change and submits it as a WorkflowRequest; the executor
+// registered for the request applies it once a reviewer approves.
+
+// aiAction is a change proposed by a write tool.
+type aiAction struct {
+	resourceType string
+	resourceName string
+	action       string
+	// diff is a unified diff of the affected object, current state first.
+	diff     string
+	warnings []AuditFinding
+	// note is passed on to the model with the submission result.
+	note string
+	// payload returns the workflow payload. It runs only once the preview
+	// has been built, so it may create the records the executor reads.
+	payload func(ctx context.Context) (any, error)
+}
+
+// reservedLabelPrefix marks labels Lattice manages itself, such as network
+// membership.
+const reservedLabelPrefix = "alattice.io/"
+
+var writeTools = []llm.Tool{
+	{
+		Name:        "create_policy",
+		Description: "创建或更新访问控制策略（LatticePolicy）。不会直接生效：生成变更预览并提交审批请求，审批通过后才会应用",
+		InputSchema: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"name":{"type":"string","description":"策略名称，小写字母、数字和 -"},
+				"description":{"type":"string","description":"策略说明"},
+				"action":{"type":"string","enum":["ALLOW","DENY"]},
+				"network":{"type":"string","description":"策略所属网络名称"},
+				"peerSelector":{"type":"object","description":"目标 Peer 的标签选择器，如 {\"matchLabels\":{\"role\":\"db\"}}"},
+				"ingress":{"type":"array","description":"入站规则，每条含 from（peerSelector 或 ipBlock 列表）和 ports（protocol、port、endPort）"},
+				"egress":{"type":"array","description":"出站规则，每条含 to 和 ports"}
+			},
+			"required":["name","action","network","peerSelector"]
+		}`),
+	},
+	{
+		Name:        "label_peer",
+		Description: "修改 Peer 的标签，值为空字符串表示删除该标签。不会直接生效：生成变更预览并提交审批请求",
+		InputSchema: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"peer":{"type":"string","description":"Peer 名称"},
+				"labels":{"type":"object","additionalProperties":{"type":"string"},"description":"要设置的标签"}
+			},
+			"required":["peer","labels"]
+		}`),
+	},
+	{
+		Name:        "disable_peer",
+		Description: "禁用 Peer，使其断开网络。不会直接生效：生成变更预览并提交审批请求",
+		InputSchema: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"peer":{"type":"string","description":"Peer 名称"}
+			},
+			"required":["peer"]
+		}`),
+	},
+	{
+		Name:        "create_token",
+		Description: "创建 Peer 入网令牌。不会直接生效：提交审批请求，审批通过后令牌出现在令牌列表中",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{}}`),
+	},
+}
+
+func isWriteTool(name string) bool {
+	for _, t := range writeTools {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runTool executes one tool call within the daily tool-call quota of ws and
+// audits it. The returned text goes back to the model; errors are reported to
+// it rather than ending the chat.
+func (s *aiService) runTool(ctx context.Context, req *ChatRequest, ws *models.Workspace, tc llm.ToolCall, out StreamWriter) string {
+	var (
+		result     string
+		workflowID string
+		err        error
+	)
+	if err = s.reserve(ctx, ws, true); err == nil {
+		switch {
+		case !isWriteTool(tc.Name):
+			result, err = s.executeTool(ctx, ws.Namespace, tc.Name, tc.Input)
+		case !req.AllowWrites:
+			err = errors.New("write tools are not available to this user")
+		default:
+			var act *aiAction
+			if act, err = s.planAction(ctx, req, ws, tc.Name, tc.Input); err == nil {
+				result, workflowID, err = s.submitAction(ctx, req, ws, tc.Name, act, out)
+			}
+		}
+		s.recordToolCall(ws, tc.Name, err)
+	}
+	s.auditTool(req, ws, tc, workflowID, err)
+	if err != nil {
+		return fmt.Sprintf("error: %s", err.Error())
+	}
+	return result
+}
+
+func (s *aiService) planAction(ctx context.Context, req *ChatRequest, ws *models.Workspace, name string, input json.RawMessage) (*aiAction, error) {
+	switch name {
+	case "create_policy":
+		var args dto.PolicyDto
+		if err := json.Unmarshal(input, &args); err != nil {
+			return nil, fmt.Errorf("invalid input: %w", err)
+		}
+		var current *v1alpha1.LatticePolicy
+		if args.Name != "" {
+			var p v1alpha1.LatticePolicy
+			err := s.k8s.GetAPIReader().Get(ctx, client.ObjectKey{Namespace: ws.Namespace, Name: args.Name}, &p)
+			switch {
+			case err == nil:
+				current = &p
+			case !apierrors.IsNotFound(err):
+				return nil, err
+			}
+		}
+		return s.createPolicyAction(ctx, req, ws, current, &args)
+	case "label_peer", "disable_peer":
+		var args struct {
+			Peer   string            `json:"peer"`
+			Labels map[string]string `json:"labels"`
+		}
+		if err := json.Unmarshal(input, &args); err != nil {
+			return nil, fmt.Errorf("invalid input: %w", err)
+		}
+		var peer v1alpha1.LatticePeer
+		if err := s.k8s.GetAPIReader().Get(ctx, client.ObjectKey{Namespace: ws.Namespace, Name: args.Peer}, &peer); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("peer %q not found", args.Peer)
+			}
+			return nil, err
+		}
+		if name == "label_peer" {
+			return labelPeerAction(&peer, args.Labels)
+		}
+		return disablePeerAction(&peer)
+	case "create_token":
+		return createTokenAction(ws), nil
+	default:
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+}
+
+// submitAction files the workflow request for act and streams its preview.
+func (s *aiService) submitAction(ctx context.Context, req *ChatRequest, ws *models.Workspace, tool string, act *aiAction, out StreamWriter) (string, string, error) {
+	payload, err := act.payload(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal payload: %w", err)
+	}
+	wr, err := s.workflow.Submit(ctx, SubmitWorkflowReq{
+		WorkspaceID:      ws.ID,
+		RequestedBy:      req.UserID,
+		RequestedByName:  req.UserName,
+		RequestedByEmail: req.UserEmail,
+		ResourceType:     act.resourceType,
+		ResourceName:     act.resourceName,
+		Action:           act.action,
+		Payload:          string(raw),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("submit workflow request: %w", err)
+	}
+	_ = out.Write(StreamEvent{Type: "preview", Tool: tool, Content: act.diff, WorkflowID: wr.ID, Warnings: act.warnings})
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("已提交审批请求 %s（%s %s/%s），审批通过后才会执行。\n", wr.ID, act.action, act.resourceType, act.resourceName))
+	if act.note != "" {
+		sb.WriteString(act.note + "\n")
+	}
+	sb.WriteString("变更预览：\n" + act.diff)
+	if len(act.warnings) > 0 {
+		sb.WriteString("策略检查提示：\n")
+		for _, w := range act.warnings {
+			sb.WriteString(fmt.Sprintf("- [%s] %s: %s\n", w.Severity, w.Rule, w.Description))
+		}
+	}
+	return sb.String(), wr.ID, nil
+}
+
+// auditTool records a tool call, read or write, in the audit log.
+func (s *aiService) auditTool(req *ChatRequest, ws *models.Workspace, tc llm.ToolCall, workflowID string, toolErr error) {
+	if s.audit == nil {
+		return
+	}
+	detail := struct {
+		Input      any    `json:"input,omitempty"`
+		WorkflowID string `json:"workflowId,omitempty"`
+		Error      string `json:"error,omitempty"`
+	}{Input: tc.Input, WorkflowID: workflowID}
+	if !json.Valid(tc.Input) {
+		detail.Input = string(tc.Input)
+	}
+	entry := models.AuditLog{
+		UserID:       req.UserID,
+		UserName:     req.UserName,
+		UserEmail:    req.UserEmail,
+		WorkspaceID:  ws.ID,
+		Source:       models.AuditSourceAI,
+		Action:       "TOOL",
+		Resource:     "ai",
+		ResourceID:   workflowID,
+		ResourceName: tc.Name,
+		Scope:        "namespace:" + ws.Namespace,
+		Status:       "success",
+	}
+	if toolErr != nil {
+		entry.Status = "failed"
+		detail.Error = toolErr.Error()
+	}
+	raw, _ := json.Marshal(detail)
+	entry.Detail = string(raw)
+	s.audit.Log(entry)
+}
+
+// ── Actions ───────────────────────────────────────────────────────────────────
+
+// policyPreview is the part of a LatticePolicy a create_policy preview shows.
+type policyPreview struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description,omitempty"`
+	Spec        v1alpha1.LatticePolicySpec `json:"spec"`
+}
+
+// createPolicyAction saves p as a pending policy and submits it for approval
+// through the same "policy"/"create" executor the console uses. current is
+// the policy of that name already in the workspace, if any.
+func (s *aiService) createPolicyAction(ctx context.Context, req *ChatRequest, ws *models.Workspace, current *v1alpha1.LatticePolicy, p *dto.PolicyDto) (*aiAction, error) {
+	if errs := validation.IsDNS1123Subdomain(p.Name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid policy name %q: %s", p.Name, strings.Join(errs, "; "))
+	}
+	p.Action = strings.ToUpper(p.Action)
+	if p.Action != "ALLOW" && p.Action != "DENY" {
+		return nil, fmt.Errorf("action must be ALLOW or DENY, got %q", p.Action)
+	}
+	if p.Network == "" {
+		return nil, errors.New("network is required")
+	}
+
+	spec := p.LatticePolicySpec
+	spec.Action = p.Action
+	var from any
+	if current != nil {
+		from = policyPreview{Name: current.Name, Description: current.Annotations["description"], Spec: current.Spec}
+	}
+	to := policyPreview{Name: p.Name, Description: p.Description, Spec: spec}
+	if current != nil && sameObject(from, to) {
+		return nil, fmt.Errorf("policy %q already matches; nothing to submit", p.Name)
+	}
+
+	act := &aiAction{
+		resourceType: "policy",
+		resourceName: p.Name,
+		action:       "create",
+		diff:         labeledYAMLDiff("current", "proposed", from, to),
+		payload: func(ctx context.Context) (any, error) {
+			rec, err := s.policies.Submit(ctx, ws.ID, req.UserID, req.UserName, p)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]string{"policyId": rec.ID}, nil
+		},
+	}
+	if warnings, err := s.policies.Lint(ctx, ws.ID, p); err != nil {
+		s.logger.Warn("policy lint failed", "policy", p.Name, "err", err)
+	} else {
+		act.warnings = warnings
+	}
+	return act, nil
+}
+
+func labelPeerAction(peer *v1alpha1.LatticePeer, labels map[string]string) (*aiAction, error) {
+	if len(labels) == 0 {
+		return nil, errors.New("labels is required")
+	}
+	for k, v := range labels {
+		if strings.HasPrefix(k, reservedLabelPrefix) {
+			return nil, fmt.Errorf("label %q is managed by Lattice", k)
+		}
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid label key %q: %s", k, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid value for label %q: %s", k, strings.Join(errs, "; "))
+		}
+	}
+	after := mergeLabels(peer.Labels, labels)
+	if sameObject(peer.Labels, after) {
+		return nil, fmt.Errorf("peer %q already has these labels; nothing to submit", peer.Name)
+	}
+	payload := dto.PeerLabelsPayload{Namespace: peer.Namespace, Name: peer.Name, Labels: labels}
+	return &aiAction{
+		resourceType: "peer",
+		resourceName: peer.Name,
+		action:       "update",
+		diff: labeledYAMLDiff("current", "proposed",
+			map[string]any{"name": peer.Name, "labels": peer.Labels},
+			map[string]any{"name": peer.Name, "labels": after}),
+		payload: func(context.Context) (any, error) { return payload, nil },
+	}, nil
+}
+
+func disablePeerAction(peer *v1alpha1.LatticePeer) (*aiAction, error) {
+	if peer.Annotations[disabledAnnotation] == "true" {
+		return nil, fmt.Errorf("peer %q is already disabled", peer.Name)
+	}
+	after := make(map[string]string, len(peer.Annotations)+1)
+	for k, v := range peer.Annotations {
+		after[k] = v
+	}
+	after[disabledAnnotation] = "true"
+	payload := dto.PeerPayload{Namespace: peer.Namespace, Name: peer.Name}
+	return &aiAction{
+		resourceType: "peer",
+		resourceName: peer.Name,
+		action:       "disable",
+		diff: labeledYAMLDiff("current", "proposed",
+			map[string]any{"name": peer.Name, "annotations": peer.Annotations},
+			map[string]any{"name": peer.Name, "annotations": after}),
+		payload: func(context.Context) (any, error) { return payload, nil },
+	}, nil
+}
+
+func createTokenAction(ws *models.Workspace) *aiAction {
+	payload := dto.TokenPayload{WorkspaceID: ws.ID}
+	return &aiAction{
+		resourceType: "token",
+		resourceName: ws.Namespace,
+		action:       "create",
+		diff: labeledYAMLDiff("current", "proposed", nil, map[string]any{
+			"namespace": ws.Namespace,
+			"expiry":    defaultTokenExpiry,
+			"limit":     defaultTokenLimit,
+		}),
+		note:    "令牌在审批通过后生成，可在令牌列表中查看。",
+		payload: func(context.Context) (any, error) { return payload, nil },
+	}
+}
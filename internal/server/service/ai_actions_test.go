@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/llm"
+	"github.com/alatticeio/lattice/internal/server/models"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeWorkflow struct {
+	WorkflowService
+	submitted []SubmitWorkflowReq
+}
+
+func (f *fakeWorkflow) Submit(_ context.Context, req SubmitWorkflowReq) (*models.WorkflowRequest, error) {
+	f.submitted = append(f.submitted, req)
+	return &models.WorkflowRequest{ID: "wr-1", WorkspaceID: req.WorkspaceID}, nil
+}
+
+type fakeAudit struct {
+	AuditService
+	entries []models.AuditLog
+}
+
+func (f *fakeAudit) Log(entry models.AuditLog) { f.entries = append(f.entries, entry) }
+
+type eventRecorder struct{ events []StreamEvent }
+
+func (r *eventRecorder) Write(e StreamEvent) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+func actionPeer() *v1alpha1.LatticePeer {
+	return &v1alpha1.LatticePeer{ObjectMeta: metav1.ObjectMeta{
+		Name:      "web-1",
+		Namespace: "ns",
+		Labels:    map[string]string{"role": "web", "tier": "frontend"},
+	}}
+}
+
+func TestLabelPeerAction(t *testing.T) {
+	act, err := labelPeerAction(actionPeer(), map[string]string{"role": "db", "tier": ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"-  role: web", "-  tier: frontend", "+  role: db"} {
+		if !strings.Contains(act.diff, line) {
+			t.Errorf("diff lacks %q:\n%s", line, act.diff)
+		}
+	}
+	payload, _ := act.payload(context.Background())
+	p := payload.(dto.PeerLabelsPayload)
+	if act.resourceType != "peer" || act.action != "update" || p.Name != "web-1" || p.Namespace != "ns" || p.Labels["tier"] != "" {
+		t.Errorf("action = %+v, payload = %+v", act, p)
+	}
+}
+
+func TestLabelPeerAction_Rejects(t *testing.T) {
+	for name, labels := range map[string]map[string]string{
+		"empty":     nil,
+		"reserved":  {v1alpha1.NetworkLabelKey("net"): "true"},
+		"bad key":   {"not a key": "x"},
+		"bad value": {"role": "no spaces"},
+		"no change": {"role": "web"},
+	} {
+		if _, err := labelPeerAction(actionPeer(), labels); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDisablePeerAction(t *testing.T) {
+	peer := actionPeer()
+	act, err := disablePeerAction(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(act.diff, `+  lattice.io/disabled: "true"`) {
+		t.Errorf("diff:\n%s", act.diff)
+	}
+
+	peer.Annotations = map[string]string{disabledAnnotation: "true"}
+	if _, err := disablePeerAction(peer); err == nil {
+		t.Error("disabling a disabled peer should fail")
+	}
+}
+
+func TestRunTool_SubmitsAndAudits(t *testing.T) {
+	wf, audit := &fakeWorkflow{}, &fakeAudit{}
+	s, _ := newTestAIService(t, nil, wf, audit, config.AIConfig{})
+	ws := testAIWorkspace
+	req := &ChatRequest{WorkspaceID: "ws-1", UserID: "u-1", UserName: "alice", AllowWrites: true}
+	out := &eventRecorder{}
+
+	result := s.runTool(context.Background(), req, ws, llm.ToolCall{ID: "1", Name: "create_token", Input: json.RawMessage(`{}`)}, out)
+	if !strings.Contains(result, "wr-1") {
+		t.Errorf("result = %q", result)
+	}
+	if len(wf.submitted) != 1 {
+		t.Fatalf("submitted = %+v", wf.submitted)
+	}
+	sub := wf.submitted[0]
+	if sub.ResourceType != "token" || sub.Action != "create" || sub.RequestedBy != "u-1" || sub.Payload != `{"workspaceId":"ws-1"}` {
+		t.Errorf("submitted = %+v", sub)
+	}
+	if len(out.events) != 1 || out.events[0].Type != "preview" || out.events[0].WorkflowID != "wr-1" ||
+		!strings.Contains(out.events[0].Content, "+expiry: 168h") {
+		t.Errorf("events = %+v", out.events)
+	}
+	if len(audit.entries) != 1 {
+		t.Fatalf("audit = %+v", audit.entries)
+	}
+	e := audit.entries[0]
+	if e.Source != models.AuditSourceAI || e.ResourceName != "create_token" || e.ResourceID != "wr-1" || e.Status != "success" || e.UserID != "u-1" {
+		t.Errorf("audit entry = %+v", e)
+	}
+
+	// Without write access the call is refused, and still audited.
+	req.AllowWrites = false
+	result = s.runTool(context.Background(), req, ws, llm.ToolCall{ID: "2", Name: "create_token", Input: json.RawMessage(`{}`)}, out)
+	if !strings.HasPrefix(result, "error:") || len(wf.submitted) != 1 {
+		t.Errorf("result = %q, submitted = %d", result, len(wf.submitted))
+	}
+	if len(audit.entries) != 2 || audit.entries[1].Status != "failed" || !strings.Contains(audit.entries[1].Detail, "not available") {
+		t.Errorf("audit = %+v", audit.entries)
+	}
+}
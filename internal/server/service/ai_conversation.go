@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/llm"
+	"github.com/alatticeio/lattice/internal/server/models"
+	"github.com/alatticeio/lattice/internal/server/vo"
+)
+
+// AIConversationService reads stored AI assistant conversations and usage,
+// for users resuming their own chats and for admins reviewing transcripts.
+// It does not need an LLM.
+type AIConversationService interface {
+	List(ctx context.Context, filter store.AIConversationFilter) (*dto.PageResult[vo.AIConversationVo], error)
+	// Get returns a conversation with its messages.
+	Get(ctx context.Context, id string) (*vo.AIConversationVo, error)
+	// Export streams every conversation matching filter with its messages
+	// to w, one JSON object per line, oldest first. Paging fields of filter
+	// are ignored.
+	Export(ctx context.Context, filter store.AIConversationFilter, w io.Writer) error
+	// Usage returns the daily usage rows matching filter.
+	Usage(ctx context.Context, filter store.AIUsageFilter) ([]*models.AIUsage, error)
+}
+
+type aiConversationService struct {
+	store store.Store
+}
+
+func NewAIConversationService(st store.Store) AIConversationService {
+	return &aiConversationService{store: st}
+}
+
+func (s *aiConversationService) List(ctx context.Context, filter store.AIConversationFilter) (*dto.PageResult[vo.AIConversationVo], error) {
+	convs, total, err := s.store.AIConversations().List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	vos := make([]vo.AIConversationVo, 0, len(convs))
+	for _, c := range convs {
+		vos = append(vos, toAIConversationVo(c, nil))
+	}
+	return &dto.PageResult[vo.AIConversationVo]{
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		Total:    total,
+		List:     vos,
+	}, nil
+}
+
+func (s *aiConversationService) Get(ctx context.Context, id string) (*vo.AIConversationVo, error) {
+	conv, err := s.store.AIConversations().GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("conversation not found")
+	}
+	msgs, err := s.store.AIConversations().Messages(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	v := toAIConversationVo(conv, msgs)
+	return &v, nil
+}
+
+func (s *aiConversationService) Export(ctx context.Context, filter store.AIConversationFilter, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return s.store.AIConversations().Each(ctx, filter, func(c *models.AIConversation) error {
+		msgs, err := s.store.AIConversations().Messages(ctx, c.ID)
+		if err != nil {
+			return err
+		}
+		return enc.Encode(toAIConversationVo(c, msgs))
+	})
+}
+
+func (s *aiConversationService) Usage(ctx context.Context, filter store.AIUsageFilter) ([]*models.AIUsage, error) {
+	return s.store.AIConversations().ListUsage(ctx, filter)
+}
+
+func toAIConversationVo(c *models.AIConversation, msgs []*models.AIMessage) vo.AIConversationVo {
+	v := vo.AIConversationVo{
+		ID:           c.ID,
+		CreatedAt:    c.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:    c.UpdatedAt.UTC().Format(time.RFC3339),
+		WorkspaceID:  c.WorkspaceID,
+		UserID:       c.UserID,
+		UserName:     c.UserName,
+		Title:        c.Title,
+		MessageCount: c.MessageCount,
+		InputTokens:  c.InputTokens,
+		OutputTokens: c.OutputTokens,
+		ToolCalls:    c.ToolCalls,
+	}
+	for _, m := range msgs {
+		mv := vo.AIMessageVo{
+			CreatedAt:    m.CreatedAt.UTC().Format(time.RFC3339),
+			Role:         m.Role,
+			Content:      m.Content,
+			InputTokens:  m.InputTokens,
+			OutputTokens: m.OutputTokens,
+		}
+		if m.ToolCalls != "" {
+			mv.ToolCalls = json.RawMessage(m.ToolCalls)
+		}
+		if m.ToolResults != "" {
+			mv.ToolResults = json.RawMessage(m.ToolResults)
+		}
+		v.Messages = append(v.Messages, mv)
+	}
+	return v
+}
+
+// ── Chat persistence ──────────────────────────────────────────────────────────
+
+// conversationTitleLen is how many characters of the first message become
+// the title of a conversation.
+const conversationTitleLen = 60
+
+func conversationTitle(message string) string {
+	if utf8.RuneCountInString(message) <= conversationTitleLen {
+		return message
+	}
+	return string([]rune(message)[:conversationTitleLen]) + "…"
+}
+
+// transcript collects the messages of one chat turn for storage.
+type transcript struct {
+	msgs []*models.AIMessage
+}
+
+// add records m, with the usage of the completion that produced it.
+func (t *transcript) add(m llm.Message, usage llm.Usage) {
+	am := &models.AIMessage{
+		Role:          m.Role,
+		Content:       m.Content,
+		ToolCallCount: len(m.ToolCalls),
+		InputTokens:   usage.InputTokens,
+		OutputTokens:  usage.OutputTokens,
+	}
+	if len(m.ToolCalls) > 0 {
+		raw, _ := json.Marshal(m.ToolCalls)
+		am.ToolCalls = string(raw)
+	}
+	if len(m.ToolResults) > 0 {
+		raw, _ := json.Marshal(m.ToolResults)
+		am.ToolResults = string(raw)
+	}
+	t.msgs = append(t.msgs, am)
+}
+
+// llmMessages converts stored messages back into a conversation history.
+func llmMessages(stored []*models.AIMessage) []llm.Message {
+	out := make([]llm.Message, 0, len(stored))
+	for _, am := range stored {
+		m := llm.Message{Role: am.Role, Content: am.Content}
+		if am.ToolCalls != "" {
+			_ = json.Unmarshal([]byte(am.ToolCalls), &m.ToolCalls)
+		}
+		if am.ToolResults != "" {
+			_ = json.Unmarshal([]byte(am.ToolResults), &m.ToolResults)
+		}
+		out = append(out, m)
+	}
+	return out
+}
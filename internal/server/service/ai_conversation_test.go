@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/llm"
+	"github.com/alatticeio/lattice/internal/server/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var testAIWorkspace = &models.Workspace{Model: models.Model{ID: "ws-1"}, Slug: "ns", Namespace: "ns"}
+
+// scriptedLLM replies with its responses in order and records each request.
+type scriptedLLM struct {
+	responses []*llm.Response
+	requests  []*llm.Request
+}
+
+func (f *scriptedLLM) Complete(_ context.Context, req *llm.Request) (*llm.Response, error) {
+	f.requests = append(f.requests, req)
+	if len(f.responses) == 0 {
+		return nil, errors.New("no scripted response left")
+	}
+	resp := f.responses[0]
+	f.responses = f.responses[1:]
+	return resp, nil
+}
+
+func newTestAIService(t *testing.T, client llm.Client, wf WorkflowService, audit AuditService, cfg config.AIConfig) (*aiService, store.Store) {
+	t.Helper()
+	st, _ := newTestStore(t)
+	ws := *testAIWorkspace
+	if err := st.Workspaces().Create(context.Background(), &ws); err != nil {
+		t.Fatal(err)
+	}
+	return NewAIService(client, st, nil, nil, nil, nil, wf, audit, cfg).(*aiService), st
+}
+
+func tokenToolCall(id string) llm.ToolCall {
+	return llm.ToolCall{ID: id, Name: "create_token", Input: json.RawMessage(`{}`)}
+}
+
+func TestChat_PersistsAndResumes(t *testing.T) {
+	client := &scriptedLLM{responses: []*llm.Response{
+		{ToolCalls: []llm.ToolCall{tokenToolCall("1")}, Usage: llm.Usage{InputTokens: 100, OutputTokens: 10}},
+		{Content: "已提交审批", Usage: llm.Usage{InputTokens: 120, OutputTokens: 20}},
+		{Content: "还是那个申请", Usage: llm.Usage{InputTokens: 150, OutputTokens: 5}},
+	}}
+	s, st := newTestAIService(t, client, &fakeWorkflow{}, &fakeAudit{}, config.AIConfig{})
+	ctx := context.Background()
+
+	out := &eventRecorder{}
+	req := &ChatRequest{WorkspaceID: "ws-1", UserID: "u-1", UserName: "alice", Message: "帮我创建一个 token", AllowWrites: true}
+	if err := s.Chat(ctx, req, out); err != nil {
+		t.Fatal(err)
+	}
+	if out.events[0].Type != "conversation" || out.events[0].ConversationID == "" {
+		t.Fatalf("first event = %+v", out.events[0])
+	}
+	convID := out.events[0].ConversationID
+
+	conv, err := st.AIConversations().GetByID(ctx, convID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conv.Title != req.Message || conv.MessageCount != 4 || conv.InputTokens != 220 || conv.OutputTokens != 30 || conv.ToolCalls != 1 {
+		t.Errorf("conversation = %+v", conv)
+	}
+	usage, err := st.AIConversations().GetUsage(ctx, "ws-1", usageDay(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Requests != 2 || usage.InputTokens != 220 || usage.OutputTokens != 30 || usage.ToolCalls != 1 {
+		t.Errorf("usage = %+v", usage)
+	}
+
+	// Resuming sends the stored transcript back to the LLM.
+	out = &eventRecorder{}
+	if err := s.Chat(ctx, &ChatRequest{WorkspaceID: "ws-1", UserID: "u-1", Message: "刚才是哪个申请？", ConversationID: convID}, out); err != nil {
+		t.Fatal(err)
+	}
+	msgs := client.requests[2].Messages
+	if len(msgs) != 5 || msgs[1].ToolCalls[0].Name != "create_token" || msgs[2].ToolResults[0].ToolCallID != "1" || msgs[4].Content != "刚才是哪个申请？" {
+		t.Errorf("resumed messages = %+v", msgs)
+	}
+	if conv, _ = st.AIConversations().GetByID(ctx, convID); conv.MessageCount != 6 {
+		t.Errorf("message count = %d", conv.MessageCount)
+	}
+
+	// Someone else's conversation cannot be resumed.
+	out = &eventRecorder{}
+	if err := s.Chat(ctx, &ChatRequest{WorkspaceID: "ws-1", UserID: "u-2", Message: "hi", ConversationID: convID}, out); err == nil {
+		t.Error("expected an error resuming another user's conversation")
+	}
+}
+
+func TestChat_TokenQuota(t *testing.T) {
+	client := &scriptedLLM{responses: []*llm.Response{
+		{Content: "ok", Usage: llm.Usage{InputTokens: 80, OutputTokens: 30}},
+	}}
+	s, _ := newTestAIService(t, client, &fakeWorkflow{}, &fakeAudit{}, config.AIConfig{
+		DailyTokenQuota: 1000,
+		WorkspaceQuotas: map[string]config.AIQuota{"ns": {Tokens: 100}},
+	})
+	ctx := context.Background()
+
+	if err := s.Chat(ctx, &ChatRequest{WorkspaceID: "ws-1", UserID: "u-1", Message: "hi"}, &eventRecorder{}); err != nil {
+		t.Fatal(err)
+	}
+	out := &eventRecorder{}
+	err := s.Chat(ctx, &ChatRequest{WorkspaceID: "ws-1", UserID: "u-1", Message: "again"}, out)
+	if !errors.Is(err, errQuotaExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+	if last := out.events[len(out.events)-1]; last.Type != "error" || !strings.Contains(last.Error, "110 of 100 tokens") {
+		t.Errorf("last event = %+v", last)
+	}
+	if len(client.requests) != 1 {
+		t.Errorf("LLM called %d times", len(client.requests))
+	}
+}
+
+func TestChat_ToolCallQuota(t *testing.T) {
+	client := &scriptedLLM{responses: []*llm.Response{
+		{ToolCalls: []llm.ToolCall{tokenToolCall("1"), tokenToolCall("2")}},
+		{Content: "done"},
+	}}
+	wf := &fakeWorkflow{}
+	s, _ := newTestAIService(t, client, wf, &fakeAudit{}, config.AIConfig{DailyToolCallQuota: 1})
+
+	req := &ChatRequest{WorkspaceID: "ws-1", UserID: "u-1", Message: "两个 token", AllowWrites: true}
+	if err := s.Chat(context.Background(), req, &eventRecorder{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(wf.submitted) != 1 {
+		t.Errorf("submitted = %d", len(wf.submitted))
+	}
+	results := client.requests[1].Messages[2].ToolResults
+	if len(results) != 2 || !strings.Contains(results[1].Content, errQuotaExceeded.Error()) {
+		t.Errorf("tool results = %+v", results)
+	}
+}
+
+func TestReserve_ConcurrentToolCalls(t *testing.T) {
+	s, _ := newTestAIService(t, nil, nil, nil, config.AIConfig{DailyToolCallQuota: 3})
+
+	var wg sync.WaitGroup
+	var admitted atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s.reserve(context.Background(), testAIWorkspace, true) == nil {
+				admitted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if admitted.Load() != 3 {
+		t.Errorf("admitted %d tool calls, want 3", admitted.Load())
+	}
+}
+
+func TestRecordToolCall_UnknownTool(t *testing.T) {
+	s, _ := newTestAIService(t, nil, nil, nil, config.AIConfig{})
+	ws := &models.Workspace{Namespace: "metrics-ns"}
+	unknown := aiToolCalls.WithLabelValues("metrics-ns", "unknown", "success")
+	listPeers := aiToolCalls.WithLabelValues("metrics-ns", "list_peers", "success")
+	unknownBefore, listPeersBefore := testutil.ToFloat64(unknown), testutil.ToFloat64(listPeers)
+
+	s.recordToolCall(ws, "made_up_tool", nil)
+	s.recordToolCall(ws, "list_peers", nil)
+	if got := testutil.ToFloat64(unknown) - unknownBefore; got != 1 {
+		t.Errorf("unknown = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(listPeers) - listPeersBefore; got != 1 {
+		t.Errorf("list_peers = %v, want 1", got)
+	}
+}
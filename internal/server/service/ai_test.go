@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/server/llm"
+	"github.com/alatticeio/lattice/internal/server/resource"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// readerManager serves GetAPIReader from a fake client; the rest of the
+// manager is unused by the AI service.
+type readerManager struct {
+	manager.Manager
+	reader client.Reader
+}
+
+func (m readerManager) GetAPIReader() client.Reader { return m.reader }
+
+func fakeK8s(t *testing.T, objs ...client.Object) *resource.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	return &resource.Client{Client: c, Manager: readerManager{reader: c}}
+}
+
+// fixtureClient loads a fixture the way the fixture provider does.
+func fixtureClient(t *testing.T, fixture string) llm.Client {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.yaml")
+	if err := os.WriteFile(path, []byte(fixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := llm.NewClient(config.AIConfig{Provider: "fixture", Fixture: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+const aiTestFixture = `
+turns:
+  - match: 安全扫描
+    responses:
+      - content: |
+          ` + "```json" + `
+          [{"rule":"allow-all-detected","description":"策略放行所有流量","suggestion":"收紧 peerSelector"}]
+          ` + "```" + `
+  - match: Peer
+    responses:
+      - tool_calls: [{name: list_peers, input: {}}]
+        usage: {input_tokens: 50, output_tokens: 5}
+      - content: 工作区有 1 个 Peer：web-1。
+`
+
+func TestChat_FixtureReplay(t *testing.T) {
+	s, st := newTestAIService(t, fixtureClient(t, aiTestFixture), &fakeWorkflow{}, &fakeAudit{}, config.AIConfig{})
+	s.k8s = fakeK8s(t, &v1alpha1.LatticePeer{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "ns"}})
+	ctx := context.Background()
+
+	out := &eventRecorder{}
+	if err := s.Chat(ctx, &ChatRequest{WorkspaceID: "ws-1", UserID: "u-1", Message: "有哪些 Peer？"}, out); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, e := range out.events {
+		types = append(types, e.Type)
+	}
+	if strings.Join(types, ",") != "conversation,tool_use,token,done" || out.events[2].Content != "工作区有 1 个 Peer：web-1。" {
+		t.Fatalf("events = %+v", out.events)
+	}
+
+	msgs, err := st.AIConversations().Messages(ctx, out.events[0].ConversationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 4 || !strings.Contains(msgs[2].ToolResults, "web-1") || msgs[1].InputTokens != 50 {
+		t.Errorf("messages = %+v", msgs)
+	}
+}
+
+func TestAudit_EnrichesFindings(t *testing.T) {
+	s, _ := newTestAIService(t, fixtureClient(t, aiTestFixture), nil, nil, config.AIConfig{})
+	s.k8s = fakeK8s(t, &v1alpha1.LatticePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "open", Namespace: "ns"},
+		Spec:       v1alpha1.LatticePolicySpec{Action: "ALLOW"},
+	})
+
+	report, err := s.Audit(context.Background(), "ws-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Findings) != 1 {
+		t.Fatalf("findings = %+v", report.Findings)
+	}
+	f := report.Findings[0]
+	if f.Rule != "allow-all-detected" || f.Description != "策略放行所有流量" || f.Suggestion != "收紧 peerSelector" || report.Score != 85 {
+		t.Errorf("report = %+v", report)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/server/llm"
+	"github.com/alatticeio/lattice/internal/server/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// AI usage metrics, labelled with the workspace namespace so that LLM cost
+// can be attributed per team. They are registered on the default registry
+// the manager serves at /metrics.
+var (
+	aiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lattice",
+		Subsystem: "ai",
+		Name:      "requests_total",
+		Help:      "LLM completions requested by the AI assistant.",
+	}, []string{"workspace", "status"})
+
+	aiTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lattice",
+		Subsystem: "ai",
+		Name:      "tokens_total",
+		Help:      "LLM tokens consumed by the AI assistant.",
+	}, []string{"workspace", "direction"})
+
+	aiToolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lattice",
+		Subsystem: "ai",
+		Name:      "tool_calls_total",
+		Help:      "Tool calls executed by the AI assistant.",
+	}, []string{"workspace", "tool", "status"})
+
+	aiQuotaRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "lattice",
+		Subsystem: "ai",
+		Name:      "quota_rejections_total",
+		Help:      "LLM completions and tool calls refused because a daily quota was used up.",
+	}, []string{"workspace", "quota"})
+)
+
+func init() {
+	prometheus.MustRegister(aiRequests, aiTokens, aiToolCalls, aiQuotaRejections)
+}
+
+// errQuotaExceeded is wrapped by the error returned when a workspace has used
+// up a daily AI quota.
+var errQuotaExceeded = errors.New("daily AI quota exceeded")
+
+// usageDay is the key of the daily usage row t counts towards.
+func usageDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// quota returns the daily limits of ws: its ai.workspace-quotas entry, or
+// else the defaults.
+func (s *aiService) quota(ws *models.Workspace) config.AIQuota {
+	if q, ok := s.cfg.WorkspaceQuotas[ws.Namespace]; ok {
+		return q
+	}
+	return config.AIQuota{Tokens: s.cfg.DailyTokenQuota, ToolCalls: s.cfg.DailyToolCallQuota}
+}
+
+// reserve counts one LLM request, or with toolCall set one tool call,
+// towards today's usage of ws. It returns an error wrapping errQuotaExceeded,
+// and counts nothing, when ws has used up the matching quota. The check and
+// the count are one atomic update, so concurrent chats cannot overrun the
+// tool-call quota. Tokens are only known once a request completes, so
+// requests admitted together can overrun the token quota by their own use.
+func (s *aiService) reserve(ctx context.Context, ws *models.Workspace, toolCall bool) error {
+	q := s.quota(ws)
+	u := &models.AIUsage{WorkspaceID: ws.ID, Day: usageDay(time.Now())}
+	var maxTokens, maxToolCalls int64
+	limit, name := q.Tokens, "tokens"
+	if toolCall {
+		u.ToolCalls, maxToolCalls = 1, q.ToolCalls
+		limit, name = q.ToolCalls, "tool_calls"
+	} else {
+		u.Requests, maxTokens = 1, q.Tokens
+	}
+	ok, err := s.store.AIConversations().ReserveUsage(ctx, u, maxTokens, maxToolCalls)
+	if err != nil {
+		return fmt.Errorf("record AI usage: %w", err)
+	}
+	if ok {
+		return nil
+	}
+
+	aiQuotaRejections.WithLabelValues(ws.Namespace, name).Inc()
+	used := limit
+	if cur, err := s.store.AIConversations().GetUsage(ctx, ws.ID, u.Day); err == nil {
+		used = cur.InputTokens + cur.OutputTokens
+		if toolCall {
+			used = cur.ToolCalls
+		}
+	}
+	return fmt.Errorf("%w: workspace %s has used %d of %d %s today (UTC)", errQuotaExceeded, ws.Namespace, used, limit, name)
+}
+
+// recordCompletion adds the tokens of one completion, whose request reserve
+// already counted, to the usage of ws.
+func (s *aiService) recordCompletion(ctx context.Context, ws *models.Workspace, usage llm.Usage) {
+	aiRequests.WithLabelValues(ws.Namespace, "ok").Inc()
+	aiTokens.WithLabelValues(ws.Namespace, "input").Add(float64(usage.InputTokens))
+	aiTokens.WithLabelValues(ws.Namespace, "output").Add(float64(usage.OutputTokens))
+	u := &models.AIUsage{
+		WorkspaceID:  ws.ID,
+		Day:          usageDay(time.Now()),
+		InputTokens:  int64(usage.InputTokens),
+		OutputTokens: int64(usage.OutputTokens),
+	}
+	if err := s.store.AIConversations().AddUsage(ctx, u); err != nil {
+		s.logger.Warn("failed to record AI usage", "workspace", u.WorkspaceID, "err", err)
+	}
+}
+
+// recordToolCall counts an executed tool call in the metrics. Tools the
+// model made up are counted as "unknown" so that the label stays bounded.
+func (s *aiService) recordToolCall(ws *models.Workspace, tool string, toolErr error) {
+	status := "success"
+	if toolErr != nil {
+		status = "failed"
+	}
+	if !s.isTool(tool) {
+		tool = "unknown"
+	}
+	aiToolCalls.WithLabelValues(ws.Namespace, tool, status).Inc()
+}
+
+// isTool reports whether name is a tool the assistant offers.
+func (s *aiService) isTool(name string) bool {
+	if isWriteTool(name) {
+		return true
+	}
+	for _, t := range s.buildTools("") {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
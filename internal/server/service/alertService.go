@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/monitor/template"
 	"github.com/alatticeio/lattice/internal/server/models"
 	"github.com/google/uuid"
 )
@@ -40,6 +42,9 @@ type CreateAlertRuleRequest struct {
 
 // CreateRule creates a new alert rule.
 func (s *AlertService) CreateRule(ctx context.Context, wsID string, req CreateAlertRuleRequest) (*models.AlertRule, error) {
+	if err := s.checkMetric(ctx, wsID, req.MetricType); err != nil {
+		return nil, err
+	}
 	groupBy, _ := json.Marshal(req.GroupBy)
 	channels, _ := json.Marshal(req.Channels)
 
@@ -82,6 +87,9 @@ func (s *AlertService) UpdateRule(ctx context.Context, id string, req CreateAler
 	if err != nil {
 		return nil, err
 	}
+	if err := s.checkMetric(ctx, rule.WorkspaceID, req.MetricType); err != nil {
+		return nil, err
+	}
 	groupBy, _ := json.Marshal(req.GroupBy)
 	channels, _ := json.Marshal(req.Channels)
 
@@ -103,6 +111,18 @@ func (s *AlertService) UpdateRule(ctx context.Context, id string, req CreateAler
 	return rule, nil
 }
 
+// checkMetric rejects rules on custom metrics of other workspaces.
+func (s *AlertService) checkMetric(ctx context.Context, wsID, metricType string) error {
+	if !template.IsCustomMetricType(metricType) {
+		return nil
+	}
+	m, err := s.store.CustomMetrics().GetByID(ctx, strings.TrimPrefix(metricType, template.CustomPrefix))
+	if err != nil || m.WorkspaceID != wsID {
+		return fmt.Errorf("custom metric not found: %s", metricType)
+	}
+	return nil
+}
+
 // DeleteRule deletes an alert rule by ID.
 func (s *AlertService) DeleteRule(ctx context.Context, id string) error {
 	return s.store.Alerts().DeleteAlertRule(ctx, id)
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/log"
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/auth"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/models"
+	"github.com/alatticeio/lattice/internal/server/vo"
+)
+
+const (
+	// apiTokenPrefixLen is how much of a token is kept in clear, including
+	// auth.APITokenPrefix, to tell tokens apart in listings.
+	apiTokenPrefixLen = 12
+
+	// apiTokenTouchInterval throttles last-used updates, so a busy CI job
+	// does not write on every request.
+	apiTokenTouchInterval = time.Minute
+
+	// serviceAccountUserPrefix marks a service account in audit entries and
+	// other places that show a user name.
+	serviceAccountUserPrefix = "sa:"
+)
+
+var (
+	ErrAPITokenInvalid = errors.New("invalid api token")
+	ErrAPITokenExpired = errors.New("api token has expired")
+	ErrAPITokenRevoked = errors.New("api token has been revoked")
+)
+
+// APITokenService manages personal access tokens, workspace service accounts
+// and their tokens, and authenticates requests made with them.
+type APITokenService interface {
+	auth.APITokenAuthenticator
+
+	CreatePersonal(ctx context.Context, userID string, req *dto.APITokenDto) (*vo.APITokenCreatedVo, error)
+	ListPersonal(ctx context.Context, userID string) ([]*vo.APITokenVo, error)
+	RevokePersonal(ctx context.Context, userID, tokenID string) error
+
+	CreateServiceAccount(ctx context.Context, wsID, createdBy string, req *dto.ServiceAccountDto) (*vo.ServiceAccountVo, error)
+	ListServiceAccounts(ctx context.Context, wsID string) ([]*vo.ServiceAccountVo, error)
+	// DeleteServiceAccount deletes the account and revokes all its tokens.
+	DeleteServiceAccount(ctx context.Context, wsID, saID string) error
+
+	CreateServiceAccountToken(ctx context.Context, wsID, saID, createdBy string, req *dto.APITokenDto) (*vo.APITokenCreatedVo, error)
+	ListServiceAccountTokens(ctx context.Context, wsID, saID string) ([]*vo.APITokenVo, error)
+	RevokeServiceAccountToken(ctx context.Context, wsID, saID, tokenID string) error
+}
+
+type apiTokenService struct {
+	store  store.Store
+	cfg    config.APITokenConfig
+	logger *log.Logger
+}
+
+func NewAPITokenService(st store.Store, cfg config.APITokenConfig) APITokenService {
+	return &apiTokenService{store: st, cfg: cfg, logger: log.GetLogger("api-token")}
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newAPIToken returns a fresh secret with its prefix and stored hash.
+func newAPIToken() (secret, prefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	secret = auth.APITokenPrefix + hex.EncodeToString(b)
+	return secret, secret[:apiTokenPrefixLen], hashAPIToken(secret), nil
+}
+
+// expiry resolves a requested lifetime against the configured default and
+// maximum. A nil result never expires.
+func (s *apiTokenService) expiry(days int, now time.Time) (*time.Time, error) {
+	switch {
+	case days == 0:
+		days = s.cfg.DefaultTTLDays
+	case days < -1:
+		return nil, errors.New("expiresInDays must be positive, 0 for the default or -1 for no expiry")
+	}
+	if s.cfg.MaxTTLDays > 0 {
+		if days <= 0 {
+			days = s.cfg.MaxTTLDays
+		} else if days > s.cfg.MaxTTLDays {
+			return nil, fmt.Errorf("tokens may be valid for at most %d days", s.cfg.MaxTTLDays)
+		}
+	}
+	if days <= 0 {
+		return nil, nil
+	}
+	t := now.AddDate(0, 0, days)
+	return &t, nil
+}
+
+func validRole(role dto.WorkspaceRole) bool {
+	return dto.GetRoleWeight(role) > 0
+}
+
+func (s *apiTokenService) issue(ctx context.Context, t *models.APIToken, days int) (*vo.APITokenCreatedVo, error) {
+	exp, err := s.expiry(days, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	secret, prefix, hash, err := newAPIToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate api token: %w", err)
+	}
+	t.Prefix, t.Hash, t.ExpiresAt = prefix, hash, exp
+	if err := s.store.APITokens().Create(ctx, t); err != nil {
+		return nil, fmt.Errorf("create api token: %w", err)
+	}
+	return &vo.APITokenCreatedVo{APITokenVo: *toAPITokenVo(t, time.Now()), Token: secret}, nil
+}
+
+func (s *apiTokenService) CreatePersonal(ctx context.Context, userID string, req *dto.APITokenDto) (*vo.APITokenCreatedVo, error) {
+	user, err := s.store.Users().GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	isAdmin := user.SystemRole == dto.SystemRolePlatformAdmin
+	if req.Role != "" && !validRole(req.Role) {
+		return nil, fmt.Errorf("invalid role %q", req.Role)
+	}
+	if req.PlatformAdmin && !isAdmin {
+		return nil, errors.New("only platform admins can create platform admin tokens")
+	}
+	if req.WorkspaceID != "" && !isAdmin {
+		if _, err := s.store.WorkspaceMembers().GetMembership(ctx, req.WorkspaceID, userID); err != nil {
+			return nil, errors.New("you are not a member of this workspace")
+		}
+	}
+	return s.issue(ctx, &models.APIToken{
+		Name:          req.Name,
+		UserID:        userID,
+		WorkspaceID:   req.WorkspaceID,
+		Role:          req.Role,
+		PlatformAdmin: req.PlatformAdmin,
+		CreatedBy:     user.Username,
+	}, req.ExpiresInDays)
+}
+
+func (s *apiTokenService) ListPersonal(ctx context.Context, userID string) ([]*vo.APITokenVo, error) {
+	tokens, err := s.store.APITokens().ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toAPITokenVos(tokens), nil
+}
+
+func (s *apiTokenService) RevokePersonal(ctx context.Context, userID, tokenID string) error {
+	t, err := s.store.APITokens().GetByID(ctx, tokenID)
+	if err != nil || t.UserID != userID {
+		return errors.New("token not found")
+	}
+	return s.store.APITokens().Revoke(ctx, tokenID, time.Now())
+}
+
+func (s *apiTokenService) CreateServiceAccount(ctx context.Context, wsID, createdBy string, req *dto.ServiceAccountDto) (*vo.ServiceAccountVo, error) {
+	if !validRole(req.Role) {
+		return nil, fmt.Errorf("invalid role %q", req.Role)
+	}
+	existing, err := s.store.ServiceAccounts().ListByWorkspace(ctx, wsID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sa := range existing {
+		if sa.Name == req.Name {
+			return nil, fmt.Errorf("service account %q already exists", req.Name)
+		}
+	}
+	sa := &models.ServiceAccount{
+		WorkspaceID: wsID,
+		Name:        req.Name,
+		Description: req.Description,
+		Role:        req.Role,
+		CreatedBy:   createdBy,
+	}
+	if err := s.store.ServiceAccounts().Create(ctx, sa); err != nil {
+		return nil, fmt.Errorf("create service account: %w", err)
+	}
+	return toServiceAccountVo(sa, 0), nil
+}
+
+func (s *apiTokenService) ListServiceAccounts(ctx context.Context, wsID string) ([]*vo.ServiceAccountVo, error) {
+	accounts, err := s.store.ServiceAccounts().ListByWorkspace(ctx, wsID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	out := make([]*vo.ServiceAccountVo, 0, len(accounts))
+	for _, sa := range accounts {
+		tokens, err := s.store.APITokens().ListByServiceAccount(ctx, sa.ID)
+		if err != nil {
+			return nil, err
+		}
+		active := 0
+		for _, t := range tokens {
+			if apiTokenStatus(t, now) == "active" {
+				active++
+			}
+		}
+		out = append(out, toServiceAccountVo(sa, active))
+	}
+	return out, nil
+}
+
+// serviceAccount loads saID and checks it belongs to wsID.
+func (s *apiTokenService) serviceAccount(ctx context.Context, wsID, saID string) (*models.ServiceAccount, error) {
+	sa, err := s.store.ServiceAccounts().GetByID(ctx, saID)
+	if err != nil || sa.WorkspaceID != wsID {
+		return nil, errors.New("service account not found")
+	}
+	return sa, nil
+}
+
+func (s *apiTokenService) DeleteServiceAccount(ctx context.Context, wsID, saID string) error {
+	if _, err := s.serviceAccount(ctx, wsID, saID); err != nil {
+		return err
+	}
+	return s.store.Tx(ctx, func(tx store.Store) error {
+		if err := tx.APITokens().RevokeByServiceAccount(ctx, saID, time.Now()); err != nil {
+			return err
+		}
+		return tx.ServiceAccounts().Delete(ctx, saID)
+	})
+}
+
+func (s *apiTokenService) CreateServiceAccountToken(ctx context.Context, wsID, saID, createdBy string, req *dto.APITokenDto) (*vo.APITokenCreatedVo, error) {
+	sa, err := s.serviceAccount(ctx, wsID, saID)
+	if err != nil {
+		return nil, err
+	}
+	if req.PlatformAdmin {
+		return nil, errors.New("service account tokens cannot have platform admin rights")
+	}
+	if req.WorkspaceID != "" && req.WorkspaceID != wsID {
+		return nil, errors.New("service account tokens are limited to the account's workspace")
+	}
+	role := req.Role
+	if role == "" {
+		role = sa.Role
+	} else if !validRole(role) {
+		return nil, fmt.Errorf("invalid role %q", role)
+	} else if dto.GetRoleWeight(role) > dto.GetRoleWeight(sa.Role) {
+		return nil, errors.New("token role cannot exceed the service account's role")
+	}
+	return s.issue(ctx, &models.APIToken{
+		Name:             req.Name,
+		ServiceAccountID: sa.ID,
+		WorkspaceID:      wsID,
+		Role:             role,
+		CreatedBy:        createdBy,
+	}, req.ExpiresInDays)
+}
+
+func (s *apiTokenService) ListServiceAccountTokens(ctx context.Context, wsID, saID string) ([]*vo.APITokenVo, error) {
+	if _, err := s.serviceAccount(ctx, wsID, saID); err != nil {
+		return nil, err
+	}
+	tokens, err := s.store.APITokens().ListByServiceAccount(ctx, saID)
+	if err != nil {
+		return nil, err
+	}
+	return toAPITokenVos(tokens), nil
+}
+
+func (s *apiTokenService) RevokeServiceAccountToken(ctx context.Context, wsID, saID, tokenID string) error {
+	if _, err := s.serviceAccount(ctx, wsID, saID); err != nil {
+		return err
+	}
+	t, err := s.store.APITokens().GetByID(ctx, tokenID)
+	if err != nil || t.ServiceAccountID != saID {
+		return errors.New("token not found")
+	}
+	return s.store.APITokens().Revoke(ctx, tokenID, time.Now())
+}
+
+func (s *apiTokenService) AuthenticateAPIToken(ctx context.Context, token, clientIP string) (*auth.APIIdentity, error) {
+	if !auth.IsAPIToken(token) {
+		return nil, ErrAPITokenInvalid
+	}
+	t, err := s.store.APITokens().GetByHash(ctx, hashAPIToken(token))
+	if err != nil {
+		return nil, ErrAPITokenInvalid
+	}
+	now := time.Now()
+	switch apiTokenStatus(t, now) {
+	case "revoked":
+		return nil, ErrAPITokenRevoked
+	case "expired":
+		return nil, ErrAPITokenExpired
+	}
+
+	ident := &auth.APIIdentity{
+		TokenID:     t.ID,
+		WorkspaceID: t.WorkspaceID,
+		Role:        t.Role,
+		SystemRole:  string(dto.SystemRoleUser),
+	}
+	if t.ExpiresAt != nil {
+		ident.ExpiresAt = *t.ExpiresAt
+	}
+	if t.ServiceAccountID != "" {
+		sa, err := s.store.ServiceAccounts().GetByID(ctx, t.ServiceAccountID)
+		if err != nil {
+			return nil, ErrAPITokenInvalid
+		}
+		ident.UserID = sa.ID
+		ident.Username = serviceAccountUserPrefix + sa.Name
+		ident.ServiceAccount = true
+		ident.WorkspaceID = sa.WorkspaceID
+		// The account's role may have been lowered since the token was made.
+		if ident.Role == "" || dto.GetRoleWeight(sa.Role) < dto.GetRoleWeight(ident.Role) {
+			ident.Role = sa.Role
+		}
+	} else {
+		user, err := s.store.Users().GetByID(ctx, t.UserID)
+		if err != nil {
+			return nil, ErrAPITokenInvalid
+		}
+		ident.UserID = user.ID
+		ident.Username = user.Username
+		ident.Email = user.Email
+		if t.PlatformAdmin && user.SystemRole == dto.SystemRolePlatformAdmin {
+			ident.SystemRole = string(dto.SystemRolePlatformAdmin)
+		}
+	}
+
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) >= apiTokenTouchInterval || t.LastUsedIP != clientIP {
+		if err := s.store.APITokens().TouchLastUsed(ctx, t.ID, now, clientIP); err != nil {
+			s.logger.Warn("failed to record api token use", "token", t.ID, "err", err)
+		}
+	}
+	return ident, nil
+}
+
+func apiTokenStatus(t *models.APIToken, now time.Time) string {
+	switch {
+	case t.RevokedAt != nil:
+		return "revoked"
+	case t.ExpiresAt != nil && !now.Before(*t.ExpiresAt):
+		return "expired"
+	}
+	return "active"
+}
+
+func toAPITokenVo(t *models.APIToken, now time.Time) *vo.APITokenVo {
+	return &vo.APITokenVo{
+		ID:               t.ID,
+		Name:             t.Name,
+		Prefix:           t.Prefix,
+		ServiceAccountID: t.ServiceAccountID,
+		WorkspaceID:      t.WorkspaceID,
+		Role:             string(t.Role),
+		PlatformAdmin:    t.PlatformAdmin,
+		ExpiresAt:        t.ExpiresAt,
+		LastUsedAt:       t.LastUsedAt,
+		LastUsedIP:       t.LastUsedIP,
+		RevokedAt:        t.RevokedAt,
+		Status:           apiTokenStatus(t, now),
+		CreatedAt:        t.CreatedAt,
+	}
+}
+
+func toAPITokenVos(tokens []*models.APIToken) []*vo.APITokenVo {
+	now := time.Now()
+	out := make([]*vo.APITokenVo, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, toAPITokenVo(t, now))
+	}
+	return out
+}
+
+func toServiceAccountVo(sa *models.ServiceAccount, activeTokens int) *vo.ServiceAccountVo {
+	return &vo.ServiceAccountVo{
+		ID:          sa.ID,
+		Name:        sa.Name,
+		Description: sa.Description,
+		Role:        string(sa.Role),
+		CreatedBy:   sa.CreatedBy,
+		Tokens:      activeTokens,
+		CreatedAt:   sa.CreatedAt,
+	}
+}
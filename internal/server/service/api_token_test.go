@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/models"
+)
+
+func newTestAPITokenService(t *testing.T, cfg config.APITokenConfig) (*apiTokenService, store.Store) {
+	t.Helper()
+	st, _ := newTestStore(t)
+	return NewAPITokenService(st, cfg).(*apiTokenService), st
+}
+
+func TestAPIToken_PersonalLifecycle(t *testing.T) {
+	ctx := context.Background()
+	s, st := newTestAPITokenService(t, config.APITokenConfig{DefaultTTLDays: 90})
+
+	admin := &models.User{Model: models.Model{ID: "u-1"}, Username: "alice", SystemRole: dto.SystemRolePlatformAdmin}
+	if err := st.Users().Create(ctx, admin); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := s.CreatePersonal(ctx, admin.ID, &dto.APITokenDto{Name: "ci", Role: dto.RoleViewer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ExpiresAt == nil || created.ExpiresAt.Sub(time.Now()) < 89*24*time.Hour {
+		t.Errorf("default expiry not applied: %v", created.ExpiresAt)
+	}
+	if created.Prefix != created.Token[:apiTokenPrefixLen] {
+		t.Errorf("prefix %q does not match token", created.Prefix)
+	}
+
+	ident, err := s.AuthenticateAPIToken(ctx, created.Token, "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Platform admin rights are only kept when asked for.
+	if ident.UserID != admin.ID || ident.SystemRole != string(dto.SystemRoleUser) || ident.Role != dto.RoleViewer {
+		t.Errorf("unexpected identity %+v", ident)
+	}
+	stored, _ := st.APITokens().GetByID(ctx, created.ID)
+	if stored.LastUsedAt == nil || stored.LastUsedIP != "10.0.0.1" {
+		t.Errorf("last use not recorded: %+v", stored)
+	}
+	if stored.Hash == created.Token {
+		t.Error("token stored in clear")
+	}
+
+	if _, err := s.AuthenticateAPIToken(ctx, created.Token+"x", ""); !errors.Is(err, ErrAPITokenInvalid) {
+		t.Errorf("wrong token: got %v", err)
+	}
+
+	if err := s.RevokePersonal(ctx, "someone-else", created.ID); err == nil {
+		t.Error("revoked another user's token")
+	}
+	if err := s.RevokePersonal(ctx, admin.ID, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AuthenticateAPIToken(ctx, created.Token, ""); !errors.Is(err, ErrAPITokenRevoked) {
+		t.Errorf("revoked token: got %v", err)
+	}
+
+	adminTok, err := s.CreatePersonal(ctx, admin.ID, &dto.APITokenDto{Name: "ops", PlatformAdmin: true, ExpiresInDays: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adminTok.ExpiresAt != nil {
+		t.Errorf("-1 should never expire, got %v", adminTok.ExpiresAt)
+	}
+	ident, err = s.AuthenticateAPIToken(ctx, adminTok.Token, "")
+	if err != nil || ident.SystemRole != string(dto.SystemRolePlatformAdmin) {
+		t.Errorf("platform admin token: %+v, %v", ident, err)
+	}
+
+	// Demoted users lose the platform admin rights of their tokens.
+	admin.SystemRole = dto.SystemRoleUser
+	if err := st.Users().Update(ctx, admin); err != nil {
+		t.Fatal(err)
+	}
+	ident, err = s.AuthenticateAPIToken(ctx, adminTok.Token, "")
+	if err != nil || ident.SystemRole != string(dto.SystemRoleUser) {
+		t.Errorf("demoted owner: %+v, %v", ident, err)
+	}
+	if _, err := s.CreatePersonal(ctx, admin.ID, &dto.APITokenDto{Name: "x", PlatformAdmin: true}); err == nil {
+		t.Error("non-admin created a platform admin token")
+	}
+}
+
+func TestAPIToken_MaxTTL(t *testing.T) {
+	s, _ := newTestAPITokenService(t, config.APITokenConfig{DefaultTTLDays: 0, MaxTTLDays: 30})
+	now := time.Now()
+
+	exp, err := s.expiry(0, now)
+	if err != nil || exp == nil || !exp.Equal(now.AddDate(0, 0, 30)) {
+		t.Errorf("default capped to max: %v, %v", exp, err)
+	}
+	if exp, err = s.expiry(-1, now); err != nil || exp == nil {
+		t.Errorf("no-expiry must be capped when a max is set: %v, %v", exp, err)
+	}
+	if _, err = s.expiry(31, now); err == nil {
+		t.Error("lifetime above the max accepted")
+	}
+}
+
+func TestAPIToken_ServiceAccount(t *testing.T) {
+	ctx := context.Background()
+	s, st := newTestAPITokenService(t, config.APITokenConfig{})
+
+	sa, err := s.CreateServiceAccount(ctx, "ws-1", "alice", &dto.ServiceAccountDto{Name: "terraform", Role: dto.RoleEditor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateServiceAccount(ctx, "ws-1", "alice", &dto.ServiceAccountDto{Name: "terraform", Role: dto.RoleViewer}); err == nil {
+		t.Error("duplicate service account name accepted")
+	}
+	if _, err := s.CreateServiceAccountToken(ctx, "ws-1", sa.ID, "alice", &dto.APITokenDto{Name: "t", Role: dto.RoleAdmin}); err == nil {
+		t.Error("token role above the account's role accepted")
+	}
+	if _, err := s.CreateServiceAccountToken(ctx, "ws-2", sa.ID, "alice", &dto.APITokenDto{Name: "t"}); err == nil {
+		t.Error("token created through another workspace")
+	}
+
+	tok, err := s.CreateServiceAccountToken(ctx, "ws-1", sa.ID, "alice", &dto.APITokenDto{Name: "ci"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ident, err := s.AuthenticateAPIToken(ctx, tok.Token, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ident.ServiceAccount || ident.Username != "sa:terraform" || ident.WorkspaceID != "ws-1" || ident.Role != dto.RoleEditor {
+		t.Errorf("unexpected identity %+v", ident)
+	}
+	if err := ident.Permits("ws-2", dto.RoleViewer); err == nil {
+		t.Error("service account token permitted in another workspace")
+	}
+	if err := ident.Permits("ws-1", dto.RoleAdmin); err == nil {
+		t.Error("service account token permitted above its role")
+	}
+
+	list, err := s.ListServiceAccounts(ctx, "ws-1")
+	if err != nil || len(list) != 1 || list[0].Tokens != 1 {
+		t.Fatalf("list: %+v, %v", list, err)
+	}
+
+	if err := s.DeleteServiceAccount(ctx, "ws-1", sa.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AuthenticateAPIToken(ctx, tok.Token, ""); !errors.Is(err, ErrAPITokenRevoked) {
+		t.Errorf("token of deleted account: got %v", err)
+	}
+	if tokens, _ := st.APITokens().ListByServiceAccount(ctx, sa.ID); len(tokens) != 1 || tokens[0].RevokedAt == nil {
+		t.Errorf("tokens not revoked with their account: %+v", tokens)
+	}
+}
@@ -2,37 +2,82 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/alatticeio/lattice/internal/agent/config"
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"github.com/alatticeio/lattice/internal/agent/store"
 	"github.com/alatticeio/lattice/internal/server/models"
+	"github.com/alatticeio/lattice/internal/server/vo"
 
 	"github.com/google/uuid"
 )
 
+// Audit export formats accepted by AuditService.Export.
+const (
+	AuditExportJSONL = "jsonl"
+	AuditExportCSV   = "csv"
+)
+
+// auditVerifyBatch is how many chained entries Verify reads per query.
+const auditVerifyBatch = 1000
+
 // AuditService records and queries audit log entries.
 type AuditService interface {
 	// Log queues an audit event for async write; never blocks the caller.
 	Log(entry models.AuditLog)
 	// List returns a paginated, filtered list of audit logs.
 	List(ctx context.Context, filter store.AuditLogFilter) ([]*models.AuditLog, int64, error)
-	// Start launches the background writer; call once at startup.
+	// Verify walks the hash chain from the oldest retained entry and reports
+	// the first entry that was modified, removed or reordered.
+	Verify(ctx context.Context) (*vo.AuditVerifyVo, error)
+	// Export streams every entry matching filter, oldest first, to w as
+	// JSONL or CSV. Paging fields of filter are ignored.
+	Export(ctx context.Context, filter store.AuditLogFilter, format string, w io.Writer) error
+	// Start launches the background writer, the retention pruning and the
+	// sinks; call once at startup.
 	Start(ctx context.Context)
 }
 
 type auditService struct {
 	store  store.Store
+	cfg    config.AuditConfig
 	logger *log.Logger
 	ch     chan models.AuditLog
+	sinks  []*auditSinkWorker
+
+	// mu guards the chain head; only the writer goroutine advances it.
+	mu   sync.Mutex
+	seq  int64
+	head string
 }
 
-func NewAuditService(st store.Store) AuditService {
-	return &auditService{
+func NewAuditService(st store.Store, cfg config.AuditConfig) AuditService {
+	s := &auditService{
 		store:  st,
+		cfg:    cfg,
 		logger: log.GetLogger("audit"),
 		ch:     make(chan models.AuditLog, 512),
 	}
+	for _, sc := range cfg.Sinks {
+		sink, err := newAuditSink(sc)
+		if err != nil {
+			s.logger.Error("audit sink disabled", err, "type", sc.Type, "address", sc.Address)
+			continue
+		}
+		s.sinks = append(s.sinks, newAuditSinkWorker(sink, s.logger))
+	}
+	return s
 }
 
 func (s *auditService) Log(entry models.AuditLog) {
@@ -55,19 +100,32 @@ func (s *auditService) List(ctx context.Context, filter store.AuditLogFilter) ([
 }
 
 // Start runs the background flush goroutine.
-// It batches up to 100 entries or flushes every second, whichever comes first.
+// It batches up to 100 entries or flushes every second, whichever comes first,
+// and prunes entries older than the retention hourly when one is configured.
 func (s *auditService) Start(ctx context.Context) {
+	s.resume(ctx)
+	for _, w := range s.sinks {
+		go w.run(ctx)
+	}
+
 	go func() {
 		ticker := time.NewTicker(time.Second)
 		defer ticker.Stop()
 
+		var pruneC <-chan time.Time
+		if s.cfg.RetentionDays > 0 {
+			prune := time.NewTicker(time.Hour)
+			defer prune.Stop()
+			pruneC = prune.C
+		}
+
 		buf := make([]*models.AuditLog, 0, 100)
 
 		flush := func() {
 			if len(buf) == 0 {
 				return
 			}
-			if err := s.store.AuditLogs().BatchCreate(context.Background(), buf); err != nil {
+			if err := s.commit(context.Background(), buf); err != nil {
 				s.logger.Error("audit flush failed", err)
 			}
 			buf = buf[:0]
@@ -95,7 +153,272 @@ func (s *auditService) Start(ctx context.Context) {
 				}
 			case <-ticker.C:
 				flush()
+			case <-pruneC:
+				if err := s.prune(context.Background(), time.Now()); err != nil {
+					s.logger.Error("audit prune failed", err)
+				}
 			}
 		}
 	}()
 }
+
+// resume picks the chain up where the previous run left it.
+func (s *auditService) resume(ctx context.Context) {
+	last, err := s.store.AuditLogs().Last(ctx)
+	if err != nil {
+		s.logger.Error("audit chain head not loaded, starting a new chain", err)
+		return
+	}
+	if last != nil {
+		s.mu.Lock()
+		s.seq, s.head = last.Seq, last.Hash
+		s.mu.Unlock()
+	}
+}
+
+// auditCommitRetries is how many times commit relinks a batch onto a chain
+// head another replica has moved.
+const auditCommitRetries = 5
+
+// commit links buf onto the chain, writes it and hands it to the sinks. The
+// chain head only advances once the write succeeded, so a failed batch leaves
+// no gap. Seq is unique, so when another replica has appended since the head
+// was loaded the write fails as a whole; commit then picks up the new head
+// and links the batch again.
+func (s *auditService) commit(ctx context.Context, buf []*models.AuditLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		seq, prev := s.seq, s.head
+		for _, e := range buf {
+			seq++
+			// Millisecond precision survives a round trip through every
+			// supported database, so the stored row hashes the same as this one.
+			e.CreatedAt = e.CreatedAt.UTC().Truncate(time.Millisecond)
+			e.Seq = seq
+			e.PrevHash = prev
+			e.Hash = auditEntryHash(e)
+			prev = e.Hash
+		}
+		err := s.store.AuditLogs().BatchCreate(ctx, buf)
+		if err == nil {
+			s.seq, s.head = seq, prev
+			break
+		}
+		last, lerr := s.store.AuditLogs().Last(ctx)
+		if lerr != nil || last == nil || last.Seq == s.seq || attempt == auditCommitRetries {
+			return err
+		}
+		s.seq, s.head = last.Seq, last.Hash
+	}
+
+	for _, w := range s.sinks {
+		for _, e := range buf {
+			w.enqueue(*e)
+		}
+	}
+	return nil
+}
+
+// auditHashInput is the canonical form of an entry that its hash covers.
+// Fields added later are omitempty, so entries written before they existed
+// keep their hashes.
+type auditHashInput struct {
+	Seq          int64  `json:"seq"`
+	ID           string `json:"id"`
+	CreatedAt    string `json:"createdAt"`
+	UserID       string `json:"userId"`
+	UserName     string `json:"userName"`
+	UserEmail    string `json:"userEmail"`
+	UserIP       string `json:"userIP"`
+	WorkspaceID  string `json:"workspaceId"`
+	Source       string `json:"source,omitempty"`
+	Action       string `json:"action"`
+	Resource     string `json:"resource"`
+	ResourceID   string `json:"resourceId"`
+	ResourceName string `json:"resourceName"`
+	Scope        string `json:"scope"`
+	Status       string `json:"status"`
+	StatusCode   int    `json:"statusCode"`
+	Detail       string `json:"detail"`
+}
+
+// auditEntryHash returns hex(sha256(PrevHash || canonical JSON of e)).
+func auditEntryHash(e *models.AuditLog) string {
+	b, _ := json.Marshal(auditHashInput{
+		Seq:          e.Seq,
+		ID:           e.ID,
+		CreatedAt:    e.CreatedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		UserID:       e.UserID,
+		UserName:     e.UserName,
+		UserEmail:    e.UserEmail,
+		UserIP:       e.UserIP,
+		WorkspaceID:  e.WorkspaceID,
+		Source:       e.Source,
+		Action:       e.Action,
+		Resource:     e.Resource,
+		ResourceID:   e.ResourceID,
+		ResourceName: e.ResourceName,
+		Scope:        e.Scope,
+		Status:       e.Status,
+		StatusCode:   e.StatusCode,
+		Detail:       e.Detail,
+	})
+	h := sha256.New()
+	h.Write([]byte(e.PrevHash))
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (s *auditService) Verify(ctx context.Context) (*vo.AuditVerifyVo, error) {
+	res := &vo.AuditVerifyVo{Valid: true}
+	broken := func(seq int64, format string, args ...any) (*vo.AuditVerifyVo, error) {
+		res.Valid = false
+		res.BrokenSeq = seq
+		res.Reason = fmt.Sprintf(format, args...)
+		return res, nil
+	}
+
+	var prev *models.AuditLog
+	var after int64
+	for {
+		batch, err := s.store.AuditLogs().Range(ctx, after, auditVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range batch {
+			if prev == nil {
+				// Entries before the first one may only have been pruned by
+				// the retention, which records the last one it removed.
+				res.FirstSeq = e.Seq
+				if e.Seq > 1 {
+					anchor, err := s.store.AuditLogs().Anchor(ctx)
+					if err != nil {
+						return nil, err
+					}
+					if anchor == nil || anchor.Seq != e.Seq-1 || anchor.Hash != e.PrevHash {
+						return broken(e.Seq-1, "entries before %d were removed outside the retention", e.Seq)
+					}
+				}
+			} else {
+				if e.Seq != prev.Seq+1 {
+					return broken(prev.Seq+1, "entries %d to %d are missing", prev.Seq+1, e.Seq-1)
+				}
+				if e.PrevHash != prev.Hash {
+					return broken(e.Seq, "previous hash does not match entry %d", prev.Seq)
+				}
+			}
+			if auditEntryHash(e) != e.Hash {
+				return broken(e.Seq, "content does not match its hash")
+			}
+			res.Entries++
+			res.LastSeq, res.HeadHash = e.Seq, e.Hash
+			prev = e
+		}
+		if len(batch) < auditVerifyBatch {
+			break
+		}
+		after = batch[len(batch)-1].Seq
+	}
+
+	s.mu.Lock()
+	written := s.seq
+	s.mu.Unlock()
+	if res.LastSeq < written {
+		return broken(res.LastSeq+1, "entries %d to %d are missing", res.LastSeq+1, written)
+	}
+	return res, nil
+}
+
+var auditCSVHeader = []string{
+	"seq", "id", "createdAt", "userId", "userName", "userEmail", "userIP",
+	"workspaceId", "source", "action", "resource", "resourceId", "resourceName",
+	"scope", "status", "statusCode", "detail", "prevHash", "hash",
+}
+
+func (s *auditService) Export(ctx context.Context, filter store.AuditLogFilter, format string, w io.Writer) error {
+	switch format {
+	case AuditExportJSONL, "":
+		enc := json.NewEncoder(w)
+		return s.store.AuditLogs().Each(ctx, filter, func(e *models.AuditLog) error {
+			return enc.Encode(e)
+		})
+	case AuditExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(auditCSVHeader); err != nil {
+			return err
+		}
+		err := s.store.AuditLogs().Each(ctx, filter, func(e *models.AuditLog) error {
+			return cw.Write([]string{
+				strconv.FormatInt(e.Seq, 10), e.ID, e.CreatedAt.UTC().Format(time.RFC3339Nano),
+				e.UserID, e.UserName, e.UserEmail, e.UserIP, e.WorkspaceID, e.Source,
+				e.Action, e.Resource, e.ResourceID, e.ResourceName, e.Scope,
+				e.Status, strconv.Itoa(e.StatusCode), e.Detail, e.PrevHash, e.Hash,
+			})
+		})
+		cw.Flush()
+		if err != nil {
+			return err
+		}
+		return cw.Error()
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// prune deletes entries older than the retention, archiving them first when
+// an archive directory is configured. Nothing is deleted if the archive
+// cannot be written.
+func (s *auditService) prune(ctx context.Context, now time.Time) error {
+	// Whole seconds, so the RFC 3339 filter used for the archive selects
+	// exactly the rows DeleteUntil removes.
+	cutoff := now.AddDate(0, 0, -s.cfg.RetentionDays).UTC().Truncate(time.Second)
+
+	if s.cfg.ArchiveDir != "" {
+		if err := s.archive(ctx, cutoff); err != nil {
+			return fmt.Errorf("archive audit logs: %w", err)
+		}
+	}
+	n, err := s.store.AuditLogs().DeleteUntil(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		s.logger.Info("audit logs pruned", "records", n, "until", cutoff)
+	}
+	return nil
+}
+
+// archive writes every entry up to cutoff to a JSONL file named after the
+// cutoff. No file is created when there is nothing to archive.
+func (s *auditService) archive(ctx context.Context, cutoff time.Time) error {
+	if err := os.MkdirAll(s.cfg.ArchiveDir, 0o750); err != nil {
+		return err
+	}
+	path := filepath.Join(s.cfg.ArchiveDir, "audit-"+cutoff.Format("20060102T150405Z")+".jsonl")
+
+	var f *os.File
+	var enc *json.Encoder
+	err := s.store.AuditLogs().Each(ctx, store.AuditLogFilter{To: cutoff.Format(time.RFC3339)}, func(e *models.AuditLog) error {
+		if f == nil {
+			var err error
+			if f, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600); err != nil {
+				return err
+			}
+			enc = json.NewEncoder(f)
+		}
+		return enc.Encode(e)
+	})
+	if f == nil {
+		return err
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	s.logger.Info("audit logs archived", "file", path)
+	return nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/log"
+	"github.com/alatticeio/lattice/internal/server/models"
+)
+
+// Audit sink types accepted in config.AuditSinkConfig.Type.
+const (
+	AuditSinkSyslog  = "syslog"
+	AuditSinkWebhook = "webhook"
+	AuditSinkFile    = "file"
+)
+
+const (
+	// auditSinkQueue is how many entries a slow sink may fall behind before
+	// new ones are dropped for it.
+	auditSinkQueue = 1024
+	// auditSinkTimeout bounds a single delivery.
+	auditSinkTimeout = 10 * time.Second
+)
+
+// auditSink delivers committed audit entries to an external system.
+type auditSink interface {
+	Send(ctx context.Context, e *models.AuditLog) error
+	Close() error
+	String() string
+}
+
+func newAuditSink(cfg config.AuditSinkConfig) (auditSink, error) {
+	switch cfg.Type {
+	case AuditSinkSyslog:
+		return newSyslogSink(cfg.Address)
+	case AuditSinkWebhook:
+		if _, err := url.ParseRequestURI(cfg.Address); err != nil {
+			return nil, fmt.Errorf("webhook sink: %w", err)
+		}
+		return &webhookSink{
+			url:     cfg.Address,
+			headers: cfg.Headers,
+			client:  &http.Client{Timeout: auditSinkTimeout},
+		}, nil
+	case AuditSinkFile:
+		if cfg.Address == "" {
+			return nil, fmt.Errorf("file sink: no path")
+		}
+		return &fileSink{path: cfg.Address}, nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink type %q, want syslog, webhook or file", cfg.Type)
+	}
+}
+
+// auditSinkWorker feeds one sink from its own queue, so a slow or unreachable
+// sink neither blocks the database writer nor the other sinks.
+type auditSinkWorker struct {
+	sink   auditSink
+	logger *log.Logger
+	ch     chan models.AuditLog
+}
+
+func newAuditSinkWorker(sink auditSink, logger *log.Logger) *auditSinkWorker {
+	return &auditSinkWorker{sink: sink, logger: logger, ch: make(chan models.AuditLog, auditSinkQueue)}
+}
+
+func (w *auditSinkWorker) enqueue(e models.AuditLog) {
+	select {
+	case w.ch <- e:
+	default:
+		w.logger.Warn("audit sink queue full, dropping entry", "sink", w.sink.String(), "seq", e.Seq)
+	}
+}
+
+func (w *auditSinkWorker) run(ctx context.Context) {
+	defer w.sink.Close() //nolint:errcheck
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-w.ch:
+			sendCtx, cancel := context.WithTimeout(ctx, auditSinkTimeout)
+			if err := w.sink.Send(sendCtx, &e); err != nil {
+				w.logger.Warn("audit sink delivery failed", "sink", w.sink.String(), "seq", e.Seq, "err", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// syslogSink writes RFC 5424 messages over UDP, or over TCP with RFC 6587
+// octet-counting framing. The message body is the entry as JSON.
+type syslogSink struct {
+	network  string
+	addr     string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// syslogFacilityLocal0 is the facility audit messages are sent with.
+const syslogFacilityLocal0 = 16
+
+const (
+	syslogSeverityWarning = 4
+	syslogSeverityInfo    = 6
+)
+
+func newSyslogSink(address string) (*syslogSink, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("syslog sink: %w", err)
+	}
+	if u.Scheme != "udp" && u.Scheme != "tcp" {
+		return nil, fmt.Errorf("syslog sink: scheme must be udp or tcp, got %q", u.Scheme)
+	}
+	if u.Port() == "" {
+		return nil, fmt.Errorf("syslog sink: %q has no port", address)
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	return &syslogSink{network: u.Scheme, addr: u.Host, hostname: hostname}, nil
+}
+
+func (s *syslogSink) String() string { return s.network + "://" + s.addr }
+
+// format renders e as an RFC 5424 message.
+func (s *syslogSink) format(e *models.AuditLog) ([]byte, error) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	severity := syslogSeverityInfo
+	if e.Status == "failed" {
+		severity = syslogSeverityWarning
+	}
+	msgID := e.Action
+	if msgID == "" {
+		msgID = "-"
+	}
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	header := fmt.Sprintf("<%d>1 %s %s lattice %d %s - ",
+		syslogFacilityLocal0*8+severity,
+		e.CreatedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		s.hostname, os.Getpid(), msgID)
+	return append([]byte(header), body...), nil
+}
+
+func (s *syslogSink) Send(ctx context.Context, e *models.AuditLog) error {
+	msg, err := s.format(e)
+	if err != nil {
+		return err
+	}
+	if s.network == "tcp" {
+		msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// One redial covers a collector that restarted since the last message.
+	for attempt := 0; ; attempt++ {
+		if s.conn == nil {
+			var d net.Dialer
+			if s.conn, err = d.DialContext(ctx, s.network, s.addr); err != nil {
+				return err
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = s.conn.SetWriteDeadline(deadline)
+		}
+		if _, err = s.conn.Write(msg); err == nil {
+			return nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+		if attempt > 0 {
+			return err
+		}
+	}
+}
+
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// webhookSink POSTs each entry as JSON.
+type webhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (s *webhookSink) String() string { return s.url }
+
+func (s *webhookSink) Send(ctx context.Context, e *models.AuditLog) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() //nolint:errcheck
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", res.Status)
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error { return nil }
+
+// fileSink appends each entry to a file as one JSON line.
+type fileSink struct {
+	path string
+	f    *os.File
+}
+
+func (s *fileSink) String() string { return "file://" + s.path }
+
+func (s *fileSink) Send(_ context.Context, e *models.AuditLog) error {
+	if s.f == nil {
+		f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return err
+		}
+		s.f = f
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = s.f.Write(append(b, '\n'))
+	return err
+}
+
+func (s *fileSink) Close() error {
+	if s.f == nil {
+		return nil
+	}
+	return s.f.Close()
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/models"
+
+	"gorm.io/gorm"
+)
+
+func newTestAuditService(t *testing.T, cfg config.AuditConfig) (*auditService, *gorm.DB) {
+	t.Helper()
+	st, db := newTestStore(t)
+	return NewAuditService(st, cfg).(*auditService), db
+}
+
+func auditEntries(prefix string, n int, at time.Time) []*models.AuditLog {
+	out := make([]*models.AuditLog, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, &models.AuditLog{
+			ID:        prefix + string(rune('a'+i)),
+			CreatedAt: at.Add(time.Duration(i) * time.Second),
+			UserName:  "alice",
+			Action:    "UPDATE",
+			Resource:  "policy",
+			Status:    "success",
+		})
+	}
+	return out
+}
+
+func TestAuditChain_VerifyDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	s, db := newTestAuditService(t, config.AuditConfig{})
+
+	now := time.Now()
+	if err := s.commit(ctx, auditEntries("a", 3, now)); err != nil {
+		t.Fatal(err)
+	}
+	// A restarted manager continues the same chain.
+	s2 := NewAuditService(s.store, config.AuditConfig{}).(*auditService)
+	s2.resume(ctx)
+	if err := s2.commit(ctx, auditEntries("b", 1, now.Add(time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := s2.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid || res.Entries != 4 || res.FirstSeq != 1 || res.LastSeq != 4 {
+		t.Fatalf("intact chain: got %+v", res)
+	}
+
+	if err := db.Model(&models.AuditLog{}).Where("seq = ?", 2).Update("user_name", "mallory").Error; err != nil {
+		t.Fatal(err)
+	}
+	res, err = s2.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Valid || res.BrokenSeq != 2 {
+		t.Fatalf("modified entry: got %+v", res)
+	}
+
+	if err := db.Where("seq = ?", 2).Delete(&models.AuditLog{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	res, err = s2.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Valid || res.BrokenSeq != 2 || !strings.Contains(res.Reason, "missing") {
+		t.Fatalf("deleted entry: got %+v", res)
+	}
+
+	// Dropping the newest entry is caught against the writer's own head.
+	if err := db.Where("seq >= ?", 3).Delete(&models.AuditLog{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	res, err = s2.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Valid || res.BrokenSeq != 2 {
+		t.Fatalf("truncated chain: got %+v", res)
+	}
+}
+
+func TestAuditChain_ConcurrentReplicasDoNotFork(t *testing.T) {
+	ctx := context.Background()
+	s1, db := newTestAuditService(t, config.AuditConfig{})
+	// A legacy entry from before the chain has no Seq.
+	if err := db.Exec("INSERT INTO t_audit_log (id, created_at, action, resource, status) VALUES ('legacy', ?, 'LOGIN', 'user', 'success')", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Both replicas load the same (empty) head, then append in turn.
+	s2 := NewAuditService(s1.store, config.AuditConfig{}).(*auditService)
+	s1.resume(ctx)
+	s2.resume(ctx)
+	now := time.Now()
+	if err := s1.commit(ctx, auditEntries("a", 2, now)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.commit(ctx, auditEntries("b", 1, now.Add(time.Second))); err != nil {
+		t.Fatal(err)
+	}
+	if err := s1.commit(ctx, auditEntries("c", 1, now.Add(2*time.Second))); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := s1.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid || res.Entries != 4 || res.LastSeq != 4 {
+		t.Fatalf("chain: got %+v", res)
+	}
+	logs, total, err := s1.store.AuditLogs().List(ctx, store.AuditLogFilter{})
+	if err != nil || total != 5 || len(logs) != 5 {
+		t.Fatalf("list = %d/%d, %v", len(logs), total, err)
+	}
+}
+
+func TestAuditPrune_ArchivesAndKeepsChainVerifiable(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s, db := newTestAuditService(t, config.AuditConfig{RetentionDays: 30, ArchiveDir: dir})
+
+	now := time.Now()
+	old := auditEntries("o", 2, now.AddDate(0, 0, -40))
+	recent := auditEntries("r", 3, now.Add(-time.Hour))
+	recent[2].Status = "failed"
+	if err := s.commit(ctx, append(old, recent...)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.prune(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "audit-*.jsonl"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("archive files: %v, %v", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 2 {
+		t.Errorf("archived %d entries, want 2", n)
+	}
+
+	res, err := s.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid || res.FirstSeq != 3 || res.Entries != 3 {
+		t.Errorf("after prune: got %+v", res)
+	}
+
+	var buf bytes.Buffer
+	if err := s.Export(ctx, store.AuditLogFilter{Status: "failed"}, AuditExportCSV, &buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1][0] != "5" {
+		t.Errorf("csv export: got %v", rows)
+	}
+
+	// Deleting the oldest retained entry outside the retention is reported.
+	if err := db.Where("seq = 3").Delete(&models.AuditLog{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	res, err = s.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Valid || res.BrokenSeq != 3 {
+		t.Errorf("after deleting the oldest entry: got %+v", res)
+	}
+}
+
+func TestSyslogSink_TCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close() //nolint:errcheck
+
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close() //nolint:errcheck
+		r := bufio.NewReader(conn)
+		n, _ := r.ReadString(' ')
+		line, _ := r.ReadString('}')
+		got <- n + line
+	}()
+
+	sink, err := newAuditSink(config.AuditSinkConfig{Type: AuditSinkSyslog, Address: "tcp://" + ln.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close() //nolint:errcheck
+	e := &models.AuditLog{ID: "x", CreatedAt: time.Now(), Action: "DELETE", Status: "failed", Seq: 7}
+	if err := sink.Send(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-got:
+		// local0.warning = 16*8+4
+		if !strings.Contains(msg, " <132>1 ") || !strings.Contains(msg, " lattice ") || !strings.Contains(msg, " DELETE - {") {
+			t.Errorf("unexpected syslog frame %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no syslog message received")
+	}
+}
@@ -0,0 +1,624 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/log"
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/models"
+	"github.com/alatticeio/lattice/internal/server/resource"
+	"github.com/alatticeio/lattice/internal/server/vo"
+
+	"github.com/pmezard/go-difflib/difflib"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// maxBundleObjects bounds the DB-backed objects read per section.
+const maxBundleObjects = 10000
+
+// BundleService exports a workspace as a declarative bundle and reconciles
+// a bundle back onto it.
+//
+// Networks and peers are never deleted: networks belong to the workspace and
+// peers to their agents. Prune deletes active policies and alert rules
+// missing from the bundle, and relays dedicated to the workspace. Relays are
+// cluster-wide, so only those dedicated to the workspace, serving it alone,
+// are created or changed; the others are skipped.
+type BundleService interface {
+	Export(ctx context.Context, namespace string) (*dto.Bundle, error)
+	Diff(ctx context.Context, namespace string, bundle *dto.Bundle, prune bool) ([]vo.BundleChange, error)
+	Apply(ctx context.Context, namespace string, bundle *dto.Bundle, prune bool) ([]vo.BundleChange, error)
+}
+
+type bundleService struct {
+	log      *log.Logger
+	client   *resource.Client
+	store    store.Store
+	policies PolicyService
+	alerts   *AlertService
+}
+
+// NewBundleService constructs a BundleService.
+func NewBundleService(c *resource.Client, st store.Store) BundleService {
+	return &bundleService{
+		log:      log.GetLogger("bundle-service"),
+		client:   c,
+		store:    st,
+		policies: NewPolicyService(c, st),
+		alerts:   NewAlertService(st),
+	}
+}
+
+// bundleState is the server side of a workspace: its bundle plus what apply
+// needs to address the objects in it.
+type bundleState struct {
+	wsID   string
+	bundle *dto.Bundle
+	// relays holds every relay, including those that do not serve the
+	// workspace, so a bundle naming a relay of other workspaces is skipped
+	// rather than created.
+	relays     map[string]dto.BundleRelay
+	ruleIDs    map[string]string // alert rule name -> ID
+	channelIDs map[string]string // alert channel name -> ID
+}
+
+// bundleStep is one change with the object it converges to. desired is nil
+// for deletes and skips.
+type bundleStep struct {
+	vo.BundleChange
+	desired any
+}
+
+func (s *bundleService) Export(ctx context.Context, namespace string) (*dto.Bundle, error) {
+	st, err := s.state(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return st.bundle, nil
+}
+
+func (s *bundleService) Diff(ctx context.Context, namespace string, bundle *dto.Bundle, prune bool) ([]vo.BundleChange, error) {
+	st, err := s.state(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := planBundle(st, namespace, bundle, prune)
+	if err != nil {
+		return nil, err
+	}
+	return bundleChanges(steps), nil
+}
+
+func (s *bundleService) Apply(ctx context.Context, namespace string, bundle *dto.Bundle, prune bool) ([]vo.BundleChange, error) {
+	st, err := s.state(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := planBundle(st, namespace, bundle, prune)
+	if err != nil {
+		return nil, err
+	}
+	if err = st.checkChannels(bundle); err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, infra.WorkspaceKey, st.wsID)
+	for i, step := range steps {
+		if step.Action == vo.BundleActionSkip {
+			continue
+		}
+		if err = s.applyStep(ctx, namespace, st, step); err != nil {
+			return nil, fmt.Errorf("%s %s %q: %w (%d of %d changes applied)",
+				step.Action, step.Kind, step.Name, err, i, len(steps))
+		}
+		s.log.Info("bundle change applied", "namespace", namespace, "kind", step.Kind, "name", step.Name, "action", step.Action)
+	}
+	return bundleChanges(steps), nil
+}
+
+// state reads the workspace's bundle from k8s and the DB.
+func (s *bundleService) state(ctx context.Context, namespace string) (*bundleState, error) {
+	ws, err := s.store.Workspaces().GetByNamespace(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("workspace not found for namespace %q: %w", namespace, err)
+	}
+	st := &bundleState{
+		wsID:       ws.ID,
+		bundle:     &dto.Bundle{APIVersion: dto.BundleAPIVersion, Kind: dto.BundleKind, Namespace: namespace},
+		relays:     make(map[string]dto.BundleRelay),
+		ruleIDs:    make(map[string]string),
+		channelIDs: make(map[string]string),
+	}
+	b := st.bundle
+	reader := s.client.GetAPIReader()
+
+	var networks v1alpha1.LatticeNetworkList
+	if err = reader.List(ctx, &networks, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("list networks: %w", err)
+	}
+	for _, n := range networks.Items {
+		b.Networks = append(b.Networks, dto.BundleNetwork{Name: n.Name, Spec: n.Spec})
+	}
+
+	records, _, err := s.store.Policies().List(ctx, store.PolicyFilter{
+		WorkspaceID: ws.ID,
+		Status:      string(models.PolicyStatusActive),
+		PageSize:    maxBundleObjects,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list policies: %w", err)
+	}
+	for _, rec := range records {
+		p := dto.BundlePolicy{Name: rec.Name, Description: rec.Description}
+		_ = json.Unmarshal([]byte(rec.Spec), &p.LatticePolicySpec)
+		_ = json.Unmarshal([]byte(rec.PolicyTypes), &p.PolicyTypes)
+		p.Action = rec.Action
+		b.Policies = append(b.Policies, p)
+	}
+
+	var peers v1alpha1.LatticePeerList
+	if err = reader.List(ctx, &peers, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("list peers: %w", err)
+	}
+	for _, p := range peers.Items {
+		if p.Labels["alattice.io/shadow"] == "true" {
+			continue
+		}
+		b.Peers = append(b.Peers, dto.BundlePeer{
+			Name:      p.Name,
+			Labels:    userPeerLabels(p.Labels),
+			Bandwidth: p.Spec.Bandwidth,
+			QoSClass:  p.Spec.QoSClass,
+		})
+	}
+
+	var relays v1alpha1.LatticeRelayServerList
+	if err = reader.List(ctx, &relays); err != nil {
+		return nil, fmt.Errorf("list relays: %w", err)
+	}
+	for _, r := range relays.Items {
+		br := dto.BundleRelay{Name: r.Name, Spec: r.Spec}
+		st.relays[r.Name] = br
+		if slices.Contains(r.Spec.Namespaces, namespace) {
+			b.Relays = append(b.Relays, br)
+		}
+	}
+
+	channels, err := s.store.Alerts().ListAlertChannels(ctx, ws.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list alert channels: %w", err)
+	}
+	channelNames := make(map[string]string, len(channels))
+	for _, c := range channels {
+		channelNames[c.ID] = c.Name
+		st.channelIDs[c.Name] = c.ID
+	}
+	rules, err := s.store.Alerts().ListAlertRulesByWorkspace(ctx, ws.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list alert rules: %w", err)
+	}
+	for _, r := range rules {
+		if _, dup := st.ruleIDs[r.Name]; dup {
+			s.log.Warn("duplicate alert rule name, exporting the first", "namespace", namespace, "name", r.Name)
+			continue
+		}
+		st.ruleIDs[r.Name] = r.ID
+		b.AlertRules = append(b.AlertRules, alertRuleToBundle(r, channelNames))
+	}
+
+	sortByName(b.Networks, func(n dto.BundleNetwork) string { return n.Name })
+	sortByName(b.Policies, func(p dto.BundlePolicy) string { return p.Name })
+	sortByName(b.Peers, func(p dto.BundlePeer) string { return p.Name })
+	sortByName(b.Relays, func(r dto.BundleRelay) string { return r.Name })
+	sortByName(b.AlertRules, func(r dto.BundleAlertRule) string { return r.Name })
+	return st, nil
+}
+
+func (s *bundleService) applyStep(ctx context.Context, namespace string, st *bundleState, step bundleStep) error {
+	switch step.Kind {
+	case vo.BundleKindNetwork:
+		return s.applyNetwork(ctx, namespace, step.desired.(*dto.BundleNetwork))
+	case vo.BundleKindRelay:
+		if step.Action == vo.BundleActionDelete {
+			return client.IgnoreNotFound(s.client.Delete(ctx, &v1alpha1.LatticeRelayServer{
+				ObjectMeta: metav1.ObjectMeta{Name: step.Name},
+			}))
+		}
+		return s.applyRelay(ctx, namespace, step.desired.(*dto.BundleRelay))
+	case vo.BundleKindPeer:
+		return s.applyPeer(ctx, namespace, step.desired.(*dto.BundlePeer))
+	case vo.BundleKindPolicy:
+		if step.Action == vo.BundleActionDelete {
+			return s.policies.DeletePolicy(ctx, step.Name)
+		}
+		p := step.desired.(*dto.BundlePolicy)
+		_, err := s.policies.ApplyDirect(ctx, st.wsID, "", "", &dto.PolicyDto{
+			Name:              p.Name,
+			Namespace:         namespace,
+			Action:            p.Action,
+			Description:       p.Description,
+			PolicyTypes:       p.PolicyTypes,
+			LatticePolicySpec: p.LatticePolicySpec,
+		})
+		return err
+	case vo.BundleKindAlertRule:
+		if step.Action == vo.BundleActionDelete {
+			return s.alerts.DeleteRule(ctx, st.ruleIDs[step.Name])
+		}
+		req := st.alertRuleRequest(step.desired.(*dto.BundleAlertRule))
+		if step.Action == vo.BundleActionCreate {
+			_, err := s.alerts.CreateRule(ctx, st.wsID, req)
+			return err
+		}
+		_, err := s.alerts.UpdateRule(ctx, st.ruleIDs[step.Name], req)
+		return err
+	}
+	return fmt.Errorf("unknown kind %q", step.Kind)
+}
+
+func (s *bundleService) applyNetwork(ctx context.Context, namespace string, n *dto.BundleNetwork) error {
+	var existing v1alpha1.LatticeNetwork
+	err := s.client.GetAPIReader().Get(ctx, client.ObjectKey{Namespace: namespace, Name: n.Name}, &existing)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err != nil {
+		return s.client.Create(ctx, &v1alpha1.LatticeNetwork{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      n.Name,
+				Namespace: namespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "lattice-controller"},
+			},
+			Spec: n.Spec,
+		})
+	}
+	original := existing.DeepCopy()
+	existing.Spec = n.Spec
+	return s.client.Patch(ctx, &existing, client.MergeFrom(original))
+}
+
+func (s *bundleService) applyRelay(ctx context.Context, namespace string, r *dto.BundleRelay) error {
+	var existing v1alpha1.LatticeRelayServer
+	err := s.client.GetAPIReader().Get(ctx, client.ObjectKey{Name: r.Name}, &existing)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err != nil {
+		return s.client.Create(ctx, &v1alpha1.LatticeRelayServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   r.Name,
+				Labels: map[string]string{"app.kubernetes.io/managed-by": "lattice-controller"},
+			},
+			Spec: r.Spec,
+		})
+	}
+	if !dedicatedRelay(existing.Spec, namespace) {
+		return fmt.Errorf("relay %q serves other workspaces", r.Name)
+	}
+	original := existing.DeepCopy()
+	existing.Spec = r.Spec
+	return s.client.Patch(ctx, &existing, client.MergeFrom(original))
+}
+
+// dedicatedRelay reports whether a relay serves namespace alone. Bundles
+// only manage such relays.
+func dedicatedRelay(spec v1alpha1.LatticeRelayServerSpec, namespace string) bool {
+	return len(spec.Namespaces) == 1 && spec.Namespaces[0] == namespace
+}
+
+// applyPeer replaces the user labels and traffic settings of a peer. Labels
+// the controllers maintain are kept.
+func (s *bundleService) applyPeer(ctx context.Context, namespace string, p *dto.BundlePeer) error {
+	var peer v1alpha1.LatticePeer
+	if err := s.client.GetAPIReader().Get(ctx, client.ObjectKey{Namespace: namespace, Name: p.Name}, &peer); err != nil {
+		return err
+	}
+	original := peer.DeepCopy()
+	labels := make(map[string]string, len(p.Labels))
+	for k, v := range peer.Labels {
+		if managedPeerLabel(k) {
+			labels[k] = v
+		}
+	}
+	for k, v := range p.Labels {
+		labels[k] = v
+	}
+	peer.Labels = labels
+	peer.Spec.Bandwidth = p.Bandwidth
+	peer.Spec.QoSClass = p.QoSClass
+	return s.client.Patch(ctx, &peer, client.MergeFrom(original))
+}
+
+// checkChannels rejects alert rules that name unknown channels before
+// anything is written.
+func (st *bundleState) checkChannels(b *dto.Bundle) error {
+	for _, r := range b.AlertRules {
+		for _, c := range r.Channels {
+			if _, ok := st.channelIDs[c]; !ok {
+				return fmt.Errorf("alert rule %q: unknown channel %q", r.Name, c)
+			}
+		}
+	}
+	return nil
+}
+
+func (st *bundleState) alertRuleRequest(r *dto.BundleAlertRule) CreateAlertRuleRequest {
+	channels := make([]string, 0, len(r.Channels))
+	for _, c := range r.Channels {
+		channels = append(channels, st.channelIDs[c])
+	}
+	return CreateAlertRuleRequest{
+		Name:       r.Name,
+		MetricType: r.MetricType,
+		Operator:   r.Operator,
+		Threshold:  r.Threshold,
+		Duration:   r.Duration,
+		Lookback:   r.Lookback,
+		GroupBy:    r.GroupBy,
+		ForEach:    r.ForEach,
+		Channels:   channels,
+		Severity:   r.Severity,
+		Message:    r.Message,
+	}
+}
+
+// alertRuleToBundle converts a rule, naming its channels. Channels that no
+// longer exist keep their ID.
+func alertRuleToBundle(r *models.AlertRule, channelNames map[string]string) dto.BundleAlertRule {
+	out := dto.BundleAlertRule{
+		Name:       r.Name,
+		MetricType: r.MetricType,
+		Operator:   r.Operator,
+		Threshold:  r.Threshold,
+		Duration:   r.Duration,
+		Lookback:   r.Lookback,
+		ForEach:    r.ForEach,
+		Severity:   r.Severity,
+		Message:    r.Message,
+	}
+	_ = json.Unmarshal([]byte(r.GroupBy), &out.GroupBy)
+	var channels []string
+	_ = json.Unmarshal([]byte(r.Channels), &channels)
+	for _, id := range channels {
+		if name, ok := channelNames[id]; ok {
+			id = name
+		}
+		out.Channels = append(out.Channels, id)
+	}
+	return out
+}
+
+// managedPeerLabel reports whether the controllers maintain a peer label.
+// Bundles neither export nor change such labels.
+func managedPeerLabel(key string) bool {
+	return strings.HasPrefix(key, "alattice.io/network-") ||
+		key == "alattice.io/shadow" ||
+		key == v1alpha1.RelayPeerLabel
+}
+
+func userPeerLabels(labels map[string]string) map[string]string {
+	var out map[string]string
+	for k, v := range labels {
+		if managedPeerLabel(k) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// planBundle lists the steps that turn the server state into want.
+func planBundle(st *bundleState, namespace string, want *dto.Bundle, prune bool) ([]bundleStep, error) {
+	if want.Kind != "" && want.Kind != dto.BundleKind {
+		return nil, fmt.Errorf("unsupported kind %q, want %s", want.Kind, dto.BundleKind)
+	}
+	have := st.bundle
+
+	policies := slices.Clone(want.Policies)
+	for i := range policies {
+		if policies[i].Action == "" {
+			policies[i].Action = "ALLOW"
+		}
+		policies[i].Action = strings.ToUpper(policies[i].Action)
+		if a := policies[i].Action; a != "ALLOW" && a != "DENY" {
+			return nil, fmt.Errorf("policy %q: action must be ALLOW or DENY, got %q", policies[i].Name, a)
+		}
+	}
+
+	var dedicatedRelays []dto.BundleRelay
+	for _, r := range have.Relays {
+		if dedicatedRelay(r.Spec, namespace) {
+			dedicatedRelays = append(dedicatedRelays, r)
+		}
+	}
+
+	var steps []bundleStep
+	for _, section := range []func() ([]bundleStep, error){
+		func() ([]bundleStep, error) {
+			return planSection(vo.BundleKindNetwork, want.Networks, have.Networks, nil, prune,
+				func(n *dto.BundleNetwork) string { return n.Name })
+		},
+		func() ([]bundleStep, error) {
+			all := make([]dto.BundleRelay, 0, len(st.relays))
+			for _, r := range st.relays {
+				all = append(all, r)
+			}
+			relaySteps, err := planSection(vo.BundleKindRelay, want.Relays, all, dedicatedRelays, prune,
+				func(r *dto.BundleRelay) string { return r.Name })
+			for i, step := range relaySteps {
+				if step.Action == vo.BundleActionDelete {
+					continue
+				}
+				current, exists := st.relays[step.Name]
+				if exists && !dedicatedRelay(current.Spec, namespace) ||
+					!dedicatedRelay(step.desired.(*dto.BundleRelay).Spec, namespace) {
+					relaySteps[i] = bundleStep{BundleChange: vo.BundleChange{
+						Kind:   vo.BundleKindRelay,
+						Name:   step.Name,
+						Action: vo.BundleActionSkip,
+						Reason: "relay serves other workspaces",
+					}}
+				}
+			}
+			return relaySteps, err
+		},
+		func() ([]bundleStep, error) {
+			peerSteps, err := planSection(vo.BundleKindPeer, want.Peers, have.Peers, nil, prune,
+				func(p *dto.BundlePeer) string { return p.Name })
+			for i := range peerSteps {
+				if peerSteps[i].Action == vo.BundleActionCreate {
+					peerSteps[i] = bundleStep{BundleChange: vo.BundleChange{
+						Kind:   vo.BundleKindPeer,
+						Name:   peerSteps[i].Name,
+						Action: vo.BundleActionSkip,
+						Reason: "peer has not enrolled",
+					}}
+				}
+			}
+			return peerSteps, err
+		},
+		func() ([]bundleStep, error) {
+			return planSection(vo.BundleKindPolicy, policies, have.Policies, have.Policies, prune,
+				func(p *dto.BundlePolicy) string { return p.Name })
+		},
+		func() ([]bundleStep, error) {
+			return planSection(vo.BundleKindAlertRule, want.AlertRules, have.AlertRules, have.AlertRules, prune,
+				func(r *dto.BundleAlertRule) string { return r.Name })
+		},
+	} {
+		s, err := section()
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, s...)
+	}
+	return steps, nil
+}
+
+// planSection compares the wanted objects of one kind with those on the
+// server. prunable lists the server objects prune may delete.
+func planSection[T any](kind string, want, have, prunable []T, prune bool, name func(*T) string) ([]bundleStep, error) {
+	current := make(map[string]*T, len(have))
+	for i := range have {
+		current[name(&have[i])] = &have[i]
+	}
+
+	var steps []bundleStep
+	seen := make(map[string]bool, len(want))
+	for i := range want {
+		w := &want[i]
+		n := name(w)
+		if n == "" {
+			return nil, fmt.Errorf("%s #%d has no name", kind, i+1)
+		}
+		if seen[n] {
+			return nil, fmt.Errorf("duplicate %s %q", kind, n)
+		}
+		seen[n] = true
+
+		h, ok := current[n]
+		switch {
+		case !ok:
+			steps = append(steps, newBundleStep(kind, n, vo.BundleActionCreate, nil, w))
+		case !sameObject(h, w):
+			steps = append(steps, newBundleStep(kind, n, vo.BundleActionUpdate, h, w))
+		}
+	}
+	if prune {
+		for i := range prunable {
+			p := &prunable[i]
+			if n := name(p); !seen[n] {
+				steps = append(steps, bundleStep{BundleChange: vo.BundleChange{
+					Kind:   kind,
+					Name:   n,
+					Action: vo.BundleActionDelete,
+					Diff:   yamlDiff(p, nil),
+				}})
+			}
+		}
+	}
+	return steps, nil
+}
+
+func newBundleStep(kind, name, action string, from, to any) bundleStep {
+	return bundleStep{
+		BundleChange: vo.BundleChange{Kind: kind, Name: name, Action: action, Diff: yamlDiff(from, to)},
+		desired:      to,
+	}
+}
+
+// sameObject compares objects by their JSON form, so unset and empty
+// optional fields are equal.
+func sameObject(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// yamlDiff renders a unified diff between the YAML of from (the server) and
+// to (the bundle). Either may be nil.
+func yamlDiff(from, to any) string {
+	return labeledYAMLDiff("server", "bundle", from, to)
+}
+
+// labeledYAMLDiff is yamlDiff with the file names of both sides given.
+func labeledYAMLDiff(fromFile, toFile string, from, to any) string {
+	text := func(v any) []string {
+		if v == nil {
+			return nil
+		}
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return difflib.SplitLines(string(out))
+	}
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        text(from),
+		B:        text(to),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+	return diff
+}
+
+func bundleChanges(steps []bundleStep) []vo.BundleChange {
+	out := make([]vo.BundleChange, 0, len(steps))
+	for _, s := range steps {
+		out = append(out, s.BundleChange)
+	}
+	return out
+}
+
+func sortByName[T any](items []T, name func(T) string) {
+	sort.Slice(items, func(i, j int) bool { return name(items[i]) < name(items[j]) })
+}
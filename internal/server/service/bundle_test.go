@@ -0,0 +1,143 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/vo"
+)
+
+func testBundleState() *bundleState {
+	shared := dto.BundleRelay{Name: "shared", Spec: v1alpha1.LatticeRelayServerSpec{TcpUrl: "r1:8080", Namespaces: []string{"ws", "other"}}}
+	own := dto.BundleRelay{Name: "own", Spec: v1alpha1.LatticeRelayServerSpec{TcpUrl: "r2:8080", Namespaces: []string{"ws"}}}
+	return &bundleState{
+		bundle: &dto.Bundle{
+			Networks: []dto.BundleNetwork{{Name: "net", Spec: v1alpha1.LatticeNetworkSpec{CIDR: "100.64.0.0/16"}}},
+			Policies: []dto.BundlePolicy{
+				{Name: "allow-web", LatticePolicySpec: v1alpha1.LatticePolicySpec{Action: "ALLOW"}},
+				{Name: "old", LatticePolicySpec: v1alpha1.LatticePolicySpec{Action: "DENY"}},
+			},
+			Peers:      []dto.BundlePeer{{Name: "node-1", Labels: map[string]string{"role": "web"}}},
+			Relays:     []dto.BundleRelay{own, shared},
+			AlertRules: []dto.BundleAlertRule{{Name: "cpu", MetricType: "cpu", Threshold: 90}},
+		},
+		relays: map[string]dto.BundleRelay{"shared": shared, "own": own},
+	}
+}
+
+func TestPlanBundle_NoChangesForExport(t *testing.T) {
+	st := testBundleState()
+	steps, err := planBundle(st, "ws", st.bundle, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 0 {
+		t.Fatalf("re-applying an export must be a no-op, got %+v", bundleChanges(steps))
+	}
+}
+
+func TestPlanBundle(t *testing.T) {
+	st := testBundleState()
+	want := &dto.Bundle{
+		Kind:     dto.BundleKind,
+		Networks: []dto.BundleNetwork{{Name: "net", Spec: v1alpha1.LatticeNetworkSpec{CIDR: "100.64.0.0/16"}}},
+		Policies: []dto.BundlePolicy{
+			// An empty action defaults to ALLOW, so this is unchanged.
+			{Name: "allow-web"},
+			{Name: "new", LatticePolicySpec: v1alpha1.LatticePolicySpec{Action: "deny"}},
+		},
+		Peers: []dto.BundlePeer{
+			{Name: "node-1", Labels: map[string]string{"role": "db"}},
+			{Name: "node-2"},
+		},
+	}
+
+	steps, err := planBundle(st, "ws", want, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(steps))
+	for _, s := range steps {
+		got = append(got, s.Kind+"/"+s.Name+"="+s.Action)
+	}
+	wantSteps := "Peer/node-1=update Peer/node-2=skip Policy/new=create"
+	if strings.Join(got, " ") != wantSteps {
+		t.Fatalf("steps = %v, want %s", got, wantSteps)
+	}
+	if d := steps[0].Diff; !strings.Contains(d, "-  role: web") || !strings.Contains(d, "+  role: db") {
+		t.Errorf("unexpected diff:\n%s", d)
+	}
+	if p := steps[2].desired.(*dto.BundlePolicy); p.Action != "DENY" {
+		t.Errorf("action = %q, want DENY", p.Action)
+	}
+
+	// Prune deletes the missing policy, the alert rule and the dedicated
+	// relay, but neither the shared relay nor anything it cannot own.
+	steps, err = planBundle(st, "ws", want, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var deleted []string
+	for _, s := range steps {
+		if s.Action == vo.BundleActionDelete {
+			deleted = append(deleted, s.Kind+"/"+s.Name)
+		}
+	}
+	if strings.Join(deleted, " ") != "Relay/own Policy/old AlertRule/cpu" {
+		t.Errorf("deleted = %v", deleted)
+	}
+}
+
+func TestPlanBundle_OnlyDedicatedRelays(t *testing.T) {
+	st := testBundleState()
+	want := &dto.Bundle{Relays: []dto.BundleRelay{
+		{Name: "own", Spec: v1alpha1.LatticeRelayServerSpec{TcpUrl: "r3:8080", Namespaces: []string{"ws"}}},
+		{Name: "shared", Spec: v1alpha1.LatticeRelayServerSpec{TcpUrl: "r4:8080", Namespaces: []string{"ws"}}},
+		{Name: "wide", Spec: v1alpha1.LatticeRelayServerSpec{TcpUrl: "r5:8080", Namespaces: []string{"ws", "other"}}},
+		{Name: "mine", Spec: v1alpha1.LatticeRelayServerSpec{TcpUrl: "r6:8080", Namespaces: []string{"ws"}}},
+	}}
+
+	steps, err := planBundle(st, "ws", want, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(steps))
+	for _, s := range steps {
+		got = append(got, s.Kind+"/"+s.Name+"="+s.Action)
+	}
+	wantSteps := "Relay/own=update Relay/shared=skip Relay/wide=skip Relay/mine=create"
+	if strings.Join(got, " ") != wantSteps {
+		t.Fatalf("steps = %v, want %s", got, wantSteps)
+	}
+}
+
+func TestPlanBundle_Invalid(t *testing.T) {
+	st := testBundleState()
+	for name, b := range map[string]*dto.Bundle{
+		"kind":      {Kind: "Other"},
+		"duplicate": {Policies: []dto.BundlePolicy{{Name: "a"}, {Name: "a"}}},
+		"unnamed":   {Peers: []dto.BundlePeer{{}}},
+		"action":    {Policies: []dto.BundlePolicy{{Name: "a", LatticePolicySpec: v1alpha1.LatticePolicySpec{Action: "MAYBE"}}}},
+	} {
+		if _, err := planBundle(st, "ws", b, false); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestBundleState_CheckChannels(t *testing.T) {
+	st := &bundleState{channelIDs: map[string]string{"ops": "c1"}}
+	ok := &dto.Bundle{AlertRules: []dto.BundleAlertRule{{Name: "cpu", Channels: []string{"ops"}}}}
+	if err := st.checkChannels(ok); err != nil {
+		t.Fatal(err)
+	}
+	if req := st.alertRuleRequest(&ok.AlertRules[0]); len(req.Channels) != 1 || req.Channels[0] != "c1" {
+		t.Errorf("channels = %v, want [c1]", req.Channels)
+	}
+	bad := &dto.Bundle{AlertRules: []dto.BundleAlertRule{{Name: "cpu", Channels: []string{"pager"}}}}
+	if err := st.checkChannels(bad); err == nil {
+		t.Error("expected unknown channel error")
+	}
+}
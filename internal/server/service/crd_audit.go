@@ -0,0 +1,362 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/log"
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/models"
+	"github.com/alatticeio/lattice/internal/server/resource"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// crdAttributionTTL is how long a user reported by the admission webhook
+	// waits for the informer event of the change it admitted.
+	crdAttributionTTL = time.Minute
+
+	// labelShadowPeer marks the synthetic peers the peering controllers
+	// create and delete on their own.
+	labelShadowPeer = "alattice.io/shadow"
+)
+
+// auditedCRD is a Lattice kind whose changes are recorded, the audit
+// resource name used for it, matching the names the API middleware uses, and
+// the spec fields that hold secrets. Secrets never reach the audit log, its
+// exports or its sinks; a change to one is recorded as redacted.
+type auditedCRD struct {
+	obj      client.Object
+	resource string
+	secrets  []string
+}
+
+var auditedCRDs = []auditedCRD{
+	{&v1alpha1.LatticePolicy{}, "policy", nil},
+	{&v1alpha1.LatticePeer{}, "peer", []string{"privateKey"}},
+	{&v1alpha1.LatticeNetwork{}, "network", nil},
+	{&v1alpha1.LatticeEnrollmentToken{}, "token", []string{"token"}},
+}
+
+// redactedValue stands in for a secret spec field in audit details.
+const redactedValue = "[redacted]"
+
+// CRDAuditService records changes to Lattice CRDs made directly against the
+// Kubernetes API — kubectl, GitOps or any other client — in the audit log, so
+// the log holds every change however it was made. Writes made by the manager
+// itself are already recorded by the API middleware and are skipped.
+type CRDAuditService interface {
+	// Start registers the informer handlers; call once before the manager
+	// starts. Objects that exist when the informers first sync are not
+	// recorded.
+	Start(ctx context.Context) error
+	// Review handles a request forwarded by the validating admission webhook.
+	// It remembers who made the request so the resulting change is attributed
+	// to that user, and always allows it.
+	Review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse
+}
+
+type crdAuditService struct {
+	client *resource.Client
+	audit  AuditService
+	store  store.Store
+	logger *log.Logger
+
+	// ignored are field managers of Lattice's own controllers.
+	ignored map[string]bool
+
+	mu           sync.Mutex
+	attributions map[string]crdAttribution
+}
+
+// crdAttribution is the requesting user of an admitted change.
+type crdAttribution struct {
+	user string
+	at   time.Time
+}
+
+func NewCRDAuditService(c *resource.Client, audit AuditService, st store.Store, cfg config.AuditConfig) CRDAuditService {
+	ignored := make(map[string]bool, len(cfg.IgnoreFieldManagers))
+	for _, m := range cfg.IgnoreFieldManagers {
+		ignored[m] = true
+	}
+	return &crdAuditService{
+		client:       c,
+		audit:        audit,
+		store:        st,
+		logger:       log.GetLogger("crd-audit"),
+		ignored:      ignored,
+		attributions: make(map[string]crdAttribution),
+	}
+}
+
+func (s *crdAuditService) Start(ctx context.Context) error {
+	for _, crd := range auditedCRDs {
+		informer, err := s.client.GetCache().GetInformer(ctx, crd.obj)
+		if err != nil {
+			return err
+		}
+		crd := crd
+		_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerDetailedFuncs{
+			AddFunc: func(obj interface{}, isInInitialList bool) {
+				if o, ok := obj.(client.Object); ok && !isInInitialList {
+					s.record(ctx, "CREATE", crd, nil, o)
+				}
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				o, ok1 := oldObj.(client.Object)
+				n, ok2 := newObj.(client.Object)
+				if ok1 && ok2 {
+					s.record(ctx, "UPDATE", crd, o, n)
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tomb, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+					obj = tomb.Obj
+				}
+				if o, ok := obj.(client.Object); ok {
+					s.record(ctx, "DELETE", crd, o, nil)
+				}
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *crdAuditService) Review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	resp := &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+	if req.Name == "" || (req.DryRun != nil && *req.DryRun) {
+		return resp
+	}
+	key := attributionKey(string(req.Operation), req.Kind.Kind, req.Namespace, req.Name)
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, a := range s.attributions {
+		if now.Sub(a.at) > crdAttributionTTL {
+			delete(s.attributions, k)
+		}
+	}
+	s.attributions[key] = crdAttribution{user: req.UserInfo.Username, at: now}
+	return resp
+}
+
+func attributionKey(op, kind, namespace, name string) string {
+	return op + "/" + kind + "/" + namespace + "/" + name
+}
+
+// attributedUser pops the user the webhook reported for this change.
+func (s *crdAuditService) attributedUser(op string, obj client.Object) string {
+	gvk, err := s.client.GroupVersionKindFor(obj)
+	if err != nil {
+		return ""
+	}
+	key := attributionKey(op, gvk.Kind, obj.GetNamespace(), obj.GetName())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.attributions[key]
+	if !ok {
+		return ""
+	}
+	delete(s.attributions, key)
+	if time.Since(a.at) > crdAttributionTTL {
+		return ""
+	}
+	return a.user
+}
+
+// crdAuditDetail is stored as the entry's Detail.
+type crdAuditDetail struct {
+	FieldManager string        `json:"fieldManager,omitempty"`
+	Changes      []fieldChange `json:"changes,omitempty"`
+	Before       any           `json:"before,omitempty"`
+	After        any           `json:"after,omitempty"`
+}
+
+// fieldChange is one changed leaf of a spec.
+type fieldChange struct {
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+func (s *crdAuditService) record(ctx context.Context, action string, crd auditedCRD, oldObj, newObj client.Object) {
+	obj := newObj
+	if obj == nil {
+		obj = oldObj
+	}
+	if obj.GetLabels()[labelShadowPeer] == "true" {
+		return
+	}
+	// Pop the attribution first, so one left by a change that is skipped
+	// below cannot be picked up by a later one.
+	user := s.attributedUser(action, obj)
+	if s.client.IsOwnWrite(obj, action == "DELETE") {
+		return
+	}
+
+	detail := crdAuditDetail{}
+	switch action {
+	case "CREATE":
+		detail.After = redactSpec(specOf(newObj), crd.secrets)
+	case "UPDATE":
+		diffFields("spec", specOf(oldObj), specOf(newObj), &detail.Changes)
+		if len(detail.Changes) == 0 {
+			// Status, label and annotation changes are not audited.
+			return
+		}
+		redactChanges(detail.Changes, crd.secrets)
+	case "DELETE":
+		detail.Before = redactSpec(specOf(oldObj), crd.secrets)
+		if s.namespaceGone(ctx, obj.GetNamespace()) {
+			// Removed with its workspace, which is audited on its own.
+			return
+		}
+	}
+	if action != "DELETE" {
+		detail.FieldManager = specManager(obj)
+		if s.ignored[detail.FieldManager] {
+			return
+		}
+	}
+	if user == "" {
+		user = detail.FieldManager
+	}
+
+	var wsID string
+	if ws, err := s.store.Workspaces().GetByNamespace(ctx, obj.GetNamespace()); err == nil {
+		wsID = ws.ID
+	}
+	raw, _ := json.Marshal(detail)
+
+	s.audit.Log(models.AuditLog{
+		UserName:     user,
+		WorkspaceID:  wsID,
+		Source:       models.AuditSourceKubernetes,
+		Action:       action,
+		Resource:     crd.resource,
+		ResourceID:   string(obj.GetUID()),
+		ResourceName: obj.GetName(),
+		Scope:        "namespace:" + obj.GetNamespace(),
+		Status:       "success",
+		Detail:       string(raw),
+	})
+	s.logger.Debug("out-of-band CRD change recorded", "action", action, "resource", crd.resource,
+		"namespace", obj.GetNamespace(), "name", obj.GetName(), "user", user)
+}
+
+// namespaceGone reports whether ns is being or has been deleted.
+func (s *crdAuditService) namespaceGone(ctx context.Context, ns string) bool {
+	var namespace corev1.Namespace
+	if err := s.client.Get(ctx, client.ObjectKey{Name: ns}, &namespace); err != nil {
+		return client.IgnoreNotFound(err) == nil
+	}
+	return !namespace.DeletionTimestamp.IsZero()
+}
+
+// specManager returns the field manager that most recently wrote obj's spec,
+// as recorded in its managedFields.
+func specManager(obj client.Object) string {
+	var name string
+	var at time.Time
+	for _, mf := range obj.GetManagedFields() {
+		if mf.Subresource != "" || mf.FieldsV1 == nil || !bytes.Contains(mf.FieldsV1.Raw, []byte(`"f:spec"`)) {
+			continue
+		}
+		var t time.Time
+		if mf.Time != nil {
+			t = mf.Time.Time
+		}
+		if name == "" || !t.Before(at) {
+			name, at = mf.Manager, t
+		}
+	}
+	return name
+}
+
+// specOf returns obj's spec as plain JSON values.
+func specOf(obj client.Object) any {
+	if obj == nil {
+		return nil
+	}
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil
+	}
+	return m["spec"]
+}
+
+// redactSpec replaces the secret fields of spec, a value returned by specOf.
+func redactSpec(spec any, secrets []string) any {
+	m, ok := spec.(map[string]any)
+	if !ok {
+		return spec
+	}
+	for _, k := range secrets {
+		if _, ok := m[k]; ok {
+			m[k] = redactedValue
+		}
+	}
+	return m
+}
+
+// redactChanges replaces the values of changes to secret fields, keeping
+// the fact that they changed.
+func redactChanges(changes []fieldChange, secrets []string) {
+	for i := range changes {
+		for _, k := range secrets {
+			if changes[i].Path != "spec."+k {
+				continue
+			}
+			if changes[i].Old != nil {
+				changes[i].Old = redactedValue
+			}
+			if changes[i].New != nil {
+				changes[i].New = redactedValue
+			}
+		}
+	}
+}
+
+// diffFields appends every leaf under path that differs between a and b.
+// Lists are compared as a whole.
+func diffFields(path string, a, b any, out *[]fieldChange) {
+	am, aok := a.(map[string]any)
+	bm, bok := b.(map[string]any)
+	if aok && bok {
+		keys := make([]string, 0, len(am)+len(bm))
+		for k := range am {
+			keys = append(keys, k)
+		}
+		for k := range bm {
+			if _, ok := am[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			diffFields(path+"."+k, am[k], bm[k], out)
+		}
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*out = append(*out, fieldChange{Path: path, Old: a, New: b})
+	}
+}
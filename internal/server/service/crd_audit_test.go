@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSpecManager_LatestSpecWriterWins(t *testing.T) {
+	t0 := metav1.NewTime(time.Now().Add(-time.Hour))
+	t1 := metav1.NewTime(time.Now())
+	policy := &v1alpha1.LatticePolicy{ObjectMeta: metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+		{Manager: "lattice-controller-manager", Operation: metav1.ManagedFieldsOperationApply, Time: &t0,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:action":{}}}`)}},
+		{Manager: "kubectl-edit", Operation: metav1.ManagedFieldsOperationUpdate, Time: &t1,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:network":{}}}`)}},
+		// Later, but only touches metadata or status.
+		{Manager: "kubectl-label", Operation: metav1.ManagedFieldsOperationUpdate, Time: &t1,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{}}}`)}},
+		{Manager: "manager", Operation: metav1.ManagedFieldsOperationUpdate, Time: &t1, Subresource: "status",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{}}`)}},
+	}}}
+
+	if got := specManager(policy); got != "kubectl-edit" {
+		t.Errorf("specManager = %q, want kubectl-edit", got)
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	old := &v1alpha1.LatticePolicy{Spec: v1alpha1.LatticePolicySpec{Action: "ALLOW", Network: "web"}}
+	updated := &v1alpha1.LatticePolicy{Spec: v1alpha1.LatticePolicySpec{Action: "DENY", Network: "web"}}
+
+	var changes []fieldChange
+	diffFields("spec", specOf(old), specOf(updated), &changes)
+	if len(changes) != 1 || changes[0].Path != "spec.action" || changes[0].Old != "ALLOW" || changes[0].New != "DENY" {
+		t.Fatalf("changes = %+v", changes)
+	}
+
+	changes = nil
+	diffFields("spec", specOf(old), specOf(old.DeepCopy()), &changes)
+	if len(changes) != 0 {
+		t.Errorf("unchanged spec reported %+v", changes)
+	}
+}
+
+func TestRedact_EnrollmentToken(t *testing.T) {
+	secrets := []string{"token"}
+	old := &v1alpha1.LatticeEnrollmentToken{Spec: v1alpha1.LatticeEnrollmentTokenSpec{Token: "s3cret", UsageLimit: 1}}
+	updated := &v1alpha1.LatticeEnrollmentToken{Spec: v1alpha1.LatticeEnrollmentTokenSpec{Token: "r0tated", UsageLimit: 5}}
+
+	spec := redactSpec(specOf(old), secrets).(map[string]any)
+	if spec["token"] != redactedValue || spec["usageLimit"] != int64(1) {
+		t.Errorf("spec = %+v", spec)
+	}
+
+	var changes []fieldChange
+	diffFields("spec", specOf(old), specOf(updated), &changes)
+	redactChanges(changes, secrets)
+	if len(changes) != 2 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	for _, c := range changes {
+		if c.Path == "spec.token" && (c.Old != redactedValue || c.New != redactedValue) {
+			t.Errorf("token change not redacted: %+v", c)
+		}
+	}
+}
@@ -2,23 +2,42 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/monitor"
+	"github.com/alatticeio/lattice/internal/monitor/adapter"
+	"github.com/alatticeio/lattice/internal/monitor/template"
 	"github.com/alatticeio/lattice/internal/server/models"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-// CustomMetricService handles custom metric CRUD operations.
+// ErrMonitorDisabled is returned when querying metrics without a monitor backend.
+var ErrMonitorDisabled = errors.New("monitoring is not configured")
+
+// CustomMetricService handles custom metrics. The monitor's template registry
+// resolves template.CustomMetricType(id) from the store on every lookup, so
+// the gateway and alert rules can use a metric like a built-in one and every
+// replica sees the same definition.
 type CustomMetricService struct {
-	store  store.Store
-	logger *log.Logger
+	store   store.Store
+	monitor *monitor.Monitor
+	logger  *log.Logger
 }
 
-// NewCustomMetricService creates a new CustomMetricService.
-func NewCustomMetricService(st store.Store) *CustomMetricService {
-	return &CustomMetricService{store: st, logger: log.GetLogger("custom-metric-service")}
+// NewCustomMetricService creates a new CustomMetricService. mon may be nil
+// when monitoring is disabled; metrics are then only stored.
+func NewCustomMetricService(st store.Store, mon *monitor.Monitor) *CustomMetricService {
+	s := &CustomMetricService{store: st, monitor: mon, logger: log.GetLogger("custom-metric-service")}
+	if mon != nil {
+		mon.Templates.SetResolver(s.resolve)
+	}
+	return s
 }
 
 // CreateCustomMetricRequest is the request body for creating/updating a custom metric.
@@ -30,6 +49,14 @@ type CreateCustomMetricRequest struct {
 	Labels     string `json:"labels"`
 }
 
+// QueryCustomMetricRequest selects an instant query, or a range query when
+// Start and End are set.
+type QueryCustomMetricRequest struct {
+	Start time.Time
+	End   time.Time
+	Step  time.Duration
+}
+
 // Create creates a new custom metric.
 func (s *CustomMetricService) Create(ctx context.Context, wsID, createdBy string, req CreateCustomMetricRequest) (*models.CustomMetric, error) {
 	m := &models.CustomMetric{
@@ -42,6 +69,9 @@ func (s *CustomMetricService) Create(ctx context.Context, wsID, createdBy string
 		Labels:      req.Labels,
 		CreatedBy:   createdBy,
 	}
+	if err := s.validate(ctx, m); err != nil {
+		return nil, err
+	}
 	if err := s.store.CustomMetrics().Create(ctx, m); err != nil {
 		return nil, fmt.Errorf("create custom metric: %w", err)
 	}
@@ -53,14 +83,21 @@ func (s *CustomMetricService) List(ctx context.Context, wsID string) ([]*models.
 	return s.store.CustomMetrics().ListByWorkspace(ctx, wsID)
 }
 
-// Get gets a single custom metric by ID.
-func (s *CustomMetricService) Get(ctx context.Context, id string) (*models.CustomMetric, error) {
-	return s.store.CustomMetrics().GetByID(ctx, id)
+// Get gets a single custom metric of a workspace by ID.
+func (s *CustomMetricService) Get(ctx context.Context, wsID, id string) (*models.CustomMetric, error) {
+	m, err := s.store.CustomMetrics().GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m.WorkspaceID != wsID {
+		return nil, fmt.Errorf("custom metric not found")
+	}
+	return m, nil
 }
 
 // Update updates an existing custom metric.
-func (s *CustomMetricService) Update(ctx context.Context, id string, req CreateCustomMetricRequest) (*models.CustomMetric, error) {
-	m, err := s.store.CustomMetrics().GetByID(ctx, id)
+func (s *CustomMetricService) Update(ctx context.Context, wsID, id string, req CreateCustomMetricRequest) (*models.CustomMetric, error) {
+	m, err := s.Get(ctx, wsID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +106,9 @@ func (s *CustomMetricService) Update(ctx context.Context, id string, req CreateC
 	m.Type = req.Type
 	m.ResultType = req.ResultType
 	m.Labels = req.Labels
+	if err := s.validate(ctx, m); err != nil {
+		return nil, err
+	}
 	if err := s.store.CustomMetrics().Update(ctx, m); err != nil {
 		return nil, err
 	}
@@ -76,6 +116,98 @@ func (s *CustomMetricService) Update(ctx context.Context, id string, req CreateC
 }
 
 // Delete deletes a custom metric by ID.
-func (s *CustomMetricService) Delete(ctx context.Context, id string) error {
+func (s *CustomMetricService) Delete(ctx context.Context, wsID, id string) error {
+	if _, err := s.Get(ctx, wsID, id); err != nil {
+		return err
+	}
 	return s.store.CustomMetrics().Delete(ctx, id)
 }
+
+// Query evaluates a custom metric through the monitor gateway.
+func (s *CustomMetricService) Query(ctx context.Context, wsID, id string, req QueryCustomMetricRequest) (any, error) {
+	if s.monitor == nil {
+		return nil, ErrMonitorDisabled
+	}
+	m, err := s.Get(ctx, wsID, id)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := s.store.Workspaces().GetByID(ctx, wsID)
+	if err != nil {
+		return nil, err
+	}
+	metricType := template.CustomMetricType(m.ID)
+	if req.Start.IsZero() || req.End.IsZero() {
+		return s.monitor.Gateway.Query(ctx, &adapter.QueryRequest{
+			MetricType: metricType,
+			Namespace:  ws.Namespace,
+			TimeRange:  adapter.TimeRange{End: req.End},
+		})
+	}
+	if req.Step <= 0 {
+		req.Step = time.Minute
+	}
+	return s.monitor.Gateway.QueryRange(ctx, &adapter.QueryRangeRequest{
+		MetricType: metricType,
+		Namespace:  ws.Namespace,
+		Start:      req.Start,
+		End:        req.End,
+		Step:       req.Step,
+	})
+}
+
+// validate fills in defaults and checks that the query stays inside the
+// workspace, so it is rejected at save time rather than at query time.
+func (s *CustomMetricService) validate(ctx context.Context, m *models.CustomMetric) error {
+	if m.Name == "" || m.Query == "" {
+		return fmt.Errorf("name and query are required")
+	}
+	if m.Type == "" {
+		m.Type = "instant"
+	}
+	if m.ResultType == "" {
+		m.ResultType = "scalar"
+	}
+	switch m.ResultType {
+	case "scalar", "table", "series":
+	default:
+		return fmt.Errorf("invalid result type %q", m.ResultType)
+	}
+	tpl, err := s.template(ctx, m)
+	if err != nil {
+		return err
+	}
+	if err := template.Validate(tpl); err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+	return nil
+}
+
+func (s *CustomMetricService) template(ctx context.Context, m *models.CustomMetric) (*template.MetricTemplate, error) {
+	ws, err := s.store.Workspaces().GetByID(ctx, m.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("load workspace: %w", err)
+	}
+	return &template.MetricTemplate{
+		Name:        template.CustomMetricType(m.ID),
+		Query:       m.Query,
+		Type:        m.Type,
+		ResultType:  m.ResultType,
+		Description: m.Name,
+		Namespace:   ws.Namespace,
+	}, nil
+}
+
+// resolve loads the template of a custom metric from the store.
+func (s *CustomMetricService) resolve(metricType string) (*template.MetricTemplate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m, err := s.store.CustomMetrics().GetByID(ctx, strings.TrimPrefix(metricType, template.CustomPrefix))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("metric template not found: %s: %w", metricType, template.ErrTemplateNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load custom metric: %w", err)
+	}
+	return s.template(ctx, m)
+}
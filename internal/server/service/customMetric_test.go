@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alatticeio/lattice/internal/monitor"
+	"github.com/alatticeio/lattice/internal/monitor/template"
+	"github.com/alatticeio/lattice/internal/server/models"
+)
+
+func TestCustomMetric_RegisteredAndTenantScoped(t *testing.T) {
+	ctx := context.Background()
+	st, _ := newTestStore(t)
+	for _, id := range []string{"a", "b"} {
+		if err := st.Workspaces().Create(ctx, &models.Workspace{Model: models.Model{ID: id}, Slug: id, Namespace: "wf-" + id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reg, err := template.NewRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewCustomMetricService(st, &monitor.Monitor{Templates: reg})
+
+	if _, err := s.Create(ctx, "a", "alice", CreateCustomMetricRequest{Name: "leak", Query: `up{network_id="wf-b"}`}); err == nil {
+		t.Error("query reading another workspace accepted")
+	}
+
+	m, err := s.Create(ctx, "a", "alice", CreateCustomMetricRequest{Name: "up", Query: `sum(up)`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != "instant" || m.ResultType != "scalar" {
+		t.Errorf("defaults not applied: %+v", m)
+	}
+	q, err := reg.Render(template.CustomMetricType(m.ID), map[string]any{"Namespace": "wf-a"})
+	if err != nil || q != `sum(up{network_id="wf-a"})` {
+		t.Errorf("registered query: %q, %v", q, err)
+	}
+	if _, err := reg.Render(template.CustomMetricType(m.ID), map[string]any{"Namespace": "wf-b"}); err == nil {
+		t.Error("custom metric rendered for another workspace")
+	}
+
+	if _, err := s.Update(ctx, "b", m.ID, CreateCustomMetricRequest{Name: "x", Query: "up"}); err == nil {
+		t.Error("updated through another workspace")
+	}
+	if err := s.Delete(ctx, "b", m.ID); err == nil {
+		t.Error("deleted through another workspace")
+	}
+
+	// Another replica shares only the store and sees changes at once.
+	other, err := template.NewRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewCustomMetricService(st, &monitor.Monitor{Templates: other})
+	if _, err := s.Update(ctx, "a", m.ID, CreateCustomMetricRequest{Name: "up", Query: `count(up)`}); err != nil {
+		t.Fatal(err)
+	}
+	q, err = other.Render(template.CustomMetricType(m.ID), map[string]any{"Namespace": "wf-a"})
+	if err != nil || q != `count(up{network_id="wf-a"})` {
+		t.Errorf("query on other replica: %q, %v", q, err)
+	}
+
+	if err := s.Delete(ctx, "a", m.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*template.TemplateRegistry{reg, other} {
+		if _, err := r.Get(template.CustomMetricType(m.ID)); !errors.Is(err, template.ErrTemplateNotFound) {
+			t.Errorf("deleted metric still resolved: %v", err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/log"
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/models"
+
+	"github.com/google/uuid"
+)
+
+// flowLogRetention is how long flow records are kept.
+const flowLogRetention = 30 * 24 * time.Hour
+
+// FlowLogService stores the flow records reported by agents and queries them.
+type FlowLogService interface {
+	// Record resolves the report's workspace and queues its records for
+	// async write; it never blocks on the database write.
+	Record(ctx context.Context, report *infra.FlowLogReport) error
+	// List returns a paginated, filtered list of flow records.
+	List(ctx context.Context, filter store.FlowLogFilter) ([]*models.FlowLog, int64, error)
+	// Start launches the background writer and the retention pruning; call
+	// once at startup.
+	Start(ctx context.Context)
+}
+
+type flowLogService struct {
+	store  store.Store
+	logger *log.Logger
+	ch     chan *models.FlowLog
+}
+
+func NewFlowLogService(st store.Store) FlowLogService {
+	return &flowLogService{
+		store:  st,
+		logger: log.GetLogger("flowlog"),
+		ch:     make(chan *models.FlowLog, 4096),
+	}
+}
+
+func (s *flowLogService) Record(ctx context.Context, report *infra.FlowLogReport) error {
+	if report.Namespace == "" {
+		return fmt.Errorf("flow log from %q has no namespace", report.AppID)
+	}
+	ws, err := s.store.Workspaces().GetByNamespace(ctx, report.Namespace)
+	if err != nil {
+		return fmt.Errorf("flow log: workspace of namespace %q: %w", report.Namespace, err)
+	}
+
+	now := time.Now()
+	for _, r := range report.Records {
+		entry := &models.FlowLog{
+			ID:            uuid.New().String(),
+			CreatedAt:     now,
+			WorkspaceID:   ws.ID,
+			Peer:          report.AppID,
+			IntervalStart: report.Start,
+			IntervalEnd:   report.End,
+			Direction:     r.Direction,
+			Protocol:      r.Protocol,
+			LocalIP:       r.LocalIP,
+			LocalPort:     r.LocalPort,
+			RemoteIP:      r.RemoteIP,
+			RemotePort:    r.RemotePort,
+			RemotePeer:    r.RemotePeer,
+			Verdict:       r.Verdict,
+			Policy:        r.Policy,
+			Established:   r.Established,
+			Packets:       r.Packets,
+			Bytes:         r.Bytes,
+		}
+		// Non-blocking: drop if the buffer is full rather than stalling the
+		// NATS handler.
+		select {
+		case s.ch <- entry:
+		default:
+			s.logger.Warn("flow log channel full, dropping record", "peer", report.AppID, "remote", r.RemoteIP)
+		}
+	}
+	return nil
+}
+
+func (s *flowLogService) List(ctx context.Context, filter store.FlowLogFilter) ([]*models.FlowLog, int64, error) {
+	return s.store.FlowLogs().List(ctx, filter)
+}
+
+// Start runs the background flush goroutine, batching up to 500 records or
+// flushing every second, and prunes expired records hourly.
+func (s *flowLogService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		prune := time.NewTicker(time.Hour)
+		defer prune.Stop()
+
+		buf := make([]*models.FlowLog, 0, 500)
+
+		flush := func() {
+			if len(buf) == 0 {
+				return
+			}
+			if err := s.store.FlowLogs().BatchCreate(context.Background(), buf); err != nil {
+				s.logger.Error("flow log flush failed", err)
+			}
+			buf = buf[:0]
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				// Drain remaining records before exit.
+				for {
+					select {
+					case e := <-s.ch:
+						buf = append(buf, e)
+					default:
+						flush()
+						return
+					}
+				}
+			case e := <-s.ch:
+				buf = append(buf, e)
+				if len(buf) >= 500 {
+					flush()
+				}
+			case <-ticker.C:
+				flush()
+			case <-prune.C:
+				n, err := s.store.FlowLogs().DeleteBefore(context.Background(), time.Now().Add(-flowLogRetention))
+				if err != nil {
+					s.logger.Error("flow log prune failed", err)
+				} else if n > 0 {
+					s.logger.Debug("flow log pruned", "records", n)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/log"
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/models"
+
+	"gorm.io/gorm"
+)
+
+// GroupSyncProvider is the identity provider whose groups drive memberships.
+const GroupSyncProvider = "dex"
+
+// GroupSyncService maps IdP groups to workspace memberships and platform
+// admin rights. Only memberships it created (source "group") are changed;
+// memberships added by hand are left alone.
+type GroupSyncService interface {
+	// SyncLogin records the groups an identity logged in with and
+	// re-evaluates the user's memberships.
+	SyncLogin(ctx context.Context, provider, subject string, groups []string) error
+	// Resync re-evaluates every identity against the current rules, dropping
+	// the groups of identities that have not logged in for MaxAgeHours.
+	Resync(ctx context.Context) error
+	// Start runs Resync in the background every IntervalSeconds.
+	Start(ctx context.Context)
+}
+
+type groupSyncService struct {
+	store  store.Store
+	cfg    config.DexConfig
+	logger *log.Logger
+	rules  []config.GroupMapping
+}
+
+// identityGroups is what group sync keeps in UserIdentity.Metadata.
+type identityGroups struct {
+	Groups []string `json:"groups"`
+	// Admin is set when platform_admin was granted through AdminGroups, so
+	// it is only taken back from users who got it that way.
+	Admin bool `json:"groupAdmin,omitempty"`
+}
+
+func NewGroupSyncService(st store.Store, cfg config.DexConfig) GroupSyncService {
+	s := &groupSyncService{
+		store:  st,
+		cfg:    cfg,
+		logger: log.GetLogger("group-sync"),
+	}
+	for _, r := range cfg.GroupMappings {
+		if r.Group == "" || r.Workspace == "" || dto.GetRoleWeight(dto.WorkspaceRole(r.Role)) == 0 {
+			s.logger.Warn("ignoring invalid group mapping", "group", r.Group, "workspace", r.Workspace, "role", r.Role)
+			continue
+		}
+		if _, err := path.Match(r.Group, ""); err != nil {
+			s.logger.Warn("ignoring group mapping with a bad pattern", "group", r.Group, "err", err)
+			continue
+		}
+		s.rules = append(s.rules, r)
+	}
+	return s
+}
+
+// GroupsFromClaims reads a groups claim from raw ID token claims. IdPs send
+// either a list or a single string.
+func GroupsFromClaims(claims map[string]any, claim string) []string {
+	switch v := claims[claim].(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []any:
+		groups := make([]string, 0, len(v))
+		for _, g := range v {
+			if s, ok := g.(string); ok && s != "" {
+				groups = append(groups, s)
+			}
+		}
+		return groups
+	}
+	return nil
+}
+
+func (s *groupSyncService) SyncLogin(ctx context.Context, provider, subject string, groups []string) error {
+	identity, err := s.store.UserIdentities().GetByProviderAndExternalID(ctx, provider, subject)
+	if err != nil {
+		return err
+	}
+	identity.LastSyncAt = time.Now()
+	return s.apply(ctx, identity, groups)
+}
+
+func (s *groupSyncService) Resync(ctx context.Context) error {
+	identities, err := s.store.UserIdentities().ListByProvider(ctx, GroupSyncProvider)
+	if err != nil {
+		return err
+	}
+	maxAge := time.Duration(s.cfg.GroupSync.MaxAgeHours) * time.Hour
+	for _, identity := range identities {
+		groups := decodeIdentityGroups(identity.Metadata).Groups
+		if maxAge > 0 && time.Since(identity.LastSyncAt) > maxAge {
+			groups = nil
+		}
+		if err := s.apply(ctx, identity, groups); err != nil {
+			s.logger.Warn("group sync failed", "user", identity.UserID, "err", err)
+		}
+	}
+	return nil
+}
+
+func (s *groupSyncService) Start(ctx context.Context) {
+	if s.cfg.GroupSync.IntervalSeconds <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(s.cfg.GroupSync.IntervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for {
+			if err := s.Resync(ctx); err != nil {
+				s.logger.Error("group resync failed", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// apply brings the user's group-managed memberships and platform admin
+// rights in line with groups, then stores groups on the identity.
+func (s *groupSyncService) apply(ctx context.Context, identity *models.UserIdentity, groups []string) error {
+	desired, err := s.desiredRoles(ctx, groups)
+	if err != nil {
+		return err
+	}
+	prev := decodeIdentityGroups(identity.Metadata)
+	next := identityGroups{Groups: groups}
+
+	return s.store.Tx(ctx, func(st store.Store) error {
+		user, err := st.Users().GetByID(ctx, identity.UserID)
+		if err != nil {
+			return err
+		}
+		if err := s.syncMemberships(ctx, st, user.ID, desired); err != nil {
+			return err
+		}
+
+		switch {
+		case matchesAny(s.cfg.AdminGroups, groups):
+			if user.SystemRole != dto.SystemRolePlatformAdmin {
+				user.SystemRole = dto.SystemRolePlatformAdmin
+				if err := st.Users().Update(ctx, user); err != nil {
+					return err
+				}
+				next.Admin = true
+			} else {
+				next.Admin = prev.Admin
+			}
+		case prev.Admin && user.SystemRole == dto.SystemRolePlatformAdmin && !containsFold(s.cfg.AdminEmails, user.Email):
+			user.SystemRole = dto.SystemRoleUser
+			if err := st.Users().Update(ctx, user); err != nil {
+				return err
+			}
+		}
+
+		raw, err := json.Marshal(next)
+		if err != nil {
+			return err
+		}
+		identity.Metadata = string(raw)
+		return st.UserIdentities().Update(ctx, identity)
+	})
+}
+
+// desiredRoles resolves the rules matching groups to workspace IDs, keeping
+// the highest role per workspace.
+func (s *groupSyncService) desiredRoles(ctx context.Context, groups []string) (map[string]dto.WorkspaceRole, error) {
+	desired := make(map[string]dto.WorkspaceRole)
+	for _, r := range s.rules {
+		if !matchesAny([]string{r.Group}, groups) {
+			continue
+		}
+		ws, err := s.store.Workspaces().GetByNamespace(ctx, r.Workspace)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("group mapping names an unknown workspace", "group", r.Group, "workspace", r.Workspace)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		role := dto.WorkspaceRole(r.Role)
+		if dto.GetRoleWeight(role) > dto.GetRoleWeight(desired[ws.ID]) {
+			desired[ws.ID] = role
+		}
+	}
+	return desired, nil
+}
+
+func (s *groupSyncService) syncMemberships(ctx context.Context, st store.Store, userID string, desired map[string]dto.WorkspaceRole) error {
+	members := st.WorkspaceMembers()
+	for wsID, role := range desired {
+		m, err := members.GetMembership(ctx, wsID, userID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		switch {
+		case m != nil && m.Status != models.MemberStatusRemoved && m.Source != models.MemberSourceGroup:
+			// Added by hand: not ours to change.
+			continue
+		case m != nil && m.Status != models.MemberStatusRemoved:
+			if m.Role != role {
+				if err := members.UpdateRole(ctx, wsID, userID, role); err != nil {
+					return err
+				}
+			}
+			continue
+		case m != nil:
+			// A removed membership is replaced by the group-managed one.
+			if err := members.RemoveMember(ctx, wsID, userID); err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		if err := members.AddMember(ctx, &models.WorkspaceMember{
+			WorkspaceID: wsID,
+			UserID:      userID,
+			Role:        role,
+			Status:      models.MemberStatusActive,
+			Source:      models.MemberSourceGroup,
+			JoinedAt:    &now,
+		}); err != nil {
+			return err
+		}
+	}
+
+	current, err := members.ListBySource(ctx, userID, models.MemberSourceGroup)
+	if err != nil {
+		return err
+	}
+	for _, m := range current {
+		if _, ok := desired[m.WorkspaceID]; ok {
+			continue
+		}
+		if err := members.RemoveMember(ctx, m.WorkspaceID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeIdentityGroups(metadata string) identityGroups {
+	var g identityGroups
+	if metadata != "" {
+		_ = json.Unmarshal([]byte(metadata), &g)
+	}
+	return g
+}
+
+// matchesAny reports whether any group matches any of the patterns.
+func matchesAny(patterns, groups []string) bool {
+	for _, p := range patterns {
+		for _, g := range groups {
+			if ok, _ := path.Match(p, g); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
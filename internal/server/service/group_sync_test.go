@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/config"
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/dto"
+	"github.com/alatticeio/lattice/internal/server/models"
+)
+
+func newTestGroupSync(t *testing.T, cfg config.DexConfig) (*groupSyncService, store.Store) {
+	t.Helper()
+	st, _ := newTestStore(t)
+	for _, ns := range []string{"wf-eng", "wf-ops"} {
+		if err := st.Workspaces().Create(context.Background(), &models.Workspace{Model: models.Model{ID: ns}, Slug: ns, Namespace: ns}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewGroupSyncService(st, cfg).(*groupSyncService), st
+}
+
+func onboard(t *testing.T, st store.Store, userID, subject string) {
+	t.Helper()
+	ctx := context.Background()
+	if err := st.Users().Create(ctx, &models.User{Model: models.Model{ID: userID}, Username: userID, Email: userID + "@example.com", SystemRole: dto.SystemRoleUser}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.UserIdentities().Create(ctx, &models.UserIdentity{UserID: userID, Provider: GroupSyncProvider, ExternalID: subject}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGroupSync_Login(t *testing.T) {
+	ctx := context.Background()
+	s, st := newTestGroupSync(t, config.DexConfig{
+		GroupMappings: []config.GroupMapping{
+			{Group: "eng-*", Workspace: "wf-eng", Role: "member"},
+			{Group: "eng-leads", Workspace: "wf-eng", Role: "admin"},
+			{Group: "sre", Workspace: "wf-ops", Role: "editor"},
+			{Group: "sre", Workspace: "wf-missing", Role: "editor"},
+			{Group: "bad", Workspace: "wf-ops", Role: "owner"},
+		},
+	})
+	if len(s.rules) != 4 {
+		t.Fatalf("invalid rule not dropped: %d rules", len(s.rules))
+	}
+	onboard(t, st, "u-1", "sub-1")
+
+	if err := s.SyncLogin(ctx, GroupSyncProvider, "sub-1", []string{"eng-backend", "eng-leads", "sre"}); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := st.WorkspaceMembers().GetMembership(ctx, "wf-eng", "u-1"); err != nil || m.Role != dto.RoleAdmin || m.Source != models.MemberSourceGroup {
+		t.Errorf("highest matching role not granted: %+v, %v", m, err)
+	}
+	if m, err := st.WorkspaceMembers().GetMembership(ctx, "wf-ops", "u-1"); err != nil || m.Role != dto.RoleEditor {
+		t.Errorf("ops membership: %+v, %v", m, err)
+	}
+
+	// Leaving groups lowers and removes access at the next login.
+	if err := s.SyncLogin(ctx, GroupSyncProvider, "sub-1", []string{"eng-backend"}); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := st.WorkspaceMembers().GetMembership(ctx, "wf-eng", "u-1"); err != nil || m.Role != dto.RoleMember {
+		t.Errorf("role not lowered: %+v, %v", m, err)
+	}
+	if _, err := st.WorkspaceMembers().GetMembership(ctx, "wf-ops", "u-1"); err == nil {
+		t.Error("membership kept after leaving the group")
+	}
+
+	identity, _ := st.UserIdentities().GetByProviderAndExternalID(ctx, GroupSyncProvider, "sub-1")
+	if got := decodeIdentityGroups(identity.Metadata).Groups; len(got) != 1 || got[0] != "eng-backend" {
+		t.Errorf("groups not recorded: %q", identity.Metadata)
+	}
+}
+
+func TestGroupSync_ManualMembershipsUntouched(t *testing.T) {
+	ctx := context.Background()
+	s, st := newTestGroupSync(t, config.DexConfig{
+		GroupMappings: []config.GroupMapping{{Group: "sre", Workspace: "wf-ops", Role: "viewer"}},
+	})
+	onboard(t, st, "u-1", "sub-1")
+	if err := st.WorkspaceMembers().AddMember(ctx, &models.WorkspaceMember{WorkspaceID: "wf-ops", UserID: "u-1", Role: dto.RoleAdmin, Status: models.MemberStatusActive}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, groups := range [][]string{{"sre"}, nil} {
+		if err := s.SyncLogin(ctx, GroupSyncProvider, "sub-1", groups); err != nil {
+			t.Fatal(err)
+		}
+		if m, err := st.WorkspaceMembers().GetMembership(ctx, "wf-ops", "u-1"); err != nil || m.Role != dto.RoleAdmin || m.Source != "" {
+			t.Errorf("groups %v changed a manual membership: %+v, %v", groups, m, err)
+		}
+	}
+}
+
+func TestGroupSync_AdminGroups(t *testing.T) {
+	ctx := context.Background()
+	s, st := newTestGroupSync(t, config.DexConfig{AdminGroups: []string{"platform"}})
+	onboard(t, st, "u-1", "sub-1")
+	onboard(t, st, "u-2", "sub-2")
+
+	if err := s.SyncLogin(ctx, GroupSyncProvider, "sub-1", []string{"platform"}); err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := st.Users().GetByID(ctx, "u-1"); u.SystemRole != dto.SystemRolePlatformAdmin {
+		t.Fatalf("admin group not promoted: %s", u.SystemRole)
+	}
+	if err := s.SyncLogin(ctx, GroupSyncProvider, "sub-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := st.Users().GetByID(ctx, "u-1"); u.SystemRole != dto.SystemRoleUser {
+		t.Errorf("not demoted after leaving the admin group: %s", u.SystemRole)
+	}
+
+	// Admins who did not get the role from a group keep it.
+	u2, _ := st.Users().GetByID(ctx, "u-2")
+	u2.SystemRole = dto.SystemRolePlatformAdmin
+	if err := st.Users().Update(ctx, u2); err != nil {
+		t.Fatal(err)
+	}
+	for _, groups := range [][]string{{"platform"}, nil} {
+		if err := s.SyncLogin(ctx, GroupSyncProvider, "sub-2", groups); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if u, _ := st.Users().GetByID(ctx, "u-2"); u.SystemRole != dto.SystemRolePlatformAdmin {
+		t.Errorf("admin granted by hand was demoted: %s", u.SystemRole)
+	}
+}
+
+func TestGroupSync_ResyncMaxAge(t *testing.T) {
+	ctx := context.Background()
+	s, st := newTestGroupSync(t, config.DexConfig{
+		GroupMappings: []config.GroupMapping{{Group: "sre", Workspace: "wf-ops", Role: "editor"}},
+		GroupSync:     config.GroupSyncConfig{MaxAgeHours: 24},
+	})
+	onboard(t, st, "u-1", "sub-1")
+	if err := s.SyncLogin(ctx, GroupSyncProvider, "sub-1", []string{"sre"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Resync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.WorkspaceMembers().GetMembership(ctx, "wf-ops", "u-1"); err != nil {
+		t.Fatalf("fresh login lost its membership: %v", err)
+	}
+
+	identity, _ := st.UserIdentities().GetByProviderAndExternalID(ctx, GroupSyncProvider, "sub-1")
+	identity.LastSyncAt = time.Now().Add(-25 * time.Hour)
+	if err := st.UserIdentities().Update(ctx, identity); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Resync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.WorkspaceMembers().GetMembership(ctx, "wf-ops", "u-1"); err == nil {
+		t.Error("membership kept past the max age")
+	}
+}
+
+func TestGroupsFromClaims(t *testing.T) {
+	claims := map[string]any{"groups": []any{"a", "", 3, "b"}, "team": "c"}
+	if got := GroupsFromClaims(claims, "groups"); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("list claim: %v", got)
+	}
+	if got := GroupsFromClaims(claims, "team"); len(got) != 1 || got[0] != "c" {
+		t.Errorf("string claim: %v", got)
+	}
+	if got := GroupsFromClaims(claims, "missing"); got != nil {
+		t.Errorf("missing claim: %v", got)
+	}
+}
@@ -51,6 +51,9 @@ type PeerService interface {
 	//Peer tenant
 	ListPeers(ctx context.Context, pageParam *dto.PageRequest) (*dto.PageResult[vo.PeerVo], error)
 	UpdatePeer(ctx context.Context, peerDto *dto.PeerDto) (*vo.PeerVo, error)
+	// UpdateLabels sets the given labels on a peer and leaves everything else
+	// as it is. A label with an empty value is removed.
+	UpdateLabels(ctx context.Context, namespace, name string, labels map[string]string) error
 	DisablePeer(ctx context.Context, namespace, name string) error
 	EnablePeer(ctx context.Context, namespace, name string) error
 	DeletePeer(ctx context.Context, namespace, name string) error
@@ -75,19 +78,7 @@ func (p *peerService) UpdatePeer(ctx context.Context, peerDto *dto.PeerDto) (*vo
 	}
 
 	// Update labels
-	peerLabels := peer.GetLabels()
-	if peerLabels == nil {
-		peerLabels = make(map[string]string)
-	}
-	if peerDto.Labels != nil {
-		for k, v := range peerDto.Labels {
-			if v == "" {
-				delete(peerLabels, k)
-			} else {
-				peerLabels[k] = v
-			}
-		}
-	}
+	peerLabels := mergeLabels(peer.GetLabels(), peerDto.Labels)
 	peer.SetLabels(peerLabels)
 
 	// Update display name annotation
@@ -102,6 +93,17 @@ func (p *peerService) UpdatePeer(ctx context.Context, peerDto *dto.PeerDto) (*vo
 	}
 	peer.SetAnnotations(annotations)
 
+	if peerDto.Bandwidth != nil {
+		if *peerDto.Bandwidth == (v1alpha1.BandwidthLimit{}) {
+			peer.Spec.Bandwidth = nil
+		} else {
+			peer.Spec.Bandwidth = peerDto.Bandwidth
+		}
+	}
+	if peerDto.QoSClass != nil {
+		peer.Spec.QoSClass = *peerDto.QoSClass
+	}
+
 	if err := p.client.Update(ctx, &peer); err != nil {
 		return nil, err
 	}
@@ -114,6 +116,8 @@ func (p *peerService) UpdatePeer(ctx context.Context, peerDto *dto.PeerDto) (*vo
 		PublicKey:   peer.Spec.PublicKey,
 		Platform:    peer.Spec.Platform,
 		Address:     peer.Status.AllocatedAddress,
+		Bandwidth:   peer.Spec.Bandwidth,
+		QoSClass:    peer.Spec.QoSClass,
 	}, nil
 }
 
@@ -210,6 +214,7 @@ func (p *peerService) ListPeers(ctx context.Context, pageParam *dto.PageRequest)
 				t := lastSeen.Format(time.RFC3339)
 				pv.LastSeen = &t
 			}
+			pv.Connections = p.presence.GetPaths(n.appId)
 		}
 		vos = append(vos, pv)
 	}
@@ -277,6 +282,32 @@ func (p *peerService) GetNetmap(ctx context.Context, token string, appId string)
 
 func (p *peerService) UpdateStatus(_ context.Context, _ int) error { return nil }
 
+func (p *peerService) UpdateLabels(ctx context.Context, namespace, name string, labels map[string]string) error {
+	var peer v1alpha1.LatticePeer
+	if err := p.client.GetAPIReader().Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &peer); err != nil {
+		return err
+	}
+	peer.SetLabels(mergeLabels(peer.GetLabels(), labels))
+	return p.client.Update(ctx, &peer)
+}
+
+// mergeLabels applies changes to a copy of current: a key with an empty value
+// is removed, any other is set.
+func mergeLabels(current, changes map[string]string) map[string]string {
+	out := make(map[string]string, len(current)+len(changes))
+	for k, v := range current {
+		out[k] = v
+	}
+	for k, v := range changes {
+		if v == "" {
+			delete(out, k)
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
 func (p *peerService) DisablePeer(ctx context.Context, namespace, name string) error {
 	var peer v1alpha1.LatticePeer
 	if err := p.client.GetAPIReader().Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &peer); err != nil {
@@ -80,6 +80,7 @@ func (s *peeringService) List(ctx context.Context) ([]vo.PeeringVo, error) {
 			Remote:      remoteEndpoint,
 			Status:      phaseToStatus(string(p.Status.Phase)),
 			PeeringMode: string(p.Spec.PeeringMode),
+			GatewayMode: string(p.Spec.GatewayMode),
 			CreatedAt:   p.CreationTimestamp.UTC().Format(time.RFC3339),
 		})
 	}
@@ -120,6 +121,10 @@ func (s *peeringService) Create(ctx context.Context, d *dto.PeeringDto) (*vo.Pee
 	if mode == "" {
 		mode = v1alpha1.PeeringModeGateway
 	}
+	gatewayMode := v1alpha1.GatewayMode(d.GatewayMode)
+	if gatewayMode == "" {
+		gatewayMode = v1alpha1.GatewayModeActiveStandby
+	}
 
 	peering := &v1alpha1.LatticeNetworkPeering{
 		ObjectMeta: metav1.ObjectMeta{
@@ -131,6 +136,7 @@ func (s *peeringService) Create(ctx context.Context, d *dto.PeeringDto) (*vo.Pee
 			NamespaceB:  nsB,
 			NetworkB:    netB,
 			PeeringMode: mode,
+			GatewayMode: gatewayMode,
 		},
 	}
 
@@ -147,6 +153,7 @@ func (s *peeringService) Create(ctx context.Context, d *dto.PeeringDto) (*vo.Pee
 		Remote:      s.buildEndpoint(ctx, nsB, netB),
 		Status:      "pending",
 		PeeringMode: string(mode),
+		GatewayMode: string(gatewayMode),
 		CreatedAt:   peering.CreationTimestamp.UTC().Format(time.RFC3339),
 	}
 	if result.CreatedAt == "0001-01-01T00:00:00Z" {
@@ -32,6 +32,10 @@ type PolicyService interface {
 	// Used for admin direct-create (POST) or direct-update (PUT).
 	ApplyDirect(ctx context.Context, wsID, operatorID, operatorName string, policyDto *dto.PolicyDto) (*vo.PolicyVo, error)
 
+	// Lint checks the policy against the current state of the workspace
+	// without saving it and returns the findings that concern it.
+	Lint(ctx context.Context, wsID string, policyDto *dto.PolicyDto) ([]vo.AuditFinding, error)
+
 	ListPolicy(ctx context.Context, pageParam *dto.PageRequest) (*dto.PageResult[vo.PolicyVo], error)
 	DeletePolicy(ctx context.Context, name string) error
 }
@@ -192,6 +196,12 @@ func (p *policyService) ApplyDirect(ctx context.Context, wsID, operatorID, opera
 		_ = p.store.Policies().Update(ctx, existing)
 	}
 
+	// Lint findings are advisory; the policy is applied either way.
+	warnings, err := p.lintPolicy(ctx, crd)
+	if err != nil {
+		p.log.Warn("policy lint failed", "policy", crd.Name, "err", err)
+	}
+
 	return &vo.PolicyVo{
 		Name:              policyDto.Name,
 		Action:            policyDto.Action,
@@ -199,9 +209,31 @@ func (p *policyService) ApplyDirect(ctx context.Context, wsID, operatorID, opera
 		Namespace:         policyDto.Namespace,
 		PolicyTypes:       policyDto.PolicyTypes,
 		LatticePolicySpec: &spec,
+		Warnings:          warnings,
 	}, nil
 }
 
+func (p *policyService) Lint(ctx context.Context, wsID string, policyDto *dto.PolicyDto) ([]vo.AuditFinding, error) {
+	workspace, err := p.store.Workspaces().GetByID(ctx, wsID)
+	if err != nil {
+		return nil, err
+	}
+	spec := policyDto.LatticePolicySpec
+	spec.Action = policyDto.Action
+	return p.lintPolicy(ctx, &v1alpha1.LatticePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: policyDto.Name, Namespace: workspace.Namespace},
+		Spec:       spec,
+	})
+}
+
+func (p *policyService) lintPolicy(ctx context.Context, policy *v1alpha1.LatticePolicy) ([]vo.AuditFinding, error) {
+	in, err := loadLintInput(ctx, p.client.GetAPIReader(), policy.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return lintPolicy(in, policy), nil
+}
+
 // ListPolicy reads from DB — the single source of truth for all policy states.
 func (p *policyService) ListPolicy(ctx context.Context, pageParam *dto.PageRequest) (*dto.PageResult[vo.PolicyVo], error) {
 	wsID, _ := ctx.Value(infra.WorkspaceKey).(string)
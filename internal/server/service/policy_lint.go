@@ -0,0 +1,620 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/log"
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/server/resource"
+	"github.com/alatticeio/lattice/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PolicyLintService checks the policies of a workspace for mistakes that can
+// be found from the policies, peers and networks alone, without an LLM.
+type PolicyLintService interface {
+	// Lint analyzes the current policies of the namespace.
+	Lint(ctx context.Context, namespace string) ([]AuditFinding, error)
+
+	// Report scores the findings of a workspace like the security audit.
+	Report(ctx context.Context, workspaceID string) (*AuditReport, error)
+
+	// Start lints every workspace on the given cron schedule and logs the
+	// findings. An empty schedule disables the scheduled runs.
+	Start(ctx context.Context, schedule string) error
+}
+
+type policyLintService struct {
+	logger *log.Logger
+	k8s    *resource.Client
+	store  store.Store
+}
+
+func NewPolicyLintService(k8s *resource.Client, st store.Store) PolicyLintService {
+	return &policyLintService{
+		logger: log.GetLogger("policy-lint"),
+		k8s:    k8s,
+		store:  st,
+	}
+}
+
+func (s *policyLintService) Lint(ctx context.Context, namespace string) ([]AuditFinding, error) {
+	if s.k8s == nil {
+		return nil, fmt.Errorf("kubernetes client not available")
+	}
+	in, err := loadLintInput(ctx, s.k8s.GetAPIReader(), namespace)
+	if err != nil {
+		return nil, err
+	}
+	return lintFindings(lintPolicies(in)), nil
+}
+
+func (s *policyLintService) Report(ctx context.Context, workspaceID string) (*AuditReport, error) {
+	ws, err := s.store.Workspaces().GetByID(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("workspace not found: %w", err)
+	}
+	findings, err := s.Lint(ctx, ws.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditReport{
+		Score:    auditScore(findings),
+		Findings: findings,
+	}, nil
+}
+
+func (s *policyLintService) Start(ctx context.Context, schedule string) error {
+	if schedule == "" {
+		return nil
+	}
+	sched, err := utils.ParseCron(schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	go func() {
+		for {
+			next := sched.Next(time.Now())
+			if next.IsZero() {
+				s.logger.Warn("policy lint schedule never fires", "schedule", schedule)
+				return
+			}
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			s.lintAll(ctx)
+		}
+	}()
+	return nil
+}
+
+// lintAll lints every workspace and logs the workspaces with findings. The
+// audit lints live, so nothing is kept.
+func (s *policyLintService) lintAll(ctx context.Context) {
+	const pageSize = 100
+	linted := 0
+	for page := 1; ; page++ {
+		workspaces, _, err := s.store.Workspaces().List(ctx, "", page, pageSize)
+		if err != nil {
+			s.logger.Error("policy lint: list workspaces failed", err)
+			return
+		}
+		for _, ws := range workspaces {
+			findings, err := s.Lint(ctx, ws.Namespace)
+			if err != nil {
+				s.logger.Warn("policy lint failed", "namespace", ws.Namespace, "err", err)
+				continue
+			}
+			linted++
+			if len(findings) > 0 {
+				s.logger.Warn("policy lint findings", "namespace", ws.Namespace, "findings", len(findings))
+			}
+		}
+		if len(workspaces) < pageSize {
+			break
+		}
+	}
+
+	s.logger.Info("policy lint finished", "workspaces", linted)
+}
+
+// ── Analyzer ──────────────────────────────────────────────────────────────────
+
+// lintInput is the workspace state the policy linter works on.
+type lintInput struct {
+	policies []v1alpha1.LatticePolicy
+	peers    []v1alpha1.LatticePeer
+	networks []v1alpha1.LatticeNetwork
+}
+
+func loadLintInput(ctx context.Context, reader client.Reader, namespace string) (*lintInput, error) {
+	var policies v1alpha1.LatticePolicyList
+	if err := reader.List(ctx, &policies, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("list policies: %w", err)
+	}
+	var peers v1alpha1.LatticePeerList
+	if err := reader.List(ctx, &peers, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("list peers: %w", err)
+	}
+	var networks v1alpha1.LatticeNetworkList
+	if err := reader.List(ctx, &networks, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("list networks: %w", err)
+	}
+	return &lintInput{policies: policies.Items, peers: peers.Items, networks: networks.Items}, nil
+}
+
+// lintIssue is a finding and the policies it is about.
+type lintIssue struct {
+	AuditFinding
+	policies []string
+}
+
+func lintFindings(issues []lintIssue) []AuditFinding {
+	findings := make([]AuditFinding, 0, len(issues))
+	for _, issue := range issues {
+		findings = append(findings, issue.AuditFinding)
+	}
+	return findings
+}
+
+// lintPolicy lints the workspace as if candidate were applied and returns
+// the findings that concern it.
+func lintPolicy(in *lintInput, candidate *v1alpha1.LatticePolicy) []AuditFinding {
+	next := *in
+	next.policies = []v1alpha1.LatticePolicy{*candidate}
+	for _, p := range in.policies {
+		if p.Name != candidate.Name {
+			next.policies = append(next.policies, p)
+		}
+	}
+	var findings []AuditFinding
+	for _, issue := range lintPolicies(&next) {
+		if slices.Contains(issue.policies, candidate.Name) {
+			findings = append(findings, issue.AuditFinding)
+		}
+	}
+	return findings
+}
+
+// lintRule is a policy rule with its selectors resolved against the peers
+// of the workspace, the way agents resolve them.
+type lintRule struct {
+	policy  string
+	ref     string // e.g. "ingress[0]"
+	allow   bool
+	egress  bool
+	targets map[string]bool // peers the policy applies to
+	remotes map[string]bool // peers the rule selects
+	cidrs   []netip.Prefix
+	ports   []v1alpha1.NetworkPolicyPort // empty matches all ports
+}
+
+type policyLinter struct {
+	peers    []v1alpha1.LatticePeer
+	networks map[string]*v1alpha1.LatticeNetwork
+	cidrs    []netip.Prefix // CIDRs of all networks
+	issues   []lintIssue
+}
+
+// lintPolicies runs every lint rule over the workspace. Findings are ordered
+// by resource.
+func lintPolicies(in *lintInput) []lintIssue {
+	l := &policyLinter{
+		peers:    slices.Clone(in.peers),
+		networks: make(map[string]*v1alpha1.LatticeNetwork, len(in.networks)),
+	}
+	sort.Slice(l.peers, func(i, j int) bool { return l.peers[i].Name < l.peers[j].Name })
+	for i := range in.networks {
+		n := &in.networks[i]
+		l.networks[n.Name] = n
+		if prefix, err := netip.ParsePrefix(n.Spec.CIDR); err == nil {
+			l.cidrs = append(l.cidrs, prefix.Masked())
+		}
+	}
+
+	policies := slices.Clone(in.policies)
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+	var rules []*lintRule
+	for i := range policies {
+		rules = append(rules, l.resolve(&policies[i])...)
+	}
+
+	l.checkOverlaps(rules)
+	if len(policies) > 0 {
+		l.checkReachability(rules)
+	}
+	sort.SliceStable(l.issues, func(i, j int) bool { return l.issues[i].Resource < l.issues[j].Resource })
+	return l.issues
+}
+
+func (l *policyLinter) add(severity, rule, resource, description, suggestion string, policies ...string) {
+	l.issues = append(l.issues, lintIssue{
+		AuditFinding: AuditFinding{
+			Severity:    severity,
+			Rule:        rule,
+			Resource:    resource,
+			Description: description,
+			Suggestion:  suggestion,
+		},
+		policies: policies,
+	})
+}
+
+// resolve checks the references of a policy and resolves its rules.
+func (l *policyLinter) resolve(p *v1alpha1.LatticePolicy) []*lintRule {
+	res := "policy/" + p.Name
+	known := l.cidrs
+	if p.Spec.Network != "" {
+		n, ok := l.networks[p.Spec.Network]
+		if !ok {
+			l.add("high", "missing-network", res,
+				fmt.Sprintf("The policy references network %q, which does not exist in the workspace, so its rules select no peers.", p.Spec.Network),
+				"Set spec.network to an existing network, or leave it empty to select peers of every network.",
+				p.Name)
+			return nil
+		}
+		known = nil
+		if prefix, err := netip.ParsePrefix(n.Spec.CIDR); err == nil {
+			known = []netip.Prefix{prefix.Masked()}
+		}
+	}
+
+	targets, ok := l.selectPeers(p, "peerSelector", &p.Spec.PeerSelector, "")
+	if !ok {
+		return nil
+	}
+	allow := p.Spec.Action == "" || strings.EqualFold(p.Spec.Action, "ALLOW")
+
+	var rules []*lintRule
+	for i, r := range p.Spec.Ingress {
+		rules = append(rules, l.resolveRule(p, fmt.Sprintf("ingress[%d]", i), "from", r.From, r.Ports, known, allow, false, targets))
+	}
+	for i, r := range p.Spec.Egress {
+		rules = append(rules, l.resolveRule(p, fmt.Sprintf("egress[%d]", i), "to", r.To, r.Ports, known, allow, true, targets))
+	}
+	return rules
+}
+
+func (l *policyLinter) resolveRule(p *v1alpha1.LatticePolicy, ref, field string, sel []v1alpha1.PeerSelection,
+	ports []v1alpha1.NetworkPolicyPort, known []netip.Prefix, allow, egress bool, targets map[string]bool) *lintRule {
+	rule := &lintRule{
+		policy:  p.Name,
+		ref:     ref,
+		allow:   allow,
+		egress:  egress,
+		targets: targets,
+		remotes: make(map[string]bool),
+		ports:   ports,
+	}
+	res := "policy/" + p.Name
+	for i, s := range sel {
+		where := fmt.Sprintf("%s.%s[%d]", ref, field, i)
+		if s.PeerSelector != nil {
+			matched, _ := l.selectPeers(p, where+".peerSelector", s.PeerSelector, p.Spec.Network)
+			for name := range matched {
+				rule.remotes[name] = true
+			}
+		}
+		if s.IPBlock == nil || strings.TrimSpace(s.IPBlock.CIDR) == "" {
+			continue
+		}
+		prefix, err := parseIPBlock(s.IPBlock.CIDR)
+		if err != nil {
+			l.add("high", "invalid-ipblock", res,
+				fmt.Sprintf("%s.ipBlock %q is not a valid CIDR.", where, s.IPBlock.CIDR),
+				"Use a CIDR such as 10.0.0.0/24.",
+				p.Name)
+			continue
+		}
+		if len(known) > 0 && !slices.ContainsFunc(known, func(n netip.Prefix) bool { return prefixWithin(prefix, n) }) {
+			l.add("low", "ipblock-outside-network", res,
+				fmt.Sprintf("%s.ipBlock %s is outside every network CIDR of the workspace (%s), so no peer has an address in it unless a gateway routes it.", where, prefix, joinPrefixes(known)),
+				"Check the CIDR for typos, or use a peerSelector to select peers.",
+				p.Name)
+		}
+		rule.cidrs = append(rule.cidrs, prefix)
+	}
+	return rule
+}
+
+// selectPeers returns the peers a selector matches, limited to the members of
+// network when it is set. ok is false if the selector is invalid.
+func (l *policyLinter) selectPeers(p *v1alpha1.LatticePolicy, where string, sel *metav1.LabelSelector, network string) (map[string]bool, bool) {
+	res := "policy/" + p.Name
+	selector, err := metav1.LabelSelectorAsSelector(sel)
+	if err != nil {
+		l.add("high", "invalid-selector", res,
+			fmt.Sprintf("%s is invalid: %v.", where, err),
+			"Fix the selector; agents skip rules whose selector cannot be parsed.",
+			p.Name)
+		return nil, false
+	}
+	matched := make(map[string]bool)
+	for _, peer := range l.peers {
+		if network != "" && peer.Labels[v1alpha1.NetworkLabelKey(network)] != "true" {
+			continue
+		}
+		if selector.Matches(labels.Set(peer.Labels)) {
+			matched[peer.Name] = true
+		}
+	}
+	if len(matched) == 0 && len(l.peers) > 0 {
+		desc := fmt.Sprintf("%s matches no peers.", where)
+		if where == "peerSelector" {
+			desc = "peerSelector matches no peers, so the policy applies to none."
+		} else if network != "" {
+			desc = fmt.Sprintf("%s matches no peers of network %q.", where, network)
+		}
+		l.add("medium", "empty-selector", res, desc,
+			"Check the labels in the selector against the labels of the peers.",
+			p.Name)
+	}
+	return matched, true
+}
+
+// checkOverlaps reports rules that another rule of the same action makes
+// redundant, and DENY rules that an ALLOW rule overrides. Each rule is
+// reported at most once per check.
+func (l *policyLinter) checkOverlaps(rules []*lintRule) {
+	for i, r := range rules {
+		if len(r.targets) == 0 || (len(r.remotes) == 0 && len(r.cidrs) == 0) {
+			continue
+		}
+		shadowed, conflicting := false, false
+		for j, o := range rules {
+			if i == j || o.egress != r.egress || len(o.targets) == 0 {
+				continue
+			}
+			switch {
+			case o.allow == r.allow:
+				// Of two identical rules, only the later one is reported.
+				if shadowed || !o.covers(r) || (j > i && r.covers(o)) {
+					continue
+				}
+				shadowed = true
+				by := o.ref
+				if o.policy != r.policy {
+					by = fmt.Sprintf("%s of policy/%s", o.ref, o.policy)
+				}
+				l.add("low", "shadowed-rule", "policy/"+r.policy,
+					fmt.Sprintf("%s is covered by %s: it matches no traffic that rule does not already match.", r.ref, by),
+					"Remove the redundant rule, or narrow the broader one if it matches more than intended.",
+					r.policy, o.policy)
+			case !r.allow && !conflicting:
+				flow, ports, ok := overlap(o, r)
+				if !ok {
+					continue
+				}
+				conflicting = true
+				l.add("medium", "allow-deny-conflict", "policy/"+r.policy,
+					fmt.Sprintf("DENY %s conflicts with ALLOW %s of policy/%s, e.g. for %s on %s. ALLOW takes precedence, so this traffic is not denied.", r.ref, o.ref, o.policy, flow, ports),
+					"Narrow the selectors or ports of the ALLOW rule, or remove the DENY rule if the traffic should be allowed.",
+					r.policy, o.policy)
+			}
+		}
+	}
+}
+
+// covers reports whether r matches all traffic that o matches.
+func (r *lintRule) covers(o *lintRule) bool {
+	for name := range o.targets {
+		if !r.targets[name] {
+			return false
+		}
+	}
+	for name := range o.remotes {
+		if !r.remotes[name] {
+			return false
+		}
+	}
+	for _, c := range o.cidrs {
+		if !slices.ContainsFunc(r.cidrs, func(n netip.Prefix) bool { return prefixWithin(c, n) }) {
+			return false
+		}
+	}
+	return portsWithin(o.ports, r.ports)
+}
+
+// overlap finds a peer pair and port that both rules match. flow describes
+// the pair, e.g. "web-1 ← db-1" for ingress rules.
+func overlap(a, b *lintRule) (flow, ports string, ok bool) {
+	ports, ok = portsOverlap(a.ports, b.ports)
+	if !ok {
+		return "", "", false
+	}
+	arrow := " ← "
+	if a.egress {
+		arrow = " → "
+	}
+	for _, t := range sortedNames(b.targets) {
+		if !a.targets[t] {
+			continue
+		}
+		for _, remote := range sortedNames(b.remotes) {
+			if remote != t && a.remotes[remote] {
+				return t + arrow + remote, ports, true
+			}
+		}
+		for _, c := range b.cidrs {
+			for _, o := range a.cidrs {
+				if c.Overlaps(o) {
+					return t + arrow + c.String(), ports, true
+				}
+			}
+		}
+	}
+	return "", "", false
+}
+
+// checkReachability reports peers that no other peer may send traffic to.
+// Agents drop traffic by default in both directions, so a flow needs an
+// ALLOW egress rule on the sender and an ALLOW ingress rule on the receiver.
+func (l *policyLinter) checkReachability(rules []*lintRule) {
+	if len(l.peers) < 2 {
+		return
+	}
+	ingress := make(map[string]map[string]bool) // receiver → senders
+	egress := make(map[string]map[string]bool)  // sender → receivers
+	for _, r := range rules {
+		if !r.allow {
+			continue
+		}
+		for t := range r.targets {
+			for remote := range r.remotes {
+				if remote == t {
+					continue
+				}
+				if r.egress {
+					addPair(egress, t, remote)
+				} else {
+					addPair(ingress, t, remote)
+				}
+			}
+		}
+	}
+	for _, peer := range l.peers {
+		if _, ok := peer.Labels["alattice.io/shadow"]; ok {
+			continue // stands in for a peer of a peered network
+		}
+		reachable := false
+		for sender := range ingress[peer.Name] {
+			if egress[sender][peer.Name] {
+				reachable = true
+				break
+			}
+		}
+		if !reachable {
+			l.add("low", "unreachable-peer", "peer/"+peer.Name,
+				"No peer may send traffic to this peer: no ALLOW policy permits both ingress on it and egress towards it.",
+				"Add ALLOW rules for the traffic it should receive, or remove the peer if it is unused.")
+		}
+	}
+}
+
+func addPair(m map[string]map[string]bool, a, b string) {
+	if m[a] == nil {
+		m[a] = make(map[string]bool)
+	}
+	m[a][b] = true
+}
+
+func sortedNames(m map[string]bool) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseIPBlock parses a CIDR. A bare address is taken as a single host, as
+// iptables does.
+func parseIPBlock(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// prefixWithin reports whether inner lies entirely in outer.
+func prefixWithin(inner, outer netip.Prefix) bool {
+	return outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+}
+
+func joinPrefixes(prefixes []netip.Prefix) string {
+	s := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		s[i] = p.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// portSpan returns the inclusive port range of p. Port 0 matches all ports.
+func portSpan(p v1alpha1.NetworkPolicyPort) (int32, int32) {
+	if p.Port == 0 {
+		return 0, 65535
+	}
+	return p.Port, max(p.Port, p.EndPort)
+}
+
+// protocolsOverlap reports whether two protocols match common traffic. An
+// empty protocol matches all.
+func protocolsOverlap(a, b string) bool {
+	return a == "" || b == "" || strings.EqualFold(a, b)
+}
+
+// portsWithin reports whether outer matches every port inner matches.
+func portsWithin(inner, outer []v1alpha1.NetworkPolicyPort) bool {
+	if len(outer) == 0 {
+		return true
+	}
+	if len(inner) == 0 {
+		return false
+	}
+	for _, i := range inner {
+		ilo, ihi := portSpan(i)
+		if !slices.ContainsFunc(outer, func(o v1alpha1.NetworkPolicyPort) bool {
+			olo, ohi := portSpan(o)
+			return (o.Protocol == "" || strings.EqualFold(o.Protocol, i.Protocol)) && olo <= ilo && ihi <= ohi
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// portsOverlap returns a port that both lists match.
+func portsOverlap(a, b []v1alpha1.NetworkPolicyPort) (string, bool) {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return "all ports", true
+	case len(a) == 0:
+		return portString(b[0]), true
+	case len(b) == 0:
+		return portString(a[0]), true
+	}
+	for _, x := range a {
+		xlo, xhi := portSpan(x)
+		for _, y := range b {
+			ylo, yhi := portSpan(y)
+			if protocolsOverlap(x.Protocol, y.Protocol) && xlo <= yhi && ylo <= xhi {
+				return portString(y), true
+			}
+		}
+	}
+	return "", false
+}
+
+func portString(p v1alpha1.NetworkPolicyPort) string {
+	proto := strings.ToUpper(p.Protocol)
+	if proto == "" {
+		proto = "any protocol"
+	}
+	switch {
+	case p.Port == 0:
+		return proto + " (all ports)"
+	case p.EndPort > p.Port:
+		return fmt.Sprintf("%s/%d-%d", proto, p.Port, p.EndPort)
+	default:
+		return fmt.Sprintf("%s/%d", proto, p.Port)
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func lintPeer(name string, lbls map[string]string) v1alpha1.LatticePeer {
+	l := map[string]string{v1alpha1.NetworkLabelKey("net"): "true"}
+	for k, v := range lbls {
+		l[k] = v
+	}
+	return v1alpha1.LatticePeer{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: l}}
+}
+
+func role(r string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: map[string]string{"role": r}}
+}
+
+func lintPolicyFixture(name, action string, target *metav1.LabelSelector, from []v1alpha1.PeerSelection, to []v1alpha1.PeerSelection, ports ...v1alpha1.NetworkPolicyPort) v1alpha1.LatticePolicy {
+	p := v1alpha1.LatticePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1alpha1.LatticePolicySpec{Network: "net", Action: action, PeerSelector: *target},
+	}
+	if from != nil {
+		p.Spec.Ingress = []v1alpha1.IngressRule{{From: from, Ports: ports}}
+	}
+	if to != nil {
+		p.Spec.Egress = []v1alpha1.EgressRule{{To: to, Ports: ports}}
+	}
+	return p
+}
+
+func testLintInput() *lintInput {
+	tcp := func(port int32) v1alpha1.NetworkPolicyPort {
+		return v1alpha1.NetworkPolicyPort{Protocol: "TCP", Port: port}
+	}
+	web := []v1alpha1.PeerSelection{{PeerSelector: role("web")}}
+	db := []v1alpha1.PeerSelection{{PeerSelector: role("db")}}
+	return &lintInput{
+		networks: []v1alpha1.LatticeNetwork{{
+			ObjectMeta: metav1.ObjectMeta{Name: "net"},
+			Spec:       v1alpha1.LatticeNetworkSpec{CIDR: "100.64.0.0/16"},
+		}},
+		peers: []v1alpha1.LatticePeer{
+			lintPeer("web-1", map[string]string{"role": "web"}),
+			lintPeer("db-1", map[string]string{"role": "db"}),
+			lintPeer("batch-1", map[string]string{"role": "batch"}),
+		},
+		policies: []v1alpha1.LatticePolicy{
+			// db accepts web on 5432, web may send to db.
+			lintPolicyFixture("db-in", "ALLOW", role("db"), web, nil, tcp(5432)),
+			lintPolicyFixture("web-out", "ALLOW", role("web"), nil, db),
+			// Redundant with db-in.
+			lintPolicyFixture("db-in-copy", "ALLOW", role("db"), web, nil, tcp(5432)),
+			// Overridden by db-in.
+			lintPolicyFixture("db-deny", "DENY", role("db"), web, nil, v1alpha1.NetworkPolicyPort{Protocol: "TCP", Port: 5000, EndPort: 6000}),
+			lintPolicyFixture("cache", "ALLOW", role("cache"), web, nil),
+			lintPolicyFixture("external", "ALLOW", role("web"), []v1alpha1.PeerSelection{
+				{IPBlock: &v1alpha1.IPBlock{CIDR: "10.0.0.0/8"}},
+				{IPBlock: &v1alpha1.IPBlock{CIDR: "100.64.1.0/24"}},
+				{IPBlock: &v1alpha1.IPBlock{CIDR: "not-a-cidr"}},
+			}, nil),
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "gone"},
+				Spec:       v1alpha1.LatticePolicySpec{Network: "old", Ingress: []v1alpha1.IngressRule{{From: web}}},
+			},
+		},
+	}
+}
+
+func TestLintPolicies(t *testing.T) {
+	var got []string
+	for _, issue := range lintPolicies(testLintInput()) {
+		got = append(got, issue.Rule+" "+issue.Resource)
+	}
+	want := []string{
+		"unreachable-peer peer/batch-1",
+		"unreachable-peer peer/web-1",
+		"empty-selector policy/cache",
+		"allow-deny-conflict policy/db-deny",
+		"shadowed-rule policy/db-in-copy",
+		"ipblock-outside-network policy/external",
+		"invalid-ipblock policy/external",
+		"missing-network policy/gone",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLintPolicies_Conflict(t *testing.T) {
+	for _, issue := range lintPolicies(testLintInput()) {
+		if issue.Rule != "allow-deny-conflict" {
+			continue
+		}
+		if !strings.Contains(issue.Description, "db-1 ← web-1 on TCP/5000-6000") {
+			t.Errorf("description = %q", issue.Description)
+		}
+		if strings.Join(issue.policies, ",") != "db-deny,db-in" {
+			t.Errorf("policies = %v", issue.policies)
+		}
+		return
+	}
+	t.Fatal("no conflict found")
+}
+
+func TestLintPolicy(t *testing.T) {
+	in := testLintInput()
+	// Replacing the copy with a narrower rule on another port leaves nothing
+	// to report about it.
+	candidate := lintPolicyFixture("db-in-copy", "ALLOW", role("db"), []v1alpha1.PeerSelection{{PeerSelector: role("batch")}}, nil,
+		v1alpha1.NetworkPolicyPort{Protocol: "TCP", Port: 22})
+	if findings := lintPolicy(in, &candidate); len(findings) != 0 {
+		t.Fatalf("unexpected findings: %+v", findings)
+	}
+
+	candidate = lintPolicyFixture("new", "ALLOW", role("nothing"), nil, nil)
+	findings := lintPolicy(in, &candidate)
+	if len(findings) != 1 || findings[0].Rule != "empty-selector" {
+		t.Fatalf("findings = %+v, want one empty-selector", findings)
+	}
+}
+
+func TestPortsWithin(t *testing.T) {
+	tcp := func(port, end int32) v1alpha1.NetworkPolicyPort {
+		return v1alpha1.NetworkPolicyPort{Protocol: "TCP", Port: port, EndPort: end}
+	}
+	cases := []struct {
+		inner, outer []v1alpha1.NetworkPolicyPort
+		want         bool
+	}{
+		{nil, nil, true},
+		{[]v1alpha1.NetworkPolicyPort{tcp(80, 0)}, nil, true},
+		{nil, []v1alpha1.NetworkPolicyPort{tcp(80, 0)}, false},
+		{[]v1alpha1.NetworkPolicyPort{tcp(80, 0)}, []v1alpha1.NetworkPolicyPort{tcp(1, 1024)}, true},
+		{[]v1alpha1.NetworkPolicyPort{tcp(80, 90)}, []v1alpha1.NetworkPolicyPort{tcp(85, 0)}, false},
+		{[]v1alpha1.NetworkPolicyPort{{Protocol: "UDP", Port: 53}}, []v1alpha1.NetworkPolicyPort{tcp(53, 0)}, false},
+		{[]v1alpha1.NetworkPolicyPort{{Protocol: "UDP", Port: 53}}, []v1alpha1.NetworkPolicyPort{{Port: 0}}, true},
+	}
+	for i, c := range cases {
+		if got := portsWithin(c.inner, c.outer); got != c.want {
+			t.Errorf("case %d: portsWithin = %v, want %v", i, got, c.want)
+		}
+	}
+}
@@ -7,10 +7,10 @@ import (
 	"github.com/alatticeio/lattice/internal/agent/infra"
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/relay"
 	"github.com/alatticeio/lattice/internal/server/dto"
 	"github.com/alatticeio/lattice/internal/server/resource"
 	"github.com/alatticeio/lattice/internal/server/vo"
-	"net"
 	"strings"
 	"time"
 
@@ -206,26 +206,53 @@ func (s *relayService) Delete(ctx context.Context, id string) error {
 // Test
 // --------------------------------------------------------------------------
 
-// Test performs a TCP dial to the relay's TcpUrl and reports latency.
+// Test runs the same LRP probe as the relay controller against each endpoint
+// and reports the fastest answer.
 func (s *relayService) Test(ctx context.Context, id string) (*vo.RelayTestVo, error) {
-	var relay v1alpha1.LatticeRelayServer
-	if err := s.client.Get(ctx, client.ObjectKey{Name: id}, &relay); err != nil {
+	var r v1alpha1.LatticeRelayServer
+	if err := s.client.Get(ctx, client.ObjectKey{Name: id}, &r); err != nil {
 		return nil, fmt.Errorf("relay get: %w", err)
 	}
 
-	addr := relay.Spec.TcpUrl
-	if addr == "" {
+	if r.Spec.TcpUrl == "" {
 		return &vo.RelayTestVo{OK: false, Error: "tcpUrl is empty"}, nil
 	}
 
-	start := time.Now()
-	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
-	latency := time.Since(start).Milliseconds()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var (
+		best *relay.ProbeResult
+		errs []string
+	)
+	res, err := relay.ProbeTCP(ctx, r.Spec.TcpUrl)
 	if err != nil {
-		return &vo.RelayTestVo{OK: false, LatencyMs: latency, Error: err.Error()}, nil
+		errs = append(errs, "tcp: "+err.Error())
+	} else {
+		best = res
+	}
+	if r.Spec.QuicUrl != "" {
+		res, err = relay.ProbeQUIC(ctx, r.Spec.QuicUrl)
+		if err != nil {
+			errs = append(errs, "quic: "+err.Error())
+		} else if best == nil || res.RTT < best.RTT {
+			best = res
+		}
+	}
+
+	if best == nil {
+		return &vo.RelayTestVo{OK: false, Error: strings.Join(errs, "; ")}, nil
+	}
+	out := &vo.RelayTestVo{
+		OK:        true,
+		LatencyMs: best.RTT.Milliseconds(),
+		Version:   best.Version,
+		Error:     strings.Join(errs, "; "),
+	}
+	if best.Sessions >= 0 {
+		out.Sessions = &best.Sessions
 	}
-	_ = conn.Close()
-	return &vo.RelayTestVo{OK: true, LatencyMs: latency}, nil
+	return out, nil
 }
 
 // --------------------------------------------------------------------------
@@ -265,6 +292,8 @@ func relayToVo(r *v1alpha1.LatticeRelayServer) *vo.RelayVo {
 		Enabled:        r.Spec.Enabled,
 		ConnectedPeers: r.Status.ConnectedPeers,
 		LatencyMs:      r.Status.LatencyMs,
+		Sessions:       r.Status.Sessions,
+		Version:        r.Status.Version,
 		Workspaces:     r.Spec.Namespaces,
 		CreatedAt:      r.CreationTimestamp.Time,
 	}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/alatticeio/lattice/internal/agent/store"
+	"github.com/alatticeio/lattice/internal/db/gormstore"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTestStore returns a store backed by an in-memory sqlite database.
+// In-memory sqlite is per connection, so the pool holds a single one and
+// concurrent callers share the same database.
+func newTestStore(t *testing.T) (store.Store, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	st, err := gormstore.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st, db
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// TelemetryService forwards the Remote Write batches agents send over NATS
+// to VictoriaMetrics.
+type TelemetryService interface {
+	// Forward sets the workspace labels of every series in payload to those
+	// of the sending peer and pushes the batch. Whatever the agent put in
+	// those labels is discarded, so a peer can only write to its own
+	// workspace.
+	Forward(ctx context.Context, namespace, peerID string, payload []byte) error
+}
+
+type telemetryService struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewTelemetryService returns a TelemetryService that pushes to the Remote
+// Write URL endpoint.
+func NewTelemetryService(endpoint string) TelemetryService {
+	return &telemetryService{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *telemetryService) Forward(ctx context.Context, namespace, peerID string, payload []byte) error {
+	if namespace == "" || peerID == "" {
+		return errors.New("telemetry: peer identity is required")
+	}
+	body, err := relabelRemoteWrite(payload, namespace, peerID)
+	if err != nil {
+		return fmt.Errorf("telemetry: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telemetry: push: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("telemetry: vm returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// maxTelemetryBatch bounds the decoded size of one Remote Write batch.
+const maxTelemetryBatch = 16 << 20
+
+// relabelRemoteWrite decodes a Snappy-compressed Remote Write request and
+// sets network_id and peer_id on every series. local_network_id, which the
+// peering metrics carry instead of network_id, is overwritten where present.
+// Fields other than labels pass through unchanged.
+//
+// Field numbers per prometheus/prompb:
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; ... }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; ... }
+//	Label        { string name = 1; string value = 2 }
+func relabelRemoteWrite(payload []byte, namespace, peerID string) ([]byte, error) {
+	n, err := s2.DecodedLen(payload)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxTelemetryBatch {
+		return nil, fmt.Errorf("batch of %d bytes is too large", n)
+	}
+	raw, err := s2.Decode(nil, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	for len(raw) > 0 {
+		num, typ, v, rest, err := consumeField(raw)
+		if err != nil {
+			return nil, err
+		}
+		if num == 1 && typ == protowire.BytesType {
+			ts, err := relabelSeries(v, namespace, peerID)
+			if err != nil {
+				return nil, err
+			}
+			out = protowire.AppendTag(out, 1, protowire.BytesType)
+			out = protowire.AppendBytes(out, ts)
+		} else {
+			out = append(out, raw[:len(raw)-len(rest)]...)
+		}
+		raw = rest
+	}
+	return s2.EncodeSnappy(nil, out), nil
+}
+
+func relabelSeries(ts []byte, namespace, peerID string) ([]byte, error) {
+	labels := map[string]string{}
+	var other []byte
+	for len(ts) > 0 {
+		num, typ, v, rest, err := consumeField(ts)
+		if err != nil {
+			return nil, err
+		}
+		if num == 1 && typ == protowire.BytesType {
+			name, value, err := parseLabel(v)
+			if err != nil {
+				return nil, err
+			}
+			labels[name] = value
+		} else {
+			other = append(other, ts[:len(ts)-len(rest)]...)
+		}
+		ts = rest
+	}
+
+	labels["network_id"] = namespace
+	labels["peer_id"] = peerID
+	if _, ok := labels["local_network_id"]; ok {
+		labels["local_network_id"] = namespace
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []byte
+	for _, name := range names {
+		var l []byte
+		l = protowire.AppendTag(l, 1, protowire.BytesType)
+		l = protowire.AppendString(l, name)
+		l = protowire.AppendTag(l, 2, protowire.BytesType)
+		l = protowire.AppendString(l, labels[name])
+		out = protowire.AppendTag(out, 1, protowire.BytesType)
+		out = protowire.AppendBytes(out, l)
+	}
+	return append(out, other...), nil
+}
+
+func parseLabel(b []byte) (name, value string, err error) {
+	for len(b) > 0 {
+		num, typ, v, rest, err := consumeField(b)
+		if err != nil {
+			return "", "", err
+		}
+		if typ == protowire.BytesType {
+			switch num {
+			case 1:
+				name = string(v)
+			case 2:
+				value = string(v)
+			}
+		}
+		b = rest
+	}
+	return name, value, nil
+}
+
+// consumeField reads one field from b. v holds the content of a
+// length-delimited field and is nil for other wire types.
+func consumeField(b []byte) (num protowire.Number, typ protowire.Type, v, rest []byte, err error) {
+	num, typ, n := protowire.ConsumeTag(b)
+	if n < 0 {
+		return 0, 0, nil, nil, protowire.ParseError(n)
+	}
+	if typ == protowire.BytesType {
+		v, m := protowire.ConsumeBytes(b[n:])
+		if m < 0 {
+			return 0, 0, nil, nil, protowire.ParseError(m)
+		}
+		return num, typ, v, b[n+m:], nil
+	}
+	m := protowire.ConsumeFieldValue(num, typ, b[n:])
+	if m < 0 {
+		return 0, 0, nil, nil, protowire.ParseError(m)
+	}
+	return num, typ, nil, b[n+m:], nil
+}
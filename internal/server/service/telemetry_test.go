@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klauspost/compress/s2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendLabel(b []byte, name, value string) []byte {
+	var l []byte
+	l = protowire.AppendTag(l, 1, protowire.BytesType)
+	l = protowire.AppendString(l, name)
+	l = protowire.AppendTag(l, 2, protowire.BytesType)
+	l = protowire.AppendString(l, value)
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, l)
+}
+
+func writeRequest(labels ...string) []byte {
+	var ts []byte
+	for i := 0; i < len(labels); i += 2 {
+		ts = appendLabel(ts, labels[i], labels[i+1])
+	}
+	var sample []byte
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(42))
+	sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, 1700000000000)
+	ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+	ts = protowire.AppendBytes(ts, sample)
+
+	var req []byte
+	req = protowire.AppendTag(req, 1, protowire.BytesType)
+	req = protowire.AppendBytes(req, ts)
+	return s2.EncodeSnappy(nil, req)
+}
+
+// seriesLabels decodes the labels of the first series of a Remote Write body.
+func seriesLabels(t *testing.T, body []byte) (map[string]string, []string) {
+	t.Helper()
+	raw, err := s2.Decode(nil, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, ts, _, err := consumeField(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := map[string]string{}
+	var order []string
+	for len(ts) > 0 {
+		num, _, v, rest, err := consumeField(ts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if num == 1 {
+			name, value, _ := parseLabel(v)
+			labels[name] = value
+			order = append(order, name)
+		}
+		ts = rest
+	}
+	return labels, order
+}
+
+func TestRelabelRemoteWrite(t *testing.T) {
+	in := writeRequest(
+		"__name__", "lattice_node_uptime_seconds",
+		"network_id", "other-ns",
+		"peer_id", "spoofed",
+		"local_network_id", "other-ns",
+	)
+	out, err := relabelRemoteWrite(in, "ns-a", "peer-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels, order := seriesLabels(t, out)
+	if labels["network_id"] != "ns-a" || labels["local_network_id"] != "ns-a" || labels["peer_id"] != "peer-1" {
+		t.Errorf("labels = %v", labels)
+	}
+	if labels["__name__"] != "lattice_node_uptime_seconds" {
+		t.Errorf("metric name lost: %v", labels)
+	}
+	for i := 1; i < len(order); i++ {
+		if order[i-1] > order[i] {
+			t.Errorf("labels not sorted: %v", order)
+		}
+	}
+
+	if _, err := relabelRemoteWrite([]byte("not snappy"), "ns-a", "peer-1"); err == nil {
+		t.Error("expected error for a malformed batch")
+	}
+}
+
+func TestTelemetryService_Forward(t *testing.T) {
+	var got []byte
+	vm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "snappy" {
+			t.Errorf("content encoding = %q", r.Header.Get("Content-Encoding"))
+		}
+		got, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer vm.Close()
+
+	svc := NewTelemetryService(vm.URL)
+	if err := svc.Forward(context.Background(), "ns-a", "peer-1", writeRequest("__name__", "m")); err != nil {
+		t.Fatal(err)
+	}
+	labels, _ := seriesLabels(t, got)
+	if labels["network_id"] != "ns-a" || labels["peer_id"] != "peer-1" {
+		t.Errorf("labels = %v", labels)
+	}
+
+	if err := svc.Forward(context.Background(), "", "peer-1", writeRequest()); err == nil {
+		t.Error("expected error without a namespace")
+	}
+}
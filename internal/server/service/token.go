@@ -16,6 +16,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// Enrollment tokens created from the console expire after a week and admit
+// up to five peers.
+const (
+	defaultTokenExpiry = "168h"
+	defaultTokenLimit  = 5
+)
+
 type TokenService interface {
 	Create(ctx context.Context) (string, error)
 	Delete(ctx context.Context, token string) error
@@ -68,8 +75,8 @@ func (t *tokenService) Create(ctx context.Context) (string, error) {
 
 	tokenDto := dto.TokenDto{
 		Namespace: workspace.Namespace,
-		Expiry:    "168h",
-		Limit:     5,
+		Expiry:    defaultTokenExpiry,
+		Limit:     defaultTokenLimit,
 		Name:      tokenStr,
 	}
 
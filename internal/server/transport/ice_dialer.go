@@ -332,6 +332,16 @@ func (i *iceDialer) Dial(ctx context.Context) (infra.Transport, error) {
 			return nil, err
 		}
 		remoteAddr := iceConn.RemoteAddr().String()
+		transport := &ICETransport{remoteAddr: remoteAddr}
+		// Capture the selected pair before the agent is torn down below; it is
+		// only reported for visibility and never affects the data path.
+		if pair, err := i.agent.GetSelectedCandidatePair(); err == nil && pair != nil {
+			transport.localCandidate = pair.Local.Type().String()
+			transport.remoteCandidate = pair.Remote.Type().String()
+		}
+		if stats, ok := i.agent.GetSelectedCandidatePairStats(); ok {
+			transport.rtt = time.Duration(stats.CurrentRoundTripTime * float64(time.Second))
+		}
 		// Close the ICE conn and dialer after a brief delay to let final STUN
 		// checks complete.  Calling i.Close() sets closed=true and clears i.agent,
 		// so any late SYN retries from the remote's ticker are dropped rather than
@@ -341,7 +351,7 @@ func (i *iceDialer) Dial(ctx context.Context) (infra.Transport, error) {
 			iceConn.Close() //nolint:errcheck
 			i.Close()       //nolint:errcheck
 		}()
-		return &ICETransport{remoteAddr: remoteAddr}, nil
+		return transport, nil
 	}
 }
 
@@ -528,7 +538,20 @@ var (
 )
 
 type ICETransport struct {
-	remoteAddr string
+	remoteAddr      string
+	localCandidate  string
+	remoteCandidate string
+	rtt             time.Duration
+}
+
+// CandidatePair returns the local and remote candidate types of the selected pair.
+func (i *ICETransport) CandidatePair() (local, remote string) {
+	return i.localCandidate, i.remoteCandidate
+}
+
+// RTT returns the round-trip time measured during connectivity checks.
+func (i *ICETransport) RTT() time.Duration {
+	return i.rtt
 }
 
 func (i *ICETransport) Priority() uint8 {
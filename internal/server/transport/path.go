@@ -0,0 +1,132 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+// maxPathHistory bounds the number of path changes kept per peer.
+const maxPathHistory = 16
+
+// candidatePairer is implemented by transports that know which ICE candidate
+// pair was selected and how long a connectivity check round trip took.
+type candidatePairer interface {
+	CandidatePair() (local, remote string)
+	RTT() time.Duration
+}
+
+// pathTracker records the connection path history of a single Probe.
+// The zero value is ready to use.
+type pathTracker struct {
+	mu   sync.Mutex
+	info infra.PeerPath
+
+	// onChange, when set, is called outside the lock for every new history entry.
+	onChange func(ev infra.PathEvent)
+	notified time.Time
+}
+
+// onTransport records that t is now the active transport.
+func (pt *pathTracker) onTransport(t infra.Transport, reason string) {
+	pt.mu.Lock()
+	defer pt.notify()
+	defer pt.mu.Unlock()
+
+	pt.info.Path = infra.PathKindOf(t.Type())
+	pt.info.Endpoint = t.RemoteAddr()
+	pt.info.LocalCandidate, pt.info.RemoteCandidate, pt.info.RTTMillis = "", "", 0
+	if cp, ok := t.(candidatePairer); ok {
+		pt.info.LocalCandidate, pt.info.RemoteCandidate = cp.CandidatePair()
+		pt.info.RTTMillis = float64(cp.RTT().Microseconds()) / 1000
+	}
+	pt.info.LastError = ""
+	pt.appendLocked(pt.info.Path, reason)
+}
+
+// onFailure records that discovery failed and no transport is active.
+func (pt *pathTracker) onFailure(err error) {
+	pt.mu.Lock()
+	defer pt.notify()
+	defer pt.mu.Unlock()
+
+	if err != nil {
+		pt.info.LastError = err.Error()
+	}
+	pt.clearLocked(pt.info.LastError)
+}
+
+// onReset records that the active transport was torn down on purpose.
+func (pt *pathTracker) onReset(reason string) {
+	pt.mu.Lock()
+	defer pt.notify()
+	defer pt.mu.Unlock()
+
+	pt.clearLocked(reason)
+}
+
+// clearLocked drops the active path. Caller holds mu.
+func (pt *pathTracker) clearLocked(reason string) {
+	pt.info.Path = infra.PathNone
+	pt.info.Endpoint = ""
+	pt.info.LocalCandidate, pt.info.RemoteCandidate, pt.info.RTTMillis = "", "", 0
+	pt.appendLocked(infra.PathNone, reason)
+}
+
+// notify reports the newest history entry if it has not been reported yet.
+func (pt *pathTracker) notify() {
+	pt.mu.Lock()
+	fn := pt.onChange
+	var ev infra.PathEvent
+	fresh := false
+	if n := len(pt.info.History); n > 0 && !pt.info.History[n-1].At.Equal(pt.notified) {
+		ev = pt.info.History[n-1]
+		pt.notified = ev.At
+		fresh = true
+	}
+	pt.mu.Unlock()
+	if fresh && fn != nil {
+		fn(ev)
+	}
+}
+
+// appendLocked adds a history entry if the path kind changed. Caller holds mu.
+func (pt *pathTracker) appendLocked(to infra.PathKind, reason string) {
+	from := infra.PathNone
+	if n := len(pt.info.History); n > 0 {
+		from = pt.info.History[n-1].To
+	}
+	if from == to && len(pt.info.History) > 0 {
+		return
+	}
+	now := time.Now()
+	pt.info.LastChange = now
+	pt.info.History = append(pt.info.History, infra.PathEvent{At: now, From: from, To: to, Reason: reason})
+	if len(pt.info.History) > maxPathHistory {
+		pt.info.History = pt.info.History[len(pt.info.History)-maxPathHistory:]
+	}
+}
+
+// snapshot returns a deep copy of the tracked path.
+func (pt *pathTracker) snapshot() infra.PeerPath {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	out := pt.info
+	out.History = append([]infra.PathEvent(nil), pt.info.History...)
+	return out
+}
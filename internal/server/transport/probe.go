@@ -69,14 +69,40 @@ type Probe struct {
 	// firstFailureAt tracks consecutive failure duration for 60s timeout.
 	muFail         sync.Mutex
 	firstFailureAt time.Time
+
+	// path records the user-visible connection path and its history.
+	path pathTracker
+
+	// iceInFlight is true while an ICE dialer is between Prepare and the end
+	// of Dial; guarded by mu. It keeps re-probes from replacing a dialer that
+	// is still negotiating.
+	iceInFlight bool
+
+	// relayTransport returns a transport for the WRRP relay, or nil when the
+	// relay is unavailable. Used to fall back when the direct path stalls.
+	relayTransport func() infra.Transport
+
+	// Re-probe and stall-detection bookkeeping; see reprobe.go.
+	muReprobe      sync.Mutex
+	reprobeAttempt int
+	nextReprobeAt  time.Time
+	directSince    time.Time
 }
 
 func (p *Probe) Handle(ctx context.Context, remoteId infra.PeerIdentity, packet *grpc.SignalPacket) error {
 	switch packet.Dialer {
 	case grpc.DialerType_ICE:
+		// A SYN while relayed means the initiator is re-probing for a direct
+		// path; answer it with a fresh dialer unless one is still negotiating.
+		if packet.Type == grpc.PacketType_HANDSHAKE_SYN && p.sm.Current() == StateWRRPReady {
+			p.startUpgradeAttempt()
+		}
 		p.mu.RLock()
 		d := p.iceDialer
 		p.mu.RUnlock()
+		if d == nil {
+			return nil
+		}
 		return d.Handle(ctx, p.remoteId, packet)
 	case grpc.DialerType_WRRP:
 		p.mu.RLock()
@@ -108,6 +134,28 @@ func (p *Probe) restart() {
 	_ = p.Start(context.Background(), p.remoteId)
 }
 
+// Reconnect drops the current transport and re-runs discovery with fresh
+// dialers. The old dialers are closed after the new ones are installed so
+// any in-flight discover() result is discarded by the epoch check.
+func (p *Probe) Reconnect() {
+	p.mu.RLock()
+	oldIce, oldWrrp := p.iceDialer, p.wrrpDialer
+	p.mu.RUnlock()
+
+	p.path.onReset("reconnect requested")
+	// Failed → Probing is the only way back into discovery; the transition
+	// also removes the WireGuard peer so no traffic uses the stale endpoint.
+	_ = p.sm.Transition(StateFailed)
+	p.restart()
+
+	if oldIce != nil {
+		oldIce.Close() //nolint:errcheck
+	}
+	if oldWrrp != nil {
+		oldWrrp.Close() //nolint:errcheck
+	}
+}
+
 // Close permanently stops this probe.
 func (p *Probe) Close() {
 	p.mu.Lock()
@@ -169,6 +217,15 @@ func (p *Probe) Start(ctx context.Context, remoteId infra.PeerIdentity) error {
 	return nil
 }
 
+// Path returns a snapshot of the current connection path to the remote peer.
+func (p *Probe) Path() infra.PeerPath {
+	out := p.path.snapshot()
+	out.AppID = p.remoteId.AppID
+	out.PublicKey = p.remoteId.PublicKey.String()
+	out.State = p.sm.Current().String()
+	return out
+}
+
 func (p *Probe) Ping(ctx context.Context) error {
 	return nil
 }
@@ -178,17 +235,22 @@ func (p *Probe) onSuccess(transport infra.Transport) {
 	p.mu.Lock()
 	p.currentTransport = transport
 	p.mu.Unlock()
+	p.path.onTransport(transport, "connected")
 
 	transportType := transport.Type()
 	if transportType == infra.ICE {
+		p.markDirect()
 		_ = p.sm.Transition(StateICEReady)
 	} else {
+		p.resetReprobe()
 		_ = p.sm.Transition(StateWRRPReady)
 	}
 }
 
 // onFailure handles discovery failure.
 func (p *Probe) onFailure(err error) {
+	p.path.onFailure(err)
+
 	// ErrDialerClosed: clean session transition, restart immediately.
 	if errors.Is(err, ErrDialerClosed) {
 		p.muFail.Lock()
@@ -231,12 +293,18 @@ func (p *Probe) discover(ctx context.Context) (infra.Transport, error) {
 
 	go func() {
 		p.log.Debug("Starting ice dialer", "remoteId", p.remoteId)
-		if err := p.iceDialer.Prepare(ctx, p.remoteId); err != nil {
+		p.mu.Lock()
+		d := p.iceDialer
+		p.iceInFlight = true
+		p.mu.Unlock()
+		defer p.iceDone(d)
+
+		if err := d.Prepare(ctx, p.remoteId); err != nil {
 			p.log.Error("Prepare failed", err)
 			errs <- err
 			return
 		}
-		t, err := p.iceDialer.Dial(ctx)
+		t, err := d.Dial(ctx)
 		if err != nil {
 			errs <- err
 			return
@@ -298,6 +366,8 @@ func (p *Probe) handleUpgradeTransport(newTransport infra.Transport) error {
 	old := p.currentTransport
 	p.currentTransport = newTransport
 	p.mu.Unlock()
+	p.path.onTransport(newTransport, "upgraded from relay")
+	p.markDirect()
 
 	// Close old after delay.
 	if old != nil {
@@ -18,9 +18,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/alatticeio/lattice/internal/agent/config"
 	"github.com/alatticeio/lattice/internal/agent/infra"
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"github.com/alatticeio/lattice/internal/agent/provision"
@@ -38,6 +41,8 @@ type ProbeFactory struct {
 	getProvisioner func() provision.Provisioner
 	getOnMessage   func() func(context.Context, *infra.Message) error
 	getWrrp        func() infra.Wrrp
+	onPathChange   func(appId string, ev infra.PathEvent)
+	getHandshakes  func() map[string]time.Time
 
 	log *log.Logger
 
@@ -58,6 +63,12 @@ type ProbeFactoryConfig struct {
 	FilteringMux6  *infra.FilteringUDPMux
 	GetProvisioner func() provision.Provisioner
 	ShowLog        bool
+	// OnPathChange, when set, is called whenever a peer's connection path
+	// changes (connected, upgraded, failed or reset).
+	OnPathChange func(appId string, ev infra.PathEvent)
+	// GetHandshakes returns the last WireGuard handshake time keyed by peer
+	// public key. Used by RunPathMonitor to detect stalled direct paths.
+	GetHandshakes func() map[string]time.Time
 }
 
 func NewProbeFactory(cfg *ProbeFactoryConfig) *ProbeFactory {
@@ -73,6 +84,8 @@ func NewProbeFactory(cfg *ProbeFactoryConfig) *ProbeFactory {
 		FilteringMux6:  cfg.FilteringMux6,
 		getProvisioner: cfg.GetProvisioner,
 		getOnMessage:   cfg.GetOnMessage,
+		onPathChange:   cfg.OnPathChange,
+		getHandshakes:  cfg.GetHandshakes,
 	}
 }
 
@@ -99,6 +112,64 @@ func (f *ProbeFactory) Get(remoteId infra.PeerIdentity) (*Probe, error) {
 	return f.NewProbe(remoteId)
 }
 
+// Paths returns a snapshot of the connection path to every known peer,
+// sorted by AppID.
+func (f *ProbeFactory) Paths() []infra.PeerPath {
+	f.mu.RLock()
+	probes := make([]*Probe, 0, len(f.probes))
+	for _, probe := range f.probes {
+		probes = append(probes, probe)
+	}
+	f.mu.RUnlock()
+
+	paths := make([]infra.PeerPath, 0, len(probes))
+	for _, probe := range probes {
+		paths = append(paths, probe.Path())
+	}
+	sort.Slice(paths, func(i, j int) bool { return paths[i].AppID < paths[j].AppID })
+	return paths
+}
+
+// RunPathMonitor periodically re-probes relayed peers for a direct path and
+// moves stalled direct peers back to the relay. It blocks until ctx is done.
+func (f *ProbeFactory) RunPathMonitor(ctx context.Context) {
+	ticker := time.NewTicker(maintainInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			var handshakes map[string]time.Time
+			if f.getHandshakes != nil {
+				handshakes = f.getHandshakes()
+			}
+			f.mu.RLock()
+			probes := make([]*Probe, 0, len(f.probes))
+			for _, probe := range f.probes {
+				probes = append(probes, probe)
+			}
+			f.mu.RUnlock()
+			for _, probe := range probes {
+				hs, known := handshakes[probe.remoteId.PublicKey.String()]
+				probe.maintain(now, hs, known)
+			}
+		}
+	}
+}
+
+// Reconnect forces discovery to restart for the peer identified by appId.
+func (f *ProbeFactory) Reconnect(appId string) error {
+	f.mu.RLock()
+	probe := f.probes[appId]
+	f.mu.RUnlock()
+	if probe == nil {
+		return fmt.Errorf("no connection to peer %q", appId)
+	}
+	probe.Reconnect()
+	return nil
+}
+
 func (f *ProbeFactory) Remove(appId string) {
 	f.mu.Lock()
 	probe := f.probes[appId]
@@ -180,12 +251,18 @@ func (a *wgConfigAdapter) SetupNAT(iface string) error {
 func (p *ProbeFactory) NewProbe(remoteId infra.PeerIdentity) (*Probe, error) {
 	getLocalPeer := func() *infra.Peer {
 		lp := p.peerManager.GetPeer(p.localId.AppID)
-		if lp != nil && lp.AllowedIPs == "" && lp.Address != nil {
-			lpCopy := *lp
+		if lp == nil {
+			return nil
+		}
+		lpCopy := *lp
+		if lpCopy.AllowedIPs == "" && lp.Address != nil {
 			lpCopy.AllowedIPs = fmt.Sprintf("%s/32", *lp.Address)
-			return &lpCopy
 		}
-		return lp
+		// Advertise our relays so the remote can pin the pair to a shared one.
+		if rs, ok := p.getWrrp().(infra.RelaySelector); ok {
+			lpCopy.Relays = rs.Preferences()
+		}
+		return &lpCopy
 	}
 
 	var mu sync.Mutex
@@ -243,6 +320,10 @@ func (p *ProbeFactory) NewProbe(remoteId infra.PeerIdentity) (*Probe, error) {
 		p.peerManager.AddPeer(peer.AppID, &peer)
 		remotePeer = &peer
 		mu.Unlock()
+		if rs, ok := p.getWrrp().(infra.RelaySelector); ok {
+			relay := rs.Pin(remoteId.ID().ToUint64(), peer.Relays, isInitiator(p.localId, remoteId))
+			p.log.Debug("relay pinned", "remoteId", remoteId.AppID, "relay", relay)
+		}
 		onPeerKnown(peer)
 	}
 
@@ -318,6 +399,14 @@ func (p *ProbeFactory) NewProbe(remoteId infra.PeerIdentity) (*Probe, error) {
 				p.log.Error("transition: SetEndpoint (upgrade) failed", err)
 			}
 
+		// Direct path stalled: point WireGuard at the relay. The peer entry,
+		// route and NAT are already in place from the first transport.
+		case from == StateICEReady && to == StateWRRPReady:
+			endpoint := infra.WrrpFakeAddrPort(remoteId.ID().ToUint64()).String()
+			if err := configurator.SetEndpoint(pubKey, endpoint, persistentKA); err != nil {
+				p.log.Error("transition: SetEndpoint (downgrade) failed", err)
+			}
+
 		// Failed or Closed: clean up WireGuard peer.
 		case to == StateFailed || to == StateClosed:
 			if err := configurator.RemovePeer(pubKey); err != nil {
@@ -347,6 +436,19 @@ func (p *ProbeFactory) NewProbe(remoteId infra.PeerIdentity) (*Probe, error) {
 		sm:           sm,
 		configurator: configurator,
 	}
+	if p.onPathChange != nil {
+		probe.path.onChange = func(ev infra.PathEvent) { p.onPathChange(remoteId.AppID, ev) }
+	}
+	probe.relayTransport = func() infra.Transport {
+		if !config.Conf.EnableWrrp {
+			return nil
+		}
+		w := p.getWrrp()
+		if w == nil {
+			return nil
+		}
+		return &WrrpTransport{remoteAddr: relayAddr(w, remoteId)}
+	}
 
 	makeIceDialer := func() infra.Dialer {
 		return NewIceDialer(&ICEDialerConfig{
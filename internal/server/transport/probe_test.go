@@ -169,3 +169,33 @@ func TestStateMachine_RejectedTransition_NoCallback(t *testing.T) {
 		t.Error("OnTransition should not be called on rejected transition")
 	}
 }
+
+func TestProbe_Path_TracksUpgradeAndFailure(t *testing.T) {
+	sm := NewStateMachine(StateProbing)
+	p := &Probe{
+		sm:  sm,
+		log: log.GetLogger("test-probe"),
+	}
+
+	p.onSuccess(&mockTransport{tp: infra.WRRP, addr: "relay:6266"})
+	if got := p.Path(); got.Path != infra.PathRelay || got.State != StateWRRPReady.String() {
+		t.Fatalf("expected relayed path in wrrp-ready, got %+v", got)
+	}
+
+	if err := p.handleUpgradeTransport(&mockTransport{tp: infra.ICE, addr: "5.6.7.8:6000"}); err != nil {
+		t.Fatalf("handleUpgradeTransport error: %v", err)
+	}
+	got := p.Path()
+	if got.Path != infra.PathDirect || got.Endpoint != "5.6.7.8:6000" {
+		t.Fatalf("expected direct path after upgrade, got %+v", got)
+	}
+	if len(got.History) != 2 || got.History[1].From != infra.PathRelay || got.History[1].To != infra.PathDirect {
+		t.Fatalf("expected relay -> direct history entry, got %+v", got.History)
+	}
+
+	p.path.onFailure(errors.New("ice failed"))
+	got = p.Path()
+	if got.Path != infra.PathNone || got.LastError != "ice failed" {
+		t.Errorf("expected no path with last error, got %+v", got)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+)
+
+// Path maintenance: relayed peers periodically retry ICE so that a NAT
+// mapping that was not ready during the first race can still be used, and
+// direct peers fall back to the relay when WireGuard stops completing
+// handshakes over the direct path.
+const (
+	// reprobeInitialDelay is the wait before the first ICE re-attempt after
+	// settling on the relay. It doubles after every failed attempt up to
+	// reprobeMaxDelay.
+	reprobeInitialDelay = 30 * time.Second
+	reprobeMaxDelay     = 10 * time.Minute
+
+	// reprobeDialTimeout bounds a single background ICE attempt. It matches
+	// the SYN window of iceDialer.Prepare.
+	reprobeDialTimeout = 60 * time.Second
+
+	// directStallThreshold is the handshake age after which a direct path
+	// is considered dead. WireGuard re-handshakes every 2 minutes on an
+	// active session and rejects keys after 3 minutes.
+	directStallThreshold = 3 * time.Minute
+
+	// directGracePeriod prevents a fresh upgrade from being judged on the
+	// handshake that was completed over the previous path.
+	directGracePeriod = directStallThreshold
+
+	// maintainInterval is how often ProbeFactory.RunPathMonitor checks probes.
+	maintainInterval = 10 * time.Second
+)
+
+// reprobeBackoff returns the delay before re-probe attempt n (0-based).
+func reprobeBackoff(n int) time.Duration {
+	d := reprobeInitialDelay
+	for i := 0; i < n && d < reprobeMaxDelay; i++ {
+		d *= 2
+	}
+	if d > reprobeMaxDelay {
+		d = reprobeMaxDelay
+	}
+	return d
+}
+
+// resetReprobe restarts the re-probe schedule; called whenever the probe
+// settles on the relay.
+func (p *Probe) resetReprobe() {
+	p.muReprobe.Lock()
+	p.reprobeAttempt = 0
+	p.nextReprobeAt = time.Now().Add(reprobeBackoff(0))
+	p.muReprobe.Unlock()
+}
+
+// markDirect records when the direct path was (re)established.
+func (p *Probe) markDirect() {
+	p.muReprobe.Lock()
+	p.directSince = time.Now()
+	p.muReprobe.Unlock()
+}
+
+// maintain runs one path-maintenance step. lastHandshake is the time of the
+// most recent WireGuard handshake with the remote peer (zero if none); known
+// is false when the WireGuard state of the peer could not be read, in which
+// case stall detection is skipped.
+func (p *Probe) maintain(now, lastHandshake time.Time, known bool) {
+	switch p.sm.Current() {
+	case StateWRRPReady:
+		// Only the initiator drives re-probes; the responder follows its SYN.
+		if !isInitiator(p.localId, p.remoteId) {
+			return
+		}
+		p.muReprobe.Lock()
+		due := !p.nextReprobeAt.IsZero() && now.After(p.nextReprobeAt)
+		if due {
+			p.reprobeAttempt++
+			p.nextReprobeAt = now.Add(reprobeBackoff(p.reprobeAttempt))
+		}
+		p.muReprobe.Unlock()
+		if due {
+			p.log.Debug("re-probing direct path", "remoteId", p.remoteId.AppID)
+			p.startUpgradeAttempt()
+		}
+
+	case StateICEReady:
+		p.muReprobe.Lock()
+		since := p.directSince
+		p.muReprobe.Unlock()
+		if !known || since.IsZero() || now.Sub(since) < directGracePeriod {
+			return
+		}
+		if !lastHandshake.IsZero() && now.Sub(lastHandshake) < directStallThreshold {
+			return
+		}
+		p.downgrade("direct path stalled: no WireGuard handshake")
+	}
+}
+
+// startUpgradeAttempt installs a fresh ICE dialer and dials in the
+// background while the relay keeps carrying traffic. It is a no-op when an
+// ICE dialer is already negotiating or the probe is closed.
+func (p *Probe) startUpgradeAttempt() {
+	p.mu.Lock()
+	if p.iceInFlight || p.newIceDialer == nil {
+		p.mu.Unlock()
+		return
+	}
+	old := p.iceDialer
+	d := p.newIceDialer()
+	p.iceDialer = d
+	p.iceInFlight = true
+	p.mu.Unlock()
+
+	if old != nil {
+		old.Close() //nolint:errcheck
+	}
+
+	epoch := p.epoch.Load()
+	go func() {
+		defer p.iceDone(d)
+		ctx, cancel := context.WithTimeout(context.Background(), reprobeDialTimeout)
+		defer cancel()
+
+		if err := d.Prepare(ctx, p.remoteId); err != nil {
+			p.log.Debug("re-probe prepare failed", "remoteId", p.remoteId.AppID, "err", err)
+			return
+		}
+		t, err := d.Dial(ctx)
+		if err != nil {
+			p.log.Debug("re-probe failed, staying on relay", "remoteId", p.remoteId.AppID, "err", err)
+			return
+		}
+		if p.epoch.Load() != epoch || p.sm.Current() != StateWRRPReady {
+			t.Close() //nolint:errcheck
+			return
+		}
+		if err = p.handleUpgradeTransport(t); err != nil {
+			p.log.Error("Upgrade transport failed", err)
+		}
+	}()
+}
+
+// iceDone clears the in-flight flag if d is still the active ICE dialer.
+func (p *Probe) iceDone(d infra.Dialer) {
+	p.mu.Lock()
+	if p.iceDialer == d {
+		p.iceInFlight = false
+	}
+	p.mu.Unlock()
+}
+
+// downgrade moves a stalled direct peer back onto the relay, or restarts
+// discovery when no relay is available.
+func (p *Probe) downgrade(reason string) {
+	var relay infra.Transport
+	if p.relayTransport != nil {
+		relay = p.relayTransport()
+	}
+	if relay == nil {
+		p.log.Info("direct path stalled and no relay available, reconnecting", "remoteId", p.remoteId.AppID)
+		p.Reconnect()
+		return
+	}
+
+	p.log.Info("direct path stalled, falling back to relay", "remoteId", p.remoteId.AppID)
+	p.mu.Lock()
+	p.currentTransport = relay
+	p.mu.Unlock()
+	p.path.onTransport(relay, reason)
+	p.resetReprobe()
+	// ICEReady → WRRPReady: WG endpoint is rewritten by the transition callback.
+	_ = p.sm.Transition(StateWRRPReady)
+}
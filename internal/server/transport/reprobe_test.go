@@ -0,0 +1,89 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alatticeio/lattice/internal/agent/infra"
+	"github.com/alatticeio/lattice/internal/agent/log"
+)
+
+func TestReprobeBackoff(t *testing.T) {
+	cases := []struct {
+		n    int
+		want time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, reprobeMaxDelay},
+		{50, reprobeMaxDelay},
+	}
+	for _, c := range cases {
+		if got := reprobeBackoff(c.n); got != c.want {
+			t.Errorf("reprobeBackoff(%d) = %s, want %s", c.n, got, c.want)
+		}
+	}
+}
+
+func TestProbe_maintain_DowngradesStalledDirectPath(t *testing.T) {
+	sm := NewStateMachine(StateProbing)
+	relay := &mockTransport{tp: infra.WRRP, addr: "relay:6266"}
+	p := &Probe{
+		sm:             sm,
+		log:            log.GetLogger("test-probe"),
+		relayTransport: func() infra.Transport { return relay },
+	}
+	p.onSuccess(&mockTransport{tp: infra.ICE, addr: "5.6.7.8:6000"})
+
+	now := time.Now()
+	// Inside the grace period a missing handshake is not a stall.
+	p.maintain(now, time.Time{}, true)
+	if got := sm.Current(); got != StateICEReady {
+		t.Fatalf("expected ice-ready during grace period, got %s", got)
+	}
+
+	later := now.Add(directGracePeriod + time.Second)
+	// A recent handshake keeps the direct path.
+	p.maintain(later, later.Add(-time.Minute), true)
+	if got := sm.Current(); got != StateICEReady {
+		t.Fatalf("expected ice-ready with recent handshake, got %s", got)
+	}
+	// Unknown WireGuard state never triggers a downgrade.
+	p.maintain(later, time.Time{}, false)
+	if got := sm.Current(); got != StateICEReady {
+		t.Fatalf("expected ice-ready with unknown handshake, got %s", got)
+	}
+
+	p.maintain(later, later.Add(-directStallThreshold-time.Second), true)
+	if got := sm.Current(); got != StateWRRPReady {
+		t.Fatalf("expected wrrp-ready after stall, got %s", got)
+	}
+	if p.currentTransport != relay {
+		t.Errorf("currentTransport should be the relay after downgrade")
+	}
+	if got := p.Path(); got.Path != infra.PathRelay {
+		t.Errorf("expected relayed path after downgrade, got %+v", got)
+	}
+	p.muReprobe.Lock()
+	next := p.nextReprobeAt
+	p.muReprobe.Unlock()
+	if next.IsZero() {
+		t.Errorf("expected re-probe to be scheduled after downgrade")
+	}
+}
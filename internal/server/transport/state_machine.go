@@ -44,7 +44,7 @@ var allowedTransitions = map[PeerState][]PeerState{
 	StateCreated:   {StateProbing},
 	StateProbing:   {StateICEReady, StateWRRPReady, StateFailed},
 	StateWRRPReady: {StateICEReady, StateFailed, StateClosed},
-	StateICEReady:  {StateFailed, StateClosed},
+	StateICEReady:  {StateWRRPReady, StateFailed, StateClosed},
 	StateFailed:    {StateProbing, StateClosed},
 }
 
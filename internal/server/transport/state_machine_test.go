@@ -21,6 +21,7 @@ func TestStateMachine_ValidTransitions(t *testing.T) {
 		{"wrrp-ready→ice-ready", StateWRRPReady, StateICEReady, false},
 		{"wrrp-ready→failed", StateWRRPReady, StateFailed, false},
 		{"wrrp-ready→closed", StateWRRPReady, StateClosed, false},
+		{"ice-ready→wrrp-ready", StateICEReady, StateWRRPReady, false},
 		{"ice-ready→failed", StateICEReady, StateFailed, false},
 		{"ice-ready→closed", StateICEReady, StateClosed, false},
 		{"failed→probing", StateFailed, StateProbing, false},
@@ -29,7 +30,6 @@ func TestStateMachine_ValidTransitions(t *testing.T) {
 		{"created→ice-ready", StateCreated, StateICEReady, true},
 		{"created→failed", StateCreated, StateFailed, true},
 		{"ice-ready→probing", StateICEReady, StateProbing, true},
-		{"ice-ready→wrrp-ready", StateICEReady, StateWRRPReady, true},
 		{"closed→probing", StateClosed, StateProbing, true},
 		{"closed→ice-ready", StateClosed, StateICEReady, true},
 	}
@@ -22,6 +22,7 @@ import (
 	"github.com/alatticeio/lattice/internal/agent/log"
 	"github.com/alatticeio/lattice/internal/grpc"
 	"github.com/alatticeio/lattice/internal/relay"
+	"net"
 	"sync"
 	"time"
 
@@ -289,12 +290,23 @@ func (w *wrrpDialer) Dial(ctx context.Context) (infra.Transport, error) {
 	case <-dialCtx.Done():
 		return nil, fmt.Errorf("wrrpDialer: timed out waiting for ready: %w", dialCtx.Err())
 	case <-w.readyChan:
-		remoteAddr := ""
-		if ra := w.wrrp.RemoteAddr(); ra != nil {
-			remoteAddr = ra.String()
-		}
-		return &WrrpTransport{remoteAddr: remoteAddr}, nil
+		return &WrrpTransport{remoteAddr: relayAddr(w.wrrp, w.remoteId)}, nil
+	}
+}
+
+// relayAddr returns the address of the relay that carries traffic to
+// remoteId, or "" when unknown.
+func relayAddr(w infra.Wrrp, remoteId infra.PeerIdentity) string {
+	var ra net.Addr
+	if rs, ok := w.(infra.RelaySelector); ok {
+		ra = rs.RemoteAddrFor(remoteId.ID().ToUint64())
+	} else {
+		ra = w.RemoteAddr()
+	}
+	if ra == nil {
+		return ""
 	}
+	return ra.String()
 }
 
 func (w *wrrpDialer) Type() infra.DialerType {
@@ -0,0 +1,33 @@
+package vo
+
+import "encoding/json"
+
+// AIConversationVo is the HTTP response shape for an AI assistant
+// conversation. Messages is set when a single conversation is read or
+// exported.
+type AIConversationVo struct {
+	ID           string        `json:"id"`
+	CreatedAt    string        `json:"createdAt"`
+	UpdatedAt    string        `json:"updatedAt"`
+	WorkspaceID  string        `json:"workspaceId"`
+	UserID       string        `json:"userId"`
+	UserName     string        `json:"userName"`
+	Title        string        `json:"title"`
+	MessageCount int           `json:"messageCount"`
+	InputTokens  int64         `json:"inputTokens"`
+	OutputTokens int64         `json:"outputTokens"`
+	ToolCalls    int           `json:"toolCalls"`
+	Messages     []AIMessageVo `json:"messages,omitempty"`
+}
+
+// AIMessageVo is one message of a conversation. ToolCalls and ToolResults
+// are the provider-neutral llm.ToolCall and llm.ToolResult lists.
+type AIMessageVo struct {
+	CreatedAt    string          `json:"createdAt"`
+	Role         string          `json:"role"`
+	Content      string          `json:"content,omitempty"`
+	ToolCalls    json.RawMessage `json:"toolCalls,omitempty"`
+	ToolResults  json.RawMessage `json:"toolResults,omitempty"`
+	InputTokens  int             `json:"inputTokens,omitempty"`
+	OutputTokens int             `json:"outputTokens,omitempty"`
+}
@@ -0,0 +1,38 @@
+package vo
+
+import "time"
+
+// APITokenVo describes an API token. The secret is never returned after
+// creation.
+type APITokenVo struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	Prefix           string     `json:"prefix"` // first characters of the secret, for recognition
+	ServiceAccountID string     `json:"serviceAccountId,omitempty"`
+	WorkspaceID      string     `json:"workspaceId,omitempty"`
+	Role             string     `json:"role,omitempty"`
+	PlatformAdmin    bool       `json:"platformAdmin,omitempty"`
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt       *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP       string     `json:"lastUsedIp,omitempty"`
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	Status           string     `json:"status"` // active | expired | revoked
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+// APITokenCreatedVo is returned once, when a token is created.
+type APITokenCreatedVo struct {
+	APITokenVo
+	Token string `json:"token"`
+}
+
+// ServiceAccountVo describes a workspace service account.
+type ServiceAccountVo struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Role        string    `json:"role"`
+	CreatedBy   string    `json:"createdBy,omitempty"`
+	Tokens      int       `json:"tokens"` // active tokens
+	CreatedAt   time.Time `json:"createdAt"`
+}
@@ -9,6 +9,7 @@ type AuditLogVo struct {
 	UserEmail    string `json:"userEmail"`
 	UserIP       string `json:"userIP"`
 	WorkspaceID  string `json:"workspaceId"`
+	Source       string `json:"source"`
 	Action       string `json:"action"`
 	Resource     string `json:"resource"`
 	ResourceID   string `json:"resourceId"`
@@ -17,4 +18,21 @@ type AuditLogVo struct {
 	Status       string `json:"status"`
 	StatusCode   int    `json:"statusCode"`
 	Detail       string `json:"detail,omitempty"`
+	Seq          int64  `json:"seq"`
+	Hash         string `json:"hash"`
+}
+
+// AuditVerifyVo is the result of walking the audit hash chain.
+type AuditVerifyVo struct {
+	// Valid is false when an entry was modified, removed or reordered.
+	Valid bool `json:"valid"`
+	// Entries is how many entries were checked before stopping.
+	Entries int64 `json:"entries"`
+	// FirstSeq is the oldest retained entry; earlier ones were pruned.
+	FirstSeq int64  `json:"firstSeq"`
+	LastSeq  int64  `json:"lastSeq"`
+	HeadHash string `json:"headHash"`
+	// BrokenSeq is the first entry that failed verification.
+	BrokenSeq int64  `json:"brokenSeq,omitempty"`
+	Reason    string `json:"reason,omitempty"`
 }
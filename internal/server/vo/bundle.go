@@ -0,0 +1,31 @@
+package vo
+
+// Bundle object kinds, in the order apply creates and updates them.
+const (
+	BundleKindNetwork   = "Network"
+	BundleKindRelay     = "Relay"
+	BundleKindPeer      = "Peer"
+	BundleKindPolicy    = "Policy"
+	BundleKindAlertRule = "AlertRule"
+)
+
+// Bundle change actions.
+const (
+	BundleActionCreate = "create"
+	BundleActionUpdate = "update"
+	BundleActionDelete = "delete"
+	// BundleActionSkip marks an object apply cannot reconcile, e.g. a peer
+	// that has not enrolled yet.
+	BundleActionSkip = "skip"
+)
+
+// BundleChange is one difference between a bundle and the server.
+type BundleChange struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	// Diff is a unified diff of the object's YAML, server state first.
+	Diff string `json:"diff,omitempty"`
+	// Reason explains a skip.
+	Reason string `json:"reason,omitempty"`
+}
@@ -0,0 +1,22 @@
+package vo
+
+// FlowLogVo is the HTTP response shape for a single flow record.
+type FlowLogVo struct {
+	ID            string `json:"id"`
+	WorkspaceID   string `json:"workspaceId"`
+	Peer          string `json:"peer"`
+	IntervalStart string `json:"intervalStart"`
+	IntervalEnd   string `json:"intervalEnd"`
+	Direction     string `json:"direction"`
+	Protocol      string `json:"protocol"`
+	LocalIP       string `json:"localIP"`
+	LocalPort     int    `json:"localPort"`
+	RemoteIP      string `json:"remoteIP"`
+	RemotePort    int    `json:"remotePort"`
+	RemotePeer    string `json:"remotePeer"`
+	Verdict       string `json:"verdict"`
+	Policy        string `json:"policy"`
+	Established   bool   `json:"established"`
+	Packets       uint64 `json:"packets"`
+	Bytes         uint64 `json:"bytes"`
+}
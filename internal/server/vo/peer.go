@@ -2,6 +2,9 @@ package vo
 
 import (
 	"time"
+
+	"github.com/alatticeio/lattice/api/v1alpha1"
+	"github.com/alatticeio/lattice/internal/agent/infra"
 )
 
 type PeerVo struct {
@@ -32,6 +35,9 @@ type PeerVo struct {
 	Status string `json:"status,omitempty"`
 	// LastSeen is the RFC3339 timestamp of the last received heartbeat. Nil if never seen.
 	LastSeen *string `json:"lastSeen,omitempty"`
+	// Connections is the per-peer connection path (direct/relay, RTT) last
+	// reported by this node's heartbeat.
+	Connections []infra.PeerPath `json:"connections,omitempty"`
 
 	// WorkspaceDisplayName is the human-readable name of the workspace this peer belongs to
 	WorkspaceDisplayName string `json:"workspaceDisplayName,omitempty"`
@@ -41,4 +47,8 @@ type PeerVo struct {
 
 	// Disabled indicates the node has been administratively disabled by a workspace manager.
 	Disabled bool `json:"disabled,omitempty"`
+
+	// Bandwidth and QoSClass are the node's traffic shaping settings.
+	Bandwidth *v1alpha1.BandwidthLimit `json:"bandwidth,omitempty"`
+	QoSClass  v1alpha1.QoSClass        `json:"qosClass,omitempty"`
 }
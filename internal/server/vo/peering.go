@@ -15,5 +15,6 @@ type PeeringVo struct {
 	Remote      WorkspaceEndpointVo `json:"remote"`
 	Status      string              `json:"status"`      // active | pending | failed
 	PeeringMode string              `json:"peeringMode"` // gateway | mesh
+	GatewayMode string              `json:"gatewayMode"` // active-standby | active-active
 	CreatedAt   string              `json:"createdAt"`
 }
@@ -19,4 +19,17 @@ type PolicyVo struct {
 	UpdatedByName               string `json:"updatedByName,omitempty"`
 	UpdatedAt                   string `json:"updatedAt,omitempty"`
 	*v1alpha1.LatticePolicySpec `json:",inline"`
+	// Warnings are the lint findings about the policy at the time it was
+	// saved. They do not block the change.
+	Warnings []AuditFinding `json:"warnings,omitempty"`
+}
+
+// AuditFinding is a single issue found by the policy linter or a security
+// audit scan.
+type AuditFinding struct {
+	Severity    string `json:"severity"` // high | medium | low
+	Rule        string `json:"rule"`
+	Resource    string `json:"resource"`
+	Description string `json:"description"`
+	Suggestion  string `json:"suggestion"`
 }
@@ -6,6 +6,8 @@ import "time"
 type RelayTestVo struct {
 	OK        bool   `json:"ok"`
 	LatencyMs int64  `json:"latencyMs"`
+	Sessions  *int   `json:"sessions,omitempty"`
+	Version   string `json:"version,omitempty"`
 	Error     string `json:"error,omitempty"`
 }
 
@@ -25,6 +27,10 @@ type RelayVo struct {
 	// LatencyMs is the last probe round-trip latency.
 	LatencyMs *int64 `json:"latencyMs,omitempty"`
 
+	// Sessions and Version are reported by the relay in the last probe.
+	Sessions *int   `json:"sessions,omitempty"`
+	Version  string `json:"version,omitempty"`
+
 	// ConnectedPeers is the number of peers configured to use this relay.
 	ConnectedPeers int `json:"connectedPeers,omitempty"`
 
@@ -9,8 +9,9 @@ type MemberVo struct {
 	Email    string            `json:"email"`
 	Avatar   string            `json:"avatar"`
 	Role     dto.WorkspaceRole `json:"role"`
-	Provider string            `json:"provider"` // "local", "dex", "ldap", etc.
-	Status   string            `json:"status"`   // "active", "pending"
+	Provider string            `json:"provider"`         // "local", "dex", "ldap", etc.
+	Status   string            `json:"status"`           // "active", "pending"
+	Source   string            `json:"source,omitempty"` // "group" when granted by an IdP group
 	JoinedAt string            `json:"joinedAt,omitempty"`
 }
 
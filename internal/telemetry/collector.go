@@ -22,10 +22,16 @@
 //	│                    Collector (engine)                │
 //	│                                                      │
 //	│  ┌────────────┐  []Sample  ┌──────────┐  []byte  ┌──────┐ │
-//	│  │ Scraper[]  │ ─────────▶ │ Encoder  │ ────────▶ │ Push │ │
+//	│  │ Scraper[]  │ ─────────▶ │ Encoder  │ ────────▶ │ Sink │ │
 //	│  └────────────┘            └──────────┘           └──────┘ │
+//	│                                          failed ──▶ WAL  │
 //	└──────────────────────────────────────────────────────┘
 //
+// The default Sink pushes to VictoriaMetrics over HTTP. The agent can pass a
+// Sink that sends batches over its NATS signaling connection instead. Batches
+// the Sink rejects after MaxRetries are kept in a bounded on-disk WAL and
+// replayed after the next successful send.
+//
 // Built-in Scrapers:
 //   - SystemScraper    CPU / memory / goroutines
 //   - WireGuardScraper per-peer traffic, status, handshake; node & workspace totals; peering traffic
@@ -115,8 +121,14 @@ type Config struct {
 	VMEndpoint string
 	// Interval between full scrape+push cycles. Defaults to 30 s.
 	Interval time.Duration
-	// MaxRetries is the number of HTTP push attempts on transient failure. Defaults to 3.
+	// MaxRetries is the number of push attempts on transient failure. Defaults to 3.
 	MaxRetries int
+	// Sink delivers encoded batches. Defaults to an HTTP push to VMEndpoint.
+	Sink Sink
+	// WALDir keeps batches that could not be delivered. Empty disables the WAL.
+	WALDir string
+	// WALMaxBytes bounds the WAL. Defaults to 64 MiB.
+	WALMaxBytes int64
 }
 
 func (c *Config) setDefaults() {
@@ -126,18 +138,23 @@ func (c *Config) setDefaults() {
 	if c.MaxRetries <= 0 {
 		c.MaxRetries = 3
 	}
+	if c.WALMaxBytes <= 0 {
+		c.WALMaxBytes = 64 << 20
+	}
 }
 
 // ─── Collector (engine) ───────────────────────────────────────────────────────
 
 // Collector drives all registered Scrapers on a fixed interval, encodes results
-// as Prometheus Remote Write (protobuf + Snappy), and pushes to VictoriaMetrics.
+// as Prometheus Remote Write (protobuf + Snappy), and hands them to a Sink.
 type Collector struct {
 	cfg      Config
 	id       Identity
 	log      *log.Logger
 	scrapers []Scraper
 	client   *http.Client
+	sink     Sink
+	wal      *WAL
 	// set exposes the VM metrics registry; currently used for node-level gauges
 	// that can serve a future scrape endpoint alongside push mode.
 	set *victoriametrics.Set
@@ -165,6 +182,16 @@ func New(cfg Config, peers *infra.PeerManager, logger *log.Logger, extra ...Scra
 		NewICMPScraper(peers, 3, 2*time.Second),
 	)
 	c.scrapers = append(c.scrapers, extra...)
+
+	c.sink = cfg.Sink
+	if c.sink == nil && cfg.VMEndpoint != "" {
+		c.sink = SinkFunc(c.push)
+	}
+	if cfg.WALDir != "" {
+		if c.wal, err = OpenWAL(cfg.WALDir, cfg.WALMaxBytes); err != nil {
+			return nil, err
+		}
+	}
 	return c, nil
 }
 
@@ -183,6 +210,7 @@ func (c *Collector) Run(ctx context.Context) error {
 	}
 	c.log.Info("telemetry collector started",
 		"endpoint", c.cfg.VMEndpoint,
+		"wal", c.cfg.WALDir,
 		"interval", c.cfg.Interval,
 		"scrapers", names,
 	)
@@ -213,7 +241,7 @@ func (c *Collector) tick(ctx context.Context) error {
 		all = append(all, samples...)
 	}
 
-	if len(all) == 0 || c.cfg.VMEndpoint == "" {
+	if len(all) == 0 || c.sink == nil {
 		return nil
 	}
 
@@ -221,7 +249,42 @@ func (c *Collector) tick(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("encode: %w", err)
 	}
-	return c.pushWithRetry(ctx, payload)
+	return c.deliver(ctx, payload)
+}
+
+// deliver sends payload, keeping it in the WAL when the Sink rejects it.
+// After a successful send the batches left in the WAL are replayed, oldest
+// first, until one fails again.
+func (c *Collector) deliver(ctx context.Context, payload []byte) error {
+	err := c.pushWithRetry(ctx, payload)
+	if c.wal == nil {
+		return err
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		dropped, walErr := c.wal.Append(payload)
+		if walErr != nil {
+			return fmt.Errorf("%w (WAL: %v)", err, walErr)
+		}
+		if dropped > 0 {
+			c.log.Warn("telemetry WAL full, dropped oldest batches", "dropped", dropped)
+		}
+		return fmt.Errorf("%w; batch kept in WAL (%d pending)", err, c.wal.Len())
+	}
+
+	if c.wal.Len() == 0 {
+		return nil
+	}
+	sent, err := c.wal.Replay(func(p []byte) error { return c.sink.Send(ctx, p) })
+	if sent > 0 {
+		c.log.Info("telemetry WAL replayed", "batches", sent, "pending", c.wal.Len())
+	}
+	if err != nil {
+		return fmt.Errorf("WAL replay: %w", err)
+	}
+	return nil
 }
 
 // WritePrometheus writes the VM metrics Set in Prometheus text format.
@@ -279,12 +342,12 @@ func encodeRemoteWrite(samples []Sample) ([]byte, error) {
 	return s2.EncodeSnappy(nil, writeReq), nil
 }
 
-// ─── HTTP push ────────────────────────────────────────────────────────────────
+// ─── Push ─────────────────────────────────────────────────────────────────────
 
 func (c *Collector) pushWithRetry(ctx context.Context, payload []byte) error {
 	var lastErr error
 	for i := 0; i < c.cfg.MaxRetries; i++ {
-		if err := c.push(ctx, payload); err != nil {
+		if err := c.sink.Send(ctx, payload); err != nil {
 			lastErr = err
 			select {
 			case <-ctx.Done():
@@ -298,6 +361,8 @@ func (c *Collector) pushWithRetry(ctx context.Context, payload []byte) error {
 	return fmt.Errorf("push failed after %d retries: %w", c.cfg.MaxRetries, lastErr)
 }
 
+// push is the default Sink: an HTTP Remote Write to VMEndpoint.
+
 func (c *Collector) push(ctx context.Context, payload []byte) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.VMEndpoint, bytes.NewReader(payload))
 	if err != nil {
@@ -0,0 +1,29 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import "context"
+
+// Sink delivers one encoded Remote Write batch. An error leaves the batch to
+// the caller, which retries it or keeps it in the WAL.
+type Sink interface {
+	Send(ctx context.Context, payload []byte) error
+}
+
+// SinkFunc adapts an ordinary function to Sink.
+type SinkFunc func(ctx context.Context, payload []byte) error
+
+// Send calls f(ctx, payload).
+func (f SinkFunc) Send(ctx context.Context, payload []byte) error { return f(ctx, payload) }
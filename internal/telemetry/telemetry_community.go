@@ -60,9 +60,12 @@ type Scraper interface {
 
 // Config holds engine-level settings.
 type Config struct {
-	VMEndpoint string
-	Interval   time.Duration
-	MaxRetries int
+	VMEndpoint  string
+	Interval    time.Duration
+	MaxRetries  int
+	Sink        Sink
+	WALDir      string
+	WALMaxBytes int64
 }
 
 // Collector stub — New always returns errProRequired so the collector is never started.
@@ -0,0 +1,179 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// walSuffix names the files that hold one batch each.
+const walSuffix = ".rw"
+
+// WAL is a bounded on-disk queue of Remote Write batches that could not be
+// delivered. Each batch is one file named by a sequence number, so replay
+// keeps the order in which the batches were produced. When the queue grows
+// past its limit the oldest batches are dropped.
+type WAL struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	next    uint64
+	entries []walEntry // oldest first
+	size    int64
+}
+
+type walEntry struct {
+	seq  uint64
+	size int64
+}
+
+// OpenWAL opens the WAL in dir, creating the directory if needed, and loads
+// the batches left by a previous run.
+func OpenWAL(dir string, maxBytes int64) (*WAL, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("telemetry: invalid WAL limit %d", maxBytes)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("telemetry: create WAL dir: %w", err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: read WAL dir: %w", err)
+	}
+
+	w := &WAL{dir: dir, maxBytes: maxBytes}
+	for _, f := range files {
+		name := f.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			// Interrupted Append.
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, walSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, walSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		w.entries = append(w.entries, walEntry{seq: seq, size: info.Size()})
+		w.size += info.Size()
+	}
+	sort.Slice(w.entries, func(i, j int) bool { return w.entries[i].seq < w.entries[j].seq })
+	if n := len(w.entries); n > 0 {
+		w.next = w.entries[n-1].seq + 1
+	}
+	w.trim()
+	return w, nil
+}
+
+// Append stores payload as the newest batch. It returns the number of old
+// batches dropped to stay within the limit.
+func (w *WAL) Append(payload []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	size := int64(len(payload))
+	if size > w.maxBytes {
+		return 0, fmt.Errorf("telemetry: batch of %d bytes exceeds the WAL limit", size)
+	}
+	seq := w.next
+	path := w.path(seq)
+	if err := os.WriteFile(path+".tmp", payload, 0o600); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		_ = os.Remove(path + ".tmp")
+		return 0, err
+	}
+	w.next++
+	w.entries = append(w.entries, walEntry{seq: seq, size: size})
+	w.size += size
+	return w.trim(), nil
+}
+
+// Replay sends the stored batches oldest first and removes every batch send
+// accepts. It stops at the first error and returns it together with the
+// number of batches sent. Batches that can no longer be read are dropped.
+func (w *WAL) Replay(send func(payload []byte) error) (int, error) {
+	sent := 0
+	for {
+		w.mu.Lock()
+		if len(w.entries) == 0 {
+			w.mu.Unlock()
+			return sent, nil
+		}
+		e := w.entries[0]
+		w.mu.Unlock()
+
+		payload, err := os.ReadFile(w.path(e.seq))
+		if err == nil {
+			if err = send(payload); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+		w.remove(e.seq)
+	}
+}
+
+// Len returns the number of stored batches.
+func (w *WAL) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.entries)
+}
+
+// remove deletes the batch seq if it is still the oldest; a concurrent
+// Append may have trimmed it already.
+func (w *WAL) remove(seq uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.entries) == 0 || w.entries[0].seq != seq {
+		return
+	}
+	_ = os.Remove(w.path(seq))
+	w.size -= w.entries[0].size
+	w.entries = w.entries[1:]
+}
+
+// trim drops the oldest batches until the WAL fits its limit. The caller
+// must hold w.mu.
+func (w *WAL) trim() int {
+	dropped := 0
+	for w.size > w.maxBytes && len(w.entries) > 0 {
+		e := w.entries[0]
+		_ = os.Remove(w.path(e.seq))
+		w.size -= e.size
+		w.entries = w.entries[1:]
+		dropped++
+	}
+	return dropped
+}
+
+func (w *WAL) path(seq uint64) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%020d%s", seq, walSuffix))
+}
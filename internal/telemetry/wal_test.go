@@ -0,0 +1,119 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWAL_ReplayInOrder(t *testing.T) {
+	w, err := OpenWAL(t.TempDir(), 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"a", "b", "c"} {
+		if _, err := w.Append([]byte(p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	fail := errors.New("offline")
+	n, err := w.Replay(func(p []byte) error {
+		if string(p) == "c" {
+			return fail
+		}
+		got = append(got, string(p))
+		return nil
+	})
+	if !errors.Is(err, fail) || n != 2 {
+		t.Fatalf("replay = %d, %v", n, err)
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("replayed %v", got)
+	}
+	if w.Len() != 1 {
+		t.Errorf("len = %d, want 1", w.Len())
+	}
+
+	n, err = w.Replay(func([]byte) error { return nil })
+	if err != nil || n != 1 || w.Len() != 0 {
+		t.Errorf("second replay = %d, %v, len %d", n, err, w.Len())
+	}
+}
+
+func TestWAL_DropsOldest(t *testing.T) {
+	w, err := OpenWAL(t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"1111", "2222"} {
+		if _, err := w.Append([]byte(p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dropped, err := w.Append([]byte("3333"))
+	if err != nil || dropped != 1 {
+		t.Fatalf("append = %d, %v", dropped, err)
+	}
+
+	var got []string
+	_, _ = w.Replay(func(p []byte) error {
+		got = append(got, string(p))
+		return nil
+	})
+	if len(got) != 2 || got[0] != "2222" || got[1] != "3333" {
+		t.Errorf("replayed %v", got)
+	}
+
+	if _, err := w.Append(make([]byte, 11)); err == nil {
+		t.Error("expected error for a batch larger than the limit")
+	}
+}
+
+func TestWAL_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL(dir, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Append([]byte("a"))
+	_, _ = w.Append([]byte("b"))
+	// Leftover of an interrupted append.
+	if err := os.WriteFile(filepath.Join(dir, "x.rw.tmp"), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err = OpenWAL(dir, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Append([]byte("c"))
+
+	var got string
+	_, _ = w.Replay(func(p []byte) error {
+		got += string(p)
+		return nil
+	})
+	if got != "abc" {
+		t.Errorf("replayed %q, want %q", got, "abc")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "x.rw.tmp")); !os.IsNotExist(err) {
+		t.Error("temporary file was not removed")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed five-field cron expression: minute, hour, day of
+// month, month and day of week. Fields take *, values, ranges, lists and
+// steps, e.g. "*/15 2-4 * * 1,3".
+type CronSchedule struct {
+	fields  [5]uint64 // bit v is set when value v matches
+	anyDay  bool      // day of month is *
+	anyWday bool      // day of week is *
+}
+
+var cronBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// ParseCron parses a five-field cron expression.
+func ParseCron(expr string) (*CronSchedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(parts))
+	}
+	s := &CronSchedule{anyDay: parts[2] == "*", anyWday: parts[4] == "*"}
+	for i, part := range parts {
+		bits, err := parseCronField(part, cronBounds[i][0], cronBounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", part, err)
+		}
+		s.fields[i] = bits
+	}
+	// Both 0 and 7 are Sunday.
+	if s.fields[4]&(1<<7) != 0 {
+		s.fields[4] |= 1
+	}
+	return s, nil
+}
+
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		span, step := item, 1
+		if before, after, ok := strings.Cut(item, "/"); ok {
+			n, err := strconv.Atoi(after)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", after)
+			}
+			span, step = before, n
+		}
+		from, to := lo, hi
+		if span != "*" {
+			a, b, isRange := strings.Cut(span, "-")
+			var err error
+			if from, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value %q", a)
+			}
+			switch {
+			case isRange:
+				if to, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("invalid value %q", b)
+				}
+			case step == 1:
+				to = from
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return 0, fmt.Errorf("%q is outside %d-%d", item, lo, hi)
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (s *CronSchedule) has(field, v int) bool {
+	return s.fields[field]&(1<<uint(v)) != 0
+}
+
+// dayMatches applies the cron rule that a restricted day of month and day of
+// week match if either does.
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	day, wday := s.has(2, t.Day()), s.has(4, int(t.Weekday()))
+	switch {
+	case s.anyDay && s.anyWday:
+		return true
+	case s.anyDay:
+		return wday
+	case s.anyWday:
+		return day
+	default:
+		return day || wday
+	}
+}
+
+// Next returns the first matching minute after t, or the zero time if none
+// comes within five years.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for end := t.AddDate(5, 0, 0); t.Before(end); {
+		switch {
+		case !s.has(3, int(t.Month())) || !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.has(1, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !s.has(0, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 The Lattice Authors, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	from := time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC) // a Saturday
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 2 * * *", time.Date(2026, 3, 15, 2, 0, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2026, 3, 14, 10, 40, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2026, 3, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"15 4 1 4 *", time.Date(2026, 4, 1, 4, 15, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, c := range cases {
+		s, err := ParseCron(c.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", c.expr, err)
+		}
+		if got := s.Next(from); !got.Equal(c.want) {
+			t.Errorf("%s: next = %v, want %v", c.expr, got, c.want)
+		}
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}